@@ -5,6 +5,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -74,14 +75,17 @@ type InstanceServer interface {
 
 	// Server functions
 	GetMetrics() (metrics string, err error)
+	GetInventory(format string, target io.Writer) (err error)
 	GetServer() (server *api.Server, ETag string, err error)
 	GetServerResources() (resources *api.Resources, err error)
+	GetMetadataDeviceTypes() (deviceTypes []api.MetadataDeviceType, err error)
 	UpdateServer(server api.ServerPut, ETag string) (err error)
 	HasExtension(extension string) (exists bool)
 	RequireAuthenticated(authenticated bool)
 	IsClustered() (clustered bool)
 	UseTarget(name string) (client InstanceServer)
 	UseProject(name string) (client InstanceServer)
+	UseOperationCallback(url string) (client InstanceServer)
 
 	// Certificate functions
 	GetCertificateFingerprints() (fingerprints []string, err error)
@@ -91,6 +95,7 @@ type InstanceServer interface {
 	UpdateCertificate(fingerprint string, certificate api.CertificatePut, ETag string) (err error)
 	DeleteCertificate(fingerprint string) (err error)
 	CreateCertificateToken(certificate api.CertificatesPost) (op Operation, err error)
+	CreateCertificateGenerated(certificate api.CertificatesPost) (generated *api.CertificateGenerated, err error)
 
 	// Container functions
 	//
@@ -212,11 +217,26 @@ type InstanceServer interface {
 	CreateInstanceFromBackup(args InstanceBackupArgs) (op Operation, err error)
 
 	GetInstanceState(name string) (state *api.InstanceState, ETag string, err error)
+	GetInstanceUsage(name string, period time.Duration) (usage *api.InstanceUsage, err error)
+	GetInstanceTopology(name string) (topology *api.InstanceTopology, err error)
+	GetInstanceFirewall(name string) (firewall *api.InstanceFirewall, err error)
+	GetInstanceLease(name string) (lease *api.InstanceLease, err error)
+	RenewInstanceLease(name string) (lease *api.InstanceLease, err error)
+	RemapInstance(name string) (op Operation, err error)
 	UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (op Operation, err error)
 
+	GetInstanceCommands(name string) (commands []api.InstanceCommand, err error)
+	GetInstanceCommand(name string, id string) (command *api.InstanceCommand, err error)
+	CreateInstanceCommand(name string, command api.InstanceCommandsPost) (queued *api.InstanceCommand, err error)
+	DeleteInstanceCommand(name string, id string) (err error)
+
+	GetInstanceDiskEncryptionKeys(name string) (keys []api.InstanceDiskEncryptionKey, err error)
+	DeleteInstanceDiskEncryptionKey(name string, device string) (err error)
+
 	GetInstanceLogfiles(name string) (logfiles []string, err error)
 	GetInstanceLogfile(name string, filename string) (content io.ReadCloser, err error)
 	DeleteInstanceLogfile(name string, filename string) (err error)
+	GetInstanceLogsStream(name string, args InstanceLogsStreamArgs) (conn *websocket.Conn, err error)
 
 	GetInstanceMetadata(name string) (metadata *api.ImageMetadata, ETag string, err error)
 	UpdateInstanceMetadata(name string, metadata api.ImageMetadata, ETag string) (err error)
@@ -226,6 +246,27 @@ type InstanceServer interface {
 	CreateInstanceTemplateFile(instanceName string, templateName string, content io.ReadSeeker) (err error)
 	DeleteInstanceTemplateFile(name string, templateName string) (err error)
 
+	// Instance pool functions ("instance_pools" API extension)
+	GetInstancePoolNames() (names []string, err error)
+	GetInstancePools() (pools []api.InstancePool, err error)
+	GetInstancePool(name string) (pool *api.InstancePool, ETag string, err error)
+	GetInstancePoolInstances(name string) (instances []api.InstancePoolInstance, err error)
+	CreateInstancePool(pool api.InstancePoolsPost) (err error)
+	UpdateInstancePool(name string, pool api.InstancePoolPut, ETag string) (err error)
+	DeleteInstancePool(name string) (err error)
+	AcquireInstancePoolInstance(name string) (instance *api.InstancePoolInstance, err error)
+	ReleaseInstancePoolInstance(name string, release api.InstancePoolReleasePost) (err error)
+
+	// Configuration history functions ("config_history" API extension)
+	GetInstanceConfigRevisions(name string) (revisions []api.ConfigRevision, err error)
+	RollbackInstanceConfigRevision(name string, revision int64) (op Operation, err error)
+	GetProfileConfigRevisions(name string) (revisions []api.ConfigRevision, err error)
+	RollbackProfileConfigRevision(name string, revision int64) (err error)
+	GetNetworkConfigRevisions(name string) (revisions []api.ConfigRevision, err error)
+	RollbackNetworkConfigRevision(name string, revision int64) (err error)
+	GetStoragePoolConfigRevisions(name string) (revisions []api.ConfigRevision, err error)
+	RollbackStoragePoolConfigRevision(name string, revision int64) (err error)
+
 	// Event handling functions
 	GetEvents() (listener *EventListener, err error)
 	GetEventsAllProjects() (listener *EventListener, err error)
@@ -241,6 +282,8 @@ type InstanceServer interface {
 	CreateImageAlias(alias api.ImageAliasesPost) (err error)
 	UpdateImageAlias(name string, alias api.ImageAliasesEntryPut, ETag string) (err error)
 	RenameImageAlias(name string, alias api.ImageAliasesEntryPost) (err error)
+	PromoteImageAlias(name string, channel string) (err error)
+	RollbackImageAlias(name string) (err error)
 	DeleteImageAlias(name string) (err error)
 
 	// Network functions ("network" API extension)
@@ -249,6 +292,7 @@ type InstanceServer interface {
 	GetNetwork(name string) (network *api.Network, ETag string, err error)
 	GetNetworkLeases(name string) (leases []api.NetworkLease, err error)
 	GetNetworkState(name string) (state *api.NetworkState, err error)
+	GetNetworkMTU(name string) (check *api.NetworkMTU, err error)
 	CreateNetwork(network api.NetworksPost) (err error)
 	UpdateNetwork(name string, network api.NetworkPut, ETag string) (err error)
 	RenameNetwork(name string, network api.NetworkPost) (err error)
@@ -291,6 +335,15 @@ type InstanceServer interface {
 	// Network allocations functions ("network_allocations" API extension)
 	GetNetworkAllocations(allProjects bool) (allocations []api.NetworkAllocations, err error)
 
+	// Network address pool functions ("network_address_pools" API extension)
+	GetNetworkAddressPoolNames() (names []string, err error)
+	GetNetworkAddressPools() (pools []api.NetworkAddressPool, err error)
+	GetNetworkAddressPool(name string) (pool *api.NetworkAddressPool, ETag string, err error)
+	GetNetworkAddressPoolAllocations(name string) (allocations []api.NetworkAddressPoolAllocation, err error)
+	CreateNetworkAddressPool(pool api.NetworkAddressPoolsPost) (err error)
+	UpdateNetworkAddressPool(name string, pool api.NetworkAddressPoolPut, ETag string) (err error)
+	DeleteNetworkAddressPool(name string) (err error)
+
 	// Network zone functions ("network_dns" API extension)
 	GetNetworkZoneNames() (names []string, err error)
 	GetNetworkZones() (zones []api.NetworkZone, err error)
@@ -324,17 +377,27 @@ type InstanceServer interface {
 	UpdateProfile(name string, profile api.ProfilePut, ETag string) (err error)
 	RenameProfile(name string, profile api.ProfilePost) (err error)
 	DeleteProfile(name string) (err error)
+	GetProfileDrift(name string) (drift []api.ProfileInstanceDrift, err error)
 
 	// Project functions
 	GetProjectNames() (names []string, err error)
 	GetProjects() (projects []api.Project, err error)
 	GetProject(name string) (project *api.Project, ETag string, err error)
 	GetProjectState(name string) (project *api.ProjectState, err error)
+	GetProjectIdmap(name string) (idmap *api.ProjectIdmap, err error)
 	CreateProject(project api.ProjectsPost) (err error)
 	UpdateProject(name string, project api.ProjectPut, ETag string) (err error)
 	RenameProject(name string, project api.ProjectPost) (op Operation, err error)
 	DeleteProject(name string) (err error)
 
+	// Syscall interception policy functions ("seccomp_policies" API extension)
+	GetSeccompPolicyNames() (names []string, err error)
+	GetSeccompPolicies() (policies []api.SeccompPolicy, err error)
+	GetSeccompPolicy(name string) (policy *api.SeccompPolicy, ETag string, err error)
+	CreateSeccompPolicy(policy api.SeccompPoliciesPost) (err error)
+	UpdateSeccompPolicy(name string, policy api.SeccompPolicyPut, ETag string) (err error)
+	DeleteSeccompPolicy(name string) (err error)
+
 	// Storage pool functions ("storage" API extension)
 	GetStoragePoolNames() (names []string, err error)
 	GetStoragePools() (pools []api.StoragePool, err error)
@@ -414,6 +477,8 @@ type InstanceServer interface {
 	UpdateClusterCertificate(certs api.ClusterCertificatePut, ETag string) (err error)
 	GetClusterMemberState(name string) (*api.ClusterMemberState, string, error)
 	UpdateClusterMemberState(name string, state api.ClusterMemberStatePost) (op Operation, err error)
+	GetClusterMemberLog(name string) (content io.ReadCloser, err error)
+	GetClusterMemberLogStream(name string, args ClusterMemberLogStreamArgs) (conn *websocket.Conn, err error)
 	GetClusterGroups() ([]api.ClusterGroup, error)
 	GetClusterGroupNames() ([]string, error)
 	RenameClusterGroup(name string, group api.ClusterGroupPost) error
@@ -421,6 +486,12 @@ type InstanceServer interface {
 	DeleteClusterGroup(name string) error
 	UpdateClusterGroup(name string, group api.ClusterGroupPut, ETag string) error
 	GetClusterGroup(name string) (*api.ClusterGroup, string, error)
+	GetClusterDatabaseSnapshots() (snapshots []api.ClusterDatabaseSnapshot, err error)
+	GetClusterDatabaseSnapshot(name string) (snapshot *api.ClusterDatabaseSnapshot, err error)
+	CreateClusterDatabaseSnapshot() (op Operation, err error)
+	RestoreClusterDatabaseSnapshot(name string) (op Operation, err error)
+	GetClusterDatabaseIntegrity() (integrity *api.ClusterDatabaseIntegrity, err error)
+	DeleteClusterDatabaseOrphans(checks []string) (integrity *api.ClusterDatabaseIntegrity, err error)
 
 	// Warning functions
 	GetWarningUUIDs() (uuids []string, err error)
@@ -429,6 +500,28 @@ type InstanceServer interface {
 	UpdateWarning(UUID string, warning api.WarningPut, ETag string) (err error)
 	DeleteWarning(UUID string) (err error)
 
+	// Tombstone functions
+	GetTombstones() (tombstones []api.Tombstone, err error)
+	GetTombstonesAllProjects() (tombstones []api.Tombstone, err error)
+	GetTombstone(id int64) (tombstone *api.Tombstone, err error)
+	RestoreTombstone(id int64, tombstone api.TombstonePost) (err error)
+	DeleteTombstone(id int64) (err error)
+
+	// Approval functions
+	GetAuthApprovals() (approvals []api.AuthApproval, err error)
+	GetAuthApproval(id int64) (approval *api.AuthApproval, err error)
+	CreateAuthApproval(approval api.AuthApprovalsPost) (created *api.AuthApproval, err error)
+	UpdateAuthApproval(id int64, approval api.AuthApprovalPost) (err error)
+	DeleteAuthApproval(id int64) (err error)
+
+	// SSH key functions ("ssh_keys" API extension)
+	GetSSHKeyNames() (names []string, err error)
+	GetSSHKeys() (keys []api.SSHKey, err error)
+	GetSSHKey(name string) (key *api.SSHKey, ETag string, err error)
+	CreateSSHKey(key api.SSHKeysPost) (err error)
+	UpdateSSHKey(name string, key api.SSHKeyPut, ETag string) (err error)
+	DeleteSSHKey(name string) (err error)
+
 	// Authorization functions
 	GetAuthGroupNames() (groupNames []string, err error)
 	GetAuthGroups() (groups []api.AuthGroup, err error)
@@ -469,6 +562,10 @@ type ConnectionInfo struct {
 	SocketPath  string
 	Project     string
 	Target      string
+
+	// Addresses and certificate to use for migration and data transfer connections.
+	MigrationAddresses   []string
+	MigrationCertificate string
 }
 
 // The BackupFileRequest struct is used for a backup download request.
@@ -671,6 +768,29 @@ type InstanceConsoleArgs struct {
 type InstanceConsoleLogArgs struct {
 }
 
+// The InstanceLogsStreamArgs struct is used to pass additional options during an
+// instance log stream request.
+type InstanceLogsStreamArgs struct {
+	// Sources to stream (defaults to all the sources of the instance)
+	Sources []string
+
+	// Number of past lines to send for each source (the server default is used if negative)
+	Lines int
+
+	// Whether to keep streaming new records
+	Follow bool
+}
+
+// The ClusterMemberLogStreamArgs struct is used to pass additional options during a
+// cluster member log stream request.
+type ClusterMemberLogStreamArgs struct {
+	// Number of past lines to send (the server default is used if negative)
+	Lines int
+
+	// Whether to keep streaming new lines
+	Follow bool
+}
+
 // The InstanceExecArgs struct is used to pass additional options during instance exec.
 type InstanceExecArgs struct {
 	// Standard input
@@ -46,8 +46,9 @@ type ProtocolLXD struct {
 
 	requireAuthenticated bool
 
-	clusterTarget string
-	project       string
+	clusterTarget     string
+	project           string
+	operationCallback string
 
 	oidcClient *oidcClient
 }
@@ -97,6 +98,26 @@ func (r *ProtocolLXD) GetConnectionInfo() (*ConnectionInfo, error) {
 
 	info.Addresses = urls
 
+	// Use the dedicated migration listener if the server has one.
+	info.MigrationAddresses = info.Addresses
+	info.MigrationCertificate = info.Certificate
+
+	if r.server != nil && len(r.server.Environment.MigrationAddresses) > 0 {
+		migrationURLs := []string{}
+		for _, addr := range r.server.Environment.MigrationAddresses {
+			if strings.HasPrefix(addr, ":") {
+				continue
+			}
+
+			migrationURLs = append(migrationURLs, fmt.Sprintf("https://%s", addr))
+		}
+
+		if len(migrationURLs) > 0 {
+			info.MigrationAddresses = migrationURLs
+			info.MigrationCertificate = r.server.Environment.MigrationCertificate
+		}
+	}
+
 	return &info, nil
 }
 
@@ -134,6 +155,34 @@ func (r *ProtocolLXD) isSameServer(server Server) bool {
 		srcInfo.Project == dstInfo.Project && srcInfo.Target == dstInfo.Target
 }
 
+// sameServerOtherProject checks if the given server is the same as the server of the struct, but uses a different
+// project. If so, the project of the given server is returned.
+func (r *ProtocolLXD) sameServerOtherProject(server Server) (string, bool) {
+	if r == nil || server == nil || r.server == nil {
+		return "", false
+	}
+
+	srcInfo, err := r.GetConnectionInfo()
+	if err != nil {
+		return "", false
+	}
+
+	dstInfo, err := server.GetConnectionInfo()
+	if err != nil {
+		return "", false
+	}
+
+	if dstInfo.Protocol != "lxd" || srcInfo.Protocol != dstInfo.Protocol || srcInfo.Certificate != dstInfo.Certificate || srcInfo.Project == dstInfo.Project {
+		return "", false
+	}
+
+	if srcInfo.URL != dstInfo.URL || srcInfo.SocketPath != dstInfo.SocketPath {
+		return "", false
+	}
+
+	return dstInfo.Project, true
+}
+
 // GetHTTPClient returns the http client used for the connection. This can be used to set custom http options.
 func (r *ProtocolLXD) GetHTTPClient() (*http.Client, error) {
 	if r.http == nil {
@@ -157,6 +206,7 @@ func (r *ProtocolLXD) DoHTTP(req *http.Request) (*http.Response, error) {
 // addClientHeaders sets headers from client settings.
 // User-Agent (if r.httpUserAgent is set).
 // X-LXD-authenticated (if r.requireAuthenticated is set).
+// X-LXD-Operation-Callback (if r.operationCallback is set).
 // OIDC Authorization header (if r.oidcClient is set).
 func (r *ProtocolLXD) addClientHeaders(req *http.Request) {
 	if r.httpUserAgent != "" {
@@ -167,6 +217,10 @@ func (r *ProtocolLXD) addClientHeaders(req *http.Request) {
 		req.Header.Set("X-LXD-authenticated", "true")
 	}
 
+	if r.operationCallback != "" {
+		req.Header.Set("X-LXD-Operation-Callback", r.operationCallback)
+	}
+
 	if r.oidcClient != nil {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.oidcClient.getAccessToken()))
 	}
@@ -221,7 +275,15 @@ func lxdParseResponse(resp *http.Response) (*api.Response, string, error) {
 
 	// Handle errors
 	if response.Type == api.ErrorResponse {
-		return nil, "", api.StatusErrorf(resp.StatusCode, response.Error)
+		statusErr := api.StatusErrorf(resp.StatusCode, response.Error)
+		if response.ErrorReason == "" {
+			return nil, "", statusErr
+		}
+
+		// Keep the reason and metadata so that callers can match on them.
+		metadata, _ := response.MetadataAsMap()
+
+		return nil, "", statusErr.WithReason(response.ErrorReason, metadata)
 	}
 
 	return &response, etag, nil
@@ -502,6 +564,15 @@ func (r *ProtocolLXD) getSourceImageConnectionInfo(source ImageServer, image api
 		return nil, nil
 	}
 
+	// Clone the image of another project on the same server rather than transferring it.
+	sourceProject, ok := r.sameServerOtherProject(source)
+	if ok && r.HasExtension("instances_cross_project_clone") {
+		instSrc.Fingerprint = image.Fingerprint
+		instSrc.Alias = ""
+		instSrc.Project = sourceProject
+		return nil, nil
+	}
+
 	// Minimal source fields for remote image
 	instSrc.Mode = "pull"
 
@@ -0,0 +1,88 @@
+package lxd
+
+import (
+	"fmt"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetAuthApprovals returns the requests for approval visible to the current identity.
+func (r *ProtocolLXD) GetAuthApprovals() ([]api.AuthApproval, error) {
+	err := r.CheckExtension("auth_approvals")
+	if err != nil {
+		return nil, err
+	}
+
+	approvals := []api.AuthApproval{}
+
+	_, err = r.queryStruct("GET", "/auth/approvals?recursion=1", nil, "", &approvals)
+	if err != nil {
+		return nil, err
+	}
+
+	return approvals, nil
+}
+
+// GetAuthApproval returns the request for approval with the given ID.
+func (r *ProtocolLXD) GetAuthApproval(id int64) (*api.AuthApproval, error) {
+	err := r.CheckExtension("auth_approvals")
+	if err != nil {
+		return nil, err
+	}
+
+	approval := api.AuthApproval{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("/auth/approvals/%d", id), nil, "", &approval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &approval, nil
+}
+
+// CreateAuthApproval requests the approval of a privileged action by another identity.
+func (r *ProtocolLXD) CreateAuthApproval(approval api.AuthApprovalsPost) (*api.AuthApproval, error) {
+	err := r.CheckExtension("auth_approvals")
+	if err != nil {
+		return nil, err
+	}
+
+	created := api.AuthApproval{}
+
+	_, err = r.queryStruct("POST", "/auth/approvals", approval, "", &created)
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// UpdateAuthApproval approves or rejects the request for approval with the given ID.
+func (r *ProtocolLXD) UpdateAuthApproval(id int64, approval api.AuthApprovalPost) error {
+	err := r.CheckExtension("auth_approvals")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query("POST", fmt.Sprintf("/auth/approvals/%d", id), approval, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAuthApproval withdraws or revokes the request for approval with the given ID.
+func (r *ProtocolLXD) DeleteAuthApproval(id int64) error {
+	err := r.CheckExtension("auth_approvals")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query("DELETE", fmt.Sprintf("/auth/approvals/%d", id), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -87,6 +87,28 @@ func (r *ProtocolLXD) DeleteCertificate(fingerprint string) error {
 	return nil
 }
 
+// CreateCertificateGenerated asks the server to generate and trust a new certificate, returning its key pair.
+func (r *ProtocolLXD) CreateCertificateGenerated(certificate api.CertificatesPost) (*api.CertificateGenerated, error) {
+	err := r.CheckExtension("certificate_generate_metrics")
+	if err != nil {
+		return nil, err
+	}
+
+	if !certificate.Generate {
+		return nil, fmt.Errorf("Generate needs to be true if requesting a generated certificate")
+	}
+
+	generated := api.CertificateGenerated{}
+
+	// Send the request
+	_, err = r.queryStruct("POST", "/certificates", certificate, "", &generated)
+	if err != nil {
+		return nil, err
+	}
+
+	return &generated, nil
+}
+
 // CreateCertificateToken requests a certificate add token.
 func (r *ProtocolLXD) CreateCertificateToken(certificate api.CertificatesPost) (Operation, error) {
 	err := r.CheckExtension("certificate_token")
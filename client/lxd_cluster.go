@@ -2,6 +2,12 @@ package lxd
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/websocket"
 
 	"github.com/canonical/lxd/shared/api"
 )
@@ -223,6 +229,67 @@ func (r *ProtocolLXD) UpdateClusterMemberState(name string, state api.ClusterMem
 	return op, nil
 }
 
+// GetClusterMemberLog returns the content of the daemon log file of a cluster member.
+//
+// Note that it's the caller's responsibility to close the returned ReadCloser.
+func (r *ProtocolLXD) GetClusterMemberLog(name string) (io.ReadCloser, error) {
+	err := r.CheckExtension("cluster_member_log")
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the HTTP request
+	url := fmt.Sprintf("%s/1.0/cluster/members/%s/log", r.httpBaseURL.String(), url.PathEscape(name))
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the return value for a cleaner error
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := lxdParseResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp.Body, err
+}
+
+// GetClusterMemberLogStream returns a websocket on which the lines of the daemon log of a cluster member are sent.
+//
+// Each message is a JSON encoded api.ClusterMemberLogRecord. The websocket is closed by the server once the past
+// lines have been sent, unless following the log.
+func (r *ProtocolLXD) GetClusterMemberLogStream(name string, args ClusterMemberLogStreamArgs) (*websocket.Conn, error) {
+	err := r.CheckExtension("cluster_member_log")
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	if args.Lines >= 0 {
+		values.Set("lines", strconv.Itoa(args.Lines))
+	}
+
+	if args.Follow {
+		values.Set("follow", "true")
+	}
+
+	uri := fmt.Sprintf("/cluster/members/%s/log/stream", url.PathEscape(name))
+	if len(values) > 0 {
+		uri += "?" + values.Encode()
+	}
+
+	return r.websocket(uri)
+}
+
 // GetClusterGroups returns the cluster groups.
 func (r *ProtocolLXD) GetClusterGroups() ([]api.ClusterGroup, error) {
 	err := r.CheckExtension("clustering_groups")
@@ -334,3 +401,100 @@ func (r *ProtocolLXD) GetClusterGroup(name string) (*api.ClusterGroup, string, e
 
 	return &group, etag, nil
 }
+
+// GetClusterDatabaseSnapshots returns the database snapshots stored in the configured S3 bucket, newest first.
+func (r *ProtocolLXD) GetClusterDatabaseSnapshots() ([]api.ClusterDatabaseSnapshot, error) {
+	err := r.CheckExtension("cluster_database_snapshots")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []api.ClusterDatabaseSnapshot{}
+	_, err = r.queryStruct("GET", "/cluster/database-snapshots?recursion=1", nil, "", &snapshots)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// GetClusterDatabaseSnapshot returns the database snapshot with the given name.
+func (r *ProtocolLXD) GetClusterDatabaseSnapshot(name string) (*api.ClusterDatabaseSnapshot, error) {
+	err := r.CheckExtension("cluster_database_snapshots")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := api.ClusterDatabaseSnapshot{}
+	_, err = r.queryStruct("GET", api.NewURL().Path("cluster", "database-snapshots", name).String(), nil, "", &snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// CreateClusterDatabaseSnapshot ships a snapshot of the global database to the configured S3 bucket.
+func (r *ProtocolLXD) CreateClusterDatabaseSnapshot() (Operation, error) {
+	err := r.CheckExtension("cluster_database_snapshots")
+	if err != nil {
+		return nil, err
+	}
+
+	op, _, err := r.queryOperation("POST", "/cluster/database-snapshots", nil, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// RestoreClusterDatabaseSnapshot replaces the content of the global database of a standalone server with the one
+// of the database snapshot with the given name.
+func (r *ProtocolLXD) RestoreClusterDatabaseSnapshot(name string) (Operation, error) {
+	err := r.CheckExtension("cluster_database_snapshots")
+	if err != nil {
+		return nil, err
+	}
+
+	req := api.ClusterDatabaseSnapshotPost{Action: "restore"}
+	op, _, err := r.queryOperation("POST", api.NewURL().Path("cluster", "database-snapshots", name).String(), req, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// GetClusterDatabaseIntegrity checks the global database for orphaned rows.
+func (r *ProtocolLXD) GetClusterDatabaseIntegrity() (*api.ClusterDatabaseIntegrity, error) {
+	err := r.CheckExtension("cluster_database_integrity")
+	if err != nil {
+		return nil, err
+	}
+
+	integrity := api.ClusterDatabaseIntegrity{}
+	_, err = r.queryStruct("GET", "/cluster/database-integrity", nil, "", &integrity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &integrity, nil
+}
+
+// DeleteClusterDatabaseOrphans deletes the orphaned rows of the global database found by the given checks and
+// returns the result of checking the database again.
+func (r *ProtocolLXD) DeleteClusterDatabaseOrphans(checks []string) (*api.ClusterDatabaseIntegrity, error) {
+	err := r.CheckExtension("cluster_database_integrity")
+	if err != nil {
+		return nil, err
+	}
+
+	integrity := api.ClusterDatabaseIntegrity{}
+	_, err = r.queryStruct("POST", "/cluster/database-integrity", api.ClusterDatabaseIntegrityPost{Delete: checks}, "", &integrity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &integrity, nil
+}
@@ -0,0 +1,100 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// getConfigRevisions returns the recorded configuration revisions of the entity at the given path.
+func (r *ProtocolLXD) getConfigRevisions(path string) ([]api.ConfigRevision, error) {
+	err := r.CheckExtension("config_history")
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []api.ConfigRevision{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/history?recursion=1", path), nil, "", &revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// rollbackConfigRevision rolls the entity at the given path back to the given configuration revision.
+func (r *ProtocolLXD) rollbackConfigRevision(path string, revision int64) error {
+	err := r.CheckExtension("config_history")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query("POST", fmt.Sprintf("%s/history/%d", path, revision), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetInstanceConfigRevisions returns the recorded configuration revisions of the instance.
+func (r *ProtocolLXD) GetInstanceConfigRevisions(name string) ([]api.ConfigRevision, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.getConfigRevisions(fmt.Sprintf("%s/%s", path, url.PathEscape(name)))
+}
+
+// RollbackInstanceConfigRevision rolls the instance back to the given configuration revision.
+func (r *ProtocolLXD) RollbackInstanceConfigRevision(name string, revision int64) (Operation, error) {
+	err := r.CheckExtension("config_history")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/history/%d", path, url.PathEscape(name), revision), nil, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// GetProfileConfigRevisions returns the recorded configuration revisions of the profile.
+func (r *ProtocolLXD) GetProfileConfigRevisions(name string) ([]api.ConfigRevision, error) {
+	return r.getConfigRevisions(fmt.Sprintf("/profiles/%s", url.PathEscape(name)))
+}
+
+// RollbackProfileConfigRevision rolls the profile back to the given configuration revision.
+func (r *ProtocolLXD) RollbackProfileConfigRevision(name string, revision int64) error {
+	return r.rollbackConfigRevision(fmt.Sprintf("/profiles/%s", url.PathEscape(name)), revision)
+}
+
+// GetNetworkConfigRevisions returns the recorded configuration revisions of the network.
+func (r *ProtocolLXD) GetNetworkConfigRevisions(name string) ([]api.ConfigRevision, error) {
+	return r.getConfigRevisions(fmt.Sprintf("/networks/%s", url.PathEscape(name)))
+}
+
+// RollbackNetworkConfigRevision rolls the network back to the given configuration revision.
+func (r *ProtocolLXD) RollbackNetworkConfigRevision(name string, revision int64) error {
+	return r.rollbackConfigRevision(fmt.Sprintf("/networks/%s", url.PathEscape(name)), revision)
+}
+
+// GetStoragePoolConfigRevisions returns the recorded configuration revisions of the storage pool.
+func (r *ProtocolLXD) GetStoragePoolConfigRevisions(name string) ([]api.ConfigRevision, error) {
+	return r.getConfigRevisions(fmt.Sprintf("/storage-pools/%s", url.PathEscape(name)))
+}
+
+// RollbackStoragePoolConfigRevision rolls the storage pool back to the given configuration revision.
+func (r *ProtocolLXD) RollbackStoragePoolConfigRevision(name string, revision int64) error {
+	return r.rollbackConfigRevision(fmt.Sprintf("/storage-pools/%s", url.PathEscape(name)), revision)
+}
@@ -431,10 +431,10 @@ func (r *ProtocolLXD) CopyContainer(source InstanceServer, container api.Contain
 		target := api.ContainerPostTarget{}
 		target.Operation = opAPI.ID
 		target.Websockets = targetSecrets
-		target.Certificate = info.Certificate
+		target.Certificate = info.MigrationCertificate
 		sourceReq.Target = &target
 
-		return r.tryMigrateContainer(source, container.Name, sourceReq, info.Addresses)
+		return r.tryMigrateContainer(source, container.Name, sourceReq, info.MigrationAddresses)
 	}
 
 	// Get source server connection information
@@ -501,9 +501,9 @@ func (r *ProtocolLXD) CopyContainer(source InstanceServer, container api.Contain
 	req.Source.Mode = "pull"
 	req.Source.Operation = opAPI.ID
 	req.Source.Websockets = sourceSecrets
-	req.Source.Certificate = info.Certificate
+	req.Source.Certificate = info.MigrationCertificate
 
-	return r.tryCreateContainer(req, info.Addresses)
+	return r.tryCreateContainer(req, info.MigrationAddresses)
 }
 
 // UpdateContainer updates the container definition.
@@ -1136,10 +1136,10 @@ func (r *ProtocolLXD) CopyContainerSnapshot(source InstanceServer, containerName
 		target := api.ContainerPostTarget{}
 		target.Operation = opAPI.ID
 		target.Websockets = targetSecrets
-		target.Certificate = info.Certificate
+		target.Certificate = info.MigrationCertificate
 		sourceReq.Target = &target
 
-		return r.tryMigrateContainerSnapshot(source, cName, sName, sourceReq, info.Addresses)
+		return r.tryMigrateContainerSnapshot(source, cName, sName, sourceReq, info.MigrationAddresses)
 	}
 
 	// Get source server connection information
@@ -1206,9 +1206,9 @@ func (r *ProtocolLXD) CopyContainerSnapshot(source InstanceServer, containerName
 	req.Source.Mode = "pull"
 	req.Source.Operation = opAPI.ID
 	req.Source.Websockets = sourceSecrets
-	req.Source.Certificate = info.Certificate
+	req.Source.Certificate = info.MigrationCertificate
 
-	return r.tryCreateContainer(req, info.Addresses)
+	return r.tryCreateContainer(req, info.MigrationAddresses)
 }
 
 // RenameContainerSnapshot requests that LXD renames the snapshot.
@@ -398,6 +398,13 @@ func (r *ProtocolLXD) CreateImage(image api.ImagesPost, args *ImageCreateArgs) (
 		}
 	}
 
+	if image.CompressionLevel != 0 || image.Reproducible {
+		err := r.CheckExtension("image_publish_reproducible")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the JSON based request
 	if args == nil {
 		op, _, err := r.queryOperation("POST", "/images", image, "", true)
@@ -991,6 +998,38 @@ func (r *ProtocolLXD) RenameImageAlias(name string, alias api.ImageAliasesEntryP
 	return nil
 }
 
+// PromoteImageAlias changes the target of an image alias to the target of one of its channels.
+func (r *ProtocolLXD) PromoteImageAlias(name string, channel string) error {
+	err := r.CheckExtension("image_alias_channels")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("POST", fmt.Sprintf("/images/aliases/%s", url.PathEscape(name)), api.ImageAliasesEntryPost{Promote: channel}, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RollbackImageAlias restores the target an image alias had before its last change.
+func (r *ProtocolLXD) RollbackImageAlias(name string) error {
+	err := r.CheckExtension("image_alias_channels")
+	if err != nil {
+		return err
+	}
+
+	// Send the request
+	_, _, err = r.query("POST", fmt.Sprintf("/images/aliases/%s", url.PathEscape(name)), api.ImageAliasesEntryPost{Rollback: true}, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // DeleteImageAlias removes an alias from the LXD image store.
 func (r *ProtocolLXD) DeleteImageAlias(name string) error {
 	// Send the request
@@ -0,0 +1,163 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetInstancePoolNames returns a list of instance pool names.
+func (r *ProtocolLXD) GetInstancePoolNames() ([]string, error) {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/instance-pools"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetInstancePools returns a list of instance pool structs.
+func (r *ProtocolLXD) GetInstancePools() ([]api.InstancePool, error) {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return nil, err
+	}
+
+	pools := []api.InstancePool{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/instance-pools?recursion=1", nil, "", &pools)
+	if err != nil {
+		return nil, err
+	}
+
+	return pools, nil
+}
+
+// GetInstancePool returns an instance pool entry for the provided name.
+func (r *ProtocolLXD) GetInstancePool(name string) (*api.InstancePool, string, error) {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return nil, "", err
+	}
+
+	pool := api.InstancePool{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/instance-pools/%s", url.PathEscape(name)), nil, "", &pool)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &pool, etag, nil
+}
+
+// GetInstancePoolInstances returns the standby and acquired instances of an instance pool.
+func (r *ProtocolLXD) GetInstancePoolInstances(name string) ([]api.InstancePoolInstance, error) {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return nil, err
+	}
+
+	instances := []api.InstancePoolInstance{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", fmt.Sprintf("/instance-pools/%s/instances", url.PathEscape(name)), nil, "", &instances)
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// CreateInstancePool defines a new instance pool using the provided struct.
+func (r *ProtocolLXD) CreateInstancePool(pool api.InstancePoolsPost) error {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/instance-pools", pool, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateInstancePool updates the instance pool to match the provided struct.
+func (r *ProtocolLXD) UpdateInstancePool(name string, pool api.InstancePoolPut, ETag string) error {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", fmt.Sprintf("/instance-pools/%s", url.PathEscape(name)), pool, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteInstancePool deletes an existing instance pool.
+func (r *ProtocolLXD) DeleteInstancePool(name string) error {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/instance-pools/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AcquireInstancePoolInstance hands out a standby instance of an instance pool.
+func (r *ProtocolLXD) AcquireInstancePoolInstance(name string) (*api.InstancePoolInstance, error) {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return nil, err
+	}
+
+	instance := api.InstancePoolInstance{}
+
+	// Send the request.
+	_, err = r.queryStruct("POST", fmt.Sprintf("/instance-pools/%s/acquire", url.PathEscape(name)), nil, "", &instance)
+	if err != nil {
+		return nil, err
+	}
+
+	return &instance, nil
+}
+
+// ReleaseInstancePoolInstance deletes an instance acquired from an instance pool.
+func (r *ProtocolLXD) ReleaseInstancePoolInstance(name string, release api.InstancePoolReleasePost) error {
+	err := r.CheckExtension("instance_pools")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", fmt.Sprintf("/instance-pools/%s/release", url.PathEscape(name)), release, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
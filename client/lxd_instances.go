@@ -10,7 +10,9 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -700,6 +702,13 @@ func (r *ProtocolLXD) CreateInstance(instance api.InstancesPost) (Operation, err
 		}
 	}
 
+	if instance.Resources != nil {
+		err := r.CheckExtension("instances_create_resources")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", path, instance, "", true)
 	if err != nil {
@@ -960,10 +969,10 @@ func (r *ProtocolLXD) CopyInstance(source InstanceServer, instance api.Instance,
 		target := api.InstancePostTarget{}
 		target.Operation = opAPI.ID
 		target.Websockets = targetSecrets
-		target.Certificate = info.Certificate
+		target.Certificate = info.MigrationCertificate
 		sourceReq.Target = &target
 
-		return r.tryMigrateInstance(source, instance.Name, sourceReq, info.Addresses)
+		return r.tryMigrateInstance(source, instance.Name, sourceReq, info.MigrationAddresses)
 	}
 
 	// Get source server connection information
@@ -1030,9 +1039,9 @@ func (r *ProtocolLXD) CopyInstance(source InstanceServer, instance api.Instance,
 	req.Source.Mode = "pull"
 	req.Source.Operation = opAPI.ID
 	req.Source.Websockets = sourceSecrets
-	req.Source.Certificate = info.Certificate
+	req.Source.Certificate = info.MigrationCertificate
 
-	return r.tryCreateInstance(req, info.Addresses, op)
+	return r.tryCreateInstance(req, info.MigrationAddresses, op)
 }
 
 // UpdateInstance updates the instance definition.
@@ -1999,10 +2008,10 @@ func (r *ProtocolLXD) CopyInstanceSnapshot(source InstanceServer, instanceName s
 		target := api.InstancePostTarget{}
 		target.Operation = opAPI.ID
 		target.Websockets = targetSecrets
-		target.Certificate = info.Certificate
+		target.Certificate = info.MigrationCertificate
 		sourceReq.Target = &target
 
-		return r.tryMigrateInstanceSnapshot(source, cName, sName, sourceReq, info.Addresses)
+		return r.tryMigrateInstanceSnapshot(source, cName, sName, sourceReq, info.MigrationAddresses)
 	}
 
 	// Get source server connection information
@@ -2069,9 +2078,9 @@ func (r *ProtocolLXD) CopyInstanceSnapshot(source InstanceServer, instanceName s
 	req.Source.Mode = "pull"
 	req.Source.Operation = opAPI.ID
 	req.Source.Websockets = sourceSecrets
-	req.Source.Certificate = info.Certificate
+	req.Source.Certificate = info.MigrationCertificate
 
-	return r.tryCreateInstance(req, info.Addresses, op)
+	return r.tryCreateInstance(req, info.MigrationAddresses, op)
 }
 
 // RenameInstanceSnapshot requests that LXD renames the snapshot.
@@ -2234,6 +2243,271 @@ func (r *ProtocolLXD) GetInstanceState(name string) (*api.InstanceState, string,
 	return &state, etag, nil
 }
 
+// GetInstanceUsage returns the resource usage history of the instance over the given period.
+// A zero period returns all the samples that are kept.
+func (r *ProtocolLXD) GetInstanceUsage(name string, period time.Duration) (*api.InstanceUsage, error) {
+	err := r.CheckExtension("instance_usage")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := fmt.Sprintf("%s/%s/usage", path, url.PathEscape(name))
+	if period > 0 {
+		uri = fmt.Sprintf("%s?period=%s", uri, url.QueryEscape(period.String()))
+	}
+
+	usage := api.InstanceUsage{}
+
+	_, err = r.queryStruct("GET", uri, nil, "", &usage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+// GetInstanceTopology returns how the instance is placed on the host CPUs, NUMA nodes, disks and network cards.
+func (r *ProtocolLXD) GetInstanceTopology(name string) (*api.InstanceTopology, error) {
+	err := r.CheckExtension("instances_topology")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	topology := api.InstanceTopology{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/topology", path, url.PathEscape(name)), nil, "", &topology)
+	if err != nil {
+		return nil, err
+	}
+
+	return &topology, nil
+}
+
+// GetInstanceCommands returns the commands queued to run in the instance.
+func (r *ProtocolLXD) GetInstanceCommands(name string) ([]api.InstanceCommand, error) {
+	err := r.CheckExtension("instance_commands")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := []api.InstanceCommand{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/commands?recursion=1", path, url.PathEscape(name)), nil, "", &commands)
+	if err != nil {
+		return nil, err
+	}
+
+	return commands, nil
+}
+
+// GetInstanceCommand returns the queued command with the given ID and its result.
+func (r *ProtocolLXD) GetInstanceCommand(name string, id string) (*api.InstanceCommand, error) {
+	err := r.CheckExtension("instance_commands")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	command := api.InstanceCommand{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/commands/%s", path, url.PathEscape(name), url.PathEscape(id)), nil, "", &command)
+	if err != nil {
+		return nil, err
+	}
+
+	return &command, nil
+}
+
+// CreateInstanceCommand queues a command to run in the instance and returns it.
+func (r *ProtocolLXD) CreateInstanceCommand(name string, command api.InstanceCommandsPost) (*api.InstanceCommand, error) {
+	err := r.CheckExtension("instance_commands")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	queued := api.InstanceCommand{}
+
+	_, err = r.queryStruct("POST", fmt.Sprintf("%s/%s/commands", path, url.PathEscape(name)), command, "", &queued)
+	if err != nil {
+		return nil, err
+	}
+
+	return &queued, nil
+}
+
+// DeleteInstanceCommand removes a queued command and its recorded output.
+func (r *ProtocolLXD) DeleteInstanceCommand(name string, id string) error {
+	err := r.CheckExtension("instance_commands")
+	if err != nil {
+		return err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query("DELETE", fmt.Sprintf("%s/%s/commands/%s", path, url.PathEscape(name), url.PathEscape(id)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetInstanceDiskEncryptionKeys returns the recovery keys escrowed by the instance for its encrypted block devices.
+func (r *ProtocolLXD) GetInstanceDiskEncryptionKeys(name string) ([]api.InstanceDiskEncryptionKey, error) {
+	err := r.CheckExtension("instances_disk_encryption")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []api.InstanceDiskEncryptionKey{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/disk-encryption-keys?recursion=1", path, url.PathEscape(name)), nil, "", &keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// DeleteInstanceDiskEncryptionKey removes the recovery key escrowed by the instance for the given block device.
+func (r *ProtocolLXD) DeleteInstanceDiskEncryptionKey(name string, device string) error {
+	err := r.CheckExtension("instances_disk_encryption")
+	if err != nil {
+		return err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query("DELETE", fmt.Sprintf("%s/%s/disk-encryption-keys/%s", path, url.PathEscape(name), url.PathEscape(device)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetInstanceFirewall returns the host firewall rules applied for the devices of the instance with their counters.
+func (r *ProtocolLXD) GetInstanceFirewall(name string) (*api.InstanceFirewall, error) {
+	err := r.CheckExtension("instance_firewall_rules")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	firewall := api.InstanceFirewall{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/firewall", path, url.PathEscape(name)), nil, "", &firewall)
+	if err != nil {
+		return nil, err
+	}
+
+	return &firewall, nil
+}
+
+// GetInstanceLease returns the lease of an ephemeral instance that has a TTL.
+func (r *ProtocolLXD) GetInstanceLease(name string) (*api.InstanceLease, error) {
+	err := r.CheckExtension("instance_ephemeral_ttl")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	lease := api.InstanceLease{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/lease", path, url.PathEscape(name)), nil, "", &lease)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lease, nil
+}
+
+// RenewInstanceLease records activity on an ephemeral instance that has a TTL, which postpones its deletion.
+func (r *ProtocolLXD) RenewInstanceLease(name string) (*api.InstanceLease, error) {
+	err := r.CheckExtension("instance_ephemeral_ttl")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	lease := api.InstanceLease{}
+
+	_, err = r.queryStruct("POST", fmt.Sprintf("%s/%s/lease", path, url.PathEscape(name)), nil, "", &lease)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lease, nil
+}
+
+// RemapInstance allocates a new idmap for a stopped container and shifts its filesystem to it.
+func (r *ProtocolLXD) RemapInstance(name string) (Operation, error) {
+	err := r.CheckExtension("project_idmap_ranges")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/remap", path, url.PathEscape(name)), nil, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // UpdateInstanceState updates the instance to match the requested state.
 func (r *ProtocolLXD) UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (Operation, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2324,6 +2598,47 @@ func (r *ProtocolLXD) DeleteInstanceLogfile(name string, filename string) error
 	return nil
 }
 
+// GetInstanceLogsStream returns a websocket on which the log records of the instance are sent.
+//
+// Each message is a JSON encoded api.InstanceLogRecord. The websocket is closed by the server once the past
+// records have been sent, unless following the logs.
+func (r *ProtocolLXD) GetInstanceLogsStream(name string, args InstanceLogsStreamArgs) (*websocket.Conn, error) {
+	err := r.CheckExtension("instance_logs_stream")
+	if err != nil {
+		return nil, err
+	}
+
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	if len(args.Sources) > 0 {
+		values.Set("sources", strings.Join(args.Sources, ","))
+	}
+
+	if args.Lines >= 0 {
+		values.Set("lines", strconv.Itoa(args.Lines))
+	}
+
+	if args.Follow {
+		values.Set("follow", "true")
+	}
+
+	uri := fmt.Sprintf("%s/%s/logs/stream", path, url.PathEscape(name))
+	if len(values) > 0 {
+		uri += "?" + values.Encode()
+	}
+
+	uri, err = r.setQueryAttributes(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.websocket(uri)
+}
+
 // getInstanceExecOutputLogFile returns the content of the requested exec logfile.
 //
 // Note that it's the caller's responsibility to close the returned ReadCloser.
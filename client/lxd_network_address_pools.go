@@ -0,0 +1,129 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetNetworkAddressPoolNames returns a list of network address pool names.
+func (r *ProtocolLXD) GetNetworkAddressPoolNames() ([]string, error) {
+	err := r.CheckExtension("network_address_pools")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/network-address-pools"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetNetworkAddressPools returns a list of network address pool structs.
+func (r *ProtocolLXD) GetNetworkAddressPools() ([]api.NetworkAddressPool, error) {
+	err := r.CheckExtension("network_address_pools")
+	if err != nil {
+		return nil, err
+	}
+
+	pools := []api.NetworkAddressPool{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/network-address-pools?recursion=1", nil, "", &pools)
+	if err != nil {
+		return nil, err
+	}
+
+	return pools, nil
+}
+
+// GetNetworkAddressPool returns a network address pool entry for the provided name.
+func (r *ProtocolLXD) GetNetworkAddressPool(name string) (*api.NetworkAddressPool, string, error) {
+	err := r.CheckExtension("network_address_pools")
+	if err != nil {
+		return nil, "", err
+	}
+
+	pool := api.NetworkAddressPool{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/network-address-pools/%s", url.PathEscape(name)), nil, "", &pool)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &pool, etag, nil
+}
+
+// GetNetworkAddressPoolAllocations returns the addresses allocated from a network address pool.
+func (r *ProtocolLXD) GetNetworkAddressPoolAllocations(name string) ([]api.NetworkAddressPoolAllocation, error) {
+	err := r.CheckExtension("network_address_pools")
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := []api.NetworkAddressPoolAllocation{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", fmt.Sprintf("/network-address-pools/%s/allocations", url.PathEscape(name)), nil, "", &allocations)
+	if err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+// CreateNetworkAddressPool defines a new network address pool using the provided struct.
+func (r *ProtocolLXD) CreateNetworkAddressPool(pool api.NetworkAddressPoolsPost) error {
+	err := r.CheckExtension("network_address_pools")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/network-address-pools", pool, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkAddressPool updates the network address pool to match the provided struct.
+func (r *ProtocolLXD) UpdateNetworkAddressPool(name string, pool api.NetworkAddressPoolPut, ETag string) error {
+	err := r.CheckExtension("network_address_pools")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", fmt.Sprintf("/network-address-pools/%s", url.PathEscape(name)), pool, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkAddressPool deletes an existing network address pool.
+func (r *ProtocolLXD) DeleteNetworkAddressPool(name string) error {
+	err := r.CheckExtension("network_address_pools")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/network-address-pools/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -98,6 +98,24 @@ func (r *ProtocolLXD) GetNetworkState(name string) (*api.NetworkState, error) {
 	return &state, nil
 }
 
+// GetNetworkMTU returns the MTU check of the network.
+func (r *ProtocolLXD) GetNetworkMTU(name string) (*api.NetworkMTU, error) {
+	err := r.CheckExtension("network_mtu_check")
+	if err != nil {
+		return nil, err
+	}
+
+	check := api.NetworkMTU{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("/networks/%s/mtu", url.PathEscape(name)), nil, "", &check)
+	if err != nil {
+		return nil, err
+	}
+
+	return &check, nil
+}
+
 // CreateNetwork defines a new network using the provided Network struct.
 func (r *ProtocolLXD) CreateNetwork(network api.NetworksPost) error {
 	err := r.CheckExtension("network")
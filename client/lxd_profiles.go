@@ -92,3 +92,21 @@ func (r *ProtocolLXD) DeleteProfile(name string) error {
 
 	return nil
 }
+
+// GetProfileDrift returns the instances using the profile that are outdated or override some of its configuration.
+func (r *ProtocolLXD) GetProfileDrift(name string) ([]api.ProfileInstanceDrift, error) {
+	err := r.CheckExtension("profile_drift")
+	if err != nil {
+		return nil, err
+	}
+
+	drift := []api.ProfileInstanceDrift{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("/profiles/%s/drift", url.PathEscape(name)), nil, "", &drift)
+	if err != nil {
+		return nil, err
+	}
+
+	return drift, nil
+}
@@ -82,6 +82,24 @@ func (r *ProtocolLXD) GetProjectState(name string) (*api.ProjectState, error) {
 	return &projectState, nil
 }
 
+// GetProjectIdmap returns the range of host IDs reserved for the project and those used by its containers.
+func (r *ProtocolLXD) GetProjectIdmap(name string) (*api.ProjectIdmap, error) {
+	err := r.CheckExtension("project_idmap_ranges")
+	if err != nil {
+		return nil, err
+	}
+
+	projectIdmap := api.ProjectIdmap{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("/projects/%s/idmap", url.PathEscape(name)), nil, "", &projectIdmap)
+	if err != nil {
+		return nil, err
+	}
+
+	return &projectIdmap, nil
+}
+
 // CreateProject defines a new container project.
 func (r *ProtocolLXD) CreateProject(project api.ProjectsPost) error {
 	err := r.CheckExtension("projects")
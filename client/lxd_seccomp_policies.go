@@ -0,0 +1,111 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetSeccompPolicyNames returns a list of syscall interception policy names.
+func (r *ProtocolLXD) GetSeccompPolicyNames() ([]string, error) {
+	err := r.CheckExtension("seccomp_policies")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/seccomp-policies"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetSeccompPolicies returns a list of syscall interception policy structs.
+func (r *ProtocolLXD) GetSeccompPolicies() ([]api.SeccompPolicy, error) {
+	err := r.CheckExtension("seccomp_policies")
+	if err != nil {
+		return nil, err
+	}
+
+	policies := []api.SeccompPolicy{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/seccomp-policies?recursion=1", nil, "", &policies)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// GetSeccompPolicy returns a syscall interception policy entry for the provided name.
+func (r *ProtocolLXD) GetSeccompPolicy(name string) (*api.SeccompPolicy, string, error) {
+	err := r.CheckExtension("seccomp_policies")
+	if err != nil {
+		return nil, "", err
+	}
+
+	policy := api.SeccompPolicy{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/seccomp-policies/%s", url.PathEscape(name)), nil, "", &policy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &policy, etag, nil
+}
+
+// CreateSeccompPolicy defines a new syscall interception policy using the provided struct.
+func (r *ProtocolLXD) CreateSeccompPolicy(policy api.SeccompPoliciesPost) error {
+	err := r.CheckExtension("seccomp_policies")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/seccomp-policies", policy, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateSeccompPolicy updates the syscall interception policy to match the provided struct.
+func (r *ProtocolLXD) UpdateSeccompPolicy(name string, policy api.SeccompPolicyPut, ETag string) error {
+	err := r.CheckExtension("seccomp_policies")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", fmt.Sprintf("/seccomp-policies/%s", url.PathEscape(name)), policy, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteSeccompPolicy deletes an existing syscall interception policy.
+func (r *ProtocolLXD) DeleteSeccompPolicy(name string) error {
+	err := r.CheckExtension("seccomp_policies")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/seccomp-policies/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
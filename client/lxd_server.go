@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/gorilla/websocket"
 
@@ -98,6 +99,23 @@ func (r *ProtocolLXD) GetServerResources() (*api.Resources, error) {
 	return &resources, nil
 }
 
+// GetMetadataDeviceTypes returns the configuration schema of every device type supported by the server.
+func (r *ProtocolLXD) GetMetadataDeviceTypes() ([]api.MetadataDeviceType, error) {
+	err := r.CheckExtension("device_schemas")
+	if err != nil {
+		return nil, err
+	}
+
+	deviceTypes := []api.MetadataDeviceType{}
+
+	_, err = r.queryStruct("GET", "/metadata/devices", nil, "", &deviceTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return deviceTypes, nil
+}
+
 // UseProject returns a client that will use a specific project.
 func (r *ProtocolLXD) UseProject(name string) InstanceServer {
 	return &ProtocolLXD{
@@ -113,6 +131,7 @@ func (r *ProtocolLXD) UseProject(name string) InstanceServer {
 		requireAuthenticated: r.requireAuthenticated,
 		clusterTarget:        r.clusterTarget,
 		project:              name,
+		operationCallback:    r.operationCallback,
 		eventConns:           make(map[string]*websocket.Conn),  // New project specific listener conns.
 		eventListeners:       make(map[string][]*EventListener), // New project specific listeners.
 		oidcClient:           r.oidcClient,
@@ -139,6 +158,30 @@ func (r *ProtocolLXD) UseTarget(name string) InstanceServer {
 		eventListeners:       make(map[string][]*EventListener), // New target specific listeners.
 		oidcClient:           r.oidcClient,
 		clusterTarget:        name,
+		operationCallback:    r.operationCallback,
+	}
+}
+
+// UseOperationCallback returns a client that asks the server to post the final state of the operations that it
+// starts to the given URL.
+func (r *ProtocolLXD) UseOperationCallback(url string) InstanceServer {
+	return &ProtocolLXD{
+		ctx:                  r.ctx,
+		ctxConnected:         r.ctxConnected,
+		ctxConnectedCancel:   r.ctxConnectedCancel,
+		server:               r.server,
+		http:                 r.http,
+		httpCertificate:      r.httpCertificate,
+		httpBaseURL:          r.httpBaseURL,
+		httpProtocol:         r.httpProtocol,
+		httpUserAgent:        r.httpUserAgent,
+		requireAuthenticated: r.requireAuthenticated,
+		clusterTarget:        r.clusterTarget,
+		project:              r.project,
+		eventConns:           make(map[string]*websocket.Conn),
+		eventListeners:       make(map[string][]*EventListener),
+		oidcClient:           r.oidcClient,
+		operationCallback:    url,
 	}
 }
 
@@ -186,3 +229,45 @@ func (r *ProtocolLXD) GetMetrics() (string, error) {
 
 	return string(content), nil
 }
+
+// GetInventory writes the resource inventory of the server, in the given format ("json" or "csv"), to target.
+func (r *ProtocolLXD) GetInventory(format string, target io.Writer) error {
+	// Check that the server supports it.
+	err := r.CheckExtension("inventory_export")
+	if err != nil {
+		return err
+	}
+
+	// Prepare the request.
+	requestURL, err := r.setQueryAttributes(fmt.Sprintf("%s/1.0/inventory?format=%s", r.httpBaseURL.String(), url.QueryEscape(format)))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := lxdParseResponse(resp)
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf("Bad HTTP status: %d", resp.StatusCode)
+	}
+
+	// Stream the content.
+	_, err = io.Copy(target, resp.Body)
+
+	return err
+}
@@ -0,0 +1,111 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetSSHKeyNames returns a list of SSH key names.
+func (r *ProtocolLXD) GetSSHKeyNames() ([]string, error) {
+	err := r.CheckExtension("ssh_keys")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/ssh-keys"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetSSHKeys returns a list of SSH key structs.
+func (r *ProtocolLXD) GetSSHKeys() ([]api.SSHKey, error) {
+	err := r.CheckExtension("ssh_keys")
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []api.SSHKey{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/ssh-keys?recursion=1", nil, "", &keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetSSHKey returns an SSH key for the provided name.
+func (r *ProtocolLXD) GetSSHKey(name string) (*api.SSHKey, string, error) {
+	err := r.CheckExtension("ssh_keys")
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := api.SSHKey{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/ssh-keys/%s", url.PathEscape(name)), nil, "", &key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &key, etag, nil
+}
+
+// CreateSSHKey defines a new SSH key using the provided struct.
+func (r *ProtocolLXD) CreateSSHKey(key api.SSHKeysPost) error {
+	err := r.CheckExtension("ssh_keys")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/ssh-keys", key, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateSSHKey updates the SSH key to match the provided struct.
+func (r *ProtocolLXD) UpdateSSHKey(name string, key api.SSHKeyPut, ETag string) error {
+	err := r.CheckExtension("ssh_keys")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", fmt.Sprintf("/ssh-keys/%s", url.PathEscape(name)), key, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteSSHKey deletes an existing SSH key.
+func (r *ProtocolLXD) DeleteSSHKey(name string) error {
+	err := r.CheckExtension("ssh_keys")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/ssh-keys/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -715,10 +715,10 @@ func (r *ProtocolLXD) CopyStoragePoolVolume(pool string, source InstanceServer,
 		target := api.StorageVolumePostTarget{}
 		target.Operation = opAPI.ID
 		target.Websockets = targetSecrets
-		target.Certificate = info.Certificate
+		target.Certificate = info.MigrationCertificate
 		sourceReq.Target = &target
 
-		return r.tryMigrateStoragePoolVolume(source, sourcePool, sourceReq, info.Addresses)
+		return r.tryMigrateStoragePoolVolume(source, sourcePool, sourceReq, info.MigrationAddresses)
 	}
 
 	// Get source server connection information
@@ -790,9 +790,9 @@ func (r *ProtocolLXD) CopyStoragePoolVolume(pool string, source InstanceServer,
 	req.Source.Mode = "pull"
 	req.Source.Operation = opAPI.ID
 	req.Source.Websockets = sourceSecrets
-	req.Source.Certificate = info.Certificate
+	req.Source.Certificate = info.MigrationCertificate
 
-	return r.tryCreateStoragePoolVolume(pool, req, info.Addresses)
+	return r.tryCreateStoragePoolVolume(pool, req, info.MigrationAddresses)
 }
 
 // MoveStoragePoolVolume renames or moves an existing storage volume.
@@ -0,0 +1,110 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetTombstones returns the tombstones of recently deleted entities in the current project.
+func (r *ProtocolLXD) GetTombstones() ([]api.Tombstone, error) {
+	err := r.CheckExtension("tombstones")
+	if err != nil {
+		return nil, err
+	}
+
+	tombstones := []api.Tombstone{}
+
+	_, err = r.queryStruct("GET", "/tombstones?recursion=1", nil, "", &tombstones)
+	if err != nil {
+		return nil, err
+	}
+
+	return tombstones, nil
+}
+
+// GetTombstonesAllProjects returns the tombstones of recently deleted entities in all projects.
+func (r *ProtocolLXD) GetTombstonesAllProjects() ([]api.Tombstone, error) {
+	err := r.CheckExtension("tombstones")
+	if err != nil {
+		return nil, err
+	}
+
+	tombstones := []api.Tombstone{}
+
+	v := url.Values{}
+	v.Set("recursion", "1")
+	v.Set("all-projects", "true")
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("/tombstones?%s", v.Encode()), nil, "", &tombstones)
+	if err != nil {
+		return nil, err
+	}
+
+	return tombstones, nil
+}
+
+// GetTombstone returns the tombstone with the given ID.
+func (r *ProtocolLXD) GetTombstone(id int64) (*api.Tombstone, error) {
+	err := r.CheckExtension("tombstones")
+	if err != nil {
+		return nil, err
+	}
+
+	tombstone := api.Tombstone{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("/tombstones/%d", id), nil, "", &tombstone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tombstone, nil
+}
+
+// RestoreTombstone recreates the deleted entity from the given tombstone.
+// Restoring an instance runs as a background operation which this waits for.
+func (r *ProtocolLXD) RestoreTombstone(id int64, tombstone api.TombstonePost) error {
+	err := r.CheckExtension("tombstones")
+	if err != nil {
+		return err
+	}
+
+	resp, _, err := r.query("POST", fmt.Sprintf("/tombstones/%d", id), tombstone, "")
+	if err != nil {
+		return err
+	}
+
+	if resp.Type != api.AsyncResponse {
+		return nil
+	}
+
+	opAPI, err := resp.MetadataAsOperation()
+	if err != nil {
+		return err
+	}
+
+	op := operation{
+		Operation:    *opAPI,
+		r:            r,
+		chActive:     make(chan bool),
+		skipListener: true,
+	}
+
+	return op.Wait()
+}
+
+// DeleteTombstone deletes the tombstone with the given ID.
+func (r *ProtocolLXD) DeleteTombstone(id int64) error {
+	err := r.CheckExtension("tombstones")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query("DELETE", fmt.Sprintf("/tombstones/%d", id), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1146,8 +1146,9 @@ func (c *cmdClusterUpdateCertificate) run(cmd *cobra.Command, args []string) err
 type cmdClusterEvacuateAction struct {
 	global *cmdGlobal
 
-	flagAction string
-	flagForce  bool
+	flagAction           string
+	flagForce            bool
+	flagAllowVersionSkew bool
 }
 
 // Cluster member evacuation.
@@ -1169,6 +1170,7 @@ func (c *cmdClusterEvacuate) command() *cobra.Command {
 
 	cmd.Flags().BoolVar(&c.action.flagForce, "force", false, i18n.G(`Force evacuation without user confirmation`)+"``")
 	cmd.Flags().StringVar(&c.action.flagAction, "action", "", i18n.G(`Force a particular evacuation action`)+"``")
+	cmd.Flags().BoolVar(&c.action.flagAllowVersionSkew, "allow-version-skew", false, i18n.G("Live-migrate instances even across known-unsafe version differences"))
 
 	return cmd
 }
@@ -1190,6 +1192,7 @@ func (c *cmdClusterRestore) command() *cobra.Command {
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Restore cluster member`))
 
 	cmd.Flags().BoolVar(&c.action.flagForce, "force", false, i18n.G(`Force restoration without user confirmation`)+"``")
+	cmd.Flags().BoolVar(&c.action.flagAllowVersionSkew, "allow-version-skew", false, i18n.G("Live-migrate instances even across known-unsafe version differences"))
 
 	return cmd
 }
@@ -1232,8 +1235,9 @@ func (c *cmdClusterEvacuateAction) run(cmd *cobra.Command, args []string) error
 	}
 
 	state := api.ClusterMemberStatePost{
-		Action: cmd.Name(),
-		Mode:   c.flagAction,
+		Action:           cmd.Name(),
+		Mode:             c.flagAction,
+		AllowVersionSkew: c.flagAllowVersionSkew,
 	}
 
 	op, err := resource.server.UpdateClusterMemberState(resource.name, state)
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -78,6 +79,8 @@ type cmdConfigTrustAdd struct {
 	flagProjects   string
 	flagRestricted bool
 	flagType       string
+	flagGenerate   bool
+	flagExpiry     string
 }
 
 func (c *cmdConfigTrustAdd) command() *cobra.Command {
@@ -95,12 +98,21 @@ If the certificate is omitted, a token will be generated and returned. A client
 providing a valid token will have its client certificate added to the trusted list
 and the consumed token will be invalidated. Similar to certificates, tokens can be
 restricted to one or more projects.
+
+With --generate, the server generates a metrics certificate and its key, trusts
+the certificate and returns both. They are written to <name>.crt and <name>.key
+in the current directory.
 `))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc config trust add --type=metrics --generate --name=prometheus --expiry=90d --restricted --projects=default,foo
+    Generate a metrics certificate valid for 90 days and restricted to the "default" and "foo" projects.`))
 
 	cmd.Flags().BoolVar(&c.flagRestricted, "restricted", false, i18n.G("Restrict the certificate to one or more projects"))
 	cmd.Flags().StringVar(&c.flagProjects, "projects", "", i18n.G("List of projects to restrict the certificate to")+"``")
 	cmd.Flags().StringVar(&c.flagName, "name", "", i18n.G("Alternative certificate name")+"``")
 	cmd.Flags().StringVar(&c.flagType, "type", "client", i18n.G("Type of certificate")+"``")
+	cmd.Flags().BoolVar(&c.flagGenerate, "generate", false, i18n.G("Have the server generate the certificate and key (metrics only)"))
+	cmd.Flags().StringVar(&c.flagExpiry, "expiry", "", i18n.G("Expiry of the generated certificate (e.g. 30d, 1y)")+"``")
 
 	cmd.RunE = c.run
 
@@ -119,6 +131,14 @@ func (c *cmdConfigTrustAdd) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Unknown certificate type %q"), c.flagType)
 	}
 
+	if c.flagGenerate && c.flagType != "metrics" {
+		return errors.New(i18n.G("Only metrics certificates can be generated"))
+	}
+
+	if c.flagExpiry != "" && !c.flagGenerate {
+		return errors.New(i18n.G("--expiry can only be used with --generate"))
+	}
+
 	// Parse remote
 	remote := ""
 	if len(args) > 0 {
@@ -145,7 +165,31 @@ func (c *cmdConfigTrustAdd) run(cmd *cobra.Command, args []string) error {
 		useToken = true
 	}
 
-	if useToken {
+	if c.flagGenerate {
+		if !useToken {
+			return errors.New(i18n.G("Can't pass a certificate when using --generate"))
+		}
+
+		cert.Generate = true
+
+		if c.flagName != "" {
+			cert.Name = c.flagName
+		} else {
+			cert.Name, err = c.global.asker.AskString(i18n.G("Please provide client name: "), "", nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		if c.flagExpiry != "" {
+			expiresAt, err := shared.GetExpiry(time.Now(), c.flagExpiry)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Invalid expiry: %w"), err)
+			}
+
+			cert.ExpiresAt = &expiresAt
+		}
+	} else if useToken {
 		// Use token
 		cert.Token = true
 
@@ -198,6 +242,36 @@ func (c *cmdConfigTrustAdd) run(cmd *cobra.Command, args []string) error {
 		cert.Projects = strings.Split(c.flagProjects, ",")
 	}
 
+	if cert.Generate {
+		certFile := cert.Name + ".crt"
+		keyFile := cert.Name + ".key"
+
+		if shared.PathExists(certFile) || shared.PathExists(keyFile) {
+			return fmt.Errorf(i18n.G("Refusing to overwrite existing %q or %q"), certFile, keyFile)
+		}
+
+		generated, err := resource.server.CreateCertificateGenerated(cert)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(certFile, []byte(generated.Certificate), 0644)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(keyFile, []byte(generated.Key), 0600)
+		if err != nil {
+			return err
+		}
+
+		if !c.global.flagQuiet {
+			fmt.Printf(i18n.G("Generated certificate %s (expires %s), written to %s and %s")+"\n", generated.Fingerprint[0:12], generated.ExpiresAt.Local().Format("2006/01/02 15:04 MST"), certFile, keyFile)
+		}
+
+		return nil
+	}
+
 	if cert.Token {
 		op, err := resource.server.CreateCertificateToken(cert)
 		if err != nil {
@@ -32,14 +32,26 @@ func (c *cmdImageAlias) command() *cobra.Command {
 	imageAliasDeleteCmd := cmdImageAliasDelete{global: c.global, image: c.image, imageAlias: c}
 	cmd.AddCommand(imageAliasDeleteCmd.command())
 
+	// Channel
+	imageAliasChannelCmd := cmdImageAliasChannel{global: c.global, image: c.image, imageAlias: c}
+	cmd.AddCommand(imageAliasChannelCmd.command())
+
 	// List
 	imageAliasListCmd := cmdImageAliasList{global: c.global, image: c.image, imageAlias: c}
 	cmd.AddCommand(imageAliasListCmd.command())
 
+	// Promote
+	imageAliasPromoteCmd := cmdImageAliasPromote{global: c.global, image: c.image, imageAlias: c}
+	cmd.AddCommand(imageAliasPromoteCmd.command())
+
 	// Rename
 	imageAliasRenameCmd := cmdImageAliasRename{global: c.global, image: c.image, imageAlias: c}
 	cmd.AddCommand(imageAliasRenameCmd.command())
 
+	// Rollback
+	imageAliasRollbackCmd := cmdImageAliasRollback{global: c.global, image: c.image, imageAlias: c}
+	cmd.AddCommand(imageAliasRollbackCmd.command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -282,3 +294,162 @@ func (c *cmdImageAliasRename) run(cmd *cobra.Command, args []string) error {
 	// Rename the alias
 	return resource.server.RenameImageAlias(resource.name, api.ImageAliasesEntryPost{Name: args[1]})
 }
+
+// Channel.
+type cmdImageAliasChannel struct {
+	global     *cmdGlobal
+	image      *cmdImage
+	imageAlias *cmdImageAlias
+}
+
+func (c *cmdImageAliasChannel) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("channel", i18n.G("[<remote>:]<alias> <channel> [<fingerprint>]"))
+	cmd.Short = i18n.G("Set or unset image alias channels")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Set or unset image alias channels
+
+Channels let an alias track other images (for example a "candidate" image)
+alongside its own target. Without a fingerprint, the channel is removed.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc image alias channel ubuntu candidate 06b86454720d
+    Make the "candidate" channel of the "ubuntu" alias point to image 06b86454720d.`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdImageAliasChannel) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 3)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Alias name missing"))
+	}
+
+	if !resource.server.HasExtension("image_alias_channels") {
+		return fmt.Errorf(i18n.G("The server doesn't support image alias channels"))
+	}
+
+	alias, etag, err := resource.server.GetImageAlias(resource.name)
+	if err != nil {
+		return err
+	}
+
+	channels := alias.Channels
+	if channels == nil {
+		channels = map[string]string{}
+	}
+
+	if len(args) == 3 {
+		channels[args[1]] = args[2]
+	} else {
+		_, ok := channels[args[1]]
+		if !ok {
+			return fmt.Errorf(i18n.G("Channel %q not found"), args[1])
+		}
+
+		delete(channels, args[1])
+	}
+
+	// Update the alias
+	return resource.server.UpdateImageAlias(resource.name, api.ImageAliasesEntryPut{Description: alias.Description, Target: alias.Target, Channels: channels}, etag)
+}
+
+// Promote.
+type cmdImageAliasPromote struct {
+	global     *cmdGlobal
+	image      *cmdImage
+	imageAlias *cmdImageAlias
+}
+
+func (c *cmdImageAliasPromote) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("promote", i18n.G("[<remote>:]<alias> <channel>"))
+	cmd.Short = i18n.G("Point aliases to the image of one of their channels")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Point aliases to the image of one of their channels
+
+The previous target of the alias is kept so that the promotion can be rolled back.`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdImageAliasPromote) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Alias name missing"))
+	}
+
+	// Promote the channel
+	return resource.server.PromoteImageAlias(resource.name, args[1])
+}
+
+// Rollback.
+type cmdImageAliasRollback struct {
+	global     *cmdGlobal
+	image      *cmdImage
+	imageAlias *cmdImageAlias
+}
+
+func (c *cmdImageAliasRollback) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rollback", i18n.G("[<remote>:]<alias>"))
+	cmd.Short = i18n.G("Point aliases back to their previous image")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Point aliases back to their previous image`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdImageAliasRollback) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Alias name missing"))
+	}
+
+	// Roll back the alias
+	return resource.server.RollbackImageAlias(resource.name)
+}
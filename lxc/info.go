@@ -5,6 +5,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -546,6 +547,15 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, name string, showLog bool)
 			fmt.Print(memoryInfo)
 		}
 
+		// Kernel resources usage
+		if inst.State.KernelResources != nil {
+			fmt.Printf("  %s\n", i18n.G("Kernel resources:"))
+			fmt.Printf("    %s: %d\n", i18n.G("Open files"), inst.State.KernelResources.OpenFiles)
+			fmt.Printf("    %s: %d\n", i18n.G("Inotify instances"), inst.State.KernelResources.InotifyInstances)
+			fmt.Printf("    %s: %d\n", i18n.G("Inotify watches"), inst.State.KernelResources.InotifyWatches)
+			fmt.Printf("    %s: %d (%s)\n", i18n.G("Keys"), inst.State.KernelResources.Keys, units.GetByteSizeStringIEC(inst.State.KernelResources.KeysBytes, 2))
+		}
+
 		// Network usage and IP info
 		networkInfo := ""
 		if inst.State.Network != nil {
@@ -588,6 +598,33 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, name string, showLog bool)
 		}
 	}
 
+	// Timing of the last start
+	if inst.State.Boot != nil && len(inst.State.Boot.Phases) > 0 {
+		fmt.Println("\n" + i18n.G("Last boot:"))
+		fmt.Printf("  %s: %s\n", i18n.G("Started"), inst.State.Boot.StartedAt.Local().Format(layout))
+		fmt.Printf("  %s: %s\n", i18n.G("Duration"), time.Duration(inst.State.Boot.Duration).Round(time.Millisecond))
+		fmt.Printf("  %s\n", i18n.G("Phases:"))
+		for _, phase := range inst.State.Boot.Phases {
+			fmt.Printf("    %s: %s\n", phase.Name, time.Duration(phase.Duration).Round(time.Millisecond))
+		}
+	}
+
+	// Encrypted guest block devices
+	if len(inst.State.DiskEncryption) > 0 {
+		fmt.Println("\n" + i18n.G("Disk encryption:"))
+		for _, disk := range inst.State.DiskEncryption {
+			status := i18n.G("locked")
+			if disk.Mapping != "" {
+				status = fmt.Sprintf(i18n.G("unlocked as %s"), disk.Mapping)
+				if disk.Mountpoint != "" {
+					status = fmt.Sprintf(i18n.G("unlocked as %s, mounted on %s"), disk.Mapping, disk.Mountpoint)
+				}
+			}
+
+			fmt.Printf("  %s (%s): %s\n", disk.Device, disk.Type, status)
+		}
+	}
+
 	// List snapshots
 	firstSnapshot := true
 	if len(inst.Snapshots) > 0 {
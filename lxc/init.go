@@ -34,6 +34,7 @@ type cmdInit struct {
 	flagNoProfiles bool
 	flagEmpty      bool
 	flagVM         bool
+	flagImageProj  string
 }
 
 func (c *cmdInit) command() *cobra.Command {
@@ -65,6 +66,7 @@ lxc init ubuntu:24.04 v1 --vm -c limits.cpu=2 -c limits.memory=8GiB -d root,size
 	cmd.Flags().BoolVar(&c.flagNoProfiles, "no-profiles", false, i18n.G("Create the instance with no profiles applied"))
 	cmd.Flags().BoolVar(&c.flagEmpty, "empty", false, i18n.G("Create an empty instance"))
 	cmd.Flags().BoolVar(&c.flagVM, "vm", false, i18n.G("Create a virtual machine"))
+	cmd.Flags().StringVar(&c.flagImageProj, "image-project", "", i18n.G("Project of a local image to clone the instance from")+"``")
 
 	return cmd
 }
@@ -314,7 +316,17 @@ func (c *cmdInit) create(conf *config.Config, args []string) (lxd.InstanceServer
 			image = "default"
 		}
 
-		imgRemote, imgInfo, err := getImgInfo(d, conf, iremote, remote, image, &req.Source)
+		// Use a local image of another project.
+		imgServer := d
+		if c.flagImageProj != "" {
+			if iremote != remote {
+				return nil, "", fmt.Errorf(i18n.G("--image-project can only be used with local images"))
+			}
+
+			imgServer = d.UseProject(c.flagImageProj)
+		}
+
+		imgRemote, imgInfo, err := getImgInfo(imgServer, conf, iremote, remote, image, &req.Source)
 		if err != nil {
 			return nil, "", err
 		}
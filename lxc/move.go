@@ -27,6 +27,7 @@ type cmdMove struct {
 	flagTarget            string
 	flagTargetProject     string
 	flagAllowInconsistent bool
+	flagAllowVersionSkew  bool
 }
 
 func (c *cmdMove) command() *cobra.Command {
@@ -66,6 +67,7 @@ lxc move <instance>/<old snapshot name> <instance>/<new snapshot name>
 	cmd.Flags().StringVar(&c.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 	cmd.Flags().StringVar(&c.flagTargetProject, "target-project", "", i18n.G("Copy to a project different from the source")+"``")
 	cmd.Flags().BoolVar(&c.flagAllowInconsistent, "allow-inconsistent", false, i18n.G("Ignore copy errors for volatile files"))
+	cmd.Flags().BoolVar(&c.flagAllowVersionSkew, "allow-version-skew", false, i18n.G("Live-migrate between cluster members even across known-unsafe version differences"))
 
 	return cmd
 }
@@ -180,7 +182,7 @@ func (c *cmdMove) run(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf(i18n.G("The --mode flag can't be used with --target"))
 			}
 
-			return moveClusterInstance(conf, sourceResource, destResource, c.flagTarget, c.global.flagQuiet, stateful)
+			return moveClusterInstance(conf, sourceResource, destResource, c.flagTarget, c.global.flagQuiet, stateful, c.flagAllowVersionSkew)
 		}
 
 		dest, err := conf.GetInstanceServer(destRemote)
@@ -215,7 +217,7 @@ func (c *cmdMove) run(cmd *cobra.Command, args []string) error {
 				profiles = &[]string{}
 			}
 
-			return moveInstance(conf, sourceResource, destResource, c.flagStorage, c.flagTargetProject, c.flagConfig, c.flagDevice, profiles, c.flagInstanceOnly, stateful)
+			return moveInstance(conf, sourceResource, destResource, c.flagStorage, c.flagTargetProject, c.flagConfig, c.flagDevice, profiles, c.flagInstanceOnly, stateful, c.global.flagQuiet)
 		}
 
 		if source.HasExtension("instance_pool_move") && source.HasExtension("instance_project_move") {
@@ -227,7 +229,7 @@ func (c *cmdMove) run(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf(i18n.G("The --mode flag can't be used with --storage or --target-project"))
 			}
 
-			return moveInstance(conf, sourceResource, destResource, c.flagStorage, c.flagTargetProject, []string{}, []string{}, nil, c.flagInstanceOnly, stateful)
+			return moveInstance(conf, sourceResource, destResource, c.flagStorage, c.flagTargetProject, []string{}, []string{}, nil, c.flagInstanceOnly, stateful, c.global.flagQuiet)
 		}
 	}
 
@@ -262,7 +264,7 @@ func (c *cmdMove) run(cmd *cobra.Command, args []string) error {
 }
 
 // Move an instance using special POST /instances/<name>?target=<member> API.
-func moveClusterInstance(conf *config.Config, sourceResource string, destResource string, target string, quiet bool, stateful bool) error {
+func moveClusterInstance(conf *config.Config, sourceResource string, destResource string, target string, quiet bool, stateful bool, allowVersionSkew bool) error {
 	// Parse the source.
 	sourceRemote, sourceName, err := conf.ParseRemote(sourceResource)
 	if err != nil {
@@ -299,9 +301,10 @@ func moveClusterInstance(conf *config.Config, sourceResource string, destResourc
 	// The migrate API will do the right thing when passed a target.
 	source = source.UseTarget(target)
 	req := api.InstancePost{
-		Name:      destName,
-		Migration: true,
-		Live:      stateful,
+		Name:             destName,
+		Migration:        true,
+		Live:             stateful,
+		AllowVersionSkew: allowVersionSkew,
 	}
 
 	op, err := source.MigrateInstance(sourceName, req)
@@ -338,7 +341,7 @@ func moveClusterInstance(conf *config.Config, sourceResource string, destResourc
 }
 
 // Move an instance between pools and projects using special POST /instances/<name> API.
-func moveInstance(conf *config.Config, sourceResource string, destResource string, storage string, targetProject string, config []string, devices []string, profiles *[]string, instanceOnly bool, stateful bool) error {
+func moveInstance(conf *config.Config, sourceResource string, destResource string, storage string, targetProject string, config []string, devices []string, profiles *[]string, instanceOnly bool, stateful bool, quiet bool) error {
 	// Parse the source.
 	sourceRemote, sourceName, err := conf.ParseRemote(sourceResource)
 	if err != nil {
@@ -422,11 +425,26 @@ func moveInstance(conf *config.Config, sourceResource string, destResource strin
 		return fmt.Errorf(i18n.G("Migration API failure: %w"), err)
 	}
 
+	// Watch the background operation
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Moving instance: %s"),
+		Quiet:  quiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
 	err = op.Wait()
 	if err != nil {
+		progress.Done("")
 		return fmt.Errorf(i18n.G("Migration operation failure: %w"), err)
 	}
 
+	progress.Done("")
+
 	return nil
 }
 
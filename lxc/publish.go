@@ -20,10 +20,12 @@ type cmdPublish struct {
 
 	flagAliases              []string
 	flagCompressionAlgorithm string
+	flagCompressionLevel     int
 	flagExpiresAt            string
 	flagMakePublic           bool
 	flagForce                bool
 	flagReuse                bool
+	flagReproducible         bool
 }
 
 func (c *cmdPublish) command() *cobra.Command {
@@ -38,6 +40,8 @@ func (c *cmdPublish) command() *cobra.Command {
 	cmd.Flags().StringArrayVar(&c.flagAliases, "alias", nil, i18n.G("New alias to define at target")+"``")
 	cmd.Flags().BoolVarP(&c.flagForce, "force", "f", false, i18n.G("Stop the instance if currently running"))
 	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", i18n.G("Compression algorithm to use (`none` for uncompressed)"))
+	cmd.Flags().IntVar(&c.flagCompressionLevel, "compression-level", 0, i18n.G("Compression level to use (default level of the compression algorithm if not set)")+"``")
+	cmd.Flags().BoolVar(&c.flagReproducible, "reproducible", false, i18n.G("Generate the image deterministically, so that the same instance content results in the same fingerprint"))
 	cmd.Flags().StringVar(&c.flagExpiresAt, "expire", "", i18n.G("Image expiration date (format: rfc3339)")+"``")
 	cmd.Flags().BoolVar(&c.flagReuse, "reuse", false, i18n.G("If the image alias already exists, delete and create a new one"))
 
@@ -206,6 +210,8 @@ func (c *cmdPublish) run(cmd *cobra.Command, args []string) error {
 			Name: cName,
 		},
 		CompressionAlgorithm: c.flagCompressionAlgorithm,
+		CompressionLevel:     c.flagCompressionLevel,
+		Reproducible:         c.flagReproducible,
 	}
 
 	req.Properties = properties
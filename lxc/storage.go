@@ -472,6 +472,8 @@ func (c *cmdStorageInfo) run(cmd *cobra.Command, args []string) error {
 	descriptionstring := i18n.G("description")
 	totalspacestring := i18n.G("total space")
 	spaceusedstring := i18n.G("space used")
+	growthstring := i18n.G("growth per day")
+	fullstring := i18n.G("full in")
 
 	// Initialize the usedby map
 	poolusedby[usedbystring] = make(map[string][]string)
@@ -554,6 +556,20 @@ func (c *cmdStorageInfo) run(cmd *cobra.Command, args []string) error {
 		poolinfo[infostring][spaceusedstring] = units.GetByteSizeStringIEC(int64(res.Space.Used), 2)
 	}
 
+	if res.Forecast != nil {
+		if c.flagBytes {
+			poolinfo[infostring][growthstring] = strconv.FormatInt(res.Forecast.GrowthPerDay, 10)
+		} else if res.Forecast.GrowthPerDay < 0 {
+			poolinfo[infostring][growthstring] = "-" + units.GetByteSizeStringIEC(-res.Forecast.GrowthPerDay, 2)
+		} else {
+			poolinfo[infostring][growthstring] = units.GetByteSizeStringIEC(res.Forecast.GrowthPerDay, 2)
+		}
+
+		if res.Forecast.DaysUntilFull >= 0 {
+			poolinfo[infostring][fullstring] = fmt.Sprintf(i18n.G("~%d days"), res.Forecast.DaysUntilFull)
+		}
+	}
+
 	poolinfodata, err := yaml.Marshal(poolinfo)
 	if err != nil {
 		return err
@@ -1335,6 +1335,12 @@ func (c *cmdStorageVolumeInfo) run(cmd *cobra.Command, args []string) error {
 				row = append(row, " ")
 			}
 
+			if snap.Usage != nil {
+				row = append(row, units.GetByteSizeStringIEC(snap.Usage.Unique, 2), units.GetByteSizeStringIEC(snap.Usage.Shared, 2))
+			} else {
+				row = append(row, " ", " ")
+			}
+
 			firstSnapshot = false
 			snapData = append(snapData, row)
 		}
@@ -1344,6 +1350,8 @@ func (c *cmdStorageVolumeInfo) run(cmd *cobra.Command, args []string) error {
 			i18n.G("Name"),
 			i18n.G("Description"),
 			i18n.G("Expires at"),
+			i18n.G("Unique"),
+			i18n.G("Shared"),
 		}
 
 		_ = cli.RenderTable(cli.TableFormatTable, snapHeader, snapData, volSnapshots)
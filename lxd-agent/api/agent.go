@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // API10Put contains the fields which are needed for the lxd-agent to connect to LXD.
 type API10Put struct {
 	// Context ID
@@ -17,4 +21,15 @@ type API10Put struct {
 	// Whether or not to enable devlxd
 	// Example: true
 	Devlxd bool `json:"devlxd" yaml:"devlxd"`
+
+	// Whether or not to use the PTP clock of the hypervisor
+	// Example: true
+	PTP bool `json:"ptp" yaml:"ptp"`
+}
+
+// ClockPut contains the time the lxd-agent should set the system clock of the guest to.
+type ClockPut struct {
+	// Current time
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	Time time.Time `json:"time" yaml:"time"`
 }
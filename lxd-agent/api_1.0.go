@@ -13,6 +13,7 @@ import (
 	"github.com/canonical/lxd/client"
 	agentAPI "github.com/canonical/lxd/lxd-agent/api"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
 	lxdvsock "github.com/canonical/lxd/lxd/vsock"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -27,8 +28,10 @@ var api10Cmd = APIEndpoint{
 
 var api10 = []APIEndpoint{
 	api10Cmd,
+	clockCmd,
 	execCmd,
 	eventsCmd,
+	journalCmd,
 	metricsCmd,
 	operationsCmd,
 	operationCmd,
@@ -89,6 +92,14 @@ func setConnectionInfo(d *Daemon, rd io.Reader) error {
 	d.devlxdEnabled = data.Devlxd
 	d.devlxdMu.Unlock()
 
+	// Expose the clock of the hypervisor as a PTP device so that time daemons can use it as a reference clock.
+	if data.PTP {
+		err = util.LoadModule("ptp_kvm")
+		if err != nil {
+			logger.Warn("Failed loading the ptp_kvm kernel module", logger.Ctx{"err": err})
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/sys/unix"
+
+	agentAPI "github.com/canonical/lxd/lxd-agent/api"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+var clockCmd = APIEndpoint{
+	Name: "clock",
+	Path: "clock",
+
+	Put: APIEndpointAction{Handler: clockPut},
+}
+
+// clockPut sets the system clock of the guest, used to resynchronize it after the VM was paused, migrated or
+// the host was suspended.
+func clockPut(d *Daemon, r *http.Request) response.Response {
+	req := agentAPI.ClockPut{}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Time.IsZero() {
+		return response.BadRequest(fmt.Errorf("Time is required"))
+	}
+
+	tv := unix.NsecToTimeval(req.Time.UnixNano())
+	err = unix.Settimeofday(&tv)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed setting system clock: %w", err))
+	}
+
+	logger.Info("Synchronized system clock", logger.Ctx{"time": req.Time})
+
+	return response.EmptySyncResponse
+}
@@ -216,6 +216,49 @@ var devlxdDevicesGet = devLxdHandler{"/1.0/devices", func(d *Daemon, w http.Resp
 	return okResponse(devices, "json")
 }}
 
+var devlxdCredentialsGet = devLxdHandler{"/1.0/credentials", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+	client, err := getVsockClient(d)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
+	}
+
+	defer client.Disconnect()
+
+	resp, _, err := client.RawQuery("GET", "/1.0/credentials", nil, "")
+	if err != nil {
+		return smartResponse(err)
+	}
+
+	var credentials api.DevLXDCredentials
+
+	err = resp.MetadataAsStruct(&credentials)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed parsing response from LXD: %w", err))
+	}
+
+	return okResponse(credentials, "json")
+}}
+
+var devlxdDiskEncryptionKeysPost = devLxdHandler{"/1.0/disk-encryption-keys", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
+	if r.Method != "POST" {
+		return &devLxdResponse{fmt.Sprintf("method %q not allowed", r.Method), http.StatusBadRequest, "raw"}
+	}
+
+	client, err := getVsockClient(d)
+	if err != nil {
+		return smartResponse(fmt.Errorf("Failed connecting to LXD over vsock: %w", err))
+	}
+
+	defer client.Disconnect()
+
+	_, _, err = client.RawQuery(r.Method, "/1.0/disk-encryption-keys", r.Body, "")
+	if err != nil {
+		return smartResponse(err)
+	}
+
+	return okResponse("", "raw")
+}}
+
 var handlers = []devLxdHandler{
 	{"/", func(d *Daemon, w http.ResponseWriter, r *http.Request) *devLxdResponse {
 		return okResponse([]string{"/1.0"}, "json")
@@ -226,6 +269,8 @@ var handlers = []devLxdHandler{
 	devlxdMetadataGet,
 	devLxdEventsGet,
 	devlxdDevicesGet,
+	devlxdCredentialsGet,
+	devlxdDiskEncryptionKeysPost,
 }
 
 func hoistReq(f func(*Daemon, http.ResponseWriter, *http.Request) *devLxdResponse, d *Daemon) func(http.ResponseWriter, *http.Request) {
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// diskEncryptionMagics maps the signatures found at the start of encrypted block devices to their format.
+var diskEncryptionMagics = []struct {
+	offset int
+	magic  []byte
+	format string
+}{
+	{offset: 0, magic: []byte{'L', 'U', 'K', 'S', 0xba, 0xbe}, format: "luks"},
+	{offset: 3, magic: []byte("-FVE-FS-"), format: "bitlocker"},
+}
+
+// diskEncryptionState returns the encrypted block devices of the guest, whether they are unlocked or not.
+func diskEncryptionState() []api.InstanceStateDiskEncryption {
+	mountpoints := diskEncryptionMountpoints()
+
+	// Find the unlocked devices through their device mapper targets.
+	devices := map[string]api.InstanceStateDiskEncryption{}
+	mappings, _ := filepath.Glob("/sys/block/dm-*")
+	for _, mapping := range mappings {
+		uuid, err := os.ReadFile(filepath.Join(mapping, "dm", "uuid"))
+		if err != nil || !bytes.HasPrefix(uuid, []byte("CRYPT-")) {
+			continue
+		}
+
+		// The UUID of dm-crypt mappings is in the form CRYPT-<TYPE>-<uuid>-<name>.
+		fields := strings.SplitN(strings.TrimSpace(string(uuid)), "-", 3)
+		format := strings.ToLower(fields[1])
+		if format == "bitlk" {
+			format = "bitlocker"
+		}
+
+		slaves, _ := os.ReadDir(filepath.Join(mapping, "slaves"))
+		if len(slaves) == 0 {
+			continue
+		}
+
+		name, _ := os.ReadFile(filepath.Join(mapping, "dm", "name"))
+		dev, _ := os.ReadFile(filepath.Join(mapping, "dev"))
+
+		devices[slaves[0].Name()] = api.InstanceStateDiskEncryption{
+			Device:     slaves[0].Name(),
+			Mapping:    strings.TrimSpace(string(name)),
+			Type:       format,
+			Mountpoint: mountpoints[strings.TrimSpace(string(dev))],
+		}
+	}
+
+	// Find the locked devices through the signature of their encryption format.
+	blockDevices, _ := os.ReadDir("/sys/class/block")
+	for _, blockDevice := range blockDevices {
+		name := blockDevice.Name()
+		if strings.HasPrefix(name, "dm-") || strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+			continue
+		}
+
+		_, found := devices[name]
+		if found {
+			continue
+		}
+
+		format, err := diskEncryptionFormat(filepath.Join("/dev", name))
+		if err != nil {
+			logger.Debug("Failed checking block device encryption", logger.Ctx{"device": name, "err": err})
+			continue
+		}
+
+		if format != "" {
+			devices[name] = api.InstanceStateDiskEncryption{Device: name, Type: format}
+		}
+	}
+
+	if len(devices) == 0 {
+		return nil
+	}
+
+	result := make([]api.InstanceStateDiskEncryption, 0, len(devices))
+	for _, device := range devices {
+		result = append(result, device)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Device < result[j].Device })
+
+	return result
+}
+
+// diskEncryptionFormat returns the encryption format of the given block device, or an empty string if it isn't
+// encrypted.
+func diskEncryptionFormat(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	header := make([]byte, 16)
+	_, err = f.ReadAt(header, 0)
+	if err != nil {
+		return "", err
+	}
+
+	for _, m := range diskEncryptionMagics {
+		if !bytes.Equal(header[m.offset:m.offset+len(m.magic)], m.magic) {
+			continue
+		}
+
+		// The LUKS version follows the magic.
+		if m.format == "luks" {
+			return fmt.Sprintf("luks%d", binary.BigEndian.Uint16(header[6:8])), nil
+		}
+
+		return m.format, nil
+	}
+
+	return "", nil
+}
+
+// diskEncryptionMountpoints returns the first mountpoint of each mounted block device, indexed by major:minor.
+func diskEncryptionMountpoints() map[string]string {
+	mountpoints := map[string]string{}
+
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return mountpoints
+	}
+
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		_, found := mountpoints[fields[2]]
+		if !found {
+			mountpoints[fields[2]] = fields[4]
+		}
+	}
+
+	return mountpoints
+}
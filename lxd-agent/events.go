@@ -78,7 +78,7 @@ func eventsSocket(d *Daemon, r *http.Request, w http.ResponseWriter) error {
 	}
 
 	// As we don't know which project we are in, subscribe to events from all projects.
-	listener, err := d.events.AddListener("", true, nil, listenerConnection, strings.Split(typeStr, ","), nil, nil, nil)
+	listener, err := d.events.AddListener("", true, nil, true, listenerConnection, strings.Split(typeStr, ","), nil, nil, nil, time.Time{})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/ws"
+)
+
+var journalCmd = APIEndpoint{
+	Name: "journal",
+	Path: "journal",
+
+	Get: APIEndpointAction{Handler: journalGet},
+}
+
+// journalFields are the fields of the journal entries that are forwarded along with their message.
+var journalFields = []string{"PRIORITY", "SYSLOG_IDENTIFIER", "_SYSTEMD_UNIT", "_PID", "_COMM", "_HOSTNAME"}
+
+// journalPriorities are the priorities accepted by journalctl, from the most to the least important.
+var journalPriorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// journalGet streams the entries of the systemd journal over a websocket.
+func journalGet(d *Daemon, r *http.Request) response.Response {
+	lines := 0
+	linesStr := r.FormValue("lines")
+	if linesStr != "" {
+		var err error
+
+		lines, err = strconv.Atoi(linesStr)
+		if err != nil || lines < 0 {
+			return response.BadRequest(fmt.Errorf("Invalid number of lines %q", linesStr))
+		}
+	}
+
+	follow := shared.IsTrue(r.FormValue("follow"))
+
+	priority := r.FormValue("priority")
+	if priority != "" && !shared.ValueInSlice(priority, journalPriorities) {
+		return response.BadRequest(fmt.Errorf("Invalid journal priority %q", priority))
+	}
+
+	units := r.Form["unit"]
+
+	_, err := exec.LookPath("journalctl")
+	if err != nil {
+		return response.NotFound(fmt.Errorf("The systemd journal isn't available"))
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		conn, err := ws.Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// Stop when the client goes away.
+		go func() {
+			for {
+				_, _, err := conn.NextReader()
+				if err != nil {
+					cancel()
+					return
+				}
+			}
+		}()
+
+		args := []string{"--output=json", "--no-pager", "--lines", strconv.Itoa(lines)}
+		if follow {
+			args = append(args, "--follow")
+		}
+
+		if priority != "" {
+			args = append(args, "--priority="+priority)
+		}
+
+		for _, unit := range units {
+			args = append(args, "--unit="+unit)
+		}
+
+		cmd := exec.CommandContext(ctx, "journalctl", args...)
+
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return err
+		}
+
+		err = cmd.Start()
+		if err != nil {
+			_ = conn.WriteJSON(api.InstanceLogRecord{Source: api.InstanceLogSourceJournal, Timestamp: time.Now(), Error: err.Error()})
+			return nil
+		}
+
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+		for scanner.Scan() {
+			record, err := journalRecord(scanner.Bytes())
+			if err != nil {
+				logger.Debug("Skipping invalid journal entry", logger.Ctx{"err": err})
+				continue
+			}
+
+			err = conn.WriteJSON(record)
+			if err != nil {
+				break
+			}
+		}
+
+		cancel()
+		_ = cmd.Wait()
+
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+
+		return nil
+	})
+}
+
+// journalRecord converts an entry of the JSON output of journalctl to a log record.
+func journalRecord(data []byte) (*api.InstanceLogRecord, error) {
+	entry := map[string]any{}
+
+	err := json.Unmarshal(data, &entry)
+	if err != nil {
+		return nil, err
+	}
+
+	record := api.InstanceLogRecord{
+		Source:    api.InstanceLogSourceJournal,
+		Timestamp: time.Now(),
+		Message:   journalValue(entry["MESSAGE"]),
+		Fields:    map[string]string{},
+	}
+
+	// The realtime timestamp is in microseconds since the epoch.
+	usec, err := strconv.ParseInt(journalValue(entry["__REALTIME_TIMESTAMP"]), 10, 64)
+	if err == nil {
+		record.Timestamp = time.UnixMicro(usec)
+	}
+
+	for _, field := range journalFields {
+		value := journalValue(entry[field])
+		if value != "" {
+			record.Fields[field] = value
+		}
+	}
+
+	return &record, nil
+}
+
+// journalValue returns the value of a field of a journal entry as a string.
+// Values that aren't valid UTF-8 are serialized by journalctl as arrays of bytes.
+func journalValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		buf := make([]byte, 0, len(v))
+		for _, b := range v {
+			n, ok := b.(float64)
+			if ok {
+				buf = append(buf, byte(n))
+			}
+		}
+
+		return string(buf)
+	}
+
+	return ""
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/resources"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/storage/filesystem"
 	"github.com/canonical/lxd/shared"
@@ -61,6 +62,13 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		out.Network = netStats
 	}
 
+	gpuStats, err := getGPUMetrics()
+	if err != nil {
+		logger.Warn("Failed to get GPU metrics", logger.Ctx{"err": err})
+	} else {
+		out.GPU = gpuStats
+	}
+
 	out.ProcessesTotal, err = getTotalProcesses()
 	if err != nil {
 		logger.Warn("Failed to get total processes", logger.Ctx{"err": err})
@@ -76,6 +84,26 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, &out)
 }
 
+func getGPUMetrics() (map[string]metrics.GPUMetrics, error) {
+	usage, err := resources.GetGPUUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]metrics.GPUMetrics, len(usage))
+
+	for pciAddress, stats := range usage {
+		out[pciAddress] = metrics.GPUMetrics{
+			Vendor:           stats.Vendor,
+			UtilizationRatio: stats.UtilizationRatio,
+			MemoryUsedBytes:  stats.MemoryUsedBytes,
+			MemoryTotalBytes: stats.MemoryTotalBytes,
+		}
+	}
+
+	return out, nil
+}
+
 func getCPUMetrics() (map[string]metrics.CPUMetrics, error) {
 	stats, err := os.ReadFile("/proc/stat")
 	if err != nil {
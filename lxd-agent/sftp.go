@@ -5,8 +5,10 @@ import (
 	"net/http"
 
 	"github.com/pkg/sftp"
+	"golang.org/x/sys/unix"
 
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/logger"
 )
 
 var sftpCmd = APIEndpoint{
@@ -59,11 +61,17 @@ func (r *sftpServe) Render(w http.ResponseWriter) error {
 		return nil
 	}
 
-	// Start sftp server.
-	server, err := sftp.NewServer(conn)
+	// Start sftp server from the root of the guest filesystem, the same way as the one of the containers.
+	server, err := sftp.NewServer(conn, sftp.WithServerWorkingDirectory("/"))
 	if err != nil {
+		logger.Error("Failed starting SFTP server", logger.Ctx{"err": err})
 		return nil
 	}
 
-	return server.Serve()
+	err = server.Serve()
+
+	// Sync the filesystem so that the changes are persisted even if the instance is stopped abruptly afterwards.
+	unix.Sync()
+
+	return err
 }
@@ -3,10 +3,12 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -29,7 +31,98 @@ func stateGet(d *Daemon, r *http.Request) response.Response {
 }
 
 func statePut(d *Daemon, r *http.Request) response.Response {
-	return response.NotImplemented(nil)
+	req := api.InstanceStatePut{}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	switch req.Action {
+	case "freeze":
+		err = cgroupFreeze(true)
+	case "unfreeze":
+		err = cgroupFreeze(false)
+	default:
+		return response.NotImplemented(fmt.Errorf("Action %q isn't supported", req.Action))
+	}
+
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// cgroupFreeze freezes or thaws all the processes of the guest except for the lxd-agent and its children.
+// This is done through the cgroup freezer of each cgroup that doesn't contain the lxd-agent.
+func cgroupFreeze(freeze bool) error {
+	if !shared.PathExists("/sys/fs/cgroup/cgroup.controllers") {
+		return api.StatusErrorf(http.StatusNotImplemented, "Freezing the guest requires cgroup v2")
+	}
+
+	ownCgroup, err := cgroupOwn()
+	if err != nil {
+		return err
+	}
+
+	value := "0"
+	if freeze {
+		value = "1"
+	}
+
+	return cgroupFreezeChildren("/sys/fs/cgroup", "/", ownCgroup, value)
+}
+
+// cgroupFreezeChildren sets the freezer state of the children of a cgroup.
+// The children that contain the lxd-agent aren't frozen themselves but are searched for further children instead.
+func cgroupFreezeChildren(path string, cgroup string, ownCgroup string, value string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("Failed listing cgroup %q: %w", cgroup, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		childPath := filepath.Join(path, entry.Name())
+		childCgroup := filepath.Join(cgroup, entry.Name())
+
+		if ownCgroup == childCgroup || strings.HasPrefix(ownCgroup, childCgroup+"/") {
+			err = cgroupFreezeChildren(childPath, childCgroup, ownCgroup, value)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err = os.WriteFile(filepath.Join(childPath, "cgroup.freeze"), []byte(value), 0)
+		if err != nil {
+			return fmt.Errorf("Failed setting freezer state of cgroup %q: %w", childCgroup, err)
+		}
+	}
+
+	return nil
+}
+
+// cgroupOwn returns the cgroup v2 path of the lxd-agent.
+func cgroupOwn() (string, error) {
+	content, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		cgroup, found := strings.CutPrefix(line, "0::")
+		if found {
+			return cgroup, nil
+		}
+	}
+
+	return "", fmt.Errorf("Failed finding the cgroup of the lxd-agent")
 }
 
 func renderState() *api.InstanceState {
@@ -39,6 +132,8 @@ func renderState() *api.InstanceState {
 		Network:   networkState(),
 		Pid:       1,
 		Processes: processesState(),
+
+		DiskEncryption: diskEncryptionState(),
 	}
 }
 
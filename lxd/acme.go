@@ -2,16 +2,22 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 
 	"github.com/gorilla/mux"
 
+	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/acme"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
@@ -73,12 +79,89 @@ func acmeProvideChallenge(d *Daemon, r *http.Request) response.Response {
 	})
 }
 
+// acmeListeners lists the listeners which can be given a dedicated certificate.
+var acmeListeners = []string{acme.ListenerMetrics, acme.ListenerStorageBuckets, acme.ListenerEvents, acme.ListenerMigration}
+
+// internalACMEListenerCertificatePut is used to distribute a listener certificate to the other cluster members.
+type internalACMEListenerCertificatePut struct {
+	Certificate string `json:"certificate" yaml:"certificate"`
+	Key         string `json:"key"         yaml:"key"`
+}
+
+// acmeChallenge returns the challenge used to prove the ownership of the domains to the ACME service.
+func acmeChallenge(d *Daemon) (acme.Challenge, error) {
+	challengeType, providerName, environment, resolvers := d.State().GlobalConfig.ACMEChallenge()
+
+	if challengeType != acme.ChallengeDNS01 {
+		return acme.Challenge{Type: acme.ChallengeHTTP01, Provider: d.http01Provider}, nil
+	}
+
+	if providerName == "" {
+		return acme.Challenge{}, fmt.Errorf(`"acme.provider" must be set to use %s challenges`, acme.ChallengeDNS01)
+	}
+
+	provider, err := acme.NewDNS01Provider(providerName, environment)
+	if err != nil {
+		return acme.Challenge{}, err
+	}
+
+	return acme.Challenge{Type: acme.ChallengeDNS01, Provider: provider, Resolvers: resolvers}, nil
+}
+
+// acmeListenerUpdateCert applies the dedicated certificate of a listener. Passing nil makes the listener use the
+// server certificate again.
+func acmeListenerUpdateCert(s *state.State, listener string, cert *shared.CertInfo) {
+	if cert != nil {
+		// Keep the CA and CRL of the server certificate so that client certificates are checked the same way.
+		networkCert := s.Endpoints.NetworkCert()
+		cert = shared.NewCertInfo(cert.KeyPair(), networkCert.CA(), networkCert.CRL())
+	}
+
+	switch listener {
+	case acme.ListenerMetrics:
+		s.Endpoints.MetricsUpdateCert(cert)
+	case acme.ListenerStorageBuckets:
+		s.Endpoints.StorageBucketsUpdateCert(cert)
+	case acme.ListenerEvents:
+		s.Endpoints.EventsUpdateCert(cert)
+	case acme.ListenerMigration:
+		s.Endpoints.MigrationUpdateCert(cert)
+	}
+}
+
+// acmeLoadListenerCertificates applies the dedicated certificates of the listeners which have a domain configured.
+func acmeLoadListenerCertificates(s *state.State) {
+	for _, listener := range acmeListeners {
+		if s.GlobalConfig.ACMEListenerDomain(listener) == "" {
+			continue
+		}
+
+		cert, err := acme.LoadListenerCertificate(s.OS.VarDir, listener)
+		if err != nil {
+			logger.Warn("Failed loading listener certificate", logger.Ctx{"listener": listener, "err": err})
+			continue
+		}
+
+		if cert != nil {
+			acmeListenerUpdateCert(s, listener, cert)
+		}
+	}
+}
+
 func autoRenewCertificate(ctx context.Context, d *Daemon, force bool) error {
 	s := d.State()
 
 	domain, email, caURL, agreeToS := s.GlobalConfig.ACME()
 
-	if domain == "" || email == "" || !agreeToS {
+	listenerDomains := map[string]string{}
+	for _, listener := range acmeListeners {
+		listenerDomain := s.GlobalConfig.ACMEListenerDomain(listener)
+		if listenerDomain != "" {
+			listenerDomains[listener] = listenerDomain
+		}
+	}
+
+	if (domain == "" && len(listenerDomains) == 0) || email == "" || !agreeToS {
 		return nil
 	}
 
@@ -97,69 +180,189 @@ func autoRenewCertificate(ctx context.Context, d *Daemon, force bool) error {
 		}
 	}
 
+	ch, err := acmeChallenge(d)
+	if err != nil {
+		return err
+	}
+
 	opRun := func(op *operations.Operation) error {
-		newCert, err := acme.UpdateCertificate(s, d.http01Provider, s.ServerClustered, domain, email, caURL, force)
+		if domain != "" {
+			err := renewServerCertificate(s, d.gateway, ch, domain, email, caURL, force)
+			if err != nil {
+				return err
+			}
+		}
+
+		for listener, listenerDomain := range listenerDomains {
+			err := renewListenerCertificate(s, ch, listener, listenerDomain, email, caURL, force)
+			if err != nil {
+				return fmt.Errorf("Failed renewing %q listener certificate: %w", listener, err)
+			}
+		}
+
+		return nil
+	}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.RenewServerCertificate, nil, nil, opRun, nil, nil, nil)
+	if err != nil {
+		logger.Error("Failed creating renew server certificate operation", logger.Ctx{"err": err})
+		return err
+	}
+
+	logger.Info("Starting automatic server certificate renewal check")
+
+	err = op.Start()
+	if err != nil {
+		logger.Error("Failed starting renew server certificate operation", logger.Ctx{"err": err})
+		return err
+	}
+
+	err = op.Wait(ctx)
+	if err != nil {
+		logger.Error("Failed server certificate renewal", logger.Ctx{"err": err})
+		return err
+	}
+
+	logger.Info("Done automatic server certificate renewal check")
+
+	return nil
+}
+
+// renewServerCertificate renews the server (or cluster) certificate if needed.
+func renewServerCertificate(s *state.State, gateway *cluster.Gateway, ch acme.Challenge, domain string, email string, caURL string, force bool) error {
+	newCert, err := acme.UpdateCertificate(s, ch, s.ServerClustered, domain, email, caURL, force)
+	if err != nil {
+		return err
+	}
+
+	// If cert is nil, there's no need to update it as it's still valid.
+	if newCert == nil {
+		return nil
+	}
+
+	if s.ServerClustered {
+		req := api.ClusterCertificatePut{
+			ClusterCertificate:    string(newCert.Certificate),
+			ClusterCertificateKey: string(newCert.PrivateKey),
+		}
+
+		err = updateClusterCertificate(s.ShutdownCtx, s, gateway, nil, req)
 		if err != nil {
 			return err
 		}
 
-		// If cert is nil, there's no need to update it as it's still valid.
-		if newCert == nil {
-			return nil
+		return nil
+	}
+
+	cert, err := shared.KeyPairFromRaw(newCert.Certificate, newCert.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	s.Endpoints.NetworkUpdateCert(cert)
+
+	err = util.WriteCert(s.OS.VarDir, "server", newCert.Certificate, newCert.PrivateKey, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// renewListenerCertificate renews the dedicated certificate of a listener if needed. When clustered, the current
+// certificate is sent to all other members even if it wasn't renewed so that new members get it too.
+func renewListenerCertificate(s *state.State, ch acme.Challenge, listener string, domain string, email string, caURL string, force bool) error {
+	req := internalACMEListenerCertificatePut{}
+
+	newCert, err := acme.UpdateListenerCertificate(s, ch, listener, domain, email, caURL, force)
+	if err != nil {
+		return err
+	}
+
+	if newCert != nil {
+		cert, err := shared.KeyPairFromRaw(newCert.Certificate, newCert.PrivateKey)
+		if err != nil {
+			return err
 		}
 
-		if s.ServerClustered {
-			req := api.ClusterCertificatePut{
-				ClusterCertificate:    string(newCert.Certificate),
-				ClusterCertificateKey: string(newCert.PrivateKey),
-			}
+		err = util.WriteCert(s.OS.VarDir, listener, newCert.Certificate, newCert.PrivateKey, nil)
+		if err != nil {
+			return err
+		}
 
-			err = updateClusterCertificate(s.ShutdownCtx, s, d.gateway, nil, req)
-			if err != nil {
-				return err
-			}
+		acmeListenerUpdateCert(s, listener, cert)
 
+		req.Certificate = string(newCert.Certificate)
+		req.Key = string(newCert.PrivateKey)
+	} else if s.ServerClustered {
+		certFilename := filepath.Join(s.OS.VarDir, listener+".crt")
+		if !shared.PathExists(certFilename) {
 			return nil
 		}
 
-		cert, err := shared.KeyPairFromRaw(newCert.Certificate, newCert.PrivateKey)
+		cert, err := os.ReadFile(certFilename)
 		if err != nil {
 			return err
 		}
 
-		s.Endpoints.NetworkUpdateCert(cert)
-
-		err = util.WriteCert(s.OS.VarDir, "server", newCert.Certificate, newCert.PrivateKey, nil)
+		key, err := os.ReadFile(filepath.Join(s.OS.VarDir, listener+".key"))
 		if err != nil {
 			return err
 		}
 
+		req.Certificate = string(cert)
+		req.Key = string(key)
+	}
+
+	if !s.ServerClustered {
 		return nil
 	}
 
-	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.RenewServerCertificate, nil, nil, opRun, nil, nil, nil)
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
-		logger.Error("Failed creating renew server certificate operation", logger.Ctx{"err": err})
 		return err
 	}
 
-	logger.Info("Starting automatic server certificate renewal check")
+	return notifier(func(client lxd.InstanceServer) error {
+		_, _, err := client.RawQuery(http.MethodPut, "/internal/acme/listeners/"+listener, req, "")
+		return err
+	})
+}
 
-	err = op.Start()
+// internalACMEListenerCertificate stores and applies the dedicated certificate of a listener sent by the leader.
+func internalACMEListenerCertificate(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	listener, err := url.PathUnescape(mux.Vars(r)["listener"])
 	if err != nil {
-		logger.Error("Failed starting renew server certificate operation", logger.Ctx{"err": err})
-		return err
+		return response.SmartError(err)
 	}
 
-	err = op.Wait(ctx)
+	if !shared.ValueInSlice(listener, acmeListeners) {
+		return response.NotFound(fmt.Errorf("Unknown listener %q", listener))
+	}
+
+	req := internalACMEListenerCertificatePut{}
+	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		logger.Error("Failed server certificate renewal", logger.Ctx{"err": err})
-		return err
+		return response.BadRequest(err)
 	}
 
-	logger.Info("Done automatic server certificate renewal check")
+	cert, err := shared.KeyPairFromRaw([]byte(req.Certificate), []byte(req.Key))
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
-	return nil
+	err = util.WriteCert(s.OS.VarDir, listener, []byte(req.Certificate), []byte(req.Key), nil)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if s.GlobalConfig.ACMEListenerDomain(listener) != "" {
+		acmeListenerUpdateCert(s, listener, cert)
+	}
+
+	return response.EmptySyncResponse
 }
 
 func autoRenewCertificateTask(d *Daemon) (task.Func, task.Schedule) {
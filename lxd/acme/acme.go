@@ -1,6 +1,7 @@
 package acme
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -8,11 +9,14 @@ import (
 	"crypto/x509"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/lego"
 	"github.com/go-acme/lego/v4/registration"
 
@@ -32,6 +36,33 @@ const retries = 5
 // certificate at a later stage.
 const ClusterCertFilename = "cluster.crt.new"
 
+// Challenge types which can be used to prove the ownership of a domain.
+const (
+	ChallengeHTTP01 = "HTTP-01"
+	ChallengeDNS01  = "DNS-01"
+)
+
+// Listeners which can be given a dedicated certificate. The names are used in the acme.<listener>.domain
+// configuration keys and as the prefix of the certificate files.
+const (
+	ListenerMetrics        = "metrics"
+	ListenerStorageBuckets = "storage_buckets"
+	ListenerEvents         = "events"
+	ListenerMigration      = "migration"
+)
+
+// Challenge describes how the ownership of a domain is proven to the ACME service.
+type Challenge struct {
+	// Type is either ChallengeHTTP01 or ChallengeDNS01.
+	Type string
+
+	// Provider answers the challenge.
+	Provider challenge.Provider
+
+	// Resolvers are the DNS resolvers used to check that the DNS-01 record has propagated.
+	Resolvers []string
+}
+
 // certificateNeedsUpdate returns true if the domain doesn't match the certificate's DNS names
 // or it's valid for less than 30 days.
 func certificateNeedsUpdate(domain string, cert *x509.Certificate) bool {
@@ -39,7 +70,7 @@ func certificateNeedsUpdate(domain string, cert *x509.Certificate) bool {
 }
 
 // UpdateCertificate updates the certificate.
-func UpdateCertificate(s *state.State, provider HTTP01Provider, clustered bool, domain string, email string, caURL string, force bool) (*certificate.Resource, error) {
+func UpdateCertificate(s *state.State, ch Challenge, clustered bool, domain string, email string, caURL string, force bool) (*certificate.Resource, error) {
 	clusterCertFilename := shared.VarPath(ClusterCertFilename)
 
 	l := logger.AddContext(logger.Ctx{"domain": domain, "caURL": caURL})
@@ -98,6 +129,91 @@ func UpdateCertificate(s *state.State, provider HTTP01Provider, clustered bool,
 		return nil, nil
 	}
 
+	l.Info("Issuing certificate")
+
+	certificates, err := obtainCertificate(l, ch, domain, email, caURL, certInfo.KeyPair().PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Info("Finished issuing certificate")
+
+	return certificates, nil
+}
+
+// UpdateListenerCertificate updates the dedicated certificate of the given listener. It returns nil if the current
+// certificate doesn't need to be renewed.
+func UpdateListenerCertificate(s *state.State, ch Challenge, listener string, domain string, email string, caURL string, force bool) (*certificate.Resource, error) {
+	l := logger.AddContext(logger.Ctx{"listener": listener, "domain": domain, "caURL": caURL})
+
+	var privateKey crypto.PrivateKey
+
+	certInfo, err := LoadListenerCertificate(s.OS.VarDir, listener)
+	if err != nil {
+		return nil, err
+	}
+
+	if certInfo != nil {
+		cert, err := x509.ParseCertificate(certInfo.KeyPair().Certificate[0])
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse certificate: %w", err)
+		}
+
+		if !force && !certificateNeedsUpdate(domain, cert) {
+			l.Debug("Skipping certificate renewal as it is still valid for more than 30 days")
+			return nil, nil
+		}
+
+		privateKey = certInfo.KeyPair().PrivateKey
+	} else {
+		privateKey, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("Failed generating private key: %w", err)
+		}
+	}
+
+	l.Info("Issuing listener certificate")
+
+	certificates, err := obtainCertificate(l, ch, domain, email, caURL, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Info("Finished issuing listener certificate")
+
+	return certificates, nil
+}
+
+// LoadListenerCertificate loads the dedicated certificate of the given listener from the given directory. It
+// returns nil if the listener has no dedicated certificate.
+func LoadListenerCertificate(dir string, listener string) (*shared.CertInfo, error) {
+	certFilename := filepath.Join(dir, listener+".crt")
+	keyFilename := filepath.Join(dir, listener+".key")
+
+	if !shared.PathExists(certFilename) || !shared.PathExists(keyFilename) {
+		return nil, nil
+	}
+
+	cert, err := os.ReadFile(certFilename)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading listener certificate file: %w", err)
+	}
+
+	key, err := os.ReadFile(keyFilename)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading listener key file: %w", err)
+	}
+
+	certInfo, err := shared.KeyPairFromRaw(cert, key)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get keypair: %w", err)
+	}
+
+	return certInfo, nil
+}
+
+// obtainCertificate registers a new ACME account and obtains a certificate for the given domain and private key.
+func obtainCertificate(l logger.Logger, ch Challenge, domain string, email string, caURL string, privateKey crypto.PrivateKey) (*certificate.Resource, error) {
 	// Generate new private key for user. This key needs to be different from the server's private key.
 	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
@@ -125,9 +241,23 @@ func UpdateCertificate(s *state.State, provider HTTP01Provider, clustered bool,
 		return nil, fmt.Errorf("Failed to create new client: %w", err)
 	}
 
-	err = client.Challenge.SetHTTP01Provider(provider)
-	if err != nil {
-		return nil, fmt.Errorf("Failed setting HTTP-01 provider: %w", err)
+	switch ch.Type {
+	case ChallengeDNS01:
+		var opts []dns01.ChallengeOption
+		if len(ch.Resolvers) > 0 {
+			opts = append(opts, dns01.AddRecursiveNameservers(dns01.ParseNameservers(ch.Resolvers)))
+		}
+
+		err = client.Challenge.SetDNS01Provider(ch.Provider, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("Failed setting DNS-01 provider: %w", err)
+		}
+
+	default:
+		err = client.Challenge.SetHTTP01Provider(ch.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("Failed setting HTTP-01 provider: %w", err)
+		}
 	}
 
 	var reg *registration.Resource
@@ -158,13 +288,11 @@ func UpdateCertificate(s *state.State, provider HTTP01Provider, clustered bool,
 	request := certificate.ObtainRequest{
 		Domains:    []string{domain},
 		Bundle:     true,
-		PrivateKey: certInfo.KeyPair().PrivateKey,
+		PrivateKey: privateKey,
 	}
 
 	var certificates *certificate.Resource
 
-	l.Info("Issuing certificate")
-
 	// Get new certificate.
 	// This might fail randomly (as seen in manual tests), so retry in that case.
 	for i := 0; i < retries; i++ {
@@ -187,7 +315,5 @@ func UpdateCertificate(s *state.State, provider HTTP01Provider, clustered bool,
 		return nil, fmt.Errorf("Failed to obtain certificate: %w", err)
 	}
 
-	l.Info("Finished issuing certificate")
-
 	return certificates, nil
 }
@@ -72,3 +72,68 @@ func Test_certificateNeedsUpdate(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewDNS01Provider(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    string
+		environment []string
+		wantErr     bool
+	}{
+		{
+			"Unknown provider",
+			"unknown",
+			nil,
+			true,
+		},
+		{
+			"Invalid setting",
+			"exec",
+			[]string{"EXEC_PATH"},
+			true,
+		},
+		{
+			"Missing required setting",
+			"exec",
+			nil,
+			true,
+		},
+		{
+			"Invalid propagation timeout",
+			"exec",
+			[]string{"EXEC_PATH=/usr/local/bin/update-dns", "EXEC_PROPAGATION_TIMEOUT=soon"},
+			true,
+		},
+		{
+			"Exec provider",
+			"exec",
+			[]string{"EXEC_PATH=/usr/local/bin/update-dns", "EXEC_PROPAGATION_TIMEOUT=120"},
+			false,
+		},
+		{
+			"HTTP request provider",
+			"httpreq",
+			[]string{"HTTPREQ_ENDPOINT=https://dns.example.net/acme"},
+			false,
+		},
+		{
+			"RFC2136 provider",
+			"rfc2136",
+			[]string{"RFC2136_NAMESERVER=192.0.2.53", "RFC2136_TSIG_KEY=lxd", "RFC2136_TSIG_SECRET=c2VjcmV0"},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewDNS01Provider(tt.provider, tt.environment)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, provider)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package acme
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/providers/dns/exec"
+	"github.com/go-acme/lego/v4/providers/dns/httpreq"
+	"github.com/go-acme/lego/v4/providers/dns/rfc2136"
+)
+
+// dnsProviders maps the names of the supported DNS providers to a function returning a configured provider.
+// The environment holds the provider settings and uses the same variable names as the lego DNS providers.
+var dnsProviders = map[string]func(environment map[string]string) (challenge.Provider, error){
+	"exec":    newExecProvider,
+	"httpreq": newHTTPReqProvider,
+	"rfc2136": newRFC2136Provider,
+}
+
+// DNSProviders returns the names of the supported DNS providers.
+func DNSProviders() []string {
+	names := make([]string, 0, len(dnsProviders))
+	for name := range dnsProviders {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// NewDNS01Provider returns the DNS-01 challenge provider with the given name, configured using the given list of
+// KEY=VALUE settings.
+func NewDNS01Provider(name string, environment []string) (challenge.Provider, error) {
+	newProvider, ok := dnsProviders[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown DNS provider %q (supported providers: %s)", name, strings.Join(DNSProviders(), ", "))
+	}
+
+	env := make(map[string]string, len(environment))
+	for _, entry := range environment {
+		key, value, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("Invalid DNS provider setting %q, expected KEY=VALUE", entry)
+		}
+
+		env[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	provider, err := newProvider(env)
+	if err != nil {
+		return nil, fmt.Errorf("Failed configuring DNS provider %q: %w", name, err)
+	}
+
+	return provider, nil
+}
+
+// envSeconds sets target to the number of seconds found in the given setting, if set.
+func envSeconds(env map[string]string, key string, target *time.Duration) error {
+	value, ok := env[key]
+	if !ok {
+		return nil
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return fmt.Errorf("Invalid value %q for %q, expected a number of seconds", value, key)
+	}
+
+	*target = time.Duration(seconds) * time.Second
+
+	return nil
+}
+
+// newExecProvider returns a provider running an external program to create and remove the DNS records.
+func newExecProvider(env map[string]string) (challenge.Provider, error) {
+	config := exec.NewDefaultConfig()
+	config.Program = env[exec.EnvPath]
+	config.Mode = env[exec.EnvMode]
+
+	if config.Program == "" {
+		return nil, fmt.Errorf("%q must be set", exec.EnvPath)
+	}
+
+	for key, target := range map[string]*time.Duration{
+		exec.EnvPropagationTimeout: &config.PropagationTimeout,
+		exec.EnvPollingInterval:    &config.PollingInterval,
+		exec.EnvSequenceInterval:   &config.SequenceInterval,
+	} {
+		err := envSeconds(env, key, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	provider, err := exec.NewDNSProviderConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// newHTTPReqProvider returns a provider calling an HTTP endpoint to create and remove the DNS records.
+func newHTTPReqProvider(env map[string]string) (challenge.Provider, error) {
+	config := httpreq.NewDefaultConfig()
+	config.Mode = env[httpreq.EnvMode]
+	config.Username = env[httpreq.EnvUsername]
+	config.Password = env[httpreq.EnvPassword]
+
+	endpoint := env[httpreq.EnvEndpoint]
+	if endpoint == "" {
+		return nil, fmt.Errorf("%q must be set", httpreq.EnvEndpoint)
+	}
+
+	var err error
+	config.Endpoint, err = url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid value for %q: %w", httpreq.EnvEndpoint, err)
+	}
+
+	for key, target := range map[string]*time.Duration{
+		httpreq.EnvPropagationTimeout: &config.PropagationTimeout,
+		httpreq.EnvPollingInterval:    &config.PollingInterval,
+		httpreq.EnvHTTPTimeout:        &config.HTTPClient.Timeout,
+	} {
+		err := envSeconds(env, key, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	provider, err := httpreq.NewDNSProviderConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// newRFC2136Provider returns a provider using dynamic DNS updates (RFC 2136) to create and remove the DNS records.
+func newRFC2136Provider(env map[string]string) (challenge.Provider, error) {
+	config := rfc2136.NewDefaultConfig()
+	config.Nameserver = env[rfc2136.EnvNameserver]
+	config.TSIGKey = env[rfc2136.EnvTSIGKey]
+	config.TSIGSecret = env[rfc2136.EnvTSIGSecret]
+
+	if env[rfc2136.EnvTSIGAlgorithm] != "" {
+		config.TSIGAlgorithm = env[rfc2136.EnvTSIGAlgorithm]
+	}
+
+	if env[rfc2136.EnvTTL] != "" {
+		ttl, err := strconv.Atoi(env[rfc2136.EnvTTL])
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("Invalid value %q for %q", env[rfc2136.EnvTTL], rfc2136.EnvTTL)
+		}
+
+		config.TTL = ttl
+	}
+
+	for key, target := range map[string]*time.Duration{
+		rfc2136.EnvPropagationTimeout: &config.PropagationTimeout,
+		rfc2136.EnvPollingInterval:    &config.PollingInterval,
+		rfc2136.EnvSequenceInterval:   &config.SequenceInterval,
+		rfc2136.EnvDNSTimeout:         &config.DNSTimeout,
+	} {
+		err := envSeconds(env, key, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	provider, err := rfc2136.NewDNSProviderConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
@@ -163,6 +163,9 @@ func restServer(d *Daemon) *http.Server {
 		d.oidcVerifier.Logout(w, r)
 	})
 
+	// SCIM provisioning of OIDC identities.
+	scimRoutes(d, mux)
+
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
@@ -253,6 +256,53 @@ func metricsServer(d *Daemon) *http.Server {
 
 	d.createCmd(mux, "1.0", api10Cmd)
 	d.createCmd(mux, "1.0", metricsCmd)
+	d.createCmd(mux, "1.0", projectMetricsCmd)
+
+	mux.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Info("Sending top level 404", logger.Ctx{"url": r.URL, "method": r.Method, "remote": r.RemoteAddr})
+		w.Header().Set("Content-Type", "application/json")
+		_ = response.NotFound(nil).Render(w)
+	})
+
+	return &http.Server{Handler: &lxdHTTPServer{r: mux, d: d}}
+}
+
+func eventsServer(d *Daemon) *http.Server {
+	/* Setup the web server */
+	mux := mux.NewRouter()
+	mux.StrictSlash(false)
+	mux.SkipClean(true)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = response.SyncResponse(true, []string{"/1.0"}).Render(w)
+	})
+
+	d.createCmd(mux, "1.0", api10Cmd)
+	d.createCmd(mux, "1.0", eventsCmd)
+
+	mux.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Info("Sending top level 404", logger.Ctx{"url": r.URL, "method": r.Method, "remote": r.RemoteAddr})
+		w.Header().Set("Content-Type", "application/json")
+		_ = response.NotFound(nil).Render(w)
+	})
+
+	return &http.Server{Handler: &lxdHTTPServer{r: mux, d: d}}
+}
+
+func migrationServer(d *Daemon) *http.Server {
+	/* Setup the web server */
+	mux := mux.NewRouter()
+	mux.StrictSlash(false)
+	mux.SkipClean(true)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = response.SyncResponse(true, []string{"/1.0"}).Render(w)
+	})
+
+	// Only the operation websockets are served, those are authenticated using the operation secrets.
+	d.createCmd(mux, "1.0", operationWebsocket)
 
 	mux.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger.Info("Sending top level 404", logger.Ctx{"url": r.URL, "method": r.Method, "remote": r.RemoteAddr})
@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/auth"
@@ -48,8 +49,13 @@ var api10 = []APIEndpoint{
 	clusterGroupsCmd,
 	clusterNodeCmd,
 	clusterNodeStateCmd,
+	clusterMemberLogCmd,
+	clusterMemberLogStreamCmd,
 	clusterNodesCmd,
 	clusterCertificateCmd,
+	clusterDatabaseIntegrityCmd,
+	clusterDatabaseSnapshotCmd,
+	clusterDatabaseSnapshotsCmd,
 	instanceBackupCmd,
 	instanceBackupExportCmd,
 	instanceBackupsCmd,
@@ -57,12 +63,24 @@ var api10 = []APIEndpoint{
 	instanceConsoleCmd,
 	instanceExecCmd,
 	instanceFileCmd,
+	instanceFirewallCmd,
+	instanceLeaseCmd,
+	instanceRemapCmd,
+	instanceHistoryCmd,
+	instanceHistoryRevisionCmd,
 	instanceExecOutputCmd,
 	instanceExecOutputsCmd,
+	instanceLogsStreamCmd,
+	instanceTopologyCmd,
 	instanceLogCmd,
 	instanceLogsCmd,
 	instanceMetadataCmd,
 	instanceMetadataTemplatesCmd,
+	instancePoolCmd,
+	instancePoolsCmd,
+	instancePoolAcquireCmd,
+	instancePoolInstancesCmd,
+	instancePoolReleaseCmd,
 	instancesCmd,
 	instanceRebuildCmd,
 	instanceSFTPCmd,
@@ -70,6 +88,11 @@ var api10 = []APIEndpoint{
 	instanceSnapshotsCmd,
 	instanceStateCmd,
 	instanceUEFIVarsCmd,
+	instanceUsageCmd,
+	instanceCommandsCmd,
+	instanceCommandCmd,
+	instanceDiskEncryptionKeysCmd,
+	instanceDiskEncryptionKeyCmd,
 	eventsCmd,
 	imageAliasCmd,
 	imageAliasesCmd,
@@ -78,14 +101,22 @@ var api10 = []APIEndpoint{
 	imageRefreshCmd,
 	imagesCmd,
 	imageSecretCmd,
+	inventoryCmd,
 	metadataConfigurationCmd,
+	metadataDevicesCmd,
 	networkCmd,
+	networkHistoryCmd,
+	networkHistoryRevisionCmd,
 	networkLeasesCmd,
 	networksCmd,
 	networkStateCmd,
+	networkMTUCmd,
 	networkACLCmd,
 	networkACLsCmd,
 	networkACLLogCmd,
+	networkAddressPoolCmd,
+	networkAddressPoolsCmd,
+	networkAddressPoolAllocationsCmd,
 	networkAllocationsCmd,
 	networkForwardCmd,
 	networkForwardsCmd,
@@ -102,11 +133,20 @@ var api10 = []APIEndpoint{
 	operationWait,
 	operationWebsocket,
 	profileCmd,
+	profileHistoryCmd,
+	profileHistoryRevisionCmd,
+	profileDriftCmd,
 	profilesCmd,
 	projectCmd,
 	projectsCmd,
 	projectStateCmd,
+	projectIdmapCmd,
+	projectMetricsCmd,
+	seccompPolicyCmd,
+	seccompPoliciesCmd,
 	storagePoolCmd,
+	storagePoolHistoryCmd,
+	storagePoolHistoryRevisionCmd,
 	storagePoolResourcesCmd,
 	storagePoolsCmd,
 	storagePoolBucketsCmd,
@@ -135,6 +175,12 @@ var api10 = []APIEndpoint{
 	permissionsCmd,
 	storageVolumesCmd,
 	storageVolumesTypeCmd,
+	tombstonesCmd,
+	tombstoneCmd,
+	authApprovalsCmd,
+	authApprovalCmd,
+	sshKeysCmd,
+	sshKeyCmd,
 }
 
 // swagger:operation GET /1.0?public server server_get_untrusted
@@ -319,6 +365,16 @@ func api10Get(d *Daemon, r *http.Request) response.Response {
 		Firewall:               s.Firewall.String(),
 	}
 
+	localMigrationAddress := s.LocalConfig.MigrationAddress()
+	if localMigrationAddress != "" {
+		env.MigrationAddresses, err = util.ListenAddresses(localMigrationAddress)
+		if err != nil {
+			return response.InternalError(err)
+		}
+
+		env.MigrationCertificate = string(s.Endpoints.MigrationPublicKey())
+	}
+
 	env.KernelFeatures = map[string]string{
 		"netnsid_getifaddrs":        fmt.Sprintf("%v", s.OS.NetnsGetifaddrs),
 		"uevent_injection":          fmt.Sprintf("%v", s.OS.UeventInjection),
@@ -326,6 +382,7 @@ func api10Get(d *Daemon, r *http.Request) response.Response {
 		"seccomp_listener":          fmt.Sprintf("%v", s.OS.SeccompListener),
 		"seccomp_listener_continue": fmt.Sprintf("%v", s.OS.SeccompListenerContinue),
 		"idmapped_mounts":           fmt.Sprintf("%v", s.OS.IdmappedMounts),
+		"time_namespace":            fmt.Sprintf("%v", s.OS.TimeNamespace),
 	}
 
 	drivers := instanceDrivers.DriverStatuses()
@@ -627,6 +684,10 @@ func doAPI10Update(d *Daemon, r *http.Request, req api.ServerPut, patch bool) re
 		}
 
 		if nodeValues["storage.images_volume"] != nil && nodeValues["storage.images_volume"] != newNodeConfig.StorageImagesVolume() {
+			if nodeValues["storage.images_volume"] != "" && nodeValues["storage.images_volume"] == s.GlobalConfig.ImagesVolume() {
+				return fmt.Errorf("Storage volume %q is already used by all members through %q", nodeValues["storage.images_volume"], "cluster.images_volume")
+			}
+
 			err := daemonStorageValidate(s, nodeValues["storage.images_volume"].(string))
 			if err != nil {
 				return fmt.Errorf("Failed validation of %q: %w", "storage.images_volume", err)
@@ -699,6 +760,19 @@ func doAPI10Update(d *Daemon, r *http.Request, req api.ServerPut, patch bool) re
 			oldClusterConfig[k] = v
 		}
 
+		// Instance names can only be required to be unique once they are.
+		uniqueNames, _ := req.Config["instances.unique_names"].(string)
+		if shared.IsTrue(uniqueNames) && !newClusterConfig.InstancesUniqueNames() {
+			duplicateNames, err := tx.GetDuplicateInstanceNames(ctx)
+			if err != nil {
+				return fmt.Errorf("Failed checking instance name uniqueness: %w", err)
+			}
+
+			if len(duplicateNames) > 0 {
+				return api.StatusErrorf(http.StatusBadRequest, "Cannot enable %q while instance names are used in more than one project: %s", "instances.unique_names", strings.Join(duplicateNames, ", "))
+			}
+		}
+
 		if patch {
 			clusterChanged, err = newClusterConfig.Patch(req.Config)
 		} else {
@@ -745,6 +819,15 @@ func doAPI10Update(d *Daemon, r *http.Request, req api.ServerPut, patch bool) re
 		}
 	})
 
+	// Validate the shared storage volumes.
+	value, ok := clusterChanged["cluster.images_volume"]
+	if ok {
+		err := daemonStorageValidateShared(s, value)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Failed validation of %q: %w", "cluster.images_volume", err))
+		}
+	}
+
 	// Notify the other nodes about changes
 	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
@@ -797,6 +880,7 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 	bgpChanged := false
 	dnsChanged := false
 	lokiChanged := false
+	natsChanged := false
 	acmeDomainChanged := false
 	acmeCAURLChanged := false
 	oidcChanged := false
@@ -832,6 +916,16 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 				d.taskPruneImages.Reset()
 			}
 
+		case "instances.usage.interval":
+			if !s.OS.MockMode {
+				d.taskInstanceUsageSample.Reset()
+			}
+
+		case "database.snapshots.interval", "database.snapshots.s3.url", "database.snapshots.s3.bucket":
+			if !s.OS.MockMode {
+				d.taskDatabaseSnapshots.Reset()
+			}
+
 		case "core.bgp_asn":
 			bgpChanged = true
 		case "loki.api.url":
@@ -850,9 +944,11 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 			fallthrough
 		case "loki.types":
 			lokiChanged = true
+		case "nats.url", "nats.auth.username", "nats.auth.password", "nats.auth.token", "nats.ca_cert", "nats.subject", "nats.types":
+			natsChanged = true
 		case "acme.ca_url":
 			acmeCAURLChanged = true
-		case "acme.domain":
+		case "acme.domain", "acme.metrics.domain", "acme.storage_buckets.domain", "acme.events.domain", "acme.migration.domain":
 			acmeDomainChanged = true
 		case "oidc.issuer", "oidc.client.id", "oidc.audience", "oidc.groups.claim":
 			oidcChanged = true
@@ -923,9 +1019,25 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	value, ok = nodeChanged["core.events_address"]
+	if ok {
+		err := s.Endpoints.EventsUpdateAddress(value, s.Endpoints.NetworkCert())
+		if err != nil {
+			return err
+		}
+	}
+
+	value, ok = nodeChanged["core.migration_address"]
+	if ok {
+		err := s.Endpoints.MigrationUpdateAddress(value, s.Endpoints.NetworkCert())
+		if err != nil {
+			return err
+		}
+	}
+
 	value, ok = nodeChanged["storage.backups_volume"]
 	if ok {
-		err := daemonStorageMove(s, "backups", value)
+		err := daemonStorageMove(s, "backups", value, false, false)
 		if err != nil {
 			return err
 		}
@@ -933,7 +1045,27 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 
 	value, ok = nodeChanged["storage.images_volume"]
 	if ok {
-		err := daemonStorageMove(s, "images", value)
+		// Fall back to the volume shared by all cluster members.
+		sharedVolume := clusterConfig.ImagesVolume()
+		sourceShared := sharedVolume != "" && daemonStorageIsOnVolume("images", sharedVolume)
+		targetShared := value == "" && sharedVolume != ""
+		if targetShared {
+			value = sharedVolume
+		}
+
+		err := daemonStorageMove(s, "images", value, sourceShared, targetShared)
+		if err != nil {
+			return err
+		}
+	}
+
+	value, ok = clusterChanged["cluster.images_volume"]
+	if ok && nodeConfig.StorageImagesVolume() == "" {
+		// Without a member specific volume, images stored on a volume are on the previous shared volume.
+		_, err := os.Readlink(shared.VarPath("images"))
+		sourceShared := err == nil
+
+		err = daemonStorageMove(s, "images", value, sourceShared, value != "")
 		if err != nil {
 			return err
 		}
@@ -985,6 +1117,23 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	if natsChanged {
+		natsURL, natsUsername, natsPassword, natsToken, natsCACert, natsSubject, natsTypes := clusterConfig.NATSServer()
+
+		err := d.setupNATS(natsURL, natsUsername, natsPassword, natsToken, natsCACert, natsSubject, natsTypes)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, listener := range acmeListeners {
+		value, ok := clusterChanged["acme."+listener+".domain"]
+		if ok && value == "" {
+			// Go back to using the server certificate.
+			acmeListenerUpdateCert(s, listener, nil)
+		}
+	}
+
 	if acmeCAURLChanged || acmeDomainChanged {
 		err := autoRenewCertificate(s.ShutdownCtx, d, acmeCAURLChanged)
 		if err != nil {
@@ -1503,6 +1503,11 @@ func clusterNodeGet(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
+		memberInfo.Evacuation, err = evacuationInstanceStates(ctx, tx, member.Name)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -2985,6 +2990,22 @@ func clusterNodeStatePost(d *Daemon, r *http.Request) response.Response {
 		stopFunc := func(inst instance.Instance) error {
 			l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
+			// Save the state of the instance if requested so that it resumes where it left off when restored.
+			if req.Mode == "stateful-stop" || (req.Mode == "" && inst.ExpandedConfig()["cluster.evacuate"] == "stateful-stop") {
+				err := inst.Stop(true)
+				if err == nil {
+					// Mark the instance as RUNNING in volatile so its state can be properly restored.
+					err = inst.VolatileSet(map[string]string{"volatile.last_state.power": instance.PowerStateRunning})
+					if err != nil {
+						l.Warn("Failed to set instance state to RUNNING", logger.Ctx{"err": err})
+					}
+
+					return nil
+				}
+
+				l.Warn("Failed stateful stop of instance, shutting it down instead", logger.Ctx{"err": err})
+			}
+
 			// Get the shutdown timeout for the instance.
 			timeout := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
 			val, err := strconv.Atoi(timeout)
@@ -3015,12 +3036,13 @@ func clusterNodeStatePost(d *Daemon, r *http.Request) response.Response {
 
 		migrateFunc := func(s *state.State, r *http.Request, inst instance.Instance, targetMemberInfo *db.NodeInfo, live bool, startInstance bool, metadata map[string]any, op *operations.Operation) error {
 			// Migrate the instance.
-			req := api.InstancePost{
-				Name: inst.Name(),
-				Live: live,
+			instReq := api.InstancePost{
+				Name:             inst.Name(),
+				Live:             live,
+				AllowVersionSkew: req.AllowVersionSkew,
 			}
 
-			err := migrateInstance(s, r, inst, targetMemberInfo.Name, req, op)
+			err := migrateInstance(s, r, inst, targetMemberInfo.Name, instReq, op)
 			if err != nil {
 				return fmt.Errorf("Failed to migrate instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
 			}
@@ -3057,7 +3079,7 @@ func clusterNodeStatePost(d *Daemon, r *http.Request) response.Response {
 
 		return evacuateClusterMember(s, d.gateway, r, req.Mode, stopFunc, migrateFunc)
 	} else if req.Action == "restore" {
-		return restoreClusterMember(d, r)
+		return restoreClusterMember(d, r, req.AllowVersionSkew)
 	}
 
 	return response.BadRequest(fmt.Errorf("Unknown action %q", req.Action))
@@ -3174,7 +3196,16 @@ func evacuateClusterMember(s *state.State, gateway *cluster.Gateway, r *http.Req
 
 	// The instances are retrieved in a separate transaction, after the node is in EVACUATED state.
 	var dbInstances []dbCluster.Instance
+	var resume bool
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		member, err := tx.GetNodeByName(ctx, nodeName)
+		if err != nil {
+			return fmt.Errorf("Failed to get cluster member by name: %w", err)
+		}
+
+		// An already evacuated member is resumed, to retry the instances which weren't evacuated.
+		resume = member.State == db.ClusterMemberStateEvacuated
+
 		// If evacuating, consider only the instances on the node which needs to be evacuated.
 		dbInstances, err = dbCluster.GetInstances(ctx, tx.Tx(), dbCluster.InstanceFilter{Node: &nodeName})
 		if err != nil {
@@ -3187,33 +3218,37 @@ func evacuateClusterMember(s *state.State, gateway *cluster.Gateway, r *http.Req
 		return response.SmartError(err)
 	}
 
-	instances := make([]instance.Instance, len(dbInstances))
+	instances := make([]instance.Instance, 0, len(dbInstances))
 
-	for i, dbInst := range dbInstances {
+	for _, dbInst := range dbInstances {
 		inst, err := instance.LoadByProjectAndName(s, dbInst.Project, dbInst.Name)
 		if err != nil {
 			return response.SmartError(fmt.Errorf("Failed to load instance: %w", err))
 		}
 
-		instances[i] = inst
+		// Instances which were stopped in place by a previous evacuation attempt are done.
+		if resume && inst.LocalConfig()["volatile.evacuate.state"] == "stopped" {
+			continue
+		}
+
+		instances = append(instances, inst)
 	}
 
-	run := func(op *operations.Operation) error {
-		// Setup a reverter.
-		revert := revert.New()
-		defer revert.Fail()
+	if resume && len(instances) == 0 {
+		return response.BadRequest(fmt.Errorf("Cluster member is already evacuated"))
+	}
 
+	run := func(op *operations.Operation) error {
 		// Set node status to EVACUATED.
-		err := evacuateClusterSetState(s, nodeName, db.ClusterMemberStateEvacuated)
-		if err != nil {
-			return err
+		// The member stays evacuated if some instances fail to be evacuated, so that the evacuation can be
+		// resumed or rolled back by restoring the member.
+		if !resume {
+			err := evacuateClusterSetState(s, nodeName, db.ClusterMemberStateEvacuated)
+			if err != nil {
+				return err
+			}
 		}
 
-		// Ensure node is put into its previous state if anything fails.
-		revert.Add(func() {
-			_ = evacuateClusterSetState(s, nodeName, db.ClusterMemberStateCreated)
-		})
-
 		ctx := context.TODO()
 
 		opts := evacuateOpts{
@@ -3228,13 +3263,7 @@ func evacuateClusterMember(s *state.State, gateway *cluster.Gateway, r *http.Req
 			op:              op,
 		}
 
-		err = evacuateInstances(ctx, opts)
-		if err != nil {
-			return err
-		}
-
-		revert.Success()
-		return nil
+		return evacuateInstances(ctx, opts)
 	}
 
 	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterMemberEvacuate, nil, nil, run, nil, nil, r)
@@ -3245,99 +3274,202 @@ func evacuateClusterMember(s *state.State, gateway *cluster.Gateway, r *http.Req
 	return operations.OperationResponse(op)
 }
 
+// evacuateInstances evacuates the instances one by one, recording the outcome of each of them in its
+// volatile.evacuate.state key. A failure doesn't stop the evacuation of the other instances.
 func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 	if opts.migrateInstance == nil {
 		return fmt.Errorf("Missing migration callback function")
 	}
 
 	metadata := make(map[string]any)
+	failures := make([]error, 0)
 
 	for _, inst := range opts.instances {
-		instProject := inst.Project()
-		l := logger.AddContext(logger.Ctx{"project": instProject.Name, "instance": inst.Name()})
-
-		// Check if migratable.
-		migrate, live := inst.CanMigrate()
-
-		// Apply overrides.
-		if opts.mode != "" {
-			if opts.mode == "stop" {
-				migrate = false
-				live = false
-			} else if opts.mode == "migrate" {
-				migrate = true
-				live = false
-			} else if opts.mode == "live-migrate" {
-				migrate = true
-				live = true
-			}
+		err := evacuateInstance(ctx, opts, inst, metadata)
+		if err != nil {
+			logger.Warn("Failed evacuating instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			failures = append(failures, fmt.Errorf("Instance %q in project %q: %w", inst.Name(), inst.Project().Name, err))
+
+			evacuateInstanceSetState(opts.s, inst.Project().Name, inst.Name(), "failed", err)
 		}
+	}
 
-		// Stop the instance if needed.
-		isRunning := inst.IsRunning()
-		if opts.stopInstance != nil && isRunning && !(migrate && live) {
-			metadata["evacuation_progress"] = fmt.Sprintf("Stopping %q in project %q", inst.Name(), instProject.Name)
-			_ = opts.op.UpdateMetadata(metadata)
+	if len(failures) > 0 {
+		return fmt.Errorf("Failed evacuating %d instance(s), evacuate the member again to retry or restore it to roll back: %w", len(failures), errors.Join(failures...))
+	}
 
-			err := opts.stopInstance(inst)
-			if err != nil {
-				return err
-			}
+	return nil
+}
+
+// evacuateInstance stops or migrates a single instance away from the evacuated member.
+func evacuateInstance(ctx context.Context, opts evacuateOpts, inst instance.Instance, metadata map[string]any) error {
+	instProject := inst.Project()
+	l := logger.AddContext(logger.Ctx{"project": instProject.Name, "instance": inst.Name()})
+
+	// Check if migratable.
+	migrate, live := inst.CanMigrate()
+
+	// Apply overrides.
+	if opts.mode != "" {
+		if opts.mode == "stop" || opts.mode == "stateful-stop" {
+			migrate = false
+			live = false
+		} else if opts.mode == "migrate" {
+			migrate = true
+			live = false
+		} else if opts.mode == "live-migrate" {
+			migrate = true
+			live = true
 		}
+	}
 
-		// If not migratable, the instance is just stopped.
-		if !migrate {
-			continue
+	// Stop the instance if needed.
+	isRunning := inst.IsRunning()
+	if opts.stopInstance != nil && isRunning && !(migrate && live) {
+		metadata["evacuation_progress"] = fmt.Sprintf("Stopping %q in project %q", inst.Name(), instProject.Name)
+		_ = opts.op.UpdateMetadata(metadata)
+
+		err := opts.stopInstance(inst)
+		if err != nil {
+			return err
 		}
+	}
 
-		// Get candidate cluster members to move instances to.
-		var candidateMembers []db.NodeInfo
-		err := opts.s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
-			allMembers, err := tx.GetNodes(ctx)
-			if err != nil {
-				return fmt.Errorf("Failed getting cluster members: %w", err)
-			}
+	// If not migratable, the instance is just stopped.
+	if !migrate {
+		evacuateInstanceSetState(opts.s, instProject.Name, inst.Name(), "stopped", nil)
+		return nil
+	}
 
-			candidateMembers, err = tx.GetCandidateMembers(ctx, allMembers, []int{inst.Architecture()}, "", nil, opts.s.GlobalConfig.OfflineThreshold())
-			if err != nil {
-				return err
-			}
+	// Get candidate cluster members to move instances to.
+	var candidateMembers []db.NodeInfo
+	err := opts.s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		allMembers, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
 
-			return nil
-		})
+		candidateMembers, err = tx.GetCandidateMembers(ctx, allMembers, []int{inst.Architecture()}, "", nil, opts.s.GlobalConfig.OfflineThreshold())
 		if err != nil {
 			return err
 		}
 
-		targetMemberInfo, err := evacuateClusterSelectTarget(ctx, opts.s, opts.gateway, inst, candidateMembers)
-		if err != nil {
-			if api.StatusErrorCheck(err, http.StatusNotFound) {
-				// Skip migration if no target is available
-				l.Warn("No migration target available for instance")
-				continue
-			}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	targetMemberInfo, err := evacuateClusterSelectTarget(ctx, opts.s, opts.gateway, inst, candidateMembers)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			// Skip migration if no target is available
+			l.Warn("No migration target available for instance")
+			evacuateInstanceSetState(opts.s, instProject.Name, inst.Name(), "skipped", nil)
+			return nil
 		}
 
-		// Start migrating the instance.
-		metadata["evacuation_progress"] = fmt.Sprintf("Migrating %q in project %q to %q", inst.Name(), instProject.Name, targetMemberInfo.Name)
-		_ = opts.op.UpdateMetadata(metadata)
+		return err
+	}
+
+	// Start migrating the instance.
+	metadata["evacuation_progress"] = fmt.Sprintf("Migrating %q in project %q to %q", inst.Name(), instProject.Name, targetMemberInfo.Name)
+	_ = opts.op.UpdateMetadata(metadata)
+
+	// Set origin server (but skip if already set as that suggests more than one server being evacuated).
+	volatile := map[string]string{"volatile.evacuate.state": "migrating", "volatile.evacuate.error": ""}
+	if inst.LocalConfig()["volatile.evacuate.origin"] == "" {
+		volatile["volatile.evacuate.origin"] = opts.srcMemberName
+	}
+
+	err = inst.VolatileSet(volatile)
+	if err != nil {
+		return err
+	}
+
+	start := isRunning || instanceShouldAutoStart(inst)
+	err = opts.migrateInstance(opts.s, opts.r, inst, targetMemberInfo, live, start, metadata, opts.op)
+	if err != nil {
+		return err
+	}
+
+	evacuateInstanceSetState(opts.s, instProject.Name, inst.Name(), "migrated", nil)
+
+	return nil
+}
+
+// evacuateInstanceSetState records the evacuation state of an instance, along with the error if it failed.
+// An empty state clears the evacuation state and origin of the instance.
+// As the instance may have moved to another member, it is reloaded from the database.
+func evacuateInstanceSetState(s *state.State, projectName string, instanceName string, evacuationState string, evacuationErr error) {
+	inst, err := instance.LoadByProjectAndName(s, projectName, instanceName)
+	if err != nil {
+		logger.Warn("Failed loading instance to record its evacuation state", logger.Ctx{"project": projectName, "instance": instanceName, "err": err})
+		return
+	}
+
+	volatile := map[string]string{"volatile.evacuate.state": evacuationState, "volatile.evacuate.error": ""}
+	if evacuationState == "" {
+		volatile["volatile.evacuate.origin"] = ""
+	}
+
+	if evacuationErr != nil {
+		volatile["volatile.evacuate.error"] = evacuationErr.Error()
+	}
+
+	err = inst.VolatileSet(volatile)
+	if err != nil {
+		logger.Warn("Failed recording evacuation state of instance", logger.Ctx{"project": projectName, "instance": instanceName, "err": err})
+	}
+}
+
+// evacuationInstanceStates returns the evacuation state of the instances on, or evacuated from, the given member.
+func evacuationInstanceStates(ctx context.Context, tx *db.ClusterTx, memberName string) ([]api.ClusterMemberEvacuationInstance, error) {
+	keys := []string{"volatile.evacuate.state", "volatile.evacuate.error", "volatile.evacuate.origin"}
+	filters := make([]dbCluster.ConfigFilter, 0, len(keys))
+	for i := range keys {
+		filters = append(filters, dbCluster.ConfigFilter{Key: &keys[i]})
+	}
 
-		// Set origin server (but skip if already set as that suggests more than one server being evacuated).
-		if inst.LocalConfig()["volatile.evacuate.origin"] == "" {
-			_ = inst.VolatileSet(map[string]string{"volatile.evacuate.origin": opts.srcMemberName})
+	config, err := dbCluster.GetConfig(ctx, tx.Tx(), "instance", filters...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance evacuation state: %w", err)
+	}
+
+	if len(config) == 0 {
+		return nil, nil
+	}
+
+	dbInstances, err := dbCluster.GetInstances(ctx, tx.Tx())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get instances: %w", err)
+	}
+
+	states := []api.ClusterMemberEvacuationInstance{}
+	for _, dbInst := range dbInstances {
+		evacuationState := config[dbInst.ID]["volatile.evacuate.state"]
+		if evacuationState == "" {
+			continue
 		}
 
-		start := isRunning || instanceShouldAutoStart(inst)
-		err = opts.migrateInstance(opts.s, opts.r, inst, targetMemberInfo, live, start, metadata, opts.op)
-		if err != nil {
-			return err
+		origin := config[dbInst.ID]["volatile.evacuate.origin"]
+		if dbInst.Node != memberName && origin != memberName {
+			continue
 		}
+
+		states = append(states, api.ClusterMemberEvacuationInstance{
+			Project:  dbInst.Project,
+			Name:     dbInst.Name,
+			Location: dbInst.Node,
+			State:    evacuationState,
+			Error:    config[dbInst.ID]["volatile.evacuate.error"],
+		})
 	}
 
-	return nil
+	return states, nil
 }
 
-func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
+func restoreClusterMember(d *Daemon, r *http.Request, allowVersionSkew bool) response.Response {
 	s := d.State()
 
 	originName, err := url.PathUnescape(mux.Vars(r)["name"])
@@ -3398,170 +3530,208 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 			_ = evacuateClusterSetState(s, originName, db.ClusterMemberStateEvacuated)
 		})
 
-		var source lxd.InstanceServer
-		var sourceNode db.NodeInfo
-
 		metadata := make(map[string]any)
+		failures := make([]error, 0)
 
 		// Restart the local instances.
 		for _, inst := range localInstances {
-			// Don't start instances which were stopped by the user.
-			if inst.LocalConfig()["volatile.last_state.power"] != instance.PowerStateRunning {
+			err := restoreLocalInstance(inst, metadata, op)
+			if err != nil {
+				logger.Warn("Failed restoring instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+				failures = append(failures, fmt.Errorf("Instance %q in project %q: %w", inst.Name(), inst.Project().Name, err))
+				evacuateInstanceSetState(s, inst.Project().Name, inst.Name(), "failed", err)
 				continue
 			}
 
-			// Don't attempt to start instances which are already running.
-			if inst.IsRunning() {
-				continue
+			if inst.LocalConfig()["volatile.evacuate.state"] != "" || inst.LocalConfig()["volatile.evacuate.origin"] != "" {
+				evacuateInstanceSetState(s, inst.Project().Name, inst.Name(), "", nil)
 			}
+		}
 
-			// Start the instance.
-			metadata["evacuation_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name)
-			_ = op.UpdateMetadata(metadata)
-
-			err = inst.Start(false)
+		// Migrate back the remote instances.
+		for _, inst := range instances {
+			err := restoreRemoteInstance(s, r, inst, originName, allowVersionSkew, metadata, op)
 			if err != nil {
-				return fmt.Errorf("Failed to start instance %q: %w", inst.Name(), err)
+				logger.Warn("Failed restoring instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+				failures = append(failures, fmt.Errorf("Instance %q in project %q: %w", inst.Name(), inst.Project().Name, err))
+				evacuateInstanceSetState(s, inst.Project().Name, inst.Name(), "failed", err)
 			}
 		}
 
-		// Migrate back the remote instances.
-		for _, inst := range instances {
-			l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+		if len(failures) > 0 {
+			return fmt.Errorf("Failed restoring %d instance(s), restore the member again to retry: %w", len(failures), errors.Join(failures...))
+		}
 
-			// Check if live-migratable.
-			_, live := inst.CanMigrate()
+		revert.Success()
+		return nil
+	}
 
-			metadata["evacuation_progress"] = fmt.Sprintf("Migrating %q in project %q from %q", inst.Name(), inst.Project().Name, inst.Location())
-			_ = op.UpdateMetadata(metadata)
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterMemberRestore, nil, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
 
-			err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-				sourceNode, err = tx.GetNodeByName(ctx, inst.Location())
-				if err != nil {
-					return fmt.Errorf("Failed to get node %q: %w", inst.Location(), err)
-				}
+	return operations.OperationResponse(op)
+}
 
-				return nil
-			})
-			if err != nil {
-				return fmt.Errorf("Failed to get node: %w", err)
-			}
+// restoreLocalInstance starts an instance which was stopped in place during the evacuation of its member.
+func restoreLocalInstance(inst instance.Instance, metadata map[string]any, op *operations.Operation) error {
+	// Don't start instances which were stopped by the user.
+	if inst.LocalConfig()["volatile.last_state.power"] != instance.PowerStateRunning {
+		return nil
+	}
 
-			source, err = cluster.Connect(sourceNode.Address, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
-			if err != nil {
-				return fmt.Errorf("Failed to connect to source: %w", err)
-			}
+	// Don't attempt to start instances which are already running.
+	if inst.IsRunning() {
+		return nil
+	}
 
-			source = source.UseProject(inst.Project().Name)
+	// Start the instance.
+	metadata["evacuation_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name)
+	_ = op.UpdateMetadata(metadata)
 
-			apiInst, _, err := source.GetInstance(inst.Name())
-			if err != nil {
-				return fmt.Errorf("Failed to get instance %q: %w", inst.Name(), err)
-			}
+	// Restore the state saved during evacuation if any.
+	err := inst.Start(inst.IsStateful())
+	if err != nil {
+		return fmt.Errorf("Failed to start instance %q: %w", inst.Name(), err)
+	}
 
-			isRunning := apiInst.StatusCode == api.Running
-			if isRunning && !live {
-				metadata["evacuation_progress"] = fmt.Sprintf("Stopping %q in project %q", inst.Name(), inst.Project().Name)
-				_ = op.UpdateMetadata(metadata)
+	return nil
+}
 
-				timeout := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
-				val, err := strconv.Atoi(timeout)
-				if err != nil {
-					val = evacuateHostShutdownDefaultTimeout
-				}
+// restoreRemoteInstance migrates an instance back to the restored member it was evacuated from.
+func restoreRemoteInstance(s *state.State, r *http.Request, inst instance.Instance, originName string, allowVersionSkew bool, metadata map[string]any, op *operations.Operation) error {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
-				// Attempt a clean stop.
-				stopOp, err := source.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "stop", Force: false, Timeout: val}, "")
-				if err != nil {
-					return fmt.Errorf("Failed to stop instance %q: %w", inst.Name(), err)
-				}
+	// Check if live-migratable.
+	_, live := inst.CanMigrate()
 
-				// Wait for the stop operation to complete or timeout.
-				err = stopOp.Wait()
-				if err != nil {
-					l.Warn("Failed shutting down instance, forcing stop", logger.Ctx{"err": err})
+	metadata["evacuation_progress"] = fmt.Sprintf("Migrating %q in project %q from %q", inst.Name(), inst.Project().Name, inst.Location())
+	_ = op.UpdateMetadata(metadata)
 
-					// On failure, attempt a forceful stop.
-					stopOp, err = source.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "stop", Force: true}, "")
-					if err != nil {
-						// If this fails too, fail the whole operation.
-						return fmt.Errorf("Failed to stop instance %q: %w", inst.Name(), err)
-					}
+	var sourceNode db.NodeInfo
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
 
-					// Wait for the forceful stop to complete.
-					err = stopOp.Wait()
-					if err != nil && !strings.Contains(err.Error(), "The instance is already stopped") {
-						return fmt.Errorf("Failed to stop instance %q: %w", inst.Name(), err)
-					}
-				}
-			}
+		sourceNode, err = tx.GetNodeByName(ctx, inst.Location())
+		if err != nil {
+			return fmt.Errorf("Failed to get node %q: %w", inst.Location(), err)
+		}
 
-			req := api.InstancePost{
-				Name:      inst.Name(),
-				Migration: true,
-				Live:      live,
-			}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to get node: %w", err)
+	}
+
+	source, err := cluster.Connect(sourceNode.Address, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to source: %w", err)
+	}
 
-			source = source.UseTarget(originName)
+	source = source.UseProject(inst.Project().Name)
 
-			migrationOp, err := source.MigrateInstance(inst.Name(), req)
-			if err != nil {
-				return fmt.Errorf("Migration API failure: %w", err)
-			}
+	apiInst, _, err := source.GetInstance(inst.Name())
+	if err != nil {
+		return fmt.Errorf("Failed to get instance %q: %w", inst.Name(), err)
+	}
 
-			err = migrationOp.Wait()
-			if err != nil {
-				return fmt.Errorf("Failed to wait for migration to finish: %w", err)
-			}
+	isRunning := apiInst.StatusCode == api.Running
+	if isRunning && !live {
+		metadata["evacuation_progress"] = fmt.Sprintf("Stopping %q in project %q", inst.Name(), inst.Project().Name)
+		_ = op.UpdateMetadata(metadata)
 
-			// Reload the instance after migration.
-			inst, err := instance.LoadByProjectAndName(s, inst.Project().Name, inst.Name())
-			if err != nil {
-				return fmt.Errorf("Failed to load instance: %w", err)
-			}
+		timeout := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
+		val, err := strconv.Atoi(timeout)
+		if err != nil {
+			val = evacuateHostShutdownDefaultTimeout
+		}
 
-			config := inst.LocalConfig()
-			delete(config, "volatile.evacuate.origin")
-
-			args := db.InstanceArgs{
-				Architecture: inst.Architecture(),
-				Config:       config,
-				Description:  inst.Description(),
-				Devices:      inst.LocalDevices(),
-				Ephemeral:    inst.IsEphemeral(),
-				Profiles:     inst.Profiles(),
-				Project:      inst.Project().Name,
-				ExpiryDate:   inst.ExpiryDate(),
-			}
+		// Attempt a clean stop.
+		stopOp, err := source.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "stop", Force: false, Timeout: val}, "")
+		if err != nil {
+			return fmt.Errorf("Failed to stop instance %q: %w", inst.Name(), err)
+		}
 
-			err = inst.Update(args, false)
+		// Wait for the stop operation to complete or timeout.
+		err = stopOp.Wait()
+		if err != nil {
+			l.Warn("Failed shutting down instance, forcing stop", logger.Ctx{"err": err})
+
+			// On failure, attempt a forceful stop.
+			stopOp, err = source.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "stop", Force: true}, "")
 			if err != nil {
-				return fmt.Errorf("Failed to update instance %q: %w", inst.Name(), err)
+				// If this fails too, fail the whole operation.
+				return fmt.Errorf("Failed to stop instance %q: %w", inst.Name(), err)
 			}
 
-			if !isRunning || live {
-				continue
+			// Wait for the forceful stop to complete.
+			err = stopOp.Wait()
+			if err != nil && !strings.Contains(err.Error(), "The instance is already stopped") {
+				return fmt.Errorf("Failed to stop instance %q: %w", inst.Name(), err)
 			}
+		}
+	}
 
-			metadata["evacuation_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name)
-			_ = op.UpdateMetadata(metadata)
+	req := api.InstancePost{
+		Name:             inst.Name(),
+		Migration:        true,
+		Live:             live,
+		AllowVersionSkew: allowVersionSkew,
+	}
 
-			err = inst.Start(false)
-			if err != nil {
-				return fmt.Errorf("Failed to start instance %q: %w", inst.Name(), err)
-			}
-		}
+	source = source.UseTarget(originName)
 
-		revert.Success()
+	migrationOp, err := source.MigrateInstance(inst.Name(), req)
+	if err != nil {
+		return fmt.Errorf("Migration API failure: %w", err)
+	}
+
+	err = migrationOp.Wait()
+	if err != nil {
+		return fmt.Errorf("Failed to wait for migration to finish: %w", err)
+	}
+
+	// Reload the instance after migration.
+	inst, err = instance.LoadByProjectAndName(s, inst.Project().Name, inst.Name())
+	if err != nil {
+		return fmt.Errorf("Failed to load instance: %w", err)
+	}
+
+	config := inst.LocalConfig()
+	delete(config, "volatile.evacuate.origin")
+	delete(config, "volatile.evacuate.state")
+	delete(config, "volatile.evacuate.error")
+
+	args := db.InstanceArgs{
+		Architecture: inst.Architecture(),
+		Config:       config,
+		Description:  inst.Description(),
+		Devices:      inst.LocalDevices(),
+		Ephemeral:    inst.IsEphemeral(),
+		Profiles:     inst.Profiles(),
+		Project:      inst.Project().Name,
+		ExpiryDate:   inst.ExpiryDate(),
+	}
+
+	err = inst.Update(args, false)
+	if err != nil {
+		return fmt.Errorf("Failed to update instance %q: %w", inst.Name(), err)
+	}
+
+	if !isRunning || live {
 		return nil
 	}
 
-	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterMemberRestore, nil, nil, run, nil, nil, r)
+	metadata["evacuation_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name)
+	_ = op.UpdateMetadata(metadata)
+
+	err = inst.Start(false)
 	if err != nil {
-		return response.InternalError(err)
+		return fmt.Errorf("Failed to start instance %q: %w", inst.Name(), err)
 	}
 
-	return operations.OperationResponse(op)
+	return nil
 }
 
 // swagger:operation POST /1.0/cluster/groups cluster cluster_groups_post
@@ -13,19 +13,25 @@ import (
 	runtimeDebug "runtime/debug"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"golang.org/x/sys/unix"
 
+	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/backup"
+	"github.com/canonical/lxd/lxd/benchmark"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
 	"github.com/canonical/lxd/lxd/db/query"
 	"github.com/canonical/lxd/lxd/db/warningtype"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/fault"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
@@ -42,6 +48,8 @@ import (
 )
 
 var apiInternal = []APIEndpoint{
+	internalACMEListenerCertificateCmd,
+	internalBenchmarkCmd,
 	internalBGPStateCmd,
 	internalClusterAcceptCmd,
 	internalClusterAssignCmd,
@@ -52,9 +60,14 @@ var apiInternal = []APIEndpoint{
 	internalContainerOnStartCmd,
 	internalContainerOnStopCmd,
 	internalContainerOnStopNSCmd,
+	internalFaultsCmd,
 	internalGarbageCollectorCmd,
 	internalImageOptimizeCmd,
 	internalImageRefreshCmd,
+	internalPatchesStatusCmd,
+	internalPatchesLogCmd,
+	internalPatchRerunCmd,
+	internalPatchesVerifyCmd,
 	internalRAFTSnapshotCmd,
 	internalReadyCmd,
 	internalShutdownCmd,
@@ -63,6 +76,12 @@ var apiInternal = []APIEndpoint{
 	internalIdentityCacheRefreshCmd,
 }
 
+var internalACMEListenerCertificateCmd = APIEndpoint{
+	Path: "acme/listeners/{listener}",
+
+	Put: APIEndpointAction{Handler: internalACMEListenerCertificate, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
 var internalShutdownCmd = APIEndpoint{
 	Path: "shutdown",
 
@@ -75,6 +94,30 @@ var internalReadyCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: internalWaitReady, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
 }
 
+var internalPatchesStatusCmd = APIEndpoint{
+	Path: "patches/status",
+
+	Get: APIEndpointAction{Handler: internalPatchesStatus, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var internalPatchesLogCmd = APIEndpoint{
+	Path: "patches/log",
+
+	Get: APIEndpointAction{Handler: internalPatchesLog, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var internalPatchRerunCmd = APIEndpoint{
+	Path: "patches/{name}/rerun",
+
+	Post: APIEndpointAction{Handler: internalPatchRerun, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var internalPatchesVerifyCmd = APIEndpoint{
+	Path: "patches/verify",
+
+	Get: APIEndpointAction{Handler: internalPatchesVerify, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
 var internalContainerOnStartCmd = APIEndpoint{
 	Path: "containers/{instanceRef}/onstart",
 
@@ -130,6 +173,20 @@ var internalWarningCreateCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: internalCreateWarning, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
 }
 
+var internalFaultsCmd = APIEndpoint{
+	Path: "testing/faults",
+
+	Get:    APIEndpointAction{Handler: internalFaultsGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Post:   APIEndpointAction{Handler: internalFaultsPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Delete: APIEndpointAction{Handler: internalFaultsDelete, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var internalBenchmarkCmd = APIEndpoint{
+	Path: "testing/benchmark",
+
+	Post: APIEndpointAction{Handler: internalBenchmarkPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
 var internalBGPStateCmd = APIEndpoint{
 	Path: "testing/bgp",
 
@@ -147,6 +204,14 @@ type internalImageOptimizePost struct {
 	Pool  string    `json:"pool"  yaml:"pool"`
 }
 
+type internalFaultPost struct {
+	Type      fault.Type `json:"type"      yaml:"type"`
+	Target    string     `json:"target"    yaml:"target"`
+	Operation string     `json:"operation" yaml:"operation"`
+	Delay     string     `json:"delay"     yaml:"delay"`
+	Expiry    string     `json:"expiry"    yaml:"expiry"`
+}
+
 type internalWarningCreatePost struct {
 	Location   string      `json:"location"    yaml:"location"`
 	Project    string      `json:"project"     yaml:"project"`
@@ -212,6 +277,68 @@ func internalRefreshImage(d *Daemon, r *http.Request) response.Response {
 	return response.EmptySyncResponse
 }
 
+// internalPatchesStatus returns the status of the patches applied since the daemon started. Unlike the public
+// API, it is available while the daemon is still starting up and applying them.
+func internalPatchesStatus(d *Daemon, r *http.Request) response.Response {
+	return response.SyncResponse(true, d.patchesStatus.list())
+}
+
+// internalPatchesLog returns the patches applied on all cluster members, oldest first, optionally filtered by the
+// patch name given in the name query parameter.
+func internalPatchesLog(d *Daemon, r *http.Request) response.Response {
+	var entries []db.PatchLogEntry
+
+	err := d.db.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		entries, err = tx.GetPatchLogEntries(ctx, request.QueryParam(r, "name"))
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, entries)
+}
+
+// internalPatchRerun clears the applied marker of a patch and applies it again, for when a patch didn't do all of
+// its work the first time around.
+func internalPatchRerun(d *Daemon, r *http.Request) response.Response {
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Don't interfere with the patches applied during startup.
+	if d.waitReady.Err() == nil {
+		return response.Unavailable(fmt.Errorf("LXD daemon not ready yet"))
+	}
+
+	err = patchRerun(d, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// internalPatchesVerify returns the entities still matching the condition fixed by the applied patches, for when a
+// patch was marked as applied without doing all of its work.
+func internalPatchesVerify(d *Daemon, r *http.Request) response.Response {
+	// Patches still being applied would be reported as incomplete.
+	if d.waitReady.Err() == nil {
+		return response.Unavailable(fmt.Errorf("LXD daemon not ready yet"))
+	}
+
+	results, err := patchesVerify(d)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, results)
+}
+
 func internalWaitReady(d *Daemon, r *http.Request) response.Response {
 	// Check that we're not shutting down.
 	isClosing := d.State().ShutdownCtx.Err() != nil
@@ -1037,6 +1164,147 @@ func internalBGPState(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, s.BGP.Debug())
 }
 
+// internalFaultsGet lists the injected faults, and is used for testing only.
+func internalFaultsGet(d *Daemon, r *http.Request) response.Response {
+	if !fault.Enabled() {
+		return response.Forbidden(fault.ErrDisabled)
+	}
+
+	return response.SyncResponse(true, fault.List())
+}
+
+// internalFaultsPost injects a fault, and is used for testing only.
+func internalFaultsPost(d *Daemon, r *http.Request) response.Response {
+	if !fault.Enabled() {
+		return response.Forbidden(fault.ErrDisabled)
+	}
+
+	req := internalFaultPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	f := fault.Fault{
+		Type:      req.Type,
+		Target:    req.Target,
+		Operation: req.Operation,
+	}
+
+	if req.Delay != "" {
+		f.Delay, err = time.ParseDuration(req.Delay)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid delay: %w", err))
+		}
+	}
+
+	if req.Expiry != "" {
+		expiry, err := time.ParseDuration(req.Expiry)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid expiry: %w", err))
+		}
+
+		f.ExpiresAt = time.Now().Add(expiry)
+	}
+
+	err = fault.Inject(f)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	logger.Warn("Injected fault", logger.Ctx{"type": f.Type, "target": f.Target, "operation": f.Operation, "delay": f.Delay, "expiresAt": f.ExpiresAt})
+
+	return response.EmptySyncResponse
+}
+
+// internalFaultsDelete removes the injected faults of the type given in the "type" query parameter (all if empty),
+// and is used for testing only.
+func internalFaultsDelete(d *Daemon, r *http.Request) response.Response {
+	if !fault.Enabled() {
+		return response.Forbidden(fault.ErrDisabled)
+	}
+
+	faultType := fault.Type(request.QueryParam(r, "type"))
+	if faultType != "" && !shared.ValueInSlice(faultType, fault.Types) {
+		return response.BadRequest(fmt.Errorf("Invalid fault type %q", faultType))
+	}
+
+	fault.Clear(faultType)
+	logger.Warn("Cleared injected faults", logger.Ctx{"type": faultType})
+
+	return response.EmptySyncResponse
+}
+
+// internalBenchmarkPost runs a benchmark against the local member in the requested project, and is used for
+// testing only. The benchmark report is the metadata of the returned operation.
+func internalBenchmarkPost(d *Daemon, r *http.Request) response.Response {
+	if !benchmark.Enabled() {
+		return response.Forbidden(benchmark.ErrDisabled)
+	}
+
+	s := d.State()
+	projectName := request.ProjectParam(r)
+
+	config := benchmark.Config{}
+	err := json.NewDecoder(r.Body).Decode(&config)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Networks are created on all members of a cluster, which doesn't measure the local member.
+	if config.Networks > 0 && s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("Networks can only be benchmarked on standalone servers"))
+	}
+
+	// Go through the API, so that the whole path of the requests is measured.
+	client, err := lxd.ConnectLXDUnix(d.UnixSocket(), nil)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed to connect to local LXD: %w", err))
+	}
+
+	c := client.UseProject(projectName)
+	if s.ServerClustered {
+		c = c.UseTarget(s.ServerName)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	run := func(op *operations.Operation) error {
+		defer cancel()
+
+		logger.Warn("Running benchmark", logger.Ctx{"project": projectName, "instances": config.Instances, "volumes": config.Volumes, "networks": config.Networks, "concurrency": config.Concurrency})
+
+		report, err := benchmark.Run(ctx, c, config, func(report benchmark.Report) {
+			_ = op.UpdateMetadata(report)
+		})
+		if err != nil {
+			return err
+		}
+
+		logger.Warn("Finished benchmark", logger.Ctx{"project": projectName, "duration": report.Duration})
+
+		return op.UpdateMetadata(report)
+	}
+
+	onCancel := func(op *operations.Operation) error {
+		cancel()
+		return nil
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.Benchmark, nil, nil, run, onCancel, nil, r)
+	if err != nil {
+		cancel()
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
 func internalIdentityCacheRefresh(d *Daemon, r *http.Request) response.Response {
 	logger.Debug("Received identity cache update notification - refreshing cache")
 	d.State().UpdateIdentityCache()
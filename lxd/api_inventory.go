@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/units"
+)
+
+var inventoryCmd = APIEndpoint{
+	Path: "inventory",
+
+	Get: APIEndpointAction{Handler: inventoryGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewResources)},
+}
+
+// inventoryCSVHeader is the header row of the CSV inventory export.
+var inventoryCSVHeader = []string{"type", "project", "name", "kind", "locations", "pool", "size"}
+
+// swagger:operation GET /1.0/inventory server inventory_get
+//
+//	Export the resource inventory
+//
+//	Streams the list of all cluster members, instances, custom storage volumes, networks and images
+//	of all projects, with their placement and size.
+//
+//	The `format` query parameter selects between a JSON array of `InventoryItem` (default)
+//	and CSV with a header row.
+//
+//	---
+//	produces:
+//	  - application/json
+//	  - text/csv
+//	parameters:
+//	  - in: query
+//	    name: format
+//	    description: Output format (json or csv)
+//	    type: string
+//	    example: csv
+//	responses:
+//	  "200":
+//	    description: Inventory
+//	    schema:
+//	      type: array
+//	      items:
+//	        $ref: "#/definitions/InventoryItem"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func inventoryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = "json"
+	}
+
+	if !shared.ValueInSlice(format, []string{"json", "csv"}) {
+		return response.BadRequest(fmt.Errorf("Invalid inventory format %q", format))
+	}
+
+	var items []api.InventoryItem
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		items, err = inventoryItems(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		if format == "csv" {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", "attachment; filename=inventory.csv")
+			w.WriteHeader(http.StatusOK)
+
+			return inventoryWriteCSV(w, items)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		return inventoryWriteJSON(w, items)
+	})
+}
+
+// inventoryItems returns the inventory of all the resources recorded in the cluster database.
+func inventoryItems(ctx context.Context, tx *db.ClusterTx) ([]api.InventoryItem, error) {
+	items := []api.InventoryItem{}
+
+	members, err := tx.GetNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading cluster members: %w", err)
+	}
+
+	memberNames := make(map[string]string, len(members))
+	for _, member := range members {
+		memberNames[member.Address] = member.Name
+
+		architecture, _ := osarch.ArchitectureName(member.Architecture)
+
+		items = append(items, api.InventoryItem{
+			Type:      api.InventoryItemTypeMember,
+			Name:      member.Name,
+			Kind:      architecture,
+			Locations: []string{member.Address},
+		})
+	}
+
+	volumes, err := tx.GetStorageVolumes(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading storage volumes: %w", err)
+	}
+
+	// Index the instance volumes by project, type and name to find the pool and size of instances.
+	instanceVolumes := make(map[string]*db.StorageVolume)
+	for _, vol := range volumes {
+		if shared.IsSnapshot(vol.Name) {
+			continue
+		}
+
+		switch vol.Type {
+		case dbCluster.StoragePoolVolumeTypeNameContainer, dbCluster.StoragePoolVolumeTypeNameVM:
+			instanceVolumes[vol.Project+"/"+vol.Type+"/"+vol.Name] = vol
+		case dbCluster.StoragePoolVolumeTypeNameCustom:
+			items = append(items, api.InventoryItem{
+				Type:      api.InventoryItemTypeVolume,
+				Project:   vol.Project,
+				Name:      vol.Name,
+				Kind:      vol.ContentType,
+				Locations: inventoryLocations(vol.Location),
+				Pool:      vol.Pool,
+				Size:      inventoryVolumeSize(vol.Config),
+			})
+		}
+	}
+
+	instances, err := dbCluster.GetInstances(ctx, tx.Tx())
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	for _, inst := range instances {
+		item := api.InventoryItem{
+			Type:      api.InventoryItemTypeInstance,
+			Project:   inst.Project,
+			Name:      inst.Name,
+			Kind:      inst.Type.String(),
+			Locations: inventoryLocations(inst.Node),
+		}
+
+		volType := dbCluster.StoragePoolVolumeTypeNameContainer
+		if inst.Type == instancetype.VM {
+			volType = dbCluster.StoragePoolVolumeTypeNameVM
+		}
+
+		vol, ok := instanceVolumes[inst.Project+"/"+volType+"/"+inst.Name]
+		if ok {
+			item.Pool = vol.Pool
+			item.Size = inventoryVolumeSize(vol.Config)
+		}
+
+		items = append(items, item)
+	}
+
+	networks, err := tx.GetCreatedNetworks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading networks: %w", err)
+	}
+
+	for projectName, projectNetworks := range networks {
+		for _, network := range projectNetworks {
+			items = append(items, api.InventoryItem{
+				Type:      api.InventoryItemTypeNetwork,
+				Project:   projectName,
+				Name:      network.Name,
+				Kind:      network.Type,
+				Locations: []string{},
+			})
+		}
+	}
+
+	images, err := dbCluster.GetImages(ctx, tx.Tx())
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading images: %w", err)
+	}
+
+	imageLocations := make(map[string][]string)
+	for _, image := range images {
+		locations, ok := imageLocations[image.Fingerprint]
+		if !ok {
+			addresses, err := tx.GetNodesWithImage(ctx, image.Fingerprint)
+			if err != nil {
+				return nil, fmt.Errorf("Failed loading cluster members with image %q: %w", image.Fingerprint, err)
+			}
+
+			locations = make([]string, 0, len(addresses))
+			for _, address := range addresses {
+				locations = append(locations, memberNames[address])
+			}
+
+			sort.Strings(locations)
+			imageLocations[image.Fingerprint] = locations
+		}
+
+		items = append(items, api.InventoryItem{
+			Type:      api.InventoryItemTypeImage,
+			Project:   image.Project,
+			Name:      image.Fingerprint,
+			Kind:      instancetype.Type(image.Type).String(),
+			Locations: locations,
+			Size:      image.Size,
+		})
+	}
+
+	return items, nil
+}
+
+// inventoryLocations returns the locations of a resource held by the given member, if any.
+func inventoryLocations(member string) []string {
+	if member == "" {
+		return []string{}
+	}
+
+	return []string{member}
+}
+
+// inventoryVolumeSize returns the configured size of a volume in bytes, or 0 if not set.
+func inventoryVolumeSize(config map[string]string) int64 {
+	size, err := units.ParseByteSizeString(config["size"])
+	if err != nil {
+		return 0
+	}
+
+	return size
+}
+
+// inventoryWriteJSON writes the items as a JSON array, one item at a time.
+func inventoryWriteJSON(w http.ResponseWriter, items []api.InventoryItem) error {
+	_, err := w.Write([]byte("["))
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		if i > 0 {
+			_, err = w.Write([]byte(","))
+			if err != nil {
+				return err
+			}
+		}
+
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write([]byte("]\n"))
+	return err
+}
+
+// inventoryWriteCSV writes the items as CSV, preceded by a header row.
+func inventoryWriteCSV(w http.ResponseWriter, items []api.InventoryItem) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(inventoryCSVHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		err = writer.Write([]string{item.Type, item.Project, item.Name, item.Kind, strings.Join(item.Locations, " "), item.Pool, strconv.FormatInt(item.Size, 10)})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
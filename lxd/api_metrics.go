@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"runtime"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/gorilla/mux"
+
 	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
@@ -19,6 +22,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/locking"
 	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/network/openvswitch"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
@@ -42,6 +46,12 @@ var metricsCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: metricsGet, AccessHandler: allowMetrics, AllowUntrusted: true},
 }
 
+var projectMetricsCmd = APIEndpoint{
+	Path: "projects/{name}/metrics",
+
+	Get: APIEndpointAction{Handler: projectMetricsGet, AccessHandler: allowProjectMetrics, AllowUntrusted: true},
+}
+
 func allowMetrics(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
@@ -60,6 +70,16 @@ func allowMetrics(d *Daemon, r *http.Request) response.Response {
 	return allowPermission(entityType, auth.EntitlementCanViewMetrics)(d, r)
 }
 
+func allowProjectMetrics(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.GlobalConfig.MetricsAuthentication() {
+		return response.EmptySyncResponse
+	}
+
+	return allowPermission(entity.TypeProject, auth.EntitlementCanViewMetrics, "name")(d, r)
+}
+
 // swagger:operation GET /1.0/metrics metrics metrics_get
 //
 //	Get metrics
@@ -91,9 +111,51 @@ func allowMetrics(d *Daemon, r *http.Request) response.Response {
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func metricsGet(d *Daemon, r *http.Request) response.Response {
+	return metricsResponse(d, r, request.QueryParam(r, "project"), false)
+}
+
+// swagger:operation GET /1.0/projects/{name}/metrics projects project_metrics_get
+//
+//	Get the project metrics
+//
+//	Gets metrics of the instances of the project. Unlike `/1.0/metrics?project=<name>`, the response never includes
+//	the internal metrics of the server, so that the metrics of a project can be delegated to its users.
+//
+//	---
+//	produces:
+//	  - text/plain
+//	parameters:
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "200":
+//	    description: Metrics
+//	    schema:
+//	      type: string
+//	      description: Instance metrics
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func projectMetricsGet(d *Daemon, r *http.Request) response.Response {
+	projectName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return metricsResponse(d, r, projectName, true)
+}
+
+// metricsResponse returns the metrics of the instances of the given project, or of all projects if empty.
+// When projectOnly is true, the project must exist and only the samples labelled with the project are returned.
+func metricsResponse(d *Daemon, r *http.Request, projectName string, projectOnly bool) response.Response {
 	s := d.State()
 
-	projectName := request.QueryParam(r, "project")
 	compress := strings.Contains(r.Header.Get("Accept-Encoding"), "gzip")
 
 	// Forward if requested.
@@ -102,6 +164,17 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		return resp
 	}
 
+	// Only report the patch metrics while the daemon applies its patches, so that members waiting on a patch can
+	// be alerted on.
+	select {
+	case <-d.setupChan:
+	default:
+		metricSet := metrics.NewMetricSet(nil)
+		patchesMetrics(metricSet, d.patchesStatus.list())
+
+		return getFilteredMetrics(s, r, compress, metricSet, projectOnly, projectName)
+	}
+
 	// Wait until daemon is fully started.
 	<-d.waitReady.Done()
 
@@ -112,7 +185,14 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 	var intMetrics *metrics.MetricSet
 	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		// Figure out the projects to retrieve.
-		if projectName != "" {
+		if projectOnly {
+			_, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+			if err != nil {
+				return fmt.Errorf("Failed loading project %q: %w", projectName, err)
+			}
+
+			projectNames = []string{projectName}
+		} else if projectName != "" {
 			projectNames = []string{projectName}
 		} else {
 			// Get all project names if no specific project requested.
@@ -128,7 +208,7 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		}
 
 		// Register internal metrics.
-		intMetrics = internalMetrics(ctx, s.StartTime, tx)
+		intMetrics = internalMetrics(ctx, s.StartTime, tx, d.patchesStatus.list())
 		return nil
 	})
 	if err != nil {
@@ -167,7 +247,7 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 	// If all valid, return immediately.
 	if len(projectsToFetch) == 0 {
-		return getFilteredMetrics(s, r, compress, metricSet)
+		return getFilteredMetrics(s, r, compress, metricSet, projectOnly, projectName)
 	}
 
 	cacheDuration := time.Duration(8) * time.Second
@@ -192,7 +272,7 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 	// If all valid, return immediately.
 	if len(projectsToFetch) == 0 {
-		return getFilteredMetrics(s, r, compress, metricSet)
+		return getFilteredMetrics(s, r, compress, metricSet, projectOnly, projectName)
 	}
 
 	// Gather information about host interfaces once.
@@ -345,10 +425,32 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 
 	metricsCacheLock.Unlock()
 
-	return getFilteredMetrics(s, r, compress, metricSet)
+	return getFilteredMetrics(s, r, compress, metricSet, projectOnly, projectName)
 }
 
-func getFilteredMetrics(s *state.State, r *http.Request, compress bool, metricSet *metrics.MetricSet) response.Response {
+// isProjectMetricsPath returns whether the given URL path is the one of the metrics of a project, which metrics
+// certificates can access.
+func isProjectMetricsPath(urlPath string) bool {
+	projectName, found := strings.CutPrefix(urlPath, "/1.0/projects/")
+	if !found {
+		return false
+	}
+
+	projectName, found = strings.CutSuffix(projectName, "/metrics")
+
+	return found && projectName != "" && !strings.Contains(projectName, "/")
+}
+
+// getFilteredMetrics returns the samples of the metric set which the caller can view.
+// When projectOnly is true, only the samples labelled with the given project are returned.
+func getFilteredMetrics(s *state.State, r *http.Request, compress bool, metricSet *metrics.MetricSet, projectOnly bool, projectName string) response.Response {
+	// Drop the internal metrics of the server and the samples of other projects.
+	if projectOnly {
+		metricSet.FilterSamples(func(labels map[string]string) bool {
+			return labels["project"] == projectName
+		})
+	}
+
 	// Ignore filtering in case the authentication for metrics is disabled.
 	if !s.GlobalConfig.MetricsAuthentication() {
 		return response.SyncResponsePlain(true, compress, metricSet.String())
@@ -377,7 +479,7 @@ func getFilteredMetrics(s *state.State, r *http.Request, compress bool, metricSe
 	return response.SyncResponsePlain(true, compress, metricSet.String())
 }
 
-func internalMetrics(ctx context.Context, daemonStartTime time.Time, tx *db.ClusterTx) *metrics.MetricSet {
+func internalMetrics(ctx context.Context, daemonStartTime time.Time, tx *db.ClusterTx, patches []internalPatchStatus) *metrics.MetricSet {
 	out := metrics.NewMetricSet(nil)
 
 	warnings, err := dbCluster.GetWarnings(ctx, tx.Tx())
@@ -430,5 +532,51 @@ func internalMetrics(ctx context.Context, daemonStartTime time.Time, tx *db.Clus
 	out.AddSamples(metrics.GoStackSysBytes, metrics.Sample{Value: float64(ms.StackSys)})
 	out.AddSamples(metrics.GoSysBytes, metrics.Sample{Value: float64(ms.Sys)})
 
+	// OVN database endpoints health
+	for _, result := range openvswitch.OVNHealth() {
+		labels := map[string]string{"database": string(result.Database), "endpoint": result.Endpoint}
+
+		up := 0.0
+		if result.Healthy {
+			up = 1.0
+		}
+
+		out.AddSamples(metrics.OVNDatabaseUp, metrics.Sample{Value: up, Labels: labels})
+		out.AddSamples(metrics.OVNDatabaseResponseSeconds, metrics.Sample{Value: result.ResponseTime.Seconds(), Labels: labels})
+	}
+
+	patchesMetrics(out, patches)
+
 	return out
 }
+
+// patchesMetrics adds the metrics of the patches applied since the daemon started to the metric set.
+func patchesMetrics(out *metrics.MetricSet, patches []internalPatchStatus) {
+	applied := 0
+	pending := 0
+
+	for _, patch := range patches {
+		switch patch.Status {
+		case patchStatusApplied:
+			applied++
+		case patchStatusPending, patchStatusRunning:
+			pending++
+		}
+
+		if patch.StartedAt.IsZero() {
+			continue
+		}
+
+		// Running patches report how long they have been running for.
+		finishedAt := patch.FinishedAt
+		if finishedAt.IsZero() {
+			finishedAt = time.Now().UTC()
+		}
+
+		labels := map[string]string{"name": patch.Name, "status": patch.Status}
+		out.AddSamples(metrics.PatchDurationSeconds, metrics.Sample{Value: finishedAt.Sub(patch.StartedAt).Seconds(), Labels: labels})
+	}
+
+	out.AddSamples(metrics.PatchesAppliedTotal, metrics.Sample{Value: float64(applied)})
+	out.AddSamples(metrics.PatchesPending, metrics.Sample{Value: float64(pending)})
+}
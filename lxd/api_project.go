@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
+	"path"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -17,7 +20,10 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/maintenance"
 	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/operations"
 	projecthelpers "github.com/canonical/lxd/lxd/project"
@@ -25,6 +31,7 @@ import (
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/lxd/warnings"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
@@ -55,6 +62,12 @@ var projectStateCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: projectStateGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanView, "name")},
 }
 
+var projectIdmapCmd = APIEndpoint{
+	Path: "projects/{name}/idmap",
+
+	Get: APIEndpointAction{Handler: projectIdmapGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanView, "name")},
+}
+
 // swagger:operation GET /1.0/projects projects projects_get
 //
 //  Get the projects
@@ -282,6 +295,9 @@ func projectsPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// The idmap range of the project is allocated by LXD.
+	delete(project.Config, "volatile.idmap.base")
+
 	// Validate the configuration.
 	err = projectValidateConfig(s, project.Config)
 	if err != nil {
@@ -320,6 +336,10 @@ func projectsPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Failed creating project %q: %w", project.Name, err))
 	}
 
+	if project.Config["events.history_size"] != "" {
+		eventsHistorySizesRefreshOrWarn(r.Context(), s)
+	}
+
 	requestor := request.CreateRequestor(r)
 	lc := lifecycle.ProjectCreated.Event(project.Name, requestor, nil)
 	s.Events.SendLifecycle(project.Name, lc)
@@ -619,6 +639,17 @@ func projectPatch(d *Daemon, r *http.Request) response.Response {
 
 // Common logic between PUT and PATCH.
 func projectChange(s *state.State, project *api.Project, req api.ProjectPut) response.Response {
+	// The idmap range of the project is managed by LXD and reallocated when its size changes.
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	if req.Config["idmap.size"] == project.Config["idmap.size"] && project.Config["volatile.idmap.base"] != "" {
+		req.Config["volatile.idmap.base"] = project.Config["volatile.idmap.base"]
+	} else {
+		delete(req.Config, "volatile.idmap.base")
+	}
+
 	// Make a list of config keys that have changed.
 	configChanged := []string{}
 	for key := range project.Config {
@@ -717,9 +748,73 @@ func projectChange(s *state.State, project *api.Project, req api.ProjectPut) res
 		return response.SmartError(err)
 	}
 
+	// Make sure the new project config is used right away rather than a cached one.
+	s.DB.Cluster.EntityCache().Invalidate(entity.TypeProject, project.Name)
+
+	if shared.ValueInSlice("events.history_size", configChanged) {
+		eventsHistorySizesRefreshOrWarn(context.TODO(), s)
+	}
+
+	for _, key := range configChanged {
+		if strings.HasPrefix(key, "restricted") {
+			err = projectNetworkRestrictionsWarn(s, project.Name)
+			if err != nil {
+				logger.Warn("Failed checking instances against project network restrictions", logger.Ctx{"project": project.Name, "err": err})
+			}
+
+			break
+		}
+	}
+
 	return response.EmptySyncResponse
 }
 
+// projectNetworkRestrictionsWarn checks the instances of the project against its network restrictions.
+// A warning is raised for each instance using a network that isn't allowed anymore, and the warnings of the
+// instances which comply are resolved.
+func projectNetworkRestrictionsWarn(s *state.State, projectName string) error {
+	var compliant []cluster.Instance
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		violations, err := projecthelpers.CheckInstanceNetworkRestrictions(s.GlobalConfig, tx, projectName)
+		if err != nil {
+			return err
+		}
+
+		instances, err := cluster.GetInstances(ctx, tx.Tx(), cluster.InstanceFilter{Project: &projectName})
+		if err != nil {
+			return err
+		}
+
+		for _, inst := range instances {
+			violation, ok := violations[inst.Name]
+			if !ok {
+				compliant = append(compliant, inst)
+				continue
+			}
+
+			err = tx.UpsertWarning(ctx, inst.Node, projectName, entity.TypeInstance, inst.ID, warningtype.InstanceNetworkRestricted, violation.Error())
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, inst := range compliant {
+		err = warnings.ResolveWarningsByNodeAndProjectAndTypeAndEntity(s.DB.Cluster, inst.Node, projectName, warningtype.InstanceNetworkRestricted, entity.TypeInstance, inst.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // swagger:operation POST /1.0/projects/{name} projects project_post
 //
 //	Rename the project
@@ -941,6 +1036,122 @@ func projectStateGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, &state)
 }
 
+// swagger:operation GET /1.0/projects/{name}/idmap projects project_idmap_get
+//
+//	Get the project idmap ranges
+//
+//	Gets the range of host IDs reserved for the project and the ranges used by its isolated containers.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Project idmap ranges
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ProjectIdmap"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func projectIdmapGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	idmapRanges := api.ProjectIdmap{Instances: []api.ProjectIdmapInstance{}}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := cluster.GetProject(ctx, tx.Tx(), name)
+		if err != nil {
+			return err
+		}
+
+		config, err := cluster.GetProjectConfig(ctx, tx.Tx(), dbProject.ID)
+		if err != nil {
+			return err
+		}
+
+		if config["idmap.size"] != "" {
+			idmapRanges.Size, err = strconv.ParseInt(config["idmap.size"], 10, 64)
+			if err != nil {
+				return err
+			}
+		}
+
+		if config["volatile.idmap.base"] != "" {
+			idmapRanges.Base, err = strconv.ParseInt(config["volatile.idmap.base"], 10, 64)
+			if err != nil {
+				return err
+			}
+		}
+
+		instanceType := instancetype.Container
+		filter := cluster.InstanceFilter{Project: &name, Type: &instanceType}
+
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, _ api.Project) error {
+			expandedConfig := instancetype.ExpandInstanceConfig(s.GlobalConfig.Dump(), inst.Config, inst.Profiles)
+			if shared.IsTrue(expandedConfig["security.privileged"]) || shared.IsFalseOrEmpty(expandedConfig["security.idmap.isolated"]) {
+				return nil
+			}
+
+			entry := api.ProjectIdmapInstance{
+				Name:     inst.Name,
+				Location: inst.Node,
+				Size:     65536,
+			}
+
+			if expandedConfig["volatile.idmap.base"] != "" {
+				entry.Base, err = strconv.ParseInt(expandedConfig["volatile.idmap.base"], 10, 64)
+				if err != nil {
+					return err
+				}
+			}
+
+			if expandedConfig["security.idmap.size"] != "" && expandedConfig["security.idmap.size"] != "auto" {
+				entry.Size, err = strconv.ParseInt(expandedConfig["security.idmap.size"], 10, 64)
+				if err != nil {
+					return err
+				}
+			}
+
+			if idmapRanges.Size > 0 {
+				entry.Outside = entry.Base < idmapRanges.Base || entry.Base+entry.Size > idmapRanges.Base+idmapRanges.Size
+			}
+
+			idmapRanges.Instances = append(idmapRanges.Instances, entry)
+
+			return nil
+		}, filter)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, &idmapRanges)
+}
+
 // Check if a project is empty.
 func projectIsEmpty(ctx context.Context, project *cluster.Project, tx *db.ClusterTx) (bool, error) {
 	instances, err := cluster.GetInstances(ctx, tx.Tx(), cluster.InstanceFilter{Project: &project.Name})
@@ -1018,11 +1229,42 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 	projectConfigKeys := map[string]func(value string) error{
 		// lxdmeta:generate(entities=project; group=specific; key=backups.compression_algorithm)
 		// Specify which compression algorithm to use for backups in this project.
-		// Possible values are `bzip2`, `gzip`, `lzma`, `xz`, or `none`.
+		// Possible values are `bzip2`, `gzip`, `lz4`, `lzma`, `xz`, `zstd`, or `none`.
+		// To set a compression level, append it to the algorithm, for example, `zstd -3`.
 		// ---
 		//  type: string
 		//  shortdesc: Compression algorithm to use for backups
 		"backups.compression_algorithm": validate.IsCompressionAlgorithm,
+		// lxdmeta:generate(entities=project; group=specific; key=default.network)
+		// When set, instances that are created in this project without any NIC device, either directly or through their profiles, get an `eth0` NIC device connected to this network.
+		// ---
+		//  type: string
+		//  shortdesc: Network that new instances are connected to by default
+		"default.network": validate.Optional(validate.IsAny),
+		// lxdmeta:generate(entities=project; group=specific; key=default.storage_pool)
+		// When set, instances that are created in this project without a storage pool for their root disk, either directly or through their profiles, use this storage pool.
+		// ---
+		//  type: string
+		//  shortdesc: Storage pool that new instances use by default
+		"default.storage_pool": validate.Optional(func(value string) error {
+			return projectValidateDefaultStoragePool(s, value)
+		}),
+		// lxdmeta:generate(entities=project; group=specific; key=default.target)
+		// Specify a cluster member, or a cluster group in the form `@<group>`.
+		// When set, instances that are created in this project without a target are placed on this cluster member or cluster group.
+		// Targets requested by clients are still subject to {config:option}`project-restricted:restricted.cluster.target` and {config:option}`project-restricted:restricted.cluster.groups`, while the default target is only subject to the latter.
+		// ---
+		//  type: string
+		//  shortdesc: Cluster member or group that new instances are placed on by default
+		"default.target": validate.Optional(validate.IsAny),
+		// lxdmeta:generate(entities=project; group=specific; key=events.history_size)
+		// Specify how many of the most recent events of the project are kept in memory by each cluster member.
+		// Clients can have these events replayed when connecting to the event API by using the `since` parameter.
+		// ---
+		//  type: integer
+		//  defaultdesc: `0`
+		//  shortdesc: Number of recent events to keep for replay
+		"events.history_size": validate.Optional(validate.IsInRange(0, 10000)),
 		// lxdmeta:generate(entities=project; group=features; key=features.profiles)
 		//
 		// ---
@@ -1071,6 +1313,20 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  initialvaluedesc: `false`
 		//  shortdesc: Whether to use a separate set of network zones for the project
 		"features.networks.zones": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=project; group=specific; key=idmap.size)
+		// When set, LXD reserves a range of this many host UIDs and GIDs for the project on first use.
+		// Containers of the project that set {config:option}`instance-security:security.idmap.isolated` get their idmap from that range only, and containers of other projects never use it.
+		// ---
+		//  type: integer
+		//  shortdesc: Size of the range of host IDs reserved for the project's isolated containers
+		"idmap.size": validate.Optional(validate.IsInRange(65536, math.MaxUint32)),
+		// lxdmeta:generate(entities=project; group=specific; key=images.alias_channel)
+		// When set, creating an instance from an image alias that has a channel with this name uses the target of that channel instead of the target of the alias.
+		// This allows projects to follow a `candidate` channel, for example, while others keep using the promoted target.
+		// ---
+		//  type: string
+		//  shortdesc: Image alias channel that instances of the project use
+		"images.alias_channel": validate.Optional(validate.IsHostname),
 		// lxdmeta:generate(entities=project; group=specific; key=images.auto_update_cached)
 		//
 		// ---
@@ -1102,6 +1358,22 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  type: integer
 		//  shortdesc: When an unused cached remote image is flushed in the project
 		"images.remote_cache_expiry": validate.Optional(validate.IsInt64),
+		// lxdmeta:generate(entities=project; group=specific; key=instances.disk_encryption.key_escrow)
+		// When enabled, instances of the project can escrow the recovery keys of their encrypted block devices on the server through the `/dev/lxd` API.
+		// The escrowed keys can be retrieved by users who can edit the instance.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether instances can escrow disk encryption recovery keys
+		"instances.disk_encryption.key_escrow": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=project; group=specific; key=maintenance.window)
+		// When set, this overrides {config:option}`server-core:core.maintenance_window` for the automated tasks that act on the project's images, snapshots and backups.
+		// The format is the same as for the server option.
+		// ---
+		//  type: string
+		//  defaultdesc: value of `core.maintenance_window`
+		//  shortdesc: When heavy automated tasks are allowed to run in the project
+		"maintenance.window": validate.Optional(maintenance.Validate),
 		// lxdmeta:generate(entities=project; group=limits; key=limits.instances)
 		//
 		// ---
@@ -1132,6 +1404,18 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  type: integer
 		//  shortdesc: Maximum number of processes within the project
 		"limits.processes": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.inotify.instances)
+		// This value is the maximum value for the sum of the individual {config:option}`instance-resource-limits:limits.inotify.instances` configurations set on the instances of the project.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of inotify instances within the project
+		"limits.inotify.instances": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.inotify.watches)
+		// This value is the maximum value for the sum of the individual {config:option}`instance-resource-limits:limits.inotify.watches` configurations set on the instances of the project.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of inotify watches within the project
+		"limits.inotify.watches": validate.Optional(validate.IsUint32),
 		// lxdmeta:generate(entities=project; group=limits; key=limits.cpu)
 		// This value is the maximum value for the sum of the individual {config:option}`instance-resource-limits:limits.cpu` configurations set on the instances of the project.
 		// ---
@@ -1144,6 +1428,24 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  type: string
 		//  shortdesc: Maximum disk space used by the project
 		"limits.disk": validate.Optional(validate.IsSize),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.disk.enforcement)
+		// Possible values are `config` (only check the sizes configured on the volumes of the project) and `usage` (also check the disk usage reported by the storage drivers).
+		//
+		// With `usage`, each cluster member periodically records the disk usage of the project volumes that it holds, and LXD refuses to create snapshots or copies that would make the project exceed {config:option}`project-limits:limits.disk`.
+		// ---
+		//  type: string
+		//  defaultdesc: `config`
+		//  shortdesc: How to enforce the disk limit of the project
+		"limits.disk.enforcement": validate.Optional(validate.IsOneOf("config", "usage")),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.disk.margin)
+		// Specify either a size (for example, `5GiB`) or a percentage of {config:option}`project-limits:limits.disk` (for example, `10%`).
+		//
+		// The margin is kept free when checking the disk usage reported by the storage drivers, to account for the usage changing between two checks.
+		// ---
+		//  type: string
+		//  defaultdesc: `0`
+		//  shortdesc: Safety margin for the disk usage of the project
+		"limits.disk.margin": validate.Optional(projecthelpers.ValidDiskMargin),
 		// lxdmeta:generate(entities=project; group=limits; key=limits.networks)
 		//
 		// ---
@@ -1223,6 +1525,14 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  defaultdesc: `block`
 		//  shortdesc: Whether to prevent using low-level VM options
 		"restricted.virtual-machines.lowlevel": isEitherAllowOrBlock,
+		// lxdmeta:generate(entities=project; group=restricted; key=restricted.virtual-machines.usb_redirection)
+		// Possible values are `allow` or `block`.
+		// When set to `allow`, SPICE clients can redirect local USB devices into the virtual machines of the project, unless {config:option}`instance-security:security.usb_redirection` is set to `false`.
+		// ---
+		//  type: string
+		//  defaultdesc: `block`
+		//  shortdesc: Whether to prevent redirecting USB devices into VMs over SPICE
+		"restricted.virtual-machines.usb_redirection": isEitherAllowOrBlock,
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted.devices.unix-char)
 		// Possible values are `allow` or `block`.
 		// ---
@@ -1290,6 +1600,16 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  defaultdesc: `managed`
 		//  shortdesc: Which network devices can be used
 		"restricted.devices.nic": isEitherAllowOrBlockOrManaged,
+		// lxdmeta:generate(entities=project; group=restricted; key=restricted.devices.nic.parents)
+		// If {config:option}`project-restricted:restricted.devices.nic` is set to `allow`, this option controls which host interfaces can be used as `parent` by network devices that don't set `network=`.
+		// Specify a comma-delimited list of interface names or patterns, for example, `eth0,enp5s0f*`.
+		// If this option is not set, {config:option}`project-restricted:restricted.networks.access` applies to the parent interfaces.
+		//
+		// Instances that no longer comply after this option is changed get a warning.
+		// ---
+		//  type: string
+		//  shortdesc: Which host interfaces can be used as parent by network devices
+		"restricted.devices.nic.parents": validate.Optional(validate.IsListOf(projectValidateNetworkPattern)),
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted.devices.disk)
 		// Possible values are `allow`, `block`, or `managed`.
 		//
@@ -1332,20 +1652,23 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		"restricted.idmap.gid": validate.Optional(validate.IsListOf(validate.IsUint32Range)),
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted.networks.access)
 		// Specify a comma-delimited list of network names that are allowed for use in this project.
+		// Entries can be patterns, for example, `lxdbr*`.
 		// If this option is not set, all networks are accessible.
 		//
 		// Note that this setting depends on the {config:option}`project-restricted:restricted.devices.nic` setting.
+		// Instances that no longer comply after this option is changed get a warning.
 		// ---
 		//  type: string
 		//  shortdesc: Which network names are allowed for use in this project
-		"restricted.networks.access": validate.Optional(validate.IsListOf(validate.IsAny)),
+		"restricted.networks.access": validate.Optional(validate.IsListOf(projectValidateNetworkPattern)),
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted.networks.uplinks)
 		// Specify a comma-delimited list of network names that can be used as uplink for networks in this project.
+		// Entries can be patterns, for example, `uplink*`.
 		// ---
 		//  type: string
 		//  defaultdesc: `block`
 		//  shortdesc: Which network names can be used as uplink in this project
-		"restricted.networks.uplinks": validate.Optional(validate.IsListOf(validate.IsAny)),
+		"restricted.networks.uplinks": validate.Optional(validate.IsListOf(projectValidateNetworkPattern)),
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted.networks.subnets)
 		// Specify a comma-delimited list of network subnets from the uplink networks that are allocated for use in this project.
 		// Use the form `<uplink>:<subnet>`.
@@ -1370,6 +1693,13 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  defaultdesc: `block`
 		//  shortdesc: Whether to prevent creating instance or volume snapshots
 		"restricted.snapshots": isEitherAllowOrBlock,
+
+		// lxdmeta:generate(entities=project; group=specific; key=volatile.idmap.base)
+		// The first host ID of the range reserved for the project through {config:option}`project-specific:idmap.size`.
+		// ---
+		//  type: integer
+		//  shortdesc: First host ID of the project's idmap range
+		"volatile.idmap.base": validate.Optional(validate.IsInt64),
 	}
 
 	for k, v := range config {
@@ -1440,6 +1770,28 @@ func projectValidateName(name string) error {
 	return nil
 }
 
+// projectValidateNetworkPattern checks that the value is a valid network name pattern.
+func projectValidateNetworkPattern(value string) error {
+	_, err := path.Match(value, "")
+	if err != nil {
+		return fmt.Errorf("Invalid network name pattern %q: %w", value, err)
+	}
+
+	return nil
+}
+
+// projectValidateDefaultStoragePool checks that the project's default.storage_pool exists.
+func projectValidateDefaultStoragePool(s *state.State, value string) error {
+	return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := tx.GetStoragePoolID(ctx, value)
+		if err != nil {
+			return fmt.Errorf("Invalid storage pool %q: %w", value, err)
+		}
+
+		return nil
+	})
+}
+
 // projectValidateRestrictedSubnets checks that the project's restricted.networks.subnets are properly formatted
 // and are within the specified uplink network's routes.
 func projectValidateRestrictedSubnets(s *state.State, value string) error {
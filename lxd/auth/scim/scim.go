@@ -0,0 +1,287 @@
+// Package scim contains the types and helpers used to serve the subset of the SCIM 2.0 protocol (RFC 7643 and
+// RFC 7644) used by identity providers to provision users and groups.
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ContentType is the media type of SCIM requests and responses.
+const ContentType = "application/scim+json"
+
+// Schema URNs of the SCIM resources and messages.
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// Error types (scimType) defined by RFC 7644.
+const (
+	ErrorInvalidFilter = "invalidFilter"
+	ErrorInvalidPath   = "invalidPath"
+	ErrorInvalidValue  = "invalidValue"
+	ErrorUniqueness    = "uniqueness"
+)
+
+// Meta is the metadata of a resource.
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location,omitempty"`
+}
+
+// Email is an email address of a user.
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// Name is the name of a user.
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// Member references a member of a group, or a group of a user.
+type Member struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// User is a SCIM user resource.
+type User struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	UserName    string   `json:"userName"`
+	Name        *Name    `json:"name,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Emails      []Email  `json:"emails,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+	Groups      []Member `json:"groups,omitempty"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+// Email returns the primary email address of the user, falling back to the first one and then to the user name.
+func (u User) Email() string {
+	for _, email := range u.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+
+	return u.UserName
+}
+
+// FullName returns the display name of the user, falling back to their formatted name.
+func (u User) FullName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+
+	if u.Name == nil {
+		return ""
+	}
+
+	if u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+
+	return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+}
+
+// IsActive returns whether the user is active. Users are active unless stated otherwise.
+func (u User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// Group is a SCIM group resource.
+type Group struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []Member `json:"members"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+// ListResponse is the response to a query of resources.
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// NewListResponse returns the page of the given resources starting at the given 1-based index and holding at most
+// count resources. A negative count returns all the resources from the start index.
+func NewListResponse[T any](resources []T, startIndex int, count int) ListResponse {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+
+	page := []any{}
+	for i := startIndex - 1; i < len(resources) && (count < 0 || len(page) < count); i++ {
+		page = append(page, resources[i])
+	}
+
+	return ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+// PatchOp is a request to modify a resource.
+type PatchOp struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is a single modification of a PatchOp request.
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Supported is a feature of the service provider that may or may not be supported.
+type Supported struct {
+	Supported bool `json:"supported"`
+}
+
+// Bulk describes the support of bulk operations by the service provider.
+type Bulk struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+// Filter describes the support of filters by the service provider.
+type Filter struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+// AuthenticationScheme is an authentication scheme supported by the service provider.
+type AuthenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ServiceProviderConfig describes the SCIM features supported by the service provider.
+type ServiceProviderConfig struct {
+	Schemas               []string               `json:"schemas"`
+	Patch                 Supported              `json:"patch"`
+	Bulk                  Bulk                   `json:"bulk"`
+	Filter                Filter                 `json:"filter"`
+	ChangePassword        Supported              `json:"changePassword"`
+	Sort                  Supported              `json:"sort"`
+	ETag                  Supported              `json:"etag"`
+	AuthenticationSchemes []AuthenticationScheme `json:"authenticationSchemes"`
+}
+
+// Error is a SCIM error response. It implements the error interface so it can be returned by handlers.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+
+	code int
+}
+
+// NewError returns a SCIM error with the given HTTP status code, error type and detail.
+func NewError(code int, scimType string, format string, args ...any) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		Status:   fmt.Sprint(code),
+		ScimType: scimType,
+		Detail:   fmt.Sprintf(format, args...),
+		code:     code,
+	}
+}
+
+// Error returns the detail of the error.
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+// Code returns the HTTP status code of the error.
+func (e *Error) Code() int {
+	if e.code == 0 {
+		return http.StatusInternalServerError
+	}
+
+	return e.code
+}
+
+// ParseFilter parses a filter of the form `attribute eq "value"`, the only kind of filter used by identity providers
+// to look up users and groups. The attribute name is returned as is, attribute names being case-insensitive.
+func ParseFilter(filter string) (attribute string, value string, err error) {
+	attribute, rest, ok := strings.Cut(strings.TrimSpace(filter), " ")
+	if !ok {
+		return "", "", NewError(http.StatusBadRequest, ErrorInvalidFilter, "Invalid filter %q", filter)
+	}
+
+	operator, rest, ok := strings.Cut(strings.TrimSpace(rest), " ")
+	if !ok || !strings.EqualFold(operator, "eq") {
+		return "", "", NewError(http.StatusBadRequest, ErrorInvalidFilter, "Unsupported filter %q, only the \"eq\" operator is supported", filter)
+	}
+
+	value, err = unquote(strings.TrimSpace(rest))
+	if err != nil {
+		return "", "", NewError(http.StatusBadRequest, ErrorInvalidFilter, "Invalid filter %q: %v", filter, err)
+	}
+
+	return attribute, value, nil
+}
+
+// ParseValuePath parses a path of the form `attribute[value eq "value"]`, used to select the members of a group to
+// remove. The value is empty if the path has no value filter.
+func ParseValuePath(path string) (attribute string, value string, err error) {
+	attribute, filter, ok := strings.Cut(path, "[")
+	if !ok {
+		return path, "", nil
+	}
+
+	filter, ok = strings.CutSuffix(filter, "]")
+	if !ok {
+		return "", "", NewError(http.StatusBadRequest, ErrorInvalidPath, "Invalid path %q", path)
+	}
+
+	name, value, err := ParseFilter(filter)
+	if err != nil {
+		return "", "", NewError(http.StatusBadRequest, ErrorInvalidPath, "Invalid path %q: %v", path, err)
+	}
+
+	if !strings.EqualFold(name, "value") {
+		return "", "", NewError(http.StatusBadRequest, ErrorInvalidPath, "Unsupported path %q, only members can be selected by value", path)
+	}
+
+	return attribute, value, nil
+}
+
+// unquote returns the content of the given double-quoted string.
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("Value %s isn't a quoted string", s)
+	}
+
+	return strings.ReplaceAll(s[1:len(s)-1], `\"`, `"`), nil
+}
@@ -0,0 +1,97 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter    string
+		attribute string
+		value     string
+		wantErr   bool
+	}{
+		{filter: `userName eq "jane@example.com"`, attribute: "userName", value: "jane@example.com"},
+		{filter: ` displayName EQ "Operators" `, attribute: "displayName", value: "Operators"},
+		{filter: `displayName eq "Ops \"on call\""`, attribute: "displayName", value: `Ops "on call"`},
+		{filter: `userName sw "jane"`, wantErr: true},
+		{filter: `userName eq jane`, wantErr: true},
+		{filter: `userName`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			attribute, value, err := ParseFilter(test.filter)
+			if test.wantErr {
+				var scimErr *Error
+				require.ErrorAs(t, err, &scimErr)
+				assert.Equal(t, ErrorInvalidFilter, scimErr.ScimType)
+				assert.Equal(t, 400, scimErr.Code())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.attribute, attribute)
+			assert.Equal(t, test.value, value)
+		})
+	}
+}
+
+func TestParseValuePath(t *testing.T) {
+	attribute, value, err := ParseValuePath("members")
+	require.NoError(t, err)
+	assert.Equal(t, "members", attribute)
+	assert.Equal(t, "", value)
+
+	attribute, value, err = ParseValuePath(`members[value eq "12"]`)
+	require.NoError(t, err)
+	assert.Equal(t, "members", attribute)
+	assert.Equal(t, "12", value)
+
+	_, _, err = ParseValuePath(`members[display eq "jane"]`)
+	assert.Error(t, err)
+
+	_, _, err = ParseValuePath(`members[value eq "12"`)
+	assert.Error(t, err)
+}
+
+func TestNewListResponse(t *testing.T) {
+	resources := []string{"a", "b", "c"}
+
+	list := NewListResponse(resources, 0, -1)
+	assert.Equal(t, 3, list.TotalResults)
+	assert.Equal(t, 1, list.StartIndex)
+	assert.Equal(t, []any{"a", "b", "c"}, list.Resources)
+
+	list = NewListResponse(resources, 2, 1)
+	assert.Equal(t, 3, list.TotalResults)
+	assert.Equal(t, 1, list.ItemsPerPage)
+	assert.Equal(t, []any{"b"}, list.Resources)
+
+	list = NewListResponse(resources, 5, 10)
+	assert.Equal(t, 0, list.ItemsPerPage)
+	assert.Equal(t, []any{}, list.Resources)
+}
+
+func TestUser(t *testing.T) {
+	active := false
+	user := User{
+		UserName: "jane",
+		Name:     &Name{GivenName: "Jane", FamilyName: "Doe"},
+		Emails:   []Email{{Value: "jane@example.org"}, {Value: "jane@example.com", Primary: true}},
+	}
+
+	assert.Equal(t, "jane@example.com", user.Email())
+	assert.Equal(t, "Jane Doe", user.FullName())
+	assert.True(t, user.IsActive())
+
+	user.Emails = nil
+	user.Active = &active
+	user.DisplayName = "Jane D."
+	assert.Equal(t, "jane", user.Email())
+	assert.Equal(t, "Jane D.", user.FullName())
+	assert.False(t, user.IsActive())
+}
@@ -0,0 +1,646 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var authApprovalsCmd = APIEndpoint{
+	Path: "auth/approvals",
+
+	Get:  APIEndpointAction{Handler: authApprovalsGet, AccessHandler: allowAuthenticated},
+	Post: APIEndpointAction{Handler: authApprovalsPost, AccessHandler: allowAuthenticated},
+}
+
+var authApprovalCmd = APIEndpoint{
+	Path: "auth/approvals/{id}",
+
+	Get:    APIEndpointAction{Handler: authApprovalGet, AccessHandler: allowAuthenticated},
+	Post:   APIEndpointAction{Handler: authApprovalPost, AccessHandler: allowAuthenticated},
+	Delete: APIEndpointAction{Handler: authApprovalDelete, AccessHandler: allowAuthenticated},
+}
+
+// authApprovalEntityTypes maps the actions requiring an approval to the types of entities they apply to.
+var authApprovalEntityTypes = map[string][]entity.Type{
+	api.AuthApprovalActionDeleteInstance: {entity.TypeInstance},
+	api.AuthApprovalActionSetPrivileged:  {entity.TypeInstance, entity.TypeProfile},
+	api.AuthApprovalActionAttachRawDisk:  {entity.TypeInstance, entity.TypeProfile},
+}
+
+// swagger:operation GET /1.0/auth/approvals auth_approvals auth_approvals_get
+//
+//	Get the approvals
+//
+//	Returns a list of requests for approval of privileged actions (URLs).
+//	Identities that can edit the server see all the requests, others only see their own.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/auth/approvals/1",
+//	              "/1.0/auth/approvals/2"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/auth/approvals?recursion=1 auth_approvals auth_approvals_get_recursion1
+//
+//	Get the approvals
+//
+//	Returns a list of requests for approval of privileged actions.
+//	Identities that can edit the server see all the requests, others only see their own.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of approvals
+//	          items:
+//	            $ref: "#/definitions/AuthApproval"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func authApprovalsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+	requestor := request.CreateRequestor(r)
+
+	filter := dbCluster.AuthApprovalFilter{}
+	if !authApprovalCanManage(r.Context(), s, r) {
+		filter.Username = &requestor.Username
+		filter.Protocol = &requestor.Protocol
+	}
+
+	var dbApprovals []dbCluster.AuthApproval
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		dbApprovals, err = dbCluster.GetAuthApprovals(ctx, tx.Tx(), filter)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	now := time.Now()
+	approvals := make([]api.AuthApproval, 0, len(dbApprovals))
+	for _, dbApproval := range dbApprovals {
+		// Skip approvals that expired but haven't been pruned yet.
+		if !dbApproval.ExpiresAt.After(now) {
+			continue
+		}
+
+		approvals = append(approvals, dbApproval.ToAPI())
+	}
+
+	if !util.IsRecursionRequest(r) {
+		urls := make([]string, 0, len(approvals))
+		for _, approval := range approvals {
+			urls = append(urls, api.NewURL().Path(version.APIVersion, "auth", "approvals", strconv.FormatInt(approval.ID, 10)).String())
+		}
+
+		return response.SyncResponse(true, urls)
+	}
+
+	return response.SyncResponse(true, approvals)
+}
+
+// swagger:operation POST /1.0/auth/approvals auth_approvals auth_approvals_post
+//
+//	Request an approval
+//
+//	Requests the approval of a privileged action on an entity by another identity. Leaving the entity empty
+//	requests a time-bound elevation, allowing the action on any entity until the approval expires.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: approval
+//	    description: Approval request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/AuthApprovalsPost"
+//	responses:
+//	  "200":
+//	    description: Approval
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/AuthApproval"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func authApprovalsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	req := api.AuthApprovalsPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	entityTypes, ok := authApprovalEntityTypes[req.Action]
+	if !ok {
+		return response.BadRequest(fmt.Errorf("Unknown action %q", req.Action))
+	}
+
+	if req.Entity != "" {
+		entityURL, err := authApprovalEntityURL(req.Entity, entityTypes)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		req.Entity = entityURL.String()
+	}
+
+	requestor := request.CreateRequestor(r)
+	if authApprovalExempt(requestor) {
+		return response.BadRequest(fmt.Errorf("Requests made through the Unix socket don't require approvals"))
+	}
+
+	expiresAt, err := shared.GetExpiry(time.Now(), s.GlobalConfig.ApprovalsExpiry())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	dbApproval := dbCluster.AuthApproval{
+		Action:    req.Action,
+		Entity:    req.Entity,
+		Reason:    req.Reason,
+		Username:  requestor.Username,
+		Protocol:  requestor.Protocol,
+		Status:    dbCluster.AuthApprovalStatusPending,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt.UTC(),
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, err := dbCluster.CreateAuthApproval(ctx, tx.Tx(), dbApproval)
+		if err != nil {
+			return err
+		}
+
+		dbApproval.ID = int(id)
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	approval := dbApproval.ToAPI()
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.AuthApprovalCreated.Event(approval.ID, requestor, map[string]any{"action": approval.Action, "entity": approval.Entity, "reason": approval.Reason}))
+
+	return response.SyncResponseLocation(true, approval, api.NewURL().Path(version.APIVersion, "auth", "approvals", strconv.FormatInt(approval.ID, 10)).String())
+}
+
+// swagger:operation GET /1.0/auth/approvals/{id} auth_approvals auth_approval_get
+//
+//	Get the approval
+//
+//	Gets a specific request for approval.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Approval
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/AuthApproval"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func authApprovalGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	dbApproval, err := authApprovalLoad(r.Context(), s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, dbApproval.ToAPI())
+}
+
+// swagger:operation POST /1.0/auth/approvals/{id} auth_approvals auth_approval_post
+//
+//	Approve or reject a request
+//
+//	Approves or rejects a pending request for approval. Requests can't be decided by the identity that made
+//	them, and deciding them requires the permission to edit the entity (or the server for time-bound elevations).
+//	Once approved, the request can be used until it expires.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: approval
+//	    description: Decision
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/AuthApprovalPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func authApprovalPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	req := api.AuthApprovalPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var status dbCluster.AuthApprovalStatus
+	var action lifecycle.AuthApprovalAction
+	switch req.Status {
+	case api.AuthApprovalStatusApproved:
+		status = dbCluster.AuthApprovalStatusApproved
+		action = lifecycle.AuthApprovalApproved
+	case api.AuthApprovalStatusRejected:
+		status = dbCluster.AuthApprovalStatusRejected
+		action = lifecycle.AuthApprovalRejected
+	default:
+		return response.BadRequest(fmt.Errorf("Invalid status %q, must be %q or %q", req.Status, api.AuthApprovalStatusApproved, api.AuthApprovalStatusRejected))
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid approval ID: %w", err))
+	}
+
+	var dbApproval *dbCluster.AuthApproval
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		dbApproval, err = dbCluster.GetAuthApproval(ctx, tx.Tx(), id)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if dbApproval.Status != dbCluster.AuthApprovalStatusPending || !dbApproval.ExpiresAt.After(time.Now()) {
+		return response.BadRequest(fmt.Errorf("Approval %d isn't pending", id))
+	}
+
+	requestor := request.CreateRequestor(r)
+	if requestor.Username == dbApproval.Username && requestor.Protocol == dbApproval.Protocol {
+		return response.Forbidden(fmt.Errorf("Requests for approval must be decided by another identity"))
+	}
+
+	// Deciding about an action requires being allowed to edit the entity it applies to.
+	entityURL := entity.ServerURL()
+	if dbApproval.Entity != "" {
+		entityURL, err = authApprovalEntityURL(dbApproval.Entity, authApprovalEntityTypes[dbApproval.Action])
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	err = s.Authorizer.CheckPermission(r.Context(), r, entityURL, auth.EntitlementCanEdit)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	expiresAt := dbApproval.ExpiresAt
+	if status == dbCluster.AuthApprovalStatusApproved {
+		expiresAt, err = shared.GetExpiry(time.Now(), s.GlobalConfig.ApprovalsExpiry())
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.UpdateAuthApprovalStatus(ctx, tx.Tx(), id, status, requestor.Username, expiresAt.UTC())
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, action.Event(int64(id), requestor, map[string]any{"action": dbApproval.Action, "entity": dbApproval.Entity, "requestor": dbApproval.Username}))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation DELETE /1.0/auth/approvals/{id} auth_approvals auth_approval_delete
+//
+//	Delete the approval
+//
+//	Withdraws a request for approval, or revokes an approval.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func authApprovalDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	dbApproval, err := authApprovalLoad(r.Context(), s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.DeleteAuthApproval(ctx, tx.Tx(), dbApproval.ID)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.AuthApprovalDeleted.Event(int64(dbApproval.ID), request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// authApprovalLoad returns the approval with the ID of the request, provided the requestor can see it.
+func authApprovalLoad(ctx context.Context, s *state.State, r *http.Request) (*dbCluster.AuthApproval, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid approval ID: %w", err)
+	}
+
+	var dbApproval *dbCluster.AuthApproval
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbApproval, err = dbCluster.GetAuthApproval(ctx, tx.Tx(), id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	requestor := request.CreateRequestor(r)
+	if (requestor.Username != dbApproval.Username || requestor.Protocol != dbApproval.Protocol) && !authApprovalCanManage(ctx, s, r) {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Approval not found")
+	}
+
+	return dbApproval, nil
+}
+
+// authApprovalCanManage returns whether the requestor can see and delete the approvals of all identities.
+func authApprovalCanManage(ctx context.Context, s *state.State, r *http.Request) bool {
+	return s.Authorizer.CheckPermission(ctx, r, entity.ServerURL(), auth.EntitlementCanEdit) == nil
+}
+
+// authApprovalExempt returns whether requests of the given requestor never require approvals. This is the case of
+// requests made through the Unix socket and internal requests between cluster members.
+func authApprovalExempt(requestor *api.EventLifecycleRequestor) bool {
+	return shared.ValueInSlice(requestor.Protocol, []string{"", "unix", "cluster"})
+}
+
+// authApprovalEntityURL parses the given entity URL, checks that the entity is of one of the given types and returns
+// its canonical URL.
+func authApprovalEntityURL(rawURL string, entityTypes []entity.Type) (*api.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid entity URL %q: %w", rawURL, err)
+	}
+
+	entityType, projectName, _, pathArgs, err := entity.ParseURL(*u)
+	if err != nil {
+		return nil, err
+	}
+
+	if !shared.ValueInSlice(entityType, entityTypes) {
+		return nil, fmt.Errorf("Entity type %q isn't supported by this action", entityType)
+	}
+
+	return entityType.URL(projectName, "", pathArgs...)
+}
+
+// authApprovalCheck returns an error unless the requestor is allowed to perform the given action on the entity with
+// the given URL without approval, or holds an approval for it. Approvals of the entity are used up, while time-bound
+// elevations can be used on any entity until they expire.
+func authApprovalCheck(ctx context.Context, s *state.State, r *http.Request, action string, entityURL *api.URL) error {
+	if !shared.ValueInSlice(action, s.GlobalConfig.ApprovalsActions()) {
+		return nil
+	}
+
+	requestor := request.CreateRequestor(r)
+	if authApprovalExempt(requestor) {
+		return nil
+	}
+
+	var used *dbCluster.AuthApproval
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		approvals, err := dbCluster.GetAuthApprovals(ctx, tx.Tx(), dbCluster.AuthApprovalFilter{Username: &requestor.Username, Protocol: &requestor.Protocol})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i, approval := range approvals {
+			if approval.Action != action || approval.Status != dbCluster.AuthApprovalStatusApproved || !approval.ExpiresAt.After(now) {
+				continue
+			}
+
+			if approval.Entity == entityURL.String() {
+				used = &approvals[i]
+				break
+			}
+
+			if approval.Entity == "" && used == nil {
+				used = &approvals[i]
+			}
+		}
+
+		if used == nil || used.Entity == "" {
+			return nil
+		}
+
+		return dbCluster.UpdateAuthApprovalStatus(ctx, tx.Tx(), used.ID, dbCluster.AuthApprovalStatusUsed, used.Approver, used.ExpiresAt)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed checking approvals: %w", err)
+	}
+
+	if used == nil {
+		return api.StatusErrorf(http.StatusForbidden, "Action %q on %q requires an approval from another identity", action, entityURL.String())
+	}
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.AuthApprovalUsed.Event(int64(used.ID), requestor, map[string]any{"action": action, "entity": entityURL.String(), "approver": used.Approver}))
+
+	return nil
+}
+
+// authApprovalCheckConfig checks the approvals required to go from the given configuration and devices of an
+// instance or profile to the new ones, that is to set security.privileged or to add raw disks.
+func authApprovalCheckConfig(ctx context.Context, s *state.State, r *http.Request, entityURL *api.URL, oldConfig map[string]string, oldDevices map[string]map[string]string, newConfig map[string]string, newDevices map[string]map[string]string) error {
+	if shared.IsTrue(newConfig["security.privileged"]) && !shared.IsTrue(oldConfig["security.privileged"]) {
+		err := authApprovalCheck(ctx, s, r, api.AuthApprovalActionSetPrivileged, entityURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	for name, device := range newDevices {
+		if !authApprovalIsRawDisk(device) {
+			continue
+		}
+
+		oldDevice, ok := oldDevices[name]
+		if ok && authApprovalIsRawDisk(oldDevice) && oldDevice["source"] == device["source"] {
+			continue
+		}
+
+		// A single approval covers all the raw disks added by the request.
+		return authApprovalCheck(ctx, s, r, api.AuthApprovalActionAttachRawDisk, entityURL)
+	}
+
+	return nil
+}
+
+// authApprovalIsRawDisk returns whether the given device passes a host block device through.
+func authApprovalIsRawDisk(device map[string]string) bool {
+	return device["type"] == "disk" && device["pool"] == "" && strings.HasPrefix(device["source"], "/dev/")
+}
+
+func pruneExpiredAuthApprovalsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		var deleted int64
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+			deleted, err = dbCluster.DeleteExpiredAuthApprovals(ctx, tx.Tx(), time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			logger.Error("Failed pruning expired approvals", logger.Ctx{"err": err})
+			return
+		}
+
+		if deleted > 0 {
+			logger.Debug("Pruned expired approvals", logger.Ctx{"count": deleted})
+		}
+	}
+
+	return f, task.Hourly()
+}
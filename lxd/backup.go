@@ -19,8 +19,10 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/instancewriter"
 	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/maintenance"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/lxd/task"
@@ -115,9 +117,9 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 
 	// Setup the tarball writer.
 	l.Debug("Opening backup tarball for writing", logger.Ctx{"path": target})
-	tarFileWriter, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY, 0600)
+	tarFileWriter, compress, err := backupOpenTarget(s, target, compress)
 	if err != nil {
-		return fmt.Errorf("Error opening backup tarball for writing %q: %w", target, err)
+		return err
 	}
 
 	defer func() { _ = tarFileWriter.Close() }()
@@ -227,6 +229,96 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 	return nil
 }
 
+// backupOpenTarget opens the file at target for writing a backup tarball with the given compression.
+// When backups are deduplicated, the uncompressed tarball is written to the deduplication store instead and the
+// compression is only applied on export, so the returned compression is "none".
+func backupOpenTarget(s *state.State, target string, compress string) (io.WriteCloser, string, error) {
+	if s.GlobalConfig.BackupsDeduplication() {
+		w, err := backup.NewDedupWriter(target, compress)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return w, "none", nil
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, "", fmt.Errorf("Error opening backup tarball for writing %q: %w", target, err)
+	}
+
+	return f, compress, nil
+}
+
+// backupFileResponseEntry returns the entry for serving the backup tarball at path.
+// Deduplicated backups are reassembled from the deduplication store and compressed with the algorithm that was
+// configured when they were created.
+func backupFileResponseEntry(path string) (*response.FileResponseEntry, error) {
+	index, err := backup.DedupIndexLoad(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if index == nil {
+		return &response.FileResponseEntry{Path: path}, nil
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := backup.NewDedupReader(index)
+
+	if index.Compression == "none" {
+		return &response.FileResponseEntry{
+			File:         reader,
+			FileSize:     index.Size,
+			FileModified: fi.ModTime(),
+		}, nil
+	}
+
+	// Compress into a temporary file as the size of the response must be known upfront.
+	tarFile, err := os.CreateTemp(shared.VarPath("backups"), fmt.Sprintf("%s_export_", backup.WorkingDirPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating temporary file: %w", err)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	cleanup := func() {
+		_ = tarFile.Close()
+		_ = os.Remove(tarFile.Name())
+	}
+
+	revert.Add(cleanup)
+
+	err = compressFile(index.Compression, reader, tarFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed compressing backup: %w", err)
+	}
+
+	size, err := tarFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tarFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	revert.Success()
+
+	return &response.FileResponseEntry{
+		File:         tarFile,
+		FileSize:     size,
+		FileModified: fi.ModTime(),
+		Cleanup:      cleanup,
+	}, nil
+}
+
 // backupWriteIndex generates an index.yaml file and then writes it to the root of the backup tarball.
 func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, tarWriter *instancewriter.InstanceTarWriter) error {
 	// Indicate whether the driver will include a driver-specific optimized header.
@@ -301,16 +393,36 @@ func pruneExpiredBackupsTask(d *Daemon) (task.Func, task.Schedule) {
 		s := d.State()
 
 		opRun := func(op *operations.Operation) error {
-			err := pruneExpiredInstanceBackups(ctx, s)
+			maintenanceAllowed, err := maintenanceWindowChecker(ctx, s)
+			if err != nil {
+				return fmt.Errorf("Failed loading maintenance windows: %w", err)
+			}
+
+			err = pruneExpiredInstanceBackups(ctx, s, maintenanceAllowed)
 			if err != nil {
 				return fmt.Errorf("Failed pruning expired instance backups: %w", err)
 			}
 
-			err = pruneExpiredStorageVolumeBackups(ctx, s)
+			err = pruneExpiredStorageVolumeBackups(ctx, s, maintenanceAllowed)
 			if err != nil {
 				return fmt.Errorf("Failed pruning expired storage volume backups: %w", err)
 			}
 
+			// The deduplication store is shared by all projects, so only the server window applies to it.
+			if !maintenanceAllowed("") {
+				return nil
+			}
+
+			// Remove the chunks that were only used by deleted backups.
+			removed, freed, err := backup.DedupGarbageCollect(ctx)
+			if err != nil {
+				return fmt.Errorf("Failed garbage collecting the backup deduplication store: %w", err)
+			}
+
+			if removed > 0 {
+				logger.Info("Removed unreferenced backup chunks", logger.Ctx{"chunks": removed, "freed": units.GetByteSizeString(freed, 2)})
+			}
+
 			return nil
 		}
 
@@ -353,7 +465,61 @@ func pruneExpiredBackupsTask(d *Daemon) (task.Func, task.Schedule) {
 	return f, schedule
 }
 
-func pruneExpiredInstanceBackups(ctx context.Context, s *state.State) error {
+func backupsDedupScrubTask(d *Daemon) (task.Func, task.Schedule) {
+	var lastScrub time.Time
+
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		opRun := func(op *operations.Operation) error {
+			affected, err := backup.DedupScrub(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, path := range affected {
+				logger.Warn("Backup references a corrupted chunk of the deduplication store", logger.Ctx{"path": path})
+			}
+
+			return nil
+		}
+
+		if !shared.PathExists(backup.DedupStorePath()) {
+			return
+		}
+
+		// Scrub at most once a day, during the server's maintenance window.
+		if time.Since(lastScrub) < 24*time.Hour || !maintenance.Allowed(s.GlobalConfig.MaintenanceWindow(), "", time.Now()) {
+			return
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.BackupsScrub, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating backup deduplication store scrub operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Scrubbing the backup deduplication store")
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting backup deduplication store scrub operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed scrubbing the backup deduplication store", logger.Ctx{"err": err})
+			return
+		}
+
+		lastScrub = time.Now()
+		logger.Info("Done scrubbing the backup deduplication store")
+	}
+
+	return f, task.Hourly()
+}
+
+func pruneExpiredInstanceBackups(ctx context.Context, s *state.State, maintenanceAllowed func(projectName string) bool) error {
 	var backups []db.InstanceBackup
 
 	// Get the list of expired backups.
@@ -372,6 +538,11 @@ func pruneExpiredInstanceBackups(ctx context.Context, s *state.State) error {
 			return fmt.Errorf("Error loading instance for deleting backup %q: %w", b.Name, err)
 		}
 
+		// Leave expired backups in place until the project's maintenance window.
+		if !maintenanceAllowed(inst.Project().Name) {
+			continue
+		}
+
 		instBackup := backup.NewInstanceBackup(s, inst, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.InstanceOnly, b.OptimizedStorage)
 		err = instBackup.Delete()
 		if err != nil {
@@ -451,9 +622,9 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 
 	// Setup the tarball writer.
 	l.Debug("Opening backup tarball for writing", logger.Ctx{"path": target})
-	tarFileWriter, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY, 0600)
+	tarFileWriter, compress, err := backupOpenTarget(s, target, compress)
 	if err != nil {
-		return fmt.Errorf("Error opening backup tarball for writing %q: %w", target, err)
+		return err
 	}
 
 	defer func() { _ = tarFileWriter.Close() }()
@@ -584,7 +755,7 @@ func volumeBackupWriteIndex(s *state.State, projectName string, volumeName strin
 	return nil
 }
 
-func pruneExpiredStorageVolumeBackups(ctx context.Context, s *state.State) error {
+func pruneExpiredStorageVolumeBackups(ctx context.Context, s *state.State, maintenanceAllowed func(projectName string) bool) error {
 	var volumeBackups []*backup.VolumeBackup
 
 	// Get the list of expired backups.
@@ -608,6 +779,11 @@ func pruneExpiredStorageVolumeBackups(ctx context.Context, s *state.State) error
 				continue
 			}
 
+			// Leave expired backups in place until the project's maintenance window.
+			if !maintenanceAllowed(vol.ProjectName) {
+				continue
+			}
+
 			volBackup := backup.NewVolumeBackup(s, vol.ProjectName, vol.PoolName, vol.Name, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.VolumeOnly, b.OptimizedStorage)
 
 			volumeBackups = append(volumeBackups, volBackup)
@@ -0,0 +1,523 @@
+package backup
+
+import (
+	"compress/flate"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// dedupIndexHeader is the first line of the index files replacing the tarballs of deduplicated backups.
+const dedupIndexHeader = "lxd-dedup-index-v1\n"
+
+// Chunk boundaries are content-defined using a gear rolling hash, so that data inserted into or removed from a
+// tarball only affects the chunks around the change.
+const (
+	dedupMinChunkSize = 256 * 1024
+	dedupMaxChunkSize = 4 * 1024 * 1024
+
+	// dedupChunkMask gives an average chunk size of 1MiB above the minimum chunk size.
+	dedupChunkMask = uint64(1<<20-1) << 44
+)
+
+// dedupGear is the table of random values used by the rolling hash.
+// It is generated from a fixed seed as changing it would prevent new backups from sharing chunks with old ones.
+var dedupGear = func() [256]uint64 {
+	var gear [256]uint64
+
+	seed := uint64(0x6c78645f64656475)
+	for i := range gear {
+		// splitmix64.
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		gear[i] = z ^ (z >> 31)
+	}
+
+	return gear
+}()
+
+// dedupLock prevents the garbage collection from removing the chunks of the backups being written, as those
+// aren't referenced by an index until the backup is complete.
+var dedupLock sync.RWMutex
+
+// DedupIndex describes how the tarball of a deduplicated backup is reassembled from the deduplication store.
+type DedupIndex struct {
+	// Compression algorithm applied to the tarball when exported.
+	Compression string `json:"compression"`
+
+	// Size of the uncompressed tarball.
+	Size int64 `json:"size"`
+
+	Chunks []DedupChunk `json:"chunks"`
+}
+
+// DedupChunk represents a chunk of a deduplicated backup.
+type DedupChunk struct {
+	// SHA-256 of the uncompressed content of the chunk.
+	Hash string `json:"hash"`
+
+	// Size of the uncompressed content of the chunk.
+	Size int64 `json:"size"`
+}
+
+// DedupStorePath returns the path of the deduplication store.
+func DedupStorePath() string {
+	return shared.VarPath("backups", "chunks")
+}
+
+// dedupChunkPath returns the path of the chunk with the given hash.
+func dedupChunkPath(hash string) string {
+	return filepath.Join(DedupStorePath(), hash[:2], hash)
+}
+
+// DedupWriter writes a backup tarball to the deduplication store.
+// Closing the writer writes the index of the backup to the target path.
+type DedupWriter struct {
+	target string
+	index  DedupIndex
+	buf    []byte
+	hash   uint64
+	closed bool
+}
+
+// NewDedupWriter returns a DedupWriter writing the index of the backup to target.
+// The compression algorithm is recorded in the index and applied when the backup is exported.
+func NewDedupWriter(target string, compression string) (*DedupWriter, error) {
+	err := os.MkdirAll(DedupStorePath(), 0700)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating deduplication store: %w", err)
+	}
+
+	dedupLock.RLock()
+
+	return &DedupWriter{
+		target: target,
+		index:  DedupIndex{Compression: compression, Chunks: []DedupChunk{}},
+		buf:    make([]byte, 0, dedupMaxChunkSize),
+	}, nil
+}
+
+// Write splits the data into chunks and stores the complete ones.
+func (w *DedupWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	for i, b := range p {
+		w.buf = append(w.buf, b)
+		w.hash = (w.hash << 1) + dedupGear[b]
+
+		if len(w.buf) < dedupMinChunkSize {
+			continue
+		}
+
+		if w.hash&dedupChunkMask == 0 || len(w.buf) >= dedupMaxChunkSize {
+			err := w.flush()
+			if err != nil {
+				return i, err
+			}
+		}
+	}
+
+	return len(p), nil
+}
+
+// flush stores the buffered chunk unless the store already holds it.
+func (w *DedupWriter) flush() error {
+	sum := sha256.Sum256(w.buf)
+	hash := hex.EncodeToString(sum[:])
+
+	chunkPath := dedupChunkPath(hash)
+	if !shared.PathExists(chunkPath) {
+		err := os.MkdirAll(filepath.Dir(chunkPath), 0700)
+		if err != nil {
+			return err
+		}
+
+		// Write to a temporary file first so that a partially written chunk is never referenced.
+		f, err := os.CreateTemp(filepath.Dir(chunkPath), ".tmp_")
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = os.Remove(f.Name()) }()
+		defer func() { _ = f.Close() }()
+
+		fw, err := flate.NewWriter(f, flate.BestSpeed)
+		if err != nil {
+			return err
+		}
+
+		_, err = fw.Write(w.buf)
+		if err != nil {
+			return fmt.Errorf("Failed writing chunk %q: %w", hash, err)
+		}
+
+		err = fw.Close()
+		if err != nil {
+			return fmt.Errorf("Failed writing chunk %q: %w", hash, err)
+		}
+
+		err = f.Close()
+		if err != nil {
+			return fmt.Errorf("Failed writing chunk %q: %w", hash, err)
+		}
+
+		err = os.Rename(f.Name(), chunkPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	w.index.Chunks = append(w.index.Chunks, DedupChunk{Hash: hash, Size: int64(len(w.buf))})
+	w.index.Size += int64(len(w.buf))
+	w.buf = w.buf[:0]
+	w.hash = 0
+
+	return nil
+}
+
+// Close stores the last chunk and writes the index of the backup.
+func (w *DedupWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	defer dedupLock.RUnlock()
+
+	if len(w.buf) > 0 {
+		err := w.flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	indexData, err := json.Marshal(w.index)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(w.target, append([]byte(dedupIndexHeader), indexData...), 0600)
+	if err != nil {
+		return fmt.Errorf("Failed writing deduplication index %q: %w", w.target, err)
+	}
+
+	return nil
+}
+
+// DedupIndexLoad returns the index of the deduplicated backup at path.
+// A nil index is returned if the backup isn't deduplicated.
+func DedupIndexLoad(path string) (*DedupIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	header := make([]byte, len(dedupIndexHeader))
+	_, err = io.ReadFull(f, header)
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if string(header) != dedupIndexHeader {
+		return nil, nil
+	}
+
+	index := DedupIndex{}
+
+	err = json.NewDecoder(f).Decode(&index)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing deduplication index %q: %w", path, err)
+	}
+
+	return &index, nil
+}
+
+// dedupChunkRead returns the content of the chunk, verifying it against its hash.
+func dedupChunkRead(chunk DedupChunk) ([]byte, error) {
+	f, err := os.Open(dedupChunkPath(chunk.Hash))
+	if err != nil {
+		return nil, fmt.Errorf("Failed opening chunk %q: %w", chunk.Hash, err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	data, err := io.ReadAll(flate.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading chunk %q: %w", chunk.Hash, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != chunk.Hash || (chunk.Size >= 0 && int64(len(data)) != chunk.Size) {
+		return nil, fmt.Errorf("Chunk %q is corrupted", chunk.Hash)
+	}
+
+	return data, nil
+}
+
+// DedupReader reassembles the tarball of a deduplicated backup.
+type DedupReader struct {
+	index   *DedupIndex
+	offsets []int64
+	pos     int64
+
+	chunkIndex int
+	chunk      []byte
+}
+
+// NewDedupReader returns a DedupReader for the backup with the given index.
+func NewDedupReader(index *DedupIndex) *DedupReader {
+	offsets := make([]int64, 0, len(index.Chunks))
+
+	var offset int64
+	for _, chunk := range index.Chunks {
+		offsets = append(offsets, offset)
+		offset += chunk.Size
+	}
+
+	return &DedupReader{
+		index:      index,
+		offsets:    offsets,
+		chunkIndex: -1,
+	}
+}
+
+// Read reads the tarball from the current position.
+func (r *DedupReader) Read(p []byte) (int, error) {
+	if r.pos >= r.index.Size {
+		return 0, io.EOF
+	}
+
+	// Find the chunk holding the current position.
+	i := sort.Search(len(r.offsets), func(i int) bool { return r.offsets[i] > r.pos }) - 1
+	if i != r.chunkIndex {
+		data, err := dedupChunkRead(r.index.Chunks[i])
+		if err != nil {
+			return 0, err
+		}
+
+		r.chunk = data
+		r.chunkIndex = i
+	}
+
+	n := copy(p, r.chunk[r.pos-r.offsets[i]:])
+	r.pos += int64(n)
+
+	return n, nil
+}
+
+// Seek sets the position of the next read.
+func (r *DedupReader) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = r.pos + offset
+	case io.SeekEnd:
+		pos = r.index.Size + offset
+	default:
+		return 0, fmt.Errorf("Invalid whence %d", whence)
+	}
+
+	if pos < 0 {
+		return 0, fmt.Errorf("Invalid offset %d", pos)
+	}
+
+	r.pos = pos
+
+	return pos, nil
+}
+
+// dedupIndexesWalk calls f with the path and index of every deduplicated backup.
+func dedupIndexesWalk(f func(path string, index *DedupIndex) error) error {
+	for _, backupsPath := range []string{shared.VarPath("backups", "instances"), shared.VarPath("backups", "custom")} {
+		err := filepath.WalkDir(backupsPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+
+				return err
+			}
+
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			index, err := DedupIndexLoad(path)
+			if err != nil {
+				return err
+			}
+
+			if index == nil {
+				return nil
+			}
+
+			return f(path, index)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dedupChunksWalk calls f with the path of every file of the deduplication store.
+func dedupChunksWalk(f func(path string, d fs.DirEntry) error) error {
+	return filepath.WalkDir(DedupStorePath(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		return f(path, d)
+	})
+}
+
+// DedupGarbageCollect removes the chunks of the deduplication store that aren't referenced by any backup.
+// It returns the number of removed chunks and the disk space they used.
+func DedupGarbageCollect(ctx context.Context) (int, int64, error) {
+	if !shared.PathExists(DedupStorePath()) {
+		return 0, 0, nil
+	}
+
+	dedupLock.Lock()
+	defer dedupLock.Unlock()
+
+	referenced := map[string]struct{}{}
+
+	err := dedupIndexesWalk(func(path string, index *DedupIndex) error {
+		for _, chunk := range index.Chunks {
+			referenced[chunk.Hash] = struct{}{}
+		}
+
+		return ctx.Err()
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("Failed listing deduplicated backups: %w", err)
+	}
+
+	var removed int
+	var freed int64
+
+	err = dedupChunksWalk(func(path string, d fs.DirEntry) error {
+		_, ok := referenced[d.Name()]
+		if ok {
+			return nil
+		}
+
+		// No backup is being written, so this is either an unreferenced chunk or a leftover temporary file.
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		err = os.Remove(path)
+		if err != nil {
+			return err
+		}
+
+		removed++
+		freed += info.Size()
+
+		return ctx.Err()
+	})
+	if err != nil {
+		return removed, freed, fmt.Errorf("Failed removing unreferenced chunks: %w", err)
+	}
+
+	return removed, freed, nil
+}
+
+// DedupScrub verifies the content of the chunks of the deduplication store against their hash.
+// Corrupted chunks are removed so that the next backup with the same content stores them again, and the paths of
+// the backups that referenced them are returned.
+func DedupScrub(ctx context.Context) ([]string, error) {
+	if !shared.PathExists(DedupStorePath()) {
+		return nil, nil
+	}
+
+	corrupted := map[string]struct{}{}
+
+	err := dedupChunksWalk(func(path string, d fs.DirEntry) error {
+		hash := d.Name()
+
+		_, err := hex.DecodeString(hash)
+		if err != nil || len(hash) != sha256.Size*2 {
+			return nil
+		}
+
+		_, err = dedupChunkRead(DedupChunk{Hash: hash, Size: -1})
+		if err != nil && shared.PathExists(path) {
+			corrupted[hash] = struct{}{}
+		}
+
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(corrupted) == 0 {
+		return nil, nil
+	}
+
+	dedupLock.Lock()
+	defer dedupLock.Unlock()
+
+	for hash := range corrupted {
+		err := os.Remove(dedupChunkPath(hash))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	var affected []string
+
+	err = dedupIndexesWalk(func(path string, index *DedupIndex) error {
+		for _, chunk := range index.Chunks {
+			_, ok := corrupted[chunk.Hash]
+			if ok {
+				affected = append(affected, path)
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,474 @@
+// Package benchmark measures how long a running daemon takes to perform common instance, storage and network
+// operations, so that the performance of releases and hardware configurations can be compared consistently.
+//
+// Benchmarking is disabled unless the LXD_BENCHMARK environment variable is set to true when the daemon starts, as
+// it creates and deletes entities on the server and must never be enabled on production systems.
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// ErrDisabled is returned when trying to run a benchmark while benchmarking is disabled.
+var ErrDisabled = fmt.Errorf("Benchmarking is disabled (set LXD_BENCHMARK=true when starting LXD to enable it)")
+
+// maxCount is the maximum number of entities of each kind a benchmark creates.
+const maxCount = 1000
+
+// maxErrors is the maximum number of errors reported for each phase.
+const maxErrors = 10
+
+// enabled indicates whether benchmarks can be run.
+var enabled = shared.IsTrue(os.Getenv("LXD_BENCHMARK"))
+
+// Enabled returns whether benchmarking is enabled.
+func Enabled() bool {
+	return enabled
+}
+
+// Config represents the operations performed by a benchmark.
+type Config struct {
+	// Number of operations run at the same time, the number of CPU threads if zero.
+	Concurrency int `json:"concurrency" yaml:"concurrency"`
+
+	// Number of instances to create, start, stop and delete.
+	Instances int `json:"instances" yaml:"instances"`
+
+	// Type of the instances ("container" if empty).
+	InstanceType string `json:"instance_type" yaml:"instance_type"`
+
+	// Alias or fingerprint of the local image the instances are created from.
+	Image string `json:"image" yaml:"image"`
+
+	// Profiles applied to the instances ("default" if empty).
+	Profiles []string `json:"profiles" yaml:"profiles"`
+
+	// Storage pool of the instances root disks and of the custom volumes.
+	Pool string `json:"pool" yaml:"pool"`
+
+	// Number of custom storage volumes to create and delete.
+	Volumes int `json:"volumes" yaml:"volumes"`
+
+	// Size of the custom storage volumes (the pool default if empty).
+	VolumeSize string `json:"volume_size" yaml:"volume_size"`
+
+	// Number of networks to create and delete.
+	Networks int `json:"networks" yaml:"networks"`
+
+	// Type of the networks ("bridge" if empty).
+	NetworkType string `json:"network_type" yaml:"network_type"`
+
+	// Configuration of the networks.
+	NetworkConfig map[string]string `json:"network_config" yaml:"network_config"`
+}
+
+// Environment represents the server a benchmark ran on.
+type Environment struct {
+	Server         string `json:"server"          yaml:"server"`
+	ServerVersion  string `json:"server_version"  yaml:"server_version"`
+	Architecture   string `json:"architecture"    yaml:"architecture"`
+	Kernel         string `json:"kernel"          yaml:"kernel"`
+	KernelVersion  string `json:"kernel_version"  yaml:"kernel_version"`
+	Driver         string `json:"driver"          yaml:"driver"`
+	DriverVersion  string `json:"driver_version"  yaml:"driver_version"`
+	Storage        string `json:"storage"         yaml:"storage"`
+	StorageVersion string `json:"storage_version" yaml:"storage_version"`
+	CPUs           int    `json:"cpus"            yaml:"cpus"`
+}
+
+// Phase represents the timings of the operations of a benchmark phase. All durations are in nanoseconds.
+type Phase struct {
+	// Name of the phase, for example "instance-create".
+	Name string `json:"name" yaml:"name"`
+
+	// Number of operations run.
+	Operations int `json:"operations" yaml:"operations"`
+
+	// Number of operations that failed.
+	Failures int `json:"failures" yaml:"failures"`
+
+	// Time taken by the whole phase.
+	Duration time.Duration `json:"duration" yaml:"duration"`
+
+	// Successful operations per second.
+	Rate float64 `json:"rate" yaml:"rate"`
+
+	// Statistics of the time taken by the successful operations.
+	Min    time.Duration `json:"min"    yaml:"min"`
+	Mean   time.Duration `json:"mean"   yaml:"mean"`
+	Median time.Duration `json:"median" yaml:"median"`
+	P95    time.Duration `json:"p95"    yaml:"p95"`
+	Max    time.Duration `json:"max"    yaml:"max"`
+
+	// First errors hit by the operations.
+	Errors []string `json:"errors" yaml:"errors"`
+}
+
+// Report represents the result of a benchmark.
+type Report struct {
+	Config      Config        `json:"config"      yaml:"config"`
+	Environment Environment   `json:"environment" yaml:"environment"`
+	StartedAt   time.Time     `json:"started_at"  yaml:"started_at"`
+	Duration    time.Duration `json:"duration"    yaml:"duration"`
+	Phases      []Phase       `json:"phases"      yaml:"phases"`
+}
+
+// Validate checks the benchmark configuration and fills in the defaults.
+func (c *Config) Validate() error {
+	if c.Concurrency < 0 {
+		return fmt.Errorf("Invalid concurrency %d", c.Concurrency)
+	}
+
+	if c.Concurrency == 0 {
+		c.Concurrency = runtime.NumCPU()
+	}
+
+	for kind, count := range map[string]int{"instances": c.Instances, "volumes": c.Volumes, "networks": c.Networks} {
+		if count < 0 || count > maxCount {
+			return fmt.Errorf("The number of %s must be between 0 and %d", kind, maxCount)
+		}
+	}
+
+	if c.Instances+c.Volumes+c.Networks == 0 {
+		return fmt.Errorf("Nothing to benchmark")
+	}
+
+	if c.Instances > 0 {
+		if c.InstanceType == "" {
+			c.InstanceType = string(api.InstanceTypeContainer)
+		}
+
+		if !shared.ValueInSlice(api.InstanceType(c.InstanceType), []api.InstanceType{api.InstanceTypeContainer, api.InstanceTypeVM}) {
+			return fmt.Errorf("Invalid instance type %q", c.InstanceType)
+		}
+
+		if c.Image == "" {
+			return fmt.Errorf("An image is required to benchmark instances")
+		}
+	}
+
+	if c.Volumes > 0 {
+		if c.Pool == "" {
+			return fmt.Errorf("A storage pool is required to benchmark storage volumes")
+		}
+
+		if c.VolumeSize != "" {
+			err := validate.IsSize(c.VolumeSize)
+			if err != nil {
+				return fmt.Errorf("Invalid volume size: %w", err)
+			}
+		}
+	}
+
+	if c.Networks > 0 && c.NetworkType == "" {
+		c.NetworkType = "bridge"
+	}
+
+	return nil
+}
+
+// Run runs the benchmark described by the configuration against the server and returns its report.
+// The progress function, if set, is called with the report after each phase.
+//
+// Cancelling the context stops creating, starting and stopping entities, but the entities already created are
+// still deleted.
+func Run(ctx context.Context, c lxd.InstanceServer, config Config, progress func(report Report)) (*Report, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	server, _, err := c.GetServer()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting server information: %w", err)
+	}
+
+	env := server.Environment
+	report := &Report{
+		Config: config,
+		Environment: Environment{
+			Server:         env.ServerName,
+			ServerVersion:  env.ServerVersion,
+			Architecture:   env.KernelArchitecture,
+			Kernel:         env.Kernel,
+			KernelVersion:  env.KernelVersion,
+			Driver:         env.Driver,
+			DriverVersion:  env.DriverVersion,
+			Storage:        env.Storage,
+			StorageVersion: env.StorageVersion,
+			CPUs:           runtime.NumCPU(),
+		},
+		StartedAt: time.Now(),
+	}
+
+	addPhase := func(phase Phase) {
+		report.Phases = append(report.Phases, phase)
+		report.Duration = time.Since(report.StartedAt)
+
+		if progress != nil {
+			progress(*report)
+		}
+	}
+
+	// Short enough to be a valid network interface name once the index is appended.
+	prefix := fmt.Sprintf("bench%04x", rand.Intn(0x10000))
+
+	if config.Instances > 0 {
+		err = runInstances(ctx, c, config, names(prefix, config.Instances), addPhase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Volumes > 0 {
+		runVolumes(ctx, c, config, names(prefix, config.Volumes), addPhase)
+	}
+
+	if config.Networks > 0 {
+		runNetworks(ctx, c, config, names(prefix, config.Networks), addPhase)
+	}
+
+	report.Duration = time.Since(report.StartedAt)
+
+	return report, nil
+}
+
+// runInstances creates, starts, stops and deletes the instances.
+func runInstances(ctx context.Context, c lxd.InstanceServer, config Config, instNames []string, addPhase func(Phase)) error {
+	source := api.InstanceSource{Type: "image"}
+
+	alias, _, err := c.GetImageAlias(config.Image)
+	if err == nil {
+		source.Alias = alias.Name
+	} else {
+		image, _, err := c.GetImage(config.Image)
+		if err != nil {
+			return fmt.Errorf("Failed getting image %q: %w", config.Image, err)
+		}
+
+		source.Fingerprint = image.Fingerprint
+	}
+
+	req := api.InstancesPost{
+		Source: source,
+		Type:   api.InstanceType(config.InstanceType),
+	}
+
+	req.Config = map[string]string{"user.lxd-benchmark": "true"}
+	req.Profiles = config.Profiles
+
+	if config.Pool != "" {
+		req.Devices = map[string]map[string]string{
+			"root": {"type": "disk", "path": "/", "pool": config.Pool},
+		}
+	}
+
+	phase, created := runPhase(ctx, "instance-create", instNames, config.Concurrency, func(name string) error {
+		instReq := req
+		instReq.Name = name
+
+		op, err := c.CreateInstance(instReq)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
+	addPhase(phase)
+
+	updateState := func(action string) func(name string) error {
+		return func(name string) error {
+			op, err := c.UpdateInstanceState(name, api.InstanceStatePut{Action: action, Timeout: -1, Force: true}, "")
+			if err != nil {
+				return err
+			}
+
+			return op.Wait()
+		}
+	}
+
+	phase, started := runPhase(ctx, "instance-start", created, config.Concurrency, updateState("start"))
+	addPhase(phase)
+
+	phase, _ = runPhase(ctx, "instance-stop", started, config.Concurrency, updateState("stop"))
+	addPhase(phase)
+
+	// Always clean up, even when cancelled.
+	phase, _ = runPhase(context.Background(), "instance-delete", created, config.Concurrency, func(name string) error {
+		op, err := c.DeleteInstance(name)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	})
+	addPhase(phase)
+
+	return nil
+}
+
+// runVolumes creates and deletes the custom storage volumes.
+func runVolumes(ctx context.Context, c lxd.InstanceServer, config Config, volNames []string, addPhase func(Phase)) {
+	phase, created := runPhase(ctx, "volume-create", volNames, config.Concurrency, func(name string) error {
+		req := api.StorageVolumesPost{
+			Name:        name,
+			Type:        "custom",
+			ContentType: "filesystem",
+		}
+
+		req.Config = map[string]string{}
+		if config.VolumeSize != "" {
+			req.Config["size"] = config.VolumeSize
+		}
+
+		return c.CreateStoragePoolVolume(config.Pool, req)
+	})
+	addPhase(phase)
+
+	// Always clean up, even when cancelled.
+	phase, _ = runPhase(context.Background(), "volume-delete", created, config.Concurrency, func(name string) error {
+		return c.DeleteStoragePoolVolume(config.Pool, "custom", name)
+	})
+	addPhase(phase)
+}
+
+// runNetworks creates and deletes the networks.
+func runNetworks(ctx context.Context, c lxd.InstanceServer, config Config, netNames []string, addPhase func(Phase)) {
+	phase, created := runPhase(ctx, "network-create", netNames, config.Concurrency, func(name string) error {
+		req := api.NetworksPost{
+			Name: name,
+			Type: config.NetworkType,
+		}
+
+		req.Config = map[string]string{}
+		for k, v := range config.NetworkConfig {
+			req.Config[k] = v
+		}
+
+		return c.CreateNetwork(req)
+	})
+	addPhase(phase)
+
+	// Always clean up, even when cancelled.
+	phase, _ = runPhase(context.Background(), "network-delete", created, config.Concurrency, c.DeleteNetwork)
+	addPhase(phase)
+}
+
+// names returns the names of the entities created by a benchmark.
+func names(prefix string, count int) []string {
+	names := make([]string, 0, count)
+	for i := 1; i <= count; i++ {
+		names = append(names, fmt.Sprintf("%s-%04d", prefix, i))
+	}
+
+	return names
+}
+
+// runPhase runs the function on all the names with the given concurrency, and returns the timings of the phase
+// along with the names the function succeeded for. No new operation is started once the context is cancelled.
+func runPhase(ctx context.Context, name string, names []string, concurrency int, f func(name string) error) (Phase, []string) {
+	phase := Phase{Name: name, Errors: []string{}}
+
+	var mu sync.Mutex
+	durations := make([]time.Duration, 0, len(names))
+	success := make([]bool, len(names))
+
+	queue := make(chan int)
+	wg := sync.WaitGroup{}
+	start := time.Now()
+
+	for range min(concurrency, len(names)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range queue {
+				entityName := names[i]
+				opStart := time.Now()
+				err := f(entityName)
+				elapsed := time.Since(opStart)
+
+				mu.Lock()
+				phase.Operations++
+				if err != nil {
+					phase.Failures++
+					if len(phase.Errors) < maxErrors {
+						phase.Errors = append(phase.Errors, fmt.Sprintf("%s: %v", entityName, err))
+					}
+				} else {
+					durations = append(durations, elapsed)
+					success[i] = true
+				}
+
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range names {
+		if ctx.Err() != nil {
+			break
+		}
+
+		queue <- i
+	}
+
+	close(queue)
+	wg.Wait()
+
+	phase.Duration = time.Since(start)
+	if phase.Duration > 0 {
+		phase.Rate = float64(len(durations)) / phase.Duration.Seconds()
+	}
+
+	summarize(&phase, durations)
+
+	// Keep the order of the names so that the following phases are reproducible.
+	succeeded := make([]string, 0, len(durations))
+	for i, entityName := range names {
+		if success[i] {
+			succeeded = append(succeeded, entityName)
+		}
+	}
+
+	return phase, succeeded
+}
+
+// summarize fills in the statistics of the phase from the durations of its successful operations.
+func summarize(phase *Phase, durations []time.Duration) {
+	if len(durations) == 0 {
+		return
+	}
+
+	slices.Sort(durations)
+
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+
+	// Nearest-rank percentile.
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p * float64(len(durations))))
+		return durations[max(rank, 1)-1]
+	}
+
+	phase.Min = durations[0]
+	phase.Max = durations[len(durations)-1]
+	phase.Mean = total / time.Duration(len(durations))
+	phase.Median = percentile(0.5)
+	phase.P95 = percentile(0.95)
+}
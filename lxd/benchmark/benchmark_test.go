@@ -0,0 +1,73 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	assert.Error(t, (&Config{}).Validate())
+	assert.Error(t, (&Config{Instances: 1}).Validate())
+	assert.Error(t, (&Config{Instances: 1, Image: "ubuntu", InstanceType: "unknown"}).Validate())
+	assert.Error(t, (&Config{Volumes: 1}).Validate())
+	assert.Error(t, (&Config{Volumes: 1, Pool: "default", VolumeSize: "big"}).Validate())
+	assert.Error(t, (&Config{Networks: maxCount + 1}).Validate())
+	assert.Error(t, (&Config{Networks: 1, Concurrency: -1}).Validate())
+
+	config := Config{Instances: 1, Image: "ubuntu", Networks: 1}
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, "container", config.InstanceType)
+	assert.Equal(t, "bridge", config.NetworkType)
+	assert.Positive(t, config.Concurrency)
+}
+
+func TestRunPhase(t *testing.T) {
+	entities := names("bench0000", 20)
+	assert.Equal(t, "bench0000-0001", entities[0])
+
+	phase, succeeded := runPhase(context.Background(), "test", entities, 4, func(name string) error {
+		if name == "bench0000-0003" {
+			return fmt.Errorf("Failed")
+		}
+
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+
+	assert.Equal(t, 20, phase.Operations)
+	assert.Equal(t, 1, phase.Failures)
+	assert.Equal(t, []string{"bench0000-0003: Failed"}, phase.Errors)
+	assert.Len(t, succeeded, 19)
+	assert.Equal(t, "bench0000-0004", succeeded[2])
+	assert.GreaterOrEqual(t, phase.Min, time.Millisecond)
+	assert.LessOrEqual(t, phase.Min, phase.Median)
+	assert.LessOrEqual(t, phase.Median, phase.P95)
+	assert.LessOrEqual(t, phase.P95, phase.Max)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	phase, succeeded = runPhase(ctx, "test", entities, 4, func(name string) error { return nil })
+	assert.Equal(t, 0, phase.Operations)
+	assert.Empty(t, succeeded)
+}
+
+func TestSummarize(t *testing.T) {
+	durations := []time.Duration{}
+	for i := 20; i > 0; i-- {
+		durations = append(durations, time.Duration(i)*time.Second)
+	}
+
+	phase := Phase{}
+	summarize(&phase, durations)
+
+	assert.Equal(t, time.Second, phase.Min)
+	assert.Equal(t, 20*time.Second, phase.Max)
+	assert.Equal(t, 10500*time.Millisecond, phase.Mean)
+	assert.Equal(t, 10*time.Second, phase.Median)
+	assert.Equal(t, 19*time.Second, phase.P95)
+}
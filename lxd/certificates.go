@@ -2,12 +2,17 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"math/big"
 	"net"
 	"net/http"
 	"net/url"
@@ -342,6 +347,9 @@ func certificateTokenValid(s *state.State, r *http.Request, addToken *api.Certif
 //	Adds a certificate to the trust store.
 //	In this mode, the `token` property is always ignored.
 //
+//	When `generate` is set on a metrics certificate, the server generates
+//	the certificate and key pair and returns them in the response metadata.
+//
 //	---
 //	consumes:
 //	  - application/json
@@ -390,6 +398,22 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	if req.Generate {
+		if req.Type != api.CertificateTypeMetrics {
+			return response.BadRequest(fmt.Errorf("Only metrics certificates can be generated"))
+		}
+
+		if req.Certificate != "" || req.Token {
+			return response.BadRequest(fmt.Errorf("Can't use certificate or token if generating a certificate"))
+		}
+
+		if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+			return response.BadRequest(fmt.Errorf("Expiry date must be in the future"))
+		}
+	} else if req.ExpiresAt != nil {
+		return response.BadRequest(fmt.Errorf("Expiry date can only be set when generating a certificate"))
+	}
+
 	// Check if the caller has permission to create certificates.
 	var userCanCreateCertificates bool
 	err = s.Authorizer.CheckPermission(r.Context(), r, entity.ServerURL(), auth.EntitlementCanCreateIdentities)
@@ -404,14 +428,32 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Failed to get authentication status: %w", err))
 	}
 
+	// Callers who can view the metrics of projects can generate metrics certificates restricted to those projects,
+	// so that the users of a project can monitor it themselves.
+	var delegatedMetrics bool
+	if trusted && !userCanCreateCertificates && req.Generate {
+		if !req.Restricted || len(req.Projects) == 0 {
+			return response.Forbidden(fmt.Errorf("Generated metrics certificates must be restricted to projects"))
+		}
+
+		for _, projectName := range req.Projects {
+			err := s.Authorizer.CheckPermission(r.Context(), r, entity.ProjectURL(projectName), auth.EntitlementCanViewMetrics)
+			if err != nil {
+				return response.SmartError(err)
+			}
+		}
+
+		delegatedMetrics = true
+	}
+
 	// If caller is already trusted and does not have permission to create certificates, they cannot create more certificates.
-	if trusted && !userCanCreateCertificates && req.Certificate == "" && !req.Token {
+	if trusted && !userCanCreateCertificates && !delegatedMetrics && req.Certificate == "" && !req.Token {
 		return response.BadRequest(fmt.Errorf("Client is already trusted"))
 	}
 
-	if !userCanCreateCertificates {
-		// Non-admin cannot issue tokens.
-		if req.Token {
+	if !userCanCreateCertificates && !delegatedMetrics {
+		// Non-admin cannot issue tokens or generate certificates.
+		if req.Token || req.Generate {
 			return response.Forbidden(nil)
 		}
 
@@ -471,7 +513,36 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 
 	// Extract the certificate.
 	var cert *x509.Certificate
-	if req.Certificate != "" {
+	var generated *api.CertificateGenerated
+	if req.Generate {
+		expiresAt := time.Now().AddDate(1, 0, 0)
+		if req.ExpiresAt != nil {
+			expiresAt = *req.ExpiresAt
+		}
+
+		name := req.Name
+		if name == "" {
+			name = "metrics"
+		}
+
+		certPEM, keyPEM, err := certificateGenerateMetrics(name, expiresAt)
+		if err != nil {
+			return response.InternalError(err)
+		}
+
+		certBlock, _ := pem.Decode(certPEM)
+		cert, err = x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			return response.InternalError(err)
+		}
+
+		generated = &api.CertificateGenerated{
+			Fingerprint: shared.CertFingerprint(cert),
+			Certificate: string(certPEM),
+			Key:         string(keyPEM),
+			ExpiresAt:   cert.NotAfter,
+		}
+	} else if req.Certificate != "" {
 		// Add supplied certificate.
 		data, err := base64.StdEncoding.DecodeString(req.Certificate)
 		if err != nil {
@@ -623,6 +694,11 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 	lc := lifecycle.CertificateCreated.Event(fingerprint, request.CreateRequestor(r), nil)
 	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
 
+	// Return the generated key pair, it isn't stored anywhere on the server.
+	if generated != nil {
+		return response.SyncResponseLocation(true, generated, lc.Source)
+	}
+
 	return response.SyncResponseLocation(true, nil, lc.Source)
 }
 
@@ -1064,6 +1140,49 @@ func certificateDelete(d *Daemon, r *http.Request) response.Response {
 	return response.EmptySyncResponse
 }
 
+// certificateGenerateMetrics creates a client certificate and key pair for a metrics scraper.
+// The certificate is valid until expiresAt and uses the given name as its common name.
+func certificateGenerateMetrics(name string, expiresAt time.Time) ([]byte, []byte, error) {
+	privk, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to generate key: %w", err)
+	}
+
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to generate serial number: %w", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{"LXD"},
+			CommonName:   name,
+		},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              expiresAt,
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &privk.PublicKey, privk)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to create certificate: %w", err)
+	}
+
+	data, err := x509.MarshalECPrivateKey(privk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: data})
+
+	return cert, key, nil
+}
+
 func certificateValidate(cert *x509.Certificate) error {
 	if time.Now().Before(cert.NotBefore) {
 		return fmt.Errorf("The provided certificate isn't valid yet")
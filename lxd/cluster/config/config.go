@@ -2,7 +2,10 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -11,8 +14,11 @@ import (
 
 	"github.com/canonical/lxd/lxd/config"
 	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/maintenance"
+	"github.com/canonical/lxd/lxd/rsync"
 	scriptletLoad "github.com/canonical/lxd/lxd/scriptlet/load"
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/validate"
 )
 
@@ -44,6 +50,21 @@ func (c *Config) BackupsCompressionAlgorithm() string {
 	return c.m.GetString("backups.compression_algorithm")
 }
 
+// BackupsDeduplication returns whether backups are stored in the deduplication store.
+func (c *Config) BackupsDeduplication() bool {
+	return c.m.GetBool("backups.deduplication")
+}
+
+// MigrationCompressionAlgorithm returns the compression algorithm to use for migration transfers.
+func (c *Config) MigrationCompressionAlgorithm() string {
+	return c.m.GetString("migration.compression_algorithm")
+}
+
+// MigrationVerifyChecksums returns whether to verify the block volume data of migration transfers.
+func (c *Config) MigrationVerifyChecksums() bool {
+	return c.m.GetBool("migration.verify_checksums")
+}
+
 // MetricsAuthentication checks whether metrics API requires authentication.
 func (c *Config) MetricsAuthentication() bool {
 	return c.m.GetBool("core.metrics_authentication")
@@ -54,6 +75,11 @@ func (c *Config) BGPASN() int64 {
 	return c.m.GetInt64("core.bgp_asn")
 }
 
+// ConfigHistoryRevisions returns the number of configuration revisions to keep per entity.
+func (c *Config) ConfigHistoryRevisions() int64 {
+	return c.m.GetInt64("core.config_history_revisions")
+}
+
 // HTTPSAllowedHeaders returns the relevant CORS setting.
 func (c *Config) HTTPSAllowedHeaders() string {
 	return c.m.GetString("core.https_allowed_headers")
@@ -120,6 +146,11 @@ func (c *Config) ImagesMinimalReplica() int64 {
 	return c.m.GetInt64("cluster.images_minimal_replica")
 }
 
+// ImagesVolume returns the name of the pool/volume shared by all members to store the image tarballs.
+func (c *Config) ImagesVolume() string {
+	return c.m.GetString("cluster.images_volume")
+}
+
 // MaxVoters returns the maximum number of members in a cluster that will be
 // assigned the voter role.
 func (c *Config) MaxVoters() int64 {
@@ -147,6 +178,25 @@ func (c *Config) NetworkOVNSSL() (caCert string, clientCert string, clientKey st
 	return c.m.GetString("network.ovn.ca_cert"), c.m.GetString("network.ovn.client_cert"), c.m.GetString("network.ovn.client_key")
 }
 
+// ApprovalsActions returns the actions that require an approval from another identity.
+func (c *Config) ApprovalsActions() []string {
+	if c.m.GetString("core.approvals.actions") == "" {
+		return nil
+	}
+
+	return strings.Split(c.m.GetString("core.approvals.actions"), ",")
+}
+
+// ApprovalsExpiry returns for how long requests for approval and approvals are valid.
+func (c *Config) ApprovalsExpiry() string {
+	return c.m.GetString("core.approvals.expiry")
+}
+
+// TombstonesExpiry returns for how long the definitions of deleted entities are kept.
+func (c *Config) TombstonesExpiry() string {
+	return c.m.GetString("core.tombstones_expiry")
+}
+
 // ShutdownTimeout returns the number of minutes to wait for running operation to complete
 // before LXD server shut down.
 func (c *Config) ShutdownTimeout() time.Duration {
@@ -154,6 +204,11 @@ func (c *Config) ShutdownTimeout() time.Duration {
 	return time.Duration(n) * time.Minute
 }
 
+// OperationCallbacks returns the secret used to sign operation callbacks and how many times to retry them.
+func (c *Config) OperationCallbacks() (secret string, retries int64) {
+	return c.m.GetString("core.operation_callbacks.secret"), c.m.GetInt64("core.operation_callbacks.retries")
+}
+
 // ImagesDefaultArchitecture returns the default architecture.
 func (c *Config) ImagesDefaultArchitecture() string {
 	return c.m.GetString("images.default_architecture")
@@ -194,6 +249,21 @@ func (c *Config) InstancesMigrationStateful() bool {
 	return c.m.GetBool("instances.migration.stateful")
 }
 
+// InstancesUniqueNames returns whether instance names must be unique across all projects.
+func (c *Config) InstancesUniqueNames() bool {
+	return c.m.GetBool("instances.unique_names")
+}
+
+// InstancesUsageInterval returns the interval at which instance resource usage is sampled.
+func (c *Config) InstancesUsageInterval() time.Duration {
+	return time.Duration(c.m.GetInt64("instances.usage.interval")) * time.Minute
+}
+
+// InstancesUsageRetention returns for how long instance resource usage samples are kept.
+func (c *Config) InstancesUsageRetention() time.Duration {
+	return time.Duration(c.m.GetInt64("instances.usage.retention")) * time.Hour
+}
+
 // LokiServer returns all the Loki settings needed to connect to a server.
 func (c *Config) LokiServer() (apiURL string, authUsername string, authPassword string, apiCACert string, instance string, logLevel string, labels []string, types []string) {
 	if c.m.GetString("loki.types") != "" {
@@ -212,21 +282,72 @@ func (c *Config) ACME() (domain string, email string, caURL string, agreeTOS boo
 	return c.m.GetString("acme.domain"), c.m.GetString("acme.email"), c.m.GetString("acme.ca_url"), c.m.GetBool("acme.agree_tos")
 }
 
+// ACMEChallenge returns the ACME challenge type along with the DNS provider settings used for DNS-01 challenges.
+func (c *Config) ACMEChallenge() (challenge string, provider string, environment []string, resolvers []string) {
+	for _, line := range strings.Split(c.m.GetString("acme.provider.environment"), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			environment = append(environment, line)
+		}
+	}
+
+	return c.m.GetString("acme.challenge"), c.m.GetString("acme.provider"), environment, shared.SplitNTrimSpace(c.m.GetString("acme.provider.resolvers"), ",", -1, true)
+}
+
+// ACMEListenerDomain returns the domain for which the dedicated certificate of the given listener is issued.
+func (c *Config) ACMEListenerDomain(listener string) string {
+	return c.m.GetString("acme." + listener + ".domain")
+}
+
 // ClusterJoinTokenExpiry returns the cluster join token expiry.
 func (c *Config) ClusterJoinTokenExpiry() string {
 	return c.m.GetString("cluster.join_token_expiry")
 }
 
+// MaintenanceWindow returns the windows during which heavy automated tasks are allowed to run.
+func (c *Config) MaintenanceWindow() string {
+	return c.m.GetString("core.maintenance_window")
+}
+
 // RemoteTokenExpiry returns the time after which a remote add token expires.
 func (c *Config) RemoteTokenExpiry() string {
 	return c.m.GetString("core.remote_token_expiry")
 }
 
+// DatabaseSnapshots returns all the settings needed to ship database snapshots to an S3 bucket.
+func (c *Config) DatabaseSnapshots() (s3URL string, bucket string, accessKey string, secretKey string) {
+	return c.m.GetString("database.snapshots.s3.url"), c.m.GetString("database.snapshots.s3.bucket"), c.m.GetString("database.snapshots.s3.access_key"), c.m.GetString("database.snapshots.s3.secret_key")
+}
+
+// DatabaseSnapshotsIntervalHours returns the interval in hours at which database snapshots are shipped.
+func (c *Config) DatabaseSnapshotsIntervalHours() int64 {
+	return c.m.GetInt64("database.snapshots.interval")
+}
+
+// DatabaseSnapshotsRetention returns the number of database snapshots to keep.
+func (c *Config) DatabaseSnapshotsRetention() int64 {
+	return c.m.GetInt64("database.snapshots.retention")
+}
+
+// NATSServer returns all the settings needed to publish events to a NATS server.
+func (c *Config) NATSServer() (natsURL string, username string, password string, token string, caCert string, subject string, types []string) {
+	if c.m.GetString("nats.types") != "" {
+		types = strings.Split(c.m.GetString("nats.types"), ",")
+	}
+
+	return c.m.GetString("nats.url"), c.m.GetString("nats.auth.username"), c.m.GetString("nats.auth.password"), c.m.GetString("nats.auth.token"), c.m.GetString("nats.ca_cert"), c.m.GetString("nats.subject"), types
+}
+
 // OIDCServer returns all the OpenID Connect settings needed to connect to a server.
 func (c *Config) OIDCServer() (issuer string, clientID string, audience string, groupsClaim string) {
 	return c.m.GetString("oidc.issuer"), c.m.GetString("oidc.client.id"), c.m.GetString("oidc.audience"), c.m.GetString("oidc.groups.claim")
 }
 
+// SCIMToken returns the bearer token identity providers must present to use the SCIM provisioning endpoint.
+func (c *Config) SCIMToken() string {
+	return c.m.GetString("oidc.scim.token")
+}
+
 // ClusterHealingThreshold returns the configured healing threshold, i.e. the
 // number of seconds after which an offline node will be evacuated automatically. If the config key
 // is set but its value is lower than cluster.offline_threshold it returns
@@ -322,8 +443,83 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Agree to ACME terms of service
 	"acme.agree_tos": {Type: config.Bool, Default: "false"},
 
+	// lxdmeta:generate(entities=server; group=acme; key=acme.challenge)
+	// Use `HTTP-01` if LXD is reachable on port 80 for the domain, otherwise use `DNS-01` together with
+	// {config:option}`server-acme:acme.provider`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `HTTP-01`
+	//  shortdesc: ACME challenge type to use
+	"acme.challenge": {Default: "HTTP-01", Validator: validate.Optional(validate.IsOneOf("HTTP-01", "DNS-01"))},
+
+	// lxdmeta:generate(entities=server; group=acme; key=acme.provider)
+	// Supported providers are `exec` (run a program), `httpreq` (call an HTTP endpoint) and `rfc2136`
+	// (dynamic DNS updates).
+	// This setting is used only when {config:option}`server-acme:acme.challenge` is set to `DNS-01`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: DNS provider used to answer DNS-01 challenges
+	"acme.provider": {},
+
+	// lxdmeta:generate(entities=server; group=acme; key=acme.provider.environment)
+	// Specify one `KEY=VALUE` setting per line. The setting names are the environment variables documented
+	// for the matching lego DNS provider, for example `RFC2136_NAMESERVER`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Settings of the DNS provider
+	"acme.provider.environment": {},
+
+	// lxdmeta:generate(entities=server; group=acme; key=acme.provider.resolvers)
+	// Specify a comma-separated list of DNS resolvers used to check that the DNS-01 record has propagated.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: System resolvers
+	//  shortdesc: DNS resolvers used for DNS-01 challenges
+	"acme.provider.resolvers": {Validator: validate.Optional(validate.IsListOf(validate.IsListenAddress(false, false, false)))},
+
+	// lxdmeta:generate(entities=server; group=acme; key=acme.metrics.domain)
+	// If set, the metrics listener uses a dedicated certificate issued for this domain instead of the
+	// server certificate.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Domain for which the metrics listener certificate is issued
+	"acme.metrics.domain": {},
+
+	// lxdmeta:generate(entities=server; group=acme; key=acme.storage_buckets.domain)
+	// If set, the storage buckets listener uses a dedicated certificate issued for this domain instead of the
+	// server certificate.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Domain for which the storage buckets listener certificate is issued
+	"acme.storage_buckets.domain": {},
+
+	// lxdmeta:generate(entities=server; group=acme; key=acme.events.domain)
+	// If set, the events listener uses a dedicated certificate issued for this domain instead of the
+	// server certificate.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Domain for which the events listener certificate is issued
+	"acme.events.domain": {},
+
+	// lxdmeta:generate(entities=server; group=acme; key=acme.migration.domain)
+	// If set, the migration listener uses a dedicated certificate issued for this domain instead of the
+	// server certificate.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Domain for which the migration listener certificate is issued
+	"acme.migration.domain": {},
+
 	// lxdmeta:generate(entities=server; group=miscellaneous; key=backups.compression_algorithm)
-	// Possible values are `bzip2`, `gzip`, `lzma`, `xz`, or `none`.
+	// Possible values are `bzip2`, `gzip`, `lz4`, `lzma`, `xz`, `zstd`, or `none`.
+	// To set a compression level, append it to the algorithm, for example, `zstd -3`.
 	// ---
 	//  type: string
 	//  scope: global
@@ -331,6 +527,18 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Compression algorithm to use for backups
 	"backups.compression_algorithm": {Default: "gzip", Validator: validate.IsCompressionAlgorithm},
 
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=backups.deduplication)
+	// When enabled, new backups are split into content-defined chunks that are stored once in a local
+	// deduplication store shared by all backups of the server.
+	// Backups of similar instances or volumes then share most of their chunks on disk.
+	// The configured compression is applied when the backup is exported.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to deduplicate the content of backups
+	"backups.deduplication": {Type: config.Bool, Default: "false"},
+
 	// lxdmeta:generate(entities=server; group=cluster; key=cluster.offline_threshold)
 	// Specify the number of seconds after which an unresponsive member is considered offline.
 	// ---
@@ -349,6 +557,20 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Number of cluster members that replicate an image
 	"cluster.images_minimal_replica": {Type: config.Int64, Default: "3", Validator: imageMinimalReplicaValidator},
 
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.images_volume)
+	// Specify the volume using the syntax `POOL/VOLUME`.
+	// The volume must be on a storage pool that can be mounted by all members at the same time (CephFS).
+	// All members then store their image tarballs on this volume instead of their local disk, and images are no
+	// longer replicated between members.
+	// The {config:option}`server-miscellaneous:storage.images_volume` option of a member takes precedence.
+	//
+	// See {ref}`cluster-manage-images-volume` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Volume shared by all members to store the image tarballs
+	"cluster.images_volume": {},
+
 	// lxdmeta:generate(entities=server; group=cluster; key=cluster.healing_threshold)
 	// Specify the number of seconds after which an offline cluster member is to be evacuated.
 	// To disable evacuating offline members, set this option to `0`.
@@ -397,6 +619,26 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to enforce authentication on the metrics endpoint
 	"core.metrics_authentication": {Type: config.Bool, Default: "true"},
 
+	// lxdmeta:generate(entities=server; group=core; key=core.approvals.actions)
+	// Specify a comma-separated list of actions that require an approval from another identity.
+	// The actions can be any combination of `delete-instance`, `set-privileged` and `attach-raw-disk`.
+	// Requests made through the Unix socket don't require approvals.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Actions that require an approval
+	"core.approvals.actions": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf(api.AuthApprovalActionDeleteInstance, api.AuthApprovalActionSetPrivileged, api.AuthApprovalActionAttachRawDisk)))},
+
+	// lxdmeta:generate(entities=server; group=core; key=core.approvals.expiry)
+	// Specify for how long a request for approval waits for a decision, and for how long an approval can be used once granted.
+	// The value uses the expiry format (for example, `1H` or `30M`).
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `1H`
+	//  shortdesc: How long approvals are valid
+	"core.approvals.expiry": {Type: config.String, Default: "1H", Validator: validate.Optional(expiryValidator)},
+
 	// lxdmeta:generate(entities=server; group=core; key=core.bgp_asn)
 	//
 	// ---
@@ -405,6 +647,16 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: BGP Autonomous System Number for the local server
 	"core.bgp_asn": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsInRange(0, 4294967294))},
 
+	// lxdmeta:generate(entities=server; group=core; key=core.config_history_revisions)
+	// Specify the number of configuration revisions to keep for each instance, profile, network and storage pool.
+	// To disable recording configuration revisions, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `10`
+	//  shortdesc: Number of configuration revisions to keep per entity
+	"core.config_history_revisions": {Type: config.Int64, Default: "10", Validator: validate.Optional(validate.IsInRange(0, 1000))},
+
 	// lxdmeta:generate(entities=server; group=core; key=core.https_allowed_headers)
 	//
 	// ---
@@ -446,6 +698,37 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Trusted servers to provide the client's address
 	"core.https_trusted_proxy": {},
 
+	// lxdmeta:generate(entities=server; group=core; key=core.maintenance_window)
+	// Specify a comma-separated list of windows, in the local time of each cluster member, during which heavy automated tasks are allowed to run.
+	// Each window has the form `[<days>] <HH:MM>-<HH:MM>`, where `<days>` is an optional `|` separated list of days or day ranges, for example `mon-fri 01:00-05:00, sat|sun 00:00-08:00`.
+	// This applies to image auto-updates, the pruning of expired snapshots and backups and the backup deduplication scrub.
+	// Projects can override it with {config:option}`project-specific:maintenance.window`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: no restriction
+	//  shortdesc: When heavy automated tasks are allowed to run
+	"core.maintenance_window": {Validator: maintenance.Validate},
+
+	// lxdmeta:generate(entities=server; group=core; key=core.operation_callbacks.secret)
+	// When set, the payloads sent to the callback URLs of operations are signed with HMAC-SHA256 using this secret.
+	// The signature is sent in the `X-LXD-Signature` header as `sha256=<hex digest>`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Secret used to sign operation callbacks
+	"core.operation_callbacks.secret": {},
+
+	// lxdmeta:generate(entities=server; group=core; key=core.operation_callbacks.retries)
+	// Specify how many times LXD retries sending the completion of an operation to its callback URL if that fails.
+	// The delay between two attempts doubles after each attempt, starting from one second.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `3`
+	//  shortdesc: Number of retries for operation callbacks
+	"core.operation_callbacks.retries": {Type: config.Int64, Default: "3", Validator: validate.Optional(validate.IsInRange(0, 10))},
+
 	// lxdmeta:generate(entities=server; group=core; key=core.proxy_http)
 	// If this option is not specified, LXD falls back to the `HTTP_PROXY` environment variable (if set).
 	// ---
@@ -490,6 +773,16 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: How long to wait before shutdown
 	"core.shutdown_timeout": {Type: config.Int64, Default: "5"},
 
+	// lxdmeta:generate(entities=server; group=core; key=core.tombstones_expiry)
+	// Specify for how long the definition of a deleted instance, profile or network is kept so that it can be restored.
+	// The value uses the expiry format (for example, `1d` or `12H`). To disable keeping tombstones, set this option to `0d`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `1d`
+	//  shortdesc: How long to keep the definitions of deleted entities
+	"core.tombstones_expiry": {Type: config.String, Default: "1d", Validator: validate.Optional(expiryValidator)},
+
 	// lxdmeta:generate(entities=server; group=core; key=core.trust_ca_certificates)
 	//
 	// ---
@@ -499,6 +792,58 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to automatically trust clients signed by the CA
 	"core.trust_ca_certificates": {Type: config.Bool, Default: "false"},
 
+	// lxdmeta:generate(entities=server; group=database; key=database.snapshots.interval)
+	// Specify the interval in hours at which a snapshot of the global database is shipped to the configured S3 bucket.
+	// To disable shipping snapshots, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `1`
+	//  shortdesc: Interval at which to ship database snapshots
+	"database.snapshots.interval": {Type: config.Int64, Default: "1", Validator: validate.Optional(validate.IsInt64)},
+
+	// lxdmeta:generate(entities=server; group=database; key=database.snapshots.retention)
+	// Specify the number of database snapshots to keep in the S3 bucket.
+	// Older snapshots are deleted after a new one is shipped.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `24`
+	//  shortdesc: Number of database snapshots to keep
+	"database.snapshots.retention": {Type: config.Int64, Default: "24", Validator: validate.Optional(validate.IsInRange(1, 10000))},
+
+	// lxdmeta:generate(entities=server; group=database; key=database.snapshots.s3.access_key)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Access key for the S3 bucket database snapshots are shipped to
+	"database.snapshots.s3.access_key": {},
+
+	// lxdmeta:generate(entities=server; group=database; key=database.snapshots.s3.bucket)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Name of the S3 bucket database snapshots are shipped to
+	"database.snapshots.s3.bucket": {},
+
+	// lxdmeta:generate(entities=server; group=database; key=database.snapshots.s3.secret_key)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Secret key for the S3 bucket database snapshots are shipped to
+	"database.snapshots.s3.secret_key": {},
+
+	// lxdmeta:generate(entities=server; group=database; key=database.snapshots.s3.url)
+	// Specify the URL of the S3 endpoint, for example `https://s3.example.com`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: URL of the S3 endpoint database snapshots are shipped to
+	"database.snapshots.s3.url": {Validator: validate.Optional(validate.IsRequestURL)},
+
 	// lxdmeta:generate(entities=server; group=images; key=images.auto_update_cached)
 	//
 	// ---
@@ -572,6 +917,37 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to set `migration.stateful` to `true` for the instances
 	"instances.migration.stateful": {Type: config.Bool, Default: "false"},
 
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=instances.unique_names)
+	// By default, instance names only need to be unique within a project.
+	// Enable this option to require instance names to be unique across all projects, so that they can be used as unambiguous DNS names and identifiers by external tooling.
+	// The option can only be enabled if no instance name is currently used in more than one project.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether instance names must be unique across all projects
+	"instances.unique_names": {Type: config.Bool, Default: "false"},
+
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=instances.usage.interval)
+	// Specify the interval in minutes at which the CPU, memory and disk usage of running instances is sampled.
+	// The samples are kept in memory and can be retrieved through the `/1.0/instances/<name>/usage` endpoint.
+	// To disable sampling, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `5`
+	//  shortdesc: Interval at which instance resource usage is sampled
+	"instances.usage.interval": {Type: config.Int64, Default: "5", Validator: validate.Optional(validate.IsInRange(0, 1440))},
+
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=instances.usage.retention)
+	// Specify for how many hours instance resource usage samples are kept.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `168`
+	//  shortdesc: How long to keep instance resource usage samples
+	"instances.usage.retention": {Type: config.Int64, Default: "168", Validator: validate.Optional(validate.IsInRange(1, 8760))},
+
 	// lxdmeta:generate(entities=server; group=loki; key=loki.auth.username)
 	//
 	// ---
@@ -655,6 +1031,88 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: URL of the MAAS server
 	"maas.api.url": {},
 
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=migration.compression_algorithm)
+	// Possible values are `zlib`, `zstd`, `lz4`, or `none`.
+	// To set a compression level, append it to the algorithm, for example, `zstd -3`.
+	// If the other side of the migration doesn't support the algorithm, `zlib` is used.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `zlib`
+	//  shortdesc: Compression algorithm to use for migration transfers
+	"migration.compression_algorithm": {Default: "zlib", Validator: migrationCompressionValidator},
+
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=migration.verify_checksums)
+	// When enabled, the source sends checksums of the transferred block volume data and the target verifies them.
+	// The migration fails if the data doesn't match.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to verify block volume data after migration transfers
+	"migration.verify_checksums": {Type: config.Bool, Default: "false"},
+
+	// lxdmeta:generate(entities=server; group=nats; key=nats.url)
+	// Specify the URL of the NATS server, for example `nats://nats.example.com:4222`.
+	// Use the `tls://` scheme to connect over TLS.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: URL of the NATS server to publish events to
+	"nats.url": {Validator: validate.Optional(natsURLValidator)},
+
+	// lxdmeta:generate(entities=server; group=nats; key=nats.auth.username)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: User name used for NATS authentication
+	"nats.auth.username": {},
+
+	// lxdmeta:generate(entities=server; group=nats; key=nats.auth.password)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Password used for NATS authentication
+	"nats.auth.password": {},
+
+	// lxdmeta:generate(entities=server; group=nats; key=nats.auth.token)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Token used for NATS authentication
+	"nats.auth.token": {},
+
+	// lxdmeta:generate(entities=server; group=nats; key=nats.ca_cert)
+	//
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: CA certificate for the NATS server
+	"nats.ca_cert": {},
+
+	// lxdmeta:generate(entities=server; group=nats; key=nats.subject)
+	// Specify the subject that events are published to.
+	// The `{type}`, `{action}`, `{project}` and `{location}` placeholders are replaced with the event type, the lifecycle action (or the event type for other events), the project and the cluster member of the event.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `lxd.{type}.{project}`
+	//  shortdesc: Subject to publish events to
+	"nats.subject": {Default: "lxd.{type}.{project}", Validator: validate.Optional(natsSubjectValidator)},
+
+	// lxdmeta:generate(entities=server; group=nats; key=nats.types)
+	// Specify a comma-separated list of events to publish to the NATS server.
+	// The events can be any combination of `lifecycle`, `operation` and `logging`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `lifecycle,operation`
+	//  shortdesc: Events to publish to the NATS server
+	"nats.types": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("lifecycle", "operation", "logging"))), Default: "lifecycle,operation"},
+
 	// lxdmeta:generate(entities=server; group=oidc; key=oidc.client.id)
 	//
 	// ---
@@ -689,6 +1147,15 @@ var ConfigSchema = config.Schema{
 	//  scope: global
 	//  shortdesc: Expected audience value for the application
 	"oidc.groups.claim": {},
+
+	// lxdmeta:generate(entities=server; group=oidc; key=oidc.scim.token)
+	// When set, identity providers can provision OIDC identities and their group memberships through
+	// the SCIM 2.0 endpoint at `/scim/v2/` by presenting this token as a bearer token.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Bearer token for SCIM provisioning
+	"oidc.scim.token": {},
 	// OVN networking global keys.
 
 	// lxdmeta:generate(entities=server; group=miscellaneous; key=network.ovn.integration_bridge)
@@ -817,3 +1284,40 @@ func maxStandByValidator(value string) error {
 
 	return nil
 }
+
+func migrationCompressionValidator(value string) error {
+	_, _, err := rsync.ParseCompression(value)
+
+	return err
+}
+
+func natsURLValidator(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains([]string{"nats", "tls"}, u.Scheme) {
+		return fmt.Errorf("Unsupported URL scheme %q, must be \"nats\" or \"tls\"", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return errors.New("Missing host in URL")
+	}
+
+	return nil
+}
+
+func natsSubjectValidator(value string) error {
+	if strings.ContainsAny(value, " \t\r\n*>") {
+		return errors.New("Subject can't contain whitespace or wildcards")
+	}
+
+	for _, token := range strings.Split(value, ".") {
+		if token == "" {
+			return errors.New("Subject can't contain empty tokens")
+		}
+	}
+
+	return nil
+}
@@ -67,6 +67,12 @@ func Connect(address string, networkCert *shared.CertInfo, serverCert *shared.Ce
 
 			req.Header.Add(request.HeaderForwardedAddress, r.RemoteAddr)
 
+			// Only forwarded requests carry the operation callback, notifications don't start the operation.
+			callbackURL := r.Header.Get(request.HeaderOperationCallback)
+			if callbackURL != "" && !notify {
+				req.Header.Set(request.HeaderOperationCallback, callbackURL)
+			}
+
 			identityProviderGroupsAny := ctx.Value(request.CtxIdentityProviderGroups)
 			if ok {
 				identityProviderGroups, ok := identityProviderGroupsAny.([]string)
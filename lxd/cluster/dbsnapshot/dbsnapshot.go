@@ -0,0 +1,198 @@
+package dbsnapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Snapshots are gzip compressed SQL text dumps of the global database, stored under a common prefix and named
+// after the time they were taken so that they sort chronologically.
+const (
+	objectPrefix = "lxd-database/"
+	objectSuffix = ".sql.gz"
+	nameLayout   = "20060102T150405Z"
+)
+
+// Target describes the S3 bucket database snapshots are shipped to.
+type Target struct {
+	URL       string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// Configured returns whether enough settings are set to ship snapshots to the target.
+func (t Target) Configured() bool {
+	return t.URL != "" && t.Bucket != ""
+}
+
+func (t Target) client() (*minio.Client, error) {
+	if !t.Configured() {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "No database snapshot target is configured")
+	}
+
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid database snapshot target URL: %w", err)
+	}
+
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:  credentials.NewStaticV4(t.AccessKey, t.SecretKey, ""),
+		Secure: u.Scheme == "https",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating S3 client: %w", err)
+	}
+
+	return client, nil
+}
+
+// Name returns the name of a snapshot taken at the given time.
+func Name(createdAt time.Time) string {
+	return createdAt.UTC().Format(nameLayout)
+}
+
+// Ship compresses the given SQL text dump of the global database and uploads it to the target.
+func Ship(ctx context.Context, target Target, dump string, createdAt time.Time) (*api.ClusterDatabaseSnapshot, error) {
+	client, err := target.client()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err = io.WriteString(w, dump)
+	if err != nil {
+		return nil, fmt.Errorf("Failed compressing database snapshot: %w", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, fmt.Errorf("Failed compressing database snapshot: %w", err)
+	}
+
+	snapshot := api.ClusterDatabaseSnapshot{
+		Name:      Name(createdAt),
+		CreatedAt: createdAt.UTC().Truncate(time.Second),
+		Size:      int64(buf.Len()),
+	}
+
+	_, err = client.PutObject(ctx, target.Bucket, objectPrefix+snapshot.Name+objectSuffix, &buf, snapshot.Size, minio.PutObjectOptions{ContentType: "application/gzip"})
+	if err != nil {
+		return nil, fmt.Errorf("Failed uploading database snapshot %q: %w", snapshot.Name, err)
+	}
+
+	return &snapshot, nil
+}
+
+// List returns the snapshots stored on the target, newest first.
+func List(ctx context.Context, target Target) ([]api.ClusterDatabaseSnapshot, error) {
+	client, err := target.client()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []api.ClusterDatabaseSnapshot{}
+	for object := range client.ListObjects(ctx, target.Bucket, minio.ListObjectsOptions{Prefix: objectPrefix}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("Failed listing database snapshots: %w", object.Err)
+		}
+
+		name, ok := strings.CutSuffix(strings.TrimPrefix(object.Key, objectPrefix), objectSuffix)
+		if !ok {
+			continue
+		}
+
+		createdAt, err := time.Parse(nameLayout, name)
+		if err != nil {
+			continue // Not a snapshot.
+		}
+
+		snapshots = append(snapshots, api.ClusterDatabaseSnapshot{
+			Name:      name,
+			CreatedAt: createdAt,
+			Size:      object.Size,
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+// Fetch downloads the snapshot with the given name from the target and returns its SQL text dump.
+func Fetch(ctx context.Context, target Target, name string) (string, error) {
+	_, err := time.Parse(nameLayout, name)
+	if err != nil {
+		return "", api.StatusErrorf(http.StatusBadRequest, "Invalid database snapshot name %q", name)
+	}
+
+	client, err := target.client()
+	if err != nil {
+		return "", err
+	}
+
+	object, err := client.GetObject(ctx, target.Bucket, objectPrefix+name+objectSuffix, minio.GetObjectOptions{})
+	if err != nil {
+		return "", fmt.Errorf("Failed downloading database snapshot %q: %w", name, err)
+	}
+
+	defer func() { _ = object.Close() }()
+
+	r, err := gzip.NewReader(object)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return "", api.StatusErrorf(http.StatusNotFound, "Database snapshot %q not found", name)
+		}
+
+		return "", fmt.Errorf("Failed downloading database snapshot %q: %w", name, err)
+	}
+
+	dump, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("Failed decompressing database snapshot %q: %w", name, err)
+	}
+
+	return string(dump), nil
+}
+
+// Prune deletes all but the given number of most recent snapshots from the target.
+func Prune(ctx context.Context, target Target, retention int) error {
+	snapshots, err := List(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	if len(snapshots) <= retention {
+		return nil
+	}
+
+	client, err := target.client()
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots[retention:] {
+		err = client.RemoveObject(ctx, target.Bucket, objectPrefix+snapshot.Name+objectSuffix, minio.RemoveObjectOptions{})
+		if err != nil {
+			return fmt.Errorf("Failed deleting database snapshot %q: %w", snapshot.Name, err)
+		}
+	}
+
+	return nil
+}
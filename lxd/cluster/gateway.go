@@ -116,6 +116,11 @@ type Gateway struct {
 	// detected a peer with an higher version.
 	upgradeTriggered bool
 
+	// Called when another member notifies that it applied a patch or
+	// reached a patch barrier, so that the patches waiting on other
+	// members re-check their condition.
+	PatchesNotifyHook func()
+
 	// Used for the heartbeat handler
 	Cluster                   *db.Cluster
 	HeartbeatNodeHook         HeartbeatHook
@@ -231,6 +236,15 @@ func (g *Gateway) HandlerFuncs(heartbeatHandler HeartbeatHandler, identityCache
 			return
 		}
 
+		// Handle patches notifications.
+		if r.Method == "PATCH" && r.Header.Get(patchesNotifyHeader) != "" {
+			if g.PatchesNotifyHook != nil {
+				g.PatchesNotifyHook()
+			}
+
+			return
+		}
+
 		// Handle database upgrade notifications.
 		if r.Method == "PATCH" {
 			select {
@@ -1122,6 +1136,10 @@ func dqliteMemoryDial(bindAddress string) client.DialFunc {
 // performing SQL queries against the dqlite server running on this node.
 const databaseEndpoint = "/internal/database"
 
+// Header distinguishing patches notifications from database upgrade notifications.
+// Members not knowing about it handle the request as an upgrade notification, which is harmless.
+const patchesNotifyHeader = "X-LXD-Patches-Notify"
+
 // DqliteLog redirects dqlite's logs to our own logger.
 func DqliteLog(l client.LogLevel, format string, a ...any) {
 	format = fmt.Sprintf("Dqlite: %s", format)
@@ -14,6 +14,7 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/query"
 	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/fault"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/lxd/warnings"
@@ -460,7 +461,7 @@ func (g *Gateway) heartbeat(ctx context.Context, mode heartbeatMode) {
 			return fmt.Errorf("Cluster unavailable")
 		}
 
-		return g.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return g.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 			for _, node := range hbState.Members {
 				if !node.updated {
 					// If member has not been updated during this heartbeat round it means
@@ -514,6 +515,10 @@ func (g *Gateway) heartbeat(ctx context.Context, mode heartbeatMode) {
 func HeartbeatNode(taskCtx context.Context, address string, networkCert *shared.CertInfo, serverCert *shared.CertInfo, heartbeatData *APIHeartbeat) error {
 	logger.Debug("Sending heartbeat request", logger.Ctx{"address": address})
 
+	if fault.HeartbeatDropped(address) {
+		return fmt.Errorf("Failed to send heartbeat request: Dropped by fault injection")
+	}
+
 	config, err := tlsClientConfig(networkCert, serverCert)
 	if err != nil {
 		return err
@@ -60,6 +60,51 @@ func NotifyUpgradeCompleted(state *state.State, networkCert *shared.CertInfo, se
 	})
 }
 
+// NotifyPatches sends a notification to all other online members of the cluster that this member applied a
+// patch or reached a patch barrier, so that the patches waiting on it re-check their condition straight away
+// rather than at their next periodic check.
+func NotifyPatches(state *state.State, networkCert *shared.CertInfo, serverCert *shared.CertInfo) error {
+	notifier, err := NewNotifier(state, networkCert, serverCert, NotifyAlive)
+	if err != nil {
+		return err
+	}
+
+	return notifier(func(client lxd.InstanceServer) error {
+		info, err := client.GetConnectionInfo()
+		if err != nil {
+			return fmt.Errorf("Failed to get connection info: %w", err)
+		}
+
+		url := fmt.Sprintf("%s%s", info.Addresses[0], databaseEndpoint)
+		request, err := http.NewRequest("PATCH", url, nil)
+		if err != nil {
+			return fmt.Errorf("Failed to create patches notification request: %w", err)
+		}
+
+		setDqliteVersionHeader(request)
+		request.Header.Set(patchesNotifyHeader, "1")
+
+		httpClient, err := client.GetHTTPClient()
+		if err != nil {
+			return fmt.Errorf("Failed to get HTTP client: %w", err)
+		}
+
+		httpClient.Timeout = 5 * time.Second
+		response, err := httpClient.Do(request)
+		if err != nil {
+			return fmt.Errorf("Failed to notify member about patches: %w", err)
+		}
+
+		_ = response.Body.Close()
+
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("Patches notification failed: %s", response.Status)
+		}
+
+		return nil
+	})
+}
+
 // MaybeUpdate Check this node's version and possibly run LXD_CLUSTER_UPDATE.
 func MaybeUpdate(state *state.State) error {
 	shouldUpdate := false
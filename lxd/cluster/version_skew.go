@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// Software components whose versions are recorded for each cluster member.
+const (
+	VersionComponentKernel = "kernel"
+	VersionComponentLXD    = "lxd"
+	VersionComponentLXC    = "lxc"
+	VersionComponentQEMU   = "qemu"
+	VersionComponentCRIU   = "criu"
+)
+
+// CheckLiveMigrationVersions checks whether an instance of the given type can be safely live-migrated between two
+// members with the given component versions. It returns an error for combinations known to be unsafe, and warnings
+// for differences that may cause the migration to fail.
+func CheckLiveMigrationVersions(instanceType instancetype.Type, source map[string]string, target map[string]string) ([]string, error) {
+	warnings := []string{}
+
+	// Compares the major and minor versions of a component on both members. Returns false if any is unknown.
+	compare := func(component string) (int, bool) {
+		sourceVersion, err := version.Parse(source[component])
+		if err != nil {
+			return 0, false
+		}
+
+		targetVersion, err := version.Parse(target[component])
+		if err != nil {
+			return 0, false
+		}
+
+		sourceVersion.Patch = -1
+		targetVersion.Patch = -1
+
+		return targetVersion.Compare(sourceVersion), true
+	}
+
+	switch instanceType {
+	case instancetype.VM:
+		// The machine type of running VMs may not exist in older QEMU versions, and their migration stream isn't
+		// guaranteed to be understood by them.
+		result, ok := compare(VersionComponentQEMU)
+		if !ok {
+			warnings = append(warnings, "Unknown QEMU version on one of the members")
+		} else if result < 0 {
+			return warnings, fmt.Errorf("Cannot live-migrate from QEMU %s to older QEMU %s", source[VersionComponentQEMU], target[VersionComponentQEMU])
+		}
+
+	case instancetype.Container:
+		// CRIU images are only guaranteed to be restorable by the same CRIU version.
+		result, ok := compare(VersionComponentCRIU)
+		if ok && result != 0 {
+			return warnings, fmt.Errorf("Cannot live-migrate between CRIU %s and CRIU %s", source[VersionComponentCRIU], target[VersionComponentCRIU])
+		}
+
+		result, ok = compare(VersionComponentKernel)
+		if ok && result != 0 {
+			warnings = append(warnings, fmt.Sprintf("Kernel versions differ (%s and %s), restoring the instance may fail", source[VersionComponentKernel], target[VersionComponentKernel]))
+		}
+
+		result, ok = compare(VersionComponentLXC)
+		if ok && result != 0 {
+			warnings = append(warnings, fmt.Sprintf("LXC versions differ (%s and %s)", source[VersionComponentLXC], target[VersionComponentLXC]))
+		}
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,75 @@
+package cluster_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+)
+
+func TestCheckLiveMigrationVersions(t *testing.T) {
+	tests := []struct {
+		name         string
+		instanceType instancetype.Type
+		source       map[string]string
+		target       map[string]string
+		warnings     int
+		err          bool
+	}{
+		{
+			name:         "VM to newer QEMU",
+			instanceType: instancetype.VM,
+			source:       map[string]string{"qemu": "8.0.4"},
+			target:       map[string]string{"qemu": "8.2.2"},
+		},
+		{
+			name:         "VM to older QEMU",
+			instanceType: instancetype.VM,
+			source:       map[string]string{"qemu": "8.2.2"},
+			target:       map[string]string{"qemu": "8.0.4"},
+			err:          true,
+		},
+		{
+			name:         "VM to QEMU with different patch version",
+			instanceType: instancetype.VM,
+			source:       map[string]string{"qemu": "8.2.2"},
+			target:       map[string]string{"qemu": "8.2.1"},
+		},
+		{
+			name:         "VM with unknown QEMU version",
+			instanceType: instancetype.VM,
+			source:       map[string]string{"qemu": "8.2.2"},
+			target:       map[string]string{},
+			warnings:     1,
+		},
+		{
+			name:         "Container with different CRIU versions",
+			instanceType: instancetype.Container,
+			source:       map[string]string{"criu": "3.17.1"},
+			target:       map[string]string{"criu": "3.19"},
+			err:          true,
+		},
+		{
+			name:         "Container with different kernel and LXC versions",
+			instanceType: instancetype.Container,
+			source:       map[string]string{"criu": "3.17.1", "kernel": "6.8.0-35-generic", "lxc": "5.0.3"},
+			target:       map[string]string{"criu": "3.17.1", "kernel": "5.15.0-91-generic", "lxc": "6.0.0"},
+			warnings:     2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			warnings, err := cluster.CheckLiveMigrationVersions(test.instanceType, test.source, test.target)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Len(t, warnings, test.warnings)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+	"github.com/canonical/lxd/shared/ws"
+)
+
+// The daemon log can reveal details of all projects, so it requires the same entitlement as logging events.
+var clusterMemberLogCmd = APIEndpoint{
+	Path: "cluster/members/{name}/log",
+
+	Get: APIEndpointAction{Handler: clusterMemberLogGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewPrivilegedEvents)},
+}
+
+var clusterMemberLogStreamCmd = APIEndpoint{
+	Path: "cluster/members/{name}/log/stream",
+
+	Get: APIEndpointAction{Handler: clusterMemberLogStreamGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewPrivilegedEvents)},
+}
+
+// clusterMemberLogPath returns the path of the log file of the local daemon.
+func clusterMemberLogPath(d *Daemon) (string, error) {
+	if d.config.LogFile == "" {
+		return "", api.StatusErrorf(http.StatusNotFound, "Cluster member %q doesn't log to a file", d.serverName)
+	}
+
+	return d.config.LogFile, nil
+}
+
+// swagger:operation GET /1.0/cluster/members/{name}/log cluster cluster_member_log_get
+//
+//	Get the daemon log of the cluster member
+//
+//	Gets the log file of the LXD daemon running on the cluster member.
+//
+//	---
+//	produces:
+//	  - application/json
+//	  - application/octet-stream
+//	responses:
+//	  "200":
+//	     description: Raw file
+//	     content:
+//	       application/octet-stream:
+//	         schema:
+//	           type: string
+//	           example: some-text
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterMemberLogGet(d *Daemon, r *http.Request) response.Response {
+	memberName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Forward request.
+	resp := forwardedResponseToNode(d.State(), r, memberName)
+	if resp != nil {
+		return resp
+	}
+
+	path, err := clusterMemberLogPath(d)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	ent := response.FileResponseEntry{
+		Path:     path,
+		Filename: "lxd.log",
+	}
+
+	return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
+}
+
+// swagger:operation GET /1.0/cluster/members/{name}/log/stream cluster cluster_member_log_stream_get
+//
+//	Stream the daemon log of the cluster member
+//
+//	Upgrades the connection to a websocket on which the lines of the log file of the LXD daemon running on the
+//	cluster member are sent as JSON messages.
+//	Without follow mode, the websocket is closed once the past lines have been sent.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: lines
+//	    description: Number of past lines to send
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: follow
+//	    description: Whether to keep sending new lines as they are logged
+//	    type: boolean
+//	    example: true
+//	responses:
+//	  "101":
+//	    description: Switching protocols to websocket
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterMemberLogStreamGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	memberName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	lines := 100
+	linesStr := r.FormValue("lines")
+	if linesStr != "" {
+		lines, err = strconv.Atoi(linesStr)
+		if err != nil || lines < 0 {
+			return response.BadRequest(fmt.Errorf("Invalid number of lines %q", linesStr))
+		}
+	}
+
+	follow := shared.IsTrue(r.FormValue("follow"))
+
+	// Forward the websocket if the member is remote.
+	address, err := cluster.ResolveTarget(r.Context(), s, memberName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if address != "" {
+		client, err := cluster.Connect(address, s.Endpoints.NetworkCert(), s.ServerCert(), r, false)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		source, err := client.RawWebsocket(strings.TrimPrefix(r.URL.RequestURI(), "/"+version.APIVersion))
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.ManualResponse(func(w http.ResponseWriter) error {
+			defer func() { _ = source.Close() }()
+
+			target, err := ws.Upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = target.Close() }()
+
+			<-ws.Proxy(source, target)
+
+			return nil
+		})
+	}
+
+	path, err := clusterMemberLogPath(d)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		conn, err := ws.Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// Stop streaming when the client goes away.
+		go func() {
+			for {
+				_, _, err := conn.NextReader()
+				if err != nil {
+					cancel()
+					return
+				}
+			}
+		}()
+
+		var writeLock sync.Mutex
+		send := func(record api.ClusterMemberLogRecord) error {
+			writeLock.Lock()
+			defer writeLock.Unlock()
+
+			return conn.WriteJSON(record)
+		}
+
+		err = instanceLogsStreamFile(ctx, path, lines, follow, func(messages []string) error {
+			for _, message := range messages {
+				err := send(api.ClusterMemberLogRecord{Timestamp: time.Now(), Message: message})
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil && ctx.Err() == nil {
+			logger.Debug("Failed streaming daemon log", logger.Ctx{"err": err})
+			_ = send(api.ClusterMemberLogRecord{Timestamp: time.Now(), Error: err.Error()})
+		}
+
+		writeLock.Lock()
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		writeLock.Unlock()
+
+		return nil
+	})
+}
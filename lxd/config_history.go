@@ -0,0 +1,1365 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	clusterRequest "github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var instanceHistoryCmd = APIEndpoint{
+	Name: "instanceHistory",
+	Path: "instances/{name}/history",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerHistory", Path: "containers/{name}/history"},
+		{Name: "vmHistory", Path: "virtual-machines/{name}/history"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceHistoryGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+}
+
+var instanceHistoryRevisionCmd = APIEndpoint{
+	Name: "instanceHistoryRevision",
+	Path: "instances/{name}/history/{revision}",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerHistoryRevision", Path: "containers/{name}/history/{revision}"},
+		{Name: "vmHistoryRevision", Path: "virtual-machines/{name}/history/{revision}"},
+	},
+
+	Get:  APIEndpointAction{Handler: instanceHistoryRevisionGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+	Post: APIEndpointAction{Handler: instanceHistoryRevisionPost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
+var profileHistoryCmd = APIEndpoint{
+	Path: "profiles/{name}/history",
+
+	Get: APIEndpointAction{Handler: profileHistoryGet, AccessHandler: allowPermission(entity.TypeProfile, auth.EntitlementCanView, "name")},
+}
+
+var profileHistoryRevisionCmd = APIEndpoint{
+	Path: "profiles/{name}/history/{revision}",
+
+	Get:  APIEndpointAction{Handler: profileHistoryRevisionGet, AccessHandler: allowPermission(entity.TypeProfile, auth.EntitlementCanView, "name")},
+	Post: APIEndpointAction{Handler: profileHistoryRevisionPost, AccessHandler: allowPermission(entity.TypeProfile, auth.EntitlementCanEdit, "name")},
+}
+
+var networkHistoryCmd = APIEndpoint{
+	Path: "networks/{networkName}/history",
+
+	Get: APIEndpointAction{Handler: networkHistoryGet, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanView, "networkName")},
+}
+
+var networkHistoryRevisionCmd = APIEndpoint{
+	Path: "networks/{networkName}/history/{revision}",
+
+	Get:  APIEndpointAction{Handler: networkHistoryRevisionGet, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanView, "networkName")},
+	Post: APIEndpointAction{Handler: networkHistoryRevisionPost, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanEdit, "networkName")},
+}
+
+var storagePoolHistoryCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/history",
+
+	Get: APIEndpointAction{Handler: storagePoolHistoryGet, AccessHandler: allowPermission(entity.TypeStoragePool, auth.EntitlementCanView, "poolName")},
+}
+
+var storagePoolHistoryRevisionCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/history/{revision}",
+
+	Get:  APIEndpointAction{Handler: storagePoolHistoryRevisionGet, AccessHandler: allowPermission(entity.TypeStoragePool, auth.EntitlementCanView, "poolName")},
+	Post: APIEndpointAction{Handler: storagePoolHistoryRevisionPost, AccessHandler: allowPermission(entity.TypeStoragePool, auth.EntitlementCanEdit, "poolName")},
+}
+
+// configRevisionEqual returns whether two revisions hold the same configuration.
+func configRevisionEqual(a api.ConfigRevision, b api.ConfigRevision) bool {
+	if a.Description != b.Description || !maps.Equal(a.Config, b.Config) || !slices.Equal(a.Profiles, b.Profiles) {
+		return false
+	}
+
+	return maps.EqualFunc(a.Devices, b.Devices, func(x map[string]string, y map[string]string) bool {
+		return maps.Equal(x, y)
+	})
+}
+
+// configHistoryRecord records newRevision as the latest configuration revision of the given entity.
+// If no revision has been recorded for the entity yet, oldRevision is recorded first so that the entity can be
+// rolled back to the configuration it had before its first recorded change.
+// Nothing is recorded if the configuration didn't change or if configuration history is disabled.
+func configHistoryRecord(s *state.State, r *http.Request, entityType entity.Type, entityID int, oldRevision api.ConfigRevision, newRevision api.ConfigRevision) error {
+	maxRevisions := s.GlobalConfig.ConfigHistoryRevisions()
+	if maxRevisions <= 0 || configRevisionEqual(oldRevision, newRevision) {
+		return nil
+	}
+
+	newRevision.Requestor = request.CreateRequestor(r)
+
+	return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbEntityType := dbCluster.EntityType(entityType)
+
+		exists, err := dbCluster.ConfigRevisionsExist(ctx, tx.Tx(), dbEntityType, entityID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			err = dbCluster.CreateConfigRevisionFromAPI(ctx, tx.Tx(), dbEntityType, entityID, oldRevision, maxRevisions)
+			if err != nil {
+				return err
+			}
+		}
+
+		return dbCluster.CreateConfigRevisionFromAPI(ctx, tx.Tx(), dbEntityType, entityID, newRevision, maxRevisions)
+	})
+}
+
+// configHistoryRecordOrWarn calls configHistoryRecord and logs a warning on failure.
+// Failing to record a revision never fails the configuration change that triggered it.
+func configHistoryRecordOrWarn(s *state.State, r *http.Request, entityType entity.Type, entityID int, oldRevision api.ConfigRevision, newRevision api.ConfigRevision) {
+	err := configHistoryRecord(s, r, entityType, entityID, oldRevision, newRevision)
+	if err != nil {
+		logger.Warn("Failed recording configuration revision", logger.Ctx{"entityType": entityType, "entityID": entityID, "err": err})
+	}
+}
+
+// configHistoryList returns the response listing the recorded revisions of the given entity.
+// The entityPath is the API path of the entity, e.g. "profiles", "default".
+func configHistoryList(s *state.State, r *http.Request, entityType entity.Type, entityID int, projectName string, entityPath ...string) response.Response {
+	var revisions []dbCluster.ConfigRevision
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		dbEntityType := dbCluster.EntityType(entityType)
+		revisions, err = dbCluster.GetConfigRevisions(ctx, tx.Tx(), dbCluster.ConfigRevisionFilter{EntityType: &dbEntityType, EntityID: &entityID})
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !util.IsRecursionRequest(r) {
+		urls := make([]string, 0, len(revisions))
+		for _, revision := range revisions {
+			pathParts := append([]string{version.APIVersion}, entityPath...)
+			pathParts = append(pathParts, "history", strconv.Itoa(revision.ID))
+			urls = append(urls, api.NewURL().Path(pathParts...).Project(projectName).String())
+		}
+
+		return response.SyncResponse(true, urls)
+	}
+
+	result := make([]api.ConfigRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		apiRevision, err := revision.ToAPI()
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		result = append(result, *apiRevision)
+	}
+
+	return response.SyncResponse(true, result)
+}
+
+// configHistoryLoad returns the requested revision of the given entity.
+func configHistoryLoad(ctx context.Context, s *state.State, r *http.Request, entityType entity.Type, entityID int) (*api.ConfigRevision, error) {
+	revisionID, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid revision")
+	}
+
+	var revision *api.ConfigRevision
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbRevision, err := dbCluster.GetConfigRevision(ctx, tx.Tx(), dbCluster.EntityType(entityType), entityID, revisionID)
+		if err != nil {
+			return err
+		}
+
+		revision, err = dbRevision.ToAPI()
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// instanceConfigRevision returns the current configuration of an instance as a revision.
+// Volatile keys are excluded as they are managed by LXD itself.
+func instanceConfigRevision(inst instance.Instance) api.ConfigRevision {
+	config := map[string]string{}
+	for k, v := range inst.LocalConfig() {
+		if strings.HasPrefix(k, instancetype.ConfigVolatilePrefix) {
+			continue
+		}
+
+		config[k] = v
+	}
+
+	profiles := make([]string, 0, len(inst.Profiles()))
+	for _, profile := range inst.Profiles() {
+		profiles = append(profiles, profile.Name)
+	}
+
+	return api.ConfigRevision{
+		Description: inst.Description(),
+		Config:      config,
+		Devices:     inst.LocalDevices().CloneNative(),
+		Profiles:    profiles,
+	}
+}
+
+// profileConfigRevision returns the configuration of a profile as a revision.
+func profileConfigRevision(profile api.ProfilePut) api.ConfigRevision {
+	return api.ConfigRevision{
+		Description: profile.Description,
+		Config:      util.CopyConfig(profile.Config),
+		Devices:     deviceConfig.NewDevices(profile.Devices).CloneNative(),
+	}
+}
+
+// networkConfigRevision returns the current configuration of a network as a revision.
+// When clustered, member specific keys are excluded as they aren't part of the global configuration.
+func networkConfigRevision(s *state.State, n network.Network) api.ConfigRevision {
+	config := util.CopyConfig(n.Config())
+	if s.ServerClustered {
+		for _, key := range db.NodeSpecificNetworkConfig {
+			delete(config, key)
+		}
+	}
+
+	return api.ConfigRevision{
+		Description: n.Description(),
+		Config:      config,
+	}
+}
+
+// storagePoolConfigRevision returns the current configuration of a storage pool as a revision.
+// When clustered, member specific keys are excluded as they aren't part of the global configuration.
+func storagePoolConfigRevision(s *state.State, pool storagePools.Pool) api.ConfigRevision {
+	config := util.CopyConfig(pool.Driver().Config())
+	if s.ServerClustered {
+		for _, key := range db.NodeSpecificStorageConfig {
+			delete(config, key)
+		}
+	}
+
+	return api.ConfigRevision{
+		Description: pool.Description(),
+		Config:      config,
+	}
+}
+
+// networkConfigHistoryRecord records the current global configuration of a network if it differs from oldRevision.
+func networkConfigHistoryRecord(s *state.State, r *http.Request, projectName string, networkName string, oldRevision api.ConfigRevision) {
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		logger.Warn("Failed loading network to record configuration revision", logger.Ctx{"project": projectName, "network": networkName, "err": err})
+		return
+	}
+
+	configHistoryRecordOrWarn(s, r, entity.TypeNetwork, int(n.ID()), oldRevision, networkConfigRevision(s, n))
+}
+
+// storagePoolConfigHistoryRecord records the current global configuration of a storage pool if it differs from
+// oldRevision.
+func storagePoolConfigHistoryRecord(s *state.State, r *http.Request, poolName string, oldRevision api.ConfigRevision) {
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		logger.Warn("Failed loading storage pool to record configuration revision", logger.Ctx{"pool": poolName, "err": err})
+		return
+	}
+
+	configHistoryRecordOrWarn(s, r, entity.TypeStoragePool, int(pool.ID()), oldRevision, storagePoolConfigRevision(s, pool))
+}
+
+// swagger:operation GET /1.0/instances/{name}/history instances instance_history_get
+//
+//	Get the configuration revisions
+//
+//	Returns a list of recorded configuration revisions of the instance (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instances/{name}/history?recursion=1 instances instance_history_get_recursion1
+//
+//	Get the configuration revisions
+//
+//	Returns a list of recorded configuration revisions of the instance (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of configuration revisions
+//	          items:
+//	            $ref: "#/definitions/ConfigRevision"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceHistoryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	var id int64
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, err = dbCluster.GetInstanceID(ctx, tx.Tx(), projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return configHistoryList(s, r, entity.TypeInstance, int(id), projectName, "instances", name)
+}
+
+// swagger:operation GET /1.0/instances/{name}/history/{revision} instances instance_history_revision_get
+//
+//	Get the configuration revision
+//
+//	Gets a specific configuration revision of the instance.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Configuration revision
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ConfigRevision"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceHistoryRevisionGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	var id int64
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, err = dbCluster.GetInstanceID(ctx, tx.Tx(), projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	revision, err := configHistoryLoad(r.Context(), s, r, entity.TypeInstance, int(id))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, revision)
+}
+
+// swagger:operation POST /1.0/instances/{name}/history/{revision} instances instance_history_revision_post
+//
+//	Roll back to the configuration revision
+//
+//	Restores the configuration, devices and profiles of the instance from the revision.
+//	Volatile configuration keys are left untouched.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceHistoryRevisionPost(d *Daemon, r *http.Request) response.Response {
+	// Don't mess with instance while in setup mode.
+	<-d.waitReady.Done()
+
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	revision, err := configHistoryLoad(r.Context(), s, r, entity.TypeInstance, inst.ID())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Keep the volatile keys as they are managed by LXD.
+	config := util.CopyConfig(revision.Config)
+	for k, v := range inst.LocalConfig() {
+		if strings.HasPrefix(k, instancetype.ConfigVolatilePrefix) {
+			config[k] = v
+		}
+	}
+
+	req := api.InstancePut{
+		Config:      config,
+		Devices:     revision.Devices,
+		Ephemeral:   inst.IsEphemeral(),
+		Profiles:    revision.Profiles,
+		Description: revision.Description,
+	}
+
+	// Check project limits.
+	apiProfiles := make([]api.Profile, 0, len(req.Profiles))
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		profiles, err := dbCluster.GetProfilesIfEnabled(ctx, tx.Tx(), projectName, req.Profiles)
+		if err != nil {
+			return err
+		}
+
+		for _, profile := range profiles {
+			apiProfile, err := profile.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			apiProfiles = append(apiProfiles, *apiProfile)
+		}
+
+		return project.AllowInstanceUpdate(s.GlobalConfig, tx, projectName, name, req, inst.LocalConfig())
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if len(apiProfiles) != len(req.Profiles) {
+		return response.BadRequest(fmt.Errorf("Some of the profiles of revision %d don't exist anymore", revision.Revision))
+	}
+
+	do := func(op *operations.Operation) error {
+		unlock, err := instanceOperationLock(s.ShutdownCtx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		defer unlock()
+
+		oldRevision := instanceConfigRevision(inst)
+
+		args := db.InstanceArgs{
+			Architecture: inst.Architecture(),
+			Config:       req.Config,
+			Description:  req.Description,
+			Devices:      deviceConfig.NewDevices(req.Devices),
+			Ephemeral:    req.Ephemeral,
+			Profiles:     apiProfiles,
+			Project:      projectName,
+		}
+
+		err = inst.Update(args, true)
+		if err != nil {
+			return err
+		}
+
+		configHistoryRecordOrWarn(s, r, entity.TypeInstance, inst.ID(), oldRevision, instanceConfigRevision(inst))
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name)}
+
+	if inst.Type() == instancetype.Container {
+		resources["containers"] = resources["instances"]
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceUpdate, resources, nil, do, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// profileHistoryEntity returns the project and ID of the profile referenced by the request.
+func profileHistoryEntity(s *state.State, r *http.Request) (*api.Project, string, int64, error) {
+	p, err := project.ProfileProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return nil, "", -1, err
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return nil, "", -1, err
+	}
+
+	var id int64
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, err = dbCluster.GetProfileID(ctx, tx.Tx(), p.Name, name)
+
+		return err
+	})
+	if err != nil {
+		return nil, "", -1, err
+	}
+
+	return p, name, id, nil
+}
+
+// swagger:operation GET /1.0/profiles/{name}/history profiles profile_history_get
+//
+//	Get the configuration revisions
+//
+//	Returns a list of recorded configuration revisions of the profile (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/profiles/{name}/history?recursion=1 profiles profile_history_get_recursion1
+//
+//	Get the configuration revisions
+//
+//	Returns a list of recorded configuration revisions of the profile (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of configuration revisions
+//	          items:
+//	            $ref: "#/definitions/ConfigRevision"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func profileHistoryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	p, name, id, err := profileHistoryEntity(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return configHistoryList(s, r, entity.TypeProfile, int(id), p.Name, "profiles", name)
+}
+
+// swagger:operation GET /1.0/profiles/{name}/history/{revision} profiles profile_history_revision_get
+//
+//	Get the configuration revision
+//
+//	Gets a specific configuration revision of the profile.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Configuration revision
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ConfigRevision"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func profileHistoryRevisionGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	_, _, id, err := profileHistoryEntity(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	revision, err := configHistoryLoad(r.Context(), s, r, entity.TypeProfile, int(id))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, revision)
+}
+
+// swagger:operation POST /1.0/profiles/{name}/history/{revision} profiles profile_history_revision_post
+//
+//	Roll back to the configuration revision
+//
+//	Restores the configuration and devices of the profile from the revision.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func profileHistoryRevisionPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	p, name, id, err := profileHistoryEntity(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	revision, err := configHistoryLoad(r.Context(), s, r, entity.TypeProfile, int(id))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var profile *api.Profile
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		current, err := dbCluster.GetProfile(ctx, tx.Tx(), p.Name, name)
+		if err != nil {
+			return err
+		}
+
+		profile, err = current.ToAPI(ctx, tx.Tx())
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.ProfilePut{
+		Description: revision.Description,
+		Config:      revision.Config,
+		Devices:     revision.Devices,
+	}
+
+	err = doProfileUpdate(s, *p, name, id, profile, req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Notify all other members. If a member is down, it will be ignored.
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = notifier(func(client lxd.InstanceServer) error {
+		return client.UseProject(p.Name).UpdateProfile(name, profile.Writable(), "")
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	configHistoryRecordOrWarn(s, r, entity.TypeProfile, int(id), profileConfigRevision(profile.Writable()), profileConfigRevision(req))
+
+	requestor := request.CreateRequestor(r)
+	s.Events.SendLifecycle(p.Name, lifecycle.ProfileUpdated.Event(name, p.Name, requestor, nil))
+
+	return response.EmptySyncResponse
+}
+
+// networkHistoryEntity returns the network referenced by the request.
+func networkHistoryEntity(s *state.State, r *http.Request) (network.Network, error) {
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return nil, err
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network: %w", err)
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Network not found")
+	}
+
+	return n, nil
+}
+
+// swagger:operation GET /1.0/networks/{name}/history networks network_history_get
+//
+//	Get the configuration revisions
+//
+//	Returns a list of recorded configuration revisions of the network (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/networks/{name}/history?recursion=1 networks network_history_get_recursion1
+//
+//	Get the configuration revisions
+//
+//	Returns a list of recorded configuration revisions of the network (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of configuration revisions
+//	          items:
+//	            $ref: "#/definitions/ConfigRevision"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkHistoryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	n, err := networkHistoryEntity(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return configHistoryList(s, r, entity.TypeNetwork, int(n.ID()), n.Project(), "networks", n.Name())
+}
+
+// swagger:operation GET /1.0/networks/{name}/history/{revision} networks network_history_revision_get
+//
+//	Get the configuration revision
+//
+//	Gets a specific configuration revision of the network.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Configuration revision
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ConfigRevision"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkHistoryRevisionGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	n, err := networkHistoryEntity(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	revision, err := configHistoryLoad(r.Context(), s, r, entity.TypeNetwork, int(n.ID()))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, revision)
+}
+
+// swagger:operation POST /1.0/networks/{name}/history/{revision} networks network_history_revision_post
+//
+//	Roll back to the configuration revision
+//
+//	Restores the global configuration of the network from the revision.
+//	Cluster member specific configuration keys are left untouched.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkHistoryRevisionPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	n, err := networkHistoryEntity(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if n.Status() != api.NetworkStatusCreated {
+		return response.BadRequest(fmt.Errorf("Cannot update network global config when not in created state"))
+	}
+
+	revision, err := configHistoryLoad(r.Context(), s, r, entity.TypeNetwork, int(n.ID()))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	oldRevision := networkConfigRevision(s, n)
+
+	req := api.NetworkPut{
+		Description: revision.Description,
+		Config:      util.CopyConfig(revision.Config),
+	}
+
+	resp := doNetworkUpdate(n.Project(), n, req, "", clusterRequest.ClientTypeNormal, http.MethodPut, s.ServerClustered)
+
+	networkConfigHistoryRecord(s, r, n.Project(), n.Name(), oldRevision)
+
+	requestor := request.CreateRequestor(r)
+	s.Events.SendLifecycle(n.Project(), lifecycle.NetworkUpdated.Event(n, requestor, nil))
+
+	return resp
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/history storage storage_pool_history_get
+//
+//	Get the configuration revisions
+//
+//	Returns a list of recorded configuration revisions of the storage pool (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/history?recursion=1 storage storage_pool_history_get_recursion1
+//
+//	Get the configuration revisions
+//
+//	Returns a list of recorded configuration revisions of the storage pool (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of configuration revisions
+//	          items:
+//	            $ref: "#/definitions/ConfigRevision"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolHistoryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return configHistoryList(s, r, entity.TypeStoragePool, int(pool.ID()), "", "storage-pools", pool.Name())
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/history/{revision} storage storage_pool_history_revision_get
+//
+//	Get the configuration revision
+//
+//	Gets a specific configuration revision of the storage pool.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Configuration revision
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ConfigRevision"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolHistoryRevisionGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	revision, err := configHistoryLoad(r.Context(), s, r, entity.TypeStoragePool, int(pool.ID()))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, revision)
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/history/{revision} storage storage_pool_history_revision_post
+//
+//	Roll back to the configuration revision
+//
+//	Restores the global configuration of the storage pool from the revision.
+//	Cluster member specific configuration keys are left untouched.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolHistoryRevisionPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if pool.Status() != api.StoragePoolStatusCreated {
+		return response.BadRequest(fmt.Errorf("Cannot update storage pool global config when not in created state"))
+	}
+
+	revision, err := configHistoryLoad(r.Context(), s, r, entity.TypeStoragePool, int(pool.ID()))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	oldRevision := storagePoolConfigRevision(s, pool)
+
+	req := api.StoragePoolPut{
+		Description: revision.Description,
+		Config:      util.CopyConfig(revision.Config),
+	}
+
+	resp := doStoragePoolUpdate(s, pool, req, "", clusterRequest.ClientTypeNormal, http.MethodPut, s.ServerClustered)
+
+	storagePoolConfigHistoryRecord(s, r, pool.Name(), oldRevision)
+
+	requestor := request.CreateRequestor(r)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.StoragePoolUpdated.Event(pool.Name(), requestor, nil))
+
+	return resp
+}
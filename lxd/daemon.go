@@ -42,6 +42,7 @@ import (
 	"github.com/canonical/lxd/lxd/dns"
 	"github.com/canonical/lxd/lxd/endpoints"
 	"github.com/canonical/lxd/lxd/events"
+	"github.com/canonical/lxd/lxd/fault"
 	"github.com/canonical/lxd/lxd/firewall"
 	"github.com/canonical/lxd/lxd/fsmonitor"
 	"github.com/canonical/lxd/lxd/identity"
@@ -49,9 +50,12 @@ import (
 	"github.com/canonical/lxd/lxd/instance"
 	instanceDrivers "github.com/canonical/lxd/lxd/instance/drivers"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/instance/usage"
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/loki"
 	"github.com/canonical/lxd/lxd/maas"
+	"github.com/canonical/lxd/lxd/nats"
+	"github.com/canonical/lxd/lxd/network/externaldns"
 	networkZone "github.com/canonical/lxd/lxd/network/zone"
 	"github.com/canonical/lxd/lxd/node"
 	"github.com/canonical/lxd/lxd/request"
@@ -91,6 +95,7 @@ type Daemon struct {
 	devlxdEvents     *events.DevLXDServer
 	events           *events.Server
 	internalListener *events.InternalListener
+	clusterListener  *events.InternalListener
 
 	// Tasks registry for long-running background tasks
 	// Keep clustering tasks separate as they cause a lot of CPU wakeups
@@ -98,8 +103,10 @@ type Daemon struct {
 	clusterTasks *task.Group
 
 	// Indexes of tasks that need to be reset when their execution interval changes
-	taskPruneImages      *task.Task
-	taskClusterHeartbeat *task.Task
+	taskPruneImages         *task.Task
+	taskClusterHeartbeat    *task.Task
+	taskInstanceUsageSample *task.Task
+	taskDatabaseSnapshots   *task.Task
 
 	// Stores startup time of daemon
 	startTime time.Time
@@ -152,6 +159,7 @@ type Daemon struct {
 	serverUUID string
 
 	lokiClient *loki.Client
+	natsClient *nats.Client
 
 	// HTTP-01 challenge provider for ACME
 	http01Provider acme.HTTP01Provider
@@ -161,6 +169,15 @@ type Daemon struct {
 
 	// Syslog listener cancel function.
 	syslogSocketCancel context.CancelFunc
+
+	// In-memory history of the resource usage of local instances.
+	instanceUsage *usage.Store
+
+	// Instance records published to external DNS providers, keyed by "<project>/<network>".
+	externalDNSRecords map[string]map[string]externaldns.Record
+
+	// Progress of the patches applied since the daemon started.
+	patchesStatus *patchesStatus
 }
 
 // DaemonConfig holds configuration values for Daemon.
@@ -169,6 +186,8 @@ type DaemonConfig struct {
 	Trace              []string      // List of sub-systems to trace
 	RaftLatency        float64       // Coarse grain measure of the cluster latency
 	DqliteSetupTimeout time.Duration // How long to wait for the cluster database to be up
+	SkipPatches        []string      // Patches to mark as applied without running them
+	LogFile            string        // Path to the log file of the daemon, if any
 }
 
 // newDaemon returns a new Daemon object with the given configuration.
@@ -178,20 +197,23 @@ func newDaemon(config *DaemonConfig, os *sys.OS) *Daemon {
 	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
 
 	d := &Daemon{
-		identityCache:  &identity.Cache{},
-		config:         config,
-		devlxdEvents:   devlxdEvents,
-		events:         lxdEvents,
-		tasks:          task.NewGroup(),
-		clusterTasks:   task.NewGroup(),
-		db:             &db.DB{},
-		http01Provider: acme.NewHTTP01Provider(),
-		os:             os,
-		setupChan:      make(chan struct{}),
-		waitReady:      cancel.New(context.Background()),
-		shutdownCtx:    shutdownCtx,
-		shutdownCancel: shutdownCancel,
-		shutdownDoneCh: make(chan error),
+		identityCache:      &identity.Cache{},
+		config:             config,
+		devlxdEvents:       devlxdEvents,
+		events:             lxdEvents,
+		tasks:              task.NewGroup(),
+		clusterTasks:       task.NewGroup(),
+		db:                 &db.DB{},
+		http01Provider:     acme.NewHTTP01Provider(),
+		os:                 os,
+		setupChan:          make(chan struct{}),
+		waitReady:          cancel.New(context.Background()),
+		shutdownCtx:        shutdownCtx,
+		shutdownCancel:     shutdownCancel,
+		shutdownDoneCh:     make(chan error),
+		instanceUsage:      usage.NewStore(),
+		patchesStatus:      &patchesStatus{},
+		externalDNSRecords: map[string]map[string]externaldns.Record{},
 	}
 
 	d.serverCert = func() *shared.CertInfo { return d.serverCertInt }
@@ -371,7 +393,7 @@ func (d *Daemon) Authenticate(w http.ResponseWriter, r *http.Request) (trusted b
 	trustCACertificates := d.globalConfig.TrustCACertificates()
 
 	// Validate metrics certificates.
-	if r.URL.Path == "/1.0/metrics" {
+	if r.URL.Path == "/1.0/metrics" || isProjectMetricsPath(r.URL.Path) {
 		for _, i := range r.TLS.PeerCertificates {
 			trusted, username := util.CheckTrustState(*i, d.identityCache.X509Certificates(api.IdentityTypeCertificateMetricsRestricted, api.IdentityTypeCertificateMetricsUnrestricted), d.endpoints.NetworkCert(), trustCACertificates)
 			if trusted {
@@ -544,12 +566,16 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 		if !(r.RemoteAddr == "@" && version == "internal") {
 			// Block public API requests until we're done with basic
 			// initialization tasks, such setting up the cluster database.
+			// The metrics endpoint is allowed earlier so that members waiting on a patch can be alerted on, see
+			// metricsResponse.
 			select {
 			case <-d.setupChan:
 			default:
-				response := response.Unavailable(fmt.Errorf("LXD daemon setup in progress"))
-				_ = response.Render(w)
-				return
+				if version != "1.0" || c.Path != "metrics" || !d.patchesStatus.metricsEnabled() {
+					response := response.Unavailable(fmt.Errorf("LXD daemon setup in progress"))
+					_ = response.Render(w)
+					return
+				}
 			}
 		}
 
@@ -839,6 +865,43 @@ func (d *Daemon) setupLoki(URL string, cert string, key string, caCert string, i
 	return nil
 }
 
+func (d *Daemon) setupNATS(URL string, username string, password string, token string, caCert string, subject string, types []string) error {
+	// Stop any existing NATS client.
+	if d.natsClient != nil {
+		d.natsClient.Stop()
+		d.natsClient = nil
+	}
+
+	// Check basic requirements for starting a new client.
+	if URL == "" || len(types) == 0 {
+		d.internalListener.RemoveHandler("nats")
+		return nil
+	}
+
+	// Validate the URL.
+	u, err := url.Parse(URL)
+	if err != nil {
+		return err
+	}
+
+	// Handle standalone systems.
+	var location string
+	if !d.serverClustered {
+		location, err = os.Hostname()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Start a new client.
+	d.natsClient = nats.NewClient(d.shutdownCtx, u, username, password, token, caCert, subject, types, location)
+
+	// Attach the new client to the event handler.
+	d.internalListener.AddHandler("nats", d.natsClient.HandleEvent)
+
+	return nil
+}
+
 func (d *Daemon) init() error {
 	var err error
 
@@ -855,6 +918,7 @@ func (d *Daemon) init() error {
 
 	// Setup internal event listener
 	d.internalListener = events.NewInternalListener(d.shutdownCtx, d.events)
+	d.internalListener.AddHandler("instance-commands", instanceCommandsBootHandler(d))
 
 	// Lets check if there's an existing LXD running
 	err = endpoints.CheckAlreadyRunning(d.UnixSocket())
@@ -923,6 +987,7 @@ func (d *Daemon) init() error {
 		"seccomp_proxy_send_notify_fd",
 		"idmapped_mounts_v2",
 		"core_scheduling",
+		"time_namespace",
 	}
 
 	for _, extension := range lxcExtensions {
@@ -967,6 +1032,17 @@ func (d *Daemon) init() error {
 		logger.Info(" - core scheduling: no")
 	}
 
+	if canUseTimeNamespace() {
+		d.os.TimeNamespace = true
+		logger.Info(" - time namespaces: yes")
+
+		if d.os.LXCFeatures["time_namespace"] {
+			d.os.ContainerTimeNamespace = true
+		}
+	} else {
+		logger.Info(" - time namespaces: no")
+	}
+
 	d.os.UeventInjection = canUseUeventInjection()
 	if d.os.UeventInjection {
 		logger.Info(" - uevent injection: yes")
@@ -1049,6 +1125,10 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	if fault.Enabled() {
+		logger.Warn("Fault injection is enabled, this must never be used on production systems")
+	}
+
 	// Validate the devices storage.
 	testDev := shared.VarPath("devices", ".test")
 	testDevNum := int(unix.Mkdev(0, 0))
@@ -1128,6 +1208,7 @@ func (d *Daemon) init() error {
 	}
 
 	d.gateway.HeartbeatNodeHook = d.nodeRefreshTask
+	d.gateway.PatchesNotifyHook = d.patchesStatus.wake
 
 	/* Setup some mounts (nice to have) */
 	if !d.os.MockMode {
@@ -1177,6 +1258,8 @@ func (d *Daemon) init() error {
 		DebugAddress:         debugAddress,
 		MetricsServer:        metricsServer(d),
 		StorageBucketsServer: storageBucketsServer(d),
+		EventsServer:         eventsServer(d),
+		MigrationServer:      migrationServer(d),
 		VsockServer:          vSockServer(d),
 		VsockSupport:         false,
 	}
@@ -1273,6 +1356,10 @@ func (d *Daemon) init() error {
 
 	d.gateway.Cluster = d.db.Cluster
 
+	// Invalidate the cached database entities when they are changed on any cluster member.
+	d.clusterListener = events.NewClusterInternalListener(d.shutdownCtx, d.events)
+	d.clusterListener.AddHandler("entity-cache", d.db.Cluster.EntityCache().HandleEvent)
+
 	// This logic used to belong to patchUpdateFromV10, but has been moved
 	// here because it needs database access.
 	if shared.PathExists(shared.VarPath("lxc")) {
@@ -1330,6 +1417,11 @@ func (d *Daemon) init() error {
 
 	d.events.SetLocalLocation(d.serverName)
 
+	// Read the trusted identities, so that the requests for the patch metrics can be authenticated while the
+	// remaining patches are applied.
+	loadIdentityCache(d, false)
+	d.patchesStatus.enableMetrics()
+
 	// Setup and load the server's UUID file.
 	// Use os.VarDir to allow setting up the uuid file also in the test suite.
 	var serverUUID string
@@ -1429,6 +1521,7 @@ func (d *Daemon) init() error {
 	maasAPIURL, maasAPIKey = d.globalConfig.MAASController()
 	d.gateway.HeartbeatOfflineThreshold = d.globalConfig.OfflineThreshold()
 	lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes := d.globalConfig.LokiServer()
+	natsURL, natsUsername, natsPassword, natsToken, natsCACert, natsSubject, natsTypes := d.globalConfig.NATSServer()
 	oidcIssuer, oidcClientID, oidcAudience, oidcGroupsClaim := d.globalConfig.OIDCServer()
 	syslogSocketEnabled := d.localConfig.SyslogSocket()
 	instancePlacementScriptlet := d.globalConfig.InstancesPlacementScriptlet()
@@ -1444,6 +1537,14 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	// Setup NATS event publisher.
+	if natsURL != "" {
+		err = d.setupNATS(natsURL, natsUsername, natsPassword, natsToken, natsCACert, natsSubject, natsTypes)
+		if err != nil {
+			return err
+		}
+	}
+
 	if syslogSocketEnabled {
 		err = d.setupSyslogSocket(true)
 		if err != nil {
@@ -1529,6 +1630,9 @@ func (d *Daemon) init() error {
 		logger.Info("Started DNS server")
 	}
 
+	// Apply the dedicated listener certificates before bringing the listeners up.
+	acmeLoadListenerCertificates(d.State())
+
 	metricsAddress := d.localConfig.MetricsAddress()
 	if metricsAddress != "" {
 		err = d.endpoints.UpMetrics(metricsAddress)
@@ -1545,6 +1649,22 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	eventsAddress := d.localConfig.EventsAddress()
+	if eventsAddress != "" {
+		err = d.endpoints.UpEvents(eventsAddress)
+		if err != nil {
+			return err
+		}
+	}
+
+	migrationAddress := d.localConfig.MigrationAddress()
+	if migrationAddress != "" {
+		err = d.endpoints.UpMigration(migrationAddress)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Load instance placement scriptlet.
 	if instancePlacementScriptlet != "" {
 		err = scriptletLoad.InstancePlacementSet(instancePlacementScriptlet)
@@ -1590,6 +1710,9 @@ func (d *Daemon) init() error {
 		// This should come after the event handler go routines have been started.
 		devicesRegister(instances)
 
+		// Remove the ephemeral instances that were lost along with the content of volatile storage pools.
+		instances = instancesDeleteVolatile(d.State(), instances)
+
 		// Setup seccomp handler
 		if d.os.SeccompListener {
 			seccompServer, err := seccomp.NewSeccompServer(d.State(), shared.VarPath("seccomp.socket"), func(pid int32, state *state.State) (seccomp.Instance, error) {
@@ -1644,6 +1767,12 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	// Apply all patches that need to be run after the instances are loaded and before they are started.
+	err = patchesApply(d, patchPostInstances)
+	if err != nil {
+		return err
+	}
+
 	err = d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		// Remove volatile.last_state.ready key as we don't know if the instances are ready.
 		return tx.DeleteReadyStateFromLocalInstances(ctx)
@@ -1666,6 +1795,14 @@ func (d *Daemon) init() error {
 		return nil
 	})
 
+	// Record the versions of the software components of this member, used to guard operations across members.
+	err = d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateNodeVersions(ctx, d.db.Cluster.GetNodeID(), d.componentVersions())
+	})
+	if err != nil {
+		logger.Warn("Failed to record member versions", logger.Ctx{"err": err})
+	}
+
 	// Resolve warnings older than the daemon start time
 	err = warnings.ResolveWarningsByLocalNodeOlderThan(d.db.Cluster, d.startTime)
 	if err != nil {
@@ -1701,6 +1838,9 @@ func (d *Daemon) init() error {
 		// Remove expired backups (hourly)
 		d.tasks.Add(pruneExpiredBackupsTask(d))
 
+		// Verify the integrity of the backup deduplication store (daily)
+		d.tasks.Add(backupsDedupScrubTask(d))
+
 		// Prune expired instance snapshots and take snapshot of instances (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateInstanceSnapshotsTask(d))
 
@@ -1715,6 +1855,54 @@ func (d *Daemon) init() error {
 
 		// Remove expired tokens (hourly)
 		d.tasks.Add(autoRemoveExpiredTokensTask(d))
+
+		// Remove expired tombstones (hourly)
+		d.tasks.Add(pruneExpiredTombstonesTask(d))
+
+		// Remove expired approvals (hourly)
+		d.tasks.Add(pruneExpiredAuthApprovalsTask(d))
+
+		// Sample disk space usage of storage pools (hourly)
+		d.tasks.Add(storagePoolUsageSampleTask(d))
+
+		// Push new credentials to instances before they expire (minutely)
+		d.tasks.Add(rotateDevlxdCredentialsTask(d))
+
+		// Keep the SSH keys of instances in sync (minutely)
+		d.tasks.Add(syncInstanceSSHKeysTask(d))
+
+		// Resynchronize the clock of virtual machines after host suspend (minutely)
+		d.tasks.Add(syncGuestClocksTask(d))
+
+		// Run the scheduled commands of instances (minutely)
+		d.tasks.Add(instanceCommandsScheduleTask(d))
+
+		// Sample resource usage of instances (configurable interval)
+		d.taskInstanceUsageSample = d.tasks.Add(instanceUsageSampleTask(d))
+
+		// Refresh the number of events kept for replay in each project (minutely)
+		d.tasks.Add(eventsHistorySizesRefreshTask(d))
+
+		// Publish instance records to external DNS providers (minutely)
+		d.tasks.Add(networkExternalDNSSyncTask(d))
+
+		// Check the connections to the OVN databases (minutely)
+		d.tasks.Add(networkOVNHealthCheckTask(d))
+
+		// Refill the standby instances of instance pools (minutely)
+		d.tasks.Add(instancePoolsRefillTask(d))
+
+		// Delete ephemeral instances whose lease has expired (minutely)
+		d.tasks.Add(instancesReapExpiredTask(d))
+
+		// Reconcile the disk usage of the projects that enforce it (every 10 minutes)
+		d.tasks.Add(projectsDiskUsageTask(d))
+
+		// Ship snapshots of the global database to object storage (configurable interval)
+		d.taskDatabaseSnapshots = d.tasks.Add(databaseSnapshotsTask(d))
+
+		// Look for orphaned rows in the global database (daily)
+		d.tasks.Add(databaseIntegrityTask(d))
 	}
 
 	// Start all background tasks
@@ -1723,6 +1911,9 @@ func (d *Daemon) init() error {
 	// Restore instances
 	instancesStart(d.State(), instances)
 
+	// Resume forwarding the guest journals
+	instancesForwardGuestJournals(instances)
+
 	// Re-balance in case things changed while LXD was down
 	deviceTaskBalance(d.State())
 
@@ -2167,6 +2358,9 @@ func (d *Daemon) heartbeatHandler(w http.ResponseWriter, r *http.Request, isLead
 func (d *Daemon) nodeRefreshTask(heartbeatData *cluster.APIHeartbeat, isLeader bool, unavailableMembers []string) {
 	s := d.State()
 
+	// Let the patches waiting on other members re-check their condition, even while still starting up.
+	d.patchesStatus.wake()
+
 	// Don't process the heartbeat until we're fully online.
 	if d.db.Cluster == nil || d.db.Cluster.GetNodeID() == 0 {
 		return
@@ -2203,7 +2397,7 @@ func (d *Daemon) nodeRefreshTask(heartbeatData *cluster.APIHeartbeat, isLeader b
 		// Refresh the identity cache.
 		updateIdentityCache(d)
 
-		// Refresh forkdns peers.
+		// Refresh forkdns and overlay peers.
 		err := networkUpdateForkdnsServersTask(s, heartbeatData)
 		if err != nil {
 			stateChangeTaskFailure = true
@@ -2286,3 +2480,32 @@ func (d *Daemon) nodeRefreshTask(heartbeatData *cluster.APIHeartbeat, isLeader b
 
 	wg.Wait()
 }
+
+// componentVersions returns the versions of the software components of this member that matter to operations
+// spanning multiple members, like live migrations.
+func (d *Daemon) componentVersions() map[string]string {
+	versions := map[string]string{
+		cluster.VersionComponentKernel: d.os.Uname.Release,
+		cluster.VersionComponentLXD:    version.Version,
+	}
+
+	for _, driver := range instanceDrivers.DriverStatuses() {
+		if driver.Supported {
+			versions[driver.Info.Name] = driver.Info.Version
+		}
+	}
+
+	// CRIU is optional, it is only needed for stateful operations on containers.
+	out, err := shared.RunCommand("criu", "--version")
+	if err == nil {
+		for _, line := range strings.Split(out, "\n") {
+			criuVersion, ok := strings.CutPrefix(line, "Version: ")
+			if ok {
+				versions[cluster.VersionComponentCRIU] = strings.TrimSpace(criuVersion)
+				break
+			}
+		}
+	}
+
+	return versions
+}
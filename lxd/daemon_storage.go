@@ -30,7 +30,7 @@ func daemonStorageVolumesUnmount(s *state.State) error {
 		}
 
 		storageBackups = nodeConfig.StorageBackupsVolume()
-		storageImages = nodeConfig.StorageImagesVolume()
+		storageImages, _ = daemonStorageImagesVolume(s, nodeConfig)
 
 		return nil
 	})
@@ -79,6 +79,7 @@ func daemonStorageVolumesUnmount(s *state.State) error {
 func daemonStorageMount(s *state.State) error {
 	var storageBackups string
 	var storageImages string
+	var storageImagesShared bool
 	err := s.DB.Node.Transaction(context.TODO(), func(ctx context.Context, tx *db.NodeTx) error {
 		nodeConfig, err := node.ConfigLoad(ctx, tx)
 		if err != nil {
@@ -86,7 +87,7 @@ func daemonStorageMount(s *state.State) error {
 		}
 
 		storageBackups = nodeConfig.StorageBackupsVolume()
-		storageImages = nodeConfig.StorageImagesVolume()
+		storageImages, storageImagesShared = daemonStorageImagesVolume(s, nodeConfig)
 
 		return nil
 	})
@@ -127,11 +128,52 @@ func daemonStorageMount(s *state.State) error {
 		if err != nil {
 			return fmt.Errorf("Failed to mount images storage: %w", err)
 		}
+
+		// The shared images volume may have been changed while the member was offline.
+		if storageImagesShared && !daemonStorageIsOnVolume("images", storageImages) {
+			_, err := os.Readlink(shared.VarPath("images"))
+			err = daemonStorageMove(s, "images", storageImages, err == nil, true)
+			if err != nil {
+				return fmt.Errorf("Failed to move images storage to %q: %w", storageImages, err)
+			}
+		}
 	}
 
 	return nil
 }
 
+// daemonStorageImagesVolume returns the volume used to store the image tarballs of the member, and whether it's the
+// volume shared by all cluster members.
+func daemonStorageImagesVolume(s *state.State, nodeConfig *node.Config) (string, bool) {
+	volume := nodeConfig.StorageImagesVolume()
+	if volume != "" {
+		return volume, false
+	}
+
+	if s.GlobalConfig == nil {
+		return "", false
+	}
+
+	volume = s.GlobalConfig.ImagesVolume()
+
+	return volume, volume != ""
+}
+
+// daemonStorageIsOnVolume returns whether the daemon storage of the given type is stored on the volume.
+func daemonStorageIsOnVolume(storageType string, volume string) bool {
+	poolName, volumeName, err := daemonStorageSplitVolume(volume)
+	if err != nil {
+		return false
+	}
+
+	target, err := os.Readlink(shared.VarPath(storageType))
+	if err != nil {
+		return false
+	}
+
+	return target == storageDrivers.GetVolumeMountPath(poolName, storageDrivers.VolumeTypeCustom, project.StorageVolume(api.ProjectDefaultName, volumeName))
+}
+
 func daemonStorageSplitVolume(volume string) (poolName string, volumeName string, err error) {
 	fields := strings.Split(volume, "/")
 	if len(fields) != 2 {
@@ -231,7 +273,66 @@ func daemonStorageValidate(s *state.State, target string) error {
 	return nil
 }
 
-func daemonStorageMove(s *state.State, storageType string, target string) error {
+// daemonStorageValidateShared validates the volume to be shared by all cluster members.
+func daemonStorageValidateShared(s *state.State, target string) error {
+	if target == "" {
+		return nil
+	}
+
+	poolName, _, err := daemonStorageSplitVolume(target)
+	if err != nil {
+		return err
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return err
+	}
+
+	if !pool.Driver().Info().VolumeMultiNode {
+		return fmt.Errorf("Storage pool %q doesn't support volumes used by multiple cluster members", poolName)
+	}
+
+	return daemonStorageValidate(s, target)
+}
+
+// daemonStorageMergeContent moves the entries of the source directory that don't exist in the target directory yet
+// and removes the others.
+func daemonStorageMergeContent(source string, target string) error {
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		sourcePath := filepath.Join(source, entry.Name())
+		targetPath := filepath.Join(target, entry.Name())
+
+		// Skip temporary directories and entries already provided by other members.
+		if entry.IsDir() || shared.PathExists(targetPath) {
+			err := os.RemoveAll(sourcePath)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err := shared.FileMove(sourcePath, targetPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// daemonStorageMove moves the daemon storage of the given type to the target volume, or back to the local disk if
+// the target is empty.
+// When the source is the volume shared by all cluster members, its content is copied and kept for the other members.
+// When the target is the volume shared by all cluster members, the content is merged with the one of the other
+// members.
+func daemonStorageMove(s *state.State, storageType string, target string, sourceShared bool, targetShared bool) error {
 	destPath := shared.VarPath(storageType)
 
 	// Track down the current storage.
@@ -248,12 +349,21 @@ func daemonStorageMove(s *state.State, storageType string, target string) error
 	}
 
 	moveContent := func(source string, target string) error {
+		if targetShared {
+			return daemonStorageMergeContent(source, target)
+		}
+
 		// Copy the content.
 		_, err := rsync.LocalCopy(source, target, "", false)
 		if err != nil {
 			return err
 		}
 
+		// Keep the content of the shared volume for the other members.
+		if sourceShared {
+			return nil
+		}
+
 		// Remove the source content.
 		entries, err := os.ReadDir(source)
 		if err != nil {
@@ -332,6 +442,11 @@ func daemonStorageMove(s *state.State, storageType string, target string) error
 	mountpoint := storageDrivers.GetVolumeMountPath(poolName, storageDrivers.VolumeTypeCustom, volStorageName)
 	destPath = mountpoint
 
+	// Things already look correct.
+	if sourcePath == destPath {
+		return nil
+	}
+
 	err = os.Chmod(mountpoint, 0700)
 	if err != nil {
 		return fmt.Errorf("Failed to set permissions on %q: %w", mountpoint, err)
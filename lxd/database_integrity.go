@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/warnings"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+var clusterDatabaseIntegrityCmd = APIEndpoint{
+	Path: "cluster/database-integrity",
+
+	Get:  APIEndpointAction{Handler: clusterDatabaseIntegrityGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: clusterDatabaseIntegrityPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+// swagger:operation GET /1.0/cluster/database-integrity cluster cluster_database_integrity_get
+//
+//	Check the database integrity
+//
+//	Looks for rows of the global database referencing entities that don't exist and returns how many were
+//	found for each class of orphaned rows. The warning about orphaned rows is updated accordingly.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Database integrity
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ClusterDatabaseIntegrity"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterDatabaseIntegrityGet(d *Daemon, r *http.Request) response.Response {
+	integrity, err := databaseIntegrityCheck(r.Context(), d.State(), nil)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, integrity)
+}
+
+// swagger:operation POST /1.0/cluster/database-integrity cluster cluster_database_integrity_post
+//
+//	Delete orphaned database rows
+//
+//	Deletes the orphaned rows of the global database found by the given checks, then checks the
+//	database integrity again.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: integrity
+//	    description: Checks whose orphaned rows should be deleted
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ClusterDatabaseIntegrityPost"
+//	responses:
+//	  "200":
+//	    description: Database integrity
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ClusterDatabaseIntegrity"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterDatabaseIntegrityPost(d *Daemon, r *http.Request) response.Response {
+	req := api.ClusterDatabaseIntegrityPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if len(req.Delete) == 0 {
+		return response.BadRequest(fmt.Errorf("No integrity check selected"))
+	}
+
+	names := databaseIntegrityCheckNames()
+	for _, name := range req.Delete {
+		if !shared.ValueInSlice(name, names) {
+			return response.BadRequest(fmt.Errorf("Unknown integrity check %q", name))
+		}
+	}
+
+	integrity, err := databaseIntegrityCheck(r.Context(), d.State(), req.Delete)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, integrity)
+}
+
+// databaseIntegrityCheck deletes the orphaned rows found by the given integrity checks, then counts the orphaned
+// rows left and raises or resolves the warning about them.
+func databaseIntegrityCheck(ctx context.Context, s *state.State, deleteChecks []string) (*api.ClusterDatabaseIntegrity, error) {
+	var counts map[string]int64
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		for _, name := range deleteChecks {
+			deleted, err := dbCluster.DeleteOrphans(ctx, tx.Tx(), name)
+			if err != nil {
+				return err
+			}
+
+			logger.Info("Deleted orphaned database rows", logger.Ctx{"check": name, "rows": deleted})
+		}
+
+		var err error
+		counts, err = dbCluster.CountOrphans(ctx, tx.Tx())
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed checking database integrity: %w", err)
+	}
+
+	integrity := &api.ClusterDatabaseIntegrity{Checks: make([]api.ClusterDatabaseIntegrityCheck, 0, len(dbCluster.IntegrityChecks))}
+	var findings []string
+	for _, check := range dbCluster.IntegrityChecks {
+		integrity.Checks = append(integrity.Checks, api.ClusterDatabaseIntegrityCheck{
+			Name:        check.Name,
+			Description: check.Description,
+			Orphans:     counts[check.Name],
+		})
+
+		if counts[check.Name] > 0 {
+			findings = append(findings, fmt.Sprintf("%s: %d", check.Name, counts[check.Name]))
+		}
+	}
+
+	if len(findings) == 0 {
+		err = warnings.ResolveWarningsByLocalNodeAndType(s.DB.Cluster, warningtype.DatabaseOrphans)
+	} else {
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarningLocalNode(ctx, "", "", -1, warningtype.DatabaseOrphans, strings.Join(findings, ", "))
+		})
+	}
+
+	if err != nil {
+		logger.Warn("Failed updating orphaned database rows warning", logger.Ctx{"err": err})
+	}
+
+	return integrity, nil
+}
+
+func databaseIntegrityTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		// The global database is shared, so only check it from the leader.
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil && !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if err == nil && s.LocalConfig.ClusterAddress() != leader {
+			logger.Debug("Skipping database integrity task since we're not leader")
+			return
+		}
+
+		logger.Debug("Checking database integrity")
+		integrity, err := databaseIntegrityCheck(ctx, s, nil)
+		if err != nil {
+			logger.Error("Failed checking database integrity", logger.Ctx{"err": err})
+			return
+		}
+
+		for _, check := range integrity.Checks {
+			if check.Orphans > 0 {
+				logger.Warn("Found orphaned database rows", logger.Ctx{"check": check.Name, "rows": check.Orphans})
+			}
+		}
+
+		logger.Debug("Done checking database integrity")
+	}
+
+	return f, task.Daily(task.SkipFirst)
+}
+
+// databaseIntegrityCheckNames returns the names of all integrity checks.
+func databaseIntegrityCheckNames() []string {
+	names := make([]string, 0, len(dbCluster.IntegrityChecks))
+	for _, check := range dbCluster.IntegrityChecks {
+		names = append(names, check.Name)
+	}
+
+	return names
+}
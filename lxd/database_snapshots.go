@@ -0,0 +1,441 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/cluster/dbsnapshot"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var clusterDatabaseSnapshotsCmd = APIEndpoint{
+	Path: "cluster/database-snapshots",
+
+	Get:  APIEndpointAction{Handler: clusterDatabaseSnapshotsGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: clusterDatabaseSnapshotsPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var clusterDatabaseSnapshotCmd = APIEndpoint{
+	Path: "cluster/database-snapshots/{name}",
+
+	Get:  APIEndpointAction{Handler: clusterDatabaseSnapshotGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: clusterDatabaseSnapshotPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+// swagger:operation GET /1.0/cluster/database-snapshots cluster cluster_database_snapshots_get
+//
+//	Get the database snapshots
+//
+//	Returns a list of the global database snapshots stored in the configured S3 bucket (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/cluster/database-snapshots/20261018T120000Z",
+//	              "/1.0/cluster/database-snapshots/20261018T130000Z"
+//	            ]
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/cluster/database-snapshots?recursion=1 cluster cluster_database_snapshots_get_recursion1
+//
+//	Get the database snapshots
+//
+//	Returns a list of the global database snapshots stored in the configured S3 bucket (structs), newest first.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of database snapshots
+//	          items:
+//	            $ref: "#/definitions/ClusterDatabaseSnapshot"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterDatabaseSnapshotsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	snapshots, err := dbsnapshot.List(r.Context(), databaseSnapshotTarget(s))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if util.IsRecursionRequest(r) {
+		return response.SyncResponse(true, snapshots)
+	}
+
+	urls := make([]string, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		urls = append(urls, api.NewURL().Path(version.APIVersion, "cluster", "database-snapshots", snapshot.Name).String())
+	}
+
+	return response.SyncResponse(true, urls)
+}
+
+// swagger:operation POST /1.0/cluster/database-snapshots cluster cluster_database_snapshots_post
+//
+//	Ship a database snapshot
+//
+//	Takes a snapshot of the global database and ships it to the configured S3 bucket right away,
+//	then deletes the snapshots exceeding the configured retention.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterDatabaseSnapshotsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !databaseSnapshotTarget(s).Configured() {
+		return response.BadRequest(fmt.Errorf("No database snapshot target is configured"))
+	}
+
+	run := func(op *operations.Operation) error {
+		snapshot, err := databaseSnapshotShip(context.Background(), s)
+		if err != nil {
+			return err
+		}
+
+		return op.UpdateMetadata(map[string]any{"name": snapshot.Name})
+	}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.DatabaseSnapshotCreate, nil, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// swagger:operation GET /1.0/cluster/database-snapshots/{name} cluster cluster_database_snapshot_get
+//
+//	Get the database snapshot
+//
+//	Gets a specific global database snapshot stored in the configured S3 bucket.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Database snapshot
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ClusterDatabaseSnapshot"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterDatabaseSnapshotGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	snapshots, err := dbsnapshot.List(r.Context(), databaseSnapshotTarget(s))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			return response.SyncResponse(true, snapshot)
+		}
+	}
+
+	return response.NotFound(fmt.Errorf("Database snapshot %q not found", name))
+}
+
+// swagger:operation POST /1.0/cluster/database-snapshots/{name} cluster cluster_database_snapshot_post
+//
+//	Restore the database snapshot
+//
+//	Replaces the content of the global database with the one of the snapshot.
+//	This is only possible on a standalone server, which takes over the instances, volumes and other
+//	resources of all the cluster members the snapshot was taken on.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: snapshot
+//	    description: Database snapshot action
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ClusterDatabaseSnapshotPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterDatabaseSnapshotPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.ClusterDatabaseSnapshotPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Action != "restore" {
+		return response.BadRequest(fmt.Errorf("Unknown action %q", req.Action))
+	}
+
+	if s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("Database snapshots can only be restored on a standalone server"))
+	}
+
+	target := databaseSnapshotTarget(s)
+	if !target.Configured() {
+		return response.BadRequest(fmt.Errorf("No database snapshot target is configured"))
+	}
+
+	run := func(op *operations.Operation) error {
+		ctx := context.Background()
+
+		dump, err := dbsnapshot.Fetch(ctx, target, name)
+		if err != nil {
+			return err
+		}
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return dbCluster.RestoreDump(ctx, tx.Tx(), dump, tx.GetNodeID())
+		})
+		if err != nil {
+			return fmt.Errorf("Failed restoring database snapshot %q: %w", name, err)
+		}
+
+		// Every cached entity may have changed.
+		s.DB.Cluster.EntityCache().Invalidate(entity.TypeProject, "")
+
+		logger.Warn("Restored global database from snapshot, LXD must be restarted to reload its configuration", logger.Ctx{"name": name})
+
+		return nil
+	}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.DatabaseSnapshotRestore, nil, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// databaseSnapshotTarget returns the S3 bucket database snapshots are shipped to.
+func databaseSnapshotTarget(s *state.State) dbsnapshot.Target {
+	s3URL, bucket, accessKey, secretKey := s.GlobalConfig.DatabaseSnapshots()
+
+	return dbsnapshot.Target{
+		URL:       s3URL,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	}
+}
+
+// databaseSnapshotShip ships a snapshot of the global database and prunes the snapshots exceeding the retention.
+func databaseSnapshotShip(ctx context.Context, s *state.State) (*api.ClusterDatabaseSnapshot, error) {
+	target := databaseSnapshotTarget(s)
+
+	var dump string
+	createdAt := time.Now()
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		dump, err = query.Dump(ctx, tx.Tx(), false)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed dumping global database: %w", err)
+	}
+
+	snapshot, err := dbsnapshot.Ship(ctx, target, dump, createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbsnapshot.Prune(ctx, target, int(s.GlobalConfig.DatabaseSnapshotsRetention()))
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func databaseSnapshotsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		// Only the leader ships snapshots, as they cover the whole cluster.
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil && !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if err == nil && s.LocalConfig.ClusterAddress() != leader {
+			logger.Debug("Skipping database snapshot task since we're not leader")
+			return
+		}
+
+		opRun := func(op *operations.Operation) error {
+			_, err := databaseSnapshotShip(ctx, s)
+			return err
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.DatabaseSnapshotCreate, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating database snapshot operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Debug("Shipping database snapshot")
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting database snapshot operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed shipping database snapshot", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Debug("Done shipping database snapshot")
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		s := d.State()
+
+		// Check again hourly whether snapshots got enabled, the task is also reset on configuration changes.
+		interval := time.Duration(s.GlobalConfig.DatabaseSnapshotsIntervalHours()) * time.Hour
+		if interval <= 0 || !databaseSnapshotTarget(s).Configured() {
+			return time.Hour, task.ErrSkip
+		}
+
+		// Don't ship a snapshot on every daemon start.
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
@@ -0,0 +1,145 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t auth_approvals.mapper.go
+//go:generate mapper reset -i -b "//go:build linux && cgo && !agent"
+//
+//go:generate mapper stmt -e auth_approval objects table=auth_approvals
+//go:generate mapper stmt -e auth_approval objects-by-ID table=auth_approvals
+//go:generate mapper stmt -e auth_approval objects-by-Username-and-Protocol table=auth_approvals
+//go:generate mapper stmt -e auth_approval delete-by-ID table=auth_approvals
+//
+//go:generate mapper method -i -e auth_approval GetMany
+//go:generate mapper method -i -e auth_approval DeleteOne-by-ID
+
+// AuthApprovalStatus is the status of an approval.
+type AuthApprovalStatus int
+
+// Approval statuses.
+const (
+	AuthApprovalStatusPending AuthApprovalStatus = iota
+	AuthApprovalStatusApproved
+	AuthApprovalStatusRejected
+	AuthApprovalStatusUsed
+)
+
+// AuthApprovalStatuses maps approval statuses to their API names.
+var AuthApprovalStatuses = map[AuthApprovalStatus]string{
+	AuthApprovalStatusPending:  api.AuthApprovalStatusPending,
+	AuthApprovalStatusApproved: api.AuthApprovalStatusApproved,
+	AuthApprovalStatusRejected: api.AuthApprovalStatusRejected,
+	AuthApprovalStatusUsed:     api.AuthApprovalStatusUsed,
+}
+
+// AuthApproval is a value object holding db-related details about a request for approval of a privileged action.
+type AuthApproval struct {
+	ID        int `db:"primary=yes"`
+	Action    string
+	Entity    string
+	Reason    string
+	Username  string
+	Protocol  string
+	Approver  string
+	Status    AuthApprovalStatus
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// AuthApprovalFilter specifies potential query parameter fields.
+type AuthApprovalFilter struct {
+	ID       *int
+	Username *string
+	Protocol *string
+}
+
+// ToAPI returns a LXD API entry.
+func (a AuthApproval) ToAPI() api.AuthApproval {
+	return api.AuthApproval{
+		AuthApprovalsPost: api.AuthApprovalsPost{
+			Action: a.Action,
+			Entity: a.Entity,
+			Reason: a.Reason,
+		},
+		ID:     int64(a.ID),
+		Status: AuthApprovalStatuses[a.Status],
+		Requestor: &api.EventLifecycleRequestor{
+			Username: a.Username,
+			Protocol: a.Protocol,
+		},
+		Approver:  a.Approver,
+		CreatedAt: a.CreatedAt,
+		ExpiresAt: a.ExpiresAt,
+	}
+}
+
+// GetAuthApproval returns the approval with the given ID.
+func GetAuthApproval(ctx context.Context, tx *sql.Tx, id int) (*AuthApproval, error) {
+	approvals, err := GetAuthApprovals(ctx, tx, AuthApprovalFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(approvals) == 0 {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Approval not found")
+	}
+
+	return &approvals[0], nil
+}
+
+// CreateAuthApproval adds a new approval to the database.
+func CreateAuthApproval(ctx context.Context, tx *sql.Tx, object AuthApproval) (int64, error) {
+	stmt := `
+INSERT INTO auth_approvals (action, entity, reason, username, protocol, approver, status, created_at, expires_at)
+  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+	result, err := tx.ExecContext(ctx, stmt, object.Action, object.Entity, object.Reason, object.Username, object.Protocol, object.Approver, object.Status, object.CreatedAt, object.ExpiresAt)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to create approval: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+// UpdateAuthApprovalStatus sets the status of the approval with the given ID, along with the identity that decided
+// about it and its new expiry.
+func UpdateAuthApprovalStatus(ctx context.Context, tx *sql.Tx, id int, status AuthApprovalStatus, approver string, expiresAt time.Time) error {
+	result, err := tx.ExecContext(ctx, "UPDATE auth_approvals SET status = ?, approver = ?, expires_at = ? WHERE id = ?", status, approver, expiresAt, id)
+	if err != nil {
+		return fmt.Errorf("Failed to update approval %d: %w", id, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Approval not found")
+	}
+
+	return nil
+}
+
+// DeleteExpiredAuthApprovals deletes the approvals that expired before the given date.
+func DeleteExpiredAuthApprovals(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error) {
+	result, err := tx.ExecContext(ctx, "DELETE FROM auth_approvals WHERE expires_at < ?", before)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to delete expired approvals: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,19 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+)
+
+// AuthApprovalGenerated is an interface of generated methods for AuthApproval.
+type AuthApprovalGenerated interface {
+	// GetAuthApprovals returns all available auth_approvals.
+	// generator: auth_approval GetMany
+	GetAuthApprovals(ctx context.Context, tx *sql.Tx, filters ...AuthApprovalFilter) ([]AuthApproval, error)
+
+	// DeleteAuthApproval deletes the auth_approval matching the given key parameters.
+	// generator: auth_approval DeleteOne-by-ID
+	DeleteAuthApproval(ctx context.Context, tx *sql.Tx, id int) error
+}
@@ -0,0 +1,214 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+// The code below was generated by lxd-generate - DO NOT EDIT!
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+var _ = api.ServerEnvironment{}
+
+var authApprovalObjects = RegisterStmt(`
+SELECT auth_approvals.id, auth_approvals.action, auth_approvals.entity, auth_approvals.reason, auth_approvals.username, auth_approvals.protocol, auth_approvals.approver, auth_approvals.status, auth_approvals.created_at, auth_approvals.expires_at
+  FROM auth_approvals
+  ORDER BY auth_approvals.id
+`)
+
+var authApprovalObjectsByID = RegisterStmt(`
+SELECT auth_approvals.id, auth_approvals.action, auth_approvals.entity, auth_approvals.reason, auth_approvals.username, auth_approvals.protocol, auth_approvals.approver, auth_approvals.status, auth_approvals.created_at, auth_approvals.expires_at
+  FROM auth_approvals
+  WHERE ( auth_approvals.id = ? )
+  ORDER BY auth_approvals.id
+`)
+
+var authApprovalObjectsByUsernameAndProtocol = RegisterStmt(`
+SELECT auth_approvals.id, auth_approvals.action, auth_approvals.entity, auth_approvals.reason, auth_approvals.username, auth_approvals.protocol, auth_approvals.approver, auth_approvals.status, auth_approvals.created_at, auth_approvals.expires_at
+  FROM auth_approvals
+  WHERE ( auth_approvals.username = ? AND auth_approvals.protocol = ? )
+  ORDER BY auth_approvals.id
+`)
+
+var authApprovalDeleteByID = RegisterStmt(`
+DELETE FROM auth_approvals WHERE id = ?
+`)
+
+// authApprovalColumns returns a string of column names to be used with a SELECT statement for the entity.
+// Use this function when building statements to retrieve database entries matching the AuthApproval entity.
+func authApprovalColumns() string {
+	return "auths_approvals.id, auths_approvals.action, auths_approvals.entity, auths_approvals.reason, auths_approvals.username, auths_approvals.protocol, auths_approvals.approver, auths_approvals.status, auths_approvals.created_at, auths_approvals.expires_at"
+}
+
+// getAuthApprovals can be used to run handwritten sql.Stmts to return a slice of objects.
+func getAuthApprovals(ctx context.Context, stmt *sql.Stmt, args ...any) ([]AuthApproval, error) {
+	objects := make([]AuthApproval, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		a := AuthApproval{}
+		err := scan(&a.ID, &a.Action, &a.Entity, &a.Reason, &a.Username, &a.Protocol, &a.Approver, &a.Status, &a.CreatedAt, &a.ExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, a)
+
+		return nil
+	}
+
+	err := query.SelectObjects(ctx, stmt, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"auths_approvals\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// getAuthApprovalsRaw can be used to run handwritten query strings to return a slice of objects.
+func getAuthApprovalsRaw(ctx context.Context, tx *sql.Tx, sql string, args ...any) ([]AuthApproval, error) {
+	objects := make([]AuthApproval, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		a := AuthApproval{}
+		err := scan(&a.ID, &a.Action, &a.Entity, &a.Reason, &a.Username, &a.Protocol, &a.Approver, &a.Status, &a.CreatedAt, &a.ExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, a)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, sql, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"auths_approvals\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// GetAuthApprovals returns all available auth_approvals.
+// generator: auth_approval GetMany
+func GetAuthApprovals(ctx context.Context, tx *sql.Tx, filters ...AuthApprovalFilter) ([]AuthApproval, error) {
+	var err error
+
+	// Result slice.
+	objects := make([]AuthApproval, 0)
+
+	// Pick the prepared statement and arguments to use based on active criteria.
+	var sqlStmt *sql.Stmt
+	args := []any{}
+	queryParts := [2]string{}
+
+	if len(filters) == 0 {
+		sqlStmt, err = Stmt(tx, authApprovalObjects)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get \"authApprovalObjects\" prepared statement: %w", err)
+		}
+	}
+
+	for i, filter := range filters {
+		if filter.Username != nil && filter.Protocol != nil && filter.ID == nil {
+			args = append(args, []any{filter.Username, filter.Protocol}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, authApprovalObjectsByUsernameAndProtocol)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"authApprovalObjectsByUsernameAndProtocol\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(authApprovalObjectsByUsernameAndProtocol)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"authApprovalObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.ID != nil && filter.Username == nil && filter.Protocol == nil {
+			args = append(args, []any{filter.ID}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, authApprovalObjectsByID)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"authApprovalObjectsByID\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(authApprovalObjectsByID)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"authApprovalObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.ID == nil && filter.Username == nil && filter.Protocol == nil {
+			return nil, fmt.Errorf("Cannot filter on empty AuthApprovalFilter")
+		} else {
+			return nil, fmt.Errorf("No statement exists for the given Filter")
+		}
+	}
+
+	// Select.
+	if sqlStmt != nil {
+		objects, err = getAuthApprovals(ctx, sqlStmt, args...)
+	} else {
+		queryStr := strings.Join(queryParts[:], "ORDER BY")
+		objects, err = getAuthApprovalsRaw(ctx, tx, queryStr, args...)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"auths_approvals\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// DeleteAuthApproval deletes the auth_approval matching the given key parameters.
+// generator: auth_approval DeleteOne-by-ID
+func DeleteAuthApproval(ctx context.Context, tx *sql.Tx, id int) error {
+	stmt, err := Stmt(tx, authApprovalDeleteByID)
+	if err != nil {
+		return fmt.Errorf("Failed to get \"authApprovalDeleteByID\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.Exec(id)
+	if err != nil {
+		return fmt.Errorf("Delete \"auths_approvals\": %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "AuthApproval not found")
+	} else if n > 1 {
+		return fmt.Errorf("Query deleted %d AuthApproval rows instead of 1", n)
+	}
+
+	return nil
+}
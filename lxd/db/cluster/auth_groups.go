@@ -295,3 +295,21 @@ func SetAuthGroupPermissions(ctx context.Context, tx *sql.Tx, groupID int, authG
 
 	return nil
 }
+
+// SetAuthGroupIdentities deletes all auth_group -> identity mappings from the `identities_auth_groups` table where the
+// group ID is equal to the given value. Then it inserts a new row for each given identity ID.
+func SetAuthGroupIdentities(ctx context.Context, tx *sql.Tx, groupID int, identityIDs []int) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM identities_auth_groups WHERE auth_group_id = ?`, groupID)
+	if err != nil {
+		return fmt.Errorf("Failed to delete existing identities for group with ID `%d`: %w", groupID, err)
+	}
+
+	for _, identityID := range identityIDs {
+		_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO identities_auth_groups (identity_id, auth_group_id) VALUES (?, ?)`, identityID, groupID)
+		if err != nil {
+			return fmt.Errorf("Failed to write group identities: %w", err)
+		}
+	}
+
+	return nil
+}
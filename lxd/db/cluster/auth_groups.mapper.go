@@ -275,7 +275,7 @@ func CreateAuthGroup(ctx context.Context, tx *sql.Tx, object AuthGroup) (int64,
 	}
 
 	if exists {
-		return -1, api.StatusErrorf(http.StatusConflict, "This \"auths_groups\" entry already exists")
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "This \"auths_groups\" entry already exists")
 	}
 
 	args := make([]any, 2)
@@ -269,7 +269,7 @@ func CreateClusterGroup(ctx context.Context, tx *sql.Tx, object ClusterGroup) (i
 	}
 
 	if exists {
-		return -1, api.StatusErrorf(http.StatusConflict, "This \"clusters_groups\" entry already exists")
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "This \"clusters_groups\" entry already exists")
 	}
 
 	args := make([]any, 2)
@@ -8,10 +8,12 @@ package cluster
 //go:generate mapper reset -i -b "//go:build linux && cgo && !agent"
 //
 //go:generate mapper stmt -e config objects
+//go:generate mapper stmt -e config objects-by-ReferenceID parents=instance,instance_snapshot,profile,project,instance_device,instance_snapshot_device,profile_device
 //go:generate mapper stmt -e config create struct=Config
 //go:generate mapper stmt -e config delete
 //
 //go:generate mapper method -i -e config GetMany
+//go:generate mapper method -i -e config GetMany-by-ReferenceID
 //go:generate mapper method -i -e config Create struct=Config
 //go:generate mapper method -i -e config Update struct=Config
 //go:generate mapper method -i -e config DeleteMany
@@ -13,6 +13,10 @@ type ConfigGenerated interface {
 	// generator: config GetMany
 	GetConfig(ctx context.Context, tx *sql.Tx, parent string, filters ...ConfigFilter) (map[int]map[string]string, error)
 
+	// GetConfigByReferenceID returns all available config for the parent entity with the given ID.
+	// generator: config GetMany-by-ReferenceID
+	GetConfigByReferenceID(ctx context.Context, tx *sql.Tx, parent string, referenceID int, filters ...ConfigFilter) (map[string]string, error)
+
 	// CreateConfig adds a new config to the database.
 	// generator: config Create
 	CreateConfig(ctx context.Context, tx *sql.Tx, parent string, object Config) error
@@ -20,6 +20,51 @@ const configObjects = `SELECT %s_config.id, %s_config.%s_id, %s_config.key, %s_c
   FROM %s_config
   ORDER BY %s_config.id`
 
+var configObjectsByReferenceID = map[string]int{
+	"instance": RegisterStmt(`
+SELECT instances_config.id, instances_config.instance_id, instances_config.key, instances_config.value
+  FROM instances_config
+  WHERE ( instances_config.instance_id = ? )
+  ORDER BY instances_config.id
+`),
+	"instance_snapshot": RegisterStmt(`
+SELECT instances_snapshots_config.id, instances_snapshots_config.instance_snapshot_id, instances_snapshots_config.key, instances_snapshots_config.value
+  FROM instances_snapshots_config
+  WHERE ( instances_snapshots_config.instance_snapshot_id = ? )
+  ORDER BY instances_snapshots_config.id
+`),
+	"profile": RegisterStmt(`
+SELECT profiles_config.id, profiles_config.profile_id, profiles_config.key, profiles_config.value
+  FROM profiles_config
+  WHERE ( profiles_config.profile_id = ? )
+  ORDER BY profiles_config.id
+`),
+	"project": RegisterStmt(`
+SELECT projects_config.id, projects_config.project_id, projects_config.key, projects_config.value
+  FROM projects_config
+  WHERE ( projects_config.project_id = ? )
+  ORDER BY projects_config.id
+`),
+	"instance_device": RegisterStmt(`
+SELECT instances_devices_config.id, instances_devices_config.instance_device_id, instances_devices_config.key, instances_devices_config.value
+  FROM instances_devices_config
+  WHERE ( instances_devices_config.instance_device_id = ? )
+  ORDER BY instances_devices_config.id
+`),
+	"instance_snapshot_device": RegisterStmt(`
+SELECT instances_snapshots_devices_config.id, instances_snapshots_devices_config.instance_snapshot_device_id, instances_snapshots_devices_config.key, instances_snapshots_devices_config.value
+  FROM instances_snapshots_devices_config
+  WHERE ( instances_snapshots_devices_config.instance_snapshot_device_id = ? )
+  ORDER BY instances_snapshots_devices_config.id
+`),
+	"profile_device": RegisterStmt(`
+SELECT profiles_devices_config.id, profiles_devices_config.profile_device_id, profiles_devices_config.key, profiles_devices_config.value
+  FROM profiles_devices_config
+  WHERE ( profiles_devices_config.profile_device_id = ? )
+  ORDER BY profiles_devices_config.id
+`),
+}
+
 const configCreate = `INSERT INTO %s_config (%s_id, key, value)
   VALUES (?, ?, ?)`
 
@@ -143,6 +188,74 @@ func GetConfig(ctx context.Context, tx *sql.Tx, parent string, filters ...Config
 	return resultMap, nil
 }
 
+// GetConfigByReferenceID returns all available config for the parent entity with the given ID.
+// generator: config GetMany-by-ReferenceID
+func GetConfigByReferenceID(ctx context.Context, tx *sql.Tx, parent string, referenceID int, filters ...ConfigFilter) (map[string]string, error) {
+	var err error
+	var objects []Config
+
+	// Use the prepared statement registered for the parent entity, unless extra criteria are given.
+	stmtCode, ok := configObjectsByReferenceID[parent]
+	if ok && len(filters) == 0 {
+		var sqlStmt *sql.Stmt
+		sqlStmt, err = Stmt(tx, stmtCode)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get \"configObjectsByReferenceID\" prepared statement: %w", err)
+		}
+
+		objects, err = getConfig(ctx, sqlStmt, parent, referenceID)
+	} else {
+		configObjectsLocal := strings.Replace(configObjects, "%s_id", fmt.Sprintf("%s_id", parent), -1)
+		fillParent := make([]any, strings.Count(configObjectsLocal, "%s"))
+		for i := range fillParent {
+			fillParent[i] = strings.Replace(parent, "_", "s_", -1) + "s"
+		}
+
+		queryStr := fmt.Sprintf(configObjectsLocal, fillParent...)
+		queryParts := strings.SplitN(queryStr, "ORDER BY", 2)
+		queryParts[0] += fmt.Sprintf(" WHERE ( %s_id = ? )", parent)
+		args := []any{referenceID}
+		conds := []string{}
+
+		for _, filter := range filters {
+			entries := []string{}
+			if filter.Key != nil {
+				entries = append(entries, "key = ?")
+				args = append(args, filter.Key)
+			}
+
+			if filter.Value != nil {
+				entries = append(entries, "value = ?")
+				args = append(args, filter.Value)
+			}
+
+			if len(entries) == 0 {
+				return nil, fmt.Errorf("Cannot filter on empty ConfigFilter")
+			}
+
+			conds = append(conds, fmt.Sprintf("( %s )", strings.Join(entries, " AND ")))
+		}
+
+		if len(conds) > 0 {
+			queryParts[0] += fmt.Sprintf(" AND ( %s )", strings.Join(conds, " OR "))
+		}
+
+		queryStr = strings.Join(queryParts, " ORDER BY")
+		objects, err = getConfigRaw(ctx, tx, queryStr, parent, args...)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"%s_config\" table: %w", parent, err)
+	}
+
+	result := make(map[string]string, len(objects))
+	for _, object := range objects {
+		result[object.Key] = object.Value
+	}
+
+	return result, nil
+}
+
 // CreateConfig adds a new config to the database.
 // generator: config Create
 func CreateConfig(ctx context.Context, tx *sql.Tx, parent string, object Config) error {
@@ -0,0 +1,177 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t config_revisions.mapper.go
+//go:generate mapper reset -i -b "//go:build linux && cgo && !agent"
+//
+//go:generate mapper stmt -e config_revision objects
+//go:generate mapper stmt -e config_revision objects-by-ID
+//go:generate mapper stmt -e config_revision objects-by-EntityType-and-EntityID
+//go:generate mapper stmt -e config_revision objects-by-ID-and-EntityType-and-EntityID
+//
+//go:generate mapper method -i -e config_revision GetMany
+
+// ConfigRevision is a value object holding a recorded revision of the configuration of an entity.
+type ConfigRevision struct {
+	ID         int        `db:"primary=yes"`
+	EntityType EntityType `db:"sql=config_revisions.entity_type"`
+	EntityID   int
+	Date       time.Time
+	Username   string
+	Protocol   string
+	Address    string
+	Data       string
+}
+
+// ConfigRevisionFilter specifies potential query parameter fields.
+type ConfigRevisionFilter struct {
+	ID         *int
+	EntityType *EntityType
+	EntityID   *int
+}
+
+// ToAPI returns a LXD API entry.
+func (r ConfigRevision) ToAPI() (*api.ConfigRevision, error) {
+	revision := api.ConfigRevision{}
+
+	err := json.Unmarshal([]byte(r.Data), &revision)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse configuration revision %d: %w", r.ID, err)
+	}
+
+	revision.Revision = int64(r.ID)
+	revision.CreatedAt = r.Date
+
+	if r.Username != "" || r.Protocol != "" || r.Address != "" {
+		revision.Requestor = &api.EventLifecycleRequestor{
+			Username: r.Username,
+			Protocol: r.Protocol,
+			Address:  r.Address,
+		}
+	}
+
+	return &revision, nil
+}
+
+// CreateConfigRevisionFromAPI records a new revision for the given entity and prunes the oldest revisions of
+// that entity so that no more than maxRevisions are kept.
+func CreateConfigRevisionFromAPI(ctx context.Context, tx *sql.Tx, entityType EntityType, entityID int, revision api.ConfigRevision, maxRevisions int64) error {
+	var username, protocol, address string
+	if revision.Requestor != nil {
+		username = revision.Requestor.Username
+		protocol = revision.Requestor.Protocol
+		address = revision.Requestor.Address
+	}
+
+	// The revision metadata is stored in dedicated columns.
+	revision.Revision = 0
+	revision.CreatedAt = time.Time{}
+	revision.Requestor = nil
+
+	data, err := json.Marshal(revision)
+	if err != nil {
+		return err
+	}
+
+	stmt := `
+INSERT INTO config_revisions (entity_type, entity_id, date, username, protocol, address, data)
+  VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+	_, err = tx.ExecContext(ctx, stmt, entityType, entityID, time.Now().UTC(), username, protocol, address, string(data))
+	if err != nil {
+		return fmt.Errorf("Failed to record configuration revision: %w", err)
+	}
+
+	return PruneConfigRevisions(ctx, tx, entityType, entityID, maxRevisions)
+}
+
+// PruneConfigRevisions deletes all but the most recent maxRevisions revisions of the given entity.
+func PruneConfigRevisions(ctx context.Context, tx *sql.Tx, entityType EntityType, entityID int, maxRevisions int64) error {
+	entityTypeCode, err := entityType.Value()
+	if err != nil {
+		return err
+	}
+
+	stmt := `
+DELETE FROM config_revisions
+  WHERE entity_type = ? AND entity_id = ? AND id NOT IN (
+    SELECT id FROM config_revisions WHERE entity_type = ? AND entity_id = ? ORDER BY id DESC LIMIT ?
+  )
+`
+
+	_, err = tx.ExecContext(ctx, stmt, entityTypeCode, entityID, entityTypeCode, entityID, maxRevisions)
+	if err != nil {
+		return fmt.Errorf("Failed to prune configuration revisions: %w", err)
+	}
+
+	return nil
+}
+
+// GetConfigRevision returns the revision with the given ID belonging to the given entity.
+func GetConfigRevision(ctx context.Context, tx *sql.Tx, entityType EntityType, entityID int, revisionID int) (*ConfigRevision, error) {
+	filter := ConfigRevisionFilter{
+		ID:         &revisionID,
+		EntityType: &entityType,
+		EntityID:   &entityID,
+	}
+
+	revisions, err := GetConfigRevisions(ctx, tx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Configuration revision not found")
+	}
+
+	return &revisions[0], nil
+}
+
+// ConfigRevisionsExist returns whether any revision has been recorded for the given entity.
+func ConfigRevisionsExist(ctx context.Context, tx *sql.Tx, entityType EntityType, entityID int) (bool, error) {
+	entityTypeCode, err := entityType.Value()
+	if err != nil {
+		return false, err
+	}
+
+	count, err := query.Count(ctx, tx, "config_revisions", "entity_type = ? AND entity_id = ?", entityTypeCode, entityID)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetLatestConfigRevisionID returns the ID of the most recent revision of the given entity.
+// Zero is returned if no revision has been recorded for the entity.
+func GetLatestConfigRevisionID(ctx context.Context, tx *sql.Tx, entityType EntityType, entityID int) (int, error) {
+	entityTypeCode, err := entityType.Value()
+	if err != nil {
+		return -1, err
+	}
+
+	var id sql.NullInt64
+
+	err = tx.QueryRowContext(ctx, "SELECT MAX(id) FROM config_revisions WHERE entity_type = ? AND entity_id = ?", entityTypeCode, entityID).Scan(&id)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get latest configuration revision: %w", err)
+	}
+
+	return int(id.Int64), nil
+}
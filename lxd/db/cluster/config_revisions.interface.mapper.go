@@ -0,0 +1,15 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ConfigRevisionGenerated is an interface of generated methods for ConfigRevision.
+type ConfigRevisionGenerated interface {
+	// GetConfigRevisions returns all available config_revisions.
+	// generator: config_revision GetMany
+	GetConfigRevisions(ctx context.Context, tx *sql.Tx, filters ...ConfigRevisionFilter) ([]ConfigRevision, error)
+}
@@ -0,0 +1,213 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+// The code below was generated by lxd-generate - DO NOT EDIT!
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+var _ = api.ServerEnvironment{}
+
+var configRevisionObjects = RegisterStmt(`
+SELECT config_revisions.id, config_revisions.entity_type, config_revisions.entity_id, config_revisions.date, config_revisions.username, config_revisions.protocol, config_revisions.address, config_revisions.data
+  FROM config_revisions
+  ORDER BY config_revisions.id
+`)
+
+var configRevisionObjectsByID = RegisterStmt(`
+SELECT config_revisions.id, config_revisions.entity_type, config_revisions.entity_id, config_revisions.date, config_revisions.username, config_revisions.protocol, config_revisions.address, config_revisions.data
+  FROM config_revisions
+  WHERE ( config_revisions.id = ? )
+  ORDER BY config_revisions.id
+`)
+
+var configRevisionObjectsByEntityTypeAndEntityID = RegisterStmt(`
+SELECT config_revisions.id, config_revisions.entity_type, config_revisions.entity_id, config_revisions.date, config_revisions.username, config_revisions.protocol, config_revisions.address, config_revisions.data
+  FROM config_revisions
+  WHERE ( config_revisions.entity_type = ? AND config_revisions.entity_id = ? )
+  ORDER BY config_revisions.id
+`)
+
+var configRevisionObjectsByIDAndEntityTypeAndEntityID = RegisterStmt(`
+SELECT config_revisions.id, config_revisions.entity_type, config_revisions.entity_id, config_revisions.date, config_revisions.username, config_revisions.protocol, config_revisions.address, config_revisions.data
+  FROM config_revisions
+  WHERE ( config_revisions.id = ? AND config_revisions.entity_type = ? AND config_revisions.entity_id = ? )
+  ORDER BY config_revisions.id
+`)
+
+// configRevisionColumns returns a string of column names to be used with a SELECT statement for the entity.
+// Use this function when building statements to retrieve database entries matching the ConfigRevision entity.
+func configRevisionColumns() string {
+	return "config_revisions.id, config_revisions.entity_type, config_revisions.entity_id, config_revisions.date, config_revisions.username, config_revisions.protocol, config_revisions.address, config_revisions.data"
+}
+
+// getConfigRevisions can be used to run handwritten sql.Stmts to return a slice of objects.
+func getConfigRevisions(ctx context.Context, stmt *sql.Stmt, args ...any) ([]ConfigRevision, error) {
+	objects := make([]ConfigRevision, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		c := ConfigRevision{}
+		err := scan(&c.ID, &c.EntityType, &c.EntityID, &c.Date, &c.Username, &c.Protocol, &c.Address, &c.Data)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, c)
+
+		return nil
+	}
+
+	err := query.SelectObjects(ctx, stmt, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"config_revisions\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// getConfigRevisionsRaw can be used to run handwritten query strings to return a slice of objects.
+func getConfigRevisionsRaw(ctx context.Context, tx *sql.Tx, sql string, args ...any) ([]ConfigRevision, error) {
+	objects := make([]ConfigRevision, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		c := ConfigRevision{}
+		err := scan(&c.ID, &c.EntityType, &c.EntityID, &c.Date, &c.Username, &c.Protocol, &c.Address, &c.Data)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, c)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, sql, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"config_revisions\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// GetConfigRevisions returns all available config_revisions.
+// generator: config_revision GetMany
+func GetConfigRevisions(ctx context.Context, tx *sql.Tx, filters ...ConfigRevisionFilter) ([]ConfigRevision, error) {
+	var err error
+
+	// Result slice.
+	objects := make([]ConfigRevision, 0)
+
+	// Pick the prepared statement and arguments to use based on active criteria.
+	var sqlStmt *sql.Stmt
+	args := []any{}
+	queryParts := [2]string{}
+
+	if len(filters) == 0 {
+		sqlStmt, err = Stmt(tx, configRevisionObjects)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get \"configRevisionObjects\" prepared statement: %w", err)
+		}
+	}
+
+	for i, filter := range filters {
+		if filter.ID != nil && filter.EntityType != nil && filter.EntityID != nil {
+			args = append(args, []any{filter.ID, filter.EntityType, filter.EntityID}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, configRevisionObjectsByIDAndEntityTypeAndEntityID)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"configRevisionObjectsByIDAndEntityTypeAndEntityID\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(configRevisionObjectsByIDAndEntityTypeAndEntityID)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"configRevisionObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.EntityType != nil && filter.EntityID != nil && filter.ID == nil {
+			args = append(args, []any{filter.EntityType, filter.EntityID}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, configRevisionObjectsByEntityTypeAndEntityID)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"configRevisionObjectsByEntityTypeAndEntityID\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(configRevisionObjectsByEntityTypeAndEntityID)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"configRevisionObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.ID != nil && filter.EntityType == nil && filter.EntityID == nil {
+			args = append(args, []any{filter.ID}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, configRevisionObjectsByID)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"configRevisionObjectsByID\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(configRevisionObjectsByID)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"configRevisionObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.ID == nil && filter.EntityType == nil && filter.EntityID == nil {
+			return nil, fmt.Errorf("Cannot filter on empty ConfigRevisionFilter")
+		} else {
+			return nil, fmt.Errorf("No statement exists for the given Filter")
+		}
+	}
+
+	// Select.
+	if sqlStmt != nil {
+		objects, err = getConfigRevisions(ctx, sqlStmt, args...)
+	} else {
+		queryStr := strings.Join(queryParts[:], "ORDER BY")
+		objects, err = getConfigRevisionsRaw(ctx, tx, queryStr, args...)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"config_revisions\" table: %w", err)
+	}
+
+	return objects, nil
+}
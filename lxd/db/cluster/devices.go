@@ -12,10 +12,12 @@ import (
 //go:generate mapper reset -i -b "//go:build linux && cgo && !agent"
 //
 //go:generate mapper stmt -e device objects
+//go:generate mapper stmt -e device objects-by-ReferenceID parents=instance,instance_snapshot,profile
 //go:generate mapper stmt -e device create struct=Device
 //go:generate mapper stmt -e device delete
 //
 //go:generate mapper method -i -e device GetMany
+//go:generate mapper method -i -e device GetMany-by-ReferenceID
 //go:generate mapper method -i -e device Create struct=Device
 //go:generate mapper method -i -e device Update struct=Device
 //go:generate mapper method -i -e device DeleteMany
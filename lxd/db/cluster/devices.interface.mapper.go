@@ -13,6 +13,10 @@ type DeviceGenerated interface {
 	// generator: device GetMany
 	GetDevices(ctx context.Context, tx *sql.Tx, parent string, filters ...DeviceFilter) (map[int][]Device, error)
 
+	// GetDevicesByReferenceID returns all available devices for the parent entity with the given ID.
+	// generator: device GetMany-by-ReferenceID
+	GetDevicesByReferenceID(ctx context.Context, tx *sql.Tx, parent string, referenceID int, filters ...DeviceFilter) ([]Device, error)
+
 	// CreateDevices adds a new device to the database.
 	// generator: device Create
 	CreateDevices(ctx context.Context, tx *sql.Tx, parent string, objects map[string]Device) error
@@ -20,6 +20,27 @@ const deviceObjects = `SELECT %s_devices.id, %s_devices.%s_id, %s_devices.name,
   FROM %s_devices
   ORDER BY %s_devices.name`
 
+var deviceObjectsByReferenceID = map[string]int{
+	"instance": RegisterStmt(`
+SELECT instances_devices.id, instances_devices.instance_id, instances_devices.name, instances_devices.type
+  FROM instances_devices
+  WHERE ( instances_devices.instance_id = ? )
+  ORDER BY instances_devices.name
+`),
+	"instance_snapshot": RegisterStmt(`
+SELECT instances_snapshots_devices.id, instances_snapshots_devices.instance_snapshot_id, instances_snapshots_devices.name, instances_snapshots_devices.type
+  FROM instances_snapshots_devices
+  WHERE ( instances_snapshots_devices.instance_snapshot_id = ? )
+  ORDER BY instances_snapshots_devices.name
+`),
+	"profile": RegisterStmt(`
+SELECT profiles_devices.id, profiles_devices.profile_id, profiles_devices.name, profiles_devices.type
+  FROM profiles_devices
+  WHERE ( profiles_devices.profile_id = ? )
+  ORDER BY profiles_devices.name
+`),
+}
+
 const deviceCreate = `INSERT INTO %s_devices (%s_id, name, type)
   VALUES (?, ?, ?)`
 
@@ -169,6 +190,88 @@ func GetDevices(ctx context.Context, tx *sql.Tx, parent string, filters ...Devic
 	return resultMap, nil
 }
 
+// GetDevicesByReferenceID returns all available devices for the parent entity with the given ID.
+// generator: device GetMany-by-ReferenceID
+func GetDevicesByReferenceID(ctx context.Context, tx *sql.Tx, parent string, referenceID int, filters ...DeviceFilter) ([]Device, error) {
+	var err error
+	var objects []Device
+
+	// Use the prepared statement registered for the parent entity, unless extra criteria are given.
+	stmtCode, ok := deviceObjectsByReferenceID[parent]
+	if ok && len(filters) == 0 {
+		var sqlStmt *sql.Stmt
+		sqlStmt, err = Stmt(tx, stmtCode)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get \"deviceObjectsByReferenceID\" prepared statement: %w", err)
+		}
+
+		objects, err = getDevices(ctx, sqlStmt, parent, referenceID)
+	} else {
+		deviceObjectsLocal := strings.Replace(deviceObjects, "%s_id", fmt.Sprintf("%s_id", parent), -1)
+		fillParent := make([]any, strings.Count(deviceObjectsLocal, "%s"))
+		for i := range fillParent {
+			fillParent[i] = strings.Replace(parent, "_", "s_", -1) + "s"
+		}
+
+		queryStr := fmt.Sprintf(deviceObjectsLocal, fillParent...)
+		queryParts := strings.SplitN(queryStr, "ORDER BY", 2)
+		queryParts[0] += fmt.Sprintf(" WHERE ( %s_id = ? )", parent)
+		args := []any{referenceID}
+		conds := []string{}
+
+		for _, filter := range filters {
+			entries := []string{}
+			if filter.Name != nil {
+				entries = append(entries, "name = ?")
+				args = append(args, filter.Name)
+			}
+
+			if filter.Type != nil {
+				entries = append(entries, "type = ?")
+				args = append(args, filter.Type)
+			}
+
+			if len(entries) == 0 {
+				return nil, fmt.Errorf("Cannot filter on empty DeviceFilter")
+			}
+
+			conds = append(conds, fmt.Sprintf("( %s )", strings.Join(entries, " AND ")))
+		}
+
+		if len(conds) > 0 {
+			queryParts[0] += fmt.Sprintf(" AND ( %s )", strings.Join(conds, " OR "))
+		}
+
+		queryStr = strings.Join(queryParts, " ORDER BY")
+		objects, err = getDevicesRaw(ctx, tx, queryStr, parent, args...)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"%s_devices\" table: %w", parent, err)
+	}
+
+	configFilters := []ConfigFilter{}
+	for _, f := range filters {
+		filter := f.Config
+		if filter != nil {
+			if filter.Key == nil && filter.Value == nil {
+				return nil, fmt.Errorf("Cannot filter on empty ConfigFilter")
+			}
+
+			configFilters = append(configFilters, *filter)
+		}
+	}
+
+	for i := range objects {
+		objects[i].Config, err = GetConfigByReferenceID(ctx, tx, parent+"_device", objects[i].ID, configFilters...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return objects, nil
+}
+
 // CreateDevices adds a new device to the database.
 // generator: device Create
 func CreateDevices(ctx context.Context, tx *sql.Tx, parent string, objects map[string]Device) error {
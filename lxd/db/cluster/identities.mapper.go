@@ -400,7 +400,7 @@ func CreateIdentity(ctx context.Context, tx *sql.Tx, object Identity) (int64, er
 	}
 
 	if exists {
-		return -1, api.StatusErrorf(http.StatusConflict, "This \"identity\" entry already exists")
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "This \"identity\" entry already exists")
 	}
 
 	args := make([]any, 5)
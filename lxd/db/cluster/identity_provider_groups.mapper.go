@@ -275,7 +275,7 @@ func CreateIdentityProviderGroup(ctx context.Context, tx *sql.Tx, object Identit
 	}
 
 	if exists {
-		return -1, api.StatusErrorf(http.StatusConflict, "This \"identity_providers_groups\" entry already exists")
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "This \"identity_providers_groups\" entry already exists")
 	}
 
 	args := make([]any, 1)
@@ -679,13 +679,13 @@ func GetInstances(ctx context.Context, tx *sql.Tx, filters ...InstanceFilter) ([
 // GetInstanceDevices returns all available Instance Devices
 // generator: instance GetMany
 func GetInstanceDevices(ctx context.Context, tx *sql.Tx, instanceID int, filters ...DeviceFilter) (map[string]Device, error) {
-	instanceDevices, err := GetDevices(ctx, tx, "instance", filters...)
+	instanceDevices, err := GetDevicesByReferenceID(ctx, tx, "instance", instanceID, filters...)
 	if err != nil {
 		return nil, err
 	}
 
 	devices := map[string]Device{}
-	for _, ref := range instanceDevices[instanceID] {
+	for _, ref := range instanceDevices {
 		_, ok := devices[ref.Name]
 		if !ok {
 			devices[ref.Name] = ref
@@ -700,16 +700,11 @@ func GetInstanceDevices(ctx context.Context, tx *sql.Tx, instanceID int, filters
 // GetInstanceConfig returns all available Instance Config
 // generator: instance GetMany
 func GetInstanceConfig(ctx context.Context, tx *sql.Tx, instanceID int, filters ...ConfigFilter) (map[string]string, error) {
-	instanceConfig, err := GetConfig(ctx, tx, "instance", filters...)
+	config, err := GetConfigByReferenceID(ctx, tx, "instance", instanceID, filters...)
 	if err != nil {
 		return nil, err
 	}
 
-	config, ok := instanceConfig[instanceID]
-	if !ok {
-		config = map[string]string{}
-	}
-
 	return config, nil
 }
 
@@ -782,7 +777,7 @@ func CreateInstance(ctx context.Context, tx *sql.Tx, object Instance) (int64, er
 	}
 
 	if exists {
-		return -1, api.StatusErrorf(http.StatusConflict, "This \"instances\" entry already exists")
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "This \"instances\" entry already exists")
 	}
 
 	args := make([]any, 11)
@@ -0,0 +1,162 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared"
+)
+
+// IntegrityCheck is a class of orphaned rows of the cluster database, which reference entities that don't exist.
+//
+// Foreign keys normally prevent such rows, but they can be left behind by databases created before foreign keys
+// were enforced or by bugs. The rows are found using the foreign keys declared on the checked tables.
+type IntegrityCheck struct {
+	// Name identifies the check, for example to select the orphans to delete.
+	Name string
+
+	// Description is a human-readable description of the orphaned rows.
+	Description string
+
+	// tables returns whether the table with the given name is checked.
+	tables func(table string) bool
+
+	// parents restricts the foreign keys to the ones referencing these tables. All foreign keys are considered
+	// if empty.
+	parents []string
+}
+
+// IntegrityChecks lists the classes of orphaned rows looked for in the cluster database.
+var IntegrityChecks = []IntegrityCheck{
+	{
+		Name:        "storage-volumes-without-pool",
+		Description: "Storage volumes of storage pools that don't exist",
+		tables:      func(table string) bool { return table == "storage_volumes" },
+		parents:     []string{"storage_pools"},
+	},
+	{
+		Name:        "config-without-entity",
+		Description: "Configuration of entities that don't exist",
+		tables:      func(table string) bool { return strings.HasSuffix(table, "_config") },
+	},
+	{
+		Name:        "devices-without-entity",
+		Description: "Devices of instances, instance snapshots and profiles that don't exist",
+		tables:      func(table string) bool { return strings.HasSuffix(table, "_devices") },
+	},
+	{
+		Name:        "operations-without-member",
+		Description: "Operations of cluster members that don't exist",
+		tables:      func(table string) bool { return table == "operations" },
+		parents:     []string{"nodes"},
+	},
+}
+
+// CountOrphans returns the number of orphaned rows found by each integrity check, keyed by check name.
+func CountOrphans(ctx context.Context, tx *sql.Tx) (map[string]int64, error) {
+	counts := make(map[string]int64, len(IntegrityChecks))
+	for _, check := range IntegrityChecks {
+		conditions, err := orphanConditions(ctx, tx, check)
+		if err != nil {
+			return nil, err
+		}
+
+		for table, condition := range conditions {
+			var count int64
+			err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %q WHERE %s", table, condition)).Scan(&count)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to count orphaned rows of table %q: %w", table, err)
+			}
+
+			counts[check.Name] += count
+		}
+	}
+
+	return counts, nil
+}
+
+// DeleteOrphans deletes the orphaned rows found by the integrity check with the given name and returns how many
+// were deleted.
+func DeleteOrphans(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	for _, check := range IntegrityChecks {
+		if check.Name != name {
+			continue
+		}
+
+		conditions, err := orphanConditions(ctx, tx, check)
+		if err != nil {
+			return -1, err
+		}
+
+		var deleted int64
+		for table, condition := range conditions {
+			result, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %q WHERE %s", table, condition))
+			if err != nil {
+				return -1, fmt.Errorf("Failed to delete orphaned rows of table %q: %w", table, err)
+			}
+
+			n, err := result.RowsAffected()
+			if err != nil {
+				return -1, err
+			}
+
+			deleted += n
+		}
+
+		return deleted, nil
+	}
+
+	return -1, fmt.Errorf("Unknown integrity check %q", name)
+}
+
+// orphanConditions returns the WHERE conditions matching the orphaned rows of each table checked by the given
+// integrity check.
+func orphanConditions(ctx context.Context, tx *sql.Tx, check IntegrityCheck) (map[string]string, error) {
+	tables, err := query.SelectStrings(ctx, tx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get tables: %w", err)
+	}
+
+	conditions := map[string]string{}
+	for _, table := range tables {
+		if !check.tables(table) {
+			continue
+		}
+
+		rows, err := tx.QueryContext(ctx, `SELECT "table", "from", COALESCE("to", 'id') FROM pragma_foreign_key_list(?)`, table)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get foreign keys of table %q: %w", table, err)
+		}
+
+		var clauses []string
+		for rows.Next() {
+			var parent, from, to string
+			err := rows.Scan(&parent, &from, &to)
+			if err != nil {
+				_ = rows.Close()
+				return nil, err
+			}
+
+			if len(check.parents) > 0 && !shared.ValueInSlice(parent, check.parents) {
+				continue
+			}
+
+			clauses = append(clauses, fmt.Sprintf("(%q IS NOT NULL AND %q NOT IN (SELECT %q FROM %q))", from, from, to, parent))
+		}
+
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(clauses) > 0 {
+			conditions[table] = strings.Join(clauses, " OR ")
+		}
+	}
+
+	return conditions, nil
+}
@@ -0,0 +1,71 @@
+package cluster_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/db/cluster"
+)
+
+func TestCountAndDeleteOrphans(t *testing.T) {
+	db, err := cluster.Schema().ExerciseUpdate(cluster.SchemaVersion, nil)
+	require.NoError(t, err)
+
+	// Foreign keys would otherwise prevent inserting orphaned rows.
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec("PRAGMA foreign_keys = OFF")
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+INSERT INTO nodes (id, name, address, schema, api_extensions, arch, description) VALUES (1, 'none', '0.0.0.0', 1, 1, 1, '');
+INSERT INTO storage_pools (id, name, driver, description) VALUES (1, 'default', 'dir', '');
+INSERT INTO instances (id, node_id, name, architecture, type, project_id, description) VALUES (1, 1, 'c1', 1, 0, 1, '');
+INSERT INTO instances_config (instance_id, key, value) VALUES (1, 'limits.cpu', '1'), (2, 'limits.cpu', '2');
+INSERT INTO profiles_config (profile_id, key, value) VALUES (5, 'limits.cpu', '2');
+INSERT INTO instances_devices (id, instance_id, name, type) VALUES (1, 1, 'root', 2), (2, 3, 'root', 2);
+INSERT INTO storage_volumes (id, name, storage_pool_id, node_id, type, description, project_id) VALUES (1, 'v1', 1, 1, 2, '', 1), (2, 'v2', 2, 1, 2, '', 1);
+INSERT INTO operations (id, uuid, node_id, type, project_id) VALUES (1, 'a', 1, 0, 1), (2, 'b', 2, 0, 1);
+`)
+	require.NoError(t, err)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	defer func() { _ = tx.Rollback() }()
+
+	ctx := context.Background()
+
+	counts, err := cluster.CountOrphans(ctx, tx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		"storage-volumes-without-pool": 1,
+		"config-without-entity":        2,
+		"devices-without-entity":       1,
+		"operations-without-member":    1,
+	}, counts)
+
+	deleted, err := cluster.DeleteOrphans(ctx, tx, "config-without-entity")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	deleted, err = cluster.DeleteOrphans(ctx, tx, "operations-without-member")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	counts, err = cluster.CountOrphans(ctx, tx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), counts["config-without-entity"])
+	assert.Equal(t, int64(0), counts["operations-without-member"])
+	assert.Equal(t, int64(1), counts["storage-volumes-without-pool"])
+
+	var config int
+	err = tx.QueryRow("SELECT COUNT(*) FROM instances_config").Scan(&config)
+	require.NoError(t, err)
+	assert.Equal(t, 1, config)
+
+	_, err = cluster.DeleteOrphans(ctx, tx, "unknown")
+	assert.ErrorContains(t, err, "Unknown integrity check")
+}
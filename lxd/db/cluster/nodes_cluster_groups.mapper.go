@@ -178,7 +178,7 @@ func CreateNodeClusterGroup(ctx context.Context, tx *sql.Tx, object NodeClusterG
 	}
 
 	if exists {
-		return -1, api.StatusErrorf(http.StatusConflict, "This \"nodes_clusters_groups\" entry already exists")
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "This \"nodes_clusters_groups\" entry already exists")
 	}
 
 	args := make([]any, 2)
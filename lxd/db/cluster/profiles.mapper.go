@@ -315,13 +315,13 @@ func GetProfiles(ctx context.Context, tx *sql.Tx, filters ...ProfileFilter) ([]P
 // GetProfileDevices returns all available Profile Devices
 // generator: profile GetMany
 func GetProfileDevices(ctx context.Context, tx *sql.Tx, profileID int, filters ...DeviceFilter) (map[string]Device, error) {
-	profileDevices, err := GetDevices(ctx, tx, "profile", filters...)
+	profileDevices, err := GetDevicesByReferenceID(ctx, tx, "profile", profileID, filters...)
 	if err != nil {
 		return nil, err
 	}
 
 	devices := map[string]Device{}
-	for _, ref := range profileDevices[profileID] {
+	for _, ref := range profileDevices {
 		_, ok := devices[ref.Name]
 		if !ok {
 			devices[ref.Name] = ref
@@ -336,16 +336,11 @@ func GetProfileDevices(ctx context.Context, tx *sql.Tx, profileID int, filters .
 // GetProfileConfig returns all available Profile Config
 // generator: profile GetMany
 func GetProfileConfig(ctx context.Context, tx *sql.Tx, profileID int, filters ...ConfigFilter) (map[string]string, error) {
-	profileConfig, err := GetConfig(ctx, tx, "profile", filters...)
+	config, err := GetConfigByReferenceID(ctx, tx, "profile", profileID, filters...)
 	if err != nil {
 		return nil, err
 	}
 
-	config, ok := profileConfig[profileID]
-	if !ok {
-		config = map[string]string{}
-	}
-
 	return config, nil
 }
 
@@ -381,7 +376,7 @@ func CreateProfile(ctx context.Context, tx *sql.Tx, object Profile) (int64, erro
 	}
 
 	if exists {
-		return -1, api.StatusErrorf(http.StatusConflict, "This \"profiles\" entry already exists")
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "This \"profiles\" entry already exists")
 	}
 
 	args := make([]any, 3)
@@ -210,16 +210,11 @@ func GetProjects(ctx context.Context, tx *sql.Tx, filters ...ProjectFilter) ([]P
 // GetProjectConfig returns all available Project Config
 // generator: project GetMany
 func GetProjectConfig(ctx context.Context, tx *sql.Tx, projectID int, filters ...ConfigFilter) (map[string]string, error) {
-	projectConfig, err := GetConfig(ctx, tx, "project", filters...)
+	config, err := GetConfigByReferenceID(ctx, tx, "project", projectID, filters...)
 	if err != nil {
 		return nil, err
 	}
 
-	config, ok := projectConfig[projectID]
-	if !ok {
-		config = map[string]string{}
-	}
-
 	return config, nil
 }
 
@@ -269,7 +264,7 @@ func CreateProject(ctx context.Context, tx *sql.Tx, object Project) (int64, erro
 	}
 
 	if exists {
-		return -1, api.StatusErrorf(http.StatusConflict, "This \"projects\" entry already exists")
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "This \"projects\" entry already exists")
 	}
 
 	args := make([]any, 2)
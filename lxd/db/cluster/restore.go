@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared"
+)
+
+// restoreSkippedTables lists the tables whose rows are kept as they are when restoring a dump, as they describe the
+// members and the operations of the cluster the dump is restored into rather than the one it was taken from.
+var restoreSkippedTables = []string{"schema", "nodes", "operations"}
+
+// RestoreDump replaces the content of the cluster database with the one of the given SQL text dump, as produced by
+// query.Dump. The dump is brought to the current schema version first.
+//
+// The cluster members and operations of the dump aren't restored. Rows of the dump belonging to a member which
+// doesn't exist in the database are moved to the member with the given ID instead, unless that member already has a
+// conflicting row in which case they are dropped.
+func RestoreDump(ctx context.Context, tx *sql.Tx, dump string, memberID int64) error {
+	db, source, current, err := openDump(ctx, dump)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = source.Rollback()
+		_ = db.Close()
+	}()
+
+	for version := current + 1; version <= SchemaVersion; version++ {
+		err = updates[version](ctx, source)
+		if err != nil {
+			return fmt.Errorf("Failed to apply update to schema version %d: %w", version, err)
+		}
+	}
+
+	// Tables are emptied and refilled in no particular order, so only check foreign keys once done.
+	_, err = tx.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON")
+	if err != nil {
+		return fmt.Errorf("Failed to defer foreign key checks: %w", err)
+	}
+
+	tables, err := query.SelectStrings(ctx, tx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return fmt.Errorf("Failed to get tables: %w", err)
+	}
+
+	restored := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if shared.ValueInSlice(table, restoreSkippedTables) {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %q", table))
+		if err != nil {
+			return fmt.Errorf("Failed to empty table %q: %w", table, err)
+		}
+
+		restored = append(restored, table)
+	}
+
+	for _, table := range restored {
+		err = restoreTable(ctx, source, tx, table)
+		if err != nil {
+			return fmt.Errorf("Failed to restore table %q: %w", table, err)
+		}
+
+		columns, err := query.SelectStrings(ctx, tx, "SELECT name FROM pragma_table_info(?)", table)
+		if err != nil {
+			return fmt.Errorf("Failed to get columns of table %q: %w", table, err)
+		}
+
+		if !shared.ValueInSlice("node_id", columns) {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("UPDATE OR IGNORE %q SET node_id = ? WHERE node_id NOT IN (SELECT id FROM nodes)", table), memberID)
+		if err != nil {
+			return fmt.Errorf("Failed to move rows of table %q to member: %w", table, err)
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %q WHERE node_id NOT IN (SELECT id FROM nodes)", table))
+		if err != nil {
+			return fmt.Errorf("Failed to delete conflicting rows of table %q: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// restoreTable copies all the rows of the given table from the source transaction to the target one.
+func restoreTable(ctx context.Context, source *sql.Tx, target *sql.Tx, table string) error {
+	rows, err := source.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %q", table))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = fmt.Sprintf("%q", column)
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %q (%s) VALUES %s", table, strings.Join(quoted, ", "), query.Params(len(columns)))
+
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		err = rows.Scan(pointers...)
+		if err != nil {
+			return err
+		}
+
+		_, err = target.ExecContext(ctx, stmt, values...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
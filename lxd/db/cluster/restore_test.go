@@ -0,0 +1,93 @@
+package cluster_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/query"
+)
+
+func TestRestoreDump(t *testing.T) {
+	ctx := context.Background()
+
+	// Take a dump of a two members cluster using an older schema.
+	source, err := cluster.Schema().ExerciseUpdate(cluster.SchemaVersion-1, nil)
+	require.NoError(t, err)
+
+	_, err = source.Exec(`
+INSERT INTO nodes (id, name, address, schema, api_extensions, arch, description) VALUES (1, 'm1', '10.0.0.1:8443', 1, 1, 1, '');
+INSERT INTO nodes (id, name, address, schema, api_extensions, arch, description) VALUES (2, 'm2', '10.0.0.2:8443', 1, 1, 1, '');
+INSERT INTO projects (id, name, description) VALUES (2, 'p1', '');
+INSERT INTO instances (id, node_id, name, architecture, type, project_id, description) VALUES (1, 1, 'c1', 1, 0, 1, '');
+INSERT INTO instances (id, node_id, name, architecture, type, project_id, description) VALUES (2, 2, 'c2', 1, 0, 2, '');
+INSERT INTO config (key, value) VALUES ('core.proxy_http', 'http://proxy');
+`)
+	require.NoError(t, err)
+
+	tx, err := source.Begin()
+	require.NoError(t, err)
+
+	dump, err := query.Dump(ctx, tx, false)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	// Restore it on a standalone server with different content.
+	target, err := cluster.Schema().ExerciseUpdate(cluster.SchemaVersion, nil)
+	require.NoError(t, err)
+
+	_, err = target.Exec(`
+INSERT INTO nodes (id, name, address, schema, api_extensions, arch, description) VALUES (1, 'none', '0.0.0.0', 1, 1, 1, '');
+INSERT INTO projects (id, name, description) VALUES (3, 'p2', '');
+`)
+	require.NoError(t, err)
+
+	tx, err = target.Begin()
+	require.NoError(t, err)
+
+	err = cluster.RestoreDump(ctx, tx, dump, 1)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	projects, err := queryStrings(target, "SELECT name FROM projects ORDER BY name")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "p1"}, projects)
+
+	// Instances of members which aren't part of the target are moved to the given member.
+	instances, err := queryStrings(target, "SELECT name || ':' || node_id FROM instances ORDER BY name")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c1:1", "c2:1"}, instances)
+
+	members, err := queryStrings(target, "SELECT name FROM nodes")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"none"}, members)
+
+	config, err := queryStrings(target, "SELECT value FROM config WHERE key = 'core.proxy_http'")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://proxy"}, config)
+
+	var version int
+	err = target.QueryRow("SELECT MAX(version) FROM schema").Scan(&version)
+	require.NoError(t, err)
+	assert.Equal(t, cluster.SchemaVersion, version)
+
+	var violations int
+	err = target.QueryRow("SELECT COUNT(*) FROM pragma_foreign_key_check").Scan(&violations)
+	require.NoError(t, err)
+	assert.Zero(t, violations)
+}
+
+func queryStrings(db *sql.DB, stmt string) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	return query.SelectStrings(context.Background(), tx, stmt)
+}
@@ -6,6 +6,20 @@ package cluster
 // modify the database schema, please add a new schema update to update.go
 // and the run 'make update-schema'.
 const freshSchema = `
+CREATE TABLE auth_approvals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    action TEXT NOT NULL,
+    entity TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    username TEXT NOT NULL,
+    protocol TEXT NOT NULL,
+    approver TEXT NOT NULL DEFAULT '',
+    status INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+CREATE INDEX auth_approvals_username_protocol_idx ON auth_approvals (username,
+    protocol);
 CREATE TABLE auth_groups (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     name TEXT NOT NULL,
@@ -41,6 +55,18 @@ CREATE TABLE config (
     value TEXT,
     UNIQUE (key)
 );
+CREATE TABLE config_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    entity_type INTEGER NOT NULL,
+    entity_id INTEGER NOT NULL,
+    date DATETIME NOT NULL,
+    username TEXT NOT NULL,
+    protocol TEXT NOT NULL,
+    address TEXT NOT NULL,
+    data TEXT NOT NULL
+);
+CREATE INDEX config_revisions_entity_type_entity_id ON config_revisions (entity_type,
+    entity_id);
 CREATE TABLE identities (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     auth_method INTEGER NOT NULL,
@@ -99,11 +125,22 @@ CREATE TABLE "images_aliases" (
     image_id INTEGER NOT NULL,
     description TEXT NOT NULL,
     project_id INTEGER NOT NULL,
+    previous_image_id INTEGER REFERENCES images (id) ON DELETE SET NULL,
     UNIQUE (project_id, name),
     FOREIGN KEY (image_id) REFERENCES "images" (id) ON DELETE CASCADE,
     FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
 );
+CREATE TABLE images_aliases_channels (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    image_alias_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    image_id INTEGER NOT NULL,
+    UNIQUE (image_alias_id, name),
+    FOREIGN KEY (image_alias_id) REFERENCES images_aliases (id) ON DELETE CASCADE,
+    FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE
+);
 CREATE INDEX images_aliases_project_id_idx ON images_aliases (project_id);
+CREATE INDEX images_fingerprint_idx ON images (fingerprint);
 CREATE TABLE "images_nodes" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     image_id INTEGER NOT NULL,
@@ -119,6 +156,7 @@ CREATE TABLE "images_profiles" (
 	FOREIGN KEY (profile_id) REFERENCES "profiles" (id) ON DELETE CASCADE,
 	UNIQUE (image_id, profile_id)
 );
+CREATE INDEX images_profiles_profile_id_idx ON images_profiles (profile_id);
 CREATE INDEX images_project_id_idx ON images (project_id);
 CREATE TABLE "images_properties" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
@@ -173,6 +211,11 @@ CREATE TABLE "instances_config" (
     FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE,
     UNIQUE (instance_id, key)
 );
+CREATE TRIGGER instances_delete_config_revisions
+  AFTER DELETE ON instances
+  BEGIN
+    DELETE FROM config_revisions WHERE entity_type = 5 AND entity_id = OLD.id;
+  END;
 CREATE TABLE "instances_devices" (
     id INTEGER primary key AUTOINCREMENT NOT NULL,
     instance_id INTEGER NOT NULL,
@@ -189,7 +232,41 @@ CREATE TABLE "instances_devices_config" (
     FOREIGN KEY (instance_device_id) REFERENCES "instances_devices" (id) ON DELETE CASCADE,
     UNIQUE (instance_device_id, key)
 );
+CREATE TABLE instances_disk_encryption_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_id INTEGER NOT NULL,
+    device TEXT NOT NULL,
+    recovery_key TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE (instance_id, device),
+    FOREIGN KEY (instance_id) REFERENCES instances (id) ON DELETE CASCADE
+);
 CREATE INDEX instances_node_id_idx ON instances (node_id);
+CREATE TABLE instances_pools (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+CREATE TABLE instances_pools_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_pool_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (instance_pool_id, key),
+    FOREIGN KEY (instance_pool_id) REFERENCES instances_pools (id) ON DELETE CASCADE
+);
+CREATE TABLE instances_pools_instances (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_pool_id INTEGER NOT NULL,
+    instance_id INTEGER NOT NULL,
+    acquired INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (instance_id),
+    FOREIGN KEY (instance_pool_id) REFERENCES instances_pools (id) ON DELETE CASCADE,
+    FOREIGN KEY (instance_id) REFERENCES instances (id) ON DELETE CASCADE
+);
 CREATE TABLE "instances_profiles" (
     id INTEGER primary key AUTOINCREMENT NOT NULL,
     instance_id INTEGER NOT NULL,
@@ -199,6 +276,7 @@ CREATE TABLE "instances_profiles" (
     FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE,
     FOREIGN KEY (profile_id) REFERENCES "profiles"(id) ON DELETE CASCADE
 );
+CREATE INDEX instances_profiles_profile_id_idx ON instances_profiles (profile_id);
 CREATE INDEX instances_project_id_and_name_idx ON instances (project_id,
     name);
 CREATE INDEX instances_project_id_and_node_id_and_name_idx ON instances (project_id,
@@ -270,6 +348,33 @@ CREATE TABLE "networks_acls_config" (
     UNIQUE (network_acl_id, key),
     FOREIGN KEY (network_acl_id) REFERENCES "networks_acls" (id) ON DELETE CASCADE
 );
+CREATE TABLE networks_address_pools (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (name)
+);
+CREATE TABLE networks_address_pools_allocations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_address_pool_id INTEGER NOT NULL,
+    address TEXT NOT NULL,
+    instance_id INTEGER,
+    device_name TEXT,
+    network_forward_id INTEGER,
+    UNIQUE (network_address_pool_id, address),
+    FOREIGN KEY (network_address_pool_id) REFERENCES networks_address_pools (id) ON DELETE CASCADE,
+    FOREIGN KEY (instance_id) REFERENCES instances (id) ON DELETE CASCADE,
+    FOREIGN KEY (network_forward_id) REFERENCES networks_forwards (id) ON DELETE CASCADE
+);
+CREATE INDEX networks_address_pools_allocations_instance_id_idx ON networks_address_pools_allocations (instance_id);
+CREATE TABLE networks_address_pools_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_address_pool_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (network_address_pool_id, key),
+    FOREIGN KEY (network_address_pool_id) REFERENCES networks_address_pools (id) ON DELETE CASCADE
+);
 CREATE TABLE "networks_config" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     network_id INTEGER NOT NULL,
@@ -280,6 +385,11 @@ CREATE TABLE "networks_config" (
     FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
     FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
 );
+CREATE TRIGGER networks_delete_config_revisions
+  AFTER DELETE ON networks
+  BEGIN
+    DELETE FROM config_revisions WHERE entity_type = 8 AND entity_id = OLD.id;
+  END;
 CREATE TABLE "networks_forwards" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	network_id INTEGER NOT NULL,
@@ -405,6 +515,7 @@ CREATE TABLE "nodes_cluster_groups" (
     FOREIGN KEY (group_id) REFERENCES cluster_groups (id) ON DELETE CASCADE,
     UNIQUE (node_id, group_id)
 );
+CREATE INDEX nodes_cluster_groups_group_id_idx ON nodes_cluster_groups (group_id);
 CREATE TABLE "nodes_config" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	node_id INTEGER NOT NULL,
@@ -424,6 +535,14 @@ CREATE TABLE "nodes_roles" (
     FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
     UNIQUE (node_id, role)
 );
+CREATE TABLE nodes_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    node_id INTEGER NOT NULL,
+    component TEXT NOT NULL,
+    version TEXT NOT NULL,
+    UNIQUE (node_id, component),
+    FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
 CREATE TABLE "operations" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     uuid TEXT NOT NULL,
@@ -434,6 +553,29 @@ CREATE TABLE "operations" (
     FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
     FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
 );
+CREATE INDEX operations_node_id_idx ON operations (node_id);
+CREATE TABLE patches_barriers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    node_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    reached_at DATETIME NOT NULL,
+    UNIQUE (node_id, name),
+    FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
+CREATE TABLE patches_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    node_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    stage TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    summary TEXT NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME NOT NULL,
+    FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
+CREATE INDEX patches_log_node_id_name ON patches_log (node_id,
+    name);
 CREATE TABLE "profiles" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     name TEXT NOT NULL,
@@ -450,6 +592,11 @@ CREATE TABLE "profiles_config" (
     UNIQUE (profile_id, key),
     FOREIGN KEY (profile_id) REFERENCES "profiles"(id) ON DELETE CASCADE
 );
+CREATE TRIGGER profiles_delete_config_revisions
+  AFTER DELETE ON profiles
+  BEGIN
+    DELETE FROM config_revisions WHERE entity_type = 2 AND entity_id = OLD.id;
+  END;
 CREATE TABLE "profiles_devices" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     profile_id INTEGER NOT NULL,
@@ -481,6 +628,42 @@ CREATE TABLE "projects_config" (
     FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
     UNIQUE (project_id, key)
 );
+CREATE TABLE projects_disk_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    node_id INTEGER NOT NULL,
+    usage INTEGER NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE (project_id, node_id),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE,
+    FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
+CREATE TABLE seccomp_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+CREATE TABLE seccomp_policies_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    seccomp_policy_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (seccomp_policy_id, key),
+    FOREIGN KEY (seccomp_policy_id) REFERENCES seccomp_policies (id) ON DELETE CASCADE
+);
+CREATE TABLE ssh_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    username TEXT NOT NULL,
+    public_key TEXT NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
 CREATE TABLE "storage_buckets" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	name TEXT NOT NULL,
@@ -531,6 +714,11 @@ CREATE TABLE "storage_pools_config" (
     FOREIGN KEY (storage_pool_id) REFERENCES "storage_pools" (id) ON DELETE CASCADE,
     FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
 );
+CREATE TRIGGER storage_pools_delete_config_revisions
+  AFTER DELETE ON storage_pools
+  BEGIN
+    DELETE FROM config_revisions WHERE entity_type = 12 AND entity_id = OLD.id;
+  END;
 CREATE TABLE "storage_pools_nodes" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     storage_pool_id INTEGER NOT NULL,
@@ -642,6 +830,20 @@ CREATE TABLE "storage_volumes_snapshots_config" (
     UNIQUE (storage_volume_snapshot_id, key)
 );
 CREATE UNIQUE INDEX storage_volumes_unique_storage_pool_id_node_id_project_id_name_type ON "storage_volumes" (storage_pool_id, IFNULL(node_id, -1), project_id, name, type);
+CREATE TABLE tombstones (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    entity_type INTEGER NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    date DATETIME NOT NULL,
+    username TEXT NOT NULL,
+    protocol TEXT NOT NULL,
+    address TEXT NOT NULL,
+    data TEXT NOT NULL,
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+CREATE INDEX tombstones_date ON tombstones (date);
+CREATE INDEX tombstones_project_id ON tombstones (project_id);
 CREATE TABLE "warnings" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	node_id INTEGER,
@@ -660,7 +862,9 @@ CREATE TABLE "warnings" (
 	FOREIGN KEY (node_id) REFERENCES "nodes"(id) ON DELETE CASCADE,
 	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
 );
+CREATE INDEX warnings_entity_type_code_entity_id_idx ON warnings (entity_type_code,
+    entity_id);
 CREATE UNIQUE INDEX warnings_unique_node_id_project_id_entity_type_code_entity_id_type_code ON warnings(IFNULL(node_id, -1), IFNULL(project_id, -1), entity_type_code, entity_id, type_code);
 
-INSERT INTO schema (version, updated_at) VALUES (73, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (87, strftime("%s"))
 `
@@ -245,13 +245,13 @@ func GetInstanceSnapshots(ctx context.Context, tx *sql.Tx, filters ...InstanceSn
 // GetInstanceSnapshotDevices returns all available InstanceSnapshot Devices
 // generator: instance_snapshot GetMany
 func GetInstanceSnapshotDevices(ctx context.Context, tx *sql.Tx, instanceSnapshotID int, filters ...DeviceFilter) (map[string]Device, error) {
-	instanceSnapshotDevices, err := GetDevices(ctx, tx, "instance_snapshot", filters...)
+	instanceSnapshotDevices, err := GetDevicesByReferenceID(ctx, tx, "instance_snapshot", instanceSnapshotID, filters...)
 	if err != nil {
 		return nil, err
 	}
 
 	devices := map[string]Device{}
-	for _, ref := range instanceSnapshotDevices[instanceSnapshotID] {
+	for _, ref := range instanceSnapshotDevices {
 		_, ok := devices[ref.Name]
 		if !ok {
 			devices[ref.Name] = ref
@@ -266,16 +266,11 @@ func GetInstanceSnapshotDevices(ctx context.Context, tx *sql.Tx, instanceSnapsho
 // GetInstanceSnapshotConfig returns all available InstanceSnapshot Config
 // generator: instance_snapshot GetMany
 func GetInstanceSnapshotConfig(ctx context.Context, tx *sql.Tx, instanceSnapshotID int, filters ...ConfigFilter) (map[string]string, error) {
-	instanceSnapshotConfig, err := GetConfig(ctx, tx, "instance_snapshot", filters...)
+	config, err := GetConfigByReferenceID(ctx, tx, "instance_snapshot", instanceSnapshotID, filters...)
 	if err != nil {
 		return nil, err
 	}
 
-	config, ok := instanceSnapshotConfig[instanceSnapshotID]
-	if !ok {
-		config = map[string]string{}
-	}
-
 	return config, nil
 }
 
@@ -349,7 +344,7 @@ func CreateInstanceSnapshot(ctx context.Context, tx *sql.Tx, object InstanceSnap
 	}
 
 	if exists {
-		return -1, api.StatusErrorf(http.StatusConflict, "This \"instances_snapshots\" entry already exists")
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "This \"instances_snapshots\" entry already exists")
 	}
 
 	args := make([]any, 7)
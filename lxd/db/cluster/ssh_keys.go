@@ -0,0 +1,57 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t ssh_keys.mapper.go
+//go:generate mapper reset -i -b "//go:build linux && cgo && !agent"
+//
+//go:generate mapper stmt -e SSHKey objects table=ssh_keys
+//go:generate mapper stmt -e SSHKey objects-by-Project table=ssh_keys
+//go:generate mapper stmt -e SSHKey objects-by-Project-and-Name table=ssh_keys
+//go:generate mapper stmt -e SSHKey id table=ssh_keys
+//go:generate mapper stmt -e SSHKey create table=ssh_keys
+//go:generate mapper stmt -e SSHKey update table=ssh_keys
+//go:generate mapper stmt -e SSHKey delete-by-Project-and-Name table=ssh_keys
+//
+//go:generate mapper method -i -e SSHKey ID
+//go:generate mapper method -i -e SSHKey Exists
+//go:generate mapper method -i -e SSHKey GetMany
+//go:generate mapper method -i -e SSHKey GetOne
+//go:generate mapper method -i -e SSHKey Create
+//go:generate mapper method -i -e SSHKey Update
+//go:generate mapper method -i -e SSHKey DeleteOne-by-Project-and-Name
+
+// SSHKey is a value object holding db-related details about an SSH public key of a project.
+type SSHKey struct {
+	ID          int
+	ProjectID   int    `db:"omit=create,update"`
+	Project     string `db:"primary=yes&join=projects.name"`
+	Name        string `db:"primary=yes"`
+	Description string `db:"coalesce=''"`
+	Username    string
+	PublicKey   string
+}
+
+// SSHKeyFilter specifies potential query parameter fields.
+type SSHKeyFilter struct {
+	Project *string
+	Name    *string
+}
+
+// ToAPI returns a LXD API entry.
+func (k SSHKey) ToAPI() api.SSHKey {
+	return api.SSHKey{
+		Name: k.Name,
+		SSHKeyPut: api.SSHKeyPut{
+			Description: k.Description,
+			User:        k.Username,
+			PublicKey:   k.PublicKey,
+		},
+	}
+}
@@ -0,0 +1,39 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SSHKeyGenerated is an interface of generated methods for SSHKey.
+type SSHKeyGenerated interface {
+	// GetSSHKeyID return the ID of the SSHKey with the given key.
+	// generator: SSHKey ID
+	GetSSHKeyID(ctx context.Context, tx *sql.Tx, project string, name string) (int64, error)
+
+	// SSHKeyExists checks if a SSHKey with the given key exists.
+	// generator: SSHKey Exists
+	SSHKeyExists(ctx context.Context, tx *sql.Tx, project string, name string) (bool, error)
+
+	// GetSSHKeys returns all available SSHKeys.
+	// generator: SSHKey GetMany
+	GetSSHKeys(ctx context.Context, tx *sql.Tx, filters ...SSHKeyFilter) ([]SSHKey, error)
+
+	// GetSSHKey returns the SSHKey with the given key.
+	// generator: SSHKey GetOne
+	GetSSHKey(ctx context.Context, tx *sql.Tx, project string, name string) (*SSHKey, error)
+
+	// CreateSSHKey adds a new SSHKey to the database.
+	// generator: SSHKey Create
+	CreateSSHKey(ctx context.Context, tx *sql.Tx, object SSHKey) (int64, error)
+
+	// UpdateSSHKey updates the SSHKey matching the given key parameters.
+	// generator: SSHKey Update
+	UpdateSSHKey(ctx context.Context, tx *sql.Tx, project string, name string, object SSHKey) error
+
+	// DeleteSSHKey deletes the SSHKey matching the given key parameters.
+	// generator: SSHKey DeleteOne-by-Project-and-Name
+	DeleteSSHKey(ctx context.Context, tx *sql.Tx, project string, name string) error
+}
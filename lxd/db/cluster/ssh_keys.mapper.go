@@ -0,0 +1,366 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+// The code below was generated by lxd-generate - DO NOT EDIT!
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+var _ = api.ServerEnvironment{}
+
+var sshKeyObjects = RegisterStmt(`
+SELECT ssh_keys.id, ssh_keys.project_id, projects.name AS project, ssh_keys.name, coalesce(ssh_keys.description, ''), ssh_keys.username, ssh_keys.public_key
+  FROM ssh_keys
+  JOIN projects ON ssh_keys.project_id = projects.id
+  ORDER BY projects.id, ssh_keys.name
+`)
+
+var sshKeyObjectsByProject = RegisterStmt(`
+SELECT ssh_keys.id, ssh_keys.project_id, projects.name AS project, ssh_keys.name, coalesce(ssh_keys.description, ''), ssh_keys.username, ssh_keys.public_key
+  FROM ssh_keys
+  JOIN projects ON ssh_keys.project_id = projects.id
+  WHERE ( project = ? )
+  ORDER BY projects.id, ssh_keys.name
+`)
+
+var sshKeyObjectsByProjectAndName = RegisterStmt(`
+SELECT ssh_keys.id, ssh_keys.project_id, projects.name AS project, ssh_keys.name, coalesce(ssh_keys.description, ''), ssh_keys.username, ssh_keys.public_key
+  FROM ssh_keys
+  JOIN projects ON ssh_keys.project_id = projects.id
+  WHERE ( project = ? AND ssh_keys.name = ? )
+  ORDER BY projects.id, ssh_keys.name
+`)
+
+var sshKeyID = RegisterStmt(`
+SELECT ssh_keys.id FROM ssh_keys
+  JOIN projects ON ssh_keys.project_id = projects.id
+  WHERE projects.name = ? AND ssh_keys.name = ?
+`)
+
+var sshKeyCreate = RegisterStmt(`
+INSERT INTO ssh_keys (project_id, name, description, username, public_key)
+  VALUES ((SELECT projects.id FROM projects WHERE projects.name = ?), ?, ?, ?, ?)
+`)
+
+var sshKeyUpdate = RegisterStmt(`
+UPDATE ssh_keys
+  SET project_id = (SELECT projects.id FROM projects WHERE projects.name = ?), name = ?, description = ?, username = ?, public_key = ?
+ WHERE id = ?
+`)
+
+var sshKeyDeleteByProjectAndName = RegisterStmt(`
+DELETE FROM ssh_keys WHERE project_id = (SELECT projects.id FROM projects WHERE projects.name = ?) AND name = ?
+`)
+
+// GetSSHKeyID return the ID of the SSHKey with the given key.
+// generator: SSHKey ID
+func GetSSHKeyID(ctx context.Context, tx *sql.Tx, project string, name string) (int64, error) {
+	stmt, err := Stmt(tx, sshKeyID)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"sshKeyID\" prepared statement: %w", err)
+	}
+
+	row := stmt.QueryRowContext(ctx, project, name)
+	var id int64
+	err = row.Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, api.StatusErrorf(http.StatusNotFound, "SSHKey not found")
+	}
+
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"sshs_keys\" ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// SSHKeyExists checks if a SSHKey with the given key exists.
+// generator: SSHKey Exists
+func SSHKeyExists(ctx context.Context, tx *sql.Tx, project string, name string) (bool, error) {
+	_, err := GetSSHKeyID(ctx, tx, project, name)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// sshKeyColumns returns a string of column names to be used with a SELECT statement for the entity.
+// Use this function when building statements to retrieve database entries matching the SSHKey entity.
+func sshKeyColumns() string {
+	return "sshs_keys.id, sshs_keys.project_id, projects.name AS project, sshs_keys.name, coalesce(sshs_keys.description, ''), sshs_keys.username, sshs_keys.public_key"
+}
+
+// getSSHKeys can be used to run handwritten sql.Stmts to return a slice of objects.
+func getSSHKeys(ctx context.Context, stmt *sql.Stmt, args ...any) ([]SSHKey, error) {
+	objects := make([]SSHKey, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		s := SSHKey{}
+		err := scan(&s.ID, &s.ProjectID, &s.Project, &s.Name, &s.Description, &s.Username, &s.PublicKey)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, s)
+
+		return nil
+	}
+
+	err := query.SelectObjects(ctx, stmt, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"sshs_keys\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// getSSHKeysRaw can be used to run handwritten query strings to return a slice of objects.
+func getSSHKeysRaw(ctx context.Context, tx *sql.Tx, sql string, args ...any) ([]SSHKey, error) {
+	objects := make([]SSHKey, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		s := SSHKey{}
+		err := scan(&s.ID, &s.ProjectID, &s.Project, &s.Name, &s.Description, &s.Username, &s.PublicKey)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, s)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, sql, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"sshs_keys\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// GetSSHKeys returns all available SSHKeys.
+// generator: SSHKey GetMany
+func GetSSHKeys(ctx context.Context, tx *sql.Tx, filters ...SSHKeyFilter) ([]SSHKey, error) {
+	var err error
+
+	// Result slice.
+	objects := make([]SSHKey, 0)
+
+	// Pick the prepared statement and arguments to use based on active criteria.
+	var sqlStmt *sql.Stmt
+	args := []any{}
+	queryParts := [2]string{}
+
+	if len(filters) == 0 {
+		sqlStmt, err = Stmt(tx, sshKeyObjects)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get \"sshKeyObjects\" prepared statement: %w", err)
+		}
+	}
+
+	for i, filter := range filters {
+		if filter.Project != nil && filter.Name != nil {
+			args = append(args, []any{filter.Project, filter.Name}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, sshKeyObjectsByProjectAndName)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"sshKeyObjectsByProjectAndName\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(sshKeyObjectsByProjectAndName)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"sshKeyObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.Project != nil && filter.Name == nil {
+			args = append(args, []any{filter.Project}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, sshKeyObjectsByProject)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"sshKeyObjectsByProject\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(sshKeyObjectsByProject)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"sshKeyObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.Project == nil && filter.Name == nil {
+			return nil, fmt.Errorf("Cannot filter on empty SSHKeyFilter")
+		} else {
+			return nil, fmt.Errorf("No statement exists for the given Filter")
+		}
+	}
+
+	// Select.
+	if sqlStmt != nil {
+		objects, err = getSSHKeys(ctx, sqlStmt, args...)
+	} else {
+		queryStr := strings.Join(queryParts[:], "ORDER BY")
+		objects, err = getSSHKeysRaw(ctx, tx, queryStr, args...)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"sshs_keys\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// GetSSHKey returns the SSHKey with the given key.
+// generator: SSHKey GetOne
+func GetSSHKey(ctx context.Context, tx *sql.Tx, project string, name string) (*SSHKey, error) {
+	filter := SSHKeyFilter{}
+	filter.Project = &project
+	filter.Name = &name
+
+	objects, err := GetSSHKeys(ctx, tx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"sshs_keys\" table: %w", err)
+	}
+
+	switch len(objects) {
+	case 0:
+		return nil, api.StatusErrorf(http.StatusNotFound, "SSHKey not found")
+	case 1:
+		return &objects[0], nil
+	default:
+		return nil, fmt.Errorf("More than one \"sshs_keys\" entry matches")
+	}
+}
+
+// CreateSSHKey adds a new SSHKey to the database.
+// generator: SSHKey Create
+func CreateSSHKey(ctx context.Context, tx *sql.Tx, object SSHKey) (int64, error) {
+	// Check if a SSHKey with the same key exists.
+	exists, err := SSHKeyExists(ctx, tx, object.Project, object.Name)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to check for duplicates: %w", err)
+	}
+
+	if exists {
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "This \"sshs_keys\" entry already exists")
+	}
+
+	args := make([]any, 5)
+
+	// Populate the statement arguments.
+	args[0] = object.Project
+	args[1] = object.Name
+	args[2] = object.Description
+	args[3] = object.Username
+	args[4] = object.PublicKey
+
+	// Prepared statement to use.
+	stmt, err := Stmt(tx, sshKeyCreate)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"sshKeyCreate\" prepared statement: %w", err)
+	}
+
+	// Execute the statement.
+	result, err := stmt.Exec(args...)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to create \"sshs_keys\" entry: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Failed to fetch \"sshs_keys\" entry ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateSSHKey updates the SSHKey matching the given key parameters.
+// generator: SSHKey Update
+func UpdateSSHKey(ctx context.Context, tx *sql.Tx, project string, name string, object SSHKey) error {
+	id, err := GetSSHKeyID(ctx, tx, project, name)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := Stmt(tx, sshKeyUpdate)
+	if err != nil {
+		return fmt.Errorf("Failed to get \"sshKeyUpdate\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.Exec(object.Project, object.Name, object.Description, object.Username, object.PublicKey, id)
+	if err != nil {
+		return fmt.Errorf("Update \"sshs_keys\" entry failed: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	if n != 1 {
+		return fmt.Errorf("Query updated %d rows instead of 1", n)
+	}
+
+	return nil
+}
+
+// DeleteSSHKey deletes the SSHKey matching the given key parameters.
+// generator: SSHKey DeleteOne-by-Project-and-Name
+func DeleteSSHKey(ctx context.Context, tx *sql.Tx, project string, name string) error {
+	stmt, err := Stmt(tx, sshKeyDeleteByProjectAndName)
+	if err != nil {
+		return fmt.Errorf("Failed to get \"sshKeyDeleteByProjectAndName\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.Exec(project, name)
+	if err != nil {
+		return fmt.Errorf("Delete \"sshs_keys\": %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "SSHKey not found")
+	} else if n > 1 {
+		return fmt.Errorf("Query deleted %d SSHKey rows instead of 1", n)
+	}
+
+	return nil
+}
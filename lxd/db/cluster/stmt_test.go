@@ -0,0 +1,125 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stmtScansAllowed lists the registered statements filtering on some criteria whose query plan is known to scan a
+// whole table, along with the reason why this is acceptable.
+var stmtScansAllowed = map[int]string{
+	authGroupDeleteByName:                     "Rarely used, cascades to tables without an index on the group",
+	identityProviderGroupDeleteByName:         "Rarely used, cascades to tables without an index on the group",
+	projectDeleteByName:                       "Rarely used, cascades to tables without an index on the project",
+	imageObjectsByCached:                      "Boolean filter only used by background tasks",
+	imageObjectsByAutoUpdate:                  "Boolean filter only used by background tasks",
+	instanceObjectsByType:                     "Too few distinct values for an index to help",
+	instanceObjectsByTypeAndName:              "Names are indexed per project, looking up names across projects is rare",
+	instanceObjectsByName:                     "Names are indexed per project, looking up names across projects is rare",
+	profileObjectsByName:                      "Names are indexed per project, looking up names across projects is rare",
+	warningObjectsByProject:                   "Filters on a coalesced column of a joined table",
+	warningObjectsByStatus:                    "Too few distinct values for an index to help",
+	warningObjectsByNodeAndTypeCode:           "Filters on a coalesced column of a joined table",
+	warningObjectsByNodeAndTypeCodeAndProject: "Filters on a coalesced column of a joined table",
+	warningObjectsByNodeAndTypeCodeAndProjectAndEntityTypeAndEntityID: "Filters on a coalesced column of a joined table",
+}
+
+// Registered statements filtering on some criteria must use an index rather than scanning whole tables, so that
+// their cost doesn't grow with the number of rows as the schema and the deployments grow.
+func TestRegisteredStmtsQueryPlans(t *testing.T) {
+	db, err := Schema().ExerciseUpdate(SchemaVersion, nil)
+	require.NoError(t, err)
+
+	for code, stmt := range stmts {
+		if !strings.Contains(stmt, "WHERE") {
+			// Statements listing all the rows of a table are expected to scan it.
+			continue
+		}
+
+		scans, err := stmtScans(db, stmt)
+		require.NoErrorf(t, err, "Failed to get the query plan of statement %q", stmt)
+
+		_, allowed := stmtScansAllowed[code]
+		if allowed {
+			assert.NotEmptyf(t, scans, "Statement %q no longer scans any table, remove it from the allowed list", stmt)
+			continue
+		}
+
+		assert.Emptyf(t, scans, "Statement %q scans tables instead of using an index", stmt)
+	}
+}
+
+// stmtScans returns the steps of the query plan of the given statement scanning a whole table or index.
+func stmtScans(db *sql.DB, stmt string) ([]string, error) {
+	args := make([]any, strings.Count(stmt, "?"))
+	rows, err := db.Query("EXPLAIN QUERY PLAN "+stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	scans := []string{}
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		err := rows.Scan(&id, &parent, &notUsed, &detail)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(detail, "SCAN ") {
+			scans = append(scans, detail)
+		}
+	}
+
+	return scans, rows.Err()
+}
+
+// Config and devices are only returned for the requested parent entity, whether they are loaded with the prepared
+// statement or with extra filters.
+func TestGetInstanceConfigAndDevices(t *testing.T) {
+	db, err := Schema().ExerciseUpdate(SchemaVersion, nil)
+	require.NoError(t, err)
+
+	PreparedStmts, err = PrepareStmts(db, false)
+	require.NoError(t, err)
+
+	defer func() { PreparedStmts = map[int]*sql.Stmt{} }()
+
+	_, err = db.Exec(`
+INSERT INTO nodes (id, name, address, schema, api_extensions, arch, description) VALUES (1, 'none', '0.0.0.0', 1, 1, 1, '');
+INSERT INTO instances (id, node_id, name, architecture, type, project_id, description) VALUES (1, 1, 'c1', 1, 0, 1, '');
+INSERT INTO instances (id, node_id, name, architecture, type, project_id, description) VALUES (2, 1, 'c2', 1, 0, 1, '');
+INSERT INTO instances_config (instance_id, key, value) VALUES (1, 'limits.cpu', '1'), (1, 'limits.memory', '1GiB'), (2, 'limits.cpu', '2');
+INSERT INTO instances_devices (id, instance_id, name, type) VALUES (1, 1, 'root', 2), (2, 2, 'root', 2);
+INSERT INTO instances_devices_config (instance_device_id, key, value) VALUES (1, 'path', '/'), (2, 'path', '/srv');
+`)
+	require.NoError(t, err)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	defer func() { _ = tx.Rollback() }()
+
+	ctx := context.Background()
+
+	config, err := GetInstanceConfig(ctx, tx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"limits.cpu": "1", "limits.memory": "1GiB"}, config)
+
+	key := "limits.cpu"
+	config, err = GetInstanceConfig(ctx, tx, 2, ConfigFilter{Key: &key})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"limits.cpu": "2"}, config)
+
+	devices, err := GetInstanceDevices(ctx, tx, 2)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, map[string]string{"path": "/srv"}, devices["root"].Config)
+}
@@ -0,0 +1,130 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t tombstones.mapper.go
+//go:generate mapper reset -i -b "//go:build linux && cgo && !agent"
+//
+//go:generate mapper stmt -e tombstone objects
+//go:generate mapper stmt -e tombstone objects-by-ID
+//go:generate mapper stmt -e tombstone objects-by-Project
+//go:generate mapper stmt -e tombstone delete-by-ID
+//
+//go:generate mapper method -i -e tombstone GetMany
+//go:generate mapper method -i -e tombstone DeleteOne-by-ID
+
+// Tombstone is a value object holding the recorded definition of a deleted entity.
+type Tombstone struct {
+	ID         int        `db:"primary=yes"`
+	EntityType EntityType `db:"sql=tombstones.entity_type"`
+	Project    string     `db:"join=projects.name"`
+	Name       string
+	Date       time.Time
+	Username   string
+	Protocol   string
+	Address    string
+	Data       string
+}
+
+// TombstoneFilter specifies potential query parameter fields.
+type TombstoneFilter struct {
+	ID      *int
+	Project *string
+}
+
+// ToAPI returns a LXD API entry.
+func (t Tombstone) ToAPI() (*api.Tombstone, error) {
+	tombstone := api.Tombstone{}
+
+	err := json.Unmarshal([]byte(t.Data), &tombstone)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse tombstone %d: %w", t.ID, err)
+	}
+
+	tombstone.ID = int64(t.ID)
+	tombstone.EntityType = string(t.EntityType)
+	tombstone.Project = t.Project
+	tombstone.Name = t.Name
+	tombstone.DeletedAt = t.Date
+
+	if t.Username != "" || t.Protocol != "" || t.Address != "" {
+		tombstone.Requestor = &api.EventLifecycleRequestor{
+			Username: t.Username,
+			Protocol: t.Protocol,
+			Address:  t.Address,
+		}
+	}
+
+	return &tombstone, nil
+}
+
+// CreateTombstoneFromAPI records the definition of a deleted entity.
+func CreateTombstoneFromAPI(ctx context.Context, tx *sql.Tx, entityType EntityType, tombstone api.Tombstone) (int64, error) {
+	entityTypeCode, err := entityType.Value()
+	if err != nil {
+		return -1, err
+	}
+
+	var username, protocol, address string
+	if tombstone.Requestor != nil {
+		username = tombstone.Requestor.Username
+		protocol = tombstone.Requestor.Protocol
+		address = tombstone.Requestor.Address
+	}
+
+	projectName := tombstone.Project
+	name := tombstone.Name
+
+	// The tombstone metadata is stored in dedicated columns.
+	tombstone.ID = 0
+	tombstone.EntityType = ""
+	tombstone.Project = ""
+	tombstone.Name = ""
+	tombstone.DeletedAt = time.Time{}
+	tombstone.ExpiresAt = time.Time{}
+	tombstone.Requestor = nil
+
+	data, err := json.Marshal(tombstone)
+	if err != nil {
+		return -1, err
+	}
+
+	stmt := `
+INSERT INTO tombstones (entity_type, project_id, name, date, username, protocol, address, data)
+  VALUES (?, (SELECT projects.id FROM projects WHERE projects.name = ?), ?, ?, ?, ?, ?, ?)
+`
+
+	result, err := tx.ExecContext(ctx, stmt, entityTypeCode, projectName, name, time.Now().UTC(), username, protocol, address, string(data))
+	if err != nil {
+		return -1, fmt.Errorf("Failed to record tombstone: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+// GetTombstone returns the tombstone with the given ID.
+func GetTombstone(ctx context.Context, tx *sql.Tx, id int) (*Tombstone, error) {
+	tombstones, err := GetTombstones(ctx, tx, TombstoneFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tombstones) == 0 {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Tombstone not found")
+	}
+
+	return &tombstones[0], nil
+}
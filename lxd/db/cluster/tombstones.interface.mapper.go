@@ -0,0 +1,19 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+import (
+	"context"
+	"database/sql"
+)
+
+// TombstoneGenerated is an interface of generated methods for Tombstone.
+type TombstoneGenerated interface {
+	// GetTombstones returns all available tombstones.
+	// generator: tombstone GetMany
+	GetTombstones(ctx context.Context, tx *sql.Tx, filters ...TombstoneFilter) ([]Tombstone, error)
+
+	// DeleteTombstone deletes the tombstone matching the given key parameters.
+	// generator: tombstone DeleteOne-by-ID
+	DeleteTombstone(ctx context.Context, tx *sql.Tx, id int) error
+}
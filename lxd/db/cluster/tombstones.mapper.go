@@ -0,0 +1,217 @@
+//go:build linux && cgo && !agent
+
+package cluster
+
+// The code below was generated by lxd-generate - DO NOT EDIT!
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+var _ = api.ServerEnvironment{}
+
+var tombstoneObjects = RegisterStmt(`
+SELECT tombstones.id, tombstones.entity_type, projects.name AS project, tombstones.name, tombstones.date, tombstones.username, tombstones.protocol, tombstones.address, tombstones.data
+  FROM tombstones
+  JOIN projects ON tombstones.project_id = projects.id
+  ORDER BY tombstones.id
+`)
+
+var tombstoneObjectsByID = RegisterStmt(`
+SELECT tombstones.id, tombstones.entity_type, projects.name AS project, tombstones.name, tombstones.date, tombstones.username, tombstones.protocol, tombstones.address, tombstones.data
+  FROM tombstones
+  JOIN projects ON tombstones.project_id = projects.id
+  WHERE ( tombstones.id = ? )
+  ORDER BY tombstones.id
+`)
+
+var tombstoneObjectsByProject = RegisterStmt(`
+SELECT tombstones.id, tombstones.entity_type, projects.name AS project, tombstones.name, tombstones.date, tombstones.username, tombstones.protocol, tombstones.address, tombstones.data
+  FROM tombstones
+  JOIN projects ON tombstones.project_id = projects.id
+  WHERE ( project = ? )
+  ORDER BY tombstones.id
+`)
+
+var tombstoneDeleteByID = RegisterStmt(`
+DELETE FROM tombstones WHERE id = ?
+`)
+
+// tombstoneColumns returns a string of column names to be used with a SELECT statement for the entity.
+// Use this function when building statements to retrieve database entries matching the Tombstone entity.
+func tombstoneColumns() string {
+	return "tombstones.id, tombstones.entity_type, projects.name AS project, tombstones.name, tombstones.date, tombstones.username, tombstones.protocol, tombstones.address, tombstones.data"
+}
+
+// getTombstones can be used to run handwritten sql.Stmts to return a slice of objects.
+func getTombstones(ctx context.Context, stmt *sql.Stmt, args ...any) ([]Tombstone, error) {
+	objects := make([]Tombstone, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		t := Tombstone{}
+		err := scan(&t.ID, &t.EntityType, &t.Project, &t.Name, &t.Date, &t.Username, &t.Protocol, &t.Address, &t.Data)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, t)
+
+		return nil
+	}
+
+	err := query.SelectObjects(ctx, stmt, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"tombstones\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// getTombstonesRaw can be used to run handwritten query strings to return a slice of objects.
+func getTombstonesRaw(ctx context.Context, tx *sql.Tx, sql string, args ...any) ([]Tombstone, error) {
+	objects := make([]Tombstone, 0)
+
+	dest := func(scan func(dest ...any) error) error {
+		t := Tombstone{}
+		err := scan(&t.ID, &t.EntityType, &t.Project, &t.Name, &t.Date, &t.Username, &t.Protocol, &t.Address, &t.Data)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, t)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, sql, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"tombstones\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// GetTombstones returns all available tombstones.
+// generator: tombstone GetMany
+func GetTombstones(ctx context.Context, tx *sql.Tx, filters ...TombstoneFilter) ([]Tombstone, error) {
+	var err error
+
+	// Result slice.
+	objects := make([]Tombstone, 0)
+
+	// Pick the prepared statement and arguments to use based on active criteria.
+	var sqlStmt *sql.Stmt
+	args := []any{}
+	queryParts := [2]string{}
+
+	if len(filters) == 0 {
+		sqlStmt, err = Stmt(tx, tombstoneObjects)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get \"tombstoneObjects\" prepared statement: %w", err)
+		}
+	}
+
+	for i, filter := range filters {
+		if filter.Project != nil && filter.ID == nil {
+			args = append(args, []any{filter.Project}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, tombstoneObjectsByProject)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"tombstoneObjectsByProject\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(tombstoneObjectsByProject)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"tombstoneObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.ID != nil && filter.Project == nil {
+			args = append(args, []any{filter.ID}...)
+			if len(filters) == 1 {
+				sqlStmt, err = Stmt(tx, tombstoneObjectsByID)
+				if err != nil {
+					return nil, fmt.Errorf("Failed to get \"tombstoneObjectsByID\" prepared statement: %w", err)
+				}
+
+				break
+			}
+
+			query, err := StmtString(tombstoneObjectsByID)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to get \"tombstoneObjects\" prepared statement: %w", err)
+			}
+
+			parts := strings.SplitN(query, "ORDER BY", 2)
+			if i == 0 {
+				copy(queryParts[:], parts)
+				continue
+			}
+
+			_, where, _ := strings.Cut(parts[0], "WHERE")
+			queryParts[0] += "OR" + where
+		} else if filter.ID == nil && filter.Project == nil {
+			return nil, fmt.Errorf("Cannot filter on empty TombstoneFilter")
+		} else {
+			return nil, fmt.Errorf("No statement exists for the given Filter")
+		}
+	}
+
+	// Select.
+	if sqlStmt != nil {
+		objects, err = getTombstones(ctx, sqlStmt, args...)
+	} else {
+		queryStr := strings.Join(queryParts[:], "ORDER BY")
+		objects, err = getTombstonesRaw(ctx, tx, queryStr, args...)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"tombstones\" table: %w", err)
+	}
+
+	return objects, nil
+}
+
+// DeleteTombstone deletes the tombstone matching the given key parameters.
+// generator: tombstone DeleteOne-by-ID
+func DeleteTombstone(ctx context.Context, tx *sql.Tx, id int) error {
+	stmt, err := Stmt(tx, tombstoneDeleteByID)
+	if err != nil {
+		return fmt.Errorf("Failed to get \"tombstoneDeleteByID\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.Exec(id)
+	if err != nil {
+		return fmt.Errorf("Delete \"tombstones\": %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Tombstone not found")
+	} else if n > 1 {
+		return fmt.Errorf("Query deleted %d Tombstone rows instead of 1", n)
+	}
+
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/revert"
 )
 
 // Schema for the cluster database.
@@ -36,6 +37,109 @@ func SchemaDotGo() error {
 // SchemaVersion is the current version of the cluster database schema.
 var SchemaVersion = len(updates)
 
+// UpdateDryRun holds the outcome of applying a schema update to a copy of the cluster database.
+type UpdateDryRun struct {
+	// Version is the schema version the update brings the database to.
+	Version int
+
+	// Changes is the number of rows inserted, updated or deleted by the update. Updates that don't change any
+	// row only alter the schema metadata.
+	Changes int64
+}
+
+// DryRunUpdates loads the given SQL text dump of the cluster database into a throw-away in-memory database and
+// applies to it all the schema updates that the dumped database is missing. It returns the schema version of the
+// dump along with the outcome of each update, leaving the real database untouched.
+func DryRunUpdates(ctx context.Context, dump string) (int, []UpdateDryRun, error) {
+	db, tx, current, err := openDump(ctx, dump)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+		_ = db.Close()
+	}()
+
+	results := make([]UpdateDryRun, 0, SchemaVersion-current)
+	for version := current + 1; version <= SchemaVersion; version++ {
+		before, err := totalChanges(ctx, tx)
+		if err != nil {
+			return -1, nil, err
+		}
+
+		err = updates[version](ctx, tx)
+		if err != nil {
+			return -1, nil, fmt.Errorf("Failed to apply update to schema version %d: %w", version, err)
+		}
+
+		after, err := totalChanges(ctx, tx)
+		if err != nil {
+			return -1, nil, err
+		}
+
+		results = append(results, UpdateDryRun{Version: version, Changes: after - before})
+	}
+
+	return current, results, nil
+}
+
+// openDump loads the given SQL text dump of the cluster database into an in-memory database. It returns the
+// database along with a transaction on it and the schema version of the dump, which must not be newer than the
+// current one. The transaction is never committed, so it's used directly rather than through query.Transaction
+// so that large databases aren't subject to its timeout.
+func openDump(ctx context.Context, dump string) (*sql.DB, *sql.Tx, int, error) {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, nil, -1, fmt.Errorf("Failed to open in-memory database: %w", err)
+	}
+
+	reverter.Add(func() { _ = db.Close() })
+
+	// Every connection to ":memory:" gets its own database, so stick to the one holding the dump.
+	db.SetMaxOpenConns(1)
+
+	_, err = db.ExecContext(ctx, dump)
+	if err != nil {
+		return nil, nil, -1, fmt.Errorf("Failed to load database dump: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, -1, fmt.Errorf("Failed to begin transaction: %w", err)
+	}
+
+	reverter.Add(func() { _ = tx.Rollback() })
+
+	var current int
+	err = tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema").Scan(&current)
+	if err != nil {
+		return nil, nil, -1, fmt.Errorf("Failed to get schema version of database dump: %w", err)
+	}
+
+	if current > SchemaVersion {
+		return nil, nil, -1, fmt.Errorf("Database dump has schema version %d which is newer than %d", current, SchemaVersion)
+	}
+
+	reverter.Success()
+
+	return db, tx, current, nil
+}
+
+// totalChanges returns the number of rows changed on the connection of the given transaction so far.
+func totalChanges(ctx context.Context, tx *sql.Tx) (int64, error) {
+	var changes int64
+	err := tx.QueryRowContext(ctx, "SELECT total_changes()").Scan(&changes)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to count changed rows: %w", err)
+	}
+
+	return changes, nil
+}
+
 var updates = map[int]schema.Update{
 	1:  updateFromV0,
 	2:  updateFromV1,
@@ -110,6 +214,366 @@ var updates = map[int]schema.Update{
 	71: updateFromV70,
 	72: updateFromV71,
 	73: updateFromV72,
+	74: updateFromV73,
+	75: updateFromV74,
+	76: updateFromV75,
+	77: updateFromV76,
+	78: updateFromV77,
+	79: updateFromV78,
+	80: updateFromV79,
+	81: updateFromV80,
+	82: updateFromV81,
+	83: updateFromV82,
+	84: updateFromV83,
+	85: updateFromV84,
+	86: updateFromV85,
+	87: updateFromV86,
+}
+
+func updateFromV86(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE patches_barriers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    node_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    reached_at DATETIME NOT NULL,
+    UNIQUE (node_id, name),
+    FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV85(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE patches_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    node_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    stage TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    summary TEXT NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME NOT NULL,
+    FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
+CREATE INDEX patches_log_node_id_name ON patches_log (node_id, name);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV84(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE instances_disk_encryption_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_id INTEGER NOT NULL,
+    device TEXT NOT NULL,
+    recovery_key TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE (instance_id, device),
+    FOREIGN KEY (instance_id) REFERENCES instances (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// updateFromV83 adds the channels of image aliases and the target an alias had before its last change.
+func updateFromV83(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+ALTER TABLE images_aliases ADD COLUMN previous_image_id INTEGER REFERENCES images (id) ON DELETE SET NULL;
+CREATE TABLE images_aliases_channels (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    image_alias_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    image_id INTEGER NOT NULL,
+    UNIQUE (image_alias_id, name),
+    FOREIGN KEY (image_alias_id) REFERENCES images_aliases (id) ON DELETE CASCADE,
+    FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// updateFromV81 adds the table holding the SSH public keys of projects.
+func updateFromV82(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE nodes_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    node_id INTEGER NOT NULL,
+    component TEXT NOT NULL,
+    version TEXT NOT NULL,
+    UNIQUE (node_id, component),
+    FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV81(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE ssh_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    username TEXT NOT NULL,
+    public_key TEXT NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// updateFromV80 adds the table holding the requests for approval of privileged actions.
+func updateFromV80(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE auth_approvals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    action TEXT NOT NULL,
+    entity TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    username TEXT NOT NULL,
+    protocol TEXT NOT NULL,
+    approver TEXT NOT NULL DEFAULT '',
+    status INTEGER NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+
+CREATE INDEX auth_approvals_username_protocol_idx ON auth_approvals (username, protocol);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// updateFromV79 adds indexes used by registered statements and foreign key cascades that were scanning whole
+// tables.
+func updateFromV79(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE INDEX images_fingerprint_idx ON images (fingerprint);
+CREATE INDEX images_profiles_profile_id_idx ON images_profiles (profile_id);
+CREATE INDEX instances_profiles_profile_id_idx ON instances_profiles (profile_id);
+CREATE INDEX networks_address_pools_allocations_instance_id_idx ON networks_address_pools_allocations (instance_id);
+CREATE INDEX nodes_cluster_groups_group_id_idx ON nodes_cluster_groups (group_id);
+CREATE INDEX operations_node_id_idx ON operations (node_id);
+CREATE INDEX warnings_entity_type_code_entity_id_idx ON warnings (entity_type_code, entity_id);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV78(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE projects_disk_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    node_id INTEGER NOT NULL,
+    usage INTEGER NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE (project_id, node_id),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE,
+    FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV77(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE seccomp_policies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+CREATE TABLE seccomp_policies_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    seccomp_policy_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (seccomp_policy_id, key),
+    FOREIGN KEY (seccomp_policy_id) REFERENCES seccomp_policies (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV76(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE instances_pools (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+CREATE TABLE instances_pools_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_pool_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (instance_pool_id, key),
+    FOREIGN KEY (instance_pool_id) REFERENCES instances_pools (id) ON DELETE CASCADE
+);
+CREATE TABLE instances_pools_instances (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_pool_id INTEGER NOT NULL,
+    instance_id INTEGER NOT NULL,
+    acquired INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (instance_id),
+    FOREIGN KEY (instance_pool_id) REFERENCES instances_pools (id) ON DELETE CASCADE,
+    FOREIGN KEY (instance_id) REFERENCES instances (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV75(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE networks_address_pools (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (name)
+);
+CREATE TABLE networks_address_pools_allocations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_address_pool_id INTEGER NOT NULL,
+    address TEXT NOT NULL,
+    instance_id INTEGER,
+    device_name TEXT,
+    network_forward_id INTEGER,
+    UNIQUE (network_address_pool_id, address),
+    FOREIGN KEY (network_address_pool_id) REFERENCES networks_address_pools (id) ON DELETE CASCADE,
+    FOREIGN KEY (instance_id) REFERENCES instances (id) ON DELETE CASCADE,
+    FOREIGN KEY (network_forward_id) REFERENCES networks_forwards (id) ON DELETE CASCADE
+);
+CREATE TABLE networks_address_pools_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_address_pool_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (network_address_pool_id, key),
+    FOREIGN KEY (network_address_pool_id) REFERENCES networks_address_pools (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV74(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE tombstones (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    entity_type INTEGER NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    date DATETIME NOT NULL,
+    username TEXT NOT NULL,
+    protocol TEXT NOT NULL,
+    address TEXT NOT NULL,
+    data TEXT NOT NULL,
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+CREATE INDEX tombstones_project_id ON tombstones (project_id);
+CREATE INDEX tombstones_date ON tombstones (date);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV73(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE config_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    entity_type INTEGER NOT NULL,
+    entity_id INTEGER NOT NULL,
+    date DATETIME NOT NULL,
+    username TEXT NOT NULL,
+    protocol TEXT NOT NULL,
+    address TEXT NOT NULL,
+    data TEXT NOT NULL
+);
+CREATE INDEX config_revisions_entity_type_entity_id ON config_revisions (entity_type, entity_id);
+CREATE TRIGGER instances_delete_config_revisions
+  AFTER DELETE ON instances
+  BEGIN
+    DELETE FROM config_revisions WHERE entity_type = 5 AND entity_id = OLD.id;
+  END;
+CREATE TRIGGER profiles_delete_config_revisions
+  AFTER DELETE ON profiles
+  BEGIN
+    DELETE FROM config_revisions WHERE entity_type = 2 AND entity_id = OLD.id;
+  END;
+CREATE TRIGGER networks_delete_config_revisions
+  AFTER DELETE ON networks
+  BEGIN
+    DELETE FROM config_revisions WHERE entity_type = 8 AND entity_id = OLD.id;
+  END;
+CREATE TRIGGER storage_pools_delete_config_revisions
+  AFTER DELETE ON storage_pools
+  BEGIN
+    DELETE FROM config_revisions WHERE entity_type = 12 AND entity_id = OLD.id;
+  END;
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func updateFromV72(ctx context.Context, tx *sql.Tx) error {
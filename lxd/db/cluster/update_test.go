@@ -847,3 +847,45 @@ INSERT INTO certificates_projects (certificate_id, project_id) VALUES (1, 4);
 	require.NoError(t, err)
 	assert.Equal(t, c2, metadata.Certificate)
 }
+
+func TestDryRunUpdates(t *testing.T) {
+	schema := cluster.Schema()
+	db, err := schema.ExerciseUpdate(cluster.SchemaVersion-3, nil)
+	require.NoError(t, err)
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+
+	dump, err := query.Dump(context.Background(), tx, false)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	current, results, err := cluster.DryRunUpdates(context.Background(), dump)
+	require.NoError(t, err)
+	assert.Equal(t, cluster.SchemaVersion-3, current)
+	require.Len(t, results, 3)
+
+	for i, result := range results {
+		assert.Equal(t, current+i+1, result.Version)
+	}
+
+	// The dumped database is left untouched.
+	var version int
+	err = db.QueryRow("SELECT MAX(version) FROM schema").Scan(&version)
+	require.NoError(t, err)
+	assert.Equal(t, cluster.SchemaVersion-3, version)
+
+	// Dumps from a newer schema are rejected.
+	_, err = db.Exec("INSERT INTO schema (version, updated_at) VALUES (?, strftime('%s'))", cluster.SchemaVersion+1)
+	require.NoError(t, err)
+
+	tx, err = db.Begin()
+	require.NoError(t, err)
+
+	dump, err = query.Dump(context.Background(), tx, false)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	_, _, err = cluster.DryRunUpdates(context.Background(), dump)
+	assert.ErrorContains(t, err, "newer than")
+}
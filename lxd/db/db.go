@@ -116,6 +116,7 @@ type Cluster struct {
 	nodeID     int64   // Node ID of this LXD instance.
 	mu         sync.RWMutex
 	closingCtx context.Context
+	cache      EntityCache
 }
 
 // OpenCluster creates a new Cluster object for interacting with the dqlite
@@ -366,6 +367,11 @@ func (c *Cluster) transaction(ctx context.Context, f func(context.Context, *Clus
 	}
 
 	return query.Retry(ctx, func(ctx context.Context) error {
+		// Take the generation of the entity cache before starting the transaction, so that the entities read
+		// before a concurrent change gets committed aren't cached.
+		clusterTx.cache = &c.cache
+		clusterTx.cacheGeneration = c.cache.generation.Load()
+
 		txFunc := func(ctx context.Context, tx *sql.Tx) error {
 			clusterTx.tx = tx
 			return f(ctx, clusterTx)
@@ -384,6 +390,11 @@ func (c *Cluster) transaction(ctx context.Context, f func(context.Context, *Clus
 	})
 }
 
+// EntityCache returns the cache of the entities of the cluster database.
+func (c *Cluster) EntityCache() *EntityCache {
+	return &c.cache
+}
+
 // NodeID sets the node NodeID associated with this cluster instance. It's used for
 // backward-compatibility of all db-related APIs that were written before
 // clustering and don't accept a node NodeID, so in those cases we automatically
@@ -417,23 +428,17 @@ func (c *Cluster) Begin() (*sql.Tx, error) {
 }
 
 func begin(db *sql.DB) (*sql.Tx, error) {
-	for i := 0; i < 1000; i++ {
-		tx, err := db.Begin()
-		if err == nil {
-			return tx, nil
-		}
-
-		if !query.IsRetriableError(err) {
-			logger.Debugf("DbBegin: error %q", err)
-			return nil, err
-		}
-
-		time.Sleep(30 * time.Millisecond)
+	var tx *sql.Tx
+	err := query.Retry(context.Background(), func(ctx context.Context) error {
+		var err error
+		tx, err = db.Begin()
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	logger.Debugf("DbBegin: DB still locked")
-	logger.Debugf(logger.GetStack())
-	return nil, fmt.Errorf("DB is locked")
+	return tx, nil
 }
 
 // TxCommit commits the given transaction.
@@ -0,0 +1,147 @@
+// Package dberr defines the errors returned by the database layer.
+//
+// Callers check them with errors.Is against the ErrNotFound, ErrConflict and ErrConstraint sentinels, or with
+// errors.As against the typed errors to get the details, rather than matching error messages.
+package dberr
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is matched by errors.Is for any NotFoundError.
+	ErrNotFound = errors.New("Not found")
+
+	// ErrConflict is matched by errors.Is for any ConflictError.
+	ErrConflict = errors.New("Already exists")
+
+	// ErrConstraint is matched by errors.Is for any ConstraintError.
+	ErrConstraint = errors.New("Constraint violation")
+)
+
+// SQLite result codes of constraint violations, see https://www.sqlite.org/rescode.html.
+// Both the dqlite driver and the local SQLite driver report the extended result codes.
+const (
+	sqliteConstraint           = 19
+	sqliteConstraintPrimaryKey = sqliteConstraint | (6 << 8)
+	sqliteConstraintUnique     = sqliteConstraint | (8 << 8)
+)
+
+// NotFoundError is returned when an entity doesn't exist in the database.
+type NotFoundError struct {
+	// EntityType is the human readable type of the entity, for example "Storage pool".
+	EntityType string
+
+	// Name of the entity, optional.
+	Name string
+
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// Error implements the error interface.
+func (e NotFoundError) Error() string {
+	if e.EntityType == "" {
+		return ErrNotFound.Error()
+	}
+
+	if e.Name == "" {
+		return fmt.Sprintf("%s not found", e.EntityType)
+	}
+
+	return fmt.Sprintf("%s %q not found", e.EntityType, e.Name)
+}
+
+// Is returns true if target is ErrNotFound.
+func (e NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Unwrap returns the underlying error.
+func (e NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// ConflictError is returned when an entity already exists in the database, which includes violations of
+// unique and primary key constraints.
+type ConflictError struct {
+	// EntityType is the human readable type of the entity, for example "Storage pool".
+	// It is empty when the error was converted from a SQLite error.
+	EntityType string
+
+	// Name of the entity, optional.
+	Name string
+
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// Error implements the error interface.
+func (e ConflictError) Error() string {
+	if e.EntityType == "" {
+		if e.Err != nil {
+			return e.Err.Error()
+		}
+
+		return ErrConflict.Error()
+	}
+
+	if e.Name == "" {
+		return fmt.Sprintf("%s already exists", e.EntityType)
+	}
+
+	return fmt.Sprintf("%s %q already exists", e.EntityType, e.Name)
+}
+
+// Is returns true if target is ErrConflict.
+func (e ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Unwrap returns the underlying error.
+func (e ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// ConstraintError is returned when a statement violates a constraint other than a unique or primary key one,
+// for example a foreign key, a NOT NULL or a CHECK constraint.
+type ConstraintError struct {
+	// Code is the extended SQLite result code.
+	Code int
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e ConstraintError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+
+	return ErrConstraint.Error()
+}
+
+// Is returns true if target is ErrConstraint.
+func (e ConstraintError) Is(target error) bool {
+	return target == ErrConstraint
+}
+
+// Unwrap returns the underlying error.
+func (e ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// FromSQLiteCode returns the typed error matching the given SQLite result code, wrapping err.
+// If the code isn't a constraint violation, err is returned as is.
+func FromSQLiteCode(code int, err error) error {
+	switch {
+	case code == sqliteConstraintUnique || code == sqliteConstraintPrimaryKey:
+		return ConflictError{Err: err}
+	case code&0xff == sqliteConstraint:
+		return ConstraintError{Code: code, Err: err}
+	}
+
+	return err
+}
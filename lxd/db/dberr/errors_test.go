@@ -0,0 +1,49 @@
+package dberr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/db/dberr"
+)
+
+func TestNotFoundError(t *testing.T) {
+	err := fmt.Errorf("Failed loading pool: %w", dberr.NotFoundError{EntityType: "Storage pool", Name: "default"})
+	assert.EqualError(t, err, `Failed loading pool: Storage pool "default" not found`)
+	assert.ErrorIs(t, err, dberr.ErrNotFound)
+	assert.NotErrorIs(t, err, dberr.ErrConflict)
+
+	var notFound dberr.NotFoundError
+	assert.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "Storage pool", notFound.EntityType)
+
+	assert.EqualError(t, dberr.NotFoundError{EntityType: "Instance"}, "Instance not found")
+	assert.EqualError(t, dberr.NotFoundError{}, "Not found")
+}
+
+func TestFromSQLiteCode(t *testing.T) {
+	cause := errors.New("UNIQUE constraint failed: networks.name")
+
+	// SQLITE_CONSTRAINT_UNIQUE and SQLITE_CONSTRAINT_PRIMARYKEY.
+	for _, code := range []int{2067, 1555} {
+		err := dberr.FromSQLiteCode(code, cause)
+		assert.ErrorIs(t, err, dberr.ErrConflict)
+		assert.ErrorIs(t, err, cause)
+		assert.EqualError(t, err, cause.Error())
+	}
+
+	// SQLITE_CONSTRAINT_FOREIGNKEY.
+	err := dberr.FromSQLiteCode(787, cause)
+	assert.ErrorIs(t, err, dberr.ErrConstraint)
+	assert.NotErrorIs(t, err, dberr.ErrConflict)
+
+	var constraint dberr.ConstraintError
+	assert.True(t, errors.As(err, &constraint))
+	assert.Equal(t, 787, constraint.Code)
+
+	// SQLITE_BUSY.
+	assert.Equal(t, cause, dberr.FromSQLiteCode(5, cause))
+}
@@ -0,0 +1,277 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// entityCacheTTL is how long a cached entity is used before it is loaded again from the database.
+// It limits how long a stale entity can be used if an invalidation is missed, for example while the connection
+// to another cluster member is lost.
+const entityCacheTTL = time.Minute
+
+// entityCacheKey identifies a cached entity.
+type entityCacheKey struct {
+	project string
+	name    string
+}
+
+// entityCacheEntry is a cached entity.
+type entityCacheEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+// entityCacheStore holds the cached entities of a single type.
+type entityCacheStore[T any] struct {
+	entries map[entityCacheKey]entityCacheEntry[T]
+	mu      sync.Mutex
+}
+
+// get returns the cached entity for the key, if any and not expired.
+func (s *entityCacheStore[T]) get(key entityCacheKey) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		var empty T
+		return empty, false
+	}
+
+	return entry.value, true
+}
+
+// set caches the entity for the key, unless the cache was invalidated since the generation was taken.
+func (s *entityCacheStore[T]) set(key entityCacheKey, value T, generation uint64, current *atomic.Uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current.Load() != generation {
+		return
+	}
+
+	if s.entries == nil {
+		s.entries = map[entityCacheKey]entityCacheEntry[T]{}
+	}
+
+	s.entries[key] = entityCacheEntry[T]{value: value, expiresAt: time.Now().Add(entityCacheTTL)}
+}
+
+// delete removes the cached entities with the given name from all projects (or all entities if name is empty).
+func (s *entityCacheStore[T]) delete(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.entries {
+		if name == "" || key.name == name {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// cachedProfile is a cached profile along with its ID.
+type cachedProfile struct {
+	id      int
+	profile api.Profile
+}
+
+// cachedNetwork is a cached network along with its ID and cluster member info.
+type cachedNetwork struct {
+	id      int64
+	network api.Network
+	nodes   map[int64]NetworkNode
+}
+
+// EntityCache is a read-through cache of the projects, profiles and networks of the cluster database.
+// Those entities are read on most requests but rarely change.
+//
+// Cached entities are invalidated by the lifecycle events of all cluster members (see HandleEvent). As those
+// events are only sent once a change is complete, the code changing an entity must also invalidate it locally
+// (see Invalidate) before relying on its new value.
+type EntityCache struct {
+	// generation is incremented on each invalidation. Entities loaded by transactions started before the
+	// invalidation are not cached, as they may have been read before the change was committed.
+	generation atomic.Uint64
+
+	projects entityCacheStore[api.Project]
+	profiles entityCacheStore[cachedProfile]
+	networks entityCacheStore[cachedNetwork]
+}
+
+// Invalidate removes the cached entities of the given type and name, in all projects.
+// If name is empty, all the cached entities of that type are removed. Projects can contain profiles and
+// networks, so invalidating a project removes all the cached entities.
+func (c *EntityCache) Invalidate(entityType entity.Type, name string) {
+	c.generation.Add(1)
+
+	switch entityType {
+	case entity.TypeProject:
+		c.projects.delete(name)
+		c.profiles.delete("")
+		c.networks.delete("")
+	case entity.TypeProfile:
+		c.profiles.delete(name)
+	case entity.TypeNetwork:
+		c.networks.delete(name)
+	}
+}
+
+// HandleEvent invalidates the cached entities changed by a lifecycle event.
+func (c *EntityCache) HandleEvent(event api.Event) {
+	if event.Type != api.EventTypeLifecycle {
+		return
+	}
+
+	var lifecycle api.EventLifecycle
+	err := json.Unmarshal(event.Metadata, &lifecycle)
+	if err != nil {
+		return
+	}
+
+	u, err := url.Parse(lifecycle.Source)
+	if err != nil {
+		return
+	}
+
+	entityType, _, _, pathArgs, err := entity.ParseURL(*u)
+	if err != nil || len(pathArgs) == 0 {
+		return
+	}
+
+	if !slices.Contains([]entity.Type{entity.TypeProject, entity.TypeProfile, entity.TypeNetwork}, entityType) {
+		return
+	}
+
+	logger.Debug("Invalidating cached entity", logger.Ctx{"entityType": entityType, "name": pathArgs[0], "action": lifecycle.Action})
+	c.Invalidate(entityType, pathArgs[0])
+
+	// Renames are reported with the new name as source.
+	oldName, ok := lifecycle.Context["old_name"].(string)
+	if ok {
+		c.Invalidate(entityType, oldName)
+	}
+}
+
+// GetCachedProject returns the project with the given name, from the entity cache when possible.
+func (c *ClusterTx) GetCachedProject(ctx context.Context, name string) (*api.Project, error) {
+	key := entityCacheKey{name: name}
+
+	if c.cache != nil {
+		p, ok := c.cache.projects.get(key)
+		if ok {
+			return cloneProject(p), nil
+		}
+	}
+
+	dbProject, err := cluster.GetProject(ctx, c.tx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := dbProject.ToAPI(ctx, c.tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		c.cache.projects.set(key, *cloneProject(*p), c.cacheGeneration, &c.cache.generation)
+	}
+
+	return p, nil
+}
+
+// getCachedProfile returns the given profile, from the entity cache when possible.
+func (c *ClusterTx) getCachedProfile(ctx context.Context, profile cluster.Profile) (*api.Profile, error) {
+	key := entityCacheKey{project: profile.Project, name: profile.Name}
+
+	if c.cache != nil {
+		cached, ok := c.cache.profiles.get(key)
+		if ok && cached.id == profile.ID {
+			return cloneProfile(cached.profile), nil
+		}
+	}
+
+	p, err := profile.ToAPI(ctx, c.tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		c.cache.profiles.set(key, cachedProfile{id: profile.ID, profile: *cloneProfile(*p)}, c.cacheGeneration, &c.cache.generation)
+	}
+
+	return p, nil
+}
+
+// GetCachedNetwork returns the network with the given project and name, from the entity cache when possible.
+// Only fully created networks are cached, as the state of the other ones changes without lifecycle events.
+// Returns network ID, network info, and network cluster member info.
+func (c *ClusterTx) GetCachedNetwork(ctx context.Context, projectName string, networkName string) (int64, *api.Network, map[int64]NetworkNode, error) {
+	key := entityCacheKey{project: projectName, name: networkName}
+
+	if c.cache != nil {
+		cached, ok := c.cache.networks.get(key)
+		if ok {
+			return cached.id, cloneNetwork(cached.network), maps.Clone(cached.nodes), nil
+		}
+	}
+
+	id, network, nodes, err := c.GetNetworkInAnyState(ctx, projectName, networkName)
+	if err != nil {
+		return -1, nil, nil, err
+	}
+
+	if c.cache != nil && network.Status == api.NetworkStatusCreated {
+		c.cache.networks.set(key, cachedNetwork{id: id, network: *cloneNetwork(*network), nodes: maps.Clone(nodes)}, c.cacheGeneration, &c.cache.generation)
+	}
+
+	return id, network, nodes, nil
+}
+
+// cloneProject returns a copy of the project that doesn't share any map or slice with it.
+func cloneProject(p api.Project) *api.Project {
+	p.Config = maps.Clone(p.Config)
+	p.UsedBy = slices.Clone(p.UsedBy)
+
+	return &p
+}
+
+// cloneProfile returns a copy of the profile that doesn't share any map or slice with it.
+func cloneProfile(p api.Profile) *api.Profile {
+	p.Config = maps.Clone(p.Config)
+	p.UsedBy = slices.Clone(p.UsedBy)
+
+	if p.Devices != nil {
+		devices := make(map[string]map[string]string, len(p.Devices))
+		for name, device := range p.Devices {
+			devices[name] = maps.Clone(device)
+		}
+
+		p.Devices = devices
+	}
+
+	return &p
+}
+
+// cloneNetwork returns a copy of the network that doesn't share any map or slice with it.
+func cloneNetwork(n api.Network) *api.Network {
+	n.Config = maps.Clone(n.Config)
+	n.UsedBy = slices.Clone(n.UsedBy)
+	n.Locations = slices.Clone(n.Locations)
+
+	return &n
+}
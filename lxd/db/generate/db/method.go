@@ -73,6 +73,10 @@ func (m *Method) Generate(buf *file.Buffer) error {
 	if mapping.Type != EntityTable {
 		switch operation(m.kind) {
 		case "GetMany":
+			if m.kind == "GetMany-by-ReferenceID" {
+				return m.getManyByReferenceID(buf)
+			}
+
 			return m.getMany(buf)
 		case "Create":
 			return m.create(buf, false)
@@ -487,6 +491,141 @@ func (m *Method) getMany(buf *file.Buffer) error {
 	return nil
 }
 
+// getManyByReferenceID generates a method returning the rows of a reference table that belong to a single parent
+// entity, using the statement registered for the parent when no filter is given.
+func (m *Method) getManyByReferenceID(buf *file.Buffer) error {
+	mapping, err := Parse(m.pkg, lex.Camel(m.entity), m.kind)
+	if err != nil {
+		return fmt.Errorf("Parse entity struct: %w", err)
+	}
+
+	err = m.signature(buf, false)
+	if err != nil {
+		return err
+	}
+
+	defer m.end(buf)
+
+	tableName := entityTable(m.entity, m.config["table"])
+	stmtVar := stmtCodeVar(m.entity, "objects")
+	stmtLocal := stmtVar + "Local"
+	stmtByReferenceID := stmtCodeVar(m.entity, "objects", "ReferenceID")
+
+	buf.L("var err error")
+	buf.L("var objects []%s", mapping.Name)
+	buf.N()
+	buf.L("// Use the prepared statement registered for the parent entity, unless extra criteria are given.")
+	buf.L("stmtCode, ok := %s[parent]", stmtByReferenceID)
+	buf.L("if ok && len(filters) == 0 {")
+	buf.L("var sqlStmt *sql.Stmt")
+	if m.db == "" {
+		buf.L("sqlStmt, err = Stmt(tx, stmtCode)")
+	} else {
+		buf.L("sqlStmt, err = %s.Stmt(tx, stmtCode)", m.db)
+	}
+
+	m.ifErrNotNil(buf, true, "nil", fmt.Sprintf(`fmt.Errorf("Failed to get \"%s\" prepared statement: %%w", err)`, stmtByReferenceID))
+	buf.L("objects, err = get%s(ctx, sqlStmt, parent, referenceID)", lex.Plural(mapping.Name))
+	buf.L("} else {")
+	buf.L("%s := strings.Replace(%s, \"%%s_id\", fmt.Sprintf(\"%%s_id\", parent), -1)", stmtLocal, stmtVar)
+	buf.L("fillParent := make([]any, strings.Count(%s, \"%%s\"))", stmtLocal)
+	buf.L("for i := range fillParent {")
+	buf.L("fillParent[i] = strings.Replace(parent, \"_\", \"s_\", -1) + \"s\"")
+	buf.L("}")
+	buf.N()
+	buf.L("queryStr := fmt.Sprintf(%s, fillParent...)", stmtLocal)
+	buf.L("queryParts := strings.SplitN(queryStr, \"ORDER BY\", 2)")
+	buf.L("queryParts[0] += fmt.Sprintf(\" WHERE ( %%s_id = ? )\", parent)")
+	buf.L("args := []any{referenceID}")
+	buf.L("conds := []string{}")
+	buf.N()
+	buf.L("for _, filter := range filters {")
+	buf.L("entries := []string{}")
+	for _, filter := range mapping.Filters {
+		// Skip over filter fields that are themselves filters for a referenced table.
+		found := false
+		for _, refField := range mapping.RefFields() {
+			if filter.Type.Name == entityFilter(refField.Name) {
+				found = true
+				break
+			}
+		}
+
+		if found {
+			continue
+		}
+
+		buf.L("if filter.%s != nil {", filter.Name)
+		buf.L("entries = append(entries, \"%s = ?\")", lex.Snake(filter.Name))
+		buf.L("args = append(args, filter.%s)", filter.Name)
+		buf.L("}")
+		buf.N()
+	}
+
+	buf.L("if len(entries) == 0 {")
+	buf.L("return nil, fmt.Errorf(\"Cannot filter on empty %s\")", entityFilter(mapping.Name))
+	buf.L("}")
+	buf.N()
+	buf.L("conds = append(conds, fmt.Sprintf(\"( %%s )\", strings.Join(entries, \" AND \")))")
+	buf.L("}")
+	buf.N()
+	buf.L("if len(conds) > 0 {")
+	buf.L("queryParts[0] += fmt.Sprintf(\" AND ( %%s )\", strings.Join(conds, \" OR \"))")
+	buf.L("}")
+	buf.N()
+	buf.L("queryStr = strings.Join(queryParts, \" ORDER BY\")")
+	buf.L("objects, err = get%sRaw(ctx, tx, queryStr, parent, args...)", lex.Plural(mapping.Name))
+	buf.L("}")
+	buf.N()
+	m.ifErrNotNil(buf, true, "nil", fmt.Sprintf(`fmt.Errorf("Failed to fetch from \"%%s_%s\" table: %%w", parent, err)`, tableName))
+
+	for _, field := range mapping.RefFields() {
+		refStruct := lex.Singular(field.Name)
+		refVar := lex.Minuscule(refStruct)
+		refMapping, err := Parse(m.pkg, refStruct, "")
+		if err != nil {
+			return fmt.Errorf("Could not find definition for reference struct %q in package %q: %w", refStruct, m.db, err)
+		}
+
+		if refMapping.Type != MapTable {
+			return fmt.Errorf("Reference struct %q is not supported when querying by reference ID", refStruct)
+		}
+
+		buf.L("%sFilters := []%s{}", refVar, entityFilter(refStruct))
+		buf.L("for _, f := range filters {")
+		buf.L("filter := f.%s", refStruct)
+		buf.L("if filter != nil {")
+		buf.L("if %s {", activeCriteria(nil, FieldNames(refMapping.Filters)))
+		buf.L("return nil, fmt.Errorf(\"Cannot filter on empty %s\")", entityFilter(refMapping.Name))
+		buf.L("}")
+		buf.N()
+		buf.L("%sFilters = append(%sFilters, *filter)", refVar, refVar)
+		buf.L("}")
+		buf.L("}")
+		buf.N()
+		buf.L("for i := range objects {")
+		// A reference table should let its child reference know about its parent.
+		buf.L("objects[i].%s, err = Get%sByReferenceID(ctx, tx, parent+\"_%s\", objects[i].ID, %sFilters...)", field.Name, lex.Plural(refStruct), m.entity, refVar)
+		m.ifErrNotNil(buf, false, "nil", "err")
+		buf.L("}")
+		buf.N()
+	}
+
+	switch mapping.Type {
+	case ReferenceTable:
+		buf.L("return objects, nil")
+	case MapTable:
+		buf.L("result := make(map[string]string, len(objects))")
+		buf.L("for _, object := range objects {")
+		buf.L("result[object.Key] = object.Value")
+		buf.L("}")
+		buf.N()
+		buf.L("return result, nil")
+	}
+
+	return nil
+}
+
 func (m *Method) getRefs(buf *file.Buffer, refMapping *Mapping) error {
 	m.ref = refMapping.Name
 	err := m.signature(buf, false)
@@ -507,10 +646,10 @@ func (m *Method) getRefs(buf *file.Buffer, refMapping *Mapping) error {
 
 	switch refMapping.Type {
 	case ReferenceTable:
-		buf.L("%s, err := Get%s(ctx, tx, \"%s\", filters...)", refParentList, lex.Plural(refStruct), m.entity)
+		buf.L("%s, err := Get%sByReferenceID(ctx, tx, \"%s\", %sID, filters...)", refParentList, lex.Plural(refStruct), m.entity, refParent)
 		m.ifErrNotNil(buf, true, "nil", "err")
 		buf.L("%s := map[string]%s{}", refList, refStruct)
-		buf.L("for _, ref := range %s[%sID] {", refParentList, refParent)
+		buf.L("for _, ref := range %s {", refParentList)
 		buf.L("_, ok := %s[ref.%s]", refList, refMapping.Identifier().Name)
 		buf.L("if !ok {")
 		buf.L("%s[ref.%s] = ref", refList, refMapping.Identifier().Name)
@@ -520,13 +659,8 @@ func (m *Method) getRefs(buf *file.Buffer, refMapping *Mapping) error {
 		buf.L("}")
 		buf.N()
 	case MapTable:
-		buf.L("%s, err := Get%s(ctx, tx, \"%s\", filters...)", refParentList, lex.Plural(refStruct), m.entity)
+		buf.L("%s, err := Get%sByReferenceID(ctx, tx, \"%s\", %sID, filters...)", refList, lex.Plural(refStruct), m.entity, refParent)
 		m.ifErrNotNil(buf, true, "nil", "err")
-		buf.L("%s, ok := %s[%sID]", refList, refParentList, refParent)
-		buf.L("if !ok {")
-		buf.L("%s = map[string]string{}", refList)
-		buf.L("}")
-		buf.N()
 	}
 
 	buf.L("return %s, nil", refList)
@@ -745,7 +879,7 @@ func (m *Method) create(buf *file.Buffer, replace bool) error {
 				buf.L("exists, err := %sExists(ctx, tx, %s)", lex.Camel(m.entity), strings.Join(nkParams, ", "))
 				m.ifErrNotNil(buf, true, "-1", "fmt.Errorf(\"Failed to check for duplicates: %w\", err)")
 				buf.L("if exists {")
-				buf.L(`        return -1, api.StatusErrorf(http.StatusConflict, "This \"%s\" entry already exists")`, entityTable(m.entity, m.config["table"]))
+				buf.L(`        return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "This \"%s\" entry already exists")`, entityTable(m.entity, m.config["table"]))
 				buf.L("}")
 				buf.N()
 			}
@@ -1246,9 +1380,15 @@ func (m *Method) signature(buf *file.Buffer, isInterface bool) error {
 	case ReferenceTable:
 		switch operation(m.kind) {
 		case "GetMany":
-			comment = fmt.Sprintf("returns all available %s for the parent entity.", lex.Plural(m.entity))
-			args += fmt.Sprintf("parent string, filters ...%s", entityFilter(m.entity))
-			rets = fmt.Sprintf("(map[int][]%s, error)", mapping.Name)
+			if m.kind == "GetMany-by-ReferenceID" {
+				comment = fmt.Sprintf("returns all available %s for the parent entity with the given ID.", lex.Plural(m.entity))
+				args += fmt.Sprintf("parent string, referenceID int, filters ...%s", entityFilter(m.entity))
+				rets = fmt.Sprintf("([]%s, error)", mapping.Name)
+			} else {
+				comment = fmt.Sprintf("returns all available %s for the parent entity.", lex.Plural(m.entity))
+				args += fmt.Sprintf("parent string, filters ...%s", entityFilter(m.entity))
+				rets = fmt.Sprintf("(map[int][]%s, error)", mapping.Name)
+			}
 		case "Create":
 			comment = fmt.Sprintf("adds a new %s to the database.", m.entity)
 			args += fmt.Sprintf("parent string, objects map[string]%s", mapping.Name)
@@ -1268,9 +1408,15 @@ func (m *Method) signature(buf *file.Buffer, isInterface bool) error {
 	case MapTable:
 		switch operation(m.kind) {
 		case "GetMany":
-			comment = fmt.Sprintf("returns all available %s.", lex.Plural(m.entity))
-			args += fmt.Sprintf("parent string, filters ...%s", entityFilter(m.entity))
-			rets = "(map[int]map[string]string, error)"
+			if m.kind == "GetMany-by-ReferenceID" {
+				comment = fmt.Sprintf("returns all available %s for the parent entity with the given ID.", lex.Plural(m.entity))
+				args += fmt.Sprintf("parent string, referenceID int, filters ...%s", entityFilter(m.entity))
+				rets = "(map[string]string, error)"
+			} else {
+				comment = fmt.Sprintf("returns all available %s.", lex.Plural(m.entity))
+				args += fmt.Sprintf("parent string, filters ...%s", entityFilter(m.entity))
+				rets = "(map[int]map[string]string, error)"
+			}
 		case "Create":
 			comment = fmt.Sprintf("adds a new %s to the database.", m.entity)
 			args += fmt.Sprintf("parent string, object %s", mapping.Name)
@@ -1439,6 +1585,10 @@ func (m *Method) begin(buf *file.Buffer, comment string, args string, rets strin
 			name = fmt.Sprintf("Get%sURIs", entity)
 		case "GetMany":
 			name = fmt.Sprintf("Get%s", lex.Plural(entity))
+			if m.kind == "GetMany-by-ReferenceID" {
+				name += "ByReferenceID"
+			}
+
 		case "GetOne":
 			name = fmt.Sprintf("Get%s", entity)
 		case "ID":
@@ -114,6 +114,24 @@ func (s *Stmt) objects(buf *file.Buffer) error {
 		return err
 	}
 
+	sql, err := s.objectsSQL(mapping)
+	if err != nil {
+		return err
+	}
+
+	kind := strings.Replace(s.kind, "-", "_", -1)
+	stmtName := stmtCodeVar(s.entity, kind)
+	if mapping.Type == ReferenceTable || mapping.Type == MapTable {
+		buf.L("const %s = `%s`", stmtName, sql)
+	} else {
+		s.register(buf, stmtName, sql)
+	}
+
+	return nil
+}
+
+// objectsSQL returns the SQL string selecting all the rows of the entity.
+func (s *Stmt) objectsSQL(mapping *Mapping) (string, error) {
 	table := mapping.TableName(s.entity, s.config["table"])
 	boiler := stmts["objects"]
 	fields := mapping.ColumnFields()
@@ -121,7 +139,7 @@ func (s *Stmt) objects(buf *file.Buffer) error {
 	for i, field := range fields {
 		column, err := field.SelectColumn(mapping, table)
 		if err != nil {
-			return err
+			return "", err
 		}
 
 		columns[i] = column
@@ -142,7 +160,7 @@ func (s *Stmt) objects(buf *file.Buffer) error {
 	for _, field := range orderByFields {
 		column, err := field.OrderBy(mapping, table)
 		if err != nil {
-			return err
+			return "", err
 		}
 
 		orderBy = append(orderBy, column)
@@ -153,23 +171,15 @@ func (s *Stmt) objects(buf *file.Buffer) error {
 	for _, field := range joinFields {
 		join, err := field.JoinClause(mapping, table)
 		if err != nil {
-			return err
+			return "", err
 		}
 
 		joins = append(joins, join)
 	}
 
 	table += strings.Join(joins, "")
-	sql := fmt.Sprintf(boiler, strings.Join(columns, ", "), table, strings.Join(orderBy, ", "))
-	kind := strings.Replace(s.kind, "-", "_", -1)
-	stmtName := stmtCodeVar(s.entity, kind)
-	if mapping.Type == ReferenceTable || mapping.Type == MapTable {
-		buf.L("const %s = `%s`", stmtName, sql)
-	} else {
-		s.register(buf, stmtName, sql)
-	}
 
-	return nil
+	return fmt.Sprintf(boiler, strings.Join(columns, ", "), table, strings.Join(orderBy, ", ")), nil
 }
 
 // objectsBy parses the variable declaration produced by the 'objects' function, and appends a WHERE clause to its SQL
@@ -181,6 +191,10 @@ func (s *Stmt) objectsBy(buf *file.Buffer) error {
 		return err
 	}
 
+	if mapping.Type == ReferenceTable || mapping.Type == MapTable {
+		return s.objectsByReferenceID(buf, mapping)
+	}
+
 	where := []string{}
 	filters := strings.Split(s.kind[len("objects-by-"):], "-and-")
 	sqlString, err := ParseStmt(s.pkg, s.dbPkg, stmtCodeVar(s.entity, "objects"))
@@ -238,6 +252,41 @@ func (s *Stmt) objectsBy(buf *file.Buffer) error {
 	return nil
 }
 
+// objectsByReferenceID registers one statement per parent entity given in the 'parents' config parameter, each
+// selecting the rows of the reference table that belong to a single parent entity. The statement codes are declared
+// in a map indexed by parent entity, e.g. 'var <entity>ObjectsByReferenceID = map[string]int{...}'.
+func (s *Stmt) objectsByReferenceID(buf *file.Buffer, mapping *Mapping) error {
+	if s.kind != "objects-by-ReferenceID" {
+		return fmt.Errorf("Statement %q not supported for reference tables, only objects-by-ReferenceID is", s.kind)
+	}
+
+	if s.config["parents"] == "" {
+		return fmt.Errorf("Statement %q requires the parents config parameter", s.kind)
+	}
+
+	sqlString, err := s.objectsSQL(mapping)
+	if err != nil {
+		return err
+	}
+
+	table := mapping.TableName(s.entity, s.config["table"])
+	queryParts := strings.SplitN(sqlString, "ORDER BY", 2)
+	queryParts[0] = fmt.Sprintf("%sWHERE ( %s.%%s_id = ? )", queryParts[0], table)
+	sqlString = strings.Join(queryParts, "\n  ORDER BY")
+
+	buf.L("var %s = map[string]int{", stmtCodeVar(s.entity, "objects", "ReferenceID"))
+	for _, parent := range strings.Split(s.config["parents"], ",") {
+		// Fill in the parent table and column names the same way as the generated methods do.
+		parentSQL := strings.Replace(sqlString, "%s_id", fmt.Sprintf("%s_id", parent), -1)
+		parentSQL = strings.Replace(parentSQL, "%s", strings.Replace(parent, "_", "s_", -1)+"s", -1)
+		buf.L("%q: %s,", parent, s.registerCall(parentSQL))
+	}
+
+	buf.L("}")
+
+	return nil
+}
+
 func (s *Stmt) create(buf *file.Buffer, replace bool) error {
 	entityCreate := lex.Camel(s.entity)
 
@@ -435,15 +484,20 @@ func (s *Stmt) delete(buf *file.Buffer) error {
 // Output a line of code that registers the given statement and declares the
 // associated statement code global variable.
 func (s *Stmt) register(buf *file.Buffer, stmtName, sql string) {
+	buf.L("var %s = %s", stmtName, s.registerCall(sql))
+}
+
+// registerCall returns the expression registering the given statement.
+func (s *Stmt) registerCall(sql string) string {
 	if !strings.HasPrefix(sql, "`") || !strings.HasSuffix(sql, "`") {
 		sql = fmt.Sprintf("`\n%s\n`", sql)
 	}
 
 	if s.db != "" {
-		buf.L("var %s = %s.RegisterStmt(%s)", stmtName, s.db, sql)
-	} else {
-		buf.L("var %s = RegisterStmt(%s)", stmtName, sql)
+		return fmt.Sprintf("%s.RegisterStmt(%s)", s.db, sql)
 	}
+
+	return fmt.Sprintf("RegisterStmt(%s)", sql)
 }
 
 // Map of boilerplate statements.
@@ -14,13 +14,20 @@ func Capital(s string) string {
 	return cases.Title(language.English, cases.NoLower).String(s)
 }
 
-// Minuscule turns the first character to lower case ("Foo" -> "foo") or the whole word if it is all uppercase ("UUID" -> "uuid").
+// Minuscule turns the first character to lower case ("Foo" -> "foo"), the whole word if it is all uppercase ("UUID" -> "uuid")
+// or the leading initialism ("SSHKey" -> "sshKey").
 func Minuscule(s string) string {
 	if strings.ToUpper(s) == s {
 		return strings.ToLower(s)
 	}
 
-	return strings.ToLower(s[:1]) + s[1:]
+	// Find the end of the leading run of upper case characters, leaving out the one starting the next word.
+	n := 1
+	for n < len(s)-1 && unicode.IsUpper(rune(s[n])) && unicode.IsUpper(rune(s[n+1])) {
+		n++
+	}
+
+	return strings.ToLower(s[:n]) + s[n:]
 }
 
 // Camel converts to camel case ("foo_bar" -> "FooBar").
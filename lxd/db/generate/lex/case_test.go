@@ -0,0 +1,25 @@
+package lex_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/db/generate/lex"
+)
+
+func TestMinuscule(t *testing.T) {
+	cases := map[string]string{
+		"Foo":     "foo",
+		"FooBar":  "fooBar",
+		"UUID":    "uuid",
+		"SSHKey":  "sshKey",
+		"IDs":     "iDs",
+		"X":       "x",
+		"AuthKey": "authKey",
+	}
+
+	for in, out := range cases {
+		assert.Equal(t, out, lex.Minuscule(in), in)
+	}
+}
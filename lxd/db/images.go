@@ -629,10 +629,12 @@ SELECT images_aliases.name
 func (c *ClusterTx) GetImageAlias(ctx context.Context, projectName string, imageName string, isTrustedClient bool) (int, api.ImageAliasesEntry, error) {
 	id := -1
 	entry := api.ImageAliasesEntry{}
-	q := `SELECT images_aliases.id, images.fingerprint, images.type, images_aliases.description
+	q := `SELECT images_aliases.id, images.fingerprint, images.type, images_aliases.description, coalesce(previous.fingerprint, '')
 			 FROM images_aliases
 			 INNER JOIN images
 			 ON images_aliases.image_id=images.id
+			 LEFT JOIN images AS previous
+			 ON images_aliases.previous_image_id=previous.id
                          INNER JOIN projects
                          ON images_aliases.project_id=projects.id
 			 WHERE projects.name=? AND images_aliases.name=?`
@@ -649,11 +651,11 @@ func (c *ClusterTx) GetImageAlias(ctx context.Context, projectName string, image
 		projectName = "default"
 	}
 
-	var fingerprint, description string
+	var fingerprint, description, previousFingerprint string
 	var imageType int
 
 	arg1 := []any{projectName, imageName}
-	arg2 := []any{&id, &fingerprint, &imageType, &description}
+	arg2 := []any{&id, &fingerprint, &imageType, &description, &previousFingerprint}
 	err = c.tx.QueryRowContext(ctx, q, arg1...).Scan(arg2...)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -663,14 +665,68 @@ func (c *ClusterTx) GetImageAlias(ctx context.Context, projectName string, image
 		return 0, entry, err
 	}
 
+	channels, err := c.GetImageAliasChannels(ctx, id)
+	if err != nil {
+		return -1, api.ImageAliasesEntry{}, err
+	}
+
 	entry.Name = imageName
 	entry.Target = fingerprint
 	entry.Description = description
 	entry.Type = instancetype.Type(imageType).String()
+	entry.Channels = channels
+	entry.PreviousTarget = previousFingerprint
 
 	return id, entry, nil
 }
 
+// GetImageAliasChannels returns the target fingerprints of the channels of the alias with the given ID.
+func (c *ClusterTx) GetImageAliasChannels(ctx context.Context, aliasID int) (map[string]string, error) {
+	q := `
+SELECT images_aliases_channels.name, images.fingerprint
+  FROM images_aliases_channels
+  JOIN images ON images_aliases_channels.image_id=images.id
+ WHERE images_aliases_channels.image_alias_id=?
+`
+
+	channels := map[string]string{}
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var name, fingerprint string
+
+		err := scan(&name, &fingerprint)
+		if err != nil {
+			return err
+		}
+
+		channels[name] = fingerprint
+
+		return nil
+	}, aliasID)
+	if err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// UpdateImageAliasChannels replaces the channels of the alias with the given ID by the given channel names and
+// image IDs.
+func (c *ClusterTx) UpdateImageAliasChannels(ctx context.Context, aliasID int, channels map[string]int) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM images_aliases_channels WHERE image_alias_id=?", aliasID)
+	if err != nil {
+		return err
+	}
+
+	for name, imageID := range channels {
+		_, err := c.tx.ExecContext(ctx, "INSERT INTO images_aliases_channels (image_alias_id, name, image_id) VALUES (?, ?, ?)", aliasID, name, imageID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // RenameImageAlias renames the alias with the given ID.
 func (c *ClusterTx) RenameImageAlias(ctx context.Context, id int, name string) error {
 	q := "UPDATE images_aliases SET name=? WHERE id=?"
@@ -703,10 +759,16 @@ DELETE
 	return nil
 }
 
-// MoveImageAlias changes the image ID associated with an alias.
+// MoveImageAlias changes the image ID associated with an alias, including the alias channels.
 func (c *ClusterTx) MoveImageAlias(ctx context.Context, source int, destination int) error {
 	q := "UPDATE images_aliases SET image_id=? WHERE image_id=?"
 	_, err := c.tx.ExecContext(ctx, q, destination, source)
+	if err != nil {
+		return err
+	}
+
+	q = "UPDATE images_aliases_channels SET image_id=? WHERE image_id=?"
+	_, err = c.tx.ExecContext(ctx, q, destination, source)
 
 	return err
 }
@@ -734,9 +796,14 @@ VALUES (?, ?, ?, (SELECT id FROM projects WHERE name = ?))
 }
 
 // UpdateImageAlias updates the alias with the given ID.
+// If the target image changes, the previous one is recorded so that the change can be rolled back.
 func (c *ClusterTx) UpdateImageAlias(ctx context.Context, aliasID int, imageID int, desc string) error {
-	stmt := `UPDATE images_aliases SET image_id=?, description=? WHERE id=?`
-	_, err := c.tx.ExecContext(ctx, stmt, imageID, desc, aliasID)
+	stmt := `
+UPDATE images_aliases
+   SET previous_image_id=CASE WHEN image_id=? THEN previous_image_id ELSE image_id END, image_id=?, description=?
+ WHERE id=?
+`
+	_, err := c.tx.ExecContext(ctx, stmt, imageID, imageID, desc, aliasID)
 	return err
 }
 
@@ -1087,6 +1154,26 @@ WHERE images.fingerprint = ?
 	return c.getNodesByImageFingerprint(ctx, q, fingerprint, nil)
 }
 
+// GetImageReferencesCount returns the number of cluster members, online or not, which have the image in any
+// project.
+func (c *ClusterTx) GetImageReferencesCount(ctx context.Context, fingerprint string) (int, error) {
+	q := `
+SELECT COUNT(DISTINCT images_nodes.node_id) FROM images_nodes
+  JOIN images ON images_nodes.image_id = images.id
+WHERE images.fingerprint = ?
+	`
+	counts, err := query.SelectIntegers(ctx, c.tx, q, fingerprint)
+	if err != nil {
+		return -1, err
+	}
+
+	if len(counts) == 0 {
+		return 0, nil
+	}
+
+	return counts[0], nil
+}
+
 // GetNodesWithImageAndAutoUpdate returns the addresses of online nodes which already have the image.
 func (c *ClusterTx) GetNodesWithImageAndAutoUpdate(ctx context.Context, fingerprint string, autoUpdate bool) ([]string, error) {
 	q := `
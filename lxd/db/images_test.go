@@ -46,6 +46,43 @@ func TestLocateImage(t *testing.T) {
 	})
 }
 
+func TestGetImageReferencesCount(t *testing.T) {
+	cluster, cleanup := db.NewTestCluster(t)
+	defer cleanup()
+
+	_ = cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		count, err := tx.GetImageReferencesCount(ctx, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		err = tx.CreateImage(ctx,
+			"default", "abc", "x.gz", 16, false, false, "amd64", time.Now(), time.Now(), map[string]string{}, "container", nil)
+		require.NoError(t, err)
+
+		count, err = tx.GetImageReferencesCount(ctx, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		// Add the image to another member, which is counted even when offline.
+		nodeID2, err := tx.CreateNode("node2", "1.2.3.4:666")
+		require.NoError(t, err)
+
+		err = tx.SetNodeHeartbeat("1.2.3.4:666", time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		tx.NodeID(nodeID2)
+
+		err = tx.AddImageToLocalNode(ctx, "default", "abc")
+		require.NoError(t, err)
+
+		count, err = tx.GetImageReferencesCount(ctx, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		return nil
+	})
+}
+
 func TestImageExists(t *testing.T) {
 	cluster, cleanup := db.NewTestCluster(t)
 	defer cleanup()
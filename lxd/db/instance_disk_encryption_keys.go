@@ -0,0 +1,69 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetInstanceDiskEncryptionKeys returns the recovery keys escrowed by the instance with the given ID.
+func (c *ClusterTx) GetInstanceDiskEncryptionKeys(ctx context.Context, instanceID int) ([]api.InstanceDiskEncryptionKey, error) {
+	q := `
+SELECT device, recovery_key, created_at
+  FROM instances_disk_encryption_keys
+ WHERE instance_id=?
+ ORDER BY device
+`
+
+	keys := []api.InstanceDiskEncryptionKey{}
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		key := api.InstanceDiskEncryptionKey{}
+
+		err := scan(&key.Device, &key.RecoveryKey, &key.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, key)
+
+		return nil
+	}, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// UpsertInstanceDiskEncryptionKey records the recovery key of an encrypted block device of the instance with the
+// given ID, replacing any key previously escrowed for the same device.
+func (c *ClusterTx) UpsertInstanceDiskEncryptionKey(ctx context.Context, instanceID int, device string, recoveryKey string) error {
+	stmt := `INSERT OR REPLACE INTO instances_disk_encryption_keys (instance_id, device, recovery_key, created_at) VALUES (?, ?, ?, ?)`
+	_, err := c.tx.ExecContext(ctx, stmt, instanceID, device, recoveryKey, time.Now().UTC())
+	return err
+}
+
+// DeleteInstanceDiskEncryptionKey deletes the recovery key escrowed for the given block device of the instance with
+// the given ID.
+func (c *ClusterTx) DeleteInstanceDiskEncryptionKey(ctx context.Context, instanceID int, device string) error {
+	result, err := c.tx.ExecContext(ctx, "DELETE FROM instances_disk_encryption_keys WHERE instance_id=? AND device=?", instanceID, device)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "No recovery key escrowed for device %q", device)
+	}
+
+	return nil
+}
@@ -0,0 +1,309 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// InstancePoolInstance is an instance that belongs to an instance pool.
+type InstancePoolInstance struct {
+	InstanceID int64
+	Name       string
+	Acquired   bool
+}
+
+// GetInstancePools returns the names of existing instance pools in the project.
+func (c *ClusterTx) GetInstancePools(ctx context.Context, projectName string) ([]string, error) {
+	q := `
+		SELECT instances_pools.name
+		FROM instances_pools
+		JOIN projects ON projects.id = instances_pools.project_id
+		WHERE projects.name = ?
+		ORDER BY instances_pools.id
+	`
+
+	var poolNames []string
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var poolName string
+
+		err := scan(&poolName)
+		if err != nil {
+			return err
+		}
+
+		poolNames = append(poolNames, poolName)
+
+		return nil
+	}, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	return poolNames, nil
+}
+
+// GetAllInstancePools returns the names of existing instance pools keyed by project name.
+func (c *ClusterTx) GetAllInstancePools(ctx context.Context) (map[string][]string, error) {
+	q := `
+		SELECT projects.name, instances_pools.name
+		FROM instances_pools
+		JOIN projects ON projects.id = instances_pools.project_id
+		ORDER BY instances_pools.id
+	`
+
+	projectPools := make(map[string][]string)
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var projectName, poolName string
+
+		err := scan(&projectName, &poolName)
+		if err != nil {
+			return err
+		}
+
+		projectPools[projectName] = append(projectPools[projectName], poolName)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return projectPools, nil
+}
+
+// GetInstancePoolByName returns the instance pool with the given name in the project.
+func (c *ClusterTx) GetInstancePoolByName(ctx context.Context, projectName string, name string) (int64, *api.InstancePool, error) {
+	var id = int64(-1)
+
+	pool := api.InstancePool{
+		Name: name,
+	}
+
+	q := `
+		SELECT instances_pools.id, instances_pools.description
+		FROM instances_pools
+		JOIN projects ON projects.id = instances_pools.project_id
+		WHERE projects.name = ? AND instances_pools.name = ?
+		LIMIT 1
+	`
+
+	err := c.tx.QueryRowContext(ctx, q, projectName, name).Scan(&id, &pool.Description)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Instance pool not found")
+		}
+
+		return -1, nil, err
+	}
+
+	err = instancePoolConfig(ctx, c, id, &pool)
+	if err != nil {
+		return -1, nil, fmt.Errorf("Failed loading config: %w", err)
+	}
+
+	return id, &pool, nil
+}
+
+// instancePoolConfig populates the config map of the instance pool with the given ID.
+func instancePoolConfig(ctx context.Context, tx *ClusterTx, id int64, pool *api.InstancePool) error {
+	q := `
+		SELECT key, value
+		FROM instances_pools_config
+		WHERE instance_pool_id=?
+	`
+
+	pool.Config = make(map[string]string)
+	return query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		_, found := pool.Config[key]
+		if found {
+			return fmt.Errorf("Duplicate config row found for key %q for instance pool ID %d", key, id)
+		}
+
+		pool.Config[key] = value
+
+		return nil
+	}, id)
+}
+
+// CreateInstancePool creates a new instance pool in the project.
+func (c *ClusterTx) CreateInstancePool(ctx context.Context, projectName string, info *api.InstancePoolsPost) (int64, error) {
+	// Insert a new instance pool record.
+	result, err := c.tx.ExecContext(ctx, `
+			INSERT INTO instances_pools (project_id, name, description)
+			VALUES ((SELECT id FROM projects WHERE name = ?), ?, ?)
+		`, projectName, info.Name, info.Description)
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	err = instancePoolConfigAdd(c.tx, id, info.Config)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// instancePoolConfigAdd inserts instance pool config keys.
+func instancePoolConfigAdd(tx *sql.Tx, id int64, config map[string]string) error {
+	sql := "INSERT INTO instances_pools_config (instance_pool_id, key, value) VALUES(?, ?, ?)"
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateInstancePool updates the instance pool with the given ID.
+func (c *ClusterTx) UpdateInstancePool(ctx context.Context, id int64, config *api.InstancePoolPut) error {
+	_, err := c.tx.ExecContext(ctx, `
+		UPDATE instances_pools
+		SET description=?
+		WHERE id=?
+	`, config.Description, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "DELETE FROM instances_pools_config WHERE instance_pool_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	err = instancePoolConfigAdd(c.tx, id, config.Config)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteInstancePool deletes the instance pool.
+func (c *ClusterTx) DeleteInstancePool(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM instances_pools WHERE id=?", id)
+
+	return err
+}
+
+// GetInstancePoolInstances returns the instances that belong to the instance pool with the given ID.
+// The standby instances are returned in the order they were added to the pool.
+func (c *ClusterTx) GetInstancePoolInstances(ctx context.Context, id int64) ([]InstancePoolInstance, error) {
+	q := `
+		SELECT instances.id, instances.name, instances_pools_instances.acquired
+		FROM instances_pools_instances
+		JOIN instances ON instances.id = instances_pools_instances.instance_id
+		WHERE instances_pools_instances.instance_pool_id=?
+		ORDER BY instances_pools_instances.id
+	`
+
+	var poolInstances []InstancePoolInstance
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		poolInstance := InstancePoolInstance{}
+
+		err := scan(&poolInstance.InstanceID, &poolInstance.Name, &poolInstance.Acquired)
+		if err != nil {
+			return err
+		}
+
+		poolInstances = append(poolInstances, poolInstance)
+
+		return nil
+	}, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return poolInstances, nil
+}
+
+// CreateInstancePoolInstance adds a standby instance to the instance pool with the given ID.
+func (c *ClusterTx) CreateInstancePoolInstance(ctx context.Context, id int64, instanceID int64) error {
+	_, err := c.tx.ExecContext(ctx, "INSERT INTO instances_pools_instances (instance_pool_id, instance_id) VALUES (?, ?)", id, instanceID)
+
+	return err
+}
+
+// AcquireInstancePoolInstance marks the oldest standby instance of the instance pool with the given ID as acquired.
+// Returns a not found error if the pool has no standby instance left.
+func (c *ClusterTx) AcquireInstancePoolInstance(ctx context.Context, id int64) (*InstancePoolInstance, error) {
+	poolInstances, err := c.GetInstancePoolInstances(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, poolInstance := range poolInstances {
+		if poolInstance.Acquired {
+			continue
+		}
+
+		_, err = c.tx.ExecContext(ctx, "UPDATE instances_pools_instances SET acquired=1 WHERE instance_id=?", poolInstance.InstanceID)
+		if err != nil {
+			return nil, err
+		}
+
+		poolInstance.Acquired = true
+
+		return &poolInstance, nil
+	}
+
+	return nil, api.StatusErrorf(http.StatusNotFound, "No standby instance available")
+}
+
+// DeleteInstancePoolInstance removes an instance from the instance pool it belongs to.
+func (c *ClusterTx) DeleteInstancePoolInstance(ctx context.Context, instanceID int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM instances_pools_instances WHERE instance_id=?", instanceID)
+
+	return err
+}
+
+// DeleteInstancePoolStandbyInstance removes a standby instance from the instance pool it belongs to.
+// Returns false if the instance isn't a standby instance anymore, for example because it has been acquired.
+func (c *ClusterTx) DeleteInstancePoolStandbyInstance(ctx context.Context, instanceID int64) (bool, error) {
+	result, err := c.tx.ExecContext(ctx, "DELETE FROM instances_pools_instances WHERE instance_id=? AND acquired=0", instanceID)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
@@ -54,6 +54,28 @@ SELECT instances.name FROM instances
 	return query.SelectStrings(ctx, c.tx, stmt, project)
 }
 
+// GetInstanceProjectsWithName returns the names of all projects which contain an instance with the given name.
+func (c *ClusterTx) GetInstanceProjectsWithName(ctx context.Context, name string) ([]string, error) {
+	stmt := `
+SELECT projects.name FROM instances
+  JOIN projects ON projects.id = instances.project_id
+  WHERE instances.name = ?
+  ORDER BY projects.name
+`
+	return query.SelectStrings(ctx, c.tx, stmt, name)
+}
+
+// GetDuplicateInstanceNames returns the names of all instances whose name is used in more than one project.
+func (c *ClusterTx) GetDuplicateInstanceNames(ctx context.Context) ([]string, error) {
+	stmt := `
+SELECT name FROM instances
+  GROUP BY name
+  HAVING COUNT(*) > 1
+  ORDER BY name
+`
+	return query.SelectStrings(ctx, c.tx, stmt)
+}
+
 // GetNodeAddressOfInstance returns the address of the node hosting the
 // instance with the given name in the given project.
 //
@@ -529,7 +551,7 @@ func (c *ClusterTx) instanceProfilesFill(ctx context.Context, snapshotsMode bool
 	}
 
 	// Get all profiles.
-	profiles, err := cluster.GetProfiles(context.TODO(), c.Tx())
+	profiles, err := cluster.GetProfiles(ctx, c.Tx())
 	if err != nil {
 		return fmt.Errorf("Failed loading profiles: %w", err)
 	}
@@ -543,7 +565,7 @@ func (c *ClusterTx) instanceProfilesFill(ctx context.Context, snapshotsMode bool
 			continue
 		}
 
-		profilesByID[profile.ID], err = profile.ToAPI(context.TODO(), c.tx)
+		profilesByID[profile.ID], err = c.getCachedProfile(ctx, profile)
 		if err != nil {
 			return err
 		}
@@ -195,6 +195,43 @@ func TestInstanceList_ContainerWithSameNameInDifferentProjects(t *testing.T) {
 	assert.Equal(t, "intranet", c2Profiles[0].Name)
 }
 
+func TestGetDuplicateInstanceNames(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	project := cluster.Project{}
+	project.Name = "blah"
+	_, err := cluster.CreateProject(ctx, tx.Tx(), project)
+	require.NoError(t, err)
+
+	for _, inst := range []cluster.Instance{
+		{Project: "default", Name: "c1"},
+		{Project: "default", Name: "c2"},
+		{Project: "blah", Name: "c1"},
+		{Project: "blah", Name: "c3"},
+	} {
+		inst.Node = "none"
+		inst.Type = instancetype.Container
+		inst.Architecture = 1
+		_, err = cluster.CreateInstance(ctx, tx.Tx(), inst)
+		require.NoError(t, err)
+	}
+
+	names, err := tx.GetDuplicateInstanceNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c1"}, names)
+
+	projects, err := tx.GetInstanceProjectsWithName(ctx, "c1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"blah", "default"}, projects)
+
+	projects, err = tx.GetInstanceProjectsWithName(ctx, "c3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"blah"}, projects)
+}
+
 func TestInstanceList(t *testing.T) {
 	c, clusterCleanup := db.NewTestCluster(t)
 	defer clusterCleanup()
@@ -0,0 +1,287 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/dberr"
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// NetworkAddressPoolAllocation is an address allocated from a network address pool.
+// Exactly one of InstanceID or NetworkForwardID is set.
+type NetworkAddressPoolAllocation struct {
+	Address          string
+	InstanceID       int64
+	DeviceName       string
+	NetworkForwardID int64
+
+	// Populated when loading allocations to identify the user of the address.
+	ProjectName string
+	EntityName  string
+}
+
+// GetNetworkAddressPools returns the names of existing network address pools.
+func (c *ClusterTx) GetNetworkAddressPools(ctx context.Context) ([]string, error) {
+	q := `SELECT name FROM networks_address_pools ORDER BY id`
+
+	var poolNames []string
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var poolName string
+
+		err := scan(&poolName)
+		if err != nil {
+			return err
+		}
+
+		poolNames = append(poolNames, poolName)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return poolNames, nil
+}
+
+// GetNetworkAddressPool returns the network address pool with the given name.
+func (c *ClusterTx) GetNetworkAddressPool(ctx context.Context, name string) (int64, *api.NetworkAddressPool, error) {
+	var id = int64(-1)
+
+	pool := api.NetworkAddressPool{
+		Name: name,
+	}
+
+	q := `
+		SELECT id, description
+		FROM networks_address_pools
+		WHERE name=?
+		LIMIT 1
+	`
+
+	err := c.tx.QueryRowContext(ctx, q, name).Scan(&id, &pool.Description)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Network address pool not found")
+		}
+
+		return -1, nil, err
+	}
+
+	err = networkAddressPoolConfig(ctx, c, id, &pool)
+	if err != nil {
+		return -1, nil, fmt.Errorf("Failed loading config: %w", err)
+	}
+
+	return id, &pool, nil
+}
+
+// networkAddressPoolConfig populates the config map of the network address pool with the given ID.
+func networkAddressPoolConfig(ctx context.Context, tx *ClusterTx, id int64, pool *api.NetworkAddressPool) error {
+	q := `
+		SELECT key, value
+		FROM networks_address_pools_config
+		WHERE network_address_pool_id=?
+	`
+
+	pool.Config = make(map[string]string)
+	return query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		_, found := pool.Config[key]
+		if found {
+			return fmt.Errorf("Duplicate config row found for key %q for network address pool ID %d", key, id)
+		}
+
+		pool.Config[key] = value
+
+		return nil
+	}, id)
+}
+
+// CreateNetworkAddressPool creates a new network address pool.
+func (c *ClusterTx) CreateNetworkAddressPool(ctx context.Context, info *api.NetworkAddressPoolsPost) (int64, error) {
+	// Insert a new network address pool record.
+	result, err := c.tx.ExecContext(ctx, `
+			INSERT INTO networks_address_pools (name, description)
+			VALUES (?, ?)
+		`, info.Name, info.Description)
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	err = networkAddressPoolConfigAdd(c.tx, id, info.Config)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// networkAddressPoolConfigAdd inserts network address pool config keys.
+func networkAddressPoolConfigAdd(tx *sql.Tx, id int64, config map[string]string) error {
+	sql := "INSERT INTO networks_address_pools_config (network_address_pool_id, key, value) VALUES(?, ?, ?)"
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateNetworkAddressPool updates the network address pool with the given ID.
+func (c *ClusterTx) UpdateNetworkAddressPool(ctx context.Context, id int64, config *api.NetworkAddressPoolPut) error {
+	_, err := c.tx.ExecContext(ctx, `
+		UPDATE networks_address_pools
+		SET description=?
+		WHERE id=?
+	`, config.Description, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "DELETE FROM networks_address_pools_config WHERE network_address_pool_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	err = networkAddressPoolConfigAdd(c.tx, id, config.Config)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkAddressPool deletes the network address pool.
+func (c *ClusterTx) DeleteNetworkAddressPool(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM networks_address_pools WHERE id=?", id)
+
+	return err
+}
+
+// GetNetworkAddressPoolAllocations returns the addresses allocated from the network address pool with the given ID.
+func (c *ClusterTx) GetNetworkAddressPoolAllocations(ctx context.Context, id int64) ([]NetworkAddressPoolAllocation, error) {
+	q := `
+		SELECT
+			networks_address_pools_allocations.address,
+			IFNULL(networks_address_pools_allocations.instance_id, -1),
+			IFNULL(networks_address_pools_allocations.device_name, ''),
+			IFNULL(networks_address_pools_allocations.network_forward_id, -1),
+			IFNULL(instances_projects.name, IFNULL(networks_projects.name, '')),
+			IFNULL(instances.name, IFNULL(networks.name, ''))
+		FROM networks_address_pools_allocations
+		LEFT JOIN instances ON instances.id = networks_address_pools_allocations.instance_id
+		LEFT JOIN projects AS instances_projects ON instances_projects.id = instances.project_id
+		LEFT JOIN networks_forwards ON networks_forwards.id = networks_address_pools_allocations.network_forward_id
+		LEFT JOIN networks ON networks.id = networks_forwards.network_id
+		LEFT JOIN projects AS networks_projects ON networks_projects.id = networks.project_id
+		WHERE networks_address_pools_allocations.network_address_pool_id=?
+		ORDER BY networks_address_pools_allocations.id
+	`
+
+	var allocations []NetworkAddressPoolAllocation
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		allocation := NetworkAddressPoolAllocation{}
+
+		err := scan(&allocation.Address, &allocation.InstanceID, &allocation.DeviceName, &allocation.NetworkForwardID, &allocation.ProjectName, &allocation.EntityName)
+		if err != nil {
+			return err
+		}
+
+		allocations = append(allocations, allocation)
+
+		return nil
+	}, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+// CreateNetworkAddressPoolAllocation records an address allocated from the network address pool with the given ID.
+// Returns a conflict error if the address is already allocated.
+func (c *ClusterTx) CreateNetworkAddressPoolAllocation(ctx context.Context, id int64, allocation NetworkAddressPoolAllocation) error {
+	var instanceID any
+	var deviceName any
+	var networkForwardID any
+
+	if allocation.InstanceID > 0 {
+		instanceID = allocation.InstanceID
+		deviceName = allocation.DeviceName
+	}
+
+	if allocation.NetworkForwardID > 0 {
+		networkForwardID = allocation.NetworkForwardID
+	}
+
+	_, err := c.tx.ExecContext(ctx, `
+		INSERT INTO networks_address_pools_allocations (network_address_pool_id, address, instance_id, device_name, network_forward_id)
+		VALUES (?, ?, ?, ?, ?)
+	`, id, allocation.Address, instanceID, deviceName, networkForwardID)
+	if err != nil {
+		if errors.Is(query.WrapError(err), dberr.ErrConflict) {
+			return api.StatusErrorf(http.StatusConflict, "Address %q is already allocated", allocation.Address)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkAddressPoolInstanceAllocations releases the addresses allocated to the given instance NIC device
+// from any network address pool.
+func (c *ClusterTx) DeleteNetworkAddressPoolInstanceAllocations(ctx context.Context, instanceID int64, deviceName string) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM networks_address_pools_allocations WHERE instance_id=? AND device_name=?", instanceID, deviceName)
+
+	return err
+}
+
+// SetNetworkAddressPoolAllocationForward assigns an address allocated from the network address pool with the given
+// ID to a network forward.
+func (c *ClusterTx) SetNetworkAddressPoolAllocationForward(ctx context.Context, id int64, address string, forwardID int64) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE networks_address_pools_allocations SET network_forward_id=? WHERE network_address_pool_id=? AND address=?", forwardID, id, address)
+
+	return err
+}
+
+// DeleteNetworkAddressPoolAllocation releases an address allocated from the network address pool with the given ID.
+func (c *ClusterTx) DeleteNetworkAddressPoolAllocation(ctx context.Context, id int64, address string) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM networks_address_pools_allocations WHERE network_address_pool_id=? AND address=?", id, address)
+
+	return err
+}
@@ -163,7 +163,7 @@ func (c *ClusterTx) GetNetworkForward(ctx context.Context, networkID int64, memb
 	if (err == nil && len(forwards) <= 0) || errors.Is(err, sql.ErrNoRows) {
 		return -1, nil, api.StatusErrorf(http.StatusNotFound, "Network forward not found")
 	} else if err == nil && len(forwards) > 1 {
-		return -1, nil, api.StatusErrorf(http.StatusConflict, "Network forward found on more than one cluster member. Please target a specific member")
+		return -1, nil, api.ReasonErrorf(api.ErrorReasonAmbiguousTarget, nil, "Network forward found on more than one cluster member. Please target a specific member")
 	} else if err != nil {
 		return -1, nil, err
 	}
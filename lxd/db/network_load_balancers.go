@@ -177,7 +177,7 @@ func (c *ClusterTx) GetNetworkLoadBalancer(ctx context.Context, networkID int64,
 	if (err == nil && len(loadBalancers) <= 0) || errors.Is(err, sql.ErrNoRows) {
 		return -1, nil, api.StatusErrorf(http.StatusNotFound, "Network load balancer not found")
 	} else if err == nil && len(loadBalancers) > 1 {
-		return -1, nil, api.StatusErrorf(http.StatusConflict, "Network load balancer found on more than one cluster member. Please target a specific member")
+		return -1, nil, api.ReasonErrorf(api.ErrorReasonAmbiguousTarget, nil, "Network load balancer found on more than one cluster member. Please target a specific member")
 	} else if err != nil {
 		return -1, nil, err
 	}
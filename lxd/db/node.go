@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -78,6 +79,24 @@ func (n NodeInfo) IsOffline(threshold time.Duration) bool {
 	return nodeIsOffline(threshold, n.Heartbeat)
 }
 
+// SupportsArchitecture returns true if the node can run instances of any of the given architectures, either
+// natively or through one of its personalities.
+func (n NodeInfo) SupportsArchitecture(architectures []int) (bool, error) {
+	personalities, err := osarch.ArchitecturePersonalities(n.Architecture)
+	if err != nil {
+		return false, err
+	}
+
+	supportedArchitectures := append([]int{n.Architecture}, personalities...)
+	for _, supportedArchitecture := range supportedArchitectures {
+		if shared.ValueInSlice(supportedArchitecture, architectures) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // NodeInfoArgs provides information about the cluster environment for use with NodeInfo.ToAPI().
 type NodeInfoArgs struct {
 	LeaderAddress        string
@@ -121,6 +140,16 @@ func (n NodeInfo) ToAPI(ctx context.Context, tx *ClusterTx, args NodeInfoArgs) (
 
 	result.Groups = n.Groups
 
+	result.Versions, err = tx.GetNodeVersions(ctx, n.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result.PatchBarriers, err = tx.GetNodePatchBarriers(ctx, n.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if member is the leader.
 	if args.LeaderAddress == n.Address {
 		result.Roles = append(result.Roles, string(ClusterRoleDatabaseLeader))
@@ -164,12 +193,37 @@ func (n NodeInfo) ToAPI(ctx context.Context, tx *ClusterTx, args NodeInfoArgs) (
 		if n == 1 {
 			result.Status = "Blocked"
 			result.Message = "Needs updating to newer version"
+		} else if len(result.PatchBarriers) > 0 {
+			result.Message = patchBarriersMessage(result.PatchBarriers)
 		}
 	}
 
 	return &result, nil
 }
 
+// patchBarriersMessage returns the status message of a cluster member taking part in patch barriers which aren't
+// reached by all cluster members yet.
+func patchBarriersMessage(barriers map[string]string) string {
+	reached := []string{}
+	pending := []string{}
+	for name, state := range barriers {
+		if state == PatchBarrierReached {
+			reached = append(reached, name)
+		} else {
+			pending = append(pending, name)
+		}
+	}
+
+	sort.Strings(reached)
+	sort.Strings(pending)
+
+	if len(pending) > 0 {
+		return fmt.Sprintf("Applying patches (barriers not reached: %s)", strings.Join(pending, ", "))
+	}
+
+	return fmt.Sprintf("Waiting for other members to apply patches (barriers reached: %s)", strings.Join(reached, ", "))
+}
+
 // Version returns the node's version, composed by its schema level and
 // number of extensions.
 func (n NodeInfo) Version() [2]int {
@@ -381,7 +435,7 @@ func (c *ClusterTx) RenameNode(ctx context.Context, old string, new string) erro
 	}
 
 	if count != 0 {
-		return api.StatusErrorf(http.StatusConflict, "A cluster member already exists with name %q", new)
+		return api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "A cluster member already exists with name %q", new)
 	}
 
 	stmt := `UPDATE nodes SET name=? WHERE name=?`
@@ -621,6 +675,51 @@ func (c *ClusterTx) UpdateNodeConfig(ctx context.Context, id int64, config map[s
 	return nil
 }
 
+// GetNodeVersions returns the versions of the software components (kernel, LXC, QEMU, CRIU...) recorded for the
+// member with the given ID, keyed by component.
+func (c *ClusterTx) GetNodeVersions(ctx context.Context, id int64) (map[string]string, error) {
+	versions := map[string]string{}
+	err := query.Scan(ctx, c.tx, "SELECT component, version FROM nodes_versions WHERE node_id=?", func(scan func(dest ...any) error) error {
+		var component string
+		var version string
+
+		err := scan(&component, &version)
+		if err != nil {
+			return err
+		}
+
+		versions[component] = version
+
+		return nil
+	}, id)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading member versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// UpdateNodeVersions replaces the versions of the software components recorded for the member with the given ID.
+func (c *ClusterTx) UpdateNodeVersions(ctx context.Context, id int64, versions map[string]string) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM nodes_versions WHERE node_id=?", id)
+	if err != nil {
+		return fmt.Errorf("Failed clearing member versions: %w", err)
+	}
+
+	for component, version := range versions {
+		if version == "" {
+			continue
+		}
+
+		_, err := c.tx.ExecContext(ctx, "INSERT INTO nodes_versions (node_id, component, version) VALUES (?, ?, ?)", id, component, version)
+		if err != nil {
+			return fmt.Errorf("Failed recording member version of %q: %w", component, err)
+		}
+	}
+
+	return nil
+}
+
 // UpdateNodeRoles changes the list of roles on a member.
 func (c *ClusterTx) UpdateNodeRoles(id int64, roles []ClusterRole) error {
 	getRoleID := func(role ClusterRole) (int, error) {
@@ -1091,18 +1190,13 @@ func (c *ClusterTx) GetCandidateMembers(ctx context.Context, allMembers []NodeIn
 
 		// Consider target architectures if specified.
 		if targetArchitectures != nil {
-			// Get member personalities too.
-			personalities, err := osarch.ArchitecturePersonalities(member.Architecture)
+			supported, err := member.SupportsArchitecture(targetArchitectures)
 			if err != nil {
 				return nil, err
 			}
 
-			supportedArchitectures := append([]int{member.Architecture}, personalities...)
-			for _, supportedArchitecture := range supportedArchitectures {
-				if shared.ValueInSlice(supportedArchitecture, targetArchitectures) {
-					candidateMembers = append(candidateMembers, member)
-					break
-				}
+			if supported {
+				candidateMembers = append(candidateMembers, member)
 			}
 		} else {
 			// Otherwise consider member a candidate irrespective of architecture.
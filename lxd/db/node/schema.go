@@ -33,6 +33,15 @@ CREATE TABLE raft_nodes (
     name TEXT NOT NULL default "",
     UNIQUE (address)
 );
+CREATE TABLE storage_pools_usage (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	pool TEXT NOT NULL,
+	used INTEGER NOT NULL,
+	total INTEGER NOT NULL,
+	date DATETIME NOT NULL
+);
+CREATE INDEX storage_pools_usage_pool_date_idx ON storage_pools_usage (pool,
+    date);
 
-INSERT INTO schema (version, updated_at) VALUES (43, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (44, strftime("%s"))
 `
@@ -99,6 +99,7 @@ var updates = map[int]schema.Update{
 	41: updateFromV40,
 	42: updateFromV41,
 	43: updateFromV42,
+	44: updateFromV43,
 }
 
 // UpdateFromPreClustering is the last schema version where clustering support
@@ -107,6 +108,22 @@ const UpdateFromPreClustering = 36
 
 // Schema updates begin here
 
+// updateFromV43 adds a table recording the usage of the storage pools over time.
+func updateFromV43(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE storage_pools_usage (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	pool TEXT NOT NULL,
+	used INTEGER NOT NULL,
+	total INTEGER NOT NULL,
+	date DATETIME NOT NULL
+);
+CREATE INDEX storage_pools_usage_pool_date_idx ON storage_pools_usage (pool, date);
+`
+	_, err := tx.Exec(stmt)
+	return err
+}
+
 // updateFromV42 ensures key and value fields in config table are TEXT NOT NULL.
 func updateFromV42(ctx context.Context, tx *sql.Tx) error {
 	stmt := `
@@ -76,6 +76,12 @@ const (
 	RenewServerCertificate
 	RemoveExpiredTokens
 	ClusterHeal
+	TombstonesPrune
+	InstanceRemap
+	BackupsScrub
+	DatabaseSnapshotCreate
+	DatabaseSnapshotRestore
+	Benchmark
 )
 
 // Description return a human-readable description of the operation type.
@@ -199,6 +205,18 @@ func (t Type) Description() string {
 		return "Remove expired tokens"
 	case ClusterHeal:
 		return "Healing cluster"
+	case TombstonesPrune:
+		return "Pruning expired tombstones"
+	case InstanceRemap:
+		return "Remapping instance"
+	case BackupsScrub:
+		return "Scrubbing the backup deduplication store"
+	case DatabaseSnapshotCreate:
+		return "Shipping database snapshot"
+	case DatabaseSnapshotRestore:
+		return "Restoring database snapshot"
+	case Benchmark:
+		return "Running benchmark"
 	default:
 		return "Executing operation"
 	}
@@ -254,6 +272,8 @@ func (t Type) Permission() (entity.Type, auth.Entitlement) {
 		return entity.TypeInstance, auth.EntitlementCanEdit
 	case InstanceRebuild:
 		return entity.TypeInstance, auth.EntitlementCanEdit
+	case InstanceRemap:
+		return entity.TypeInstance, auth.EntitlementCanEdit
 	case SnapshotRestore:
 		return entity.TypeInstance, auth.EntitlementCanEdit
 
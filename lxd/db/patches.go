@@ -30,3 +30,11 @@ func (n *Node) MarkPatchAsApplied(patch string) error {
 	_, err := n.db.Exec(stmt, patch)
 	return err
 }
+
+// UnmarkPatchAsApplied removes the record of the patch with the given name being applied on this node, so that
+// it gets applied again.
+func (n *Node) UnmarkPatchAsApplied(patch string) error {
+	stmt := `DELETE FROM patches WHERE name=?`
+	_, err := n.db.Exec(stmt, patch)
+	return err
+}
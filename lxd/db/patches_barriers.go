@@ -0,0 +1,73 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+)
+
+// Cluster member states of a patch barrier which isn't reached by all cluster members yet.
+const (
+	PatchBarrierReached = "reached"
+	PatchBarrierPending = "pending"
+)
+
+// CreatePatchBarrierArrival records that this cluster member reached the patch barrier with the given name.
+func (c *ClusterTx) CreatePatchBarrierArrival(ctx context.Context, name string) error {
+	stmt := `
+INSERT OR REPLACE INTO patches_barriers (node_id, name, reached_at)
+  VALUES (?, ?, ?)
+`
+	_, err := c.tx.ExecContext(ctx, stmt, c.nodeID, name, time.Now().UTC())
+	return err
+}
+
+// GetPatchBarrierMissingMembers returns the names of the cluster members which haven't reached the patch barrier
+// with the given name yet. Members which are still joining the cluster are ignored.
+func (c *ClusterTx) GetPatchBarrierMissingMembers(ctx context.Context, name string) ([]string, error) {
+	q := `
+SELECT nodes.name
+  FROM nodes
+  WHERE nodes.state != ?
+    AND nodes.id NOT IN (SELECT patches_barriers.node_id FROM patches_barriers WHERE patches_barriers.name = ?)
+  ORDER BY nodes.name
+`
+	return query.SelectStrings(ctx, c.tx, q, ClusterMemberStatePending, name)
+}
+
+// GetNodePatchBarriers returns the patch barriers which aren't reached by all cluster members yet, along with the
+// state of the cluster member with the given ID for each of them.
+func (c *ClusterTx) GetNodePatchBarriers(ctx context.Context, nodeID int64) (map[string]string, error) {
+	q := `
+SELECT patches_barriers.name,
+       EXISTS (SELECT 1 FROM patches_barriers AS reached WHERE reached.name = patches_barriers.name AND reached.node_id = ?)
+  FROM patches_barriers
+  GROUP BY patches_barriers.name
+  HAVING COUNT(*) < (SELECT COUNT(*) FROM nodes WHERE nodes.state != ?)
+`
+	barriers := map[string]string{}
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var name string
+		var reached bool
+
+		err := scan(&name, &reached)
+		if err != nil {
+			return err
+		}
+
+		barriers[name] = PatchBarrierPending
+		if reached {
+			barriers[name] = PatchBarrierReached
+		}
+
+		return nil
+	}, nodeID, ClusterMemberStatePending)
+	if err != nil {
+		return nil, err
+	}
+
+	return barriers, nil
+}
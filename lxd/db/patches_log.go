@@ -0,0 +1,71 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+)
+
+// PatchLogEntry records a patch applied on a cluster member.
+type PatchLogEntry struct {
+	Name       string    `json:"name"        yaml:"name"`
+	Location   string    `json:"location"    yaml:"location"`
+	Stage      string    `json:"stage"       yaml:"stage"`
+	Status     string    `json:"status"      yaml:"status"`
+	Error      string    `json:"error"       yaml:"error"`
+	Summary    string    `json:"summary"     yaml:"summary"`
+	StartedAt  time.Time `json:"started_at"  yaml:"started_at"`
+	FinishedAt time.Time `json:"finished_at" yaml:"finished_at"`
+	Duration   int64     `json:"duration"    yaml:"duration"`
+}
+
+// CreatePatchLogEntry records that a patch was applied, or failed to apply, on this cluster member.
+func (c *ClusterTx) CreatePatchLogEntry(ctx context.Context, entry PatchLogEntry) error {
+	stmt := `
+INSERT INTO patches_log (node_id, name, stage, status, error, summary, started_at, finished_at)
+  VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+	_, err := c.tx.ExecContext(ctx, stmt, c.nodeID, entry.Name, entry.Stage, entry.Status, entry.Error, entry.Summary, entry.StartedAt, entry.FinishedAt)
+	return err
+}
+
+// GetPatchLogEntries returns the patches applied on all cluster members, oldest first.
+// If name isn't empty, only the entries of the patch with that name are returned.
+func (c *ClusterTx) GetPatchLogEntries(ctx context.Context, name string) ([]PatchLogEntry, error) {
+	q := `
+SELECT patches_log.name, nodes.name, patches_log.stage, patches_log.status, patches_log.error, patches_log.summary,
+       patches_log.started_at, patches_log.finished_at
+  FROM patches_log
+  JOIN nodes ON patches_log.node_id=nodes.id
+`
+	args := []any{}
+	if name != "" {
+		q += " WHERE patches_log.name=?"
+		args = append(args, name)
+	}
+
+	q += " ORDER BY patches_log.id"
+
+	entries := []PatchLogEntry{}
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		entry := PatchLogEntry{}
+
+		err := scan(&entry.Name, &entry.Location, &entry.Stage, &entry.Status, &entry.Error, &entry.Summary, &entry.StartedAt, &entry.FinishedAt)
+		if err != nil {
+			return err
+		}
+
+		entry.Duration = entry.FinishedAt.Sub(entry.StartedAt).Nanoseconds()
+		entries = append(entries, entry)
+
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
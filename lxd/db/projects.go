@@ -4,6 +4,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/canonical/lxd/lxd/db/cluster"
 )
@@ -26,3 +28,31 @@ func (db *DB) GetProject(ctx context.Context, projectName string) (*cluster.Proj
 
 	return p, nil
 }
+
+// UpdateProjectDiskUsage records the disk usage of the volumes of the project that are accounted for by this member.
+func (c *ClusterTx) UpdateProjectDiskUsage(ctx context.Context, projectName string, usage int64) error {
+	_, err := c.tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO projects_disk_usage (project_id, node_id, usage, updated_at)
+		VALUES ((SELECT id FROM projects WHERE name = ?), ?, ?, ?)
+	`, projectName, c.nodeID, usage, time.Now().UTC())
+
+	return err
+}
+
+// GetProjectDiskUsage returns the disk usage of the project across all members, as last recorded by the members.
+// The second return value is false if no usage was recorded yet.
+func (c *ClusterTx) GetProjectDiskUsage(ctx context.Context, projectName string) (int64, bool, error) {
+	var usage sql.NullInt64
+
+	err := c.tx.QueryRowContext(ctx, `
+		SELECT SUM(projects_disk_usage.usage)
+		FROM projects_disk_usage
+		JOIN projects ON projects.id = projects_disk_usage.project_id
+		WHERE projects.name = ?
+	`, projectName).Scan(&usage)
+	if err != nil {
+		return -1, false, err
+	}
+
+	return usage.Int64, usage.Valid, nil
+}
@@ -0,0 +1,30 @@
+package query
+
+import (
+	"errors"
+
+	"github.com/canonical/go-dqlite/driver"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/canonical/lxd/lxd/db/dberr"
+)
+
+// WrapError converts the constraint violations reported by the dqlite and SQLite drivers into the typed errors
+// of the dberr package, so that they can be checked with errors.Is. Other errors are returned as is.
+func WrapError(err error) error {
+	if err == nil || errors.Is(err, dberr.ErrConflict) || errors.Is(err, dberr.ErrConstraint) {
+		return err
+	}
+
+	var dqliteErr driver.Error
+	if errors.As(err, &dqliteErr) {
+		return dberr.FromSQLiteCode(dqliteErr.Code, err)
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return dberr.FromSQLiteCode(int(sqliteErr.ExtendedCode), err)
+	}
+
+	return err
+}
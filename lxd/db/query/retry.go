@@ -3,6 +3,7 @@ package query
 import (
 	"context"
 	"database/sql"
+	sqlDriver "database/sql/driver"
 	"errors"
 	"net/http"
 	"strings"
@@ -16,45 +17,87 @@ import (
 	"github.com/canonical/lxd/shared/logger"
 )
 
-const maxRetries = 250
+// RetryPolicy defines how transient database errors are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. It doubles after each retry, with some jitter.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between two attempts.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the policy used by Retry. It keeps retrying for about 25s, which is enough to ride out a
+// dqlite leader election or a busy database.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    30,
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     time.Second,
+}
 
 // Retry wraps a function that interacts with the database, and retries it in
 // case a transient error is hit.
 //
 // This should by typically used to wrap transactions.
 func Retry(ctx context.Context, f func(ctx context.Context) error) error {
-	// TODO: the retry loop should be configurable.
-	var err error
-	for i := 0; i < maxRetries; i++ {
-		err = f(ctx)
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				break
-			}
-
-			// No point in re-trying or logging a no-row or not found error.
-			if errors.Is(err, sql.ErrNoRows) || api.StatusErrorCheck(err, http.StatusNotFound) {
-				break
-			}
-
-			// Process actual errors.
-			if IsRetriableError(err) {
-				if i == maxRetries {
-					logger.Warn("Database error, giving up", logger.Ctx{"attempt": i, "err": err})
-					break
-				}
-
-				logger.Debug("Database error, retrying", logger.Ctx{"attempt": i, "err": err})
-				time.Sleep(jitter.Deviation(nil, 0.8)(100 * time.Millisecond))
-				continue
-			} else {
-				logger.Debug("Database error", logger.Ctx{"err": err})
-			}
+	return RetryWithPolicy(ctx, DefaultRetryPolicy, f)
+}
+
+// RetryWithPolicy wraps a function that interacts with the database, and retries it according to the policy in
+// case a transient error is hit.
+//
+// Retrying stops early when the context is cancelled, or when waiting for the next attempt would go past the
+// context deadline. In both cases the last error returned by the function is returned.
+func RetryWithPolicy(ctx context.Context, policy RetryPolicy, f func(ctx context.Context) error) error {
+	backoff := policy.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := f(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+
+		// No point in re-trying or logging a no-row or not found error.
+		if errors.Is(err, sql.ErrNoRows) || api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
 		}
-		break
-	}
 
-	return err
+		if !IsRetriableError(err) {
+			logger.Debug("Database error", logger.Ctx{"err": err})
+			return err
+		}
+
+		if attempt >= policy.MaxAttempts {
+			logger.Warn("Database error, giving up", logger.Ctx{"attempt": attempt, "err": err})
+			return err
+		}
+
+		delay := jitter.Deviation(nil, 0.8)(backoff)
+
+		deadline, ok := ctx.Deadline()
+		if ok && time.Now().Add(delay).After(deadline) {
+			logger.Warn("Database error, giving up before context deadline", logger.Ctx{"attempt": attempt, "err": err})
+			return err
+		}
+
+		logger.Debug("Database error, retrying", logger.Ctx{"attempt": attempt, "delay": delay, "err": err})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*2, policy.MaxBackoff)
+	}
 }
 
 // IsRetriableError returns true if the given error might be transient and the
@@ -62,7 +105,12 @@ func Retry(ctx context.Context, f func(ctx context.Context) error) error {
 func IsRetriableError(err error) bool {
 	var dErr *driver.Error
 
-	if errors.As(err, &dErr) && dErr.Code == driver.ErrBusy {
+	if errors.As(err, &dErr) && (dErr.Code == driver.ErrBusy || dErr.Code == driver.ErrBusyRecovery || dErr.Code == driver.ErrBusySnapshot) {
+		return true
+	}
+
+	// The dqlite leader is being elected, or the connection to it was lost due to a leadership change.
+	if errors.Is(err, driver.ErrNoAvailableLeader) || errors.Is(err, sqlDriver.ErrBadConn) {
 		return true
 	}
 
@@ -0,0 +1,79 @@
+package query_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/db/query"
+)
+
+var testRetryPolicy = query.RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     2 * time.Millisecond,
+}
+
+// Transient errors are retried until the function succeeds.
+func TestRetryWithPolicy_Transient(t *testing.T) {
+	attempts := 0
+	err := query.RetryWithPolicy(context.Background(), testRetryPolicy, func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return sqlite3.ErrBusy
+		}
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+// Other errors are returned right away.
+func TestRetryWithPolicy_NotRetriable(t *testing.T) {
+	for _, want := range []error{fmt.Errorf("boom"), sql.ErrNoRows} {
+		attempts := 0
+		err := query.RetryWithPolicy(context.Background(), testRetryPolicy, func(ctx context.Context) error {
+			attempts++
+			return want
+		})
+		assert.Equal(t, want, err)
+		assert.Equal(t, 1, attempts)
+	}
+}
+
+// Retrying stops after the maximum number of attempts.
+func TestRetryWithPolicy_MaxAttempts(t *testing.T) {
+	attempts := 0
+	err := query.RetryWithPolicy(context.Background(), testRetryPolicy, func(ctx context.Context) error {
+		attempts++
+		return sqlite3.ErrLocked
+	})
+	assert.ErrorIs(t, err, sqlite3.ErrLocked)
+	assert.Equal(t, testRetryPolicy.MaxAttempts, attempts)
+}
+
+// Retrying stops when the next attempt would be past the context deadline.
+func TestRetryWithPolicy_Deadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	policy := query.RetryPolicy{
+		MaxAttempts:    100,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Second,
+	}
+
+	attempts := 0
+	err := query.RetryWithPolicy(ctx, policy, func(ctx context.Context) error {
+		attempts++
+		return sqlite3.ErrBusy
+	})
+	assert.ErrorIs(t, err, sqlite3.ErrBusy)
+	assert.Equal(t, 1, attempts)
+}
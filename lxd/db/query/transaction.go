@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/canonical/lxd/lxd/fault"
 	"github.com/canonical/lxd/shared/logger"
 )
 
@@ -28,7 +29,12 @@ func Transaction(ctx context.Context, db *sql.DB, f func(context.Context, *sql.T
 
 	err = f(ctx, tx)
 	if err != nil {
-		return rollback(tx, err)
+		return rollback(tx, WrapError(err))
+	}
+
+	delay := fault.DatabaseCommitDelay()
+	if delay > 0 {
+		time.Sleep(delay)
 	}
 
 	err = tx.Commit()
@@ -36,14 +42,15 @@ func Transaction(ctx context.Context, db *sql.DB, f func(context.Context, *sql.T
 		err = nil // Ignore duplicate commits/rollbacks
 	}
 
-	return err
+	return WrapError(err)
 }
 
 // Rollback a transaction after the given error occurred. If the rollback
 // succeeds the given error is returned, otherwise a new error that wraps it
 // gets generated and returned.
 func rollback(tx *sql.Tx, reason error) error {
-	err := Retry(context.TODO(), func(_ context.Context) error { return tx.Rollback() })
+	// Use a fresh context as the rollback must happen even if the context of the transaction was cancelled.
+	err := Retry(context.Background(), func(_ context.Context) error { return tx.Rollback() })
 	if err != nil {
 		logger.Warnf("Failed to rollback transaction after error (%v): %v", reason, err)
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/canonical/lxd/lxd/db/dberr"
 	"github.com/canonical/lxd/lxd/db/query"
 )
 
@@ -42,6 +43,31 @@ func TestTransaction_FunctionError(t *testing.T) {
 	assert.NotContains(t, tables, "test")
 }
 
+// Constraint violations are returned as typed errors.
+func TestTransaction_ConstraintError(t *testing.T) {
+	db := newDB(t)
+
+	_, err := db.Exec("CREATE TABLE test (id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE)")
+	require.NoError(t, err)
+
+	_, err = db.Exec("INSERT INTO test (name) VALUES ('a')")
+	require.NoError(t, err)
+
+	err = query.Transaction(context.TODO(), db, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.Exec("INSERT INTO test (name) VALUES ('a')")
+		return err
+	})
+	assert.ErrorIs(t, err, dberr.ErrConflict)
+	assert.EqualError(t, err, "UNIQUE constraint failed: test.name")
+
+	err = query.Transaction(context.TODO(), db, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.Exec("INSERT INTO test (name) VALUES (NULL)")
+		return err
+	})
+	assert.ErrorIs(t, err, dberr.ErrConstraint)
+	assert.NotErrorIs(t, err, dberr.ErrConflict)
+}
+
 // Return a new in-memory SQLite database.
 func newDB(t *testing.T) *sql.DB {
 	db, err := sql.Open("sqlite3", ":memory:")
@@ -0,0 +1,184 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetSeccompPolicies returns the names of existing syscall interception policies in the project.
+func (c *ClusterTx) GetSeccompPolicies(ctx context.Context, projectName string) ([]string, error) {
+	q := `
+		SELECT seccomp_policies.name
+		FROM seccomp_policies
+		JOIN projects ON projects.id = seccomp_policies.project_id
+		WHERE projects.name = ?
+		ORDER BY seccomp_policies.id
+	`
+
+	var policyNames []string
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var policyName string
+
+		err := scan(&policyName)
+		if err != nil {
+			return err
+		}
+
+		policyNames = append(policyNames, policyName)
+
+		return nil
+	}, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	return policyNames, nil
+}
+
+// GetSeccompPolicyByName returns the syscall interception policy with the given name in the project.
+func (c *ClusterTx) GetSeccompPolicyByName(ctx context.Context, projectName string, name string) (int64, *api.SeccompPolicy, error) {
+	var id = int64(-1)
+
+	policy := api.SeccompPolicy{
+		Name: name,
+	}
+
+	q := `
+		SELECT seccomp_policies.id, seccomp_policies.description
+		FROM seccomp_policies
+		JOIN projects ON projects.id = seccomp_policies.project_id
+		WHERE projects.name = ? AND seccomp_policies.name = ?
+		LIMIT 1
+	`
+
+	err := c.tx.QueryRowContext(ctx, q, projectName, name).Scan(&id, &policy.Description)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Syscall interception policy not found")
+		}
+
+		return -1, nil, err
+	}
+
+	err = seccompPolicyConfig(ctx, c, id, &policy)
+	if err != nil {
+		return -1, nil, fmt.Errorf("Failed loading config: %w", err)
+	}
+
+	return id, &policy, nil
+}
+
+// seccompPolicyConfig populates the config map of the syscall interception policy with the given ID.
+func seccompPolicyConfig(ctx context.Context, tx *ClusterTx, id int64, policy *api.SeccompPolicy) error {
+	q := `
+		SELECT key, value
+		FROM seccomp_policies_config
+		WHERE seccomp_policy_id=?
+	`
+
+	policy.Config = make(map[string]string)
+	return query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		_, found := policy.Config[key]
+		if found {
+			return fmt.Errorf("Duplicate config row found for key %q for syscall interception policy ID %d", key, id)
+		}
+
+		policy.Config[key] = value
+
+		return nil
+	}, id)
+}
+
+// CreateSeccompPolicy creates a new syscall interception policy in the project.
+func (c *ClusterTx) CreateSeccompPolicy(ctx context.Context, projectName string, info *api.SeccompPoliciesPost) (int64, error) {
+	// Insert a new syscall interception policy record.
+	result, err := c.tx.ExecContext(ctx, `
+			INSERT INTO seccomp_policies (project_id, name, description)
+			VALUES ((SELECT id FROM projects WHERE name = ?), ?, ?)
+		`, projectName, info.Name, info.Description)
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	err = seccompPolicyConfigAdd(c.tx, id, info.Config)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// seccompPolicyConfigAdd inserts syscall interception policy config keys.
+func seccompPolicyConfigAdd(tx *sql.Tx, id int64, config map[string]string) error {
+	sql := "INSERT INTO seccomp_policies_config (seccomp_policy_id, key, value) VALUES(?, ?, ?)"
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateSeccompPolicy updates the syscall interception policy with the given ID.
+func (c *ClusterTx) UpdateSeccompPolicy(ctx context.Context, id int64, config *api.SeccompPolicyPut) error {
+	_, err := c.tx.ExecContext(ctx, `
+		UPDATE seccomp_policies
+		SET description=?
+		WHERE id=?
+	`, config.Description, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "DELETE FROM seccomp_policies_config WHERE seccomp_policy_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	err = seccompPolicyConfigAdd(c.tx, id, config.Config)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteSeccompPolicy deletes the syscall interception policy.
+func (c *ClusterTx) DeleteSeccompPolicy(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM seccomp_policies WHERE id=?", id)
+
+	return err
+}
@@ -10,8 +10,7 @@ import (
 	"net/http"
 	"strings"
 
-	dqliteDriver "github.com/canonical/go-dqlite/driver"
-
+	"github.com/canonical/lxd/lxd/db/dberr"
 	"github.com/canonical/lxd/lxd/db/query"
 	"github.com/canonical/lxd/shared/api"
 )
@@ -192,7 +191,7 @@ func (c *ClusterTx) GetStoragePoolBucket(ctx context.Context, poolID int64, proj
 	if (err == nil && bucketsLen <= 0) || errors.Is(err, sql.ErrNoRows) {
 		return nil, api.StatusErrorf(http.StatusNotFound, "Storage bucket not found")
 	} else if err == nil && bucketsLen > 1 {
-		return nil, api.StatusErrorf(http.StatusConflict, "Storage bucket found on more than one cluster member. Please target a specific member")
+		return nil, api.ReasonErrorf(api.ErrorReasonAmbiguousTarget, nil, "Storage bucket found on more than one cluster member. Please target a specific member")
 	} else if err != nil {
 		return nil, err
 	}
@@ -301,10 +300,8 @@ func (c *ClusterTx) CreateStoragePoolBucket(ctx context.Context, poolID int64, p
 		VALUES (?, ?, ?, ?, (SELECT id FROM projects WHERE name = ?))
 		`, poolID, nodeID, info.Name, info.Description, projectName)
 	if err != nil {
-		var dqliteErr dqliteDriver.Error
-		// Detect SQLITE_CONSTRAINT_UNIQUE (2067) errors.
-		if errors.As(err, &dqliteErr) && dqliteErr.Code == 2067 {
-			return -1, api.StatusErrorf(http.StatusConflict, "A bucket for that name already exists")
+		if errors.Is(query.WrapError(err), dberr.ErrConflict) {
+			return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "A bucket for that name already exists")
 		}
 
 		return -1, err
@@ -516,7 +513,7 @@ func (c *ClusterTx) CreateStoragePoolBucketKey(ctx context.Context, bucketID int
 	if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
 		return -1, err
 	} else if bucket != nil {
-		return -1, api.StatusErrorf(http.StatusConflict, "A bucket key using that access key already exists on this server")
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "A bucket key using that access key already exists on this server")
 	}
 
 	// Insert a new Storage Bucket Key record.
@@ -526,10 +523,8 @@ func (c *ClusterTx) CreateStoragePoolBucketKey(ctx context.Context, bucketID int
 		VALUES (?, ?, ?, ?, ?, ?)
 		`, bucketID, info.Name, info.Description, info.Role, info.AccessKey, info.SecretKey)
 	if err != nil {
-		var dqliteErr dqliteDriver.Error
-		// Detect SQLITE_CONSTRAINT_UNIQUE (2067) errors.
-		if errors.As(err, &dqliteErr) && dqliteErr.Code == 2067 {
-			return -1, api.StatusErrorf(http.StatusConflict, "A bucket key for that name already exists")
+		if errors.Is(query.WrapError(err), dberr.ErrConflict) {
+			return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "A bucket key for that name already exists")
 		}
 
 		return -1, err
@@ -550,7 +545,7 @@ func (c *ClusterTx) UpdateStoragePoolBucketKey(ctx context.Context, bucketID int
 	if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
 		return err
 	} else if bucket != nil && bucket.ID != bucketID {
-		return api.StatusErrorf(http.StatusConflict, "A bucket key using that access key already exists on this server")
+		return api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "A bucket key using that access key already exists on this server")
 	}
 
 	// Update existing Storage Bucket Key record.
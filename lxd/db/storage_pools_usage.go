@@ -0,0 +1,79 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+)
+
+// StoragePoolUsageSample holds the disk space usage of a storage pool at a given time.
+type StoragePoolUsageSample struct {
+	Used  uint64
+	Total uint64
+	Date  time.Time
+}
+
+// CreateStoragePoolUsageSample records the disk space usage of the storage pool with the given name.
+func (n *NodeTx) CreateStoragePoolUsageSample(ctx context.Context, poolName string, sample StoragePoolUsageSample) error {
+	_, err := n.tx.ExecContext(ctx, "INSERT INTO storage_pools_usage (pool, used, total, date) VALUES (?, ?, ?, ?)", poolName, int64(sample.Used), int64(sample.Total), sample.Date.UTC())
+	if err != nil {
+		return fmt.Errorf("Failed recording storage pool usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetStoragePoolUsageSamples returns the disk space usage samples of the storage pool with the given name recorded
+// since the given time, oldest first.
+func (n *NodeTx) GetStoragePoolUsageSamples(ctx context.Context, poolName string, since time.Time) ([]StoragePoolUsageSample, error) {
+	samples := []StoragePoolUsageSample{}
+
+	sql := "SELECT used, total, date FROM storage_pools_usage WHERE pool = ? AND date >= ? ORDER BY date"
+	err := query.Scan(ctx, n.tx, sql, func(scan func(dest ...any) error) error {
+		var used int64
+		var total int64
+		sample := StoragePoolUsageSample{}
+
+		err := scan(&used, &total, &sample.Date)
+		if err != nil {
+			return err
+		}
+
+		sample.Used = uint64(used)
+		sample.Total = uint64(total)
+		samples = append(samples, sample)
+
+		return nil
+	}, poolName, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching storage pool usage: %w", err)
+	}
+
+	return samples, nil
+}
+
+// PruneStoragePoolUsageSamples deletes the disk space usage samples recorded before the given time, as well as the
+// samples of storage pools that aren't in the given list.
+func (n *NodeTx) PruneStoragePoolUsageSamples(ctx context.Context, poolNames []string, before time.Time) error {
+	sql := "DELETE FROM storage_pools_usage"
+	args := []any{}
+
+	if len(poolNames) > 0 {
+		sql += fmt.Sprintf(" WHERE date < ? OR pool NOT IN %s", query.Params(len(poolNames)))
+		args = append(args, before.UTC())
+		for _, poolName := range poolNames {
+			args = append(args, poolName)
+		}
+	}
+
+	_, err := n.tx.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("Failed pruning storage pool usage: %w", err)
+	}
+
+	return nil
+}
@@ -266,7 +266,7 @@ func (c *ClusterTx) GetStoragePoolVolume(ctx context.Context, poolID int64, proj
 	if (err == nil && volumesLen <= 0) || errors.Is(err, sql.ErrNoRows) {
 		return nil, api.StatusErrorf(http.StatusNotFound, "Storage volume not found")
 	} else if err == nil && volumesLen > 1 {
-		return nil, api.StatusErrorf(http.StatusConflict, "Storage volume found on more than one cluster member. Please target a specific member")
+		return nil, api.ReasonErrorf(api.ErrorReasonAmbiguousTarget, nil, "Storage volume found on more than one cluster member. Please target a specific member")
 	} else if err != nil {
 		return nil, err
 	}
@@ -21,6 +21,9 @@ type NodeTx struct {
 type ClusterTx struct {
 	tx     *sql.Tx // Handle to a transaction in the cluster dqlite database.
 	nodeID int64   // Node ID of this LXD instance.
+
+	cache           *EntityCache // Entity cache of the cluster database (optional).
+	cacheGeneration uint64       // Generation of the entity cache when the transaction started.
 }
 
 // Tx retrieves the underlying transaction on the cluster database.
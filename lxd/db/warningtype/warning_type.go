@@ -58,6 +58,12 @@ const (
 	StoragePoolUnvailable
 	// UnableToUpdateClusterCertificate represents the unable to update cluster certificate warning.
 	UnableToUpdateClusterCertificate
+	// InstanceNetworkRestricted represents an instance using a network that isn't allowed by its project.
+	InstanceNetworkRestricted
+	// OVNDatabaseUnavailable represents an OVN database endpoint that cannot be reached from the local server.
+	OVNDatabaseUnavailable
+	// DatabaseOrphans represents rows of the global database referencing entities that don't exist.
+	DatabaseOrphans
 )
 
 // TypeNames associates a warning code to its name.
@@ -88,6 +94,9 @@ var TypeNames = map[Type]string{
 	InstanceTypeNotOperational:             "Instance type not operational",
 	StoragePoolUnvailable:                  "Storage pool unavailable",
 	UnableToUpdateClusterCertificate:       "Unable to update cluster certificate",
+	InstanceNetworkRestricted:              "Instance network not allowed in project",
+	OVNDatabaseUnavailable:                 "OVN database unavailable",
+	DatabaseOrphans:                        "Orphaned database rows",
 }
 
 // Severity returns the severity of the warning type.
@@ -145,6 +154,12 @@ func (t Type) Severity() Severity {
 		return SeverityHigh
 	case UnableToUpdateClusterCertificate:
 		return SeverityLow
+	case InstanceNetworkRestricted:
+		return SeverityModerate
+	case OVNDatabaseUnavailable:
+		return SeverityHigh
+	case DatabaseOrphans:
+		return SeverityModerate
 	}
 
 	return SeverityLow
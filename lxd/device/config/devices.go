@@ -24,19 +24,31 @@ func (device Device) Clone() Device {
 
 // Validate accepts a map of field/validation functions to run against the device's config.
 func (device Device) Validate(rules map[string]func(value string) error) error {
-	checkedFields := map[string]struct{}{}
+	checkedFields := make([]string, 0, len(rules))
+	for k := range rules {
+		checkedFields = append(checkedFields, k)
+	}
+
+	// Validate the fields in a stable order so that the same config always reports the same error.
+	sort.Strings(checkedFields)
 
-	for k, validator := range rules {
-		checkedFields[k] = struct{}{} // Mark field as checked.
-		err := validator(device[k])
+	for _, k := range checkedFields {
+		err := rules[k](device[k])
 		if err != nil {
 			return fmt.Errorf("Invalid value for device option %q: %w", k, err)
 		}
 	}
 
-	// Look for any unchecked fields, as these are unknown fields and validation should fail.
+	fields := make([]string, 0, len(device))
 	for k := range device {
-		_, checked := checkedFields[k]
+		fields = append(fields, k)
+	}
+
+	sort.Strings(fields)
+
+	// Look for any unchecked fields, as these are unknown fields and validation should fail.
+	for _, k := range fields {
+		_, checked := rules[k]
 		if checked {
 			continue
 		}
@@ -72,7 +84,7 @@ func (device Device) Validate(rules map[string]func(value string) error) error {
 			continue
 		}
 
-		return fmt.Errorf("Invalid device option %q", k)
+		return fmt.Errorf("Invalid device option %q for device type %q", k, device["type"])
 	}
 
 	return nil
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,3 +50,26 @@ func TestSortableDevices(t *testing.T) {
 	result = devices.Reversed()
 	assert.Equal(t, expectedReversed, result)
 }
+
+func TestDeviceValidate(t *testing.T) {
+	rules := map[string]func(value string) error{
+		"a": func(value string) error { return errors.New("bad a") },
+		"b": func(value string) error { return errors.New("bad b") },
+	}
+
+	// Rules are checked in a stable order.
+	for i := 0; i < 10; i++ {
+		err := Device{"type": "disk"}.Validate(rules)
+		assert.EqualError(t, err, `Invalid value for device option "a": bad a`)
+	}
+
+	rules = map[string]func(value string) error{
+		"path": func(value string) error { return nil },
+	}
+
+	err := Device{"type": "disk", "path": "/", "user.foo": "bar", "zzz": "1", "foo": "1"}.Validate(rules)
+	assert.EqualError(t, err, `Invalid device option "foo" for device type "disk"`)
+
+	err = Device{"type": "disk", "path": "/", "user.foo": "bar", "initial.size": "1GiB"}.Validate(rules)
+	assert.NoError(t, err)
+}
@@ -23,10 +23,28 @@ func newByType(state *state.State, projectName string, conf deviceConfig.Device)
 	}
 
 	// Lookup device type implementation.
+	subType := nicType
+	if conf["type"] == "gpu" {
+		subType = conf["gputype"]
+	}
+
+	dev := newByTypeName(conf["type"], subType)
+
+	// Check a valid device type has been found.
+	if dev == nil {
+		return nil, ErrUnsupportedDevType
+	}
+
+	return dev, nil
+}
+
+// newByTypeName returns a new unitialised device for the given device type and sub-type, that is the NIC type of
+// network devices or the GPU type of GPU devices. Returns nil if the type isn't supported.
+func newByTypeName(devType string, subType string) device {
 	var dev device
-	switch conf["type"] {
+	switch devType {
 	case "nic":
-		switch nicType {
+		switch subType {
 		case "physical":
 			dev = &nicPhysical{}
 		case "ipvlan":
@@ -46,7 +64,7 @@ func newByType(state *state.State, projectName string, conf deviceConfig.Device)
 		}
 
 	case "infiniband":
-		switch nicType {
+		switch subType {
 		case "physical":
 			dev = &infinibandPhysical{}
 		case "sriov":
@@ -54,7 +72,7 @@ func newByType(state *state.State, projectName string, conf deviceConfig.Device)
 		}
 
 	case "gpu":
-		switch conf["gputype"] {
+		switch subType {
 		case "mig":
 			dev = &gpuMIG{}
 		case "mdev":
@@ -83,12 +101,7 @@ func newByType(state *state.State, projectName string, conf deviceConfig.Device)
 		dev = &pci{}
 	}
 
-	// Check a valid device type has been found.
-	if dev == nil {
-		return nil, ErrUnsupportedDevType
-	}
-
-	return dev, nil
+	return dev
 }
 
 // load instantiates a device and initialises its internal state. It does not validate the config supplied.
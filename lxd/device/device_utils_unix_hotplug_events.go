@@ -25,6 +25,9 @@ type UnixHotplugEvent struct {
 	Subsystem   string
 	UeventParts []string
 	UeventLen   int
+
+	// Properties contains the udev properties of the device.
+	Properties map[string]string
 }
 
 // unixHotplugHandlers stores the event handler callbacks for Unix hotplug events.
@@ -94,7 +97,7 @@ func UnixHotplugRunHandlers(state *state.State, event *UnixHotplugEvent) {
 }
 
 // UnixHotplugNewEvent instantiates a new UnixHotplugEvent struct.
-func UnixHotplugNewEvent(action string, vendor string, product string, major string, minor string, subsystem string, devname string, ueventParts []string, ueventLen int) (UnixHotplugEvent, error) {
+func UnixHotplugNewEvent(action string, vendor string, product string, major string, minor string, subsystem string, devname string, ueventParts []string, ueventLen int, properties map[string]string) (UnixHotplugEvent, error) {
 	majorInt, err := strconv.ParseUint(major, 10, 32)
 	if err != nil {
 		return UnixHotplugEvent{}, err
@@ -115,5 +118,6 @@ func UnixHotplugNewEvent(action string, vendor string, product string, major str
 		subsystem,
 		ueventParts,
 		ueventLen,
+		properties,
 	}, nil
 }
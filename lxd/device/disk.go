@@ -218,6 +218,14 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		//  required: yes
 		//  shortdesc: Source of a file system or block device
 		"source": validate.IsAny,
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=source.project)
+		// Use this option to attach a custom storage volume exported by another project (see the `security.export.projects` option of the volume).
+		// Such volumes can only be attached read-only.
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Project of the custom storage volume
+		"source.project": validate.IsAny,
 		// lxdmeta:generate(entities=device-disk; group=device-conf; key=limits.read)
 		// You can specify a value in byte/s (various suffixes supported, see {ref}`instances-limit-units`) or in IOPS (must be suffixed with `iops`).
 		// See also {ref}`storage-configure-io`.
@@ -430,6 +438,16 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 			return fmt.Errorf("Storage volumes cannot be specified as absolute paths")
 		}
 
+		if d.config["source.project"] != "" {
+			if d.config["path"] == "/" {
+				return fmt.Errorf(`The "source.project" property cannot be used with root disks`)
+			}
+
+			if shared.IsFalseOrEmpty(d.config["readonly"]) {
+				return fmt.Errorf(`Custom volumes from another project must be attached with "readonly=true"`)
+			}
+		}
+
 		// Only perform expensive instance pool volume checks when not validating a profile and after
 		// device expansion has occurred (to avoid doing it twice during instance load).
 		if d.inst != nil && !d.inst.IsSnapshot() && len(instConf.ExpandedDevices()) > 0 {
@@ -444,8 +462,7 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 
 			// Custom volume validation.
 			if d.config["source"] != "" && d.config["path"] != "/" {
-				// Derive the effective storage project name from the instance config's project.
-				storageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, instConf.Project().Name, cluster.StoragePoolVolumeTypeCustom)
+				storageProjectName, err := d.storageVolumeProject(instConf.Project().Name)
 				if err != nil {
 					return err
 				}
@@ -460,6 +477,11 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 					return fmt.Errorf("Failed loading custom volume: %w", err)
 				}
 
+				err = d.checkVolumeExported(instConf.Project().Name, dbVolume)
+				if err != nil {
+					return err
+				}
+
 				// Check storage volume is available to mount on this cluster member.
 				remoteInstance, err := storagePools.VolumeUsedByExclusiveRemoteInstancesWithProfiles(d.state, d.config["pool"], storageProjectName, &dbVolume.StorageVolume)
 				if err != nil {
@@ -630,7 +652,7 @@ func (d *disk) Register() error {
 			return err
 		}
 	} else if d.config["path"] != "/" && d.config["source"] != "" && d.config["pool"] != "" {
-		storageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, d.inst.Project().Name, cluster.StoragePoolVolumeTypeCustom)
+		storageProjectName, err := d.storageVolumeProject(d.inst.Project().Name)
 		if err != nil {
 			return err
 		}
@@ -645,6 +667,39 @@ func (d *disk) Register() error {
 	return nil
 }
 
+// storageVolumeProject returns the project of the custom volume attached by the device. This is either the project
+// set in "source.project" or the effective storage project of the instance's project.
+func (d *disk) storageVolumeProject(instProjectName string) (string, error) {
+	if d.config["source.project"] != "" {
+		return d.config["source.project"], nil
+	}
+
+	return project.StorageVolumeProject(d.state.DB.Cluster, instProjectName, cluster.StoragePoolVolumeTypeCustom)
+}
+
+// checkVolumeExported checks that a custom volume from another project is exported to the instance's project.
+func (d *disk) checkVolumeExported(instProjectName string, dbVolume *db.StorageVolume) error {
+	if d.config["source.project"] == "" {
+		return nil
+	}
+
+	storageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, instProjectName, cluster.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	// Volumes from the instance's own storage project don't need to be exported.
+	if storageProjectName == dbVolume.Project {
+		return nil
+	}
+
+	if !shared.ValueInSlice(storageProjectName, shared.SplitNTrimSpace(dbVolume.Config["security.export.projects"], ",", -1, true)) {
+		return api.StatusErrorf(http.StatusForbidden, "Custom volume %q in project %q isn't exported to project %q", dbVolume.Name, dbVolume.Project, storageProjectName)
+	}
+
+	return nil
+}
+
 // PreStartCheck checks the storage pool is available (if relevant).
 func (d *disk) PreStartCheck() error {
 	// Non-pool disks are not relevant for checking pool availability.
@@ -756,7 +811,7 @@ func (d *disk) startContainer() (*deviceConfig.RunConfig, error) {
 		// has owner shifting enabled, and if so enable shifting on this device too.
 		if ownerShift == deviceConfig.MountOwnerShiftNone && d.config["pool"] != "" {
 			// Only custom volumes can be attached currently.
-			storageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, d.inst.Project().Name, cluster.StoragePoolVolumeTypeCustom)
+			storageProjectName, err := d.storageVolumeProject(d.inst.Project().Name)
 			if err != nil {
 				return nil, err
 			}
@@ -1002,8 +1057,7 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 			if d.config["pool"] != "" {
 				var revertFunc func()
 
-				// Derive the effective storage project name from the instance config's project.
-				storageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, d.inst.Project().Name, cluster.StoragePoolVolumeTypeCustom)
+				storageProjectName, err := d.storageVolumeProject(d.inst.Project().Name)
 				if err != nil {
 					return nil, err
 				}
@@ -1527,7 +1581,7 @@ func (d *disk) mountPoolVolume() (func(), string, *storagePools.MountInfo, error
 	}
 
 	// Only custom volumes can be attached currently.
-	storageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, d.inst.Project().Name, cluster.StoragePoolVolumeTypeCustom)
+	storageProjectName, err := d.storageVolumeProject(d.inst.Project().Name)
 	if err != nil {
 		return nil, "", nil, err
 	}
@@ -1556,6 +1610,18 @@ func (d *disk) mountPoolVolume() (func(), string, *storagePools.MountInfo, error
 			return nil, "", nil, fmt.Errorf("Only filesystem volumes are supported for containers")
 		}
 
+		// Don't shift the content of volumes owned by another project.
+		if d.config["source.project"] != "" && shared.IsFalseOrEmpty(dbVolume.Config["security.shifted"]) && shared.IsFalseOrEmpty(dbVolume.Config["security.unmapped"]) {
+			instStorageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, d.inst.Project().Name, cluster.StoragePoolVolumeTypeCustom)
+			if err != nil {
+				return nil, "", nil, err
+			}
+
+			if instStorageProjectName != dbVolume.Project {
+				return nil, "", nil, fmt.Errorf(`Custom volumes from another project require "security.shifted=true" or "security.unmapped=true" to be attached to containers`)
+			}
+		}
+
 		err = d.storagePoolVolumeAttachShift(storageProjectName, d.pool.Name(), volumeName, cluster.StoragePoolVolumeTypeCustom, srcPath)
 		if err != nil {
 			return nil, "", nil, fmt.Errorf("Failed shifting storage volume %q of type %q on storage pool %q: %w", volumeName, volumeTypeName, d.pool.Name(), err)
@@ -1996,7 +2062,7 @@ func (d *disk) postStop() error {
 	// Check if pool-specific action should be taken to unmount custom volume disks.
 	if d.config["pool"] != "" && d.config["path"] != "/" {
 		// Only custom volumes can be attached currently.
-		storageProjectName, err := project.StorageVolumeProject(d.state.DB.Cluster, d.inst.Project().Name, cluster.StoragePoolVolumeTypeCustom)
+		storageProjectName, err := d.storageVolumeProject(d.inst.Project().Name)
 		if err != nil {
 			return err
 		}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/resources"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/validate"
 )
@@ -163,3 +164,22 @@ func gpuSelected(device config.Device, gpu api.ResourcesGPUCard) bool {
 		(device["productid"] != "" && gpu.ProductID != device["productid"]) ||
 		(device["id"] != "" && (gpu.DRM == nil || fmt.Sprintf("%d", gpu.DRM.ID) != device["id"])))
 }
+
+// GPUPhysicalPCIAddresses returns the PCI addresses of the host GPUs passed through by a physical GPU device.
+func GPUPhysicalPCIAddresses(device config.Device) ([]string, error) {
+	gpus, err := resources.GetGPU()
+	if err != nil {
+		return nil, err
+	}
+
+	pciAddresses := []string{}
+	for _, gpu := range gpus.Cards {
+		if gpu.PCIAddress == "" || !gpuSelected(device, gpu) {
+			continue
+		}
+
+		pciAddresses = append(pciAddresses, gpu.PCIAddress)
+	}
+
+	return pciAddresses, nil
+}
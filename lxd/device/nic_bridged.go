@@ -212,7 +212,7 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 
 		// Load managed network. api.ProjectDefaultName is used here as bridge networks don't support projects.
 		var err error
-		d.network, err = network.LoadByName(d.state, api.ProjectDefaultName, d.config["network"])
+		d.network, err = network.LoadByNameCached(d.state, api.ProjectDefaultName, d.config["network"])
 		if err != nil {
 			return fmt.Errorf("Error loading network config for %q: %w", d.config["network"], err)
 		}
@@ -248,7 +248,7 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 
 		// Check if parent is a managed network.
 		// api.ProjectDefaultName is used here as bridge networks don't support projects.
-		d.network, _ = network.LoadByName(d.state, api.ProjectDefaultName, d.config["parent"])
+		d.network, _ = network.LoadByNameCached(d.state, api.ProjectDefaultName, d.config["parent"])
 		if d.network != nil {
 			// Validate NIC settings with managed network.
 			err := checkWithManagedNetwork(d.network)
@@ -866,6 +866,14 @@ func (d *nicBridged) postStop() error {
 
 // Remove is run when the device is removed from the instance or the instance is deleted.
 func (d *nicBridged) Remove() error {
+	// Remove the instance records from the external DNS provider of the network (if configured).
+	if d.network != nil {
+		err := network.ExternalDNSRemoveInstance(d.network, d.inst.Project().Name, d.inst.Name())
+		if err != nil {
+			d.logger.Warn("Failed removing external DNS records", logger.Ctx{"err": err})
+		}
+	}
+
 	if d.config["parent"] != "" {
 		dnsmasq.ConfigMutex.Lock()
 		defer dnsmasq.ConfigMutex.Unlock()
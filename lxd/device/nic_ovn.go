@@ -109,7 +109,7 @@ func (d *nicOVN) validateConfig(instConf instance.ConfigReader) error {
 	}
 
 	// Lookup network settings and apply them to the device's config.
-	n, err := network.LoadByName(d.state, networkProjectName, d.config["network"])
+	n, err := network.LoadByNameCached(d.state, networkProjectName, d.config["network"])
 	if err != nil {
 		return fmt.Errorf("Error loading network config for %q: %w", d.config["network"], err)
 	}
@@ -991,6 +991,12 @@ func (d *nicOVN) Remove() error {
 		}
 	}
 
+	// Remove the instance records from the external DNS provider of the network (if configured).
+	err := network.ExternalDNSRemoveInstance(d.network, d.inst.Project().Name, d.inst.Name())
+	if err != nil {
+		d.logger.Warn("Failed removing external DNS records", logger.Ctx{"err": err})
+	}
+
 	return d.network.InstanceDevicePortRemove(d.inst.LocalConfig()["volatile.uuid"], d.name, d.config)
 }
 
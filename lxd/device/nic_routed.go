@@ -8,11 +8,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/canonical/lxd/lxd/db"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
 	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/network/addresspool"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/logger"
@@ -85,6 +87,18 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 	rules["ipv4.address"] = validate.Optional(validate.IsListOf(validate.IsNetworkAddressV4))
 	rules["ipv6.address"] = validate.Optional(validate.IsListOf(validate.IsNetworkAddressV6))
 	rules["gvrp"] = validate.Optional(validate.IsBool)
+	// lxdmeta:generate(entities=device-nic-routed; group=device-conf; key=ipv4.address_pool)
+	// The allocated address is added to the addresses set in `ipv4.address` and is kept until the device is removed.
+	// ---
+	//  type: string
+	//  shortdesc: Network address pool to allocate an IPv4 address from
+	rules["ipv4.address_pool"] = validate.IsAny
+	// lxdmeta:generate(entities=device-nic-routed; group=device-conf; key=ipv6.address_pool)
+	// The allocated address is added to the addresses set in `ipv6.address` and is kept until the device is removed.
+	// ---
+	//  type: string
+	//  shortdesc: Network address pool to allocate an IPv6 address from
+	rules["ipv6.address_pool"] = validate.IsAny
 	// lxdmeta:generate(entities=device-nic-routed; group=device-conf; key=ipv4.neighbor_probe)
 	//
 	// ---
@@ -124,8 +138,20 @@ func (d *nicRouted) validateConfig(instConf instance.ConfigReader) error {
 
 	// Ensure that address is set if routes is set.
 	for _, keyPrefix := range []string{"ipv4", "ipv6"} {
-		if d.config[fmt.Sprintf("%s.routes", keyPrefix)] != "" && d.config[fmt.Sprintf("%s.address", keyPrefix)] == "" {
-			return fmt.Errorf("%s.routes requires %s.address to be set", keyPrefix, keyPrefix)
+		if d.config[fmt.Sprintf("%s.routes", keyPrefix)] != "" && d.config[fmt.Sprintf("%s.address", keyPrefix)] == "" && d.config[fmt.Sprintf("%s.address_pool", keyPrefix)] == "" {
+			return fmt.Errorf("%s.routes requires %s.address or %s.address_pool to be set", keyPrefix, keyPrefix, keyPrefix)
+		}
+	}
+
+	// Ensure that the address pools exist.
+	for _, key := range []string{"ipv4.address_pool", "ipv6.address_pool"} {
+		if d.config[key] == "" {
+			continue
+		}
+
+		_, err := addresspool.LoadByName(d.state, d.config[key])
+		if err != nil {
+			return fmt.Errorf("Failed loading network address pool %q: %w", d.config[key], err)
 		}
 	}
 
@@ -276,9 +302,76 @@ func (d *nicRouted) checkIPAvailability(parent string) error {
 	return nil
 }
 
+// usesAddressPools returns whether the NIC gets addresses from network address pools.
+func (d *nicRouted) usesAddressPools() bool {
+	return d.config["ipv4.address_pool"] != "" || d.config["ipv6.address_pool"] != ""
+}
+
+// bgpOwner returns the owner of the BGP prefixes advertised for the NIC.
+func (d *nicRouted) bgpOwner() string {
+	return fmt.Sprintf("instance_%d_%s", d.inst.ID(), d.name)
+}
+
+// addPoolAddresses adds the addresses of the NIC coming from network address pools to its address settings.
+// When allocate is true, addresses are allocated from the pools if needed, otherwise only existing allocations
+// are considered. Returns the prefixes which should be advertised over BGP.
+func (d *nicRouted) addPoolAddresses(allocate bool) ([]net.IPNet, error) {
+	var prefixes []net.IPNet
+
+	for _, ipVersion := range []uint{4, 6} {
+		keyPrefix := fmt.Sprintf("ipv%d", ipVersion)
+		poolName := d.config[fmt.Sprintf("%s.address_pool", keyPrefix)]
+		if poolName == "" {
+			continue
+		}
+
+		pool, err := addresspool.LoadByName(d.state, poolName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading network address pool %q: %w", poolName, err)
+		}
+
+		var address net.IP
+		if allocate {
+			address, err = pool.Allocate(ipVersion, db.NetworkAddressPoolAllocation{InstanceID: int64(d.inst.ID()), DeviceName: d.name})
+		} else {
+			address, err = pool.InstanceAddress(ipVersion, int64(d.inst.ID()), d.name)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed allocating address from network address pool %q: %w", poolName, err)
+		}
+
+		if address == nil {
+			continue
+		}
+
+		addresses := shared.SplitNTrimSpace(d.config[fmt.Sprintf("%s.address", keyPrefix)], ",", -1, true)
+		if !shared.ValueInSlice(address.String(), addresses) {
+			d.config[fmt.Sprintf("%s.address", keyPrefix)] = strings.Join(append(addresses, address.String()), ",")
+		}
+
+		if pool.AdvertiseBGP() {
+			bits := 32
+			if ipVersion == 6 {
+				bits = 128
+			}
+
+			prefixes = append(prefixes, net.IPNet{IP: address, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+
+	return prefixes, nil
+}
+
 // Start is run when the instance is starting up (Routed mode doesn't support hot plugging).
 func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
-	err := d.validateEnvironment()
+	// Add the addresses allocated from network address pools before validating the environment.
+	bgpPrefixes, err := d.addPoolAddresses(true)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.validateEnvironment()
 	if err != nil {
 		return nil, err
 	}
@@ -490,6 +583,23 @@ func (d *nicRouted) Start() (*deviceConfig.RunConfig, error) {
 		}
 	}
 
+	// Advertise the addresses allocated from network address pools.
+	for _, prefix := range bgpPrefixes {
+		nexthop := net.ParseIP("0.0.0.0")
+		if prefix.IP.To4() == nil {
+			nexthop = net.ParseIP("::")
+		}
+
+		err = d.state.BGP.AddPrefix(prefix, nexthop, d.bgpOwner())
+		if err != nil {
+			return nil, fmt.Errorf("Failed advertising %q over BGP: %w", prefix.String(), err)
+		}
+	}
+
+	if len(bgpPrefixes) > 0 {
+		revert.Add(func() { _ = d.state.BGP.RemovePrefixByOwner(d.bgpOwner()) })
+	}
+
 	err = d.volatileSet(saveData)
 	if err != nil {
 		return nil, err
@@ -635,6 +745,20 @@ func (d *nicRouted) postStop() error {
 		d.effectiveParentName = network.GetHostDevice(d.config["parent"], d.config["vlan"])
 	}
 
+	if d.usesAddressPools() {
+		// Withdraw the addresses allocated from network address pools.
+		err := d.state.BGP.RemovePrefixByOwner(d.bgpOwner())
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		// Add the addresses allocated from network address pools so their neighbour proxy entries get removed.
+		_, err = d.addPoolAddresses(false)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	// Delete host-side interface.
 	if network.InterfaceExists(d.config["host_name"]) {
 		// Removing host-side end of veth pair will delete the peer end too.
@@ -679,6 +803,21 @@ func (d *nicRouted) postStop() error {
 	return nil
 }
 
+// Remove is run when the device is removed from the instance or the instance is deleted.
+func (d *nicRouted) Remove() error {
+	if !d.usesAddressPools() {
+		return nil
+	}
+
+	// Return the allocated addresses to the network address pools.
+	err := addresspool.ReleaseInstanceAddresses(d.state, int64(d.inst.ID()), d.name)
+	if err != nil {
+		return fmt.Errorf("Failed releasing network address pool addresses: %w", err)
+	}
+
+	return nil
+}
+
 func (d *nicRouted) ipHostAddress(ipFamily string) string {
 	key := fmt.Sprintf("%s.host_address", ipFamily)
 	if d.config[key] != "" {
@@ -0,0 +1,95 @@
+package device
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// deviceSchemaTypes lists the supported device types and sub-types along with the entity of the generated
+// configuration metadata that documents their configuration keys.
+var deviceSchemaTypes = []struct {
+	devType string
+	subType string
+	entity  string
+}{
+	{devType: "disk", entity: "device-disk"},
+	{devType: "gpu", subType: "mdev", entity: "device-gpu-mdev"},
+	{devType: "gpu", subType: "mig", entity: "device-gpu-mig"},
+	{devType: "gpu", subType: "physical", entity: "device-gpu-physical"},
+	{devType: "gpu", subType: "sriov", entity: "device-gpu-sriov"},
+	{devType: "infiniband", subType: "physical", entity: "device-infiniband"},
+	{devType: "infiniband", subType: "sriov", entity: "device-infiniband"},
+	{devType: "nic", subType: "bridged", entity: "device-nic-bridged"},
+	{devType: "nic", subType: "ipvlan", entity: "device-nic-ipvlan"},
+	{devType: "nic", subType: "macvlan", entity: "device-nic-macvlan"},
+	{devType: "nic", subType: "ovn", entity: "device-nic-ovn"},
+	{devType: "nic", subType: "p2p", entity: "device-nic-p2p"},
+	{devType: "nic", subType: "physical", entity: "device-nic-physical"},
+	{devType: "nic", subType: "routed", entity: "device-nic-routed"},
+	{devType: "nic", subType: "sriov", entity: "device-nic-sriov"},
+	{devType: "none"},
+	{devType: "pci", entity: "device-pci"},
+	{devType: "proxy", entity: "device-proxy"},
+	{devType: "tpm", entity: "device-tpm"},
+	{devType: "unix-block", entity: "device-unix-block"},
+	{devType: "unix-char", entity: "device-unix-char"},
+	{devType: "unix-hotplug", entity: "device-unix-hotplug"},
+	{devType: "usb", entity: "device-unix-usb"},
+}
+
+// Schemas returns the configuration schema of every supported device type, built from the given generated
+// configuration metadata and from the fields the device implementations can update live.
+func Schemas(metadata []byte) ([]api.MetadataDeviceType, error) {
+	var doc struct {
+		Configs map[string]map[string]struct {
+			Keys []map[string]struct {
+				Type        string `json:"type"`
+				DefaultDesc string `json:"defaultdesc"`
+				Required    string `json:"required"`
+				Condition   string `json:"condition"`
+				ShortDesc   string `json:"shortdesc"`
+			} `json:"keys"`
+		} `json:"configs"`
+	}
+
+	err := json.Unmarshal(metadata, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing configuration metadata: %w", err)
+	}
+
+	schemas := make([]api.MetadataDeviceType, 0, len(deviceSchemaTypes))
+	for _, t := range deviceSchemaTypes {
+		dev := newByTypeName(t.devType, t.subType)
+		if dev == nil {
+			return nil, fmt.Errorf("Unknown device type %q", t.devType)
+		}
+
+		liveUpdate := dev.UpdatableFields(dev)
+
+		schema := api.MetadataDeviceType{
+			Type:    t.devType,
+			SubType: t.subType,
+			Keys:    map[string]api.MetadataDeviceKey{},
+		}
+
+		for _, keys := range doc.Configs[t.entity]["device-conf"].Keys {
+			for name, key := range keys {
+				schema.Keys[name] = api.MetadataDeviceKey{
+					Type:        key.Type,
+					Default:     key.DefaultDesc,
+					Required:    key.Required,
+					Condition:   key.Condition,
+					Description: key.ShortDesc,
+					LiveUpdate:  shared.ValueInSlice(name, liveUpdate),
+				}
+			}
+		}
+
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, nil
+}
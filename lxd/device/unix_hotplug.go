@@ -4,6 +4,8 @@ package device
 
 import (
 	"fmt"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/jochenvg/go-udev"
@@ -25,9 +27,78 @@ func unixHotplugIsOurDevice(config deviceConfig.Device, unixHotplug *UnixHotplug
 		return false
 	}
 
+	// The properties have been validated already.
+	properties, _ := unixHotplugParseProperties(config["properties"])
+	for key, pattern := range properties {
+		value, ok := unixHotplug.Properties[key]
+		if !ok {
+			return false
+		}
+
+		match, _ := filepath.Match(pattern, value)
+		if !match {
+			return false
+		}
+	}
+
 	return true
 }
 
+// unixHotplugParseProperties parses a comma separated list of udev property expressions in the KEY=VALUE format.
+// The values can contain shell-style wildcards.
+func unixHotplugParseProperties(value string) (map[string]string, error) {
+	properties := map[string]string{}
+
+	if value == "" {
+		return properties, nil
+	}
+
+	for _, expression := range strings.Split(value, ",") {
+		key, pattern, found := strings.Cut(strings.TrimSpace(expression), "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("Invalid udev property expression %q (expected KEY=VALUE)", expression)
+		}
+
+		_, err := filepath.Match(pattern, "")
+		if err != nil {
+			return nil, fmt.Errorf("Invalid pattern in udev property expression %q: %w", expression, err)
+		}
+
+		_, found = properties[key]
+		if found {
+			return nil, fmt.Errorf("Duplicate udev property %q", key)
+		}
+
+		properties[key] = pattern
+	}
+
+	return properties, nil
+}
+
+// unixHotplugUSBDevice returns the USB device item to pass to a VM for the given udev device.
+// Virtual machines can only be given the USB device that the matched device belongs to.
+func unixHotplugUSBDevice(deviceName string, device *udev.Device) (*deviceConfig.USBDeviceItem, error) {
+	usbDevice := device.ParentWithSubsystemDevtype("usb", "usb_device")
+	if usbDevice == nil {
+		return nil, fmt.Errorf("Device %q isn't a USB device and can't be passed to a virtual machine", device.Devnode())
+	}
+
+	busNum, err := strconv.Atoi(usbDevice.PropertyValue("BUSNUM"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting bus number of device %q: %w", device.Devnode(), err)
+	}
+
+	devNum, err := strconv.Atoi(usbDevice.PropertyValue("DEVNUM"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting device number of device %q: %w", device.Devnode(), err)
+	}
+
+	return &deviceConfig.USBDeviceItem{
+		DeviceName:     fmt.Sprintf("%s-%03d-%03d", deviceName, busNum, devNum),
+		HostDevicePath: fmt.Sprintf("/dev/bus/usb/%03d/%03d", busNum, devNum),
+	}, nil
+}
+
 type unixHotplug struct {
 	deviceCommon
 }
@@ -40,7 +111,7 @@ func (d *unixHotplug) isRequired() bool {
 
 // validateConfig checks the supplied config for correctness.
 func (d *unixHotplug) validateConfig(instConf instance.ConfigReader) error {
-	if !instanceSupported(instConf.Type(), instancetype.Container) {
+	if !instanceSupported(instConf.Type(), instancetype.Container, instancetype.VM) {
 		return ErrUnsupportedDevType
 	}
 
@@ -69,10 +140,22 @@ func (d *unixHotplug) validateConfig(instConf instance.ConfigReader) error {
 		//  type: string
 		//  shortdesc: Product ID of the USB device
 		"productid": validate.Optional(validate.IsDeviceID),
-		"uid":       unixValidUserID,
-		"gid":       unixValidUserID,
-		"mode":      unixValidOctalFileMode,
-		"required":  validate.Optional(validate.IsBool),
+
+		// lxdmeta:generate(entities=device-unix-hotplug; group=device-conf; key=properties)
+		// Specify a comma-separated list of `KEY=VALUE` expressions, for example `ID_SERIAL_SHORT=A10K3B2C,SUBSYSTEM=tty`.
+		// The values can contain shell-style wildcards (`*`, `?` and `[...]`).
+		// The device matches if it has all the given udev properties.
+		// ---
+		//  type: string
+		//  shortdesc: udev properties of the Unix device
+		"properties": func(value string) error {
+			_, err := unixHotplugParseProperties(value)
+			return err
+		},
+		"uid":      unixValidUserID,
+		"gid":      unixValidUserID,
+		"mode":     unixValidOctalFileMode,
+		"required": validate.Optional(validate.IsBool),
 	}
 
 	err := d.config.Validate(rules)
@@ -80,8 +163,8 @@ func (d *unixHotplug) validateConfig(instConf instance.ConfigReader) error {
 		return err
 	}
 
-	if d.config["vendorid"] == "" && d.config["productid"] == "" {
-		return fmt.Errorf("Unix hotplug devices require a vendorid or a productid")
+	if d.config["vendorid"] == "" && d.config["productid"] == "" && d.config["properties"] == "" {
+		return fmt.Errorf("Unix hotplug devices require a vendorid, a productid or properties")
 	}
 
 	return nil
@@ -96,6 +179,10 @@ func (d *unixHotplug) Register() error {
 	deviceName := d.name
 	state := d.state
 
+	if d.inst.Type() == instancetype.VM {
+		return d.registerVM()
+	}
+
 	// Handler for when a UnixHotplug event occurs.
 	f := func(e UnixHotplugEvent) (*deviceConfig.RunConfig, error) {
 		runConf := deviceConfig.RunConfig{}
@@ -144,8 +231,107 @@ func (d *unixHotplug) Register() error {
 	return nil
 }
 
+// registerVM registers the handler passing the USB devices that match the device to a VM when they are plugged in.
+func (d *unixHotplug) registerVM() error {
+	// Extract variables needed to run the event hook so that the reference to this device
+	// struct is not needed to be kept in memory.
+	devConfig := d.config
+	deviceName := d.name
+
+	// Keep track of the USB device that each matched device node belongs to. This is needed to detach the USB
+	// device when the device node is removed, as the USB device cannot be looked up anymore at that point.
+	// Event handlers are run sequentially so no locking is needed.
+	attached := map[string]deviceConfig.USBDeviceItem{}
+
+	devices, err := d.loadUnixDevices()
+	if err != nil {
+		return err
+	}
+
+	for _, device := range devices {
+		usbDevice, err := unixHotplugUSBDevice(deviceName, device)
+		if err != nil {
+			continue
+		}
+
+		attached[device.Devnode()] = *usbDevice
+	}
+
+	// usbDeviceInUse returns whether another device node belongs to the same USB device.
+	usbDeviceInUse := func(path string, usbDevice deviceConfig.USBDeviceItem) bool {
+		for otherPath, otherUSBDevice := range attached {
+			if otherPath != path && otherUSBDevice.DeviceName == usbDevice.DeviceName {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// Handler for when a UnixHotplug event occurs.
+	f := func(e UnixHotplugEvent) (*deviceConfig.RunConfig, error) {
+		var usbDevice *deviceConfig.USBDeviceItem
+
+		if e.Action == "add" {
+			if !unixHotplugIsOurDevice(devConfig, &e) {
+				return nil, nil
+			}
+
+			u := udev.Udev{}
+			device := u.NewDeviceFromSyspath(filepath.Join("/sys", e.Properties["DEVPATH"]))
+			if device == nil {
+				return nil, fmt.Errorf("Failed loading device %q", e.Path)
+			}
+
+			var err error
+			usbDevice, err = unixHotplugUSBDevice(deviceName, device)
+			if err != nil {
+				return nil, err
+			}
+
+			inUse := usbDeviceInUse(e.Path, *usbDevice)
+			attached[e.Path] = *usbDevice
+
+			// The USB device has already been passed to the VM.
+			if inUse {
+				return nil, nil
+			}
+		} else if e.Action == "remove" {
+			item, ok := attached[e.Path]
+			if !ok {
+				return nil, nil
+			}
+
+			delete(attached, e.Path)
+
+			// Other device nodes of the USB device still match.
+			if usbDeviceInUse(e.Path, item) {
+				return nil, nil
+			}
+
+			usbDevice = &item
+		} else {
+			return nil, nil
+		}
+
+		runConf := deviceConfig.RunConfig{}
+		runConf.Uevents = append(runConf.Uevents, e.UeventParts)
+		runConf.USBDevice = append(runConf.USBDevice, *usbDevice)
+
+		return &runConf, nil
+	}
+
+	unixHotplugRegisterHandler(d.inst, d.name, f)
+
+	return nil
+}
+
 // Start is run when the device is added to the instance.
 func (d *unixHotplug) Start() (*deviceConfig.RunConfig, error) {
+	if d.inst.Type() == instancetype.VM {
+		return d.startVM()
+	}
+
 	runConf := deviceConfig.RunConfig{}
 	runConf.PostHooks = []func() error{d.Register}
 
@@ -177,6 +363,29 @@ func (d *unixHotplug) Start() (*deviceConfig.RunConfig, error) {
 	return &runConf, nil
 }
 
+// startVM passes the USB devices that the matching devices belong to to the VM.
+func (d *unixHotplug) startVM() (*deviceConfig.RunConfig, error) {
+	if shared.IsTrue(d.inst.ExpandedConfig()["migration.stateful"]) {
+		return nil, fmt.Errorf("Unix hotplug devices cannot be used with virtual machines when migration.stateful is enabled")
+	}
+
+	runConf := deviceConfig.RunConfig{}
+	runConf.PostHooks = []func() error{d.Register}
+
+	usbDevices, err := d.loadUSBDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.isRequired() && len(usbDevices) == 0 {
+		return nil, fmt.Errorf("Required Unix Hotplug device not found")
+	}
+
+	runConf.USBDevice = append(runConf.USBDevice, usbDevices...)
+
+	return &runConf, nil
+}
+
 // Stop is run when the device is removed from the instance.
 func (d *unixHotplug) Stop() (*deviceConfig.RunConfig, error) {
 	unixHotplugUnregisterHandler(d.inst, d.name)
@@ -185,6 +394,18 @@ func (d *unixHotplug) Stop() (*deviceConfig.RunConfig, error) {
 		PostHooks: []func() error{d.postStop},
 	}
 
+	if d.inst.Type() == instancetype.VM {
+		// Devices that have been unplugged already don't need to be detached.
+		usbDevices, err := d.loadUSBDevices()
+		if err != nil {
+			d.logger.Warn("Failed loading USB devices to detach", logger.Ctx{"err": err})
+		}
+
+		runConf.USBDevice = append(runConf.USBDevice, usbDevices...)
+
+		return &runConf, nil
+	}
+
 	err := unixDeviceRemove(d.inst.DevicesPath(), "unix", d.name, "", &runConf)
 	if err != nil {
 		return nil, err
@@ -206,34 +427,54 @@ func (d *unixHotplug) postStop() error {
 // loadUnixDevice scans the host machine for unix devices with matching product/vendor ids
 // and returns the first matching device with the subsystem type char or block.
 func (d *unixHotplug) loadUnixDevice() *udev.Device {
-	// Find device if exists
-	u := udev.Udev{}
-	e := u.NewEnumerate()
+	devices, err := d.loadUnixDevices()
+	if err != nil {
+		logger.Warn("Failed to load Unix devices", logger.Ctx{"err": err})
+		return nil
+	}
+
+	if len(devices) == 0 {
+		return nil
+	}
+
+	return devices[0]
+}
+
+// loadUnixDevices scans the host machine for unix devices with matching product/vendor ids and udev properties
+// and returns the matching devices with the subsystem type char or block.
+func (d *unixHotplug) loadUnixDevices() ([]*udev.Device, error) {
+	properties, err := unixHotplugParseProperties(d.config["properties"])
+	if err != nil {
+		return nil, err
+	}
 
 	if d.config["vendorid"] != "" {
-		err := e.AddMatchProperty("ID_VENDOR_ID", d.config["vendorid"])
-		if err != nil {
-			logger.Warn("Failed to add property to device", logger.Ctx{"property_name": "ID_VENDOR_ID", "property_value": d.config["vendorid"], "err": err})
-		}
+		properties["ID_VENDOR_ID"] = d.config["vendorid"]
 	}
 
 	if d.config["productid"] != "" {
-		err := e.AddMatchProperty("ID_MODEL_ID", d.config["productid"])
+		properties["ID_MODEL_ID"] = d.config["productid"]
+	}
+
+	// Find device if exists
+	u := udev.Udev{}
+	e := u.NewEnumerate()
+
+	for key, value := range properties {
+		err := e.AddMatchProperty(key, value)
 		if err != nil {
-			logger.Warn("Failed to add property to device", logger.Ctx{"property_name": "ID_MODEL_ID", "property_value": d.config["productid"], "err": err})
+			logger.Warn("Failed to add property to device", logger.Ctx{"property_name": key, "property_value": value, "err": err})
 		}
 	}
 
-	err := e.AddMatchIsInitialized()
+	err = e.AddMatchIsInitialized()
 	if err != nil {
 		logger.Warn("Failed to add initialised property to device", logger.Ctx{"err": err})
 	}
 
 	devices, _ := e.Devices()
-	var device *udev.Device
-	for i := range devices {
-		device = devices[i]
-
+	matches := make([]*udev.Device, 0, len(devices))
+	for _, device := range devices {
 		if device == nil {
 			continue
 		}
@@ -248,9 +489,41 @@ func (d *unixHotplug) loadUnixDevice() *udev.Device {
 		}
 
 		if !strings.HasPrefix(device.Subsystem(), "usb") {
-			return device
+			matches = append(matches, device)
 		}
 	}
 
-	return nil
+	return matches, nil
+}
+
+// loadUSBDevices returns the USB devices that the matching devices belong to, for passing them to a VM.
+func (d *unixHotplug) loadUSBDevices() ([]deviceConfig.USBDeviceItem, error) {
+	devices, err := d.loadUnixDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	usbDevices := []deviceConfig.USBDeviceItem{}
+	seen := map[string]bool{}
+
+	for _, device := range devices {
+		usbDevice, err := unixHotplugUSBDevice(d.name, device)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[usbDevice.DeviceName] {
+			continue
+		}
+
+		seen[usbDevice.DeviceName] = true
+		usbDevices = append(usbDevices, *usbDevice)
+	}
+
+	return usbDevices, nil
+}
+
+// CanHotPlug returns whether the device can be managed whilst the instance is running.
+func (d *unixHotplug) CanHotPlug() bool {
+	return true
 }
@@ -273,10 +273,8 @@ func deviceNetlinkListener() (chan []string, chan []string, chan device.USBEvent
 				vendor := ""
 				product := ""
 				if action == "add" {
-					vendor, product, ok = ueventParseVendorProduct(props, subsystem, devname)
-					if !ok {
-						continue
-					}
+					// Devices without vendor and product IDs can still be matched on their udev properties.
+					vendor, product, _ = ueventParseVendorProduct(props, subsystem, devname)
 				}
 
 				zeroPad := func(s string, l int) string {
@@ -284,11 +282,11 @@ func deviceNetlinkListener() (chan []string, chan []string, chan device.USBEvent
 				}
 
 				// zeropad
-				if len(vendor) < 4 {
+				if vendor != "" && len(vendor) < 4 {
 					vendor = zeroPad(vendor, 4)
 				}
 
-				if len(product) < 4 {
+				if product != "" && len(product) < 4 {
 					product = zeroPad(product, 4)
 				}
 
@@ -306,6 +304,7 @@ func deviceNetlinkListener() (chan []string, chan []string, chan device.USBEvent
 					devname,
 					ueventParts[:len(ueventParts)-1],
 					ueventLen,
+					props,
 				)
 				if err != nil {
 					logger.Error("Error reading unix device", logger.Ctx{"err": err, "path": props["PHYSDEVPATH"]})
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/google/uuid"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// devlxdCredentialsDefaultExpiry is the lifetime of credentials when security.devlxd.credentials.expiry isn't set.
+const devlxdCredentialsDefaultExpiry = "1H"
+
+// devlxdCredentialsClaims are the claims of the tokens issued to instances.
+type devlxdCredentialsClaims struct {
+	jwt.Claims
+
+	Project  string `json:"project"`
+	Instance string `json:"instance"`
+	UUID     string `json:"uuid"`
+	Location string `json:"location"`
+}
+
+// devlxdCredentialsRenewals holds, for each instance that requested credentials, when to push new ones to it.
+var devlxdCredentialsRenewals = map[int]time.Time{}
+var devlxdCredentialsRenewalsMu sync.Mutex
+
+var devlxdCredentialsGet = devLxdHandler{"/1.0/credentials", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+	if shared.IsFalse(c.ExpandedConfig()["security.devlxd"]) || shared.IsFalseOrEmpty(c.ExpandedConfig()["security.devlxd.credentials"]) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), c.Type() == instancetype.VM)
+	}
+
+	credentials, err := devlxdCredentialsIssue(d.State(), c)
+	if err != nil {
+		logger.Error("Failed issuing instance credentials", logger.Ctx{"project": c.Project().Name, "instance": c.Name(), "err": err})
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, "internal server error"), c.Type() == instancetype.VM)
+	}
+
+	return response.DevLxdResponse(http.StatusOK, credentials, "json", c.Type() == instancetype.VM)
+}}
+
+// devlxdCredentialsIssue returns new credentials for the given instance. The token is signed with the key of the
+// server certificate (the cluster certificate when clustered) and asserts the identity of the instance, including its
+// UUID so that it can't be confused with another instance later created with the same name.
+func devlxdCredentialsIssue(s *state.State, inst instance.Instance) (*api.DevLXDCredentials, error) {
+	expiry := inst.ExpandedConfig()["security.devlxd.credentials.expiry"]
+	if expiry == "" {
+		expiry = devlxdCredentialsDefaultExpiry
+	}
+
+	now := time.Now()
+	expiresAt, err := shared.GetExpiry(now, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := s.ServerCert()
+	algorithm, err := devlxdCredentialsAlgorithm(cert.KeyPair().PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: algorithm, Key: cert.KeyPair().PrivateKey}, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", cert.Fingerprint()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating token signer: %w", err)
+	}
+
+	claims := devlxdCredentialsClaims{
+		Claims: jwt.Claims{
+			Issuer:    "lxd:" + cert.Fingerprint(),
+			Subject:   entity.InstanceURL(inst.Project().Name, inst.Name()).String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Expiry:    jwt.NewNumericDate(expiresAt),
+			ID:        uuid.New().String(),
+		},
+		Project:  inst.Project().Name,
+		Instance: inst.Name(),
+		UUID:     inst.LocalConfig()["volatile.uuid"],
+		Location: inst.Location(),
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("Failed signing token: %w", err)
+	}
+
+	// Push new credentials to the instance once half of their lifetime has passed.
+	renewAt := now.Add(expiresAt.Sub(now) / 2)
+
+	devlxdCredentialsRenewalsMu.Lock()
+	devlxdCredentialsRenewals[inst.ID()] = renewAt
+	devlxdCredentialsRenewalsMu.Unlock()
+
+	return &api.DevLXDCredentials{
+		Token:       token,
+		ExpiresAt:   expiresAt,
+		RenewAt:     renewAt,
+		Certificate: string(cert.PublicKey()),
+	}, nil
+}
+
+// devlxdCredentialsAlgorithm returns the signature algorithm to use with the given private key.
+func devlxdCredentialsAlgorithm(key any) (jose.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+	case *rsa.PrivateKey:
+		return jose.RS256, nil
+	}
+
+	return "", fmt.Errorf("Unsupported server key type %T", key)
+}
+
+// devlxdCredentialsForget stops pushing credentials to the instance with the given ID.
+func devlxdCredentialsForget(id int) {
+	devlxdCredentialsRenewalsMu.Lock()
+	delete(devlxdCredentialsRenewals, id)
+	devlxdCredentialsRenewalsMu.Unlock()
+}
+
+func rotateDevlxdCredentialsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+		now := time.Now()
+
+		devlxdCredentialsRenewalsMu.Lock()
+		ids := make([]int, 0, len(devlxdCredentialsRenewals))
+		for id, renewAt := range devlxdCredentialsRenewals {
+			if !renewAt.After(now) {
+				ids = append(ids, id)
+			}
+		}
+
+		devlxdCredentialsRenewalsMu.Unlock()
+
+		for _, id := range ids {
+			inst, err := instance.LoadByID(s, id)
+			if err != nil {
+				if api.StatusErrorCheck(err, http.StatusNotFound) {
+					devlxdCredentialsForget(id)
+				} else {
+					logger.Warn("Failed loading instance to rotate its credentials", logger.Ctx{"id": id, "err": err})
+				}
+
+				continue
+			}
+
+			// Only keep rotating the credentials of running instances still allowed to use them.
+			if !inst.IsRunning() || shared.IsFalse(inst.ExpandedConfig()["security.devlxd"]) || shared.IsFalseOrEmpty(inst.ExpandedConfig()["security.devlxd.credentials"]) {
+				devlxdCredentialsForget(id)
+				continue
+			}
+
+			credentials, err := devlxdCredentialsIssue(s, inst)
+			if err != nil {
+				logger.Warn("Failed issuing instance credentials", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+				continue
+			}
+
+			err = inst.DevlxdEventSend("credentials", map[string]any{
+				"token":       credentials.Token,
+				"expires_at":  credentials.ExpiresAt,
+				"renew_at":    credentials.RenewAt,
+				"certificate": credentials.Certificate,
+			})
+			if err != nil {
+				logger.Warn("Failed sending instance credentials", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			}
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/canonical/lxd/lxd/device"
 	"github.com/canonical/lxd/lxd/response"
 )
 
@@ -14,6 +15,12 @@ var metadataConfigurationCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: metadataConfigurationGet, AllowUntrusted: true},
 }
 
+var metadataDevicesCmd = APIEndpoint{
+	Path: "metadata/devices",
+
+	Get: APIEndpointAction{Handler: metadataDevicesGet, AllowUntrusted: true},
+}
+
 //go:embed metadata/configuration.json
 var generatedDoc embed.FS
 
@@ -66,3 +73,54 @@ func metadataConfigurationGet(d *Daemon, r *http.Request) response.Response {
 
 	return response.SyncResponse(true, data)
 }
+
+// swagger:operation GET /1.0/metadata/devices metadata_devices_get
+//
+//	Get the device schemas
+//
+//	Returns the configuration schema of every supported device type.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of device types
+//	          items:
+//	            $ref: "#/definitions/MetadataDeviceType"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func metadataDevicesGet(d *Daemon, r *http.Request) response.Response {
+	file, err := generatedDoc.ReadFile("metadata/configuration.json")
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	schemas, err := device.Schemas(file)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, schemas)
+}
@@ -66,6 +66,12 @@ type Config struct {
 	// HTTP server handling requests for the LXD storage buckets API.
 	StorageBucketsServer *http.Server
 
+	// HTTP server handling requests for the LXD events API.
+	EventsServer *http.Server
+
+	// HTTP server handling the LXD migration websockets.
+	MigrationServer *http.Server
+
 	// HTTP server handling requests from VMs via the vsock.
 	VsockServer *http.Server
 
@@ -158,12 +164,13 @@ func Up(config *Config) (*Endpoints, error) {
 // the relevant HTTP handlers to them. When LXD shuts down they close all
 // sockets.
 type Endpoints struct {
-	tomb      *tomb.Tomb            // Controls the HTTP servers shutdown.
-	mu        sync.RWMutex          // Serialize access to internal state.
-	listeners map[kind]net.Listener // Activer listeners by endpoint type.
-	servers   map[kind]*http.Server // HTTP servers by endpoint type.
-	cert      *shared.CertInfo      // Keypair and CA to use for TLS.
-	inherited map[kind]bool         // Store whether the listener came through socket activation
+	tomb      *tomb.Tomb                // Controls the HTTP servers shutdown.
+	mu        sync.RWMutex              // Serialize access to internal state.
+	listeners map[kind]net.Listener     // Activer listeners by endpoint type.
+	servers   map[kind]*http.Server     // HTTP servers by endpoint type.
+	cert      *shared.CertInfo          // Keypair and CA to use for TLS.
+	certs     map[kind]*shared.CertInfo // Dedicated keypairs overriding cert for specific listeners.
+	inherited map[kind]bool             // Store whether the listener came through socket activation
 
 	systemdListenFDsStart int // First socket activation FD, for tests.
 }
@@ -181,6 +188,8 @@ func (e *Endpoints) up(config *Config) error {
 		pprof:          pprofCreateServer(),
 		metrics:        config.MetricsServer,
 		storageBuckets: config.StorageBucketsServer,
+		events:         config.EventsServer,
+		migration:      config.MigrationServer,
 		vmvsock:        config.VsockServer,
 	}
 
@@ -316,7 +325,7 @@ func (e *Endpoints) up(config *Config) error {
 // UpMetrics brings up metrics listener on specified address.
 func (e *Endpoints) UpMetrics(listenAddress string) error {
 	var err error
-	e.listeners[metrics], err = metricsCreateListener(listenAddress, e.cert)
+	e.listeners[metrics], err = metricsCreateListener(listenAddress, e.listenerCert(metrics, e.cert))
 	if err != nil {
 		return fmt.Errorf("Failed starting metrics listener: %w", err)
 	}
@@ -329,7 +338,7 @@ func (e *Endpoints) UpMetrics(listenAddress string) error {
 // UpStorageBuckets brings up storage buvkets listener on specified address.
 func (e *Endpoints) UpStorageBuckets(listenAddress string) error {
 	var err error
-	e.listeners[storageBuckets], err = storageBucketsCreateListener(listenAddress, e.cert)
+	e.listeners[storageBuckets], err = storageBucketsCreateListener(listenAddress, e.listenerCert(storageBuckets, e.cert))
 	if err != nil {
 		return fmt.Errorf("Failed starting storage buckets listener: %w", err)
 	}
@@ -339,6 +348,32 @@ func (e *Endpoints) UpStorageBuckets(listenAddress string) error {
 	return nil
 }
 
+// UpEvents brings up events listener on specified address.
+func (e *Endpoints) UpEvents(listenAddress string) error {
+	var err error
+	e.listeners[events], err = eventsCreateListener(listenAddress, e.listenerCert(events, e.cert))
+	if err != nil {
+		return fmt.Errorf("Failed starting events listener: %w", err)
+	}
+
+	e.serve(events)
+
+	return nil
+}
+
+// UpMigration brings up migration listener on specified address.
+func (e *Endpoints) UpMigration(listenAddress string) error {
+	var err error
+	e.listeners[migration], err = migrationCreateListener(listenAddress, e.listenerCert(migration, e.cert))
+	if err != nil {
+		return fmt.Errorf("Failed starting migration listener: %w", err)
+	}
+
+	e.serve(migration)
+
+	return nil
+}
+
 // Down brings down all endpoints and stops serving HTTP requests.
 func (e *Endpoints) Down() error {
 	e.mu.Lock()
@@ -391,6 +426,20 @@ func (e *Endpoints) Down() error {
 		}
 	}
 
+	if e.listeners[events] != nil {
+		err := e.closeListener(events)
+		if err != nil {
+			return err
+		}
+	}
+
+	if e.listeners[migration] != nil {
+		err := e.closeListener(migration)
+		if err != nil {
+			return err
+		}
+	}
+
 	if e.listeners[vmvsock] != nil {
 		err := e.closeListener(vmvsock)
 		if err != nil {
@@ -434,6 +483,38 @@ func (e *Endpoints) serve(kind kind) {
 	})
 }
 
+// Return the dedicated certificate of the given listener, or the given default one if it has none.
+func (e *Endpoints) listenerCert(listenerKind kind, cert *shared.CertInfo) *shared.CertInfo {
+	listenerCert := e.certs[listenerKind]
+	if listenerCert != nil {
+		return listenerCert
+	}
+
+	return cert
+}
+
+// Set or clear (if cert is nil) the dedicated certificate of the given listener and apply it to the listener if
+// it is active.
+func (e *Endpoints) listenerUpdateCert(listenerKind kind, cert *shared.CertInfo) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.certs == nil {
+		e.certs = map[kind]*shared.CertInfo{}
+	}
+
+	if cert != nil {
+		e.certs[listenerKind] = cert
+	} else {
+		delete(e.certs, listenerKind)
+	}
+
+	listener, found := e.listeners[listenerKind]
+	if found {
+		listener.(*listeners.FancyTLSListener).Config(e.listenerCert(listenerKind, e.cert))
+	}
+}
+
 // Stop the HTTP server of the endpoint associated with the given code. The
 // associated socket will be shutdown too.
 func (e *Endpoints) closeListener(kind kind) error {
@@ -489,6 +570,8 @@ const (
 	metrics
 	vmvsock
 	storageBuckets
+	events
+	migration
 )
 
 // Human-readable descriptions of the various kinds of endpoints.
@@ -501,4 +584,6 @@ var descriptions = map[kind]string{
 	metrics:        "metrics socket",
 	vmvsock:        "VM socket",
 	storageBuckets: "Storage buckets socket",
+	events:         "events socket",
+	migration:      "migration socket",
 }
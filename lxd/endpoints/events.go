@@ -0,0 +1,121 @@
+package endpoints
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/endpoints/listeners"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+func eventsCreateListener(address string, cert *shared.CertInfo) (net.Listener, error) {
+	// Listening on `tcp` network with address 0.0.0.0 will end up with listening
+	// on both IPv4 and IPv6 interfaces. Pass `tcp4` to make it
+	// work only on 0.0.0.0. https://go-review.googlesource.com/c/go/+/45771/
+	listenAddress := util.CanonicalNetworkAddress(address, shared.HTTPSEventsDefaultPort)
+	protocol := "tcp"
+
+	if strings.HasPrefix(listenAddress, "0.0.0.0") {
+		protocol = "tcp4"
+	}
+
+	listener, err := net.Listen(protocol, listenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("Bind network address: %w", err)
+	}
+
+	return listeners.NewFancyTLSListener(listener, cert), nil
+}
+
+// EventsAddress returns the network address of the events endpoint, or an
+// empty string if there's no events endpoint.
+func (e *Endpoints) EventsAddress() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	listener := e.listeners[events]
+	if listener == nil {
+		return ""
+	}
+
+	return listener.Addr().String()
+}
+
+// EventsUpdateAddress updates the address for the events endpoint, shutting it down and restarting it.
+func (e *Endpoints) EventsUpdateAddress(address string, cert *shared.CertInfo) error {
+	if address != "" {
+		address = util.CanonicalNetworkAddress(address, shared.HTTPSEventsDefaultPort)
+	}
+
+	oldAddress := e.EventsAddress()
+	if address == oldAddress {
+		return nil
+	}
+
+	logger.Infof("Update events address")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Use the dedicated certificate of the listener if it has one.
+	cert = e.listenerCert(events, cert)
+
+	// Close the previous socket
+	_ = e.closeListener(events)
+
+	// If turning off listening, we're done
+	if address == "" {
+		return nil
+	}
+
+	// Attempt to setup the new listening socket
+	getListener := func(address string) (*net.Listener, error) {
+		var err error
+		var listener net.Listener
+
+		for i := 0; i < 10; i++ { // Ten retries over a second seems reasonable.
+			listener, err = eventsCreateListener(address, cert)
+			if err == nil {
+				break
+			}
+
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Cannot listen on http socket: %w", err)
+		}
+
+		return &listener, nil
+	}
+
+	// If setting a new address, setup the listener
+	if address != "" {
+		listener, err := getListener(address)
+		if err != nil {
+			// Attempt to revert to the previous address
+			listener, err1 := getListener(oldAddress)
+			if err1 == nil {
+				e.listeners[events] = *listener
+				e.serve(events)
+			}
+
+			return err
+		}
+
+		e.listeners[events] = *listener
+		e.serve(events)
+	}
+
+	return nil
+}
+
+// EventsUpdateCert sets the dedicated TLS keypair of the events endpoint. Passing nil makes the endpoint use the
+// network certificate again.
+func (e *Endpoints) EventsUpdateCert(cert *shared.CertInfo) {
+	e.listenerUpdateCert(events, cert)
+}
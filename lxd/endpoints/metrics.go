@@ -61,6 +61,9 @@ func (e *Endpoints) MetricsUpdateAddress(address string, cert *shared.CertInfo)
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	// Use the dedicated certificate of the listener if it has one.
+	cert = e.listenerCert(metrics, cert)
+
 	// Close the previous socket
 	_ = e.closeListener(metrics)
 
@@ -110,3 +113,9 @@ func (e *Endpoints) MetricsUpdateAddress(address string, cert *shared.CertInfo)
 
 	return nil
 }
+
+// MetricsUpdateCert sets the dedicated TLS keypair of the metrics endpoint. Passing nil makes the endpoint use the
+// network certificate again.
+func (e *Endpoints) MetricsUpdateCert(cert *shared.CertInfo) {
+	e.listenerUpdateCert(metrics, cert)
+}
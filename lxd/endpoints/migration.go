@@ -0,0 +1,129 @@
+package endpoints
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/endpoints/listeners"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+func migrationCreateListener(address string, cert *shared.CertInfo) (net.Listener, error) {
+	// Listening on `tcp` network with address 0.0.0.0 will end up with listening
+	// on both IPv4 and IPv6 interfaces. Pass `tcp4` to make it
+	// work only on 0.0.0.0. https://go-review.googlesource.com/c/go/+/45771/
+	listenAddress := util.CanonicalNetworkAddress(address, shared.HTTPSMigrationDefaultPort)
+	protocol := "tcp"
+
+	if strings.HasPrefix(listenAddress, "0.0.0.0") {
+		protocol = "tcp4"
+	}
+
+	listener, err := net.Listen(protocol, listenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("Bind network address: %w", err)
+	}
+
+	return listeners.NewFancyTLSListener(listener, cert), nil
+}
+
+// MigrationAddress returns the network address of the migration endpoint, or an
+// empty string if there's no migration endpoint.
+func (e *Endpoints) MigrationAddress() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	listener := e.listeners[migration]
+	if listener == nil {
+		return ""
+	}
+
+	return listener.Addr().String()
+}
+
+// MigrationPublicKey returns the public key of the TLS certificate used by the migration endpoint.
+func (e *Endpoints) MigrationPublicKey() []byte {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.listenerCert(migration, e.cert).PublicKey()
+}
+
+// MigrationUpdateAddress updates the address for the migration endpoint, shutting it down and restarting it.
+func (e *Endpoints) MigrationUpdateAddress(address string, cert *shared.CertInfo) error {
+	if address != "" {
+		address = util.CanonicalNetworkAddress(address, shared.HTTPSMigrationDefaultPort)
+	}
+
+	oldAddress := e.MigrationAddress()
+	if address == oldAddress {
+		return nil
+	}
+
+	logger.Infof("Update migration address")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Use the dedicated certificate of the listener if it has one.
+	cert = e.listenerCert(migration, cert)
+
+	// Close the previous socket
+	_ = e.closeListener(migration)
+
+	// If turning off listening, we're done
+	if address == "" {
+		return nil
+	}
+
+	// Attempt to setup the new listening socket
+	getListener := func(address string) (*net.Listener, error) {
+		var err error
+		var listener net.Listener
+
+		for i := 0; i < 10; i++ { // Ten retries over a second seems reasonable.
+			listener, err = migrationCreateListener(address, cert)
+			if err == nil {
+				break
+			}
+
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Cannot listen on http socket: %w", err)
+		}
+
+		return &listener, nil
+	}
+
+	// If setting a new address, setup the listener
+	if address != "" {
+		listener, err := getListener(address)
+		if err != nil {
+			// Attempt to revert to the previous address
+			listener, err1 := getListener(oldAddress)
+			if err1 == nil {
+				e.listeners[migration] = *listener
+				e.serve(migration)
+			}
+
+			return err
+		}
+
+		e.listeners[migration] = *listener
+		e.serve(migration)
+	}
+
+	return nil
+}
+
+// MigrationUpdateCert sets the dedicated TLS keypair of the migration endpoint. Passing nil makes the endpoint use the
+// network certificate again.
+func (e *Endpoints) MigrationUpdateCert(cert *shared.CertInfo) {
+	e.listenerUpdateCert(migration, cert)
+}
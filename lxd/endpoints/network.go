@@ -133,12 +133,18 @@ func (e *Endpoints) NetworkUpdateAddress(address string) error {
 //
 // If the network endpoint is active, in-flight requests will continue using
 // the old certificate, and only new requests will use the new one.
+//
+// Listeners which were given a dedicated certificate keep using it.
 func (e *Endpoints) NetworkUpdateCert(cert *shared.CertInfo) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.cert = cert
 
-	for _, listenerKey := range []kind{network, cluster, vmvsock, storageBuckets, metrics} {
+	for _, listenerKey := range []kind{network, cluster, vmvsock, storageBuckets, metrics, events, migration} {
+		if e.certs[listenerKey] != nil {
+			continue
+		}
+
 		listener, found := e.listeners[listenerKey]
 		if found {
 			listener.(*listeners.FancyTLSListener).Config(cert)
@@ -62,6 +62,9 @@ func (e *Endpoints) StorageBucketsUpdateAddress(address string, cert *shared.Cer
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	// Use the dedicated certificate of the listener if it has one.
+	cert = e.listenerCert(storageBuckets, cert)
+
 	// Close the previous socket
 	_ = e.closeListener(storageBuckets)
 
@@ -111,3 +114,9 @@ func (e *Endpoints) StorageBucketsUpdateAddress(address string, cert *shared.Cer
 
 	return nil
 }
+
+// StorageBucketsUpdateCert sets the dedicated TLS keypair of the storage buckets endpoint. Passing nil makes the endpoint use the
+// network certificate again.
+func (e *Endpoints) StorageBucketsUpdateCert(cert *shared.CertInfo) {
+	e.listenerUpdateCert(storageBuckets, cert)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/db"
@@ -13,6 +15,7 @@ import (
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
@@ -20,7 +23,7 @@ import (
 	"github.com/canonical/lxd/shared/ws"
 )
 
-var eventTypes = []string{api.EventTypeLogging, api.EventTypeOperation, api.EventTypeLifecycle, api.EventTypeOVN}
+var eventTypes = []string{api.EventTypeLogging, api.EventTypeOperation, api.EventTypeLifecycle, api.EventTypeOVN, api.EventTypeInstanceLog}
 var privilegedEventTypes = []string{api.EventTypeLogging}
 
 var eventsCmd = APIEndpoint{
@@ -77,6 +80,22 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 
 	canViewPrivilegedEvents := s.Authorizer.CheckPermission(r.Context(), r, entity.ServerURL(), auth.EntitlementCanViewPrivilegedEvents) == nil
 
+	// Events that aren't specific to a project (server configuration, cluster members, identities...) are only
+	// delivered to clients that can view the whole server. This keeps project restricted clients isolated to the
+	// events of their projects.
+	canViewServerEvents := s.Authorizer.CheckPermission(r.Context(), r, entity.ServerURL(), auth.EntitlementViewer) == nil
+
+	var replaySince time.Time
+	sinceStr := request.QueryParam(r, "since")
+	if sinceStr != "" {
+		var err error
+
+		replaySince, err = time.Parse(time.RFC3339Nano, sinceStr)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid since timestamp %q", sinceStr)
+		}
+	}
+
 	types := strings.Split(r.FormValue("type"), ",")
 	if len(types) == 1 && types[0] == "" {
 		types = []string{}
@@ -156,7 +175,7 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 	defer func() { _ = conn.Close() }() // Ensure listener below ends when this function ends.
 
 	listenerConnection := events.NewWebsocketListenerConnection(conn)
-	listener, err := s.Events.AddListener(projectName, allProjects, projectPermissionFunc, listenerConnection, types, excludeSources, recvFunc, excludeLocations)
+	listener, err := s.Events.AddListener(projectName, allProjects, projectPermissionFunc, canViewServerEvents, listenerConnection, types, excludeSources, recvFunc, excludeLocations, replaySince)
 	if err != nil {
 		l.Warn("Failed to add event listener", logger.Ctx{"err": err})
 		return nil
@@ -184,13 +203,18 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 //	    example: default
 //	  - in: query
 //	    name: type
-//	    description: Event type(s), comma separated (valid types are logging, operation or lifecycle)
+//	    description: Event type(s), comma separated (valid types are logging, operation, lifecycle, ovn or instance-log)
 //	    type: string
 //	    example: logging,lifecycle
 //	  - in: query
 //	    name: all-projects
 //	    description: Retrieve instances from all projects
 //	    type: boolean
+//	  - in: query
+//	    name: since
+//	    description: Replay the kept events of the project(s) that are more recent than this timestamp (RFC3339)
+//	    type: string
+//	    example: 2021-03-23T17:38:37.753398689-04:00
 //	responses:
 //	  "200":
 //	    description: Websocket message (JSON)
@@ -203,3 +227,62 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 func eventsGet(d *Daemon, r *http.Request) response.Response {
 	return &eventsServe{req: r, s: d.State()}
 }
+
+// eventsHistorySizesRefresh loads the number of events to keep for replay in each project into the events server.
+func eventsHistorySizesRefresh(ctx context.Context, s *state.State) error {
+	sizes := map[string]int{}
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		projectNames, err := cluster.GetProjectIDsToNames(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		key := "events.history_size"
+		projectsConfig, err := cluster.GetConfig(ctx, tx.Tx(), "project", cluster.ConfigFilter{Key: &key})
+		if err != nil {
+			return err
+		}
+
+		for projectID, config := range projectsConfig {
+			projectName, ok := projectNames[int64(projectID)]
+			if !ok || config[key] == "" {
+				continue
+			}
+
+			size, err := strconv.Atoi(config[key])
+			if err != nil {
+				return fmt.Errorf("Invalid %q value for project %q: %w", key, projectName, err)
+			}
+
+			sizes[projectName] = size
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Events.SetHistorySizes(sizes)
+
+	return nil
+}
+
+// eventsHistorySizesRefreshOrWarn calls eventsHistorySizesRefresh and logs a warning on failure.
+func eventsHistorySizesRefreshOrWarn(ctx context.Context, s *state.State) {
+	err := eventsHistorySizesRefresh(ctx, s)
+	if err != nil {
+		logger.Warn("Failed refreshing event history sizes", logger.Ctx{"err": err})
+	}
+}
+
+// eventsHistorySizesRefreshTask periodically refreshes the event history sizes so that project configuration
+// changes made through other cluster members are picked up.
+func eventsHistorySizesRefreshTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		eventsHistorySizesRefreshOrWarn(ctx, d.State())
+	}
+
+	return f, task.Every(time.Minute)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/fault"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/cancel"
@@ -41,6 +43,10 @@ type Server struct {
 	listeners map[string]*Listener
 	notify    NotifyFunc
 	location  string
+
+	// Recent events of each project kept for replay and the number of events to keep per project.
+	histories    map[string]*history
+	historySizes map[string]int
 }
 
 // NewServer returns a new event server.
@@ -50,8 +56,10 @@ func NewServer(debug bool, verbose bool, notify NotifyFunc) *Server {
 			debug:   debug,
 			verbose: verbose,
 		},
-		listeners: map[string]*Listener{},
-		notify:    notify,
+		listeners:    map[string]*Listener{},
+		notify:       notify,
+		histories:    map[string]*history{},
+		historySizes: map[string]int{},
 	}
 
 	return server
@@ -66,8 +74,34 @@ func (s *Server) SetLocalLocation(location string) {
 	s.location = location
 }
 
+// SetHistorySizes sets the number of recent events to keep for replay in each project.
+// No events are kept for projects missing from sizes.
+func (s *Server) SetHistorySizes(sizes map[string]int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.historySizes = map[string]int{}
+	for projectName, size := range sizes {
+		if size > 0 {
+			s.historySizes[projectName] = size
+		}
+	}
+
+	for projectName, h := range s.histories {
+		size := s.historySizes[projectName]
+		if size == 0 {
+			delete(s.histories, projectName)
+		} else if size != len(h.events) {
+			s.histories[projectName] = h.resize(size)
+		}
+	}
+}
+
 // AddListener creates and returns a new event listener.
-func (s *Server) AddListener(projectName string, allProjects bool, projectPermissionFunc auth.PermissionChecker, connection EventListenerConnection, messageTypes []string, excludeSources []EventSource, recvFunc EventHandler, excludeLocations []string) (*Listener, error) {
+// Events that aren't specific to a project are only delivered if serverEvents is true.
+// If replaySince isn't zero, the kept events of the listened projects that are more recent than replaySince are
+// sent to the listener when it is added, oldest first.
+func (s *Server) AddListener(projectName string, allProjects bool, projectPermissionFunc auth.PermissionChecker, serverEvents bool, connection EventListenerConnection, messageTypes []string, excludeSources []EventSource, recvFunc EventHandler, excludeLocations []string, replaySince time.Time) (*Listener, error) {
 	if allProjects && projectName != "" {
 		return nil, fmt.Errorf("Cannot specify project name when listening for events on all projects")
 	}
@@ -90,19 +124,52 @@ func (s *Server) AddListener(projectName string, allProjects bool, projectPermis
 		allProjects:           allProjects,
 		projectName:           projectName,
 		projectPermissionFunc: projectPermissionFunc,
+		serverEvents:          serverEvents,
 		excludeSources:        excludeSources,
 		excludeLocations:      excludeLocations,
 	}
 
 	s.lock.Lock()
-	defer s.lock.Unlock()
 
 	if s.listeners[listener.id] != nil {
+		s.lock.Unlock()
 		return nil, fmt.Errorf("A listener with ID %q already exists", listener.id)
 	}
 
+	// Collect the events to replay while holding the lock so that no event gets lost between the replay and the
+	// registration of the listener.
+	var replay []api.Event
+	if !replaySince.IsZero() {
+		for _, h := range s.histories {
+			for _, event := range h.since(replaySince) {
+				if listener.wants(event) {
+					replay = append(replay, event)
+				}
+			}
+		}
+
+		sort.SliceStable(replay, func(i, j int) bool {
+			return replay[i].Timestamp.Before(replay[j].Timestamp)
+		})
+	}
+
 	s.listeners[listener.id] = listener
 
+	s.lock.Unlock()
+
+	for _, event := range replay {
+		err := listener.WriteJSON(event)
+		if err != nil {
+			s.lock.Lock()
+			delete(s.listeners, listener.id)
+			s.lock.Unlock()
+
+			listener.Close()
+
+			return nil, fmt.Errorf("Failed replaying events: %w", err)
+		}
+	}
+
 	go listener.start()
 
 	return listener, nil
@@ -177,27 +244,28 @@ func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
 
 	// If a notifcation hook is present, then call it for locally produced events.
 	// This can be used to send local events to another target (such as an event-hub member).
-	if s.notify != nil && eventSource == EventSourceLocal {
+	if s.notify != nil && eventSource == EventSourceLocal && !fault.EventHubPaused() {
 		s.notify(event)
 	}
 
-	listeners := s.listeners
-	for _, listener := range listeners {
-		// If the event is project specific, check if the listener is requesting events from that project.
-		if event.Project != "" && !listener.allProjects && event.Project != listener.projectName {
-			continue
+	// Keep the event for replay if requested for its project.
+	if event.Project != "" && s.historySizes[event.Project] > 0 {
+		h, ok := s.histories[event.Project]
+		if !ok {
+			h = newHistory(s.historySizes[event.Project])
+			s.histories[event.Project] = h
 		}
 
-		// If the event is project specific, ensure we have permission to view it.
-		if event.Project != "" && !listener.projectPermissionFunc(entity.ProjectURL(event.Project)) {
-			continue
-		}
+		h.add(event)
+	}
 
-		if sourceInSlice(eventSource, listener.excludeSources) {
+	listeners := s.listeners
+	for _, listener := range listeners {
+		if !listener.wants(event) {
 			continue
 		}
 
-		if !shared.ValueInSlice(event.Type, listener.messageTypes) {
+		if sourceInSlice(eventSource, listener.excludeSources) {
 			continue
 		}
 
@@ -245,6 +313,27 @@ type Listener struct {
 	allProjects           bool
 	projectName           string
 	projectPermissionFunc auth.PermissionChecker
+	serverEvents          bool
 	excludeSources        []EventSource
 	excludeLocations      []string
 }
+
+// wants returns whether the listener is interested in and allowed to receive the event.
+func (l *Listener) wants(event api.Event) bool {
+	// Events that aren't project specific are only delivered to listeners allowed to see server wide events.
+	if event.Project == "" && !l.serverEvents {
+		return false
+	}
+
+	// If the event is project specific, check if the listener is requesting events from that project.
+	if event.Project != "" && !l.allProjects && event.Project != l.projectName {
+		return false
+	}
+
+	// If the event is project specific, ensure we have permission to view it.
+	if event.Project != "" && !l.projectPermissionFunc(entity.ProjectURL(event.Project)) {
+		return false
+	}
+
+	return shared.ValueInSlice(event.Type, l.messageTypes)
+}
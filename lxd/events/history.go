@@ -0,0 +1,64 @@
+package events
+
+import (
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// history keeps the most recent events of a project in a circular buffer so that they can be replayed to new
+// listeners.
+type history struct {
+	events []api.Event
+	next   int
+	full   bool
+}
+
+// newHistory returns an empty history able to hold size events.
+func newHistory(size int) *history {
+	return &history{events: make([]api.Event, size)}
+}
+
+// add records an event, overwriting the oldest one if the history is full.
+func (h *history) add(event api.Event) {
+	h.events[h.next] = event
+	h.next = (h.next + 1) % len(h.events)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// since returns the recorded events more recent than the given time, oldest first.
+func (h *history) since(since time.Time) []api.Event {
+	var events []api.Event
+	if h.full {
+		events = append(events, h.events[h.next:]...)
+	}
+
+	events = append(events, h.events[:h.next]...)
+
+	result := make([]api.Event, 0, len(events))
+	for _, event := range events {
+		if event.Timestamp.After(since) {
+			result = append(result, event)
+		}
+	}
+
+	return result
+}
+
+// resize returns a history able to hold size events which keeps the most recent events of h.
+func (h *history) resize(size int) *history {
+	resized := newHistory(size)
+
+	events := h.since(time.Time{})
+	if len(events) > size {
+		events = events[len(events)-size:]
+	}
+
+	for _, event := range events {
+		resized.add(event)
+	}
+
+	return resized
+}
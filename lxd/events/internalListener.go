@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/canonical/lxd/lxd/storage/memorypipe"
 	"github.com/canonical/lxd/shared/api"
@@ -14,6 +15,7 @@ type InternalListener struct {
 	handlers       map[string]EventHandler
 	listener       *Listener
 	server         *Server
+	excludeSources []EventSource
 	ctx            context.Context
 	listenerCtx    context.Context
 	listenerCancel context.CancelFunc
@@ -21,7 +23,18 @@ type InternalListener struct {
 }
 
 // NewInternalListener returns an InternalListener.
+// The events received from other cluster members through outbound event listener streams aren't delivered to it.
 func NewInternalListener(ctx context.Context, server *Server) *InternalListener {
+	return &InternalListener{
+		ctx:            ctx,
+		handlers:       map[string]EventHandler{},
+		server:         server,
+		excludeSources: []EventSource{EventSourcePull},
+	}
+}
+
+// NewClusterInternalListener returns an InternalListener that receives the events of all cluster members.
+func NewClusterInternalListener(ctx context.Context, server *Server) *InternalListener {
 	return &InternalListener{
 		ctx:      ctx,
 		handlers: map[string]EventHandler{},
@@ -38,7 +51,7 @@ func (l *InternalListener) startListener() {
 	aEnd, bEnd := memorypipe.NewPipePair(l.listenerCtx)
 	listenerConnection := NewSimpleListenerConnection(aEnd)
 
-	l.listener, err = l.server.AddListener("", true, nil, listenerConnection, []string{"lifecycle", "logging", "ovn"}, []EventSource{EventSourcePull}, nil, nil)
+	l.listener, err = l.server.AddListener("", true, nil, true, listenerConnection, []string{"lifecycle", "logging", "ovn", "operation"}, l.excludeSources, nil, nil, time.Time{})
 	if err != nil {
 		return
 	}
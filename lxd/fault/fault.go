@@ -0,0 +1,201 @@
+// Package fault allows injecting failures into a running daemon so that its behaviour, and the behaviour of the
+// automation built on top of it, can be tested when parts of a cluster misbehave.
+//
+// Fault injection is disabled unless the LXD_FAULT_INJECTION environment variable is set to true when the daemon
+// starts, and must never be enabled on production systems.
+package fault
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// Type is the type of a fault.
+type Type string
+
+// TypeHeartbeatDrop drops the heartbeats sent to the cluster members at the target address (all if empty).
+const TypeHeartbeatDrop = Type("heartbeat-drop")
+
+// TypeDatabaseCommitDelay delays the commit of every database transaction by the fault delay.
+const TypeDatabaseCommitDelay = Type("database-commit-delay")
+
+// TypeStorageDriverError fails the calls to the storage driver of the target pool (all if empty). The calls can be
+// limited to a single driver function with the operation field.
+const TypeStorageDriverError = Type("storage-driver-error")
+
+// TypeEventHubPause stops sending the local events to the event hub members.
+const TypeEventHubPause = Type("event-hub-pause")
+
+// Types lists all the fault types.
+var Types = []Type{TypeHeartbeatDrop, TypeDatabaseCommitDelay, TypeStorageDriverError, TypeEventHubPause}
+
+// ErrDisabled is returned when trying to inject a fault while fault injection is disabled.
+var ErrDisabled = fmt.Errorf("Fault injection is disabled (set LXD_FAULT_INJECTION=true when starting LXD to enable it)")
+
+// Fault represents an injected fault.
+type Fault struct {
+	// Type of fault.
+	Type Type `json:"type" yaml:"type"`
+
+	// Target the fault applies to, all if empty. It is the address of the member for heartbeat faults and the
+	// name of the storage pool for storage driver faults.
+	Target string `json:"target" yaml:"target"`
+
+	// Storage driver function to fail (for example "CreateVolume"), all if empty.
+	Operation string `json:"operation" yaml:"operation"`
+
+	// Delay added by the fault.
+	Delay time.Duration `json:"delay" yaml:"delay"`
+
+	// When the fault is removed automatically, never if zero.
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
+// enabled indicates whether faults can be injected.
+var enabled = shared.IsTrue(os.Getenv("LXD_FAULT_INJECTION"))
+
+var faults = []Fault{}
+var faultsMu sync.Mutex
+
+// Enabled returns whether fault injection is enabled.
+func Enabled() bool {
+	return enabled
+}
+
+// Inject adds a fault.
+func Inject(f Fault) error {
+	if !enabled {
+		return ErrDisabled
+	}
+
+	if !shared.ValueInSlice(f.Type, Types) {
+		return fmt.Errorf("Invalid fault type %q", f.Type)
+	}
+
+	if f.Type == TypeDatabaseCommitDelay && f.Delay <= 0 {
+		return fmt.Errorf("A delay is required for %q faults", f.Type)
+	}
+
+	if f.Operation != "" && f.Type != TypeStorageDriverError {
+		return fmt.Errorf("An operation can only be set for %q faults", TypeStorageDriverError)
+	}
+
+	faultsMu.Lock()
+	faults = append(faults, f)
+	faultsMu.Unlock()
+
+	return nil
+}
+
+// Clear removes the faults of the given type, or all of them if empty.
+func Clear(faultType Type) {
+	faultsMu.Lock()
+	defer faultsMu.Unlock()
+
+	remaining := make([]Fault, 0, len(faults))
+	for _, f := range faults {
+		if faultType != "" && f.Type != faultType {
+			remaining = append(remaining, f)
+		}
+	}
+
+	faults = remaining
+}
+
+// List returns the active faults.
+func List() []Fault {
+	faultsMu.Lock()
+	defer faultsMu.Unlock()
+
+	expire()
+
+	list := make([]Fault, len(faults))
+	copy(list, faults)
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Type < list[j].Type
+	})
+
+	return list
+}
+
+// expire removes the expired faults. Must be called with faultsMu held.
+func expire() {
+	now := time.Now()
+
+	remaining := faults[:0]
+	for _, f := range faults {
+		if f.ExpiresAt.IsZero() || f.ExpiresAt.After(now) {
+			remaining = append(remaining, f)
+		}
+	}
+
+	faults = remaining
+}
+
+// find returns the first active fault of the given type matching the target and operation.
+func find(faultType Type, target string, operation string) (Fault, bool) {
+	if !enabled {
+		return Fault{}, false
+	}
+
+	faultsMu.Lock()
+	defer faultsMu.Unlock()
+
+	expire()
+
+	for _, f := range faults {
+		if f.Type != faultType {
+			continue
+		}
+
+		if f.Target != "" && f.Target != target {
+			continue
+		}
+
+		if f.Operation != "" && f.Operation != operation {
+			continue
+		}
+
+		return f, true
+	}
+
+	return Fault{}, false
+}
+
+// HeartbeatDropped returns whether the heartbeat to the member at the given address must be dropped.
+func HeartbeatDropped(address string) bool {
+	_, found := find(TypeHeartbeatDrop, address, "")
+	return found
+}
+
+// DatabaseCommitDelay returns how long to wait before committing a database transaction.
+func DatabaseCommitDelay() time.Duration {
+	f, found := find(TypeDatabaseCommitDelay, "", "")
+	if !found {
+		return 0
+	}
+
+	return f.Delay
+}
+
+// StorageDriverError returns the error to fail the given call to the storage driver of the pool with, if any.
+func StorageDriverError(poolName string, operation string) error {
+	_, found := find(TypeStorageDriverError, poolName, operation)
+	if !found {
+		return nil
+	}
+
+	return fmt.Errorf("Injected failure of %q on storage pool %q", operation, poolName)
+}
+
+// EventHubPaused returns whether the local events must not be sent to the event hub members.
+func EventHubPaused() bool {
+	_, found := find(TypeEventHubPause, "", "")
+	return found
+}
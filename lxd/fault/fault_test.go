@@ -0,0 +1,40 @@
+package fault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFaults(t *testing.T) {
+	enabled = false
+	assert.ErrorIs(t, Inject(Fault{Type: TypeEventHubPause}), ErrDisabled)
+
+	enabled = true
+	defer func() {
+		enabled = false
+		Clear("")
+	}()
+
+	assert.Error(t, Inject(Fault{Type: "unknown"}))
+	assert.Error(t, Inject(Fault{Type: TypeDatabaseCommitDelay}))
+	assert.Error(t, Inject(Fault{Type: TypeHeartbeatDrop, Operation: "CreateVolume"}))
+
+	assert.NoError(t, Inject(Fault{Type: TypeHeartbeatDrop, Target: "10.0.0.1:8443"}))
+	assert.True(t, HeartbeatDropped("10.0.0.1:8443"))
+	assert.False(t, HeartbeatDropped("10.0.0.2:8443"))
+
+	assert.NoError(t, Inject(Fault{Type: TypeStorageDriverError, Target: "default", Operation: "CreateVolume"}))
+	assert.Error(t, StorageDriverError("default", "CreateVolume"))
+	assert.NoError(t, StorageDriverError("default", "DeleteVolume"))
+	assert.NoError(t, StorageDriverError("remote", "CreateVolume"))
+
+	assert.NoError(t, Inject(Fault{Type: TypeDatabaseCommitDelay, Delay: time.Second, ExpiresAt: time.Now().Add(-time.Second)}))
+	assert.Equal(t, time.Duration(0), DatabaseCommitDelay())
+	assert.Len(t, List(), 2)
+
+	Clear(TypeHeartbeatDrop)
+	assert.False(t, HeartbeatDropped("10.0.0.1:8443"))
+	assert.Len(t, List(), 1)
+}
@@ -49,3 +49,12 @@ type AddressForward struct {
 	ListenPorts   []uint64
 	TargetPorts   []uint64
 }
+
+// RuleCounter represents a firewall rule along with the traffic it matched.
+type RuleCounter struct {
+	Table   string // Table the rule belongs to, prefixed with the family for nftables.
+	Chain   string // Chain the rule belongs to.
+	Rule    string // Rule as listed by the firewall backend, without its counter.
+	Packets uint64 // Number of packets matched by the rule.
+	Bytes   uint64 // Number of bytes matched by the rule.
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"regexp"
 	"strconv"
 	"strings"
 	"text/template"
@@ -126,11 +127,11 @@ func (d Nftables) Compat() (bool, error) {
 
 // nftGenericItem represents some common fields amongst the different nftables types.
 type nftGenericItem struct {
-	ItemType string `json:"-"`      // Type of item (table, chain or rule). Populated by LXD.
+	ItemType string `json:"-"`      // Type of item (table, chain, set or rule). Populated by LXD.
 	Family   string `json:"family"` // Family of item (ip, ip6, bridge etc).
-	Table    string `json:"table"`  // Table the item belongs to (for chains and rules).
+	Table    string `json:"table"`  // Table the item belongs to (for chains, sets and rules).
 	Chain    string `json:"chain"`  // Chain the item belongs to (for rules).
-	Name     string `json:"name"`   // Name of item (for tables and chains).
+	Name     string `json:"name"`   // Name of item (for tables, chains and sets).
 }
 
 // nftParseRuleset parses the ruleset and returns the generic parts as a slice of items.
@@ -163,6 +164,7 @@ func (d Nftables) nftParseRuleset() ([]nftGenericItem, error) {
 	for _, item := range v.Nftables {
 		rule, foundRule := item["rule"]
 		chain, foundChain := item["chain"]
+		set, foundSet := item["set"]
 		table, foundTable := item["table"]
 		if foundRule {
 			rule.ItemType = "rule"
@@ -170,6 +172,9 @@ func (d Nftables) nftParseRuleset() ([]nftGenericItem, error) {
 		} else if foundChain {
 			chain.ItemType = "chain"
 			items = append(items, chain)
+		} else if foundSet {
+			set.ItemType = "set"
+			items = append(items, set)
 		} else if foundTable {
 			table.ItemType = "table"
 			items = append(items, table)
@@ -434,10 +439,23 @@ func (d Nftables) InstanceSetupBridgeFilter(projectName string, instanceName str
 		})
 	}
 
+	ipv6Elements := make([]string, 0, len(ipv6Nets))
+	for _, ipv6Net := range ipv6Nets {
+		ipv6Elements = append(ipv6Elements, ipv6Net["net"])
+	}
+
 	tplFields["ipv4Nets"] = ipv4Nets
+	tplFields["ipv4Elements"] = strings.Join(ipv4Nets, ", ")
 	tplFields["ipv6Nets"] = ipv6Nets
+	tplFields["ipv6Elements"] = strings.Join(ipv6Elements, ", ")
+
+	config := &strings.Builder{}
+	err = nftablesInstanceBridgeFilter.Execute(config, tplFields)
+	if err != nil {
+		return fmt.Errorf("Failed running %q template: %w", nftablesInstanceBridgeFilter.Name(), err)
+	}
 
-	err = d.applyNftConfig(nftablesInstanceBridgeFilter, tplFields)
+	err = shared.RunCommandWithFds(context.TODO(), strings.NewReader(config.String()), nil, "nft", "-f", "-")
 	if err != nil {
 		return fmt.Errorf("Failed adding bridge filter rules for instance device %q (%s): %w", deviceLabel, tplFields["family"], err)
 	}
@@ -455,6 +473,12 @@ func (d Nftables) InstanceClearBridgeFilter(projectName string, instanceName str
 		return fmt.Errorf("Failed clearing bridge filter rules for instance device %q: %w", deviceLabel, err)
 	}
 
+	// Remove the sets of allowed IPs once the chains referencing them are gone.
+	err = d.removeSets([]string{"bridge"}, deviceLabel, "ipv4", "ipv6")
+	if err != nil {
+		return fmt.Errorf("Failed clearing bridge filter sets for instance device %q: %w", deviceLabel, err)
+	}
+
 	return nil
 }
 
@@ -627,6 +651,33 @@ func (d Nftables) removeChains(families []string, chainSuffix string, chains ...
 	return nil
 }
 
+// removeSets removes the specified sets from the specified families.
+// The set suffix is appended to each set name, separated with the chain separator.
+func (d Nftables) removeSets(families []string, setSuffix string, sets ...string) error {
+	ruleset, err := d.nftParseRuleset()
+	if err != nil {
+		return err
+	}
+
+	fullSets := make([]string, 0, len(sets))
+	for _, set := range sets {
+		fullSets = append(fullSets, fmt.Sprintf("%s%s%s", set, nftablesChainSeparator, setSuffix))
+	}
+
+	for _, item := range ruleset {
+		if item.ItemType != "set" || item.Table != nftablesNamespace || !shared.ValueInSlice(item.Family, families) || !shared.ValueInSlice(item.Name, fullSets) {
+			continue
+		}
+
+		_, err = shared.RunCommand("nft", "delete", "set", item.Family, nftablesNamespace, item.Name)
+		if err != nil {
+			return fmt.Errorf("Failed deleting nftables set %q (%s): %w", item.Name, item.Family, err)
+		}
+	}
+
+	return nil
+}
+
 // InstanceSetupRPFilter activates reverse path filtering for the specified instance device on the host interface.
 func (d Nftables) InstanceSetupRPFilter(projectName string, instanceName string, deviceName string, hostName string) error {
 	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
@@ -696,6 +747,67 @@ func (d Nftables) InstanceClearNetPrio(projectName string, instanceName string,
 	return nil
 }
 
+// InstanceRules returns the rules applied for the specified instance device along with their counters.
+func (d Nftables) InstanceRules(projectName string, instanceName string, deviceName string) ([]RuleCounter, error) {
+	deviceLabel := d.instanceDeviceLabel(projectName, instanceName, deviceName)
+
+	ruleset, err := d.nftParseRuleset()
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing nftables existing ruleset: %w", err)
+	}
+
+	rules := []RuleCounter{}
+	for _, item := range ruleset {
+		if item.ItemType != "chain" || item.Table != nftablesNamespace || !strings.HasSuffix(item.Name, nftablesChainSeparator+deviceLabel) {
+			continue
+		}
+
+		output, err := shared.RunCommandCLocale("nft", "-nn", "list", "chain", item.Family, nftablesNamespace, item.Name)
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing nftables chain %q (%s): %w", item.Name, item.Family, err)
+		}
+
+		rules = append(rules, nftParseChainRules(item.Family, item.Name, output)...)
+	}
+
+	return rules, nil
+}
+
+// nftablesCounterRegex matches the counter statement of a listed rule.
+var nftablesCounterRegex = regexp.MustCompile(` counter packets (\d+) bytes (\d+)`)
+
+// nftParseChainRules parses the rules of a chain as listed by nft along with their counters.
+// Rules without a counter are returned with zero counters.
+func nftParseChainRules(family string, chain string, output string) []RuleCounter {
+	rules := []RuleCounter{}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		// Skip the table and chain definitions.
+		if line == "" || line == "}" || strings.HasPrefix(line, "table ") || strings.HasPrefix(line, "chain ") || strings.HasPrefix(line, "type ") {
+			continue
+		}
+
+		rule := RuleCounter{
+			Table: fmt.Sprintf("%s %s", family, nftablesNamespace),
+			Chain: chain,
+			Rule:  line,
+		}
+
+		match := nftablesCounterRegex.FindStringSubmatch(line)
+		if match != nil {
+			rule.Packets, _ = strconv.ParseUint(match[1], 10, 64)
+			rule.Bytes, _ = strconv.ParseUint(match[2], 10, 64)
+			rule.Rule = nftablesCounterRegex.ReplaceAllString(line, "")
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
 // NetworkApplyACLRules applies ACL rules to the existing firewall chains.
 func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) error {
 	nftRules := make([]string, 0)
@@ -97,21 +97,21 @@ table {{.family}} {{.namespace}} {
 	chain {{.chainPrefix}}prert{{.chainSeparator}}{{.label}} {
 		type nat hook prerouting priority -100; policy accept;
 		{{- range .dnatRules}}
-		{{.ipFamily}} daddr {{.listenAddress}} {{if .protocol}}{{.protocol}} dport {{.listenPorts}}{{end}} dnat to {{.targetDest}}
+		{{.ipFamily}} daddr {{.listenAddress}} {{if .protocol}}{{.protocol}} dport {{.listenPorts}}{{end}} counter dnat to {{.targetDest}}
 		{{- end}}
 	}
 
 	chain {{.chainPrefix}}out{{.chainSeparator}}{{.label}} {
 		type nat hook output priority -100; policy accept;
 		{{- range .dnatRules}}
-		{{.ipFamily}} daddr {{.listenAddress}} {{if .protocol}}{{.protocol}} dport {{.listenPorts}}{{end}} dnat to {{.targetDest}}
+		{{.ipFamily}} daddr {{.listenAddress}} {{if .protocol}}{{.protocol}} dport {{.listenPorts}}{{end}} counter dnat to {{.targetDest}}
 		{{- end}}
 	}
 
 	chain {{.chainPrefix}}pstrt{{.chainSeparator}}{{.label}} {
 		type nat hook postrouting priority 100; policy accept;
 		{{- range .snatRules}}
-		{{.ipFamily}} saddr {{.targetHost}} {{.ipFamily}} daddr {{.targetHost}} {{if .protocol}}{{.protocol}} dport {{.targetPorts}}{{end}} masquerade
+		{{.ipFamily}} saddr {{.targetHost}} {{.ipFamily}} daddr {{.targetHost}} {{if .protocol}}{{.protocol}} dport {{.targetPorts}}{{end}} counter masquerade
 		{{- end}}
 	}
 }
@@ -190,74 +190,99 @@ table {{.family}} {{.namespace}} {
 // NDP advertisements that come from the genuine Ethernet MAC address but have a spoofed NDP source MAC/IP address
 // we need to use manual header offset extraction. This also drops IPv6 router advertisements from instance.
 // If IP filtering is enabled, this also drops unwanted ethernet frames.
+// The allowed IPs are kept in named sets, and the chains and sets are flushed and filled in the same transaction
+// so that the filter is replaced atomically when the device is updated.
 var nftablesInstanceBridgeFilter = template.Must(template.New("nftablesInstanceBridgeFilter").Parse(`
-chain in{{.chainSeparator}}{{.deviceLabel}} {
-	type filter hook input priority -200; policy accept;
-	iifname "{{.hostName}}" ether saddr != {{.hwAddr}} drop
-	iifname "{{.hostName}}" ether type arp arp saddr ether != {{.hwAddr}} drop
-	iifname "{{.hostName}}" ether type ip6 icmpv6 type 136 @nh,528,48 != {{.hwAddrHex}} drop
+add table {{.family}} {{.namespace}}
+add chain {{.family}} {{.namespace}} in{{.chainSeparator}}{{.deviceLabel}} {type filter hook input priority -200; policy accept;}
+add chain {{.family}} {{.namespace}} fwd{{.chainSeparator}}{{.deviceLabel}} {type filter hook forward priority -200; policy accept;}
+flush chain {{.family}} {{.namespace}} in{{.chainSeparator}}{{.deviceLabel}}
+flush chain {{.family}} {{.namespace}} fwd{{.chainSeparator}}{{.deviceLabel}}
+{{if .ipv4Nets -}}
+add set {{.family}} {{.namespace}} ipv4{{.chainSeparator}}{{.deviceLabel}} {type ipv4_addr; flags interval; auto-merge;}
+flush set {{.family}} {{.namespace}} ipv4{{.chainSeparator}}{{.deviceLabel}}
+{{end -}}
+{{if .ipv6Nets -}}
+add set {{.family}} {{.namespace}} ipv6{{.chainSeparator}}{{.deviceLabel}} {type ipv6_addr; flags interval; auto-merge;}
+flush set {{.family}} {{.namespace}} ipv6{{.chainSeparator}}{{.deviceLabel}}
+{{end}}
+table {{.family}} {{.namespace}} {
 	{{if .ipv4Nets -}}
-	iifname "{{.hostName}}" ether type ip ip saddr 0.0.0.0 ip daddr 255.255.255.255 udp dport 67 accept
-	{{range .ipv4Nets -}}
-	iifname "{{$.hostName}}" ether type arp arp saddr ip {{.}} accept
-	iifname "{{$.hostName}}" ether type ip ip saddr {{.}} accept
-	{{end}}
-	iifname "{{.hostName}}" ether type arp drop
-	iifname "{{.hostName}}" ether type ip drop
-	{{- end}}
-	{{if .ipv4FilterAll -}}
-	iifname "{{.hostName}}" ether type arp drop
-	iifname "{{.hostName}}" ether type ip drop
-	{{- end}}
-	{{if .ipv6Nets -}}
-	iifname "{{.hostName}}" ether type ip6 ip6 saddr fe80::/10 ip6 daddr ff02::1:2 udp dport 547 accept
-	iifname "{{.hostName}}" ether type ip6 ip6 saddr fe80::/10 ip6 daddr ff02::2 icmpv6 type 133 accept
-	iifname "{{.hostName}}" ether type ip6 icmpv6 type 134 drop
-	{{ range .ipv6Nets -}}
-	iifname "{{$.hostName}}" ether type ip6 icmpv6 type 136 @nh,384,{{.nBits}} {{.hexPrefix}} accept
-	iifname "{{$.hostName}}" ether type ip6 ip6 saddr {{.net}} accept
-	{{end}}
-	iifname "{{.hostName}}" ether type ip6 drop
-	{{- end}}
-	{{if .ipv6FilterAll -}}
-	iifname "{{.hostName}}" ether type ip6 drop
-	{{- end}}
-	{{if .filterUnwantedFrames -}}
-	iifname "{{.hostName}}" ether type != {arp, ip, ip6} drop
+	set ipv4{{.chainSeparator}}{{.deviceLabel}} {
+		type ipv4_addr; flags interval; auto-merge;
+		elements = { {{.ipv4Elements}} }
+	}
 	{{- end}}
-}
 
-chain fwd{{.chainSeparator}}{{.deviceLabel}} {
-	type filter hook forward priority -200; policy accept;
-	iifname "{{.hostName}}" ether saddr != {{.hwAddr}} drop
-	iifname "{{.hostName}}" ether type arp arp saddr ether != {{.hwAddr}} drop
-	iifname "{{.hostName}}" ether type ip6 icmpv6 type 136 @nh,528,48 != {{.hwAddrHex}} drop
-	{{if .ipv4Nets -}}
-	{{range .ipv4Nets -}}
-	iifname "{{$.hostName}}" ether type arp arp saddr ip {{.}} accept
-	iifname "{{$.hostName}}" ether type ip ip saddr {{.}} accept
-	{{end}}
-	iifname "{{.hostName}}" ether type arp drop
-	iifname "{{.hostName}}" ether type ip drop
-	{{end}}
-	{{if .ipv4FilterAll -}}
-	iifname "{{.hostName}}" ether type arp drop
-	iifname "{{.hostName}}" ether type ip drop
-	{{- end}}
 	{{if .ipv6Nets -}}
-	iifname "{{.hostName}}" ether type ip6 icmpv6 type 134 drop
-	{{range .ipv6Nets}}
-	iifname "{{$.hostName}}" ether type ip6 ip6 saddr {{.net}} accept
-	iifname "{{$.hostName}}" ether type ip6 icmpv6 type 136 @nh,384,{{.nBits}} {{.hexPrefix}} accept
-	{{end}}
-	iifname "{{.hostName}}" ether type ip6 drop
-	{{- end}}
-	{{if .ipv6FilterAll -}}
-	iifname "{{.hostName}}" ether type ip6 drop
-	{{- end}}
-	{{if .filterUnwantedFrames -}}
-	iifname "{{.hostName}}" ether type != {arp, ip, ip6} drop
+	set ipv6{{.chainSeparator}}{{.deviceLabel}} {
+		type ipv6_addr; flags interval; auto-merge;
+		elements = { {{.ipv6Elements}} }
+	}
 	{{- end}}
+
+	chain in{{.chainSeparator}}{{.deviceLabel}} {
+		iifname "{{.hostName}}" ether saddr != {{.hwAddr}} counter drop
+		iifname "{{.hostName}}" ether type arp arp saddr ether != {{.hwAddr}} counter drop
+		iifname "{{.hostName}}" ether type ip6 icmpv6 type 136 @nh,528,48 != {{.hwAddrHex}} counter drop
+		{{if .ipv4Nets -}}
+		iifname "{{.hostName}}" ether type ip ip saddr 0.0.0.0 ip daddr 255.255.255.255 udp dport 67 counter accept
+		iifname "{{.hostName}}" ether type arp arp saddr ip @ipv4{{.chainSeparator}}{{.deviceLabel}} counter accept
+		iifname "{{.hostName}}" ether type ip ip saddr @ipv4{{.chainSeparator}}{{.deviceLabel}} counter accept
+		iifname "{{.hostName}}" ether type arp counter drop
+		iifname "{{.hostName}}" ether type ip counter drop
+		{{- end}}
+		{{if .ipv4FilterAll -}}
+		iifname "{{.hostName}}" ether type arp counter drop
+		iifname "{{.hostName}}" ether type ip counter drop
+		{{- end}}
+		{{if .ipv6Nets -}}
+		iifname "{{.hostName}}" ether type ip6 ip6 saddr fe80::/10 ip6 daddr ff02::1:2 udp dport 547 counter accept
+		iifname "{{.hostName}}" ether type ip6 ip6 saddr fe80::/10 ip6 daddr ff02::2 icmpv6 type 133 counter accept
+		iifname "{{.hostName}}" ether type ip6 icmpv6 type 134 counter drop
+		{{range .ipv6Nets -}}
+		iifname "{{$.hostName}}" ether type ip6 icmpv6 type 136 @nh,384,{{.nBits}} {{.hexPrefix}} counter accept
+		{{end -}}
+		iifname "{{.hostName}}" ether type ip6 ip6 saddr @ipv6{{.chainSeparator}}{{.deviceLabel}} counter accept
+		iifname "{{.hostName}}" ether type ip6 counter drop
+		{{- end}}
+		{{if .ipv6FilterAll -}}
+		iifname "{{.hostName}}" ether type ip6 counter drop
+		{{- end}}
+		{{if .filterUnwantedFrames -}}
+		iifname "{{.hostName}}" ether type != {arp, ip, ip6} counter drop
+		{{- end}}
+	}
+
+	chain fwd{{.chainSeparator}}{{.deviceLabel}} {
+		iifname "{{.hostName}}" ether saddr != {{.hwAddr}} counter drop
+		iifname "{{.hostName}}" ether type arp arp saddr ether != {{.hwAddr}} counter drop
+		iifname "{{.hostName}}" ether type ip6 icmpv6 type 136 @nh,528,48 != {{.hwAddrHex}} counter drop
+		{{if .ipv4Nets -}}
+		iifname "{{.hostName}}" ether type arp arp saddr ip @ipv4{{.chainSeparator}}{{.deviceLabel}} counter accept
+		iifname "{{.hostName}}" ether type ip ip saddr @ipv4{{.chainSeparator}}{{.deviceLabel}} counter accept
+		iifname "{{.hostName}}" ether type arp counter drop
+		iifname "{{.hostName}}" ether type ip counter drop
+		{{- end}}
+		{{if .ipv4FilterAll -}}
+		iifname "{{.hostName}}" ether type arp counter drop
+		iifname "{{.hostName}}" ether type ip counter drop
+		{{- end}}
+		{{if .ipv6Nets -}}
+		iifname "{{.hostName}}" ether type ip6 icmpv6 type 134 counter drop
+		iifname "{{.hostName}}" ether type ip6 ip6 saddr @ipv6{{.chainSeparator}}{{.deviceLabel}} counter accept
+		{{range .ipv6Nets -}}
+		iifname "{{$.hostName}}" ether type ip6 icmpv6 type 136 @nh,384,{{.nBits}} {{.hexPrefix}} counter accept
+		{{end -}}
+		iifname "{{.hostName}}" ether type ip6 counter drop
+		{{- end}}
+		{{if .ipv6FilterAll -}}
+		iifname "{{.hostName}}" ether type ip6 counter drop
+		{{- end}}
+		{{if .filterUnwantedFrames -}}
+		iifname "{{.hostName}}" ether type != {arp, ip, ip6} counter drop
+		{{- end}}
+	}
 }
 `))
 
@@ -265,7 +290,7 @@ chain fwd{{.chainSeparator}}{{.deviceLabel}} {
 var nftablesInstanceRPFilter = template.Must(template.New("nftablesInstanceRPFilter").Parse(`
 chain prert{{.chainSeparator}}{{.deviceLabel}} {
 	type filter hook prerouting priority -300; policy accept;
-	iif "{{.hostName}}" fib saddr . iif oif missing drop
+	iif "{{.hostName}}" fib saddr . iif oif missing counter drop
 }
 `))
 
@@ -273,6 +298,6 @@ chain prert{{.chainSeparator}}{{.deviceLabel}} {
 var nftablesInstanceNetPrio = template.Must(template.New("nftablesInstanceNetPrio").Parse(`
 chain egress{{.chainSeparator}}netprio{{.chainSeparator}}{{.deviceLabel}} {
 	type filter hook egress device "{{.deviceName}}" priority 0 ;
-	meta priority set "{{.netPrio}}"
+	counter meta priority set "{{.netPrio}}"
 }
 `))
@@ -0,0 +1,62 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_nftParseChainRules(t *testing.T) {
+	output := `table bridge lxd {
+	chain in.c1.eth0 {
+		type filter hook input priority -200; policy accept;
+		iifname "veth1234" ether saddr != 00:16:3e:00:00:01 counter packets 3 bytes 180 drop
+		iifname "veth1234" ether type ip ip saddr @ipv4.c1.eth0 counter packets 12 bytes 1008 accept
+		iifname "veth1234" ether type ip drop
+	}
+}
+`
+
+	expected := []RuleCounter{
+		{
+			Table:   "bridge lxd",
+			Chain:   "in.c1.eth0",
+			Rule:    `iifname "veth1234" ether saddr != 00:16:3e:00:00:01 drop`,
+			Packets: 3,
+			Bytes:   180,
+		},
+		{
+			Table:   "bridge lxd",
+			Chain:   "in.c1.eth0",
+			Rule:    `iifname "veth1234" ether type ip ip saddr @ipv4.c1.eth0 accept`,
+			Packets: 12,
+			Bytes:   1008,
+		},
+		{
+			Table: "bridge lxd",
+			Chain: "in.c1.eth0",
+			Rule:  `iifname "veth1234" ether type ip drop`,
+		},
+	}
+
+	assert.Equal(t, expected, nftParseChainRules("bridge", "in.c1.eth0", output))
+}
+
+func Test_xtablesParseRuleCounters(t *testing.T) {
+	output := `-P PREROUTING ACCEPT -c 0 0
+-A PREROUTING -i veth1234 -m rpfilter --invert -m comment --comment "generated for LXD container c1 (eth0) rpfilter" -c 5 300 -j DROP
+-A PREROUTING -i veth5678 -m rpfilter --invert -m comment --comment "generated for LXD container c10 (eth0) rpfilter" -c 1 60 -j DROP
+`
+
+	expected := []RuleCounter{
+		{
+			Table:   "iptables raw",
+			Chain:   "PREROUTING",
+			Rule:    `-i veth1234 -m rpfilter --invert -m comment --comment "generated for LXD container c1 (eth0) rpfilter" -j DROP`,
+			Packets: 5,
+			Bytes:   300,
+		},
+	}
+
+	assert.Equal(t, expected, xtablesParseRuleCounters("iptables raw", "generated for LXD container c1 (eth0)", output))
+}
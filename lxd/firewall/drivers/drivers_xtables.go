@@ -7,6 +7,7 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -1656,3 +1657,70 @@ func (d Xtables) NetworkApplyForwards(networkName string, rules []AddressForward
 	reverter.Success()
 	return nil
 }
+
+// InstanceRules returns the iptables rules applied for the specified instance device along with their counters.
+// The ebtables rules used for bridge filtering aren't tagged with the instance device and so aren't included.
+func (d Xtables) InstanceRules(projectName string, instanceName string, deviceName string) ([]RuleCounter, error) {
+	comment := fmt.Sprintf("%s %s", iptablesCommentPrefix, d.instanceDeviceIPTablesComment(projectName, instanceName, deviceName))
+	rules := []RuleCounter{}
+
+	for _, ipVersion := range []uint{4, 6} {
+		cmd := "iptables"
+		if ipVersion == 6 {
+			cmd = "ip6tables"
+
+			// Detect kernels that lack IPv6 support.
+			if !shared.PathExists("/proc/sys/net/ipv6") {
+				continue
+			}
+		}
+
+		_, err := exec.LookPath(cmd)
+		if err != nil {
+			continue
+		}
+
+		for _, table := range []string{"filter", "nat", "mangle", "raw"} {
+			output, err := shared.RunCommandCLocale(cmd, "-w", "-t", table, "--list-rules", "--verbose")
+			if err != nil {
+				return nil, fmt.Errorf("Failed to list IPv%d rules (table %s): %w", ipVersion, table, err)
+			}
+
+			rules = append(rules, xtablesParseRuleCounters(fmt.Sprintf("%s %s", cmd, table), comment, output)...)
+		}
+	}
+
+	return rules, nil
+}
+
+// xtablesCounterRegex matches the counters of a rule listed in verbose mode.
+var xtablesCounterRegex = regexp.MustCompile(` -c (\d+) (\d+)`)
+
+// xtablesParseRuleCounters parses the rules listed by iptables in verbose mode that contain the specified comment.
+func xtablesParseRuleCounters(table string, comment string, output string) []RuleCounter {
+	rules := []RuleCounter{}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "-A" || !strings.Contains(line, comment) {
+			continue
+		}
+
+		rule := RuleCounter{
+			Table: table,
+			Chain: fields[1],
+			Rule:  strings.TrimSpace(strings.TrimPrefix(line, fmt.Sprintf("-A %s", fields[1]))),
+		}
+
+		match := xtablesCounterRegex.FindStringSubmatch(line)
+		if match != nil {
+			rule.Packets, _ = strconv.ParseUint(match[1], 10, 64)
+			rule.Bytes, _ = strconv.ParseUint(match[2], 10, 64)
+			rule.Rule = strings.TrimSpace(xtablesCounterRegex.ReplaceAllString(rule.Rule, ""))
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
@@ -760,6 +760,13 @@ func patchIdentity(d *Daemon, r *http.Request) response.Response {
 // are of type api.IdentityTypeCertificateServer. This ensures that this cluster member is able to
 // trust other cluster members on restart.
 func updateIdentityCache(d *Daemon) {
+	loadIdentityCache(d, true)
+}
+
+// loadIdentityCache reads the trusted identities from the global database into the identity cache. When
+// storeServerCerts is true, the server certificates are also written to the local database to allow the cluster to
+// restart. Otherwise, the server certificates already in the cache are kept.
+func loadIdentityCache(d *Daemon, storeServerCerts bool) {
 	s := d.State()
 
 	logger.Debug("Refreshing identity cache")
@@ -861,13 +868,28 @@ func updateIdentityCache(d *Daemon) {
 	}
 
 	// Write out the server certs to the local database to allow the cluster to restart.
-	err = s.DB.Node.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.NodeTx) error {
-		return tx.ReplaceCertificates(localServerCerts)
-	})
-	if err != nil {
-		logger.Warn("Failed writing certificates to local database", logger.Ctx{"err": err})
-		// Don't return here, as we still should update the in-memory cache to allow the cluster to
-		// continue functioning, and hopefully the write will succeed on next update.
+	if storeServerCerts {
+		err = s.DB.Node.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.NodeTx) error {
+			return tx.ReplaceCertificates(localServerCerts)
+		})
+		if err != nil {
+			logger.Warn("Failed writing certificates to local database", logger.Ctx{"err": err})
+			// Don't return here, as we still should update the in-memory cache to allow the cluster to
+			// continue functioning, and hopefully the write will succeed on next update.
+		}
+	} else {
+		// Keep the server certificates loaded from the local database, as the patches may not have added all of
+		// them to the global database yet.
+		loaded := make(map[string]bool, len(identityCacheEntries))
+		for _, entry := range identityCacheEntries {
+			loaded[entry.Identifier] = true
+		}
+
+		for identifier, entry := range d.identityCache.GetByType(api.IdentityTypeCertificateServer) {
+			if !loaded[identifier] {
+				identityCacheEntries = append(identityCacheEntries, entry)
+			}
+		}
 	}
 
 	err = d.identityCache.ReplaceAll(identityCacheEntries, idpGroupMapping)
@@ -53,6 +53,7 @@ import (
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/osarch"
 	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/validate"
 	"github.com/canonical/lxd/shared/version"
 )
 
@@ -191,6 +192,44 @@ func compressFile(compress string, infile io.Reader, outfile io.Writer) error {
 	return nil
 }
 
+// compressionLevels lists the maximum level of the compression algorithms supporting compression levels.
+var compressionLevels = map[string]int{
+	"bzip2": 9,
+	"gzip":  9,
+	"lz4":   12,
+	"lzma":  9,
+	"xz":    9,
+	"zstd":  19,
+}
+
+// compressionWithLevel returns the compression command with the given compression level added, or unchanged if
+// the level is zero.
+func compressionWithLevel(compress string, level int) (string, error) {
+	if level == 0 {
+		return compress, nil
+	}
+
+	fields, err := shellquote.Split(compress)
+	if err != nil {
+		return "", err
+	}
+
+	if len(fields) == 0 {
+		return "", fmt.Errorf("No compression algorithm provided")
+	}
+
+	maxLevel, ok := compressionLevels[fields[0]]
+	if !ok {
+		return "", fmt.Errorf("Compression algorithm %q doesn't support compression levels", fields[0])
+	}
+
+	if level < 1 || level > maxLevel {
+		return "", fmt.Errorf("Invalid compression level %d for %q (must be between 1 and %d)", level, fields[0], maxLevel)
+	}
+
+	return fmt.Sprintf("%s -%d", compress, level), nil
+}
+
 /*
  * This function takes a container or snapshot from the local image server and
  * exports it as an image.
@@ -309,6 +348,16 @@ func imgPostInstanceInfo(s *state.State, r *http.Request, req api.ImagesPost, op
 		}
 	}
 
+	compress, err = compressionWithLevel(compress, req.CompressionLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	// tar2sqfs records the time the filesystem was created.
+	if req.Reproducible && strings.HasPrefix(compress, "squashfs") {
+		return nil, fmt.Errorf("Reproducible images can't be compressed with squashfs")
+	}
+
 	// Setup tar, optional compress and sha256 to happen in one pass.
 	wg := sync.WaitGroup{}
 	var compressErr error
@@ -336,7 +385,7 @@ func imgPostInstanceInfo(s *state.State, r *http.Request, req api.ImagesPost, op
 	var meta api.ImageMetadata
 
 	writer = shared.NewQuotaWriter(writer, budget)
-	meta, err = c.Export(writer, req.Properties, req.ExpiresAt)
+	meta, err = c.Export(writer, req.Properties, req.ExpiresAt, req.Reproducible)
 
 	// Get ExpiresAt
 	if meta.ExpiryDate != 0 {
@@ -572,93 +621,120 @@ func imgPostURLInfo(s *state.State, r *http.Request, req api.ImagesPost, op *ope
 	return info, nil
 }
 
-func getImgPostInfo(s *state.State, r *http.Request, builddir string, project string, post *os.File, metadata map[string]any) (*api.Image, error) {
-	info := api.Image{}
-	var imageMeta *api.ImageMetadata
-	l := logger.AddContext(logger.Ctx{"function": "getImgPostInfo"})
+// imageUploadFiles holds the files of a split image received from a multipart upload.
+type imageUploadFiles struct {
+	metaFile    string
+	rootfsFile  string
+	filename    string
+	imageType   string
+	fingerprint string
+	size        int64
+}
 
-	info.Public = shared.IsTrue(r.Header.Get("X-LXD-public"))
-	propHeaders := r.Header[http.CanonicalHeaderKey("X-LXD-properties")]
-	profilesHeaders := r.Header.Get("X-LXD-profiles")
-	ctype, ctypeParams, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+// isMultipartUpload returns whether the request is a multipart upload of a split image.
+func isMultipartUpload(r *http.Request) bool {
+	ctype, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	return err == nil && ctype == "multipart/form-data"
+}
+
+// imgPostReceiveMultipart receives the metadata and rootfs tarballs of a multipart image upload straight from the
+// request body into files in builddir, without spooling the whole request to disk first.
+func imgPostReceiveMultipart(r *http.Request, builddir string, budget int64) (*imageUploadFiles, error) {
+	l := logger.AddContext(logger.Ctx{"function": "imgPostReceiveMultipart"})
+
+	_, ctypeParams, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
 	if err != nil {
-		ctype = "application/octet-stream"
+		return nil, err
 	}
 
+	upload := &imageUploadFiles{}
 	sha256 := sha256.New()
-	var size int64
 
-	if ctype == "multipart/form-data" {
-		// Create a temporary file for the image tarball
-		imageTarf, err := os.CreateTemp(builddir, "lxd_tar_")
+	// The quota applies to the sum of both tarballs.
+	quota := shared.NewQuotaWriter(io.Discard, budget)
+
+	receivePart := func(part *multipart.Part) (string, error) {
+		f, err := os.CreateTemp(builddir, "lxd_tar_")
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
-		defer func() { _ = os.Remove(imageTarf.Name()) }()
+		defer func() { _ = f.Close() }()
 
-		// Parse the POST data
-		_, err = post.Seek(0, io.SeekStart)
+		size, err := io.Copy(io.MultiWriter(quota, f, sha256), part)
+		upload.size += size
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
-		mr := multipart.NewReader(post, ctypeParams["boundary"])
+		return f.Name(), f.Close()
+	}
 
-		// Get the metadata tarball
-		part, err := mr.NextPart()
-		if err != nil {
-			return nil, err
-		}
+	mr := multipart.NewReader(r.Body, ctypeParams["boundary"])
 
-		if part.FormName() != "metadata" {
-			return nil, fmt.Errorf("Invalid multipart image")
-		}
+	// Get the metadata tarball
+	part, err := mr.NextPart()
+	if err != nil {
+		return nil, err
+	}
 
-		size, err = io.Copy(io.MultiWriter(imageTarf, sha256), part)
-		info.Size += size
+	if part.FormName() != "metadata" {
+		return nil, fmt.Errorf("Invalid multipart image")
+	}
 
-		_ = imageTarf.Close()
-		if err != nil {
-			l.Error("Failed to copy the image tarfile", logger.Ctx{"err": err})
-			return nil, err
-		}
+	upload.metaFile, err = receivePart(part)
+	if err != nil {
+		l.Error("Failed to copy the image tarfile", logger.Ctx{"err": err})
+		return nil, err
+	}
 
-		// Get the rootfs tarball
-		part, err = mr.NextPart()
-		if err != nil {
-			l.Error("Failed to get the next part", logger.Ctx{"err": err})
-			return nil, err
-		}
+	// Get the rootfs tarball
+	part, err = mr.NextPart()
+	if err != nil {
+		l.Error("Failed to get the next part", logger.Ctx{"err": err})
+		return nil, err
+	}
 
-		if part.FormName() == "rootfs" {
-			info.Type = instancetype.Container.String()
-		} else if part.FormName() == "rootfs.img" {
-			info.Type = instancetype.VM.String()
-		} else {
-			l.Error("Invalid multipart image")
-			return nil, fmt.Errorf("Invalid multipart image")
-		}
+	if part.FormName() == "rootfs" {
+		upload.imageType = instancetype.Container.String()
+	} else if part.FormName() == "rootfs.img" {
+		upload.imageType = instancetype.VM.String()
+	} else {
+		l.Error("Invalid multipart image")
+		return nil, fmt.Errorf("Invalid multipart image")
+	}
 
-		// Create a temporary file for the rootfs tarball
-		rootfsTarf, err := os.CreateTemp(builddir, "lxd_tar_")
-		if err != nil {
-			return nil, err
-		}
+	upload.rootfsFile, err = receivePart(part)
+	if err != nil {
+		l.Error("Failed to copy the rootfs tarfile", logger.Ctx{"err": err})
+		return nil, err
+	}
 
-		defer func() { _ = os.Remove(rootfsTarf.Name()) }()
+	upload.filename = part.FileName()
+	upload.fingerprint = fmt.Sprintf("%x", sha256.Sum(nil))
 
-		size, err = io.Copy(io.MultiWriter(rootfsTarf, sha256), part)
-		info.Size += size
+	return upload, nil
+}
 
-		_ = rootfsTarf.Close()
-		if err != nil {
-			l.Error("Failed to copy the rootfs tarfile", logger.Ctx{"err": err})
-			return nil, err
-		}
+func getImgPostInfo(s *state.State, r *http.Request, project string, post *os.File, upload *imageUploadFiles, metadata map[string]any) (*api.Image, error) {
+	info := api.Image{}
+	var imageMeta *api.ImageMetadata
+	l := logger.AddContext(logger.Ctx{"function": "getImgPostInfo"})
 
-		info.Filename = part.FileName()
-		info.Fingerprint = fmt.Sprintf("%x", sha256.Sum(nil))
+	info.Public = shared.IsTrue(r.Header.Get("X-LXD-public"))
+	propHeaders := r.Header[http.CanonicalHeaderKey("X-LXD-properties")]
+	profilesHeaders := r.Header.Get("X-LXD-profiles")
+
+	sha256 := sha256.New()
+	var size int64
+	var err error
+
+	if upload != nil {
+		info.Size = upload.size
+		info.Type = upload.imageType
+		info.Filename = upload.filename
+		info.Fingerprint = upload.fingerprint
 
 		expectedFingerprint := r.Header.Get("X-LXD-fingerprint")
 		if expectedFingerprint != "" && info.Fingerprint != expectedFingerprint {
@@ -666,29 +742,29 @@ func getImgPostInfo(s *state.State, r *http.Request, builddir string, project st
 			return nil, err
 		}
 
-		imageMeta, _, err = getImageMetadata(imageTarf.Name())
+		imageMeta, _, err = getImageMetadata(upload.metaFile)
 		if err != nil {
 			l.Error("Failed to get image metadata", logger.Ctx{"err": err})
 			return nil, err
 		}
 
 		imgfname := shared.VarPath("images", info.Fingerprint)
-		err = shared.FileMove(imageTarf.Name(), imgfname)
+		err = shared.FileMove(upload.metaFile, imgfname)
 		if err != nil {
 			l.Error("Failed to move the image tarfile", logger.Ctx{
 				"err":    err,
-				"source": imageTarf.Name(),
+				"source": upload.metaFile,
 				"dest":   imgfname})
 			return nil, err
 		}
 
 		rootfsfname := shared.VarPath("images", info.Fingerprint+".rootfs")
-		err = shared.FileMove(rootfsTarf.Name(), rootfsfname)
+		err = shared.FileMove(upload.rootfsFile, rootfsfname)
 		if err != nil {
 			l.Error("Failed to move the rootfs tarfile", logger.Ctx{
 				"err":    err,
-				"source": rootfsTarf.Name(),
-				"dest":   imgfname})
+				"source": upload.rootfsFile,
+				"dest":   rootfsfname})
 			return nil, err
 		}
 	} else {
@@ -1025,13 +1101,6 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
-	// Store the post data to disk
-	post, err := os.CreateTemp(builddir, "lxd_post_")
-	if err != nil {
-		cleanup(builddir, nil)
-		return response.InternalError(err)
-	}
-
 	// Possibly set a quota on the amount of disk space this project is
 	// allowed to use.
 	var budget int64
@@ -1040,34 +1109,58 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 		return err
 	})
 	if err != nil {
+		cleanup(builddir, nil)
 		return response.SmartError(err)
 	}
 
-	_, err = io.Copy(shared.NewQuotaWriter(post, budget), r.Body)
-	if err != nil {
-		logger.Errorf("Store image POST data to disk: %v", err)
-		cleanup(builddir, post)
-		return response.InternalError(err)
-	}
-
-	// Is this a container request?
-	_, err = post.Seek(0, io.SeekStart)
-	if err != nil {
-		return response.InternalError(err)
-	}
-
-	decoder := json.NewDecoder(post)
+	var post *os.File
+	var upload *imageUploadFiles
 	imageUpload := false
-
 	req := api.ImagesPost{}
-	err = decoder.Decode(&req)
-	if err != nil {
-		if r.Header.Get("Content-Type") == "application/json" {
-			cleanup(builddir, post)
+
+	if isMultipartUpload(r) {
+		// Receive the tarballs of split images straight into their files.
+		upload, err = imgPostReceiveMultipart(r, builddir, budget)
+		if err != nil {
+			logger.Errorf("Store image upload to disk: %v", err)
+			cleanup(builddir, nil)
 			return response.BadRequest(err)
 		}
 
 		imageUpload = true
+	} else {
+		// Store the post data to disk
+		post, err = os.CreateTemp(builddir, "lxd_post_")
+		if err != nil {
+			cleanup(builddir, nil)
+			return response.InternalError(err)
+		}
+
+		_, err = io.Copy(shared.NewQuotaWriter(post, budget), r.Body)
+		if err != nil {
+			logger.Errorf("Store image POST data to disk: %v", err)
+			cleanup(builddir, post)
+			return response.InternalError(err)
+		}
+
+		// Is this a container request?
+		_, err = post.Seek(0, io.SeekStart)
+		if err != nil {
+			cleanup(builddir, post)
+			return response.InternalError(err)
+		}
+
+		decoder := json.NewDecoder(post)
+
+		err = decoder.Decode(&req)
+		if err != nil {
+			if r.Header.Get("Content-Type") == "application/json" {
+				cleanup(builddir, post)
+				return response.BadRequest(err)
+			}
+
+			imageUpload = true
+		}
 	}
 
 	if !imageUpload && req.Source.Mode == "push" {
@@ -1121,7 +1214,7 @@ func imagesPost(d *Daemon, r *http.Request) response.Response {
 
 		if imageUpload {
 			/* Processing image upload */
-			info, err = getImgPostInfo(s, r, builddir, projectName, post, imageMetadata)
+			info, err = getImgPostInfo(s, r, projectName, post, upload, imageMetadata)
 		} else {
 			if req.Source.Type == "image" {
 				/* Processing image copy from remote */
@@ -1706,7 +1799,12 @@ func autoUpdateImagesTask(d *Daemon) (task.Func, task.Schedule) {
 func autoUpdateImages(ctx context.Context, s *state.State) error {
 	imageMap := make(map[string][]dbCluster.Image)
 
-	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	maintenanceAllowed, err := maintenanceWindowChecker(ctx, s)
+	if err != nil {
+		return fmt.Errorf("Failed loading maintenance windows: %w", err)
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
 
 		autoUpdate := true
@@ -1716,6 +1814,11 @@ func autoUpdateImages(ctx context.Context, s *state.State) error {
 		}
 
 		for _, image := range images {
+			// Only update images during the project's maintenance window.
+			if !maintenanceAllowed(image.Project) {
+				continue
+			}
+
 			imageMap[image.Fingerprint] = append(imageMap[image.Fingerprint], image)
 		}
 
@@ -2371,13 +2474,14 @@ func pruneLeftoverImages(s *state.State) {
 	opRun := func(op *operations.Operation) error {
 		// Check if dealing with shared image storage.
 		var storageImages string
+		var sharedImages bool
 		err := s.DB.Node.Transaction(context.TODO(), func(ctx context.Context, tx *db.NodeTx) error {
 			nodeConfig, err := node.ConfigLoad(ctx, tx)
 			if err != nil {
 				return err
 			}
 
-			storageImages = nodeConfig.StorageImagesVolume()
+			storageImages, sharedImages = daemonStorageImagesVolume(s, nodeConfig)
 
 			return nil
 		})
@@ -2385,7 +2489,7 @@ func pruneLeftoverImages(s *state.State) {
 			return err
 		}
 
-		if storageImages != "" {
+		if storageImages != "" && !sharedImages {
 			// Parse the source.
 			poolName, _, err := daemonStorageSplitVolume(storageImages)
 			if err != nil {
@@ -2407,9 +2511,22 @@ func pruneLeftoverImages(s *state.State) {
 			}
 		}
 
-		// Get all images
+		// Get all images, including the ones of the other members when using the volume shared by all members.
 		var images []string
 		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			if sharedImages {
+				dbImages, err := dbCluster.GetImages(ctx, tx.Tx())
+				if err != nil {
+					return err
+				}
+
+				for _, dbImage := range dbImages {
+					images = append(images, dbImage.Fingerprint)
+				}
+
+				return nil
+			}
+
 			var err error
 			images, err = tx.GetLocalImagesFingerprints(ctx)
 			return err
@@ -2426,6 +2543,14 @@ func pruneLeftoverImages(s *state.State) {
 
 		// Check and delete leftovers
 		for _, entry := range entries {
+			// Other members may be downloading images to the shared volume, only consider old entries.
+			if sharedImages {
+				info, err := entry.Info()
+				if err != nil || time.Since(info.ModTime()) < 24*time.Hour {
+					continue
+				}
+			}
+
 			fp := strings.Split(entry.Name(), ".")[0]
 			if !shared.ValueInSlice(fp, images) {
 				err = os.RemoveAll(shared.VarPath("images", entry.Name()))
@@ -2607,6 +2732,16 @@ func pruneExpiredImages(ctx context.Context, s *state.State, op *operations.Oper
 			}
 		}
 
+		// Keep the image files on the shared volume while other members reference the image.
+		referenced, err := imageSharedStoreReferenced(s, fingerprint)
+		if err != nil {
+			return fmt.Errorf("Failed checking references to image %q: %w", fingerprint, err)
+		}
+
+		if referenced {
+			continue
+		}
+
 		// Remove main image file.
 		fname := filepath.Join(s.OS.VarDir, "images", fingerprint)
 		err = os.Remove(fname)
@@ -2803,7 +2938,7 @@ func imageDelete(d *Daemon, r *http.Request) response.Response {
 		}
 
 		// Remove main image file from disk.
-		imageDeleteFromDisk(imgInfo.Fingerprint)
+		imageDeleteFromDisk(s, imgInfo.Fingerprint)
 
 		s.Events.SendLifecycle(projectName, lifecycle.ImageDeleted.Event(imgInfo.Fingerprint, projectName, op.Requestor(), nil))
 
@@ -2821,8 +2956,43 @@ func imageDelete(d *Daemon, r *http.Request) response.Response {
 	return operations.OperationResponse(op)
 }
 
+// imageSharedStoreReferenced returns whether the images are stored on the volume shared by all cluster members and
+// the image is still referenced by some members.
+func imageSharedStoreReferenced(s *state.State, fingerprint string) (bool, error) {
+	_, sharedImages := daemonStorageImagesVolume(s, s.LocalConfig)
+	if !sharedImages {
+		return false, nil
+	}
+
+	var references int
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		references, err = tx.GetImageReferencesCount(ctx, fingerprint)
+
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return references > 0, nil
+}
+
 // Helper to delete an image file from the local images directory.
-func imageDeleteFromDisk(fingerprint string) {
+// The files on the volume shared by all cluster members are only deleted once no member references the image.
+func imageDeleteFromDisk(s *state.State, fingerprint string) {
+	referenced, err := imageSharedStoreReferenced(s, fingerprint)
+	if err != nil {
+		logger.Error("Failed checking references to image", logger.Ctx{"fingerprint": fingerprint, "err": err})
+		return
+	}
+
+	if referenced {
+		return
+	}
+
 	// Remove main image file.
 	fname := shared.VarPath("images", fingerprint)
 	if shared.PathExists(fname) {
@@ -3315,7 +3485,7 @@ func imageAliasesPost(d *Daemon, r *http.Request) response.Response {
 				return err
 			}
 
-			return api.StatusErrorf(http.StatusConflict, "Alias %q already exists", req.Name)
+			return api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "Alias %q already exists", req.Name)
 		}
 
 		imgID, _, err := tx.GetImageByFingerprintPrefix(ctx, req.Target, dbCluster.ImageFilter{Project: &projectName})
@@ -3328,7 +3498,21 @@ func imageAliasesPost(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
-		return err
+		if len(req.Channels) == 0 {
+			return nil
+		}
+
+		channels, err := imageAliasChannelsImageIDs(ctx, tx, projectName, req.Channels)
+		if err != nil {
+			return err
+		}
+
+		aliasID, _, err := tx.GetImageAlias(ctx, projectName, req.Name, true)
+		if err != nil {
+			return err
+		}
+
+		return tx.UpdateImageAliasChannels(ctx, aliasID, channels)
 	})
 	if err != nil {
 		return response.SmartError(err)
@@ -3753,7 +3937,17 @@ func imageAliasPut(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
-		return err
+		// Keep the existing channels when talking to clients which don't know about them.
+		if req.Channels == nil {
+			return nil
+		}
+
+		channels, err := imageAliasChannelsImageIDs(ctx, tx, projectName, req.Channels)
+		if err != nil {
+			return err
+		}
+
+		return tx.UpdateImageAliasChannels(ctx, imgAliasID, channels)
 	})
 	if err != nil {
 		return response.SmartError(err)
@@ -3859,6 +4053,32 @@ func imageAliasPatch(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
+		_, ok = req["channels"]
+		if ok {
+			reqChannels, ok := req["channels"].(map[string]any)
+			if !ok && req["channels"] != nil {
+				return api.StatusErrorf(http.StatusBadRequest, "Channels must be a map of channel names to image fingerprints")
+			}
+
+			targets := make(map[string]string, len(reqChannels))
+			for channel, target := range reqChannels {
+				targets[channel], ok = target.(string)
+				if !ok {
+					return api.StatusErrorf(http.StatusBadRequest, "Target of channel %q must be a string", channel)
+				}
+			}
+
+			channels, err := imageAliasChannelsImageIDs(ctx, tx, projectName, targets)
+			if err != nil {
+				return err
+			}
+
+			err = tx.UpdateImageAliasChannels(ctx, imgAliasID, channels)
+			if err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -3873,9 +4093,11 @@ func imageAliasPatch(d *Daemon, r *http.Request) response.Response {
 
 // swagger:operation POST /1.0/images/aliases/{name} images images_alias_post
 //
-//	Rename the image alias
+//	Rename, promote or roll back the image alias
 //
 //	Renames an existing image alias.
+//	If promote is set, the alias target is instead changed to the target of the given channel.
+//	If rollback is set, the alias target is instead restored to the target it had before its last change.
 //
 //	---
 //	consumes:
@@ -3901,6 +4123,8 @@ func imageAliasPatch(d *Daemon, r *http.Request) response.Response {
 //	    $ref: "#/responses/BadRequest"
 //	  "403":
 //	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func imageAliasPost(d *Daemon, r *http.Request) response.Response {
@@ -3918,6 +4142,10 @@ func imageAliasPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	if req.Promote != "" || req.Rollback {
+		return imageAliasPromote(s, r, projectName, name, req)
+	}
+
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		// This is just to see if the alias name already exists.
 		_, _, err := tx.GetImageAlias(ctx, projectName, req.Name, true)
@@ -3926,7 +4154,7 @@ func imageAliasPost(d *Daemon, r *http.Request) response.Response {
 				return err
 			}
 
-			return api.StatusErrorf(http.StatusConflict, "Alias %q already exists", req.Name)
+			return api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "Alias %q already exists", req.Name)
 		}
 
 		imgAliasID, _, err := tx.GetImageAlias(ctx, projectName, name, true)
@@ -3947,6 +4175,78 @@ func imageAliasPost(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponseLocation(true, nil, lc.Source)
 }
 
+// imageAliasPromote changes the target of an image alias to the target of one of its channels, or back to its
+// previous target.
+func imageAliasPromote(s *state.State, r *http.Request, projectName string, name string, req api.ImageAliasesEntryPost) response.Response {
+	if req.Name != "" {
+		return response.BadRequest(fmt.Errorf("An image alias can't be renamed while being promoted or rolled back"))
+	}
+
+	if req.Promote != "" && req.Rollback {
+		return response.BadRequest(fmt.Errorf("Promote and rollback can't be used together"))
+	}
+
+	var target string
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		imgAliasID, imgAlias, err := tx.GetImageAlias(ctx, projectName, name, true)
+		if err != nil {
+			return err
+		}
+
+		if req.Rollback {
+			if imgAlias.PreviousTarget == "" {
+				return api.StatusErrorf(http.StatusBadRequest, "Image alias %q has no previous target to roll back to", name)
+			}
+
+			target = imgAlias.PreviousTarget
+		} else {
+			target = imgAlias.Channels[req.Promote]
+			if target == "" {
+				return api.StatusErrorf(http.StatusNotFound, "Channel %q not found on image alias %q", req.Promote, name)
+			}
+		}
+
+		imageID, _, err := tx.GetImage(ctx, target, dbCluster.ImageFilter{Project: &projectName})
+		if err != nil {
+			return err
+		}
+
+		return tx.UpdateImageAlias(ctx, imgAliasID, imageID, imgAlias.Description)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r)
+	s.Events.SendLifecycle(projectName, lifecycle.ImageAliasUpdated.Event(name, projectName, requestor, logger.Ctx{"target": target}))
+
+	return response.EmptySyncResponse
+}
+
+// imageAliasChannelsImageIDs validates the given image alias channels and returns the IDs of their target images.
+func imageAliasChannelsImageIDs(ctx context.Context, tx *db.ClusterTx, projectName string, channels map[string]string) (map[string]int, error) {
+	imageIDs := make(map[string]int, len(channels))
+	for channel, target := range channels {
+		err := validate.IsHostname(channel)
+		if err != nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid channel name %q: %w", channel, err)
+		}
+
+		if target == "" {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Channel %q has no target", channel)
+		}
+
+		imageID, _, err := tx.GetImageByFingerprintPrefix(ctx, target, dbCluster.ImageFilter{Project: &projectName})
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading target of channel %q: %w", channel, err)
+		}
+
+		imageIDs[channel] = imageID
+	}
+
+	return imageIDs, nil
+}
+
 // swagger:operation GET /1.0/images/{fingerprint}/export?public images image_export_get_untrusted
 //
 //  Get the raw image file(s)
@@ -4577,6 +4877,12 @@ func autoSyncImages(ctx context.Context, s *state.State) error {
 }
 
 func imageSyncBetweenNodes(ctx context.Context, s *state.State, r *http.Request, project string, fingerprint string) error {
+	// The members using the volume shared by all members get the image from it when needed.
+	if s.GlobalConfig.ImagesVolume() != "" {
+		logger.Debug("Skipping image sync to members as images are stored on a shared volume", logger.Ctx{"fingerprint": fingerprint, "project": project})
+		return nil
+	}
+
 	logger.Info("Syncing image to members started", logger.Ctx{"fingerprint": fingerprint, "project": project})
 	defer logger.Info("Syncing image to members finished", logger.Ctx{"fingerprint": fingerprint, "project": project})
 
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionWithLevel(t *testing.T) {
+	compress, err := compressionWithLevel("zstd", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "zstd", compress)
+
+	compress, err = compressionWithLevel("zstd", 19)
+	assert.NoError(t, err)
+	assert.Equal(t, "zstd -19", compress)
+
+	compress, err = compressionWithLevel("xz -T0", 6)
+	assert.NoError(t, err)
+	assert.Equal(t, "xz -T0 -6", compress)
+
+	_, err = compressionWithLevel("zstd", 20)
+	assert.Error(t, err)
+
+	_, err = compressionWithLevel("squashfs", 1)
+	assert.Error(t, err)
+
+	_, err = compressionWithLevel("none", 1)
+	assert.Error(t, err)
+}
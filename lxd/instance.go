@@ -27,6 +27,7 @@ import (
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/units"
 )
 
 // Helper functions
@@ -67,7 +68,14 @@ func instanceCreateAsEmpty(s *state.State, args db.InstanceArgs) (instance.Insta
 }
 
 // instanceImageTransfer transfers an image from another cluster node.
+// Nothing is transferred if the image is already available on the volume shared by all cluster members.
 func instanceImageTransfer(s *state.State, r *http.Request, projectName string, hash string, nodeAddress string) error {
+	_, sharedImages := daemonStorageImagesVolume(s, s.LocalConfig)
+	if sharedImages && shared.PathExists(filepath.Join(s.OS.VarDir, "images", hash)) {
+		logger.Debug("Image is available on the shared images volume", logger.Ctx{"fingerprint": hash})
+		return nil
+	}
+
 	logger.Debugf("Transferring image %q from node %q", hash, nodeAddress)
 	client, err := cluster.Connect(nodeAddress, s.Endpoints.NetworkCert(), s.ServerCert(), r, false)
 	if err != nil {
@@ -127,8 +135,8 @@ func ensureImageIsLocallyAvailable(s *state.State, r *http.Request, img *api.Ima
 	return nil
 }
 
-// instanceCreateFromImage creates an instance from a rootfs image.
-func instanceCreateFromImage(s *state.State, img *api.Image, args db.InstanceArgs, op *operations.Operation) error {
+// instanceCreateFromImage creates an instance from a rootfs image of the given project.
+func instanceCreateFromImage(s *state.State, img *api.Image, imageProjectName string, args db.InstanceArgs, op *operations.Operation) error {
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -152,6 +160,11 @@ func instanceCreateFromImage(s *state.State, img *api.Image, args db.InstanceArg
 	// Set the BaseImage field (regardless of previous value).
 	args.BaseImage = img.Fingerprint
 
+	err = instanceGrowRootDiskForImage(s, img, &args)
+	if err != nil {
+		return err
+	}
+
 	// Create the instance.
 	inst, instOp, cleanup, err := instance.CreateInternal(s, args, true)
 	if err != nil {
@@ -162,7 +175,7 @@ func instanceCreateFromImage(s *state.State, img *api.Image, args db.InstanceArg
 	defer instOp.Done(nil)
 
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		err = tx.UpdateImageLastUseDate(ctx, args.Project, img.Fingerprint, time.Now().UTC())
+		err = tx.UpdateImageLastUseDate(ctx, imageProjectName, img.Fingerprint, time.Now().UTC())
 		if err != nil {
 			return fmt.Errorf("Error updating image last use date: %w", err)
 		}
@@ -194,6 +207,100 @@ func instanceCreateFromImage(s *state.State, img *api.Image, args db.InstanceArg
 	return nil
 }
 
+// instanceGrowRootDiskForImage grows the root disk of a new instance to fit the image, if the disk size is only
+// inherited from the profiles or the storage pool and is too small for it.
+// The grown size is added as a local root disk device so that it is checked against the project limits.
+func instanceGrowRootDiskForImage(s *state.State, img *api.Image, args *db.InstanceArgs) error {
+	if args.Type != instancetype.VM {
+		return nil
+	}
+
+	imgSize, err := storagePools.ImageRootfsSize(s.OS, img.Fingerprint)
+	if err != nil {
+		return err
+	}
+
+	if imgSize <= 0 {
+		return nil
+	}
+
+	expandedDevices := instancetype.ExpandInstanceDevices(args.Devices.Clone(), args.Profiles)
+	rootDiskName, rootDisk, err := instancetype.GetRootDiskDevice(expandedDevices.CloneNative())
+	if err != nil {
+		// Leave reporting the missing root disk to the instance creation.
+		return nil
+	}
+
+	// An explicitly configured size is never changed.
+	if args.Devices[rootDiskName]["size"] != "" {
+		return nil
+	}
+
+	size := rootDisk["size"]
+	if size == "" {
+		pool, err := storagePools.LoadByName(s, rootDisk["pool"])
+		if err != nil {
+			return err
+		}
+
+		size = pool.Driver().Config()["volume.size"]
+	}
+
+	// The default volume size is already grown to fit the image by the storage layer.
+	if size == "" || size == "0" {
+		return nil
+	}
+
+	sizeBytes, err := units.ParseByteSizeString(size)
+	if err != nil {
+		return err
+	}
+
+	if sizeBytes >= imgSize {
+		return nil
+	}
+
+	// Round up to the next MiB as some storage drivers round volume sizes anyway.
+	newSize := fmt.Sprintf("%dMiB", (imgSize+1024*1024-1)/(1024*1024))
+
+	newRootDisk := make(map[string]string, len(rootDisk)+1)
+	for k, v := range rootDisk {
+		newRootDisk[k] = v
+	}
+
+	newRootDisk["size"] = newSize
+
+	devices := args.Devices.Clone()
+	devices[rootDiskName] = newRootDisk
+
+	profileNames := make([]string, 0, len(args.Profiles))
+	for _, profile := range args.Profiles {
+		profileNames = append(profileNames, profile.Name)
+	}
+
+	req := api.InstancesPost{
+		Name: args.Name,
+		Type: api.InstanceType(args.Type.String()),
+		InstancePut: api.InstancePut{
+			Config:   args.Config,
+			Devices:  devices.CloneNative(),
+			Profiles: profileNames,
+		},
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return project.AllowInstanceCreation(s.GlobalConfig, tx, args.Project, req)
+	})
+	if err != nil {
+		return fmt.Errorf("Image requires a root disk of at least %s but the configured size is %s and it can't be grown: %w", units.GetByteSizeStringIEC(imgSize, 2), size, err)
+	}
+
+	logger.Info("Growing instance root disk to fit image", logger.Ctx{"project": args.Project, "instance": args.Name, "image": img.Fingerprint, "oldSize": size, "newSize": newSize})
+	args.Devices = devices
+
+	return nil
+}
+
 func instanceRebuildFromImage(s *state.State, r *http.Request, inst instance.Instance, img *api.Image, op *operations.Operation) error {
 	// Validate the type of the image matches the type of the instance.
 	imgType, err := instancetype.New(img.Type)
@@ -218,6 +325,46 @@ func instanceRebuildFromImage(s *state.State, r *http.Request, inst instance.Ins
 	return nil
 }
 
+// instanceRebuildSnapshot takes a snapshot of the instance before it is rebuilt if `snapshots.rebuild` is enabled,
+// so that the rebuild can be reverted by restoring it. Returns the name of the snapshot, or an empty string if none
+// was taken.
+func instanceRebuildSnapshot(s *state.State, inst instance.Instance) (string, error) {
+	if shared.IsFalseOrEmpty(inst.ExpandedConfig()["snapshots.rebuild"]) {
+		return "", nil
+	}
+
+	p := inst.Project()
+
+	err := project.AllowSnapshotCreation(&p)
+	if err != nil {
+		return "", fmt.Errorf("Failed taking snapshot before rebuild: %w", err)
+	}
+
+	err = projectCheckDiskUsage(context.TODO(), s, p.Name, 0)
+	if err != nil {
+		return "", fmt.Errorf("Failed taking snapshot before rebuild: %w", err)
+	}
+
+	snapshotName, err := instance.NextSnapshotName(s, inst, "rebuild%d")
+	if err != nil {
+		return "", err
+	}
+
+	expiry, err := shared.GetExpiry(time.Now(), inst.ExpandedConfig()["snapshots.expiry"])
+	if err != nil {
+		return "", err
+	}
+
+	err = inst.Snapshot(snapshotName, expiry, false)
+	if err != nil {
+		return "", fmt.Errorf("Failed taking snapshot before rebuild: %w", err)
+	}
+
+	logger.Info("Took snapshot before instance rebuild", logger.Ctx{"project": p.Name, "instance": inst.Name(), "snapshot": snapshotName, "image": inst.LocalConfig()["volatile.base_image"]})
+
+	return snapshotName, nil
+}
+
 func instanceRebuildFromEmpty(inst instance.Instance, op *operations.Operation) error {
 	err := inst.Rebuild(nil, op) // Rebuild as empty.
 	if err != nil {
@@ -543,8 +690,14 @@ func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.
 		s := d.State()
 		var instances, expiredSnapshotInstances []instance.Instance
 
+		maintenanceAllowed, err := maintenanceWindowChecker(ctx, s)
+		if err != nil {
+			logger.Error("Failed loading maintenance windows", logger.Ctx{"err": err})
+			return
+		}
+
 		// Get list of expired instance snapshots for this local member.
-		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 			expiredSnaps, err := tx.GetLocalExpiredInstanceSnapshots(ctx)
 			if err != nil {
 				return fmt.Errorf("Failed loading expired instance snapshots: %w", err)
@@ -557,6 +710,11 @@ func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.
 				// Enrich expired snapshot list with info from parent (opportunistically loading
 				// the parent info from the DB if not already loaded).
 				for _, snapshot := range expiredSnaps {
+					// Leave expired snapshots in place until the project's maintenance window.
+					if !maintenanceAllowed(snapshot.Project) {
+						continue
+					}
+
 					parentInstanceKey := snapshot.Project + "/" + snapshot.Instance
 					parent, ok := parents[parentInstanceKey]
 					if !ok {
@@ -624,6 +782,13 @@ func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.
 					return nil
 				}
 
+				// Skip the scheduled snapshot if the project is out of disk budget.
+				err = project.CheckDiskUsage(ctx, tx, &p, 0)
+				if err != nil {
+					logger.Warn("Skipping scheduled instance snapshot", logger.Ctx{"project": p.Name, "instance": dbInst.Name, "err": err})
+					return nil
+				}
+
 				inst, err := instance.Load(s, dbInst, p)
 				if err != nil {
 					return fmt.Errorf("Failed loading instance %q (project %q) for snapshot task: %w", dbInst.Name, dbInst.Project, err)
@@ -758,6 +923,27 @@ func getSourceImageFromInstanceSource(ctx context.Context, s *state.State, tx *d
 	return sourceImage, nil
 }
 
+// instanceNameCheckUnique returns an error if instance names must be unique across all projects and the name is
+// already used by an instance in a project other than the given ones.
+func instanceNameCheckUnique(ctx context.Context, s *state.State, tx *db.ClusterTx, name string, projectNames ...string) error {
+	if !s.GlobalConfig.InstancesUniqueNames() {
+		return nil
+	}
+
+	usedProjectNames, err := tx.GetInstanceProjectsWithName(ctx, name)
+	if err != nil {
+		return fmt.Errorf("Failed checking instance name uniqueness: %w", err)
+	}
+
+	for _, usedProjectName := range usedProjectNames {
+		if !shared.ValueInSlice(usedProjectName, projectNames) {
+			return api.StatusErrorf(http.StatusConflict, "Instance name %q is already used in project %q and %q requires instance names to be unique across all projects", name, usedProjectName, "instances.unique_names")
+		}
+	}
+
+	return nil
+}
+
 // instanceOperationLock acquires a lock for operating on an instance and returns the unlock function.
 func instanceOperationLock(ctx context.Context, projectName string, instanceName string) (locking.UnlockFunc, error) {
 	l := logger.AddContext(logger.Ctx{"project": projectName, "instance": instanceName})
@@ -628,31 +628,52 @@ func (d *common) rebuildCommon(inst instance.Instance, img *api.Image, op *opera
 	// Reset relevant volatile keys.
 	delete(instLocalConfig, "volatile.idmap.next")
 	delete(instLocalConfig, "volatile.last_state.idmap")
+	delete(instLocalConfig, "volatile.rebuild.snapshot")
+	delete(instLocalConfig, "volatile.profiles.revisions")
 
 	pool, err := d.getStoragePool()
 	if err != nil {
 		return err
 	}
 
-	err = pool.DeleteInstance(inst, op)
+	snapshots, err := inst.Snapshots()
 	if err != nil {
 		return err
 	}
 
-	// Rebuild as empty if there is no image provided.
-	if img == nil {
-		err = pool.CreateInstance(inst, nil)
+	if img != nil && len(snapshots) > 0 {
+		// Replace the content of the volume in place to keep its snapshots.
+		err = pool.ReplaceInstanceFromImage(inst, img.Fingerprint, op)
 		if err != nil {
 			return err
 		}
 	} else {
-		err = pool.CreateInstanceFromImage(inst, img.Fingerprint, op)
+		err = pool.DeleteInstance(inst, op)
 		if err != nil {
 			return err
 		}
+
+		// Rebuild as empty if there is no image provided.
+		if img == nil {
+			err = pool.CreateInstance(inst, nil)
+			if err != nil {
+				return err
+			}
+		} else {
+			err = pool.CreateInstanceFromImage(inst, img.Fingerprint, op)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// The rebuilt instance is created from the current revision of its profiles.
+		err = d.recordProfileRevisions(ctx, tx)
+		if err != nil {
+			return err
+		}
+
 		err = dbCluster.UpdateInstanceConfig(ctx, tx.Tx(), int64(inst.ID()), instLocalConfig)
 		if err != nil {
 			return err
@@ -675,6 +696,25 @@ func (d *common) rebuildCommon(inst instance.Instance, img *api.Image, op *opera
 	return nil
 }
 
+// recordProfileRevisions records the current revision of the profiles newly applied to the instance in
+// `volatile.profiles.revisions`, keeping the revision recorded for the other profiles.
+func (d *common) recordProfileRevisions(ctx context.Context, tx *db.ClusterTx) error {
+	profileNames := make([]string, 0, len(d.profiles))
+	for _, profile := range d.profiles {
+		profileNames = append(profileNames, profile.Name)
+	}
+
+	revisions, err := instance.UpdateProfileRevisions(ctx, tx, &d.project, profileNames, d.localConfig["volatile.profiles.revisions"])
+	if err != nil {
+		return fmt.Errorf("Failed recording profile revisions: %w", err)
+	}
+
+	d.localConfig["volatile.profiles.revisions"] = revisions
+	d.expandedConfig["volatile.profiles.revisions"] = revisions
+
+	return nil
+}
+
 // runHooks executes the callback functions returned from a function.
 func (d *common) runHooks(hooks []func() error) error {
 	// Run any post start hooks.
@@ -1093,7 +1133,7 @@ func (d *common) canMigrate(inst instance.Instance) (migrate bool, live bool) {
 		return true, true
 	}
 
-	if val == "stop" {
+	if val == "stop" || val == "stateful-stop" {
 		return false, false
 	}
 
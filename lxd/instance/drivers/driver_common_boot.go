@@ -0,0 +1,92 @@
+package drivers
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// bootTimer records how long each phase of an instance start takes.
+type bootTimer struct {
+	started time.Time
+	last    time.Time
+	boot    api.InstanceStateBoot
+}
+
+// newBootTimer returns a bootTimer for a start beginning now.
+func newBootTimer() *bootTimer {
+	now := time.Now()
+
+	return &bootTimer{
+		started: now,
+		last:    now,
+		boot: api.InstanceStateBoot{
+			StartedAt: now.UTC(),
+			Phases:    []api.InstanceStateBootPhase{},
+		},
+	}
+}
+
+// phase records that the named phase of the start has completed.
+func (t *bootTimer) phase(name string) {
+	now := time.Now()
+
+	t.boot.Phases = append(t.boot.Phases, api.InstanceStateBootPhase{
+		Name:     name,
+		Duration: now.Sub(t.last).Nanoseconds(),
+	})
+
+	t.boot.Duration = now.Sub(t.started).Nanoseconds()
+	t.last = now
+}
+
+// bootPath returns the path of the file recording how long the last start of the instance took.
+func (d *common) bootPath() string {
+	return filepath.Join(d.LogPath(), "boot.json")
+}
+
+// bootLoad returns how long the phases of the last start of the instance took, or nil if not recorded.
+func (d *common) bootLoad() (*api.InstanceStateBoot, error) {
+	data, err := os.ReadFile(d.bootPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	boot := &api.InstanceStateBoot{}
+	err = json.Unmarshal(data, boot)
+	if err != nil {
+		return nil, err
+	}
+
+	return boot, nil
+}
+
+// bootSave records how long the phases of the last start of the instance took.
+func (d *common) bootSave(boot *api.InstanceStateBoot) error {
+	data, err := json.Marshal(boot)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(d.bootPath(), data, 0600)
+}
+
+// bootState returns the boot section of the instance state, logging rather than failing if it can't be loaded.
+func (d *common) bootState() *api.InstanceStateBoot {
+	boot, err := d.bootLoad()
+	if err != nil {
+		d.logger.Warn("Failed loading boot timing", logger.Ctx{"err": err})
+		return nil
+	}
+
+	return boot
+}
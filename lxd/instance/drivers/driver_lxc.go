@@ -56,6 +56,7 @@ import (
 	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/resources"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/rsync"
 	"github.com/canonical/lxd/lxd/seccomp"
@@ -67,6 +68,7 @@ import (
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/netutils"
 	"github.com/canonical/lxd/shared/osarch"
@@ -254,12 +256,18 @@ func lxcCreate(s *state.State, args db.InstanceArgs, p api.Project) (instance.In
 		return nil, nil, fmt.Errorf("Storage pool does not support instance type")
 	}
 
+	// Volumes on volatile pools don't survive the pool being unmounted, only allow ephemeral instances there.
+	if !d.isSnapshot && !d.ephemeral && d.storagePool.Driver().Info().Volatile {
+		return nil, nil, fmt.Errorf("Only ephemeral instances can be created on storage pool %q", d.storagePool.Name())
+	}
+
 	// Setup initial idmap config
 	var idmap *idmap.IdmapSet
 	base := int64(0)
 	if !d.IsPrivileged() {
 		idmap, base, err = findIdmap(
 			s,
+			args.Project,
 			args.Name,
 			d.expandedConfig["security.idmap.isolated"],
 			d.expandedConfig["security.idmap.base"],
@@ -465,7 +473,120 @@ func idmapSize(state *state.State, isolatedStr string, size string) (int64, erro
 
 var idmapLock sync.Mutex
 
-func findIdmap(state *state.State, cName string, isolatedStr string, configBase string, configSize string, rawIdmap string) (*idmap.IdmapSet, int64, error) {
+// idmapFindFree returns the first offset between start and end that can hold size IDs without
+// overlapping any of the given entries.
+func idmapFindFree(entries idmap.ByHostid, start int64, end int64, size int64) (int64, error) {
+	sort.Sort(entries)
+
+	offset := start
+	for _, entry := range entries {
+		// Entry ends before the candidate range.
+		if entry.Hostid+entry.Maprange <= offset {
+			continue
+		}
+
+		// Candidate range fits before the entry.
+		if offset+size <= entry.Hostid {
+			break
+		}
+
+		offset = entry.Hostid + entry.Maprange
+	}
+
+	if offset+size > end {
+		return 0, fmt.Errorf("Not enough uid/gid available for the container")
+	}
+
+	return offset, nil
+}
+
+// projectIdmapRanges returns the ranges of host IDs reserved by projects for their isolated containers,
+// allocating the range of the given project if it sets `idmap.size` and doesn't have one yet.
+// The allocated range avoids the ranges of the other projects and of the given host ID entries.
+// Must be called with idmapLock held.
+func projectIdmapRanges(s *state.State, projectName string, entries idmap.ByHostid) (map[string]*idmap.IdmapEntry, error) {
+	ranges := map[string]*idmap.IdmapEntry{}
+	allocated := false
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProjects, err := cluster.GetProjects(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		var pendingProject *api.Project
+		var pendingSize int64
+		for _, dbProject := range dbProjects {
+			p, err := dbProject.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			if p.Config["idmap.size"] == "" {
+				continue
+			}
+
+			size, err := strconv.ParseInt(p.Config["idmap.size"], 10, 64)
+			if err != nil {
+				return fmt.Errorf("Invalid idmap.size of project %q: %w", p.Name, err)
+			}
+
+			if p.Config["volatile.idmap.base"] == "" {
+				if p.Name == projectName {
+					pendingProject = p
+					pendingSize = size
+				}
+
+				continue
+			}
+
+			base, err := strconv.ParseInt(p.Config["volatile.idmap.base"], 10, 64)
+			if err != nil {
+				return fmt.Errorf("Invalid volatile.idmap.base of project %q: %w", p.Name, err)
+			}
+
+			ranges[p.Name] = &idmap.IdmapEntry{Hostid: base, Maprange: size}
+		}
+
+		if pendingProject == nil {
+			return nil
+		}
+
+		// Allocate a range for the project outside of any other reserved range.
+		reserved := append(idmap.ByHostid{}, entries...)
+		for _, entry := range ranges {
+			reserved = append(reserved, entry)
+		}
+
+		hostMap := s.OS.IdmapSet.Idmap[0]
+		base, err := idmapFindFree(reserved, hostMap.Hostid+65536, hostMap.Hostid+hostMap.Maprange, pendingSize)
+		if err != nil {
+			return fmt.Errorf("Failed allocating idmap range of project %q: %w", pendingProject.Name, err)
+		}
+
+		pendingProject.Config["volatile.idmap.base"] = fmt.Sprintf("%d", base)
+		err = cluster.UpdateProject(ctx, tx.Tx(), pendingProject.Name, pendingProject.Writable())
+		if err != nil {
+			return fmt.Errorf("Failed recording idmap range of project %q: %w", pendingProject.Name, err)
+		}
+
+		ranges[pendingProject.Name] = &idmap.IdmapEntry{Hostid: base, Maprange: pendingSize}
+		allocated = true
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if allocated {
+		s.DB.Cluster.EntityCache().Invalidate(entity.TypeProject, projectName)
+	}
+
+	return ranges, nil
+}
+
+func findIdmap(state *state.State, cProject string, cName string, isolatedStr string, configBase string, configSize string, rawIdmap string) (*idmap.IdmapSet, int64, error) {
 	isolated := false
 	if shared.IsTrue(isolatedStr) {
 		isolated = true
@@ -511,20 +632,6 @@ func findIdmap(state *state.State, cName string, isolatedStr string, configBase
 		return set, nil
 	}
 
-	if configBase != "" {
-		offset, err := strconv.ParseInt(configBase, 10, 64)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		set, err := mkIdmap(offset, size)
-		if err != nil && err == idmap.ErrHostIdIsSubId {
-			return nil, 0, err
-		}
-
-		return set, offset, nil
-	}
-
 	idmapLock.Lock()
 	defer idmapLock.Unlock()
 
@@ -533,18 +640,16 @@ func findIdmap(state *state.State, cName string, isolatedStr string, configBase
 		return nil, 0, err
 	}
 
-	offset := state.OS.IdmapSet.Idmap[0].Hostid + 65536
-
-	mapentries := idmap.ByHostid{}
+	// Ranges used by the isolated containers of this server, split on whether they belong to the project.
+	projectEntries := idmap.ByHostid{}
+	otherEntries := idmap.ByHostid{}
 	for _, container := range cts {
 		if container.Type() != instancetype.Container {
 			continue
 		}
 
-		name := container.Name()
-
 		/* Don't change our map Just Because. */
-		if name == cName {
+		if container.Project().Name == cProject && container.Name() == cName {
 			continue
 		}
 
@@ -569,45 +674,45 @@ func findIdmap(state *state.State, cName string, isolatedStr string, configBase
 			return nil, 0, err
 		}
 
-		mapentries = append(mapentries, &idmap.IdmapEntry{Hostid: int64(cBase), Maprange: cSize})
+		entry := &idmap.IdmapEntry{Hostid: int64(cBase), Maprange: cSize}
+		if container.Project().Name == cProject {
+			projectEntries = append(projectEntries, entry)
+		} else {
+			otherEntries = append(otherEntries, entry)
+		}
 	}
 
-	sort.Sort(mapentries)
-
-	for i := range mapentries {
-		if i == 0 {
-			if mapentries[0].Hostid < offset+size {
-				offset = mapentries[0].Hostid + mapentries[0].Maprange
-				continue
-			}
+	projectRanges, err := projectIdmapRanges(state, cProject, otherEntries)
+	if err != nil {
+		return nil, 0, err
+	}
 
-			set, err := mkIdmap(offset, size)
-			if err != nil && err == idmap.ErrHostIdIsSubId {
-				return nil, 0, err
-			}
+	// Containers of a project with a reserved range only get IDs from that range, while the others
+	// avoid all the reserved ranges.
+	start := state.OS.IdmapSet.Idmap[0].Hostid + 65536
+	end := state.OS.IdmapSet.Idmap[0].Hostid + state.OS.IdmapSet.Idmap[0].Maprange
 
-			return set, offset, nil
+	mapentries := append(projectEntries, otherEntries...)
+	projectRange := projectRanges[cProject]
+	if projectRange != nil {
+		start = projectRange.Hostid
+		end = projectRange.Hostid + projectRange.Maprange
+	} else {
+		for _, entry := range projectRanges {
+			mapentries = append(mapentries, entry)
 		}
+	}
 
-		if mapentries[i-1].Hostid+mapentries[i-1].Maprange > offset {
-			offset = mapentries[i-1].Hostid + mapentries[i-1].Maprange
-			continue
+	if configBase != "" {
+		offset, err := strconv.ParseInt(configBase, 10, 64)
+		if err != nil {
+			return nil, 0, err
 		}
 
-		offset = mapentries[i-1].Hostid + mapentries[i-1].Maprange
-		if offset+size < mapentries[i].Hostid {
-			set, err := mkIdmap(offset, size)
-			if err != nil && err == idmap.ErrHostIdIsSubId {
-				return nil, 0, err
-			}
-
-			return set, offset, nil
+		if projectRange != nil && (offset < start || offset+size > end) {
+			return nil, 0, fmt.Errorf("The idmap base %d is outside of the range reserved for project %q", offset, cProject)
 		}
 
-		offset = mapentries[i].Hostid + mapentries[i].Maprange
-	}
-
-	if offset+size < state.OS.IdmapSet.Idmap[0].Hostid+state.OS.IdmapSet.Idmap[0].Maprange {
 		set, err := mkIdmap(offset, size)
 		if err != nil && err == idmap.ErrHostIdIsSubId {
 			return nil, 0, err
@@ -616,7 +721,17 @@ func findIdmap(state *state.State, cName string, isolatedStr string, configBase
 		return set, offset, nil
 	}
 
-	return nil, 0, fmt.Errorf("Not enough uid/gid available for the container")
+	offset, err := idmapFindFree(mapentries, start, end, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	set, err := mkIdmap(offset, size)
+	if err != nil && err == idmap.ErrHostIdIsSubId {
+		return nil, 0, err
+	}
+
+	return set, offset, nil
 }
 
 func (d *lxc) init() error {
@@ -1295,10 +1410,32 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	// Setup inotify limits, scoped to the user namespace of the container.
+	if !d.IsPrivileged() {
+		inotifyLimits := map[string]string{
+			"limits.inotify.instances": "lxc.sysctl.user.max_inotify_instances",
+			"limits.inotify.watches":   "lxc.sysctl.user.max_inotify_watches",
+		}
+
+		for key, lxcKey := range inotifyLimits {
+			value := d.expandedConfig[key]
+			if value == "" {
+				continue
+			}
+
+			err = lxcSetConfigItem(cc, lxcKey, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Setup sysctls
 	for k, v := range d.expandedConfig {
 		// lxdmeta:generate(entities=instance; group=miscellaneous; key=linux.sysctl.*)
+		// Only sysctls that are scoped to the container's namespaces can be set, for example `net.*`, `kernel.shm*`, `kernel.msg*`, `kernel.sem` or `fs.mqueue.*`.
 		//
+		// If {config:option}`instance-miscellaneous:linux.kernel_modules.load` is set to `auto`, the kernel modules that provide the sysctl are loaded when the container starts.
 		// ---
 		//  type: string
 		//  liveupdate: no
@@ -1314,6 +1451,32 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	// Setup time namespace
+	timeOffsets := map[string]string{
+		"linux.time.offset.boottime":  "lxc.time.offset.boot",
+		"linux.time.offset.monotonic": "lxc.time.offset.monotonic",
+	}
+
+	for key, lxcKey := range timeOffsets {
+		if d.expandedConfig[key] == "" {
+			continue
+		}
+
+		if !d.state.OS.ContainerTimeNamespace {
+			return nil, fmt.Errorf("Time namespaces aren't supported on this system, can't use %q", key)
+		}
+
+		offset, err := instancetype.ParseTimeOffset(d.expandedConfig[key])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %q: %w", key, err)
+		}
+
+		err = lxcSetConfigItem(cc, lxcKey, fmt.Sprintf("%ds", int64(offset.Seconds())))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Setup shmounts
 	if d.state.OS.LXCFeatures["mount_injection_file"] {
 		err = lxcSetConfigItem(cc, "lxc.mount.auto", fmt.Sprintf("shmounts:%s:/dev/.lxd-mounts", d.ShmountsPath()))
@@ -1388,7 +1551,8 @@ func (d *lxc) IdmappedStorage(path string, fstype string) idmap.IdmapStorageType
 	return mode
 }
 
-func (d *lxc) devlxdEventSend(eventType string, eventMessage map[string]any) error {
+// DevlxdEventSend sends an event to the listeners of the devlxd API of the instance.
+func (d *lxc) DevlxdEventSend(eventType string, eventMessage map[string]any) error {
 	event := shared.Jmap{}
 	event["type"] = eventType
 	event["timestamp"] = time.Now()
@@ -1914,8 +2078,43 @@ func (d *lxc) handleIdmappedStorage() (idmap.IdmapStorageType, *idmap.IdmapSet,
 	return idmapType, nextIdmap, nil
 }
 
+// loadKernelModules loads the kernel modules required by the container and returns their names.
+// With the auto policy, this includes the modules providing the configured sysctls, which are then
+// checked to be available on the host.
+func (d *lxc) loadKernelModules() ([]string, error) {
+	policy := d.expandedConfig["linux.kernel_modules.load"]
+	if policy == "ondemand" {
+		return nil, nil
+	}
+
+	modules := instancetype.KernelModules(d.expandedConfig)
+	for _, module := range modules {
+		err := util.LoadModule(module)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load kernel module %q: %w", module, err)
+		}
+	}
+
+	if policy != "auto" {
+		return modules, nil
+	}
+
+	for key := range d.expandedConfig {
+		name, found := strings.CutPrefix(key, "linux.sysctl.")
+		if !found || len(instancetype.SysctlModules(name)) == 0 {
+			continue
+		}
+
+		if !shared.PathExists(instancetype.SysctlPath(name)) {
+			return nil, fmt.Errorf("Sysctl %q isn't available on the host after loading kernel modules %q", name, strings.Join(instancetype.SysctlModules(name), ","))
+		}
+	}
+
+	return modules, nil
+}
+
 // Start functions.
-func (d *lxc) startCommon() (string, []func() error, error) {
+func (d *lxc) startCommon(boot *bootTimer) (string, []func() error, error) {
 	postStartHooks := []func() error{}
 
 	revert := revert.New()
@@ -1927,22 +2126,17 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		return "", nil, fmt.Errorf("Load go-lxc struct: %w", err)
 	}
 
+	boot.phase("cgroup")
+
 	// Ensure cgroup v1 configuration is set appropriately with the image using systemd
 	if d.localConfig["image.requirements.cgroup"] == "v1" && !shared.PathExists("/sys/fs/cgroup/systemd") {
 		return "", nil, fmt.Errorf("The image used by this instance requires a CGroupV1 host system")
 	}
 
 	// Load any required kernel modules
-	kernelModules := d.expandedConfig["linux.kernel_modules"]
-	kernelModulesLoadPolicy := d.expandedConfig["linux.kernel_modules.load"]
-	if kernelModulesLoadPolicy != "ondemand" && kernelModules != "" {
-		for _, module := range strings.Split(kernelModules, ",") {
-			module = strings.TrimPrefix(module, " ")
-			err := util.LoadModule(module)
-			if err != nil {
-				return "", nil, fmt.Errorf("Failed to load kernel module '%s': %w", module, err)
-			}
-		}
+	kernelModules, err := d.loadKernelModules()
+	if err != nil {
+		return "", nil, err
 	}
 
 	// Rotate the log file.
@@ -1966,6 +2160,8 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		return "", nil, err
 	}
 
+	boot.phase("mount")
+
 	// Handle post hooks.
 	postStartHooks = append(postStartHooks, func() error {
 		for _, hook := range mountInfo.PostHooks {
@@ -2044,6 +2240,11 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		volatileSet["volatile.uuid.generation"] = genUUID
 	}
 
+	// Record the kernel modules required by the container.
+	if d.localConfig["volatile.kernel_modules"] != strings.Join(kernelModules, ",") {
+		volatileSet["volatile.kernel_modules"] = strings.Join(kernelModules, ",")
+	}
+
 	// Apply any volatile changes that need to be made.
 	err = d.VolatileSet(volatileSet)
 	if err != nil {
@@ -2227,6 +2428,8 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		}
 	}
 
+	boot.phase("devices")
+
 	// Override NVIDIA_VISIBLE_DEVICES if we have devices that need it.
 	if len(nvidiaDevices) > 0 {
 		err = lxcSetConfigItem(cc, "lxc.environment", fmt.Sprintf("NVIDIA_VISIBLE_DEVICES=%s", strings.Join(nvidiaDevices, ",")))
@@ -2297,6 +2500,8 @@ func (d *lxc) startCommon() (string, []func() error, error) {
 		return "", nil, err
 	}
 
+	boot.phase("config")
+
 	revert.Success()
 	return configPath, postStartHooks, nil
 }
@@ -2438,7 +2643,8 @@ func (d *lxc) Start(stateful bool) error {
 	}
 
 	// Run the shared start code.
-	configPath, postStartHooks, err := d.startCommon()
+	boot := newBootTimer()
+	configPath, postStartHooks, err := d.startCommon(boot)
 	if err != nil {
 		op.Done(err)
 		return err
@@ -2499,6 +2705,13 @@ func (d *lxc) Start(stateful bool) error {
 		return err
 	}
 
+	boot.phase("start")
+
+	err = d.bootSave(&boot.boot)
+	if err != nil {
+		d.logger.Warn("Failed saving boot timing", logger.Ctx{"err": err})
+	}
+
 	if op.Action() == "start" {
 		d.logger.Info("Started instance", ctxMap)
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStarted.Event(d, nil))
@@ -2924,6 +3137,7 @@ func (d *lxc) onStop(args map[string]string) error {
 	err = d.VolatileSet(map[string]string{
 		"volatile.last_state.power": instance.PowerStateStopped,
 		"volatile.last_state.ready": "false",
+		"volatile.kernel_modules":   "",
 	})
 	if err != nil {
 		// Don't return an error here as we still want to cleanup the instance even if DB not available.
@@ -3342,9 +3556,11 @@ func (d *lxc) renderState(statusCode api.StatusCode, hostInterfaces []net.Interf
 		status.Network = d.networkState(hostInterfaces)
 		status.Pid = int64(pid)
 		status.Processes = processesState
+		status.KernelResources = d.kernelResourcesState(pid)
 	}
 
 	status.Disk = d.diskState()
+	status.Boot = d.bootState()
 
 	d.release()
 
@@ -4305,6 +4521,18 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 		if newErr != nil {
 			return fmt.Errorf("Invalid root disk device: %w", newErr)
 		}
+
+		// Ensure instances on volatile pools stay ephemeral.
+		if !d.ephemeral {
+			pool, err := d.getStoragePool()
+			if err != nil {
+				return err
+			}
+
+			if pool.Driver().Info().Volatile {
+				return fmt.Errorf("Instances on storage pool %q must be ephemeral", pool.Name())
+			}
+		}
 	}
 
 	// Run through initLXC to catch anything we missed
@@ -4351,6 +4579,7 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 			// update the idmap
 			idmap, base, err = findIdmap(
 				d.state,
+				d.Project().Name,
 				d.Name(),
 				d.expandedConfig["security.idmap.isolated"],
 				d.expandedConfig["security.idmap.base"],
@@ -4462,16 +4691,14 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 					}
 				}
 			} else if key == "linux.kernel_modules" && value != "" {
-				if d.expandedConfig["linux.kernel_modules.load"] == "ondemand" {
-					continue
+				kernelModules, err := d.loadKernelModules()
+				if err != nil {
+					return err
 				}
 
-				for _, module := range strings.Split(value, ",") {
-					module = strings.TrimPrefix(module, " ")
-					err := util.LoadModule(module)
-					if err != nil {
-						return fmt.Errorf("Failed to load kernel module '%s': %w", module, err)
-					}
+				err = d.VolatileSet(map[string]string{"volatile.kernel_modules": strings.Join(kernelModules, ",")})
+				if err != nil {
+					return err
 				}
 			} else if key == "limits.disk.priority" {
 				if !d.state.OS.CGInfo.Supports(cgroup.Blkio, cg) {
@@ -4768,6 +4995,11 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 			return err
 		}
 
+		err = d.recordProfileRevisions(ctx, tx)
+		if err != nil {
+			return err
+		}
+
 		err = cluster.UpdateInstanceConfig(ctx, tx.Tx(), int64(object.ID), d.localConfig)
 		if err != nil {
 			return err
@@ -4813,7 +5045,7 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 				"value":     d.expandedConfig[key],
 			}
 
-			err = d.devlxdEventSend("config", msg)
+			err = d.DevlxdEventSend("config", msg)
 			if err != nil {
 				return err
 			}
@@ -4821,7 +5053,7 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 
 		// Device events.
 		for _, event := range devlxdEvents {
-			err = d.devlxdEventSend("device", event)
+			err = d.DevlxdEventSend("device", event)
 			if err != nil {
 				return err
 			}
@@ -4850,7 +5082,7 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 }
 
 // Export backs up the instance.
-func (d *lxc) Export(w io.Writer, properties map[string]string, expiration time.Time) (api.ImageMetadata, error) {
+func (d *lxc) Export(w io.Writer, properties map[string]string, expiration time.Time, reproducible bool) (api.ImageMetadata, error) {
 	ctxMap := logger.Ctx{
 		"created":   d.creationDate,
 		"ephemeral": d.ephemeral,
@@ -4882,6 +5114,7 @@ func (d *lxc) Export(w io.Writer, properties map[string]string, expiration time.
 
 	// Create the tarball.
 	tarWriter := instancewriter.NewInstanceTarWriter(w, idmap)
+	tarWriter.SetReproducible(reproducible)
 
 	// Keep track of the first path we saw for each path with nlink>1.
 	cDir := d.Path()
@@ -4942,8 +5175,13 @@ func (d *lxc) Export(w io.Writer, properties map[string]string, expiration time.
 
 		// Fill in the metadata.
 		meta.Architecture = arch
-		meta.CreationDate = time.Now().UTC().Unix()
 		meta.Properties = properties
+
+		// Reproducible images don't record when they were created.
+		if !reproducible {
+			meta.CreationDate = time.Now().UTC().Unix()
+		}
+
 		if !expiration.IsZero() {
 			meta.ExpiryDate = expiration.UTC().Unix()
 		}
@@ -5216,6 +5454,9 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 		return fmt.Errorf("No source migration types available")
 	}
 
+	// Only offer the requested rsync compression.
+	poolMigrationTypes = migration.SetRsyncCompression(poolMigrationTypes, args.CompressionAlgorithm)
+
 	// Convert the pool's migration type options to an offer header to target.
 	// Populate the Fs, ZfsFeatures and RsyncFeatures fields.
 	offerHeader := migration.TypesToHeader(poolMigrationTypes...)
@@ -5224,6 +5465,11 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 	indexHeaderVersion := migration.IndexHeaderVersion
 	offerHeader.IndexHeaderVersion = &indexHeaderVersion
 
+	// Offer to send the block checksums if requested.
+	if args.BlockChecksums {
+		offerHeader.BlockChecksums = proto.Bool(true)
+	}
+
 	// Add CRIU and predump info to source header.
 	maxDumpIterations := 0
 	if args.Live {
@@ -5306,6 +5552,8 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 		TrackProgress:      true,
 		Refresh:            respHeader.GetRefresh(),
 		AllowInconsistent:  args.AllowInconsistent,
+		CompressionLevel:   args.CompressionLevel,
+		BlockChecksums:     respHeader.GetBlockChecksums(),
 		VolumeOnly:         !args.Snapshots,
 		Info:               &migration.Info{Config: srcConfig},
 		ClusterMove:        args.ClusterMoveSourceName != "",
@@ -5864,6 +6112,11 @@ func (d *lxc) MigrateReceive(args instance.MigrateReceiveArgs) error {
 	respHeader.Snapshots = offerHeader.Snapshots
 	respHeader.Refresh = &args.Refresh
 
+	// Agree to verify the block checksums if offered by the source.
+	if offerHeader.GetBlockChecksums() {
+		respHeader.BlockChecksums = proto.Bool(true)
+	}
+
 	// Add CRIU info to response.
 	respHeader.Criu = criuType
 
@@ -6067,6 +6320,7 @@ func (d *lxc) MigrateReceive(args instance.MigrateReceiveArgs) error {
 			VolumeSize:            offerHeader.GetVolumeSize(), // Block size setting override.
 			VolumeOnly:            !args.Snapshots,
 			ClusterMoveSourceName: args.ClusterMoveSourceName,
+			BlockChecksums:        offerHeader.GetBlockChecksums(),
 		}
 
 		// At this point we have already figured out the parent container's root
@@ -6374,7 +6628,7 @@ func (d *lxc) migrate(args *instance.CriuMigrationArgs) error {
 		}
 
 		// Run the shared start code.
-		configPath, postStartHooks, err := d.startCommon()
+		configPath, postStartHooks, err := d.startCommon(newBootTimer())
 		if err != nil {
 			if args.Op != nil {
 				args.Op.Done(err)
@@ -7409,6 +7663,11 @@ func (d *lxc) processesState(pid int) (int64, error) {
 		return value, nil
 	}
 
+	return int64(len(processesList(pid))), nil
+}
+
+// processesList returns the PIDs of the given process and all of its descendants.
+func processesList(pid int) []int64 {
 	pids := []int64{int64(pid)}
 
 	// Go through the pid list, adding new pids at the end so we go through them all
@@ -7429,7 +7688,92 @@ func (d *lxc) processesState(pid int) (int64, error) {
 		}
 	}
 
-	return int64(len(pids)), nil
+	return pids
+}
+
+// kernelResourcesState returns the usage of the kernel resources by the processes of the instance.
+func (d *lxc) kernelResourcesState(pid int) *api.InstanceStateKernelResources {
+	if pid < 1 {
+		return nil
+	}
+
+	usage := &api.InstanceStateKernelResources{}
+
+	for _, p := range processesList(pid) {
+		fdPath := fmt.Sprintf("/proc/%d/fd", p)
+		entries, err := os.ReadDir(fdPath)
+		if err != nil {
+			// The process terminated in the meantime.
+			continue
+		}
+
+		usage.OpenFiles += int64(len(entries))
+
+		for _, entry := range entries {
+			target, err := os.Readlink(filepath.Join(fdPath, entry.Name()))
+			if err != nil || target != "anon_inode:inotify" {
+				continue
+			}
+
+			usage.InotifyInstances++
+
+			// Each watch of the inotify instance is listed on its own line.
+			content, err := os.ReadFile(fmt.Sprintf("/proc/%d/fdinfo/%s", p, entry.Name()))
+			if err != nil {
+				continue
+			}
+
+			for _, line := range strings.Split(string(content), "\n") {
+				if strings.HasPrefix(line, "inotify wd:") {
+					usage.InotifyWatches++
+				}
+			}
+		}
+	}
+
+	// Keys are accounted per user, so add up the keys of all the users mapped into the instance.
+	idmapset, err := d.CurrentIdmap()
+	if err != nil || idmapset == nil {
+		return usage
+	}
+
+	content, err := os.ReadFile("/proc/key-users")
+	if err != nil {
+		return usage
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		// Format: "<uid>: <usage> <nkeys>/<nikeys> <qnkeys>/<maxkeys> <qnbytes>/<maxbytes>".
+		fields := strings.Fields(line)
+		if len(fields) != 5 {
+			continue
+		}
+
+		uid, err := strconv.ParseInt(strings.TrimSuffix(fields[0], ":"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		nsUID, _ := idmapset.ShiftFromNs(uid, 0)
+		if nsUID < 0 {
+			continue
+		}
+
+		keys, _, _ := strings.Cut(fields[3], "/")
+		keysBytes, _, _ := strings.Cut(fields[4], "/")
+
+		value, err := strconv.ParseInt(keys, 10, 64)
+		if err == nil {
+			usage.Keys += value
+		}
+
+		value, err = strconv.ParseInt(keysBytes, 10, 64)
+		if err == nil {
+			usage.KeysBytes += value
+		}
+	}
+
+	return usage
 }
 
 // getStorageType returns the storage type of the instance's storage pool.
@@ -8108,6 +8452,74 @@ func (d *lxc) NextIdmap() (*idmap.IdmapSet, error) {
 	return idmap.JSONUnmarshal(jsonIdmap)
 }
 
+// Remap allocates a new idmap for the stopped container and shifts the ownership of its filesystem to it.
+func (d *lxc) Remap() error {
+	if d.IsPrivileged() {
+		return fmt.Errorf("Privileged containers don't use an idmap")
+	}
+
+	if d.IsRunning() {
+		return fmt.Errorf("The instance must be stopped to be remapped")
+	}
+
+	// Prevent concurrent operations on the instance.
+	op, err := operationlock.Create(d.Project().Name, d.Name(), operationlock.ActionUpdate, false, false)
+	if err != nil {
+		return fmt.Errorf("Failed to create instance remap operation: %w", err)
+	}
+
+	defer op.Done(nil)
+
+	// Stop forkfile as otherwise it will hold the root volume open.
+	d.stopForkfile(false)
+
+	d.updateProgress("Allocating new idmap")
+
+	nextIdmap, base, err := findIdmap(
+		d.state,
+		d.Project().Name,
+		d.Name(),
+		d.expandedConfig["security.idmap.isolated"],
+		d.expandedConfig["security.idmap.base"],
+		d.expandedConfig["security.idmap.size"],
+		d.expandedConfig["raw.idmap"],
+	)
+	if err != nil {
+		return fmt.Errorf("Failed allocating idmap: %w", err)
+	}
+
+	jsonIdmap, err := idmap.JSONMarshal(nextIdmap)
+	if err != nil {
+		return err
+	}
+
+	err = d.VolatileSet(map[string]string{
+		"volatile.idmap.next": jsonIdmap,
+		"volatile.idmap.base": fmt.Sprintf("%v", base),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Invalidate idmap cache.
+	d.idmapset = nil
+
+	// Shift the on-disk ownership now rather than on next start.
+	_, err = d.mount()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = d.unmount() }()
+
+	_, _, err = d.handleIdmappedStorage()
+	if err != nil {
+		return fmt.Errorf("Failed remapping container filesystem: %w", err)
+	}
+
+	return nil
+}
+
 // statusCode returns instance status code.
 func (d *lxc) statusCode() api.StatusCode {
 	// Shortcut to avoid spamming liblxc during ongoing operations.
@@ -8432,6 +8844,14 @@ func (d *lxc) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, error
 		out.AddSamples(metrics.NetworkTransmitDropTotal, metrics.Sample{Value: float64(state.Counters.PacketsDroppedOutbound), Labels: labels})
 	}
 
+	// Get GPU stats
+	gpuStats, err := d.getGPUStats()
+	if err != nil {
+		d.logger.Warn("Failed to get GPU stats", logger.Ctx{"err": err})
+	} else {
+		out.Merge(gpuStats)
+	}
+
 	// Get number of processes
 	pids, err := d.processesState(d.InitPID())
 	if err != nil {
@@ -8443,6 +8863,49 @@ func (d *lxc) Metrics(hostInterfaces []net.Interface) (*metrics.MetricSet, error
 	return out, nil
 }
 
+// getGPUStats returns the usage of the host GPUs passed through to the container by physical GPU devices.
+func (d *lxc) getGPUStats() (*metrics.MetricSet, error) {
+	out := metrics.NewMetricSet(nil)
+
+	var usage map[string]resources.GPUUsage
+
+	for _, dev := range d.expandedDevices.Sorted() {
+		if dev.Config["type"] != "gpu" || !shared.ValueInSlice(dev.Config["gputype"], []string{"", "physical"}) {
+			continue
+		}
+
+		// Only query the GPUs once the container is known to use some.
+		if usage == nil {
+			var err error
+
+			usage, err = resources.GetGPUUsage()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		pciAddresses, err := device.GPUPhysicalPCIAddresses(dev.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pciAddress := range pciAddresses {
+			stats, ok := usage[pciAddress]
+			if !ok {
+				continue
+			}
+
+			labels := map[string]string{"device": dev.Name, "pci": pciAddress, "vendor": stats.Vendor}
+
+			out.AddSamples(metrics.GPUMemoryTotalBytes, metrics.Sample{Value: float64(stats.MemoryTotalBytes), Labels: labels})
+			out.AddSamples(metrics.GPUMemoryUsedBytes, metrics.Sample{Value: float64(stats.MemoryUsedBytes), Labels: labels})
+			out.AddSamples(metrics.GPUUtilizationRatio, metrics.Sample{Value: stats.UtilizationRatio, Labels: labels})
+		}
+	}
+
+	return out, nil
+}
+
 func (d *lxc) getFSStats() (*metrics.MetricSet, error) {
 	type mountInfo struct {
 		Mountpoint string
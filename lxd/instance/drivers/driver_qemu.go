@@ -334,6 +334,11 @@ func qemuCreate(s *state.State, args db.InstanceArgs, p api.Project) (instance.I
 		return nil, nil, fmt.Errorf("Storage pool does not support instance type")
 	}
 
+	// Volumes on volatile pools don't survive the pool being unmounted, only allow ephemeral instances there.
+	if !d.isSnapshot && !d.ephemeral && d.storagePool.Driver().Info().Volatile {
+		return nil, nil, fmt.Errorf("Only ephemeral instances can be created on storage pool %q", d.storagePool.Name())
+	}
+
 	if !d.IsSnapshot() {
 		// Add devices to instance.
 		cleanup, err := d.devicesAdd(d, false)
@@ -452,11 +457,14 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 
 		if event == qmp.EventAgentStarted {
 			d.logger.Debug("Instance agent started")
+			d.bootAgentStarted()
 			err := d.advertiseVsockAddress()
 			if err != nil {
 				d.logger.Warn("Failed to advertise vsock address to instance agent", logger.Ctx{"err": err})
 				return
 			}
+
+			d.ForwardGuestJournal()
 		} else if event == qmp.EventVMShutdown {
 			target := "stop"
 			entry, ok := data["reason"]
@@ -479,6 +487,36 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 	}
 }
 
+// bootAgentStarted records how long the guest took to boot until the lxd-agent started as the agent phase of
+// the last start of the instance. Later restarts of the agent within the same boot are ignored.
+func (d *qemu) bootAgentStarted() {
+	boot, err := d.bootLoad()
+	if err != nil || boot == nil {
+		return
+	}
+
+	for _, phase := range boot.Phases {
+		if phase.Name == "agent" {
+			return
+		}
+	}
+
+	now := time.Now()
+	last := boot.StartedAt.Add(time.Duration(boot.Duration))
+
+	boot.Phases = append(boot.Phases, api.InstanceStateBootPhase{
+		Name:     "agent",
+		Duration: now.Sub(last).Nanoseconds(),
+	})
+
+	boot.Duration = now.Sub(boot.StartedAt).Nanoseconds()
+
+	err = d.bootSave(boot)
+	if err != nil {
+		d.logger.Warn("Failed saving boot timing", logger.Ctx{"err": err})
+	}
+}
+
 // mount the instance's config volume if needed.
 func (d *qemu) mount() (*storagePools.MountInfo, error) {
 	var pool storagePools.Pool
@@ -564,6 +602,22 @@ func (d *qemu) generateAgentCert() (agentCert string, agentKey string, clientCer
 
 // Freeze freezes the instance.
 func (d *qemu) Freeze() error {
+	if d.expandedConfig["freeze.mode"] == "agent" {
+		// Have the lxd-agent freeze the processes of the guest.
+		err := d.agentStateUpdate("freeze")
+		if err != nil {
+			return fmt.Errorf("Failed freezing instance through lxd-agent: %w", err)
+		}
+
+		err = d.VolatileSet(map[string]string{"volatile.last_state.freeze_mode": "agent"})
+		if err != nil {
+			return err
+		}
+
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstancePaused.Event(d, nil))
+		return nil
+	}
+
 	// Connect to the monitor.
 	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
 	if err != nil {
@@ -633,6 +687,9 @@ func (d *qemu) onStop(target string) error {
 	// Unlock on return
 	defer op.Done(nil)
 
+	// Stop forwarding the guest journal.
+	d.stopGuestJournalForwarding()
+
 	// Wait for QEMU process to end (to avoiding racing start when restarting).
 	// Wait up to 5 minutes to allow for flushing any pending data to disk.
 	d.logger.Debug("Waiting for VM process to finish")
@@ -646,8 +703,9 @@ func (d *qemu) onStop(target string) error {
 
 	// Record power state.
 	err = d.VolatileSet(map[string]string{
-		"volatile.last_state.power": instance.PowerStateStopped,
-		"volatile.last_state.ready": "false",
+		"volatile.last_state.power":       instance.PowerStateStopped,
+		"volatile.last_state.ready":       "false",
+		"volatile.last_state.freeze_mode": "",
 	})
 	if err != nil {
 		// Don't return an error here as we still want to cleanup the instance even if DB not available.
@@ -733,7 +791,7 @@ func (d *qemu) Shutdown(timeout time.Duration) error {
 	}
 
 	// If frozen, resume so the signal can be handled.
-	if d.IsFrozen() {
+	if d.isAgentFrozen() || d.IsFrozen() {
 		err := d.Unfreeze()
 		if err != nil {
 			return err
@@ -1108,6 +1166,11 @@ func (d *qemu) validateStartup(stateful bool, statusCode api.StatusCode) error {
 		return fmt.Errorf("Stateful start requires migration.stateful to be set to true")
 	}
 
+	// The PTP clock of the hypervisor relies on kvmclock on x86_64.
+	if d.architecture == osarch.ARCH_64BIT_INTEL_X86 && shared.IsTrue(d.expandedConfig["clock.ptp"]) && shared.IsFalse(d.expandedConfig["clock.kvmclock"]) {
+		return fmt.Errorf("clock.ptp requires clock.kvmclock to be enabled")
+	}
+
 	return nil
 }
 
@@ -1173,6 +1236,8 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 
 	defer op.Done(err)
 
+	boot := newBootTimer()
+
 	// Ensure the correct vhost_vsock kernel module is loaded before establishing the vsock.
 	err = util.LoadModule("vhost_vsock")
 	if err != nil {
@@ -1212,6 +1277,8 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 
 	revert.Add(func() { _ = d.unmount() })
 
+	boot.phase("mount")
+
 	// Define a set of files to open and pass their file descriptors to QEMU command.
 	fdFiles := make([]*os.File, 0)
 
@@ -1368,6 +1435,8 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		devConfs = append(devConfs, runConf)
 	}
 
+	boot.phase("devices")
+
 	// Setup the config drive readonly bind mount. Important that this come after the root disk device start.
 	// in order to allow unmounts triggered by deferred resizes of the root volume.
 	configMntPath := d.configDriveMountPath()
@@ -1475,6 +1544,10 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		if cpuInfo.threads > 1 {
 			cpuExtensions = append(cpuExtensions, "topoext")
 		}
+
+		if shared.IsFalse(d.expandedConfig["clock.kvmclock"]) {
+			cpuExtensions = append(cpuExtensions, "kvmclock=off")
+		}
 	}
 
 	cpuType := "host"
@@ -1489,6 +1562,8 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		return err
 	}
 
+	boot.phase("config")
+
 	// Start QEMU.
 	qemuCmd := []string{
 		"--",
@@ -1783,6 +1858,9 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 
 	// Finish handling stateful start.
 	if stateful {
+		// The guest clock stood still since the state was saved.
+		d.resyncGuestClock()
+
 		// Cleanup state.
 		_ = os.Remove(d.StatePath())
 		d.stateful = false
@@ -1815,6 +1893,14 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		return err
 	}
 
+	// The guest boot is recorded as the agent phase once the lxd-agent has started.
+	boot.phase("qemu")
+
+	err = d.bootSave(&boot.boot)
+	if err != nil {
+		d.logger.Warn("Failed saving boot timing", logger.Ctx{"err": err})
+	}
+
 	if op.Action() == "start" {
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStarted.Event(d, nil))
 	}
@@ -1931,6 +2017,7 @@ func (d *qemu) getAgentConnectionInfo() (*agentAPI.API10Put, error) {
 		Devlxd:      shared.IsTrueOrEmpty(d.expandedConfig["security.devlxd"]),
 		CID:         vsock.Host, // Always tell lxd-agent to connect to LXD using Host Context ID to support nesting.
 		Port:        vsockaddr.Port,
+		PTP:         shared.IsTrue(d.expandedConfig["clock.ptp"]),
 	}
 
 	return &req, nil
@@ -1982,6 +2069,46 @@ func (d *qemu) AgentCertificate() *x509.Certificate {
 	return cert
 }
 
+// GuestJournal returns a websocket streaming the systemd journal entries of the guest through the lxd-agent.
+// The entries can be filtered by maximum priority and by systemd units.
+func (d *qemu) GuestJournal(lines int, follow bool, priority string, units []string) (*websocket.Conn, error) {
+	if !d.IsRunning() {
+		return nil, errors.New("Instance is not running")
+	}
+
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		d.logger.Error("Failed to connect to lxd-agent", logger.Ctx{"err": err})
+		return nil, fmt.Errorf("Failed to connect to lxd-agent")
+	}
+
+	defer agent.Disconnect()
+
+	values := url.Values{}
+	values.Set("lines", strconv.Itoa(lines))
+	values.Set("follow", strconv.FormatBool(follow))
+
+	if priority != "" {
+		values.Set("priority", priority)
+	}
+
+	for _, unit := range units {
+		values.Add("unit", unit)
+	}
+
+	conn, err := agent.RawWebsocket("/journal?" + values.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to the guest journal: %w", err)
+	}
+
+	return conn, nil
+}
+
 func (d *qemu) architectureSupportsUEFI(arch int) bool {
 	return shared.ValueInSlice(arch, []int{osarch.ARCH_64BIT_INTEL_X86, osarch.ARCH_64BIT_ARMV8_LITTLE_ENDIAN})
 }
@@ -3118,18 +3245,76 @@ func (d *qemu) deviceBootPriorities(base int) (map[string]int, error) {
 	return sortedDevs, nil
 }
 
+// rtcOpts returns the configuration of the real time clock, or nil if the default clock should be used.
+func (d *qemu) rtcOpts() (*qemuRTCOpts, error) {
+	base := d.expandedConfig["rtc.base"]
+	if (base == "" || base == "utc") && d.expandedConfig["rtc.offset"] == "" {
+		return nil, nil
+	}
+
+	var offset time.Duration
+	if d.expandedConfig["rtc.offset"] != "" {
+		var err error
+
+		offset, err = instancetype.ParseTimeOffset(d.expandedConfig["rtc.offset"])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid rtc.offset: %w", err)
+		}
+	}
+
+	if offset == 0 && (base == "" || base == "utc" || base == "localtime") {
+		return &qemuRTCOpts{base: base}, nil
+	}
+
+	opts := &qemuRTCOpts{}
+
+	var start time.Time
+	switch base {
+	case "", "utc":
+		start = time.Now().UTC()
+	case "localtime":
+		// The real time clock holds the local wall clock time.
+		start = time.Now()
+	default:
+		var err error
+
+		start, err = time.Parse(time.RFC3339, base)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid rtc.base: %w", err)
+		}
+
+		start = start.UTC()
+
+		// A fixed start time only advances while the VM runs.
+		opts.clock = "vm"
+	}
+
+	opts.base = start.Add(offset).Format("2006-01-02T15:04:05")
+
+	return opts, nil
+}
+
 // generateQemuConfigFile writes the qemu config file and returns its location.
 // It writes the config file inside the VM's log path.
 func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePools.MountInfo, busName string, vsockFD int, devConfs []*deviceConfig.RunConfig, fdFiles *[]*os.File) (string, []monitorHook, error) {
 	var monHooks []monitorHook
 
-	cfg := qemuBase(&qemuBaseOpts{d.Architecture()})
+	cfg := qemuBase(&qemuBaseOpts{architecture: d.Architecture(), disableHPET: shared.IsFalse(d.expandedConfig["clock.hpet"])})
 
 	err := d.addCPUMemoryConfig(&cfg, cpuInfo)
 	if err != nil {
 		return "", nil, err
 	}
 
+	rtcOpts, err := d.rtcOpts()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if rtcOpts != nil {
+		cfg = append(cfg, qemuRTC(rtcOpts)...)
+	}
+
 	// Parse raw.qemu.
 	rawOptions := []string{}
 	if d.expandedConfig["raw.qemu"] != "" {
@@ -3300,6 +3485,7 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 			devAddr:       devAddr,
 			multifunction: multi,
 			ports:         qemuSparseUSBPorts,
+			redirection:   shared.IsTrueOrEmpty(d.expandedConfig["security.usb_redirection"]) && project.USBRedirectionAllowed(d.project.Config),
 		}
 
 		cfg = append(cfg, qemuUSB(&usbOpts)...)
@@ -4954,6 +5140,22 @@ func (d *qemu) Stop(stateful bool) error {
 
 // Unfreeze restores the instance to running.
 func (d *qemu) Unfreeze() error {
+	if d.isAgentFrozen() {
+		// Have the lxd-agent thaw the processes of the guest.
+		err := d.agentStateUpdate("unfreeze")
+		if err != nil {
+			return fmt.Errorf("Failed unfreezing instance through lxd-agent: %w", err)
+		}
+
+		err = d.VolatileSet(map[string]string{"volatile.last_state.freeze_mode": ""})
+		if err != nil {
+			return err
+		}
+
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceResumed.Event(d, nil))
+		return nil
+	}
+
 	// Connect to the monitor.
 	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
 	if err != nil {
@@ -4966,10 +5168,104 @@ func (d *qemu) Unfreeze() error {
 		return err
 	}
 
+	d.resyncGuestClock()
+
 	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceResumed.Event(d, nil))
 	return nil
 }
 
+// isAgentFrozen returns whether the processes of the guest have been frozen by the lxd-agent.
+func (d *qemu) isAgentFrozen() bool {
+	return d.localConfig["volatile.last_state.freeze_mode"] == "agent"
+}
+
+// agentStateUpdate sends a state change action to the lxd-agent.
+func (d *qemu) agentStateUpdate(action string) error {
+	client, err := d.getAgentClient()
+	if err != nil {
+		return err
+	}
+
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		return fmt.Errorf("Failed connecting to lxd-agent: %w", err)
+	}
+
+	defer agent.Disconnect()
+
+	_, _, err = agent.RawQuery("PUT", "/1.0/state", api.InstanceStatePut{Action: action}, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SyncGuestClock sets the clock of the guest to the current time through the lxd-agent, so that it catches up on
+// the time during which the VM was paused.
+func (d *qemu) SyncGuestClock() error {
+	if !d.IsRunning() || shared.IsFalse(d.expandedConfig["clock.resync"]) {
+		return nil
+	}
+
+	// A fixed start time of the real time clock only advances while the VM runs.
+	base := d.expandedConfig["rtc.base"]
+	if base != "" && base != "utc" && base != "localtime" {
+		return nil
+	}
+
+	now := time.Now()
+	if d.expandedConfig["rtc.offset"] != "" {
+		offset, err := instancetype.ParseTimeOffset(d.expandedConfig["rtc.offset"])
+		if err != nil {
+			return fmt.Errorf("Invalid rtc.offset: %w", err)
+		}
+
+		now = now.Add(offset)
+	}
+
+	client, err := d.getAgentClient()
+	if err != nil {
+		return err
+	}
+
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		return fmt.Errorf("Failed connecting to lxd-agent: %w", err)
+	}
+
+	defer agent.Disconnect()
+
+	_, _, err = agent.RawQuery("PUT", "/1.0/clock", agentAPI.ClockPut{Time: now}, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// resyncGuestClock synchronizes the clock of the guest in the background once the VM resumed, giving the lxd-agent
+// some time to become reachable again.
+func (d *qemu) resyncGuestClock() {
+	if shared.IsFalse(d.expandedConfig["clock.resync"]) {
+		return
+	}
+
+	go func() {
+		var err error
+		for i := 0; i < 10; i++ {
+			err = d.SyncGuestClock()
+			if err == nil {
+				return
+			}
+
+			time.Sleep(time.Second)
+		}
+
+		d.logger.Warn("Failed resynchronizing guest clock", logger.Ctx{"err": err})
+	}()
+}
+
 // IsPrivileged does not apply to virtual machines. Always returns false.
 func (d *qemu) IsPrivileged() bool {
 	return false
@@ -5023,6 +5319,8 @@ func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 		if err != nil {
 			return err
 		}
+
+		d.resyncGuestClock()
 	}
 
 	return nil
@@ -5592,6 +5890,18 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 		if newErr != nil {
 			return fmt.Errorf("Invalid root disk device: %w", newErr)
 		}
+
+		// Ensure instances on volatile pools stay ephemeral.
+		if !d.ephemeral {
+			pool, err := d.getStoragePool()
+			if err != nil {
+				return err
+			}
+
+			if pool.Driver().Info().Volatile {
+				return fmt.Errorf("Instances on storage pool %q must be ephemeral", pool.Name())
+			}
+		}
 	}
 
 	// If apparmor changed, re-validate the apparmor profile (even if not running).
@@ -5611,6 +5921,7 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 	}
 
 	cpuLimitWasChanged := false
+	journalForwardingChanged := false
 
 	if isRunning {
 		// Only certain keys can be changed on a running VM.
@@ -5624,6 +5935,7 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 		}
 
 		liveUpdateKeyPrefixes := []string{
+			"agent.journal.",
 			"boot.",
 			"cloud-init.",
 			"environment.",
@@ -5710,8 +6022,14 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 				if err != nil {
 					return err
 				}
+			} else if strings.HasPrefix(key, "agent.journal.") {
+				journalForwardingChanged = true
 			}
 		}
+
+		if journalForwardingChanged {
+			d.ForwardGuestJournal()
+		}
 	}
 
 	// Update MAAS (must run after the MAC addresses have been generated).
@@ -5781,6 +6099,11 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 			return err
 		}
 
+		err = d.recordProfileRevisions(ctx, tx)
+		if err != nil {
+			return err
+		}
+
 		err = dbCluster.UpdateInstanceConfig(ctx, tx.Tx(), int64(object.ID), d.localConfig)
 		if err != nil {
 			return err
@@ -5833,7 +6156,7 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 				"value":     d.expandedConfig[key],
 			}
 
-			err = d.devlxdEventSend("config", msg)
+			err = d.DevlxdEventSend("config", msg)
 			if err != nil {
 				return err
 			}
@@ -5841,7 +6164,7 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 
 		// Device events.
 		for _, event := range devlxdEvents {
-			err = d.devlxdEventSend("device", event)
+			err = d.DevlxdEventSend("device", event)
 			if err != nil {
 				return err
 			}
@@ -6213,7 +6536,7 @@ func (d *qemu) delete(force bool) error {
 }
 
 // Export publishes the instance.
-func (d *qemu) Export(w io.Writer, properties map[string]string, expiration time.Time) (api.ImageMetadata, error) {
+func (d *qemu) Export(w io.Writer, properties map[string]string, expiration time.Time, reproducible bool) (api.ImageMetadata, error) {
 	ctxMap := logger.Ctx{
 		"created":   d.creationDate,
 		"ephemeral": d.ephemeral,
@@ -6238,6 +6561,7 @@ func (d *qemu) Export(w io.Writer, properties map[string]string, expiration time
 
 	// Create the tarball.
 	tarWriter := instancewriter.NewInstanceTarWriter(w, nil)
+	tarWriter.SetReproducible(reproducible)
 
 	// Path inside the tar image is the pathname starting after cDir.
 	cDir := d.Path()
@@ -6296,8 +6620,13 @@ func (d *qemu) Export(w io.Writer, properties map[string]string, expiration time
 
 		// Fill in the metadata.
 		meta.Architecture = arch
-		meta.CreationDate = time.Now().UTC().Unix()
 		meta.Properties = properties
+
+		// Reproducible images don't record when they were created.
+		if !reproducible {
+			meta.CreationDate = time.Now().UTC().Unix()
+		}
+
 		if !expiration.IsZero() {
 			meta.ExpiryDate = expiration.UTC().Unix()
 		}
@@ -6519,6 +6848,9 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 		return fmt.Errorf("No source migration types available")
 	}
 
+	// Only offer the requested rsync compression.
+	poolMigrationTypes = migration.SetRsyncCompression(poolMigrationTypes, args.CompressionAlgorithm)
+
 	// Convert the pool's migration type options to an offer header to target.
 	// Populate the Fs, ZfsFeatures and RsyncFeatures fields.
 	offerHeader := migration.TypesToHeader(poolMigrationTypes...)
@@ -6527,6 +6859,11 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 	indexHeaderVersion := migration.IndexHeaderVersion
 	offerHeader.IndexHeaderVersion = &indexHeaderVersion
 
+	// Offer to send the block checksums if requested.
+	if args.BlockChecksums {
+		offerHeader.BlockChecksums = proto.Bool(true)
+	}
+
 	// For VMs, send block device size hint in offer header so that target can create the volume the same size.
 	blockSize, err := storagePools.InstanceDiskBlockSize(pool, d, d.op)
 	if err != nil {
@@ -6591,6 +6928,8 @@ func (d *qemu) MigrateSend(args instance.MigrateSendArgs) error {
 		TrackProgress:      true,
 		Refresh:            respHeader.GetRefresh(),
 		AllowInconsistent:  args.AllowInconsistent,
+		CompressionLevel:   args.CompressionLevel,
+		BlockChecksums:     respHeader.GetBlockChecksums(),
 		VolumeOnly:         !args.Snapshots,
 		Info:               &migration.Info{Config: srcConfig},
 		ClusterMove:        args.ClusterMoveSourceName != "",
@@ -7018,6 +7357,7 @@ func (d *qemu) migrateSendLive(pool storagePools.Pool, clusterMoveSourceName str
 		}
 
 		d.logger.Debug("Resumed instance")
+		d.resyncGuestClock()
 
 		// Merge snapshot back to the source disk so we don't lose the writes.
 		d.logger.Debug("Merge migration storage snapshot on source started")
@@ -7109,6 +7449,11 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 	respHeader.Snapshots = offerHeader.Snapshots
 	respHeader.Refresh = &args.Refresh
 
+	// Agree to verify the block checksums if offered by the source.
+	if offerHeader.GetBlockChecksums() {
+		respHeader.BlockChecksums = proto.Bool(true)
+	}
+
 	if args.Refresh {
 		// Get the remote snapshots on the source.
 		sourceSnapshots := offerHeader.GetSnapshots()
@@ -7289,6 +7634,7 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 			VolumeSize:            offerHeader.GetVolumeSize(), // Block size setting override.
 			VolumeOnly:            !args.Snapshots,
 			ClusterMoveSourceName: args.ClusterMoveSourceName,
+			BlockChecksums:        offerHeader.GetBlockChecksums(),
 		}
 
 		// At this point we have already figured out the parent instances's root
@@ -7460,6 +7806,22 @@ func (d *qemu) CGroup() (*cgroup.CGroup, error) {
 	return nil, instance.ErrNotImplemented
 }
 
+// VCPUThreads returns the IDs of the host threads running the virtual CPUs, in virtual CPU order.
+func (d *qemu) VCPUThreads() ([]int, error) {
+	// Check if the VM is running.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return nil, err
+	}
+
+	pids, err := monitor.GetCPUs()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get VM instance's QEMU process list: %w", err)
+	}
+
+	return pids, nil
+}
+
 // SetAffinity sets affinity for QEMU processes according with a set provided.
 func (d *qemu) SetAffinity(set []string) error {
 	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
@@ -7554,7 +7916,14 @@ func (d *qemu) FileSFTPConn() (net.Conn, error) {
 		return nil, err
 	}
 
+	if resp.StatusCode == http.StatusNotFound {
+		// Agents ported to other operating systems may not provide an SFTP server.
+		_ = tlsConn.Close()
+		return nil, api.StatusErrorf(http.StatusNotImplemented, "The instance agent doesn't support SFTP")
+	}
+
 	if resp.StatusCode != http.StatusSwitchingProtocols {
+		_ = tlsConn.Close()
 		return nil, fmt.Errorf("Dialing failed: expected status code 101 got %d", resp.StatusCode)
 	}
 
@@ -7891,6 +8260,8 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 		d.logger.Warn("Error getting disk usage", logger.Ctx{"err": err})
 	}
 
+	status.Boot = d.bootState()
+
 	return status, nil
 }
 
@@ -8223,6 +8594,10 @@ func (d *qemu) statusCode() api.StatusCode {
 
 	switch status {
 	case "prelaunch", "running":
+		if status == "running" && d.isAgentFrozen() {
+			return api.Frozen
+		}
+
 		if status == "running" && shared.IsTrue(d.LocalConfig()["volatile.last_state.ready"]) {
 			return api.Ready
 		}
@@ -8462,7 +8837,8 @@ func (d *qemu) cpuTopology(limit string) (*cpuTopology, error) {
 	return topology, nil
 }
 
-func (d *qemu) devlxdEventSend(eventType string, eventMessage map[string]any) error {
+// DevlxdEventSend sends an event to the listeners of the devlxd API of the instance.
+func (d *qemu) DevlxdEventSend(eventType string, eventMessage map[string]any) error {
 	event := shared.Jmap{}
 	event["type"] = eventType
 	event["timestamp"] = time.Now()
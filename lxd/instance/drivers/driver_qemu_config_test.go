@@ -49,6 +49,28 @@ func TestQemuConfigTemplates(t *testing.T) {
 			property = "disable_s4"
 			value = "1"
 
+			[boot-opts]
+			strict = "on"`,
+		}, {
+			qemuBaseOpts{architecture: osarch.ARCH_64BIT_INTEL_X86, disableHPET: true},
+			`# Machine
+			[machine]
+			graphics = "off"
+			type = "q35"
+			accel = "kvm"
+			usb = "off"
+			hpet = "off"
+
+			[global]
+			driver = "ICH9-LPC"
+			property = "disable_s3"
+			value = "1"
+
+			[global]
+			driver = "ICH9-LPC"
+			property = "disable_s4"
+			value = "1"
+
 			[boot-opts]
 			strict = "on"`,
 		}, {
@@ -1016,6 +1038,7 @@ func TestQemuConfigTemplates(t *testing.T) {
 				devAddr:       "00.0",
 				multifunction: true,
 				ports:         3,
+				redirection:   true,
 			},
 			`# USB controller
 			[device "qemu_usb"]
@@ -1049,6 +1072,22 @@ func TestQemuConfigTemplates(t *testing.T) {
 			[device "qemu_spice-usb3"]
 			driver = "usb-redir"
 			chardev = "qemu_spice-usb-chardev3"`,
+		}, {
+			qemuUSBOpts{
+				devBus:        "qemu_pcie1",
+				devAddr:       "00.0",
+				multifunction: true,
+				ports:         3,
+				redirection:   false,
+			},
+			`# USB controller
+			[device "qemu_usb"]
+			driver = "qemu-xhci"
+			bus = "qemu_pcie1"
+			addr = "00.0"
+			multifunction = "on"
+			p2 = "3"
+			p3 = "3"`,
 		}}
 		for _, tc := range testCases {
 			runTest(tc.expected, qemuUSB(&tc.opts))
@@ -1081,6 +1120,27 @@ func TestQemuConfigTemplates(t *testing.T) {
 		}
 	})
 
+	t.Run("qemu_rtc", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuRTCOpts
+			expected string
+		}{{
+			qemuRTCOpts{base: "localtime"},
+			`# Real time clock
+			[rtc]
+			base = "localtime"`,
+		}, {
+			qemuRTCOpts{base: "2030-01-01T00:00:00", clock: "vm"},
+			`# Real time clock
+			[rtc]
+			base = "2030-01-01T00:00:00"
+			clock = "vm"`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuRTC(&tc.opts))
+		}
+	})
+
 	t.Run("qemu_raw_cfg_override", func(t *testing.T) {
 		cfg := []cfgSection{{
 			name: "global",
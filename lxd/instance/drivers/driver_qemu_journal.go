@@ -0,0 +1,165 @@
+package drivers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// guestJournalLogMaxSize is the size above which the forwarded journal log is rotated.
+const guestJournalLogMaxSize = 10 * 1024 * 1024
+
+// guestJournalRetryInterval is the interval at which the connection to the guest journal is retried.
+const guestJournalRetryInterval = 10 * time.Second
+
+// guestJournalForwarders contains the cancel functions of the running guest journal forwarders.
+var guestJournalForwarders = map[string]context.CancelFunc{}
+var guestJournalForwardersMu sync.Mutex
+
+// guestJournalLogPath returns the path of the log file containing the forwarded journal entries.
+func (d *qemu) guestJournalLogPath() string {
+	return filepath.Join(d.LogPath(), "journal.log")
+}
+
+// ForwardGuestJournal starts forwarding the guest journal according to the instance configuration.
+// Any previous forwarding for the instance is stopped first.
+func (d *qemu) ForwardGuestJournal() {
+	d.stopGuestJournalForwarding()
+
+	if shared.IsFalseOrEmpty(d.expandedConfig["agent.journal.forward"]) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	guestJournalForwardersMu.Lock()
+	guestJournalForwarders[project.Instance(d.project.Name, d.name)] = cancel
+	guestJournalForwardersMu.Unlock()
+
+	go d.forwardGuestJournal(ctx)
+}
+
+// stopGuestJournalForwarding stops forwarding the guest journal.
+func (d *qemu) stopGuestJournalForwarding() {
+	key := project.Instance(d.project.Name, d.name)
+
+	guestJournalForwardersMu.Lock()
+	cancel, ok := guestJournalForwarders[key]
+	delete(guestJournalForwarders, key)
+	guestJournalForwardersMu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+// forwardGuestJournal forwards the guest journal until the context is cancelled or the VM stops.
+// The connection to the lxd-agent is retried if it's interrupted.
+func (d *qemu) forwardGuestJournal(ctx context.Context) {
+	priority := d.expandedConfig["agent.journal.priority"]
+	if priority == "" {
+		priority = "info"
+	}
+
+	units := shared.SplitNTrimSpace(d.expandedConfig["agent.journal.units"], ",", -1, true)
+
+	d.logger.Debug("Started forwarding guest journal", logger.Ctx{"priority": priority, "units": units})
+	defer d.logger.Debug("Stopped forwarding guest journal")
+
+	for {
+		err := d.forwardGuestJournalEntries(ctx, priority, units)
+		if ctx.Err() != nil || !d.IsRunning() {
+			return
+		}
+
+		if err != nil {
+			d.logger.Debug("Guest journal forwarding interrupted", logger.Ctx{"err": err})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(guestJournalRetryInterval):
+		}
+	}
+}
+
+// forwardGuestJournalEntries appends the new entries of the guest journal to the journal log of the instance and
+// sends them as events.
+func (d *qemu) forwardGuestJournalEntries(ctx context.Context, priority string, units []string) error {
+	conn, err := d.GuestJournal(0, true, priority, units)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	logFile, err := os.OpenFile(d.guestJournalLogPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = logFile.Close() }()
+
+	for {
+		record := api.InstanceLogRecord{}
+
+		err := conn.ReadJSON(&record)
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+
+			return err
+		}
+
+		if record.Error != "" {
+			return fmt.Errorf("Failed reading guest journal: %s", record.Error)
+		}
+
+		record.Source = api.InstanceLogSourceJournal
+
+		identifier := record.Fields["SYSLOG_IDENTIFIER"]
+		if identifier == "" {
+			identifier = record.Fields["_COMM"]
+		}
+
+		_, err = fmt.Fprintf(logFile, "%s %s: %s\n", record.Timestamp.Format(time.RFC3339Nano), identifier, record.Message)
+		if err != nil {
+			return err
+		}
+
+		_ = d.state.Events.Send(d.project.Name, api.EventTypeInstanceLog, api.EventInstanceLog{InstanceLogRecord: record, Instance: d.name})
+
+		// Rotate the log, keeping the previous one.
+		fi, err := logFile.Stat()
+		if err == nil && fi.Size() > guestJournalLogMaxSize {
+			_ = logFile.Close()
+
+			err = os.Rename(d.guestJournalLogPath(), d.guestJournalLogPath()+".1")
+			if err != nil {
+				return err
+			}
+
+			logFile, err = os.OpenFile(d.guestJournalLogPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -69,18 +69,24 @@ func qemuMachineType(architecture int) string {
 
 type qemuBaseOpts struct {
 	architecture int
+	disableHPET  bool
 }
 
 func qemuBase(opts *qemuBaseOpts) []cfgSection {
 	machineType := qemuMachineType(opts.architecture)
 	gicVersion := ""
 	capLargeDecr := ""
+	hpet := ""
 
 	switch opts.architecture {
 	case osarch.ARCH_64BIT_ARMV8_LITTLE_ENDIAN:
 		gicVersion = "max"
 	case osarch.ARCH_64BIT_POWERPC_LITTLE_ENDIAN:
 		capLargeDecr = "off"
+	case osarch.ARCH_64BIT_INTEL_X86:
+		if opts.disableHPET {
+			hpet = "off"
+		}
 	}
 
 	sections := []cfgSection{{
@@ -93,6 +99,7 @@ func qemuBase(opts *qemuBaseOpts) []cfgSection {
 			{key: "cap-large-decr", value: capLargeDecr},
 			{key: "accel", value: "kvm"},
 			{key: "usb", value: "off"},
+			{key: "hpet", value: hpet},
 		},
 	}}
 
@@ -826,6 +833,7 @@ type qemuUSBOpts struct {
 	devAddr       string
 	multifunction bool
 	ports         int
+	redirection   bool
 }
 
 func qemuUSB(opts *qemuUSBOpts) []cfgSection {
@@ -848,6 +856,11 @@ func qemuUSB(opts *qemuUSBOpts) []cfgSection {
 		}...),
 	}}
 
+	if !opts.redirection {
+		return sections
+	}
+
+	// Add the channels used by SPICE clients to redirect USB devices.
 	for i := 1; i <= 3; i++ {
 		chardev := fmt.Sprintf("qemu_spice-usb-chardev%d", i)
 		sections = append(sections, []cfgSection{{
@@ -912,3 +925,19 @@ func qemuVmgen(opts *qemuVmgenIDOpts) []cfgSection {
 		},
 	}}
 }
+
+type qemuRTCOpts struct {
+	base  string
+	clock string
+}
+
+func qemuRTC(opts *qemuRTCOpts) []cfgSection {
+	return []cfgSection{{
+		name:    "rtc",
+		comment: "Real time clock",
+		entries: []cfgEntry{
+			{key: "base", value: opts.base},
+			{key: "clock", value: opts.clock},
+		},
+	}}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/gorilla/websocket"
 	liblxc "github.com/lxc/go-lxc"
 	"github.com/pkg/sftp"
 	"google.golang.org/protobuf/proto"
@@ -97,12 +98,13 @@ type Instance interface {
 	Update(newConfig db.InstanceArgs, userRequested bool) error
 
 	Delete(force bool) error
-	Export(w io.Writer, properties map[string]string, expiration time.Time) (api.ImageMetadata, error)
+	Export(w io.Writer, properties map[string]string, expiration time.Time, reproducible bool) (api.ImageMetadata, error)
 
 	// Live configuration.
 	CGroup() (*cgroup.CGroup, error)
 	VolatileSet(changes map[string]string) error
 	SetAffinity(set []string) error
+	DevlxdEventSend(eventType string, eventMessage map[string]any) error
 
 	// File handling.
 	FileSFTPConn() (net.Conn, error)
@@ -181,6 +183,7 @@ type Container interface {
 	InsertSeccompUnixDevice(prefix string, m deviceConfig.Device, pid int) error
 	DevptsFd() (*os.File, error)
 	IdmappedStorage(path string, fstype string) idmap.IdmapStorageType
+	Remap() error
 }
 
 // VM interface is for VM specific functions.
@@ -192,6 +195,16 @@ type VM interface {
 	// UEFI vars handling.
 	UEFIVars() (*api.InstanceUEFIVars, error)
 	UEFIVarsUpdate(newUEFIVarsSet api.InstanceUEFIVars) error
+
+	// Guest journal streaming.
+	GuestJournal(lines int, follow bool, priority string, units []string) (*websocket.Conn, error)
+	ForwardGuestJournal()
+
+	// Guest clock synchronization.
+	SyncGuestClock() error
+
+	// Host threads running the virtual CPUs, in virtual CPU order.
+	VCPUThreads() ([]int, error)
 }
 
 // CriuMigrationArgs arguments for CRIU migration.
@@ -232,7 +245,10 @@ type MigrateArgs struct {
 type MigrateSendArgs struct {
 	MigrateArgs
 
-	AllowInconsistent bool
+	AllowInconsistent    bool
+	CompressionAlgorithm string // Optional rsync compression algorithm ("zlib", "zstd", "lz4" or "none").
+	CompressionLevel     int
+	BlockChecksums       bool // Whether to offer block checksum verification to the target.
 }
 
 // MigrateReceiveArgs represent arguments for instance migration receive.
@@ -27,12 +27,14 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/instance/operationlock"
 	"github.com/canonical/lxd/lxd/migration"
+	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/seccomp"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/sys"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/osarch"
 	"github.com/canonical/lxd/shared/revert"
@@ -323,12 +325,8 @@ func LoadByProjectAndName(s *state.State, projectName string, instanceName strin
 	var args db.InstanceArgs
 	var p *api.Project
 	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		proj, err := cluster.GetProject(ctx, tx.Tx(), projectName)
-		if err != nil {
-			return err
-		}
-
-		p, err = proj.ToAPI(ctx, tx.Tx())
+		var err error
+		p, err = tx.GetCachedProject(ctx, projectName)
 		if err != nil {
 			return err
 		}
@@ -415,12 +413,8 @@ func LoadFromBackup(s *state.State, projectName string, instancePath string, app
 
 	var p *api.Project
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		proj, err := cluster.GetProject(ctx, tx.Tx(), projectName)
-		if err != nil {
-			return err
-		}
-
-		p, err = proj.ToAPI(ctx, tx.Tx())
+		var err error
+		p, err = tx.GetCachedProject(ctx, projectName)
 		if err != nil {
 			return err
 		}
@@ -498,6 +492,22 @@ func ResolveImage(ctx context.Context, tx *db.ClusterTx, projectName string, sou
 			return "", err
 		}
 
+		// Use the target of the alias channel that the project follows, if the alias has that channel.
+		dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return "", err
+		}
+
+		projectConfig, err := cluster.GetProjectConfig(ctx, tx.Tx(), dbProject.ID)
+		if err != nil {
+			return "", err
+		}
+
+		channel := projectConfig["images.alias_channel"]
+		if channel != "" && alias.Channels[channel] != "" {
+			return alias.Channels[channel], nil
+		}
+
 		return alias.Target, nil
 	}
 
@@ -927,14 +937,20 @@ func CreateInternal(s *state.State, args db.InstanceArgs, clearLogDir bool) (Ins
 			return err
 		}
 
-		err = cluster.CreateInstanceConfig(ctx, tx.Tx(), instanceID, args.Config)
+		profileNames := make([]string, 0, len(args.Profiles))
+		for _, profile := range args.Profiles {
+			profileNames = append(profileNames, profile.Name)
+		}
+
+		// Record the revision of the profiles the instance is created from.
+		args.Config["volatile.profiles.revisions"], err = UpdateProfileRevisions(ctx, tx, p, profileNames, args.Config["volatile.profiles.revisions"])
 		if err != nil {
 			return err
 		}
 
-		profileNames := make([]string, 0, len(args.Profiles))
-		for _, profile := range args.Profiles {
-			profileNames = append(profileNames, profile.Name)
+		err = cluster.CreateInstanceConfig(ctx, tx.Tx(), instanceID, args.Config)
+		if err != nil {
+			return err
 		}
 
 		err = cluster.UpdateInstanceProfiles(ctx, tx.Tx(), int(instanceID), dbInst.Project, profileNames)
@@ -1241,3 +1257,59 @@ func SnapshotProtobufToInstanceArgs(s *state.State, inst Instance, snap *migrati
 
 	return &args, nil
 }
+
+// ParseProfileRevisions parses the value of `volatile.profiles.revisions` into a map of profile names to revisions.
+func ParseProfileRevisions(value string) (map[string]int64, error) {
+	revisions := map[string]int64{}
+
+	for _, entry := range shared.SplitNTrimSpace(value, ",", -1, true) {
+		name, revisionStr, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("Invalid profile revision %q", entry)
+		}
+
+		revision, err := strconv.ParseInt(revisionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid profile revision %q: %w", entry, err)
+		}
+
+		revisions[name] = revision
+	}
+
+	return revisions, nil
+}
+
+// UpdateProfileRevisions returns the value of `volatile.profiles.revisions` for an instance of the project with
+// the given profiles. The revisions recorded in the previous value are kept for the profiles that were already
+// applied, while the current revision is recorded for the others.
+func UpdateProfileRevisions(ctx context.Context, tx *db.ClusterTx, p *api.Project, profileNames []string, previous string) (string, error) {
+	revisions, err := ParseProfileRevisions(previous)
+	if err != nil {
+		// Start over rather than preventing changes to the instance.
+		revisions = map[string]int64{}
+	}
+
+	profileProjectName := project.ProfileProjectFromRecord(p)
+
+	entries := make([]string, 0, len(profileNames))
+	for _, profileName := range profileNames {
+		revision, ok := revisions[profileName]
+		if !ok {
+			profileID, err := cluster.GetProfileID(ctx, tx.Tx(), profileProjectName, profileName)
+			if err != nil {
+				return "", fmt.Errorf("Failed getting profile %q: %w", profileName, err)
+			}
+
+			latest, err := cluster.GetLatestConfigRevisionID(ctx, tx.Tx(), cluster.EntityType(entity.TypeProfile), int(profileID))
+			if err != nil {
+				return "", err
+			}
+
+			revision = int64(latest)
+		}
+
+		entries = append(entries, fmt.Sprintf("%s:%d", profileName, revision))
+	}
+
+	return strings.Join(entries, ","), nil
+}
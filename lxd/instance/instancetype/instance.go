@@ -113,6 +113,8 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 
 	// lxdmeta:generate(entities=instance; group=boot; key=boot.host_shutdown_timeout)
 	// Number of seconds to wait for the instance to shut down before it is force-stopped.
+	// When the host shuts down, the time is counted from the start of the shutdown of the instances that have the same {config:option}`instance-boot:boot.stop.priority`.
+	// Therefore, the instances of a priority are all stopped within the highest timeout among them.
 	// ---
 	//  type: integer
 	//  defaultdesc: "30"
@@ -120,6 +122,21 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: How long to wait for the instance to shut down
 	"boot.host_shutdown_timeout": validate.Optional(validate.IsInt64),
 
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.host_shutdown_action)
+	// Possible values are `stop`, `stateful-stop` and `ignore`.
+	// With `stateful-stop`, the state of the instance is saved when the host shuts down and restored when LXD starts it again.
+	// This requires {config:option}`instance-migration:migration.stateful` to be enabled.
+	// If saving the state fails, the instance is shut down instead.
+	// With `ignore`, LXD doesn't stop the instance and leaves it to the operating system of the host.
+	//
+	// See {ref}`instances-manage-stateful-stop` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `stop`
+	//  liveupdate: yes
+	//  shortdesc: What to do with the instance when the host shuts down
+	"boot.host_shutdown_action": validate.Optional(validate.IsOneOf("stop", "stateful-stop", "ignore")),
+
 	// lxdmeta:generate(entities=instance; group=cloud-init; key=cloud-init.network-config)
 	// The content is used as seed value for `cloud-init`.
 	// ---
@@ -193,6 +210,9 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//      process will not be live, meaning there will be a brief downtime for the instance during the
 	//      migration.
 	//   -  `stop`: Instances are not migrated. Instead, they are stopped on the current node.
+	//   -  `stateful-stop`: Instances are not migrated. Instead, their state is saved and they are stopped on the current
+	//      node. The state is restored when the cluster member is restored. This requires `migration.stateful` to be
+	//      enabled.
 	//
 	// See {ref}`cluster-evacuate` for more information.
 	// ---
@@ -200,7 +220,24 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  defaultdesc: `auto`
 	//  liveupdate: no
 	//  shortdesc: What to do when evacuating the instance
-	"cluster.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "stop")),
+	"cluster.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "stop", "stateful-stop")),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=ephemeral.ttl)
+	// Specify an expression like `30M 2H 1d`.
+	// LXD deletes the ephemeral instance when it has been inactive for this long.
+	// Starting the instance, running commands, attaching to its console, transferring files and renewing its lease count as activity.
+	//
+	// See {ref}`instances-ephemeral-ttl` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: ephemeral instance
+	//  shortdesc: How long an ephemeral instance can be inactive before it is deleted
+	"ephemeral.ttl": func(value string) error {
+		// Validate expression
+		_, err := shared.GetExpiry(time.Time{}, value)
+		return err
+	},
 
 	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.cpu)
 	// A number or a specific range of CPUs to expose to the instance.
@@ -302,6 +339,29 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Whether `/dev/lxd` is present in the instance
 	"security.devlxd": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.devlxd.credentials)
+	// See {ref}`dev-lxd-credentials` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Whether the instance can get short-lived credentials through `/dev/lxd`
+	"security.devlxd.credentials": validate.Optional(validate.IsBool),
+
+	// lxdmeta:generate(entities=instance; group=security; key=security.devlxd.credentials.expiry)
+	// Specify an expression like `30M` or `1H`.
+	// New credentials are pushed to the instance when half of this time has passed.
+	// ---
+	//  type: string
+	//  defaultdesc: `1H`
+	//  liveupdate: yes
+	//  shortdesc: For how long the credentials of the instance are valid
+	"security.devlxd.credentials.expiry": func(value string) error {
+		// Validate expression
+		_, err := shared.GetExpiry(time.Time{}, value)
+		return err
+	},
+
 	// lxdmeta:generate(entities=instance; group=security; key=security.protection.delete)
 	//
 	// ---
@@ -311,6 +371,16 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Prevents the instance from being deleted
 	"security.protection.delete": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.ssh-keys)
+	// Specify a comma-separated list of the SSH keys of the project to install in the instance.
+	// LXD keeps the keys in sync while the instance is running.
+	// See {ref}`instances-ssh-keys` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: SSH keys to keep in sync in the instance
+	"security.ssh-keys": validate.Optional(validate.IsListOf(validate.IsURLSegmentSafe)),
+
 	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.schedule)
 	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic snapshots.
 	//
@@ -342,6 +412,16 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Template for the snapshot name
 	"snapshots.pattern": validate.IsAny,
 
+	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.rebuild)
+	// If enabled, a snapshot of the instance is taken before it is rebuilt, for example to apply an updated image.
+	// The name of the snapshot is recorded in `volatile.rebuild.snapshot`, so that the rebuild can be reverted by restoring it.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  shortdesc: Whether to snapshot the instance before rebuilding it
+	"snapshots.rebuild": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.expiry)
 	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
 	// ---
@@ -384,6 +464,28 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: The origin of the evacuated instance
 	"volatile.evacuate.origin": validate.IsAny,
 
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.evacuate.state)
+	// Possible values are `stopped`, `migrating`, `migrated`, `skipped` and `failed`.
+	// The key is cleared when the cluster member is restored.
+	// ---
+	//  type: string
+	//  shortdesc: State of the instance in the evacuation of its cluster member
+	"volatile.evacuate.state": validate.Optional(validate.IsOneOf("stopped", "migrating", "migrated", "skipped", "failed")),
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.evacuate.error)
+	//
+	// ---
+	//  type: string
+	//  shortdesc: Error of the last failed evacuation or restore of the instance
+	"volatile.evacuate.error": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.last_activity)
+	// The time of the last activity of an ephemeral instance that has `ephemeral.ttl` set, in RFC3339 format.
+	// ---
+	//  type: string
+	//  shortdesc: Last activity of the ephemeral instance
+	"volatile.last_activity": validate.Optional(validate.IsAny),
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.last_state.power)
 	//
 	// ---
@@ -392,6 +494,38 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	"volatile.last_state.power": validate.IsAny,
 	"volatile.last_state.ready": validate.IsBool,
 	"volatile.apply_quota":      validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.profiles.revisions)
+	// Comma-separated list of `<profile>:<revision>` pairs recording the configuration revision of each profile
+	// when it was applied to the instance, or when the instance was last rebuilt.
+	// A revision of `0` means that no revision of the profile had been recorded.
+	// ---
+	//  type: string
+	//  shortdesc: Profile revisions the instance was created from
+	"volatile.profiles.revisions": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.rebuild.snapshot)
+	// The snapshot that was taken before the instance was last rebuilt (see `snapshots.rebuild`).
+	// Restore it to revert the rebuild.
+	// ---
+	//  type: string
+	//  shortdesc: Snapshot taken before the last rebuild
+	"volatile.rebuild.snapshot": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.ssh-keys.hash)
+	// Hash of the SSH keys last installed in the instance (see `security.ssh-keys`).
+	// ---
+	//  type: string
+	//  shortdesc: Hash of the installed SSH keys
+	"volatile.ssh-keys.hash": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.ssh-keys.users)
+	// Comma-separated list of the users that SSH keys were last installed for (see `security.ssh-keys`).
+	// ---
+	//  type: string
+	//  shortdesc: Users with installed SSH keys
+	"volatile.ssh-keys.users": validate.IsAny,
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.uuid)
 	// The instance UUID is globally unique across all servers and projects.
 	// ---
@@ -556,6 +690,28 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  condition: container
 	//  shortdesc: Maximum number of processes that can run in the instance
 	"limits.processes": validate.Optional(validate.IsInt64),
+	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.inotify.instances)
+	// If left empty, the limit of the host is used.
+	//
+	// The limit is applied to the user namespace of the container when it starts.
+	// ---
+	//  type: integer
+	//  defaultdesc: empty
+	//  liveupdate: no
+	//  condition: unprivileged container
+	//  shortdesc: Maximum number of inotify instances that can be created in the instance
+	"limits.inotify.instances": validate.Optional(validate.IsUint32),
+	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.inotify.watches)
+	// If left empty, the limit of the host is used.
+	//
+	// The limit is applied to the user namespace of the container when it starts.
+	// ---
+	//  type: integer
+	//  defaultdesc: empty
+	//  liveupdate: no
+	//  condition: unprivileged container
+	//  shortdesc: Maximum number of inotify watches that can be created in the instance
+	"limits.inotify.watches": validate.Optional(validate.IsUint32),
 
 	// lxdmeta:generate(entities=instance; group=miscellaneous; key=linux.kernel_modules)
 	// Specify the kernel modules as a comma-separated list.
@@ -566,18 +722,42 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  liveupdate: yes
 	//  condition: container
 	//  shortdesc: Kernel modules to load or allow loading
-	"linux.kernel_modules": validate.IsAny,
+	"linux.kernel_modules": validate.Optional(validate.IsListOf(ValidKernelModule)),
 
 	// lxdmeta:generate(entities=instance; group=miscellaneous; key=linux.kernel_modules.load)
 	// This option specifies how to load the kernel modules that are specified in {config:option}`instance-miscellaneous:linux.kernel_modules`.
-	// Possible values are `boot` (load the modules when booting the container) and `ondemand` (intercept the `finit_modules()` syscall and allow a privileged user in the container's user namespace to load the modules).
+	// Possible values are `boot` (load the modules when booting the container), `ondemand` (intercept the `finit_modules()` syscall and allow a privileged user in the container's user namespace to load the modules) and `auto` (like `boot`, but also load the modules that provide the sysctls set through `linux.sysctl.*`).
 	// ---
 	//  type: string
 	//  defaultdesc: `boot`
 	//  liveupdate: no
 	//  condition: container
 	//  shortdesc: How to load kernel modules
-	"linux.kernel_modules.load": validate.Optional(validate.IsOneOf("boot", "ondemand")),
+	"linux.kernel_modules.load": validate.Optional(validate.IsOneOf("boot", "ondemand", "auto")),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=linux.time.offset.boottime)
+	// Specify the offset as a duration, for example `-1h` or `30d`.
+	//
+	// When set, the container runs in its own time namespace, and its `CLOCK_BOOTTIME` clock is shifted by the given offset.
+	// See {ref}`instances-time-namespaces` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Offset of the boot time clock of the container
+	"linux.time.offset.boottime": validate.Optional(ValidTimeOffset),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=linux.time.offset.monotonic)
+	// Specify the offset as a duration, for example `-1h` or `30d`.
+	//
+	// When set, the container runs in its own time namespace, and its `CLOCK_MONOTONIC` clock is shifted by the given offset.
+	// See {ref}`instances-time-namespaces` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Offset of the monotonic clock of the container
+	"linux.time.offset.monotonic": validate.Optional(ValidTimeOffset),
 
 	// lxdmeta:generate(entities=instance; group=migration; key=migration.incremental.memory)
 	// Using incremental memory transfer of the instance's memory can reduce downtime.
@@ -844,6 +1024,17 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Whether to use idmapped mounts for syscall interception
 	"security.syscalls.intercept.mount.shift": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.syscalls.intercept.policies)
+	// Specify a comma-separated list of syscall interception policies from the project of the instance.
+	// The policies are defined by the administrator and allow specific device nodes to be created or specific file systems to be mounted.
+	// See {ref}`syscall-interception-policies` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Syscall interception policies to apply
+	"security.syscalls.intercept.policies": validate.Optional(validate.IsListOf(validate.IsURLSegmentSafe)),
+
 	// lxdmeta:generate(entities=instance; group=security; key=security.syscalls.intercept.sched_setscheduler)
 	// This system call allows increasing process priority.
 	// ---
@@ -901,6 +1092,13 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  type: string
 	//  shortdesc: The idmap to use the next time the instance starts
 	"volatile.idmap.next": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.kernel_modules)
+	// Comma-separated list of the kernel modules that were required by the container when it last started.
+	// ---
+	//  type: string
+	//  shortdesc: Kernel modules required by the running container
+	"volatile.kernel_modules": validate.Optional(validate.IsListOf(ValidKernelModule)),
 }
 
 // InstanceConfigKeysVM is a map of config key to validator. (keys applying to VM only).
@@ -945,6 +1143,76 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Addition/override to the generated `qemu.conf` file
 	"raw.qemu.conf": validate.IsAny,
 
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=rtc.base)
+	// Possible values are `utc`, `localtime` or a timestamp in RFC3339 format, for example `2030-01-01T00:00:00Z`.
+	//
+	// With a timestamp, the real time clock of the virtual machine starts at that time on every boot and only advances while the virtual machine runs.
+	// See {ref}`instances-time-namespaces` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `utc`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Start time of the real time clock
+	"rtc.base": validate.Optional(ValidRTCBase),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=rtc.offset)
+	// Specify the offset as a duration, for example `-1h` or `30d`.
+	// The offset is applied to the start time set through {config:option}`instance-miscellaneous:rtc.base`.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Offset of the real time clock
+	"rtc.offset": validate.Optional(ValidTimeOffset),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=clock.kvmclock)
+	// This option only applies to x86_64 virtual machines.
+	// Disable it for guests that should use other clock sources, for example the TSC or the HPET.
+	// See {ref}`instances-vm-clock` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether to expose the `kvmclock` paravirtualized clock source
+	"clock.kvmclock": validate.Optional(validate.IsBool),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=clock.hpet)
+	// This option only applies to x86_64 virtual machines.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether to expose a High Precision Event Timer (HPET)
+	"clock.hpet": validate.Optional(validate.IsBool),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=clock.ptp)
+	// If enabled, the LXD agent loads the `ptp_kvm` kernel module in the guest, which exposes the clock of the host as a PTP device (usually `/dev/ptp0`).
+	// Time daemons like `chrony` can use this device as a reference clock.
+	//
+	// On x86_64, this requires {config:option}`instance-miscellaneous:clock.kvmclock` to be enabled.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether to expose the clock of the host as a PTP device
+	"clock.ptp": validate.Optional(validate.IsBool),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=clock.resync)
+	// If enabled, LXD sets the system clock of the guest through the LXD agent after the virtual machine was paused, restored from a stateful snapshot or stop, live-migrated, or after the host resumed from suspend.
+	//
+	// The clock isn't synchronized if {config:option}`instance-miscellaneous:rtc.base` is set to a timestamp.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to resynchronize the guest clock when it was suspended
+	"clock.resync": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=security; key=security.agent.metrics)
 	//
 	// ---
@@ -1015,6 +1283,17 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: The guest owner's `base64`-encoded session blob
 	"security.sev.session.data": validate.Optional(validate.IsAny),
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.usb_redirection)
+	// When enabled, SPICE clients connected to the VGA console of the virtual machine can redirect local USB devices (for example, smart cards or security keys) into the guest.
+	// In restricted projects, this is only possible if {config:option}`project-restricted:restricted.virtual-machines.usb_redirection` is set to `allow`.
+	// ---
+	//  type: bool
+	//  defaultdesc: `true`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether USB devices can be redirected over SPICE
+	"security.usb_redirection": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=miscellaneous; key=user.*)
 	// User keys can be used in search.
 	// ---
@@ -1033,6 +1312,62 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to use the name and MTU of the default network interfaces
 	"agent.nic_config": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=agent.journal.forward)
+	// When enabled, the `lxd-agent` forwards the `systemd` journal of the guest to the host while the VM is running.
+	// The forwarded entries are appended to the `journal.log` file of the instance and sent as `instance-log` events.
+	//
+	// See {ref}`instances-troubleshoot-journal` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Whether to forward the guest journal to the host
+	"agent.journal.forward": validate.Optional(validate.IsBool),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=agent.journal.priority)
+	// Possible values are `emerg`, `alert`, `crit`, `err`, `warning`, `notice`, `info` and `debug`.
+	// Only the journal entries with this priority or a more important one are forwarded.
+	// ---
+	//  type: string
+	//  defaultdesc: `info`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: Lowest priority of the forwarded journal entries
+	"agent.journal.priority": validate.Optional(validate.IsOneOf("emerg", "alert", "crit", "err", "warning", "notice", "info", "debug")),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=agent.journal.units)
+	// Specify a comma-separated list of `systemd` units, for example, `ssh.service,cron.service`.
+	// If set, only the journal entries of those units are forwarded.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: `systemd` units of the forwarded journal entries
+	"agent.journal.units": validate.Optional(validate.IsListOf(validate.IsAny)),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=freeze.mode)
+	// Possible values are `pause` and `agent`.
+	// With `pause`, QEMU pauses the virtual CPUs of the VM.
+	// With `agent`, the `lxd-agent` freezes all processes in the guest through the cgroup freezer, while the guest kernel keeps running.
+	// The `agent` mode requires the `lxd-agent` to be running and the guest to use cgroup v2.
+	//
+	// See {ref}`instances-manage-freeze` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `pause`
+	//  liveupdate: yes
+	//  condition: virtual machine
+	//  shortdesc: How to freeze the VM
+	"freeze.mode": validate.Optional(validate.IsOneOf("pause", "agent")),
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.last_state.freeze_mode)
+	// Set while the VM is frozen by the `lxd-agent`.
+	// ---
+	//  type: string
+	//  shortdesc: Mode used to freeze the VM
+	"volatile.last_state.freeze_mode": validate.Optional(validate.IsOneOf("agent")),
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.apply_nvram)
 	//
 	// ---
@@ -1211,6 +1546,24 @@ func ConfigKeyChecker(key string, instanceType Type) (func(value string) error,
 		}
 	}
 
+	// lxdmeta:generate(entities=instance; group=cloud-init; key=cloud-init.ssh-keys.<name>)
+	// Specify the key as `<user>:<public key>`.
+	// The public keys are served by the metadata service of bridge networks, see {ref}`network-bridge-metadata`.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: SSH public key served to the instance
+	if strings.HasPrefix(key, "cloud-init.ssh-keys.") {
+		return func(value string) error {
+			user, publicKey, ok := strings.Cut(value, ":")
+			if !ok || user == "" || publicKey == "" {
+				return fmt.Errorf("Invalid SSH key %q, must be in the form <user>:<public key>", value)
+			}
+
+			return nil
+		}, nil
+	}
+
 	if strings.HasPrefix(key, "environment.") {
 		return validate.IsAny, nil
 	}
@@ -1230,7 +1583,9 @@ func ConfigKeyChecker(key string, instanceType Type) (func(value string) error,
 
 	if (instanceType == Any || instanceType == Container) &&
 		strings.HasPrefix(key, "linux.sysctl.") {
-		return validate.IsAny, nil
+		return func(value string) error {
+			return ValidSysctl(key, value)
+		}, nil
 	}
 
 	return nil, fmt.Errorf("Unknown configuration key: %s", key)
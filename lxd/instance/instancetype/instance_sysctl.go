@@ -0,0 +1,127 @@
+package instancetype
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// kernelModuleNameRegex matches the names accepted by modprobe.
+var kernelModuleNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// sysctlNamespacedPrefixes lists the sysctl prefixes that are scoped to one of the namespaces of a container.
+// Any other sysctl would apply to the whole host and to every other instance on it.
+var sysctlNamespacedPrefixes = []string{
+	"net.",
+	"kernel.domainname",
+	"kernel.hostname",
+	"kernel.msg",
+	"kernel.sem",
+	"kernel.shm",
+	"fs.mqueue.",
+	"user.",
+}
+
+// sysctlModules maps sysctl prefixes to the kernel modules that provide them.
+var sysctlModules = map[string][]string{
+	"net.bridge.":       {"br_netfilter"},
+	"net.ipv4.vs.":      {"ip_vs"},
+	"net.netfilter.":    {"nf_conntrack"},
+	"net.nf_conntrack_": {"nf_conntrack"},
+	"net.sctp.":         {"sctp"},
+	"net.ipv6.conf.":    {"ipv6"},
+}
+
+// ValidKernelModule validates a kernel module name.
+func ValidKernelModule(value string) error {
+	if !kernelModuleNameRegex.MatchString(value) {
+		return fmt.Errorf("Invalid kernel module name %q", value)
+	}
+
+	return nil
+}
+
+// ValidSysctl validates a linux.sysctl.* key and its value.
+func ValidSysctl(key string, value string) error {
+	name := strings.TrimPrefix(key, "linux.sysctl.")
+	if name == "" || strings.ContainsAny(name, "/ ") || strings.Contains(name, "..") {
+		return fmt.Errorf("Invalid sysctl %q", name)
+	}
+
+	if !SysctlIsNamespaced(name) {
+		return fmt.Errorf("Sysctl %q isn't namespaced and would conflict with the host and other instances", name)
+	}
+
+	if strings.ContainsAny(value, "\n\r") {
+		return fmt.Errorf("Sysctl %q value must be a single line", name)
+	}
+
+	return nil
+}
+
+// SysctlIsNamespaced returns true if the sysctl only applies to the namespaces of the container.
+func SysctlIsNamespaced(name string) bool {
+	for _, prefix := range sysctlNamespacedPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SysctlModules returns the kernel modules that must be loaded for the sysctl to be available.
+func SysctlModules(name string) []string {
+	for prefix, modules := range sysctlModules {
+		if strings.HasPrefix(name, prefix) {
+			return modules
+		}
+	}
+
+	return nil
+}
+
+// SysctlPath returns the path of the sysctl under /proc/sys.
+func SysctlPath(name string) string {
+	return "/proc/sys/" + strings.ReplaceAll(name, ".", "/")
+}
+
+// KernelModules returns the deduplicated list of kernel modules required by the given config.
+// When the linux.kernel_modules.load policy is auto, this also includes the modules that provide the
+// configured sysctls.
+func KernelModules(config map[string]string) []string {
+	modules := []string{}
+	seen := map[string]bool{}
+
+	add := func(module string) {
+		if module == "" || seen[module] {
+			return
+		}
+
+		seen[module] = true
+		modules = append(modules, module)
+	}
+
+	for _, module := range strings.Split(config["linux.kernel_modules"], ",") {
+		add(strings.TrimSpace(module))
+	}
+
+	if config["linux.kernel_modules.load"] == "auto" {
+		keys := make([]string, 0, len(config))
+		for key := range config {
+			if strings.HasPrefix(key, "linux.sysctl.") {
+				keys = append(keys, key)
+			}
+		}
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, module := range SysctlModules(strings.TrimPrefix(key, "linux.sysctl.")) {
+				add(module)
+			}
+		}
+	}
+
+	return modules
+}
@@ -0,0 +1,58 @@
+package instancetype
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseTimeOffset parses a clock offset.
+// It accepts the units of time.ParseDuration as well as a `d` suffix for days, and must be a whole number of
+// seconds.
+func ParseTimeOffset(value string) (time.Duration, error) {
+	var offset time.Duration
+
+	days, found := strings.CutSuffix(value, "d")
+	if found {
+		count, err := strconv.ParseInt(days, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid number of days %q", days)
+		}
+
+		offset = time.Duration(count) * 24 * time.Hour
+	} else {
+		var err error
+
+		offset, err = time.ParseDuration(value)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if offset%time.Second != 0 {
+		return 0, fmt.Errorf("Offset %q must be a whole number of seconds", value)
+	}
+
+	return offset, nil
+}
+
+// ValidTimeOffset validates a clock offset.
+func ValidTimeOffset(value string) error {
+	_, err := ParseTimeOffset(value)
+	return err
+}
+
+// ValidRTCBase validates the base of the real time clock of a virtual machine.
+func ValidRTCBase(value string) error {
+	if value == "utc" || value == "localtime" {
+		return nil
+	}
+
+	_, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return fmt.Errorf("Invalid value %q, must be one of utc, localtime or a RFC3339 timestamp", value)
+	}
+
+	return nil
+}
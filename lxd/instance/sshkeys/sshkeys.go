@@ -0,0 +1,105 @@
+// Package sshkeys manages the SSH public keys that LXD installs in the authorized_keys files of instances.
+package sshkeys
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// BeginMarker starts the block of keys managed by LXD in authorized_keys files.
+const BeginMarker = "# BEGIN LXD managed keys"
+
+// EndMarker ends the block of keys managed by LXD in authorized_keys files.
+const EndMarker = "# END LXD managed keys"
+
+// User is an entry of the passwd database of an instance.
+type User struct {
+	Name string
+	UID  int64
+	GID  int64
+	Home string
+}
+
+// LookupUser returns the user with the given name from the content of a passwd file.
+func LookupUser(passwd []byte, name string) (*User, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(passwd))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 7 || fields[0] != name {
+			continue
+		}
+
+		uid, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid UID for user %q: %w", name, err)
+		}
+
+		gid, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid GID for user %q: %w", name, err)
+		}
+
+		if fields[5] == "" {
+			return nil, fmt.Errorf("User %q has no home directory", name)
+		}
+
+		return &User{Name: name, UID: uid, GID: gid, Home: fields[5]}, nil
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("User %q not found", name)
+}
+
+// Update returns the given authorized_keys content with its block of managed keys replaced by the given keys.
+// Keys added outside of the block are left untouched. The block is removed when there are no keys.
+func Update(content []byte, keys []string) []byte {
+	var out bytes.Buffer
+
+	inBlock := false
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		switch strings.TrimSpace(line) {
+		case BeginMarker:
+			inBlock = true
+			continue
+		case EndMarker:
+			inBlock = false
+			continue
+		}
+
+		if inBlock || line == "" {
+			continue
+		}
+
+		out.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			out.WriteString("\n")
+		}
+	}
+
+	if len(keys) == 0 {
+		return out.Bytes()
+	}
+
+	out.WriteString(BeginMarker + "\n")
+	for _, key := range keys {
+		out.WriteString(strings.TrimSpace(key) + "\n")
+	}
+
+	out.WriteString(EndMarker + "\n")
+
+	return out.Bytes()
+}
+
+// Selected returns the names of the SSH keys selected by the given expanded instance config.
+func Selected(config map[string]string) []string {
+	return shared.SplitNTrimSpace(config["security.ssh-keys"], ",", -1, true)
+}
@@ -0,0 +1,61 @@
+package sshkeys_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/instance/sshkeys"
+)
+
+func TestLookupUser(t *testing.T) {
+	passwd := []byte("root:x:0:0:root:/root:/bin/bash\nubuntu:x:1000:1000:Ubuntu:/home/ubuntu:/bin/bash\n")
+
+	user, err := sshkeys.LookupUser(passwd, "ubuntu")
+	require.NoError(t, err)
+	assert.Equal(t, &sshkeys.User{Name: "ubuntu", UID: 1000, GID: 1000, Home: "/home/ubuntu"}, user)
+
+	_, err = sshkeys.LookupUser(passwd, "missing")
+	assert.Error(t, err)
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		keys    []string
+		result  string
+	}{
+		{
+			name:    "empty",
+			content: "",
+			keys:    []string{"ssh-ed25519 AAA alice"},
+			result:  "# BEGIN LXD managed keys\nssh-ed25519 AAA alice\n# END LXD managed keys\n",
+		},
+		{
+			name:    "unmanaged keys are kept",
+			content: "ssh-rsa XXX local",
+			keys:    []string{"ssh-ed25519 AAA alice"},
+			result:  "ssh-rsa XXX local\n# BEGIN LXD managed keys\nssh-ed25519 AAA alice\n# END LXD managed keys\n",
+		},
+		{
+			name:    "rotation",
+			content: "ssh-rsa XXX local\n# BEGIN LXD managed keys\nssh-ed25519 AAA alice\n# END LXD managed keys\n",
+			keys:    []string{"ssh-ed25519 BBB alice"},
+			result:  "ssh-rsa XXX local\n# BEGIN LXD managed keys\nssh-ed25519 BBB alice\n# END LXD managed keys\n",
+		},
+		{
+			name:    "revocation",
+			content: "# BEGIN LXD managed keys\nssh-ed25519 AAA alice\n# END LXD managed keys\nssh-rsa XXX local\n",
+			keys:    nil,
+			result:  "ssh-rsa XXX local\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.result, string(sshkeys.Update([]byte(test.content), test.keys)))
+		})
+	}
+}
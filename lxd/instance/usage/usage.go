@@ -0,0 +1,125 @@
+// Package usage keeps a bounded in-memory history of the resource usage of instances.
+package usage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// ring is a fixed size circular buffer of samples.
+type ring struct {
+	samples []api.InstanceUsageSample
+	next    int
+	full    bool
+}
+
+// newRing returns an empty ring able to hold size samples.
+func newRing(size int) *ring {
+	return &ring{samples: make([]api.InstanceUsageSample, size)}
+}
+
+// add records a sample, overwriting the oldest one if the ring is full.
+func (r *ring) add(sample api.InstanceUsageSample) {
+	r.samples[r.next] = sample
+	r.next = (r.next + 1) % len(r.samples)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// list returns the recorded samples, oldest first.
+func (r *ring) list() []api.InstanceUsageSample {
+	if !r.full {
+		return append([]api.InstanceUsageSample(nil), r.samples[:r.next]...)
+	}
+
+	samples := make([]api.InstanceUsageSample, 0, len(r.samples))
+	samples = append(samples, r.samples[r.next:]...)
+	samples = append(samples, r.samples[:r.next]...)
+
+	return samples
+}
+
+// resize returns a ring able to hold size samples which keeps the most recent samples of r.
+func (r *ring) resize(size int) *ring {
+	resized := newRing(size)
+
+	samples := r.list()
+	if len(samples) > size {
+		samples = samples[len(samples)-size:]
+	}
+
+	for _, sample := range samples {
+		resized.add(sample)
+	}
+
+	return resized
+}
+
+// Store holds the usage samples of instances, keyed by instance ID.
+type Store struct {
+	mu    sync.Mutex
+	rings map[int]*ring
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{rings: map[int]*ring{}}
+}
+
+// Add records a sample for the given instance. At most size samples are kept for each instance, the oldest ones
+// being discarded first.
+func (s *Store) Add(instanceID int, sample api.InstanceUsageSample, size int) {
+	if size <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.rings[instanceID]
+	if !ok {
+		r = newRing(size)
+		s.rings[instanceID] = r
+	} else if len(r.samples) != size {
+		r = r.resize(size)
+		s.rings[instanceID] = r
+	}
+
+	r.add(sample)
+}
+
+// Get returns the samples of the given instance recorded after since, oldest first.
+func (s *Store) Get(instanceID int, since time.Time) []api.InstanceUsageSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	samples := []api.InstanceUsageSample{}
+
+	r, ok := s.rings[instanceID]
+	if !ok {
+		return samples
+	}
+
+	for _, sample := range r.list() {
+		if sample.Time.After(since) {
+			samples = append(samples, sample)
+		}
+	}
+
+	return samples
+}
+
+// Prune removes the samples of all instances for which keep returns false.
+func (s *Store) Prune(keep func(instanceID int) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for instanceID := range s.rings {
+		if !keep(instanceID) {
+			delete(s.rings, instanceID)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package usage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/instance/usage"
+	"github.com/canonical/lxd/shared/api"
+)
+
+func sample(start time.Time, i int) api.InstanceUsageSample {
+	return api.InstanceUsageSample{Time: start.Add(time.Duration(i) * time.Minute), CPUUsage: int64(i)}
+}
+
+func cpuUsages(samples []api.InstanceUsageSample) []int64 {
+	usages := make([]int64, 0, len(samples))
+	for _, s := range samples {
+		usages = append(usages, s.CPUUsage)
+	}
+
+	return usages
+}
+
+func TestStore_Get(t *testing.T) {
+	start := time.Now()
+	store := usage.NewStore()
+
+	for i := 1; i <= 3; i++ {
+		store.Add(1, sample(start, i), 5)
+	}
+
+	assert.Equal(t, []int64{1, 2, 3}, cpuUsages(store.Get(1, start)))
+	assert.Equal(t, []int64{3}, cpuUsages(store.Get(1, start.Add(2*time.Minute))))
+	assert.Empty(t, store.Get(2, start))
+}
+
+func TestStore_AddWrapsAround(t *testing.T) {
+	start := time.Now()
+	store := usage.NewStore()
+
+	for i := 1; i <= 7; i++ {
+		store.Add(1, sample(start, i), 3)
+	}
+
+	assert.Equal(t, []int64{5, 6, 7}, cpuUsages(store.Get(1, start)))
+}
+
+func TestStore_AddResizes(t *testing.T) {
+	start := time.Now()
+	store := usage.NewStore()
+
+	for i := 1; i <= 4; i++ {
+		store.Add(1, sample(start, i), 4)
+	}
+
+	// Shrinking keeps the most recent samples.
+	store.Add(1, sample(start, 5), 2)
+	assert.Equal(t, []int64{4, 5}, cpuUsages(store.Get(1, start)))
+
+	// Growing keeps all existing samples.
+	store.Add(1, sample(start, 6), 4)
+	assert.Equal(t, []int64{4, 5, 6}, cpuUsages(store.Get(1, start)))
+}
+
+func TestStore_Prune(t *testing.T) {
+	start := time.Now()
+	store := usage.NewStore()
+
+	store.Add(1, sample(start, 1), 3)
+	store.Add(2, sample(start, 1), 3)
+
+	store.Prune(func(instanceID int) bool { return instanceID == 2 })
+
+	assert.Empty(t, store.Get(1, start))
+	assert.Len(t, store.Get(2, start), 1)
+}
@@ -694,11 +694,12 @@ func instanceBackupExportGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	ent := response.FileResponseEntry{
-		Path: shared.VarPath("backups", "instances", project.Instance(projectName, backup.Name())),
+	ent, err := backupFileResponseEntry(shared.VarPath("backups", "instances", project.Instance(projectName, backup.Name())))
+	if err != nil {
+		return response.SmartError(err)
 	}
 
 	s.Events.SendLifecycle(projectName, lifecycle.InstanceBackupRetrieved.Event(fullName, backup.Instance(), nil))
 
-	return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
+	return response.FileResponse(r, []response.FileResponseEntry{*ent}, nil)
 }
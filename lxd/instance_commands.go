@@ -0,0 +1,693 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// instanceCommandsBootTimeout is how long to wait for a started instance to accept commands before the commands
+// queued for its boot are considered failed. Virtual machines only accept commands once their agent is running.
+const instanceCommandsBootTimeout = 5 * time.Minute
+
+// instanceCommandsLock serializes the changes to the command queues of the local instances.
+var instanceCommandsLock sync.Mutex
+
+// swagger:operation GET /1.0/instances/{name}/commands instances instance_commands_get
+//
+//	Get the queued commands
+//
+//	Returns a list of commands queued to run in the instance (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/instances/foo/commands/3c9bd4a4-8d7c-4a3c-9d79-1f0c1b0e2cd8"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instances/{name}/commands?recursion=1 instances instance_commands_get_recursion1
+//
+//	Get the queued commands
+//
+//	Returns a list of commands queued to run in the instance (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of commands
+//	          items:
+//	            $ref: "#/definitions/InstanceCommand"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceCommandsGet(d *Daemon, r *http.Request) response.Response {
+	inst, resp := instanceCommandsLoadInstance(d.State(), r)
+	if resp != nil {
+		return resp
+	}
+
+	commands, err := instanceCommandsLoad(inst)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if util.IsRecursionRequest(r) {
+		return response.SyncResponse(true, commands)
+	}
+
+	urls := make([]string, 0, len(commands))
+	for _, command := range commands {
+		urls = append(urls, api.NewURL().Path(version.APIVersion, "instances", inst.Name(), "commands", command.ID).String())
+	}
+
+	return response.SyncResponse(true, urls)
+}
+
+// swagger:operation POST /1.0/instances/{name}/commands instances instance_commands_post
+//
+//	Queue a command
+//
+//	Queues a command to run in the instance at its next start or once a scheduled time is reached.
+//	The output of the command is recorded and can be retrieved once it has run.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: command
+//	    description: Command
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceCommandsPost"
+//	responses:
+//	  "200":
+//	    description: Queued command
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceCommand"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceCommandsPost(d *Daemon, r *http.Request) response.Response {
+	req := api.InstanceCommandsPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if len(req.Command) == 0 {
+		return response.BadRequest(fmt.Errorf("A command is required"))
+	}
+
+	switch req.Trigger {
+	case api.InstanceCommandTriggerBoot:
+		if !req.RunAt.IsZero() {
+			return response.BadRequest(fmt.Errorf("Commands triggered by %q can't be scheduled", req.Trigger))
+		}
+
+	case api.InstanceCommandTriggerSchedule:
+		if req.RunAt.IsZero() {
+			return response.BadRequest(fmt.Errorf("Commands triggered by %q require a time to run at", req.Trigger))
+		}
+
+	default:
+		return response.BadRequest(fmt.Errorf("Invalid trigger %q", req.Trigger))
+	}
+
+	inst, resp := instanceCommandsLoadInstance(d.State(), r)
+	if resp != nil {
+		return resp
+	}
+
+	command := api.InstanceCommand{
+		InstanceCommandsPost: req,
+		ID:                   uuid.New().String(),
+		Status:               api.InstanceCommandStatusPending,
+		CreatedAt:            time.Now().UTC(),
+	}
+
+	err = instanceCommandsUpdate(inst, func(commands []api.InstanceCommand) ([]api.InstanceCommand, error) {
+		return append(commands, command), nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	u := api.NewURL().Path(version.APIVersion, "instances", inst.Name(), "commands", command.ID).Project(inst.Project().Name)
+	return response.SyncResponseLocation(true, command, u.String())
+}
+
+// swagger:operation GET /1.0/instances/{name}/commands/{id} instances instance_command_get
+//
+//	Get the queued command
+//
+//	Gets a specific command queued to run in the instance and its result.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Command
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceCommand"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceCommandGet(d *Daemon, r *http.Request) response.Response {
+	inst, resp := instanceCommandsLoadInstance(d.State(), r)
+	if resp != nil {
+		return resp
+	}
+
+	id, err := url.PathUnescape(mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	commands, err := instanceCommandsLoad(inst)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for _, command := range commands {
+		if command.ID == id {
+			return response.SyncResponse(true, command)
+		}
+	}
+
+	return response.NotFound(fmt.Errorf("Command not found"))
+}
+
+// swagger:operation DELETE /1.0/instances/{name}/commands/{id} instances instance_command_delete
+//
+//	Delete the queued command
+//
+//	Removes a command that isn't running from the queue of the instance, along with its recorded output.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceCommandDelete(d *Daemon, r *http.Request) response.Response {
+	inst, resp := instanceCommandsLoadInstance(d.State(), r)
+	if resp != nil {
+		return resp
+	}
+
+	id, err := url.PathUnescape(mux.Vars(r)["id"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = instanceCommandsUpdate(inst, func(commands []api.InstanceCommand) ([]api.InstanceCommand, error) {
+		for i, command := range commands {
+			if command.ID != id {
+				continue
+			}
+
+			if command.Status == api.InstanceCommandStatusRunning {
+				return nil, api.StatusErrorf(http.StatusBadRequest, "Command is running")
+			}
+
+			return append(commands[:i], commands[i+1:]...), nil
+		}
+
+		return nil, api.StatusErrorf(http.StatusNotFound, "Command not found")
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for _, suffix := range []string{"stdout", "stderr"} {
+		err := os.Remove(filepath.Join(inst.ExecOutputPath(), fmt.Sprintf("exec_%s.%s", id, suffix)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return response.SmartError(err)
+		}
+	}
+
+	return response.EmptySyncResponse
+}
+
+// instanceCommandsLoadInstance forwards the request to the member running the instance if needed and otherwise
+// loads the instance. Returns a response if the request was forwarded or failed.
+func instanceCommandsLoadInstance(s *state.State, r *http.Request) (instance.Instance, response.Response) {
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return nil, response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	if resp != nil {
+		return nil, resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return nil, response.SmartError(err)
+	}
+
+	return inst, nil
+}
+
+// instanceCommandsPath returns the path of the file holding the command queue of the instance.
+func instanceCommandsPath(inst instance.Instance) string {
+	return filepath.Join(inst.LogPath(), "commands.json")
+}
+
+// instanceCommandsLoad returns the commands queued for the instance, oldest first.
+func instanceCommandsLoad(inst instance.Instance) ([]api.InstanceCommand, error) {
+	commands := []api.InstanceCommand{}
+
+	content, err := os.ReadFile(instanceCommandsPath(inst))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return commands, nil
+		}
+
+		return nil, fmt.Errorf("Failed reading commands of instance %q: %w", inst.Name(), err)
+	}
+
+	err = json.Unmarshal(content, &commands)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing commands of instance %q: %w", inst.Name(), err)
+	}
+
+	return commands, nil
+}
+
+// instanceCommandsUpdate applies the change function to the command queue of the instance and saves the result.
+func instanceCommandsUpdate(inst instance.Instance, change func(commands []api.InstanceCommand) ([]api.InstanceCommand, error)) error {
+	instanceCommandsLock.Lock()
+	defer instanceCommandsLock.Unlock()
+
+	commands, err := instanceCommandsLoad(inst)
+	if err != nil {
+		return err
+	}
+
+	commands, err = change(commands)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(commands)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(inst.LogPath(), 0700)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so the queue is never left half written.
+	commandsPath := instanceCommandsPath(inst)
+	err = os.WriteFile(commandsPath+".tmp", content, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed writing commands of instance %q: %w", inst.Name(), err)
+	}
+
+	err = os.Rename(commandsPath+".tmp", commandsPath)
+	if err != nil {
+		return fmt.Errorf("Failed writing commands of instance %q: %w", inst.Name(), err)
+	}
+
+	return nil
+}
+
+// instanceCommandClaim marks the oldest pending command of the instance with the given trigger which is due as
+// running and returns it. Returns nil if there is no such command.
+func instanceCommandClaim(inst instance.Instance, trigger string) (*api.InstanceCommand, error) {
+	var claimed *api.InstanceCommand
+
+	err := instanceCommandsUpdate(inst, func(commands []api.InstanceCommand) ([]api.InstanceCommand, error) {
+		now := time.Now().UTC()
+
+		for i, command := range commands {
+			if command.Status != api.InstanceCommandStatusPending || command.Trigger != trigger {
+				continue
+			}
+
+			if trigger == api.InstanceCommandTriggerSchedule && command.RunAt.After(now) {
+				continue
+			}
+
+			commands[i].Status = api.InstanceCommandStatusRunning
+			commands[i].StartedAt = now
+			claimed = &commands[i]
+			break
+		}
+
+		return commands, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claimed == nil {
+		return nil, nil
+	}
+
+	command := *claimed
+	return &command, nil
+}
+
+// instanceCommandExec runs the command in the instance, recording its output, and fills in its result.
+// Returns whether the command was started, as it must not be retried once it was.
+func instanceCommandExec(inst instance.Instance, command *api.InstanceCommand) (bool, error) {
+	execOutputDir := inst.ExecOutputPath()
+	err := os.Mkdir(execOutputDir, 0600)
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		return false, err
+	}
+
+	stdout, err := os.OpenFile(filepath.Join(execOutputDir, fmt.Sprintf("exec_%s.stdout", command.ID)), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() { _ = stdout.Close() }()
+
+	stderr, err := os.OpenFile(filepath.Join(execOutputDir, fmt.Sprintf("exec_%s.stderr", command.ID)), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() { _ = stderr.Close() }()
+
+	post := api.InstanceExecPost{
+		Command:      command.Command,
+		Environment:  map[string]string{},
+		User:         command.User,
+		Group:        command.Group,
+		Cwd:          command.Cwd,
+		RecordOutput: true,
+	}
+
+	for k, v := range command.Environment {
+		post.Environment[k] = v
+	}
+
+	instanceExecEnvironment(inst, &post)
+
+	cmd, err := inst.Exec(post, nil, stdout, stderr)
+	if err != nil {
+		return false, err
+	}
+
+	exitStatus, err := cmd.Wait()
+	if err != nil {
+		return true, err
+	}
+
+	command.ExitCode = exitStatus
+	command.Output = map[string]string{
+		"1": fmt.Sprintf("/%s/instances/%s/logs/exec-output/%s", version.APIVersion, inst.Name(), filepath.Base(stdout.Name())),
+		"2": fmt.Sprintf("/%s/instances/%s/logs/exec-output/%s", version.APIVersion, inst.Name(), filepath.Base(stderr.Name())),
+	}
+
+	return true, nil
+}
+
+// instanceCommandsRun runs the pending commands of the instance with the given trigger which are due, one after
+// the other. Commands which can't be run are retried until the deadline as long as the instance is running.
+func instanceCommandsRun(ctx context.Context, inst instance.Instance, trigger string, deadline time.Time) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	for ctx.Err() == nil {
+		command, err := instanceCommandClaim(inst, trigger)
+		if err != nil {
+			l.Warn("Failed claiming queued command", logger.Ctx{"err": err})
+			return
+		}
+
+		if command == nil {
+			return
+		}
+
+		for {
+			var started bool
+			started, err = instanceCommandExec(inst, command)
+			if started || err == nil || time.Now().After(deadline) || !inst.IsRunning() || ctx.Err() != nil {
+				break
+			}
+
+			time.Sleep(5 * time.Second)
+		}
+
+		command.FinishedAt = time.Now().UTC()
+		command.Status = api.InstanceCommandStatusDone
+		if err != nil {
+			command.Status = api.InstanceCommandStatusFailed
+			command.Error = err.Error()
+			l.Warn("Failed running queued command", logger.Ctx{"command": command.Command, "err": err})
+		}
+
+		err = instanceCommandsUpdate(inst, func(commands []api.InstanceCommand) ([]api.InstanceCommand, error) {
+			for i := range commands {
+				if commands[i].ID == command.ID {
+					commands[i] = *command
+				}
+			}
+
+			return commands, nil
+		})
+		if err != nil {
+			l.Warn("Failed recording result of queued command", logger.Ctx{"err": err})
+			return
+		}
+	}
+}
+
+// instanceCommandsBootHandler returns an internal event handler which runs the commands queued for the boot of
+// the local instances when they start.
+func instanceCommandsBootHandler(d *Daemon) func(event api.Event) {
+	return func(event api.Event) {
+		if event.Type != api.EventTypeLifecycle {
+			return
+		}
+
+		lifecycleEvent := api.EventLifecycle{}
+		err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+		if err != nil {
+			return
+		}
+
+		if lifecycleEvent.Action != api.EventLifecycleInstanceStarted && lifecycleEvent.Action != api.EventLifecycleInstanceRestarted {
+			return
+		}
+
+		u, err := url.Parse(lifecycleEvent.Source)
+		if err != nil {
+			return
+		}
+
+		projectName := event.Project
+		if projectName == "" {
+			projectName = api.ProjectDefaultName
+		}
+
+		inst, err := instance.LoadByProjectAndName(d.State(), projectName, path.Base(u.Path))
+		if err != nil {
+			return
+		}
+
+		if !shared.PathExists(instanceCommandsPath(inst)) {
+			return
+		}
+
+		instanceCommandsRun(d.shutdownCtx, inst, api.InstanceCommandTriggerBoot, time.Now().Add(instanceCommandsBootTimeout))
+	}
+}
+
+// instanceCommandsScheduleTask runs the scheduled commands of the running local instances which are due.
+func instanceCommandsScheduleTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		instances, err := instance.LoadNodeAll(d.State(), instancetype.Any)
+		if err != nil {
+			logger.Warn("Failed loading instances to run their scheduled commands", logger.Ctx{"err": err})
+			return
+		}
+
+		for _, inst := range instances {
+			if !inst.IsRunning() || !shared.PathExists(instanceCommandsPath(inst)) {
+				continue
+			}
+
+			go instanceCommandsRun(ctx, inst, api.InstanceCommandTriggerSchedule, time.Now())
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
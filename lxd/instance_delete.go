@@ -15,6 +15,7 @@ import (
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/version"
 )
 
@@ -84,8 +85,24 @@ func instanceDelete(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Instance is running"))
 	}
 
+	err = authApprovalCheck(r.Context(), s, r, api.AuthApprovalActionDeleteInstance, entity.InstanceURL(projectName, name))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r)
+
 	rmct := func(op *operations.Operation) error {
-		return inst.Delete(false)
+		tombstone := instanceTombstone(inst, requestor)
+
+		err := inst.Delete(false)
+		if err != nil {
+			return err
+		}
+
+		tombstoneRecord(s, entity.TypeInstance, tombstone)
+
+		return nil
 	}
 
 	resources := map[string][]api.URL{}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// instanceDiskEncryptionKeyMaxLength is the maximum length of an escrowed recovery key.
+const instanceDiskEncryptionKeyMaxLength = 4096
+
+// swagger:operation GET /1.0/instances/{name}/disk-encryption-keys instances instance_disk_encryption_keys_get
+//
+//	Get the escrowed disk encryption keys
+//
+//	Returns a list of the recovery keys escrowed by the instance for its encrypted block devices (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/instances/foo/disk-encryption-keys/vda3"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instances/{name}/disk-encryption-keys?recursion=1 instances instance_disk_encryption_keys_get_recursion1
+//
+//	Get the escrowed disk encryption keys
+//
+//	Returns a list of the recovery keys escrowed by the instance for its encrypted block devices (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of escrowed recovery keys
+//	          items:
+//	            $ref: "#/definitions/InstanceDiskEncryptionKey"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceDiskEncryptionKeysGet(d *Daemon, r *http.Request) response.Response {
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var keys []api.InstanceDiskEncryptionKey
+	err = d.State().DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		instanceID, err := tx.GetInstanceID(ctx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		keys, err = tx.GetInstanceDiskEncryptionKeys(ctx, instanceID)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if util.IsRecursionRequest(r) {
+		return response.SyncResponse(true, keys)
+	}
+
+	urls := make([]string, 0, len(keys))
+	for _, key := range keys {
+		urls = append(urls, api.NewURL().Path(version.APIVersion, "instances", name, "disk-encryption-keys", key.Device).String())
+	}
+
+	return response.SyncResponse(true, urls)
+}
+
+// swagger:operation GET /1.0/instances/{name}/disk-encryption-keys/{device} instances instance_disk_encryption_key_get
+//
+//	Get the escrowed disk encryption key
+//
+//	Returns the recovery key escrowed by the instance for one of its encrypted block devices.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Escrowed recovery key
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceDiskEncryptionKey"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceDiskEncryptionKeyGet(d *Daemon, r *http.Request) response.Response {
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	device, err := url.PathUnescape(mux.Vars(r)["device"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var keys []api.InstanceDiskEncryptionKey
+	err = d.State().DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		instanceID, err := tx.GetInstanceID(ctx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		keys, err = tx.GetInstanceDiskEncryptionKeys(ctx, instanceID)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for _, key := range keys {
+		if key.Device == device {
+			return response.SyncResponse(true, key)
+		}
+	}
+
+	return response.NotFound(fmt.Errorf("No recovery key escrowed for device %q", device))
+}
+
+// swagger:operation DELETE /1.0/instances/{name}/disk-encryption-keys/{device} instances instance_disk_encryption_key_delete
+//
+//	Delete the escrowed disk encryption key
+//
+//	Removes the recovery key escrowed by the instance for one of its encrypted block devices.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceDiskEncryptionKeyDelete(d *Daemon, r *http.Request) response.Response {
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	device, err := url.PathUnescape(mux.Vars(r)["device"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = d.State().DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		instanceID, err := tx.GetInstanceID(ctx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		return tx.DeleteInstanceDiskEncryptionKey(ctx, instanceID, device)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// devlxdDiskEncryptionKeysPost lets instances escrow the recovery keys of their encrypted block devices, if their
+// project allows it.
+var devlxdDiskEncryptionKeysPost = devLxdHandler{"/1.0/disk-encryption-keys", func(d *Daemon, c instance.Instance, w http.ResponseWriter, r *http.Request) response.Response {
+	isVM := c.Type() == instancetype.VM
+
+	if r.Method != "POST" {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusMethodNotAllowed, fmt.Sprintf("method %q not allowed", r.Method)), isVM)
+	}
+
+	if shared.IsFalse(c.ExpandedConfig()["security.devlxd"]) || shared.IsFalseOrEmpty(c.Project().Config["instances.disk_encryption.key_escrow"]) {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusForbidden, "not authorized"), isVM)
+	}
+
+	req := api.DevLXDDiskEncryptionKeyPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, err.Error()), isVM)
+	}
+
+	if req.Device == "" || strings.Contains(req.Device, "/") {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, "Invalid device name %q", req.Device), isVM)
+	}
+
+	if req.RecoveryKey == "" || len(req.RecoveryKey) > instanceDiskEncryptionKeyMaxLength {
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusBadRequest, "Recovery key must be between 1 and %d characters long", instanceDiskEncryptionKeyMaxLength), isVM)
+	}
+
+	err = d.State().DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertInstanceDiskEncryptionKey(ctx, c.ID(), req.Device, req.RecoveryKey)
+	})
+	if err != nil {
+		logger.Error("Failed escrowing disk encryption key", logger.Ctx{"project": c.Project().Name, "instance": c.Name(), "device": req.Device, "err": err})
+		return response.DevLxdErrorResponse(api.StatusErrorf(http.StatusInternalServerError, "internal server error"), isVM)
+	}
+
+	logger.Info("Escrowed disk encryption key", logger.Ctx{"project": c.Project().Name, "instance": c.Name(), "device": req.Device})
+
+	return response.DevLxdResponse(http.StatusOK, "", "raw", isVM)
+}}
@@ -599,66 +599,9 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Instance is frozen"))
 	}
 
-	// Process environment.
-	if post.Environment == nil {
-		post.Environment = map[string]string{}
-	}
+	instanceActivityRecordOrLog(inst)
 
-	// Override any environment variable settings from the instance if not manually specified in post.
-	for k, v := range inst.ExpandedConfig() {
-		if strings.HasPrefix(k, "environment.") {
-			envKey := strings.TrimPrefix(k, "environment.")
-			_, found := post.Environment[envKey]
-			if !found {
-				post.Environment[envKey] = v
-			}
-		}
-	}
-
-	// Set default value for PATH.
-	_, ok := post.Environment["PATH"]
-	if !ok {
-		post.Environment["PATH"] = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
-
-		if inst.Type() == instancetype.Container {
-			// Add some additional paths. This directly looks through /proc
-			// rather than use FileExists as none of those paths are expected to be
-			// symlinks and this is much faster than forking a sub-process and
-			// attaching to the instance.
-			extraPaths := map[string]string{
-				"/snap":      "/snap/bin",
-				"/etc/NIXOS": "/run/current-system/sw/bin",
-			}
-
-			instPID := inst.InitPID()
-			for k, v := range extraPaths {
-				if shared.PathExists(fmt.Sprintf("/proc/%d/root%s", instPID, k)) {
-					post.Environment["PATH"] = fmt.Sprintf("%s:%s", post.Environment["PATH"], v)
-				}
-			}
-		}
-	}
-
-	// If running as root, set some env variables.
-	if post.User == 0 {
-		// Set default value for HOME.
-		_, ok = post.Environment["HOME"]
-		if !ok {
-			post.Environment["HOME"] = "/root"
-		}
-
-		// Set default value for USER.
-		_, ok = post.Environment["USER"]
-		if !ok {
-			post.Environment["USER"] = "root"
-		}
-	}
-
-	// Set default value for LANG.
-	_, ok = post.Environment["LANG"]
-	if !ok {
-		post.Environment["LANG"] = "C.UTF-8"
-	}
+	instanceExecEnvironment(inst, &post)
 
 	if post.WaitForWS {
 		ws := &execWs{}
@@ -776,3 +719,68 @@ func instanceExecPost(d *Daemon, r *http.Request) response.Response {
 
 	return operations.OperationResponse(op)
 }
+
+// instanceExecEnvironment fills in the environment of a command run in the instance with the instance's
+// environment.* settings and default values for PATH, HOME, USER and LANG, unless specified in the request.
+func instanceExecEnvironment(inst instance.Instance, post *api.InstanceExecPost) {
+	// Process environment.
+	if post.Environment == nil {
+		post.Environment = map[string]string{}
+	}
+
+	// Override any environment variable settings from the instance if not manually specified in post.
+	for k, v := range inst.ExpandedConfig() {
+		if strings.HasPrefix(k, "environment.") {
+			envKey := strings.TrimPrefix(k, "environment.")
+			_, found := post.Environment[envKey]
+			if !found {
+				post.Environment[envKey] = v
+			}
+		}
+	}
+
+	// Set default value for PATH.
+	_, ok := post.Environment["PATH"]
+	if !ok {
+		post.Environment["PATH"] = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+		if inst.Type() == instancetype.Container {
+			// Add some additional paths. This directly looks through /proc
+			// rather than use FileExists as none of those paths are expected to be
+			// symlinks and this is much faster than forking a sub-process and
+			// attaching to the instance.
+			extraPaths := map[string]string{
+				"/snap":      "/snap/bin",
+				"/etc/NIXOS": "/run/current-system/sw/bin",
+			}
+
+			instPID := inst.InitPID()
+			for k, v := range extraPaths {
+				if shared.PathExists(fmt.Sprintf("/proc/%d/root%s", instPID, k)) {
+					post.Environment["PATH"] = fmt.Sprintf("%s:%s", post.Environment["PATH"], v)
+				}
+			}
+		}
+	}
+
+	// If running as root, set some env variables.
+	if post.User == 0 {
+		// Set default value for HOME.
+		_, ok = post.Environment["HOME"]
+		if !ok {
+			post.Environment["HOME"] = "/root"
+		}
+
+		// Set default value for USER.
+		_, ok = post.Environment["USER"]
+		if !ok {
+			post.Environment["USER"] = "root"
+		}
+	}
+
+	// Set default value for LANG.
+	_, ok = post.Environment["LANG"]
+	if !ok {
+		post.Environment["LANG"] = "C.UTF-8"
+	}
+}
@@ -69,6 +69,8 @@ func instanceFileHandler(d *Daemon, r *http.Request) response.Response {
 		path = "/" + path
 	}
 
+	instanceActivityRecordOrLog(inst)
+
 	switch r.Method {
 	case "GET":
 		return instanceFileGet(s, inst, path, r)
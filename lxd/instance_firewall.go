@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// swagger:operation GET /1.0/instances/{name}/firewall instances instance_firewall_get
+//
+//	Get the firewall rules
+//
+//	Gets the host firewall rules applied for the network and proxy devices of the instance along with the number
+//	of packets and bytes they matched, which helps with debugging connectivity issues.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Firewall rules
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceFirewall"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceFirewallGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	instFirewall := api.InstanceFirewall{
+		Driver: s.Firewall.String(),
+		Rules:  []api.InstanceFirewallRule{},
+	}
+
+	for _, entry := range inst.ExpandedDevices().Sorted() {
+		if !shared.ValueInSlice(entry.Config["type"], []string{"nic", "proxy"}) {
+			continue
+		}
+
+		rules, err := s.Firewall.InstanceRules(inst.Project().Name, inst.Name(), entry.Name)
+		if err != nil {
+			return response.InternalError(fmt.Errorf("Failed getting firewall rules for device %q: %w", entry.Name, err))
+		}
+
+		for _, rule := range rules {
+			instFirewall.Rules = append(instFirewall.Rules, api.InstanceFirewallRule{
+				Device:  entry.Name,
+				Table:   rule.Table,
+				Chain:   rule.Chain,
+				Rule:    rule.Rule,
+				Packets: rule.Packets,
+				Bytes:   rule.Bytes,
+			})
+		}
+	}
+
+	return response.SyncResponse(true, instFirewall)
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// instanceActivityInterval is the minimum interval between two updates of the last activity of an instance.
+// This avoids writing to the database on every request made to a busy instance.
+const instanceActivityInterval = time.Minute
+
+// instanceLease returns the lease of an ephemeral instance, or nil if the instance doesn't have a TTL.
+func instanceLease(inst instance.Instance) (*api.InstanceLease, error) {
+	ttl := inst.ExpandedConfig()["ephemeral.ttl"]
+	if !inst.IsEphemeral() || ttl == "" {
+		return nil, nil
+	}
+
+	// Starting the instance counts as activity.
+	lastActivity := inst.LastUsedDate()
+	if lastActivity.IsZero() {
+		lastActivity = inst.CreationDate()
+	}
+
+	lastActivityStr := inst.LocalConfig()["volatile.last_activity"]
+	if lastActivityStr != "" {
+		recorded, err := time.Parse(time.RFC3339, lastActivityStr)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing last activity of instance %q: %w", inst.Name(), err)
+		}
+
+		if recorded.After(lastActivity) {
+			lastActivity = recorded
+		}
+	}
+
+	expiresAt, err := shared.GetExpiry(lastActivity, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing ephemeral.ttl of instance %q: %w", inst.Name(), err)
+	}
+
+	return &api.InstanceLease{
+		TTL:          ttl,
+		LastActivity: lastActivity,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// instanceActivityRecord records activity on an ephemeral instance that has a TTL, which extends its lease.
+// Unless force is true, the activity is only recorded if the last one is older than instanceActivityInterval.
+func instanceActivityRecord(inst instance.Instance, force bool) error {
+	lease, err := instanceLease(inst)
+	if err != nil {
+		return err
+	}
+
+	if lease == nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	if !force && now.Sub(lease.LastActivity) < instanceActivityInterval {
+		return nil
+	}
+
+	return inst.VolatileSet(map[string]string{"volatile.last_activity": now.Format(time.RFC3339)})
+}
+
+// instanceActivityRecordOrLog records activity on an instance and logs a warning if this fails.
+// Failing to record activity shouldn't fail the request that caused it.
+func instanceActivityRecordOrLog(inst instance.Instance) {
+	err := instanceActivityRecord(inst, false)
+	if err != nil {
+		logger.Warn("Failed recording instance activity", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+	}
+}
+
+// swagger:operation GET /1.0/instances/{name}/lease instances instance_lease_get
+//
+//	Get the lease of an ephemeral instance
+//
+//	Gets the TTL, the last activity and the expiry date of an ephemeral instance that has `ephemeral.ttl` set.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Instance lease
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceLease"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceLeaseGet(d *Daemon, r *http.Request) response.Response {
+	return instanceLeaseHandler(d, r, false)
+}
+
+// swagger:operation POST /1.0/instances/{name}/lease instances instance_lease_post
+//
+//	Renew the lease of an ephemeral instance
+//
+//	Records activity on an ephemeral instance that has `ephemeral.ttl` set, which postpones its deletion by the TTL.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Renewed instance lease
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceLease"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceLeasePost(d *Daemon, r *http.Request) response.Response {
+	return instanceLeaseHandler(d, r, true)
+}
+
+// instanceLeaseHandler returns the lease of an instance, renewing it first if requested.
+func instanceLeaseHandler(d *Daemon, r *http.Request, renew bool) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !inst.IsEphemeral() {
+		return response.BadRequest(fmt.Errorf("Instance %q isn't ephemeral", inst.Name()))
+	}
+
+	if inst.ExpandedConfig()["ephemeral.ttl"] == "" {
+		return response.BadRequest(fmt.Errorf("Instance %q doesn't have %q set", inst.Name(), "ephemeral.ttl"))
+	}
+
+	if renew {
+		err = instanceActivityRecord(inst, true)
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed renewing lease of instance %q: %w", inst.Name(), err))
+		}
+	}
+
+	lease, err := instanceLease(inst)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, lease)
+}
+
+// instancesReapExpired deletes the ephemeral instances on the local member whose lease has expired.
+// Running instances are stopped, which deletes them. Stopped instances are left behind when the deletion on stop
+// failed, for example because LXD was stopped before it could complete, and are deleted directly.
+func instancesReapExpired(ctx context.Context, s *state.State) {
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		logger.Warn("Failed loading instances to reap expired ephemeral instances", logger.Ctx{"err": err})
+		return
+	}
+
+	now := time.Now()
+
+	for _, inst := range instances {
+		if ctx.Err() != nil {
+			return
+		}
+
+		lease, err := instanceLease(inst)
+		if err != nil {
+			logger.Warn("Failed getting instance lease", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			continue
+		}
+
+		if lease == nil || now.Before(lease.ExpiresAt) {
+			continue
+		}
+
+		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "lastActivity": lease.LastActivity})
+
+		if inst.IsRunning() {
+			l.Info("Stopping expired ephemeral instance")
+
+			err = inst.Stop(false)
+			if err != nil {
+				l.Warn("Failed stopping expired ephemeral instance", logger.Ctx{"err": err})
+			}
+
+			continue
+		}
+
+		l.Info("Deleting expired ephemeral instance")
+
+		err = inst.Delete(true)
+		if err != nil {
+			l.Warn("Failed deleting expired ephemeral instance", logger.Ctx{"err": err})
+		}
+	}
+}
+
+func instancesReapExpiredTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		instancesReapExpired(ctx, d.State())
+	}
+
+	return f, task.Every(time.Minute)
+}
@@ -689,6 +689,8 @@ func validLogFileName(fname string) bool {
 		fname == "lxc.conf" ||
 		fname == "qemu.log" ||
 		fname == "qemu.conf" ||
+		fname == "journal.log" ||
+		fname == "journal.log.1" ||
 		strings.HasPrefix(fname, "migration_") ||
 		strings.HasPrefix(fname, "snapshot_")
 }
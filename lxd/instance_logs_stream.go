@@ -0,0 +1,481 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	liblxc "github.com/lxc/go-lxc"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+	"github.com/canonical/lxd/shared/ws"
+)
+
+// instanceLogsStreamHistorySize is the maximum amount of data read from the end of a log file to find the
+// requested number of past lines.
+const instanceLogsStreamHistorySize = 256 * 1024
+
+// instanceLogsStreamInterval is the interval at which log files and the console are polled in follow mode.
+const instanceLogsStreamInterval = 500 * time.Millisecond
+
+var instanceLogsStreamCmd = APIEndpoint{
+	Name: "instanceLogsStream",
+	Path: "instances/{name}/logs/stream",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerLogsStream", Path: "containers/{name}/logs/stream"},
+		{Name: "vmLogsStream", Path: "virtual-machines/{name}/logs/stream"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceLogsStreamGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+}
+
+// swagger:operation GET /1.0/instances/{name}/logs/stream instances instance_logs_stream_get
+//
+//	Stream the instance logs
+//
+//	Upgrades the connection to a websocket on which the log records of the instance are sent as JSON messages.
+//	Containers provide the console and lxc.log sources, virtual machines the qemu.log and journal sources.
+//	Without follow mode, the websocket is closed once the past records of all sources have been sent.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: sources
+//	    description: Comma separated list of sources (defaults to all sources of the instance)
+//	    type: string
+//	    example: console,lxc.log
+//	  - in: query
+//	    name: lines
+//	    description: Number of past lines to send for each source
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: follow
+//	    description: Whether to keep sending new records as they are produced
+//	    type: boolean
+//	    example: true
+//	responses:
+//	  "101":
+//	    description: Switching protocols to websocket
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceLogsStreamGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	lines := 100
+	linesStr := r.FormValue("lines")
+	if linesStr != "" {
+		lines, err = strconv.Atoi(linesStr)
+		if err != nil || lines < 0 {
+			return response.BadRequest(fmt.Errorf("Invalid number of lines %q", linesStr))
+		}
+	}
+
+	follow := shared.IsTrue(r.FormValue("follow"))
+
+	// Forward the websocket if the instance is remote.
+	client, err := cluster.ConnectIfInstanceIsRemote(s, projectName, name, r, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if client != nil {
+		source, err := client.RawWebsocket(strings.TrimPrefix(r.URL.RequestURI(), "/"+version.APIVersion))
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.ManualResponse(func(w http.ResponseWriter) error {
+			defer func() { _ = source.Close() }()
+
+			target, err := ws.Upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return err
+			}
+
+			defer func() { _ = target.Close() }()
+
+			<-ws.Proxy(source, target)
+
+			return nil
+		})
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	allowedSources := []string{api.InstanceLogSourceConsole, api.InstanceLogSourceLXC}
+	if inst.Type() == instancetype.VM {
+		allowedSources = []string{api.InstanceLogSourceQEMU, api.InstanceLogSourceJournal}
+	}
+
+	sources := allowedSources
+	if r.FormValue("sources") != "" {
+		sources = shared.SplitNTrimSpace(r.FormValue("sources"), ",", -1, true)
+		for _, source := range sources {
+			if !shared.ValueInSlice(source, allowedSources) {
+				return response.BadRequest(fmt.Errorf("Log source %q isn't supported by instances of type %q", source, inst.Type().String()))
+			}
+		}
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		conn, err := ws.Upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// Stop streaming when the client goes away.
+		go func() {
+			for {
+				_, _, err := conn.NextReader()
+				if err != nil {
+					cancel()
+					return
+				}
+			}
+		}()
+
+		var writeLock sync.Mutex
+		send := func(record api.InstanceLogRecord) error {
+			writeLock.Lock()
+			defer writeLock.Unlock()
+
+			return conn.WriteJSON(record)
+		}
+
+		wg := sync.WaitGroup{}
+		for _, source := range sources {
+			wg.Add(1)
+			go func(source string) {
+				defer wg.Done()
+
+				err := instanceLogsStreamSource(ctx, inst, source, lines, follow, send)
+				if err != nil && ctx.Err() == nil {
+					logger.Debug("Failed streaming instance log", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "source": source, "err": err})
+					_ = send(api.InstanceLogRecord{Source: source, Timestamp: time.Now(), Error: err.Error()})
+				}
+			}(source)
+		}
+
+		wg.Wait()
+
+		writeLock.Lock()
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		writeLock.Unlock()
+
+		return nil
+	})
+}
+
+// instanceLogsStreamSource sends the records of a log source of the instance until the context is cancelled, or
+// once the past records have been sent when not following the source.
+func instanceLogsStreamSource(ctx context.Context, inst instance.Instance, source string, lines int, follow bool, send func(api.InstanceLogRecord) error) error {
+	sendLines := func(messages []string) error {
+		for _, message := range messages {
+			err := send(api.InstanceLogRecord{Source: source, Timestamp: time.Now(), Message: message})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	switch source {
+	case api.InstanceLogSourceLXC, api.InstanceLogSourceQEMU:
+		return instanceLogsStreamFile(ctx, inst.LogFilePath(), lines, follow, sendLines)
+	case api.InstanceLogSourceConsole:
+		c, ok := inst.(instance.Container)
+		if !ok {
+			return errors.New("The console log is only available for containers")
+		}
+
+		return instanceLogsStreamConsole(ctx, c, lines, follow, sendLines)
+	case api.InstanceLogSourceJournal:
+		vm, ok := inst.(instance.VM)
+		if !ok {
+			return errors.New("The guest journal is only available for virtual machines")
+		}
+
+		return instanceLogsStreamJournal(ctx, vm, lines, follow, send)
+	}
+
+	return fmt.Errorf("Unknown log source %q", source)
+}
+
+// instanceLogsStreamFile sends the last lines of a log file and, in follow mode, the lines appended to it.
+func instanceLogsStreamFile(ctx context.Context, path string, lines int, follow bool, sendLines func([]string) error) error {
+	// Read the past lines from the end of the file.
+	data, offset, err := instanceLogsStreamReadFile(path, -instanceLogsStreamHistorySize)
+	if err != nil {
+		return err
+	}
+
+	history, pending := instanceLogsStreamSplit("", string(data))
+
+	// Skip the first line if it may have been cut.
+	if offset > int64(len(data)) && len(history) > 0 {
+		history = history[1:]
+	}
+
+	if !follow && pending != "" {
+		history = append(history, pending)
+	}
+
+	err = sendLines(history[max(0, len(history)-lines):])
+	if err != nil || !follow {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(instanceLogsStreamInterval):
+		}
+
+		data, newOffset, err := instanceLogsStreamReadFile(path, offset)
+		if err != nil {
+			return err
+		}
+
+		// The file was truncated or re-created, start over.
+		if newOffset < offset {
+			pending = ""
+		}
+
+		offset = newOffset
+
+		var messages []string
+		messages, pending = instanceLogsStreamSplit(pending, string(data))
+
+		err = sendLines(messages)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// instanceLogsStreamReadFile returns the content of the file from the given offset along with its size.
+// A negative offset is relative to the end of the file. A missing file is treated as empty, and the whole file is
+// returned if it's smaller than the offset.
+func instanceLogsStreamReadFile(path string, offset int64) ([]byte, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, 0, nil
+		}
+
+		return nil, 0, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size := fi.Size()
+	if offset < 0 {
+		offset = max(0, size+offset)
+	} else if offset > size {
+		offset = 0
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, size-offset))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return data, size, nil
+}
+
+// instanceLogsStreamConsole sends the last lines of the console of the container and, in follow mode, its new
+// output.
+func instanceLogsStreamConsole(ctx context.Context, c instance.Container, lines int, follow bool, sendLines func([]string) error) error {
+	// The ring buffer is only available while the container is running, otherwise use its last dump on disk.
+	readConsole := func() (string, error) {
+		if c.IsRunning() {
+			content, err := c.ConsoleLog(liblxc.ConsoleLogOptions{ReadLog: true})
+			if err != nil {
+				errno, isErrno := shared.GetErrno(err)
+				if isErrno && errno == unix.ENODATA {
+					return "", nil
+				}
+
+				return "", err
+			}
+
+			return content, nil
+		}
+
+		content, err := os.ReadFile(c.ConsoleBufferLogPath())
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		return string(content), nil
+	}
+
+	content, err := readConsole()
+	if err != nil {
+		return err
+	}
+
+	history, pending := instanceLogsStreamSplit("", content)
+	if !follow && pending != "" {
+		history = append(history, pending)
+	}
+
+	err = sendLines(history[max(0, len(history)-lines):])
+	if err != nil || !follow {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(instanceLogsStreamInterval):
+		}
+
+		newContent, err := readConsole()
+		if err != nil {
+			return err
+		}
+
+		var messages []string
+		messages, pending = instanceLogsStreamSplit(pending, instanceLogsStreamConsoleDiff(content, newContent))
+		content = newContent
+
+		err = sendLines(messages)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// instanceLogsStreamConsoleDiff returns the output added to the console ring buffer between two reads.
+// As the ring buffer wraps around, the new output is located after the end of the previous content.
+func instanceLogsStreamConsoleDiff(previous string, current string) string {
+	if strings.HasPrefix(current, previous) {
+		return current[len(previous):]
+	}
+
+	tail := previous[max(0, len(previous)-256):]
+	if tail != "" {
+		idx := strings.LastIndex(current, tail)
+		if idx >= 0 {
+			return current[idx+len(tail):]
+		}
+	}
+
+	return current
+}
+
+// instanceLogsStreamJournal forwards the records of the guest journal sent by the lxd-agent.
+func instanceLogsStreamJournal(ctx context.Context, vm instance.VM, lines int, follow bool, send func(api.InstanceLogRecord) error) error {
+	conn, err := vm.GuestJournal(lines, follow, "", nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	for {
+		record := api.InstanceLogRecord{}
+
+		err := conn.ReadJSON(&record)
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) || ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("Failed reading the guest journal: %w", err)
+		}
+
+		record.Source = api.InstanceLogSourceJournal
+
+		err = send(record)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// instanceLogsStreamSplit splits the data following the pending partial line into complete lines.
+// The new partial line is returned along with the lines.
+func instanceLogsStreamSplit(pending string, data string) ([]string, string) {
+	parts := strings.Split(pending+data, "\n")
+
+	lines := parts[:len(parts)-1]
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	return lines, parts[len(parts)-1]
+}
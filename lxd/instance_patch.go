@@ -13,14 +13,17 @@ import (
 
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/warningtype"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance"
 	projecthelpers "github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/lxd/warnings"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/osarch"
 )
 
@@ -206,6 +209,11 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	err = authApprovalCheckConfig(r.Context(), s, r, entity.InstanceURL(projectName, name), c.LocalConfig(), c.LocalDevices().CloneNative(), req.Config, req.Devices)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Update container configuration
 	args := db.InstanceArgs{
 		Architecture: architecture,
@@ -217,10 +225,17 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 		Project:      projectName,
 	}
 
+	oldRevision := instanceConfigRevision(c)
+
 	err = c.Update(args, true)
 	if err != nil {
 		return response.SmartError(err)
 	}
 
+	configHistoryRecordOrWarn(s, r, entity.TypeInstance, c.ID(), oldRevision, instanceConfigRevision(c))
+
+	// The instance complies with the project network restrictions now that it has been updated.
+	_ = warnings.ResolveWarningsByNodeAndProjectAndTypeAndEntity(s.DB.Cluster, c.Location(), projectName, warningtype.InstanceNetworkRestricted, entity.TypeInstance, c.ID())
+
 	return response.EmptySyncResponse
 }
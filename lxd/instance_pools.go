@@ -0,0 +1,1009 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/instancepool"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var instancePoolsCmd = APIEndpoint{
+	Path: "instance-pools",
+
+	Get:  APIEndpointAction{Handler: instancePoolsGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewInstances)},
+	Post: APIEndpointAction{Handler: instancePoolsPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanCreateInstances)},
+}
+
+var instancePoolCmd = APIEndpoint{
+	Path: "instance-pools/{pool}",
+
+	Delete: APIEndpointAction{Handler: instancePoolDelete, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanDeleteInstances)},
+	Get:    APIEndpointAction{Handler: instancePoolGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewInstances)},
+	Put:    APIEndpointAction{Handler: instancePoolPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditInstances)},
+	Patch:  APIEndpointAction{Handler: instancePoolPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditInstances)},
+}
+
+var instancePoolInstancesCmd = APIEndpoint{
+	Path: "instance-pools/{pool}/instances",
+
+	Get: APIEndpointAction{Handler: instancePoolInstancesGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewInstances)},
+}
+
+var instancePoolAcquireCmd = APIEndpoint{
+	Path: "instance-pools/{pool}/acquire",
+
+	Post: APIEndpointAction{Handler: instancePoolAcquirePost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanOperateInstances)},
+}
+
+var instancePoolReleaseCmd = APIEndpoint{
+	Path: "instance-pools/{pool}/release",
+
+	Post: APIEndpointAction{Handler: instancePoolReleasePost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanDeleteInstances)},
+}
+
+// instancePoolsRefillMu serializes the refills of instance pools so that concurrent refills don't create more
+// standby instances than needed.
+var instancePoolsRefillMu sync.Mutex
+
+// API endpoints.
+
+// swagger:operation GET /1.0/instance-pools instance-pools instance_pools_get
+//
+//	Get the instance pools
+//
+//	Returns a list of instance pools (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/instance-pools/runners"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instance-pools?recursion=1 instance-pools instance_pools_get_recursion1
+//
+//	Get the instance pools
+//
+//	Returns a list of instance pools (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of instance pools
+//	          items:
+//	            $ref: "#/definitions/InstancePool"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	recursion := util.IsRecursionRequest(r)
+
+	var poolNames []string
+
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		// Get list of instance pools.
+		poolNames, err = tx.GetInstancePools(ctx, projectName)
+
+		return err
+	})
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.InstancePool{}
+	for _, poolName := range poolNames {
+		if !recursion {
+			resultString = append(resultString, api.NewURL().Path(version.APIVersion, "instance-pools", poolName).String())
+		} else {
+			pool, err := instancepool.LoadByName(s, projectName, poolName)
+			if err != nil {
+				continue
+			}
+
+			poolInfo := pool.Info()
+			poolInfo.UsedBy, _ = pool.UsedBy() // Ignore errors in UsedBy, will return nil.
+
+			resultMap = append(resultMap, *poolInfo)
+		}
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/instance-pools instance-pools instance_pools_post
+//
+//	Add an instance pool
+//
+//	Creates a new instance pool and starts creating its standby instances.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: pool
+//	    description: Instance pool
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstancePoolsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	req := api.InstancePoolsPost{}
+
+	// Parse the request into a record.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	_, err = instancepool.LoadByName(s, projectName, req.Name)
+	if err == nil {
+		return response.BadRequest(fmt.Errorf("The instance pool already exists"))
+	}
+
+	// Create the instance pool.
+	err = instancepool.Create(s, projectName, &req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := instancepool.LoadByName(s, projectName, req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	instancePoolRefillAsync(s, pool)
+
+	lc := lifecycle.InstancePoolCreated.Event(pool, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/instance-pools/{pool} instance-pools instance_pool_delete
+//
+//	Delete the instance pool
+//
+//	Removes the instance pool and deletes its standby instances.
+//	Acquired instances are kept.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["pool"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := instancepool.LoadByName(s, projectName, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// The standby instances are located with the template instance.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, pool.Source(), instancetype.Any)
+	if err != nil && !response.IsNotFoundError(err) {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	instancePoolsRefillMu.Lock()
+	defer instancePoolsRefillMu.Unlock()
+
+	poolInstances, err := pool.Instances()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for _, poolInstance := range poolInstances {
+		if poolInstance.Acquired {
+			continue
+		}
+
+		err = instancePoolDeleteInstance(s, projectName, poolInstance.Name)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	err = pool.Delete()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstancePoolDeleted.Event(pool, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/instance-pools/{pool} instance-pools instance_pool_get
+//
+//	Get the instance pool
+//
+//	Gets a specific instance pool.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Instance pool
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstancePool"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["pool"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := instancepool.LoadByName(s, projectName, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info := pool.Info()
+	info.UsedBy, err = pool.UsedBy()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, info, pool.Etag())
+}
+
+// swagger:operation PATCH /1.0/instance-pools/{pool} instance-pools instance_pool_patch
+//
+//	Partially update the instance pool
+//
+//	Updates a subset of the instance pool configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: pool
+//	    description: Instance pool configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstancePoolPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/instance-pools/{pool} instance-pools instance_pool_put
+//
+//	Update the instance pool
+//
+//	Updates the entire instance pool configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: pool
+//	    description: Instance pool configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstancePoolPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["pool"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get the existing instance pool.
+	pool, err := instancepool.LoadByName(s, projectName, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, pool.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.InstancePoolPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if r.Method == http.MethodPatch {
+		if req.Config == nil {
+			req.Config = map[string]string{}
+		}
+
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config.
+		for k, v := range pool.Info().Config {
+			_, ok := req.Config[k]
+			if !ok {
+				req.Config[k] = v
+			}
+		}
+	}
+
+	err = pool.Update(&req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	instancePoolRefillAsync(s, pool)
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstancePoolUpdated.Event(pool, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/instance-pools/{pool}/instances instance-pools instance_pool_instances_get
+//
+//	Get the instances of the instance pool
+//
+//	Returns the standby and acquired instances of the instance pool.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of instances
+//	          items:
+//	            $ref: "#/definitions/InstancePoolInstance"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolInstancesGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["pool"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := instancepool.LoadByName(s, projectName, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	poolInstances, err := pool.Instances()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	result := make([]api.InstancePoolInstance, 0, len(poolInstances))
+	for _, poolInstance := range poolInstances {
+		result = append(result, api.InstancePoolInstance{
+			Name:     poolInstance.Name,
+			Acquired: poolInstance.Acquired,
+		})
+	}
+
+	return response.SyncResponse(true, result)
+}
+
+// swagger:operation POST /1.0/instance-pools/{pool}/acquire instance-pools instance_pool_acquire_post
+//
+//	Acquire an instance
+//
+//	Hands out a standby instance of the instance pool.
+//	The instance is started (or resumed if it was frozen) before it is returned.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Acquired instance
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstancePoolInstance"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolAcquirePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["pool"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := instancepool.LoadByName(s, projectName, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// The standby instances are located with the template instance.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, pool.Source(), instancetype.Any)
+	if err != nil && !response.IsNotFoundError(err) {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	poolInstance, err := pool.Acquire()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Replace the acquired instance in the background.
+	instancePoolRefillAsync(s, pool)
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, poolInstance.Name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if inst.IsFrozen() {
+		err = inst.Unfreeze()
+	} else if !inst.IsRunning() {
+		err = inst.Start(false)
+	}
+
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed starting instance %q: %w", inst.Name(), err))
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstancePoolInstanceAcquired.Event(pool, request.CreateRequestor(r), map[string]any{"instance": inst.Name()}))
+
+	return response.SyncResponseLocation(true, api.InstancePoolInstance{Name: inst.Name(), Acquired: true}, api.NewURL().Path(version.APIVersion, "instances", inst.Name()).Project(projectName).String())
+}
+
+// swagger:operation POST /1.0/instance-pools/{pool}/release instance-pools instance_pool_release_post
+//
+//	Release an instance
+//
+//	Deletes an instance that was acquired from the instance pool.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: instance
+//	    description: Instance to release
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstancePoolReleasePost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instancePoolReleasePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["pool"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := instancepool.LoadByName(s, projectName, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	req := api.InstancePoolReleasePost{}
+
+	// Decode the request.
+	err = json.NewDecoder(bytes.NewReader(body)).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Restore the body in case the request is forwarded.
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	// The acquired instance may have been moved, so forward to the member that hosts it.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, req.Instance, instancetype.Any)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	_, err = pool.Release(req.Instance)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = instancePoolDeleteInstance(s, projectName, req.Instance)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstancePoolInstanceReleased.Event(pool, request.CreateRequestor(r), map[string]any{"instance": req.Instance}))
+
+	return response.EmptySyncResponse
+}
+
+// instancePoolDeleteInstance stops and deletes an instance of an instance pool.
+func instancePoolDeleteInstance(s *state.State, projectName string, instanceName string) error {
+	inst, err := instance.LoadByProjectAndName(s, projectName, instanceName)
+	if err != nil {
+		if response.IsNotFoundError(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if inst.IsRunning() {
+		err = inst.Stop(false)
+		if err != nil {
+			return fmt.Errorf("Failed stopping instance %q: %w", instanceName, err)
+		}
+	}
+
+	err = inst.Delete(true)
+	if err != nil {
+		return fmt.Errorf("Failed deleting instance %q: %w", instanceName, err)
+	}
+
+	return nil
+}
+
+// instancePoolCreateStandby creates a new standby instance of the pool by copying the template instance.
+func instancePoolCreateStandby(s *state.State, pool instancepool.InstancePool, source instance.Instance) error {
+	suffix, err := shared.RandomCryptoString()
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s", pool.Info().Name, suffix[:instancepool.NameSuffixLength])
+
+	// Copy the template instance config in the same way as for instance copies.
+	config := make(map[string]string)
+	for key, value := range source.LocalConfig() {
+		if !instancetype.InstanceIncludeWhenCopying(key, false) {
+			continue
+		}
+
+		config[key] = value
+	}
+
+	args := db.InstanceArgs{
+		Project:      pool.Project(),
+		Architecture: source.Architecture(),
+		Config:       config,
+		Type:         source.Type(),
+		Description:  source.Description(),
+		Devices:      source.LocalDevices().Clone(),
+		Name:         name,
+		Profiles:     source.Profiles(),
+	}
+
+	inst, err := instanceCreateAsCopy(s, instanceCreateAsCopyOpts{
+		sourceInstance:       source,
+		targetInstance:       args,
+		instanceOnly:         true,
+		applyTemplateTrigger: true,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { _ = instancePoolDeleteInstance(s, pool.Project(), name) })
+
+	if pool.StandbyState() == instancepool.StandbyStateFrozen {
+		err = inst.Start(false)
+		if err != nil {
+			return fmt.Errorf("Failed starting instance %q: %w", name, err)
+		}
+
+		err = inst.Freeze()
+		if err != nil {
+			return fmt.Errorf("Failed freezing instance %q: %w", name, err)
+		}
+	}
+
+	err = pool.AddInstance(int64(inst.ID()))
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// instancePoolRefill creates or deletes standby instances so that the pool has as many standby instances as its
+// configured size. Only the cluster member that hosts the template instance refills the pool.
+func instancePoolRefill(s *state.State, pool instancepool.InstancePool) error {
+	instancePoolsRefillMu.Lock()
+	defer instancePoolsRefillMu.Unlock()
+
+	source, err := instance.LoadByProjectAndName(s, pool.Project(), pool.Source())
+	if err != nil {
+		return fmt.Errorf("Failed loading template instance %q: %w", pool.Source(), err)
+	}
+
+	if s.ServerClustered && source.Location() != s.ServerName {
+		return nil
+	}
+
+	poolInstances, err := pool.Instances()
+	if err != nil {
+		return err
+	}
+
+	standby := make([]db.InstancePoolInstance, 0, len(poolInstances))
+	for _, poolInstance := range poolInstances {
+		if !poolInstance.Acquired {
+			standby = append(standby, poolInstance)
+		}
+	}
+
+	// Delete the newest standby instances if the pool has been shrunk.
+	for len(standby) > pool.Size() {
+		poolInstance := standby[len(standby)-1]
+		standby = standby[:len(standby)-1]
+
+		removed, err := pool.RemoveStandby(poolInstance.InstanceID)
+		if err != nil {
+			return err
+		}
+
+		// Skip instances that have been acquired in the meantime.
+		if !removed {
+			continue
+		}
+
+		err = instancePoolDeleteInstance(s, pool.Project(), poolInstance.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := len(standby); i < pool.Size(); i++ {
+		err = instancePoolCreateStandby(s, pool, source)
+		if err != nil {
+			return fmt.Errorf("Failed creating standby instance: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// instancePoolRefillAsync refills the pool in the background.
+func instancePoolRefillAsync(s *state.State, pool instancepool.InstancePool) {
+	go func() {
+		err := instancePoolRefill(s, pool)
+		if err != nil {
+			logger.Warn("Failed refilling instance pool", logger.Ctx{"project": pool.Project(), "pool": pool.Info().Name, "err": err})
+		}
+	}()
+}
+
+func instancePoolsRefillTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		// Don't create instances on an evacuated cluster member.
+		if s.DB.Cluster.LocalNodeIsEvacuated() {
+			return
+		}
+
+		var projectPools map[string][]string
+
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+
+			projectPools, err = tx.GetAllInstancePools(ctx)
+
+			return err
+		})
+		if err != nil {
+			logger.Error("Failed loading instance pools", logger.Ctx{"err": err})
+			return
+		}
+
+		for projectName, poolNames := range projectPools {
+			for _, poolName := range poolNames {
+				pool, err := instancepool.LoadByName(s, projectName, poolName)
+				if err != nil {
+					logger.Warn("Failed loading instance pool", logger.Ctx{"project": projectName, "pool": poolName, "err": err})
+					continue
+				}
+
+				err = instancePoolRefill(s, pool)
+				if err != nil {
+					logger.Warn("Failed refilling instance pool", logger.Ctx{"project": projectName, "pool": poolName, "err": err})
+				}
+			}
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/rsync"
 	"github.com/canonical/lxd/lxd/scriptlet"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
@@ -31,6 +32,7 @@ import (
 	apiScriptlet "github.com/canonical/lxd/shared/api/scriptlet"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/version"
 )
 
@@ -216,7 +218,16 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				return err
 			}
 
-			if targetMemberInfo == nil {
+			if targetMemberInfo != nil {
+				supported, err := targetMemberInfo.SupportsArchitecture([]int{inst.Architecture()})
+				if err != nil {
+					return err
+				}
+
+				if !supported {
+					return api.StatusErrorf(http.StatusBadRequest, "Cluster member %q doesn't support the %s architecture of the instance", targetMemberInfo.Name, architecturesString([]int{inst.Architecture()}))
+				}
+			} else {
 				clusterGroupsAllowed := project.GetRestrictedClusterGroups(targetProject)
 
 				candidateMembers, err = tx.GetCandidateMembers(ctx, allMembers, []int{inst.Architecture()}, targetGroupName, clusterGroupsAllowed, s.GlobalConfig.OfflineThreshold())
@@ -278,7 +289,7 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 		}
 
 		if targetMemberInfo.IsOffline(s.GlobalConfig.OfflineThreshold()) {
-			return response.BadRequest(fmt.Errorf("Target cluster member is offline"))
+			return response.SmartError(api.ReasonErrorf(api.ErrorReasonClusterMemberOffline, map[string]any{"member": targetMemberInfo.Name}, "Target cluster member is offline"))
 		}
 	}
 
@@ -320,6 +331,11 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	if req.Migration {
+		// Migration to a remote server.
+		if req.Remote != nil {
+			return instancePostRemote(d, r, inst, req)
+		}
+
 		// Server-side instance migration.
 		if req.Pool != "" || req.Project != "" {
 			// Check if user has access to target project.
@@ -328,6 +344,13 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				if err != nil {
 					return response.SmartError(err)
 				}
+
+				err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+					return instanceNameCheckUnique(ctx, s, tx, req.Name, projectName, req.Project)
+				})
+				if err != nil {
+					return response.SmartError(err)
+				}
 			}
 
 			// Setup the instance move operation.
@@ -381,12 +404,20 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			return operations.OperationResponse(op)
 		}
 
+		_, _, err = rsync.ParseCompression(req.CompressionAlgorithm)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
 		instanceOnly := req.InstanceOnly || req.ContainerOnly
 		ws, err := newMigrationSource(inst, req.Live, instanceOnly, req.AllowInconsistent, "", req.Target)
 		if err != nil {
 			return response.InternalError(err)
 		}
 
+		ws.compressionAlgorithm = req.CompressionAlgorithm
+		ws.verifyChecksums = req.VerifyChecksums
+
 		resources := map[string][]api.URL{}
 		resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name)}
 
@@ -427,11 +458,16 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		// Check that the name isn't already in use.
 		id, _ = tx.GetInstanceID(ctx, projectName, req.Name)
+		if id > 0 {
+			return nil
+		}
 
-		return nil
+		return instanceNameCheckUnique(ctx, s, tx, req.Name, projectName)
 	})
 	if id > 0 {
 		return response.Conflict(fmt.Errorf("Name %q already in use", req.Name))
+	} else if err != nil {
+		return response.SmartError(err)
 	}
 
 	run := func(*operations.Operation) error {
@@ -470,10 +506,6 @@ func instancePostMigration(s *state.State, inst instance.Instance, newName strin
 		}
 
 		statefulStart = true
-		err := inst.Stop(true)
-		if err != nil {
-			return err
-		}
 	}
 
 	// Copy config from instance to avoid modifying it.
@@ -594,24 +626,75 @@ func instancePostMigration(s *state.State, inst instance.Instance, newName strin
 		}
 	}
 
-	// Copy instance to new target instance.
-	targetInst, err := instanceCreateAsCopy(s, instanceCreateAsCopyOpts{
+	copyOpts := instanceCreateAsCopyOpts{
 		sourceInstance:       inst,
 		targetInstance:       args,
 		instanceOnly:         instanceOnly,
 		applyTemplateTrigger: false, // Don't apply templates when moving.
 		allowInconsistent:    allowInconsistent,
-	}, op)
+	}
+
+	moveProgress := func(stage string) {
+		_ = op.UpdateMetadata(map[string]any{"move_progress": stage})
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// Containers are copied while still running so that only the changes made since then need to be
+	// transferred once stopped, keeping the downtime short. This doesn't apply to virtual machines as
+	// refreshing a block volume transfers it in full again.
+	preCopy := statefulStart && inst.Type() == instancetype.Container
+	if preCopy {
+		moveProgress("Copying running instance")
+
+		preCopyOpts := copyOpts
+		preCopyOpts.allowInconsistent = true // Files can change while the instance is running.
+
+		targetInst, err := instanceCreateAsCopy(s, preCopyOpts, op)
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() { _ = targetInst.Delete(true) })
+		copyOpts.refresh = true
+	}
+
+	if statefulStart {
+		moveProgress("Stopping instance")
+
+		err = inst.Stop(true)
+		if err != nil {
+			return err
+		}
+
+		reverter.Add(func() { _ = inst.Start(true) })
+	}
+
+	if copyOpts.refresh {
+		moveProgress("Synchronizing changes")
+	} else {
+		moveProgress("Copying instance")
+	}
+
+	// Copy instance to new target instance.
+	targetInst, err := instanceCreateAsCopy(s, copyOpts, op)
 	if err != nil {
 		return err
 	}
 
+	if !copyOpts.refresh {
+		reverter.Add(func() { _ = targetInst.Delete(true) })
+	}
+
 	// Delete original instance.
 	err = inst.Delete(true)
 	if err != nil {
 		return err
 	}
 
+	reverter.Success()
+
 	// Rename copy from temporary name to original name if needed.
 	if newName == inst.Name() && newProject == inst.Project().Name {
 		err = targetInst.Rename(newName, false) // Don't apply templates when moving.
@@ -621,6 +704,8 @@ func instancePostMigration(s *state.State, inst instance.Instance, newName strin
 	}
 
 	if statefulStart {
+		moveProgress("Starting instance")
+
 		err = targetInst.Start(true)
 		if err != nil {
 			return err
@@ -637,7 +722,7 @@ func instancePostClusteringMigrate(s *state.State, r *http.Request, srcPool stor
 	// Make sure that the source member is online if we end up being called from another member after a
 	// redirection due to the source member being offline.
 	if srcMemberOffline {
-		return nil, fmt.Errorf("The cluster member hosting the instance is offline")
+		return nil, api.ReasonErrorf(api.ErrorReasonClusterMemberOffline, map[string]any{"member": srcMember.Name}, "The cluster member hosting the instance is offline")
 	}
 
 	// Save the original value of the "volatile.apply_template" config key,
@@ -948,6 +1033,7 @@ func migrateInstance(s *state.State, r *http.Request, inst instance.Instance, ta
 
 	var err error
 	var srcMember, newMember db.NodeInfo
+	var srcVersions, newVersions map[string]string
 
 	// If the source member is online then get its address so we can connect to it and see if the
 	// instance is running later.
@@ -962,6 +1048,16 @@ func migrateInstance(s *state.State, r *http.Request, inst instance.Instance, ta
 			return fmt.Errorf("Failed loading new cluster member for instance: %w", err)
 		}
 
+		srcVersions, err = tx.GetNodeVersions(ctx, srcMember.ID)
+		if err != nil {
+			return err
+		}
+
+		newVersions, err = tx.GetNodeVersions(ctx, newMember.ID)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -975,6 +1071,21 @@ func migrateInstance(s *state.State, r *http.Request, inst instance.Instance, ta
 				return fmt.Errorf("Cannot live migrate instance with attached custom volume")
 			}
 		}
+
+		// Check that the versions of both members are compatible.
+		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "source": srcMember.Name, "target": newMember.Name})
+		skewWarnings, err := cluster.CheckLiveMigrationVersions(inst.Type(), srcVersions, newVersions)
+		for _, warning := range skewWarnings {
+			l.Warn("Live migration across version differences", logger.Ctx{"warning": warning})
+		}
+
+		if err != nil {
+			if !req.AllowVersionSkew {
+				return api.StatusErrorf(http.StatusBadRequest, "%w (set allow_version_skew to override)", err)
+			}
+
+			l.Warn("Ignoring unsafe version difference for live migration", logger.Ctx{"err": err})
+		}
 	}
 
 	// Retrieve storage pool of the source instance.
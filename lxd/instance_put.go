@@ -13,6 +13,7 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/db/warningtype"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
@@ -22,8 +23,10 @@ import (
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/lxd/warnings"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/osarch"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/version"
@@ -154,6 +157,11 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
+		err = authApprovalCheckConfig(r.Context(), s, r, entity.InstanceURL(projectName, name), inst.LocalConfig(), inst.LocalDevices().CloneNative(), configRaw.Config, configRaw.Devices)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		// Update container configuration
 		do = func(op *operations.Operation) error {
 			defer unlock()
@@ -168,11 +176,18 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 				Project:      projectName,
 			}
 
+			oldRevision := instanceConfigRevision(inst)
+
 			err = inst.Update(args, true)
 			if err != nil {
 				return err
 			}
 
+			configHistoryRecordOrWarn(s, r, entity.TypeInstance, inst.ID(), oldRevision, instanceConfigRevision(inst))
+
+			// The instance complies with the project network restrictions now that it has been updated.
+			_ = warnings.ResolveWarningsByNodeAndProjectAndTypeAndEntity(s.DB.Cluster, inst.Location(), projectName, warningtype.InstanceNetworkRestricted, entity.TypeInstance, inst.ID())
+
 			return nil
 		}
 
@@ -136,22 +136,43 @@ func instanceRebuildPost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	run := func(op *operations.Operation) error {
+		if req.Source.Type != "none" {
+			if req.Source.Server != "" {
+				sourceImage, err = ensureDownloadedImageFitWithinBudget(s, r, op, *targetProject, sourceImageRef, req.Source, inst.Type().String())
+				if err != nil {
+					return err
+				}
+			}
+
+			if sourceImage == nil {
+				return fmt.Errorf("Image not provided for instance rebuild")
+			}
+		}
+
+		snapshotName, err := instanceRebuildSnapshot(s, inst)
+		if err != nil {
+			return err
+		}
+
 		if req.Source.Type == "none" {
-			return instanceRebuildFromEmpty(inst, op)
+			err = instanceRebuildFromEmpty(inst, op)
+		} else {
+			err = instanceRebuildFromImage(s, r, inst, sourceImage, op)
 		}
 
-		if req.Source.Server != "" {
-			sourceImage, err = ensureDownloadedImageFitWithinBudget(s, r, op, *targetProject, sourceImageRef, req.Source, inst.Type().String())
-			if err != nil {
-				return err
-			}
+		if err != nil {
+			return err
 		}
 
-		if sourceImage == nil {
-			return fmt.Errorf("Image not provided for instance rebuild")
+		// Record the snapshot to restore in order to revert the rebuild.
+		if snapshotName != "" {
+			err = inst.VolatileSet(map[string]string{"volatile.rebuild.snapshot": snapshotName})
+			if err != nil {
+				return fmt.Errorf("Failed recording rebuild snapshot: %w", err)
+			}
 		}
 
-		return instanceRebuildFromImage(s, r, inst, sourceImage, op)
+		return nil
 	}
 
 	resources := map[string][]api.URL{}
@@ -88,8 +88,14 @@ func instanceSFTPHandler(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
+		instanceActivityRecordOrLog(inst)
+
 		resp.instConn, err = inst.FileSFTPConn()
 		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotImplemented) {
+				return response.SmartError(err)
+			}
+
 			return response.SmartError(api.StatusErrorf(http.StatusInternalServerError, "Failed getting instance SFTP connection: %w", err))
 		}
 	}
@@ -272,7 +272,7 @@ func instanceSnapshotsPost(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
-		return nil
+		return project.CheckDiskUsage(ctx, tx, p, 0)
 	})
 	if err != nil {
 		return response.SmartError(err)
@@ -0,0 +1,613 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/resources"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/lxd/storage/filesystem"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+var instanceTopologyCmd = APIEndpoint{
+	Name: "instanceTopology",
+	Path: "instances/{name}/topology",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerTopology", Path: "containers/{name}/topology"},
+		{Name: "vmTopology", Path: "virtual-machines/{name}/topology"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceTopologyGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+}
+
+// swagger:operation GET /1.0/instances/{name}/topology instances instance_topology_get
+//
+//	Get the instance topology
+//
+//	Gets how the instance is placed on the host resources: the host CPU threads and NUMA nodes it runs on and
+//	allocates memory on, the host disks backing its disks and the host network cards its NICs go through.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Instance topology
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceTopology"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceTopologyGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	topology := api.InstanceTopology{
+		CPU: api.InstanceTopologyCPU{
+			Pinned:    instanceTopologyPinned(inst.ExpandedConfig()),
+			Threads:   []int64{},
+			NUMANodes: []uint64{},
+		},
+		Memory: api.InstanceTopologyMemory{
+			NUMANodes: []uint64{},
+		},
+		Disks: []api.InstanceTopologyDisk{},
+		NICs:  []api.InstanceTopologyNIC{},
+	}
+
+	if inst.IsRunning() {
+		cpus, err := resources.GetCPU()
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed getting host CPUs: %w", err))
+		}
+
+		err = instanceTopologyCPUAndMemory(inst, cpus, &topology)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	storage, err := resources.GetStorage()
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed getting host storage: %w", err))
+	}
+
+	networkCards, err := resources.GetNetwork()
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed getting host network cards: %w", err))
+	}
+
+	p := inst.Project()
+	networkProjectName := project.NetworkProjectFromRecord(&p)
+
+	for _, dev := range inst.ExpandedDevices().Sorted() {
+		switch dev.Config["type"] {
+		case "disk":
+			disk, ok := instanceTopologyDisk(s, dev.Name, dev.Config, storage)
+			if ok {
+				topology.Disks = append(topology.Disks, disk)
+			}
+
+		case "nic":
+			nic, err := instanceTopologyNIC(s, networkProjectName, dev.Name, dev.Config, networkCards)
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			topology.NICs = append(topology.NICs, nic)
+		}
+	}
+
+	return response.SyncResponse(true, topology)
+}
+
+// instanceTopologyPinned returns whether the instance configuration restricts it to specific CPU threads or NUMA
+// nodes, rather than to a number of CPUs balanced across the host.
+func instanceTopologyPinned(config map[string]string) bool {
+	if config["limits.cpu.nodes"] != "" {
+		return true
+	}
+
+	limit := config["limits.cpu"]
+	if limit == "" {
+		return false
+	}
+
+	_, err := strconv.Atoi(limit)
+	return err != nil
+}
+
+// instanceTopologyCPUAndMemory fills the CPU threads and NUMA nodes the running instance is allowed to use.
+func instanceTopologyCPUAndMemory(inst instance.Instance, cpus *api.ResourcesCPU, topology *api.InstanceTopology) error {
+	pid := inst.InitPID()
+	if pid <= 0 {
+		return nil
+	}
+
+	// Map the host CPU threads to their NUMA node.
+	threadNodes := map[int64]uint64{}
+	for _, socket := range cpus.Sockets {
+		for _, core := range socket.Cores {
+			for _, thread := range core.Threads {
+				threadNodes[thread.ID] = thread.NUMANode
+			}
+		}
+	}
+
+	status, err := instanceTopologyProcStatus(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return err
+	}
+
+	threads, err := resources.ParseCpuset(status["Cpus_allowed_list"])
+	if err != nil {
+		return err
+	}
+
+	// The main QEMU process isn't pinned, only the threads running the virtual CPUs are.
+	if inst.Type() == instancetype.VM {
+		vm, ok := inst.(instance.VM)
+		if !ok {
+			return fmt.Errorf("Instance is not a VM")
+		}
+
+		vcpuThreads, err := vm.VCPUThreads()
+		if err != nil {
+			return err
+		}
+
+		threads = nil
+		topology.CPU.VCPUs = make(map[int][]int64, len(vcpuThreads))
+		for vcpu, tid := range vcpuThreads {
+			status, err := instanceTopologyProcStatus(fmt.Sprintf("/proc/%d/task/%d/status", pid, tid))
+			if err != nil {
+				return err
+			}
+
+			vcpuAllowed, err := resources.ParseCpuset(status["Cpus_allowed_list"])
+			if err != nil {
+				return err
+			}
+
+			topology.CPU.VCPUs[vcpu] = vcpuAllowed
+			threads = append(threads, vcpuAllowed...)
+		}
+
+		topology.Memory.Usage, err = instanceTopologyNUMAUsage(fmt.Sprintf("/proc/%d/numa_maps", pid))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, thread := range threads {
+		if !shared.ValueInSlice(thread, topology.CPU.Threads) {
+			topology.CPU.Threads = append(topology.CPU.Threads, thread)
+		}
+
+		node, ok := threadNodes[thread]
+		if ok && !shared.ValueInSlice(node, topology.CPU.NUMANodes) {
+			topology.CPU.NUMANodes = append(topology.CPU.NUMANodes, node)
+		}
+	}
+
+	sort.Slice(topology.CPU.Threads, func(i, j int) bool { return topology.CPU.Threads[i] < topology.CPU.Threads[j] })
+	sort.Slice(topology.CPU.NUMANodes, func(i, j int) bool { return topology.CPU.NUMANodes[i] < topology.CPU.NUMANodes[j] })
+
+	memNodes, err := resources.ParseNumaNodeSet(status["Mems_allowed_list"])
+	if err != nil {
+		return err
+	}
+
+	for _, node := range memNodes {
+		topology.Memory.NUMANodes = append(topology.Memory.NUMANodes, uint64(node))
+	}
+
+	return nil
+}
+
+// instanceTopologyProcStatus returns the fields of a process or thread status file.
+func instanceTopologyProcStatus(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	fields := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if found {
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return fields, scanner.Err()
+}
+
+// instanceTopologyNUMAUsage returns the memory mapped by a process on each NUMA node, in bytes.
+func instanceTopologyNUMAUsage(path string) (map[uint64]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	usage := map[uint64]uint64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		pageSize := uint64(4096)
+		pages := map[uint64]uint64{}
+
+		// Lines look like "7f0000000000 bind:0 anon=262144 dirty=262144 N0=262144 kernelpagesize_kB=4".
+		for _, field := range strings.Fields(scanner.Text()) {
+			key, value, found := strings.Cut(field, "=")
+			if !found {
+				continue
+			}
+
+			if key == "kernelpagesize_kB" {
+				size, err := strconv.ParseUint(value, 10, 64)
+				if err == nil {
+					pageSize = size * 1024
+				}
+
+				continue
+			}
+
+			if !strings.HasPrefix(key, "N") {
+				continue
+			}
+
+			node, err := strconv.ParseUint(strings.TrimPrefix(key, "N"), 10, 64)
+			if err != nil {
+				continue
+			}
+
+			count, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			pages[node] += count
+		}
+
+		for node, count := range pages {
+			usage[node] += count * pageSize
+		}
+	}
+
+	return usage, scanner.Err()
+}
+
+// instanceTopologyDisk returns the host disks backing a disk device, and false if the device isn't backed by
+// storage (for example cloud-init config drives or host sockets).
+func instanceTopologyDisk(s *state.State, name string, config map[string]string, storage *api.ResourcesStorage) (api.InstanceTopologyDisk, bool) {
+	disk := api.InstanceTopologyDisk{
+		Name:      name,
+		Pool:      config["pool"],
+		Devices:   []string{},
+		NUMANodes: []uint64{},
+	}
+
+	var paths []string
+	var err error
+
+	if disk.Pool != "" {
+		var pool storagePools.Pool
+		pool, err = storagePools.LoadByName(s, disk.Pool)
+		if err == nil {
+			disk.Driver = pool.Driver().Info().Name
+			disk.Remote = pool.Driver().Info().Remote
+			if !disk.Remote {
+				paths, err = instanceTopologyPoolPaths(pool)
+			}
+		}
+	} else if filepath.IsAbs(config["source"]) {
+		disk.Source = config["source"]
+		paths = []string{disk.Source}
+	} else {
+		return disk, false
+	}
+
+	if err != nil {
+		disk.Error = err.Error()
+		return disk, true
+	}
+
+	for _, path := range paths {
+		for _, devName := range instanceTopologyPathDisks(path, 0) {
+			if !shared.ValueInSlice(devName, disk.Devices) {
+				disk.Devices = append(disk.Devices, devName)
+			}
+		}
+	}
+
+	sort.Strings(disk.Devices)
+
+	for _, hostDisk := range storage.Disks {
+		if shared.ValueInSlice(hostDisk.ID, disk.Devices) && !shared.ValueInSlice(hostDisk.NUMANode, disk.NUMANodes) {
+			disk.NUMANodes = append(disk.NUMANodes, hostDisk.NUMANode)
+		}
+	}
+
+	sort.Slice(disk.NUMANodes, func(i, j int) bool { return disk.NUMANodes[i] < disk.NUMANodes[j] })
+
+	return disk, true
+}
+
+// instanceTopologyPoolPaths returns the block devices or files making up a local storage pool, or the mount path
+// of the pool for the drivers storing volumes on an existing filesystem.
+func instanceTopologyPoolPaths(pool storagePools.Pool) ([]string, error) {
+	config := pool.Driver().Config()
+
+	switch pool.Driver().Info().Name {
+	case "zfs":
+		zpoolName := config["zfs.pool_name"]
+		out, err := shared.RunCommand("zpool", "list", "-H", "-P", "-v", zpoolName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing the devices of zpool %q: %w", zpoolName, err)
+		}
+
+		var paths []string
+		for _, line := range strings.Split(out, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) > 0 && filepath.IsAbs(fields[0]) {
+				paths = append(paths, fields[0])
+			}
+		}
+
+		return paths, nil
+	case "lvm":
+		vgName := config["lvm.vg_name"]
+		out, err := shared.RunCommand("vgs", "--noheadings", "-o", "pv_name", vgName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing the physical volumes of volume group %q: %w", vgName, err)
+		}
+
+		return shared.SplitNTrimSpace(strings.TrimSpace(out), "\n", -1, true), nil
+	}
+
+	return []string{storageDrivers.GetPoolMountPath(pool.Name())}, nil
+}
+
+// instanceTopologyPathDisks returns the names of the host disks backing a block device, or backing the
+// filesystem holding a file or directory.
+func instanceTopologyPathDisks(path string, depth int) []string {
+	// Guard against loop devices backed by files on loop devices.
+	if depth > 8 {
+		return nil
+	}
+
+	stat := unix.Stat_t{}
+	err := unix.Stat(path, &stat)
+	if err != nil {
+		return nil
+	}
+
+	if stat.Mode&unix.S_IFMT == unix.S_IFBLK {
+		return instanceTopologySysfsDisks(fmt.Sprintf("/sys/dev/block/%d:%d", unix.Major(stat.Rdev), unix.Minor(stat.Rdev)), depth)
+	}
+
+	// Find the device of the filesystem from the mount table, as some filesystems (btrfs, zfs) don't report
+	// the device they are on.
+	mountInfo, err := filesystem.GetMountinfo(path)
+	if err != nil {
+		return nil
+	}
+
+	for i, field := range mountInfo {
+		if field == "-" && i+2 < len(mountInfo) && filepath.IsAbs(mountInfo[i+2]) {
+			return instanceTopologyPathDisks(mountInfo[i+2], depth+1)
+		}
+	}
+
+	return nil
+}
+
+// instanceTopologySysfsDisks returns the names of the host disks backing the block device with the given sysfs
+// path, following partitions, stacked devices (LVM, RAID, encryption) and loop devices.
+func instanceTopologySysfsDisks(sysPath string, depth int) []string {
+	realPath, err := filepath.EvalSymlinks(sysPath)
+	if err != nil {
+		return nil
+	}
+
+	slaves, err := os.ReadDir(filepath.Join(realPath, "slaves"))
+	if err == nil && len(slaves) > 0 {
+		var disks []string
+		for _, slave := range slaves {
+			disks = append(disks, instanceTopologySysfsDisks(filepath.Join(realPath, "slaves", slave.Name()), depth+1)...)
+		}
+
+		return disks
+	}
+
+	backingFile, err := os.ReadFile(filepath.Join(realPath, "loop", "backing_file"))
+	if err == nil {
+		return instanceTopologyPathDisks(strings.TrimSpace(string(backingFile)), depth+1)
+	}
+
+	if shared.PathExists(filepath.Join(realPath, "partition")) {
+		return []string{filepath.Base(filepath.Dir(realPath))}
+	}
+
+	return []string{filepath.Base(realPath)}
+}
+
+// instanceTopologyNIC returns the path from a NIC device to the host network cards.
+func instanceTopologyNIC(s *state.State, networkProjectName string, name string, config map[string]string, networkCards *api.ResourcesNetwork) (api.InstanceTopologyNIC, error) {
+	nic := api.InstanceTopologyNIC{
+		Name:      name,
+		Type:      config["nictype"],
+		Parent:    config["parent"],
+		Devices:   []string{},
+		NUMANodes: []uint64{},
+	}
+
+	if config["network"] != "" {
+		n, err := network.LoadByName(s, networkProjectName, config["network"])
+		if err != nil {
+			return nic, fmt.Errorf("Failed loading network %q of device %q: %w", config["network"], name, err)
+		}
+
+		nic.Type = n.Type()
+		nic.Network = n.Name()
+
+		switch n.Type() {
+		case "bridge":
+			nic.Parent = n.Name()
+		case "ovn":
+			nic.Uplink = n.Config()["network"]
+
+			uplink, err := network.LoadByName(s, api.ProjectDefaultName, nic.Uplink)
+			if err != nil {
+				return nic, fmt.Errorf("Failed loading uplink network %q of device %q: %w", nic.Uplink, name, err)
+			}
+
+			if uplink.Type() == "bridge" {
+				nic.Parent = uplink.Name()
+			} else {
+				nic.Parent = uplink.Config()["parent"]
+			}
+
+		default:
+			nic.Parent = n.Config()["parent"]
+		}
+	}
+
+	if nic.Parent == "" {
+		return nic, nil
+	}
+
+	nic.Devices = instanceTopologyInterfaceDevices(nic.Parent, 0)
+	sort.Strings(nic.Devices)
+
+	for _, card := range networkCards.Cards {
+		for _, port := range card.Ports {
+			if shared.ValueInSlice(port.ID, nic.Devices) && !shared.ValueInSlice(card.NUMANode, nic.NUMANodes) {
+				nic.NUMANodes = append(nic.NUMANodes, card.NUMANode)
+			}
+		}
+	}
+
+	sort.Slice(nic.NUMANodes, func(i, j int) bool { return nic.NUMANodes[i] < nic.NUMANodes[j] })
+
+	return nic, nil
+}
+
+// instanceTopologyInterfaceDevices returns the host network interfaces of the network cards that traffic through
+// the given interface goes through, following bridge ports and the lower devices of VLANs, bonds and macvlans.
+func instanceTopologyInterfaceDevices(name string, depth int) []string {
+	if depth > 8 {
+		return nil
+	}
+
+	sysPath := filepath.Join("/sys/class/net", name)
+
+	// Interfaces backed by a device are network cards (or their virtual functions).
+	if shared.PathExists(filepath.Join(sysPath, "device")) {
+		return []string{name}
+	}
+
+	var devices []string
+
+	ports, err := os.ReadDir(filepath.Join(sysPath, "brif"))
+	if err == nil {
+		for _, port := range ports {
+			devices = append(devices, instanceTopologyInterfaceDevices(port.Name(), depth+1)...)
+		}
+	}
+
+	entries, err := os.ReadDir(sysPath)
+	if err == nil {
+		for _, entry := range entries {
+			lower, found := strings.CutPrefix(entry.Name(), "lower_")
+			if found {
+				devices = append(devices, instanceTopologyInterfaceDevices(lower, depth+1)...)
+			}
+		}
+	}
+
+	return devices
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceTopologyPinned(t *testing.T) {
+	assert.False(t, instanceTopologyPinned(map[string]string{}))
+	assert.False(t, instanceTopologyPinned(map[string]string{"limits.cpu": "4"}))
+	assert.True(t, instanceTopologyPinned(map[string]string{"limits.cpu": "0-3"}))
+	assert.True(t, instanceTopologyPinned(map[string]string{"limits.cpu": "1,3"}))
+	assert.True(t, instanceTopologyPinned(map[string]string{"limits.cpu": "4", "limits.cpu.nodes": "0"}))
+}
+
+func TestInstanceTopologyNUMAUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "numa_maps")
+	err := os.WriteFile(path, []byte(`7f0000000000 bind:0 anon=1024 dirty=1024 N0=1024 kernelpagesize_kB=4
+7f1000000000 default file=/usr/lib/x86_64-linux-gnu/libc.so.6 mapped=10 N0=6 N1=4 kernelpagesize_kB=4
+7f2000000000 bind:1 huge anon=2 dirty=2 N1=2 kernelpagesize_kB=2048
+`), 0600)
+	require.NoError(t, err)
+
+	usage, err := instanceTopologyNUMAUsage(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint64]uint64{0: 1030 * 4096, 1: 4*4096 + 2*2048*1024}, usage)
+}
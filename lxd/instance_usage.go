@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/instance/usage"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// swagger:operation GET /1.0/instances/{name}/usage instances instance_usage_get
+//
+//	Get the resource usage history
+//
+//	Gets the CPU, memory and disk usage of the instance sampled over the requested period.
+//	Samples are kept in memory by the cluster member running the instance and are lost when LXD restarts.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: period
+//	    description: How far back to return samples for (defaults to the whole retention period)
+//	    type: string
+//	    example: 24h
+//	responses:
+//	  "200":
+//	    description: Resource usage history
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceUsage"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceUsageGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	period := s.GlobalConfig.InstancesUsageRetention()
+	periodStr := request.QueryParam(r, "period")
+	if periodStr != "" {
+		period, err = time.ParseDuration(periodStr)
+		if err != nil || period <= 0 {
+			return response.BadRequest(fmt.Errorf("Invalid period %q", periodStr))
+		}
+	}
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	instUsage := api.InstanceUsage{
+		Interval: int64(s.GlobalConfig.InstancesUsageInterval().Seconds()),
+		Samples:  d.instanceUsage.Get(inst.ID(), time.Now().Add(-period)),
+	}
+
+	return response.SyncResponse(true, instUsage)
+}
+
+func instanceUsageSampleTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		instanceUsageSample(ctx, d.State(), d.instanceUsage)
+	}
+
+	// A zero interval disables sampling until the task gets reset.
+	schedule := func() (time.Duration, error) {
+		return d.State().GlobalConfig.InstancesUsageInterval(), nil
+	}
+
+	return f, schedule
+}
+
+// instanceUsageSample records the resource usage of all running instances on the local member and forgets about
+// the instances that aren't on the local member anymore.
+func instanceUsageSample(ctx context.Context, s *state.State, store *usage.Store) {
+	interval := s.GlobalConfig.InstancesUsageInterval()
+	if interval <= 0 {
+		return
+	}
+
+	size := int(s.GlobalConfig.InstancesUsageRetention() / interval)
+
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		logger.Warn("Failed loading instances to sample resource usage", logger.Ctx{"err": err})
+		return
+	}
+
+	// Gather information about host interfaces once.
+	hostInterfaces, _ := net.Interfaces()
+
+	localInstances := make(map[int]struct{}, len(instances))
+	for _, inst := range instances {
+		if ctx.Err() != nil {
+			return
+		}
+
+		localInstances[inst.ID()] = struct{}{}
+
+		if !inst.IsRunning() {
+			continue
+		}
+
+		instState, err := inst.RenderState(hostInterfaces)
+		if err != nil {
+			logger.Debug("Failed getting instance state to sample resource usage", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			continue
+		}
+
+		sample := api.InstanceUsageSample{
+			Time:        time.Now(),
+			CPUUsage:    instState.CPU.Usage,
+			MemoryUsage: instState.Memory.Usage,
+		}
+
+		for _, disk := range instState.Disk {
+			sample.DiskUsage += disk.Usage
+		}
+
+		store.Add(inst.ID(), sample, size)
+	}
+
+	store.Prune(func(instanceID int) bool {
+		_, ok := localInstances[instanceID]
+		return ok
+	})
+}
@@ -0,0 +1,38 @@
+package instancepool
+
+import (
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// InstancePool represents a set of standby instances created from a template instance.
+type InstancePool interface {
+	// Initialise.
+	init(state *state.State, id int64, projectName string, poolInfo *api.InstancePool)
+
+	// Info.
+	ID() int64
+	Project() string
+	Info() *api.InstancePool
+	Etag() []any
+	UsedBy() ([]string, error)
+	Source() string
+	Size() int
+	StandbyState() string
+
+	// Instances.
+	Instances() ([]db.InstancePoolInstance, error)
+	AddInstance(instanceID int64) error
+	Acquire() (*db.InstancePoolInstance, error)
+	Release(instanceName string) (*db.InstancePoolInstance, error)
+	RemoveStandby(instanceID int64) (bool, error)
+
+	// Internal validation.
+	validateName(name string) error
+	validateConfig(config *api.InstancePoolPut) error
+
+	// Modifications.
+	Update(config *api.InstancePoolPut) error
+	Delete() error
+}
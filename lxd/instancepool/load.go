@@ -0,0 +1,59 @@
+package instancepool
+
+import (
+	"context"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// LoadByName loads and initialises an instance pool from the database by project and name.
+func LoadByName(s *state.State, projectName string, name string) (InstancePool, error) {
+	var id int64
+	var poolInfo *api.InstancePool
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		id, poolInfo, err = tx.GetInstancePoolByName(ctx, projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pool InstancePool = &pool{}
+	pool.init(s, id, projectName, poolInfo)
+
+	return pool, nil
+}
+
+// Create validates supplied record and creates new instance pool record in the database.
+func Create(s *state.State, projectName string, poolInfo *api.InstancePoolsPost) error {
+	var pool InstancePool = &pool{}
+	pool.init(s, -1, projectName, nil)
+
+	err := pool.validateName(poolInfo.Name)
+	if err != nil {
+		return err
+	}
+
+	err = pool.validateConfig(&poolInfo.InstancePoolPut)
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Insert DB record.
+		_, err = tx.CreateInstancePool(ctx, projectName, poolInfo)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
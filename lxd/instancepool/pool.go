@@ -0,0 +1,337 @@
+package instancepool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/validate"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// StandbyStateStopped keeps the standby instances stopped.
+const StandbyStateStopped = "stopped"
+
+// StandbyStateFrozen keeps the standby instances running but frozen.
+const StandbyStateFrozen = "frozen"
+
+// NameSuffixLength is the length of the random suffix added to the pool name to generate instance names.
+const NameSuffixLength = 6
+
+// pool represents an instance pool.
+type pool struct {
+	logger      logger.Logger
+	state       *state.State
+	id          int64
+	projectName string
+	info        *api.InstancePool
+}
+
+// init initialise internal variables.
+func (d *pool) init(state *state.State, id int64, projectName string, info *api.InstancePool) {
+	if info == nil {
+		d.info = &api.InstancePool{}
+	} else {
+		d.info = info
+	}
+
+	d.logger = logger.AddContext(logger.Ctx{"project": projectName, "instancepool": d.info.Name})
+	d.id = id
+	d.projectName = projectName
+	d.state = state
+
+	if d.info.Config == nil {
+		d.info.Config = make(map[string]string)
+	}
+}
+
+// ID returns the instance pool ID.
+func (d *pool) ID() int64 {
+	return d.id
+}
+
+// Project returns the project name.
+func (d *pool) Project() string {
+	return d.projectName
+}
+
+// Info returns copy of internal info for the instance pool.
+func (d *pool) Info() *api.InstancePool {
+	// Copy internal info to prevent modification externally.
+	info := api.InstancePool{}
+	info.Name = d.info.Name
+	info.Description = d.info.Description
+	info.Config = util.CopyConfig(d.info.Config)
+	info.UsedBy = nil // To indicate its not populated (use UsedBy() function to populate).
+
+	return &info
+}
+
+// Etag returns the values used for etag generation.
+func (d *pool) Etag() []any {
+	return []any{d.info.Name, d.info.Description, d.info.Config}
+}
+
+// Source returns the name of the template instance.
+func (d *pool) Source() string {
+	return d.info.Config["source"]
+}
+
+// Size returns the number of standby instances to keep.
+func (d *pool) Size() int {
+	size, err := strconv.Atoi(d.info.Config["size"])
+	if err != nil {
+		return 1
+	}
+
+	return size
+}
+
+// StandbyState returns the state in which standby instances are kept.
+func (d *pool) StandbyState() string {
+	if d.info.Config["standby.state"] == "" {
+		return StandbyStateStopped
+	}
+
+	return d.info.Config["standby.state"]
+}
+
+// Instances returns the instances that belong to the pool.
+func (d *pool) Instances() ([]db.InstancePoolInstance, error) {
+	var poolInstances []db.InstancePoolInstance
+
+	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		poolInstances, err = tx.GetInstancePoolInstances(ctx, d.id)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instances of instance pool %q: %w", d.info.Name, err)
+	}
+
+	return poolInstances, nil
+}
+
+// UsedBy returns a list of API endpoints of the instances that belong to the pool.
+func (d *pool) UsedBy() ([]string, error) {
+	poolInstances, err := d.Instances()
+	if err != nil {
+		return nil, err
+	}
+
+	usedBy := make([]string, 0, len(poolInstances))
+	for _, poolInstance := range poolInstances {
+		usedBy = append(usedBy, api.NewURL().Path(version.APIVersion, "instances", poolInstance.Name).Project(d.projectName).String())
+	}
+
+	return usedBy, nil
+}
+
+// AddInstance adds a new standby instance to the pool.
+func (d *pool) AddInstance(instanceID int64) error {
+	return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.CreateInstancePoolInstance(ctx, d.id, instanceID)
+	})
+}
+
+// Acquire marks the oldest standby instance of the pool as acquired and returns it.
+func (d *pool) Acquire() (*db.InstancePoolInstance, error) {
+	var poolInstance *db.InstancePoolInstance
+
+	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		poolInstance, err = tx.AcquireInstancePoolInstance(ctx, d.id)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return poolInstance, nil
+}
+
+// Release removes an acquired instance from the pool and returns it.
+func (d *pool) Release(instanceName string) (*db.InstancePoolInstance, error) {
+	var poolInstance *db.InstancePoolInstance
+
+	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		poolInstances, err := tx.GetInstancePoolInstances(ctx, d.id)
+		if err != nil {
+			return err
+		}
+
+		for i := range poolInstances {
+			if poolInstances[i].Name == instanceName {
+				poolInstance = &poolInstances[i]
+				break
+			}
+		}
+
+		if poolInstance == nil {
+			return api.StatusErrorf(http.StatusNotFound, "Instance %q doesn't belong to the instance pool", instanceName)
+		}
+
+		if !poolInstance.Acquired {
+			return api.StatusErrorf(http.StatusBadRequest, "Instance %q hasn't been acquired", instanceName)
+		}
+
+		return tx.DeleteInstancePoolInstance(ctx, poolInstance.InstanceID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return poolInstance, nil
+}
+
+// RemoveStandby removes a standby instance from the pool.
+// Returns false if the instance has been acquired in the meantime.
+func (d *pool) RemoveStandby(instanceID int64) (bool, error) {
+	var removed bool
+
+	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		removed, err = tx.DeleteInstancePoolStandbyInstance(ctx, instanceID)
+
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return removed, nil
+}
+
+// validateName checks name is valid.
+func (d *pool) validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Name is required")
+	}
+
+	// The standby instances are named after the pool.
+	err := validate.IsHostname(fmt.Sprintf("%s-%s", name, strings.Repeat("0", NameSuffixLength)))
+	if err != nil {
+		return fmt.Errorf("Invalid name %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// validateConfig checks the config and rules are valid.
+func (d *pool) validateConfig(info *api.InstancePoolPut) error {
+	rules := map[string]func(value string) error{}
+
+	// lxdmeta:generate(entities=instance-pool; group=config-options; key=source)
+	// The standby instances are created as copies of this instance, without its snapshots.
+	// The instance must be in the same project as the instance pool.
+	// ---
+	//  type: string
+	//  required: yes
+	//  shortdesc: Name of the template instance
+	rules["source"] = validate.Required(validate.IsAny)
+
+	// lxdmeta:generate(entities=instance-pool; group=config-options; key=size)
+	// LXD creates new standby instances when instances are acquired and deletes standby instances when the size is reduced.
+	// ---
+	//  type: integer
+	//  defaultdesc: `1`
+	//  required: no
+	//  shortdesc: Number of standby instances to keep
+	rules["size"] = validate.Optional(validate.IsUint32)
+
+	// lxdmeta:generate(entities=instance-pool; group=config-options; key=standby.state)
+	// Possible values are `stopped` and `frozen`.
+	// Frozen instances are started when they are created and only need to be resumed when they are acquired, but they use memory while waiting.
+	// This option applies to standby instances that are created after it has been changed.
+	// ---
+	//  type: string
+	//  defaultdesc: `stopped`
+	//  required: no
+	//  shortdesc: State in which standby instances are kept
+	rules["standby.state"] = validate.Optional(validate.IsOneOf(StandbyStateStopped, StandbyStateFrozen))
+
+	// lxdmeta:generate(entities=instance-pool; group=config-options; key=user.*)
+	//
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: User-provided free-form key/value pairs
+
+	checkedFields := map[string]struct{}{}
+
+	// Run the validator against each field.
+	for k, validator := range rules {
+		checkedFields[k] = struct{}{} // Mark field as checked.
+		err := validator(info.Config[k])
+		if err != nil {
+			return fmt.Errorf("Invalid value for config option %q: %w", k, err)
+		}
+	}
+
+	// Look for any unchecked fields, as these are unknown fields and validation should fail.
+	for k := range info.Config {
+		_, checked := checkedFields[k]
+		if checked {
+			continue
+		}
+
+		// User keys are not validated.
+		if shared.IsUserConfig(k) {
+			continue
+		}
+
+		return fmt.Errorf("Invalid config option %q", k)
+	}
+
+	// Check that the template instance exists.
+	return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := dbCluster.GetInstanceID(ctx, tx.Tx(), d.projectName, info.Config["source"])
+		if err != nil {
+			return fmt.Errorf("Failed loading template instance %q: %w", info.Config["source"], err)
+		}
+
+		return nil
+	})
+}
+
+// Update applies the supplied config to the pool.
+func (d *pool) Update(config *api.InstancePoolPut) error {
+	err := d.validateConfig(config)
+	if err != nil {
+		return err
+	}
+
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateInstancePool(ctx, d.id, config)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Apply changes internally and reinitialise.
+	d.info.SetWritable(*config)
+	d.init(d.state, d.id, d.projectName, d.info)
+
+	return nil
+}
+
+// Delete deletes the pool. The caller is responsible for deleting its standby instances.
+func (d *pool) Delete() error {
+	return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteInstancePool(ctx, d.id)
+	})
+}
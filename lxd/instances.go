@@ -19,6 +19,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/lxd/warnings"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -88,6 +89,97 @@ var instanceStateCmd = APIEndpoint{
 	Put: APIEndpointAction{Handler: instanceStatePut, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanUpdateState, "name")},
 }
 
+var instanceFirewallCmd = APIEndpoint{
+	Name: "instanceFirewall",
+	Path: "instances/{name}/firewall",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerFirewall", Path: "containers/{name}/firewall"},
+		{Name: "vmFirewall", Path: "virtual-machines/{name}/firewall"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceFirewallGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+}
+
+var instanceDiskEncryptionKeysCmd = APIEndpoint{
+	Name: "instanceDiskEncryptionKeys",
+	Path: "instances/{name}/disk-encryption-keys",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerDiskEncryptionKeys", Path: "containers/{name}/disk-encryption-keys"},
+		{Name: "vmDiskEncryptionKeys", Path: "virtual-machines/{name}/disk-encryption-keys"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceDiskEncryptionKeysGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
+var instanceDiskEncryptionKeyCmd = APIEndpoint{
+	Name: "instanceDiskEncryptionKey",
+	Path: "instances/{name}/disk-encryption-keys/{device}",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerDiskEncryptionKey", Path: "containers/{name}/disk-encryption-keys/{device}"},
+		{Name: "vmDiskEncryptionKey", Path: "virtual-machines/{name}/disk-encryption-keys/{device}"},
+	},
+
+	Get:    APIEndpointAction{Handler: instanceDiskEncryptionKeyGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
+	Delete: APIEndpointAction{Handler: instanceDiskEncryptionKeyDelete, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
+var instanceCommandsCmd = APIEndpoint{
+	Name: "instanceCommands",
+	Path: "instances/{name}/commands",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerCommands", Path: "containers/{name}/commands"},
+		{Name: "vmCommands", Path: "virtual-machines/{name}/commands"},
+	},
+
+	Get:  APIEndpointAction{Handler: instanceCommandsGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+	Post: APIEndpointAction{Handler: instanceCommandsPost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
+}
+
+var instanceCommandCmd = APIEndpoint{
+	Name: "instanceCommand",
+	Path: "instances/{name}/commands/{id}",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerCommand", Path: "containers/{name}/commands/{id}"},
+		{Name: "vmCommand", Path: "virtual-machines/{name}/commands/{id}"},
+	},
+
+	Get:    APIEndpointAction{Handler: instanceCommandGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+	Delete: APIEndpointAction{Handler: instanceCommandDelete, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanExec, "name")},
+}
+
+var instanceUsageCmd = APIEndpoint{
+	Name: "instanceUsage",
+	Path: "instances/{name}/usage",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerUsage", Path: "containers/{name}/usage"},
+		{Name: "vmUsage", Path: "virtual-machines/{name}/usage"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceUsageGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+}
+
+var instanceLeaseCmd = APIEndpoint{
+	Name: "instanceLease",
+	Path: "instances/{name}/lease",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerLease", Path: "containers/{name}/lease"},
+		{Name: "vmLease", Path: "virtual-machines/{name}/lease"},
+	},
+
+	Get:  APIEndpointAction{Handler: instanceLeaseGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+	Post: APIEndpointAction{Handler: instanceLeasePost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanUpdateState, "name")},
+}
+
+var instanceRemapCmd = APIEndpoint{
+	Name: "instanceRemap",
+	Path: "instances/{name}/remap",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerRemap", Path: "containers/{name}/remap"},
+	},
+
+	Post: APIEndpointAction{Handler: instanceRemapPost, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanEdit, "name")},
+}
+
 var instanceSFTPCmd = APIEndpoint{
 	Name: "instanceFile",
 	Path: "instances/{name}/sftp",
@@ -294,11 +386,14 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 
 		instLogger := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
+		// Restore the saved state of the instance if there is one.
+		stateful := inst.IsStateful()
+
 		// Try to start the instance.
 		var attempt = 0
 		for {
 			attempt++
-			err := inst.Start(false)
+			err := inst.Start(stateful)
 			if err != nil {
 				if api.StatusErrorCheck(err, http.StatusServiceUnavailable) {
 					break // Don't log or retry instances that are not ready to start yet.
@@ -320,6 +415,12 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 					break
 				}
 
+				// The saved state may not be usable anymore, for example after a QEMU upgrade.
+				if stateful {
+					instLogger.Warn("Discarding the saved instance state for the next auto start attempt")
+					stateful = false
+				}
+
 				time.Sleep(5 * time.Second)
 
 				continue
@@ -342,6 +443,44 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 	}
 }
 
+// instancesDeleteVolatile deletes the stopped ephemeral instances whose root volume is on a volatile storage pool.
+// The content of such pools doesn't survive the pool being unmounted, for example when the host restarts, so these
+// instances cannot be started again. Returns the remaining instances.
+func instancesDeleteVolatile(s *state.State, instances []instance.Instance) []instance.Instance {
+	remaining := make([]instance.Instance, 0, len(instances))
+
+	for _, inst := range instances {
+		if !inst.IsEphemeral() || inst.IsRunning() {
+			remaining = append(remaining, inst)
+			continue
+		}
+
+		instLogger := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+		pool, err := storagePools.LoadByInstance(s, inst)
+		if err != nil {
+			instLogger.Warn("Failed loading instance storage pool", logger.Ctx{"err": err})
+			remaining = append(remaining, inst)
+			continue
+		}
+
+		if !pool.Driver().Info().Volatile {
+			remaining = append(remaining, inst)
+			continue
+		}
+
+		instLogger.Info("Deleting ephemeral instance from volatile storage pool", logger.Ctx{"pool": pool.Name()})
+
+		err = inst.Delete(true)
+		if err != nil {
+			instLogger.Error("Failed deleting ephemeral instance", logger.Ctx{"err": err})
+			remaining = append(remaining, inst)
+		}
+	}
+
+	return remaining
+}
+
 type instanceStopList []instance.Instance
 
 func (slice instanceStopList) Len() int {
@@ -365,6 +504,22 @@ func (slice instanceStopList) Swap(i, j int) {
 	slice[i], slice[j] = slice[j], slice[i]
 }
 
+// instancesForwardGuestJournals resumes forwarding the guest journal of the running virtual machines.
+func instancesForwardGuestJournals(instances []instance.Instance) {
+	for _, inst := range instances {
+		if shared.IsFalseOrEmpty(inst.ExpandedConfig()["agent.journal.forward"]) {
+			continue
+		}
+
+		vm, ok := inst.(instance.VM)
+		if !ok || !vm.IsRunning() {
+			continue
+		}
+
+		vm.ForwardGuestJournal()
+	}
+}
+
 // Return all local instances on disk (if instance is running, it will attempt to populate the instance's local
 // and expanded config using the backup.yaml file). It will clear the instance's profiles property to avoid needing
 // to enrich them from the database.
@@ -438,9 +593,15 @@ func instancesOnDisk(s *state.State) ([]instance.Instance, error) {
 func instancesShutdown(s *state.State, instances []instance.Instance) {
 	sort.Sort(instanceStopList(instances))
 
+	// instanceShutdown is an instance queued for shutdown along with the time by which it must be stopped.
+	type instanceShutdown struct {
+		inst     instance.Instance
+		deadline time.Time
+	}
+
 	// Limit shutdown concurrency to number of instances or number of CPU cores (which ever is less).
 	var wg sync.WaitGroup
-	instShutdownCh := make(chan instance.Instance)
+	instShutdownCh := make(chan instanceShutdown)
 	maxConcurrent := runtime.NumCPU()
 	instCount := len(instances)
 	if instCount < maxConcurrent {
@@ -448,21 +609,39 @@ func instancesShutdown(s *state.State, instances []instance.Instance) {
 	}
 
 	for i := 0; i < maxConcurrent; i++ {
-		go func(instShutdownCh <-chan instance.Instance) {
-			for inst := range instShutdownCh {
-				// Determine how long to wait for the instance to shutdown cleanly.
-				timeoutSeconds := 30
-				value, ok := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
-				if ok {
-					timeoutSeconds, _ = strconv.Atoi(value)
+		go func(instShutdownCh <-chan instanceShutdown) {
+			for queued := range instShutdownCh {
+				inst := queued.inst
+				l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+				var err error
+
+				// Save the state of the instance if requested so that it resumes where it left off.
+				stateful := inst.ExpandedConfig()["boot.host_shutdown_action"] == "stateful-stop"
+				if stateful {
+					err = inst.Stop(true)
+					if err != nil {
+						l.Warn("Failed stateful stop of instance, shutting it down instead", logger.Ctx{"err": err})
+						stateful = false
+					}
+				}
+
+				if !stateful {
+					// The time spent waiting for other instances of the same batch counts towards the
+					// shutdown timeout so that a batch never takes longer than its longest timeout.
+					timeout := time.Until(queued.deadline)
+					if timeout < 0 {
+						timeout = 0
+					}
+
+					err = inst.Shutdown(timeout)
 				}
 
-				err := inst.Shutdown(time.Second * time.Duration(timeoutSeconds))
 				if err != nil {
-					logger.Warn("Failed shutting down instance, forcefully stopping", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+					l.Warn("Failed shutting down instance, forcefully stopping", logger.Ctx{"err": err})
 					err = inst.Stop(false)
 					if err != nil {
-						logger.Warn("Failed forcefully stopping instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+						l.Warn("Failed forcefully stopping instance", logger.Ctx{"err": err})
 					}
 				}
 
@@ -479,25 +658,40 @@ func instancesShutdown(s *state.State, instances []instance.Instance) {
 	}
 
 	var currentBatchPriority int
-	for i, inst := range instances {
+	var currentBatchStart time.Time
+	for _, inst := range instances {
 		// Skip stopped instances.
 		if !inst.IsRunning() {
 			continue
 		}
 
+		// Leave instances that don't need to be stopped to the operating system.
+		if inst.ExpandedConfig()["boot.host_shutdown_action"] == "ignore" {
+			logger.Info("Not stopping instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+			continue
+		}
+
 		priority, _ := strconv.Atoi(inst.ExpandedConfig()["boot.stop.priority"])
 
 		// Shutdown instances in priority batches, logging at the start of each batch.
-		if i == 0 || priority != currentBatchPriority {
+		if currentBatchStart.IsZero() || priority != currentBatchPriority {
 			currentBatchPriority = priority
 
 			// Wait for instances with higher priority to finish before starting next batch.
 			wg.Wait()
 			logger.Info("Stopping instances", logger.Ctx{"stopPriority": currentBatchPriority})
+			currentBatchStart = time.Now()
+		}
+
+		// Determine how long to wait for the instance to shutdown cleanly.
+		timeoutSeconds := 30
+		value, ok := inst.ExpandedConfig()["boot.host_shutdown_timeout"]
+		if ok {
+			timeoutSeconds, _ = strconv.Atoi(value)
 		}
 
 		wg.Add(1)
-		instShutdownCh <- inst
+		instShutdownCh <- instanceShutdown{inst: inst, deadline: currentBatchStart.Add(time.Duration(timeoutSeconds) * time.Second)}
 	}
 
 	wg.Wait()
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// instanceRemoteConnect connects to a remote LXD server using the server certificate of this server, pinning the
+// given certificate of the remote. If a trust token is given and this server isn't trusted by the remote yet, it is
+// used to add the server certificate to the trust store of the remote.
+func instanceRemoteConnect(s *state.State, server string, certificate string, projectName string, trustToken string) (lxd.InstanceServer, error) {
+	if server == "" {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Remote server URL is required")
+	}
+
+	if certificate == "" {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Remote server certificate is required")
+	}
+
+	serverCert := s.ServerCert()
+	args := &lxd.ConnectionArgs{
+		TLSClientCert: string(serverCert.PublicKey()),
+		TLSClientKey:  string(serverCert.PrivateKey()),
+		TLSServerCert: certificate,
+		UserAgent:     version.UserAgent,
+	}
+
+	client, err := lxd.ConnectLXD(server, args)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to remote server %q: %w", server, err)
+	}
+
+	remoteServer, _, err := client.GetServer()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting remote server information: %w", err)
+	}
+
+	if remoteServer.Auth != "trusted" && trustToken != "" {
+		err = client.CreateCertificate(api.CertificatesPost{
+			Name:       s.ServerName,
+			Type:       api.CertificateTypeClient,
+			TrustToken: trustToken,
+		})
+		if err != nil && !api.StatusErrorCheck(err, http.StatusConflict) {
+			return nil, fmt.Errorf("Failed adding server certificate to remote server: %w", err)
+		}
+
+		// Reconnect to refresh the server information now that the certificate is trusted.
+		client, err = lxd.ConnectLXD(server, args)
+		if err != nil {
+			return nil, fmt.Errorf("Failed connecting to remote server %q: %w", server, err)
+		}
+
+		remoteServer, _, err = client.GetServer()
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting remote server information: %w", err)
+		}
+	}
+
+	if remoteServer.Auth != "trusted" {
+		return nil, api.StatusErrorf(http.StatusForbidden, "This server isn't trusted by the remote server %q", server)
+	}
+
+	if projectName != "" {
+		client = client.UseProject(projectName)
+	}
+
+	return client, nil
+}
+
+// instanceRemoteDeleteSource stops and deletes the source of an instance once it has been moved to another server.
+func instanceRemoteDeleteSource(client lxd.InstanceServer, name string) error {
+	instState, _, err := client.GetInstanceState(name)
+	if err != nil {
+		return fmt.Errorf("Failed getting state of source instance: %w", err)
+	}
+
+	if instState.StatusCode != api.Stopped {
+		op, err := client.UpdateInstanceState(name, api.InstanceStatePut{Action: "stop", Force: true, Timeout: -1}, "")
+		if err != nil {
+			return fmt.Errorf("Failed stopping source instance: %w", err)
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return fmt.Errorf("Failed stopping source instance: %w", err)
+		}
+	}
+
+	op, err := client.DeleteInstance(name)
+	if err != nil {
+		return fmt.Errorf("Failed deleting source instance: %w", err)
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return fmt.Errorf("Failed deleting source instance: %w", err)
+	}
+
+	return nil
+}
+
+// instancesAdopt moves an instance from a remote LXD server into this server or cluster, keeping its snapshots,
+// configuration and volatile keys, and deletes it from the remote once the migration succeeded.
+func instancesAdopt(d *Daemon, r *http.Request, projectName string, req *api.InstancesPost) response.Response {
+	s := d.State()
+
+	if req.Source.Source == "" {
+		return response.BadRequest(fmt.Errorf("Remote instance name is required"))
+	}
+
+	remote, err := instanceRemoteConnect(s, req.Source.Server, req.Source.Certificate, req.Source.Project, req.Source.TrustToken)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	remoteInst, _, err := remote.GetInstance(req.Source.Source)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed getting remote instance %q: %w", req.Source.Source, err))
+	}
+
+	if remoteInst.StatusCode != api.Stopped && !req.Source.Live {
+		return response.BadRequest(fmt.Errorf("Remote instance %q is running, stop it or use live migration", remoteInst.Name))
+	}
+
+	// Apply the overrides of the request on top of the remote instance.
+	inst := *remoteInst
+	if req.Description != "" {
+		inst.Description = req.Description
+	}
+
+	for key, value := range req.Config {
+		inst.Config[key] = value
+	}
+
+	for name, device := range req.Devices {
+		inst.Devices[name] = device
+	}
+
+	if req.Profiles != nil {
+		inst.Profiles = req.Profiles
+	}
+
+	name := req.Name
+	if name == "" {
+		name = inst.Name
+	}
+
+	err = instance.ValidName(name, false)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// The instance is created through the local API, so check the approvals on behalf of the requestor here.
+	err = authApprovalCheckConfig(r.Context(), s, r, entity.InstanceURL(projectName, name), nil, nil, inst.Config, inst.Devices)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	target := request.QueryParam(r, "target")
+
+	run := func(op *operations.Operation) error {
+		local, err := lxd.ConnectLXDUnix(d.UnixSocket(), &lxd.ConnectionArgs{UserAgent: version.UserAgent})
+		if err != nil {
+			return fmt.Errorf("Failed connecting to local LXD: %w", err)
+		}
+
+		local = local.UseProject(projectName)
+		if target != "" {
+			local = local.UseTarget(target)
+		}
+
+		rop, err := local.CopyInstance(remote, inst, &lxd.InstanceCopyArgs{
+			Name:              name,
+			Live:              req.Source.Live,
+			InstanceOnly:      req.Source.InstanceOnly,
+			Mode:              "pull",
+			AllowInconsistent: req.Source.AllowInconsistent,
+		})
+		if err != nil {
+			return fmt.Errorf("Failed migrating remote instance: %w", err)
+		}
+
+		err = rop.Wait()
+		if err != nil {
+			return fmt.Errorf("Failed migrating remote instance: %w", err)
+		}
+
+		err = instanceRemoteDeleteSource(remote, inst.Name)
+		if err != nil {
+			logger.Warn("Failed deleting remote instance after adopting it", logger.Ctx{"project": projectName, "instance": name, "server": req.Source.Server, "err": err})
+			return err
+		}
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", name)}
+
+	if remoteInst.Type == instancetype.Container.String() {
+		resources["containers"] = resources["instances"]
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceCreate, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// instancePostRemote moves an instance to a remote LXD server, keeping its snapshots, configuration and volatile
+// keys, and deletes it from this server once the migration succeeded.
+func instancePostRemote(d *Daemon, r *http.Request, inst instance.Instance, req api.InstancePost) response.Response {
+	s := d.State()
+
+	live := req.Live && inst.IsRunning()
+	if inst.IsRunning() && !live {
+		return response.BadRequest(fmt.Errorf("Instance is running, stop it or use live migration"))
+	}
+
+	remote, err := instanceRemoteConnect(s, req.Remote.Server, req.Remote.Certificate, req.Remote.Project, req.Remote.TrustToken)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := inst.Project().Name
+
+	run := func(op *operations.Operation) error {
+		local, err := lxd.ConnectLXDUnix(d.UnixSocket(), &lxd.ConnectionArgs{UserAgent: version.UserAgent})
+		if err != nil {
+			return fmt.Errorf("Failed connecting to local LXD: %w", err)
+		}
+
+		local = local.UseProject(projectName)
+
+		apiInst, _, err := local.GetInstance(inst.Name())
+		if err != nil {
+			return err
+		}
+
+		rop, err := remote.CopyInstance(local, *apiInst, &lxd.InstanceCopyArgs{
+			Name:              req.Name,
+			Live:              live,
+			InstanceOnly:      req.InstanceOnly || req.ContainerOnly,
+			Mode:              "push",
+			AllowInconsistent: req.AllowInconsistent,
+		})
+		if err != nil {
+			return fmt.Errorf("Failed migrating instance to remote server: %w", err)
+		}
+
+		err = rop.Wait()
+		if err != nil {
+			return fmt.Errorf("Failed migrating instance to remote server: %w", err)
+		}
+
+		return instanceRemoteDeleteSource(local, inst.Name())
+	}
+
+	resources := map[string][]api.URL{}
+	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", inst.Name())}
+
+	if inst.Type() == instancetype.Container {
+		resources["containers"] = resources["instances"]
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.InstanceMigrate, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
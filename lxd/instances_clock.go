@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// hostSuspendedTime returns the total time the host spent suspended since it booted.
+func hostSuspendedTime() (time.Duration, error) {
+	var boottime, monotonic unix.Timespec
+
+	err := unix.ClockGettime(unix.CLOCK_BOOTTIME, &boottime)
+	if err != nil {
+		return 0, err
+	}
+
+	err = unix.ClockGettime(unix.CLOCK_MONOTONIC, &monotonic)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(boottime.Nano() - monotonic.Nano()), nil
+}
+
+// syncGuestClocksTask resynchronizes the clock of the running virtual machines after the host resumed from suspend.
+func syncGuestClocksTask(d *Daemon) (task.Func, task.Schedule) {
+	lastSuspended, err := hostSuspendedTime()
+	if err != nil {
+		logger.Warn("Failed getting host suspend time, guest clocks won't be resynchronized after suspend", logger.Ctx{"err": err})
+	}
+
+	f := func(ctx context.Context) {
+		suspended, err := hostSuspendedTime()
+		if err != nil {
+			return
+		}
+
+		// Ignore the jitter between both clocks.
+		if suspended-lastSuspended < time.Second {
+			return
+		}
+
+		lastSuspended = suspended
+		logger.Info("Host resumed from suspend, resynchronizing guest clocks")
+
+		instances, err := instance.LoadNodeAll(d.State(), instancetype.VM)
+		if err != nil {
+			logger.Warn("Failed loading instances to resynchronize their clock", logger.Ctx{"err": err})
+			return
+		}
+
+		for _, inst := range instances {
+			if !inst.IsRunning() {
+				continue
+			}
+
+			vm, ok := inst.(instance.VM)
+			if !ok {
+				continue
+			}
+
+			err := vm.SyncGuestClock()
+			if err != nil {
+				logger.Warn("Failed resynchronizing guest clock", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			}
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
@@ -27,7 +27,6 @@ import (
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/filter"
-	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/version"
 )
 
@@ -222,24 +221,17 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 func instancesGet(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
-	for i := 0; i < 100; i++ {
-		result, err := doInstancesGet(s, r)
-		if err == nil {
-			return response.SyncResponse(true, result)
-		}
-
-		if !query.IsRetriableError(err) {
-			logger.Debugf("DBERR: containersGet: error %q", err)
-			return response.SmartError(err)
-		}
-		// 100 ms may seem drastic, but we really don't want to thrash
-		// perhaps we should use a random amount
-		time.Sleep(100 * time.Millisecond)
+	var result any
+	err := query.Retry(r.Context(), func(ctx context.Context) error {
+		var err error
+		result, err = doInstancesGet(s, r)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
 	}
 
-	logger.Debugf("DBERR: containersGet, db is locked")
-	logger.Debugf(logger.GetStack())
-	return response.InternalError(fmt.Errorf("DB is locked"))
+	return response.SyncResponse(true, result)
 }
 
 func doInstancesGet(s *state.State, r *http.Request) (any, error) {
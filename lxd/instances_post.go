@@ -15,6 +15,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/canonical/lxd/lxd/archive"
+	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/backup"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
@@ -34,6 +35,7 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	apiScriptlet "github.com/canonical/lxd/shared/api/scriptlet"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/osarch"
 	"github.com/canonical/lxd/shared/revert"
@@ -89,6 +91,13 @@ func createFromImage(s *state.State, r *http.Request, p api.Project, profiles []
 		return response.BadRequest(err)
 	}
 
+	// Local images can be used from another project, in which case the instance is cloned from the image
+	// volume of that project.
+	imageProjectName := p.Name
+	if req.Source.Server == "" && req.Source.Project != "" {
+		imageProjectName = req.Source.Project
+	}
+
 	run := func(op *operations.Operation) error {
 		devices := deviceConfig.NewDevices(req.Devices)
 
@@ -109,7 +118,7 @@ func createFromImage(s *state.State, r *http.Request, p api.Project, profiles []
 				return err
 			}
 		} else if img != nil {
-			err := ensureImageIsLocallyAvailable(s, r, img, args.Project)
+			err := ensureImageIsLocallyAvailable(s, r, img, imageProjectName)
 			if err != nil {
 				return err
 			}
@@ -122,7 +131,9 @@ func createFromImage(s *state.State, r *http.Request, p api.Project, profiles []
 			return err
 		}
 
-		return instanceCreateFromImage(s, img, args, op)
+		return instanceCreateWithResources(s, r, req, &args, op, func() error {
+			return instanceCreateFromImage(s, img, imageProjectName, args, op)
+		})
 	}
 
 	resources := map[string][]api.URL{}
@@ -173,8 +184,10 @@ func createFromNone(s *state.State, r *http.Request, projectName string, profile
 	}
 
 	run := func(op *operations.Operation) error {
-		_, err := instanceCreateAsEmpty(s, args)
-		return err
+		return instanceCreateWithResources(s, r, req, &args, op, func() error {
+			_, err := instanceCreateAsEmpty(s, args)
+			return err
+		})
 	}
 
 	resources := map[string][]api.URL{}
@@ -463,6 +476,12 @@ func createFromCopy(s *state.State, r *http.Request, projectName string, profile
 		}
 	}
 
+	// Refuse the copy if it would exceed the disk budget of the target project.
+	err = projectCheckDiskUsage(r.Context(), s, targetProject, instanceDiskUsage(s, source))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Config override
 	sourceConfig := source.LocalConfig()
 	if req.Config == nil {
@@ -872,6 +891,10 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	if req.Source.Type == "adopt" {
+		return instancesAdopt(d, r, targetProjectName, &req)
+	}
+
 	// Set type from URL if missing
 	urlType, err := urlInstanceTypeDetect(r)
 	if err != nil {
@@ -940,10 +963,18 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 				return fmt.Errorf("Failed getting cluster members: %w", err)
 			}
 
-			// Check if the given target is allowed and try to resolve the right member or group
-			targetMemberInfo, targetGroupName, err = project.CheckTarget(ctx, s.Authorizer, r, tx, targetProject, target, allMembers)
-			if err != nil {
-				return err
+			if target == "" && targetProject.Config["default.target"] != "" {
+				// Use the default target of the project if the client didn't request one.
+				targetMemberInfo, targetGroupName, err = project.CheckDefaultTarget(ctx, tx, targetProject, allMembers)
+				if err != nil {
+					return fmt.Errorf("Failed checking default target of project %q: %w", targetProject.Name, err)
+				}
+			} else {
+				// Check if the given target is allowed and try to resolve the right member or group
+				targetMemberInfo, targetGroupName, err = project.CheckTarget(ctx, s.Authorizer, r, tx, targetProject, target, allMembers)
+				if err != nil {
+					return err
+				}
 			}
 		}
 
@@ -982,13 +1013,19 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		case "image":
 			// Check if the image has an entry in the database but fail only if the error
 			// is different than the image not being found.
-			sourceImage, err = getSourceImageFromInstanceSource(ctx, s, tx, targetProject.Name, req.Source, &sourceImageRef, string(req.Type))
+			imageProjectName := targetProject.Name
+			if req.Source.Server == "" && req.Source.Project != "" {
+				imageProjectName = req.Source.Project
+			}
+
+			sourceImage, err = getSourceImageFromInstanceSource(ctx, s, tx, imageProjectName, req.Source, &sourceImageRef, string(req.Type))
 			if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
 				return err
 			}
 
 			// If image has an entry in the database then use its profiles if no override provided.
-			if sourceImage != nil && req.Profiles == nil {
+			// The profiles of an image from another project don't apply to the target project.
+			if sourceImage != nil && req.Profiles == nil && imageProjectName == targetProject.Name {
 				req.Architecture = sourceImage.Architecture
 				req.Profiles = sourceImage.Profiles
 			}
@@ -1040,6 +1077,22 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			}
 		}
 
+		// Connect the instance to the default network of the project if neither it nor its profiles have a NIC.
+		defaultNetwork := targetProject.Config["default.network"]
+		if defaultNetwork != "" && req.Source.Type != "copy" && !instanceHasNIC(req.Devices, profiles) {
+			if req.Devices == nil {
+				req.Devices = map[string]map[string]string{}
+			}
+
+			if req.Devices["eth0"] == nil {
+				req.Devices["eth0"] = map[string]string{
+					"type":    "nic",
+					"name":    "eth0",
+					"network": defaultNetwork,
+				}
+			}
+		}
+
 		// Generate automatic instance name if not specified.
 		if req.Name == "" {
 			names, err := tx.GetInstanceNames(ctx, targetProjectName)
@@ -1063,6 +1116,25 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			logger.Debug("No name provided for new instance, using auto-generated name", logger.Ctx{"project": targetProjectName, "instance": req.Name})
 		}
 
+		// Check that the requested cluster member can run instances of the source architecture.
+		if s.ServerClustered && !clusterNotification && targetMemberInfo != nil {
+			architectures, err := instance.SuitableArchitectures(ctx, s, tx, targetProjectName, sourceInst, sourceImageRef, req)
+			if err != nil {
+				return err
+			}
+
+			if len(architectures) > 0 {
+				supported, err := targetMemberInfo.SupportsArchitecture(architectures)
+				if err != nil {
+					return err
+				}
+
+				if !supported {
+					return api.StatusErrorf(http.StatusBadRequest, "Cluster member %q doesn't support the %s architecture of the source", targetMemberInfo.Name, architecturesString(architectures))
+				}
+			}
+		}
+
 		if s.ServerClustered && !clusterNotification && targetMemberInfo == nil {
 			architectures, err := instance.SuitableArchitectures(ctx, s, tx, targetProjectName, sourceInst, sourceImageRef, req)
 			if err != nil {
@@ -1096,6 +1168,18 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 				return err
 			}
 
+			// Report when the architecture is the reason why no member is suitable.
+			if len(candidateMembers) == 0 && len(architectures) > 0 {
+				anyArchMembers, err := tx.GetCandidateMembers(ctx, allMembers, nil, targetGroupName, clusterGroupsAllowed, s.GlobalConfig.OfflineThreshold())
+				if err != nil {
+					return err
+				}
+
+				if len(anyArchMembers) > 0 {
+					return api.StatusErrorf(http.StatusBadRequest, "No available cluster member supports the %s architecture of the source", architecturesString(architectures))
+				}
+			}
+
 			return nil
 		}
 
@@ -1106,6 +1190,11 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			if err != nil {
 				return err
 			}
+
+			err = instanceNameCheckUnique(ctx, s, tx, req.Name, targetProjectName)
+			if err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -1119,6 +1208,11 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	err = instancesPostResourcesValidate(r.Context(), s, r, targetProjectName, &req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	if s.ServerClustered && !clusterNotification && targetMemberInfo == nil {
 		// Run instance placement scriptlet if enabled and no cluster member selected yet.
 		if s.GlobalConfig.InstancesPlacementScriptlet() != "" {
@@ -1160,6 +1254,14 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Cloning an instance or image from another project requires access to it in that project.
+	if req.Source.Project != "" && req.Source.Project != targetProjectName {
+		err = instanceCheckSourceProjectAccess(r, s, req.Source, sourceImage)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	if targetMemberInfo != nil && targetMemberInfo.Address != "" && targetMemberInfo.Name != s.ServerName {
 		client, err := cluster.Connect(targetMemberInfo.Address, s.Endpoints.NetworkCert(), s.ServerCert(), r, false)
 		if err != nil {
@@ -1179,6 +1281,11 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return operations.ForwardedOperationResponse(targetProjectName, &opAPI)
 	}
 
+	err = authApprovalCheckConfig(r.Context(), s, r, entity.InstanceURL(targetProjectName, req.Name), nil, nil, req.Config, req.Devices)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	switch req.Source.Type {
 	case "image":
 		return createFromImage(s, r, *targetProject, profiles, sourceImage, sourceImageRef, &req)
@@ -1193,6 +1300,50 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 	}
 }
 
+// instanceCheckSourceProjectAccess checks that the requestor can clone the source instance or local image of a
+// request from the project it lives in.
+func instanceCheckSourceProjectAccess(r *http.Request, s *state.State, source api.InstanceSource, sourceImage *api.Image) error {
+	switch source.Type {
+	case "copy":
+		// A copy gives access to the whole filesystem of the instance, just like a backup does.
+		err := s.Authorizer.CheckPermission(r.Context(), r, entity.InstanceURL(source.Project, source.Source), auth.EntitlementCanManageBackups)
+		if err != nil {
+			return err
+		}
+
+	case "image":
+		if source.Server != "" || sourceImage == nil || sourceImage.Public {
+			return nil
+		}
+
+		err := s.Authorizer.CheckPermission(r.Context(), r, entity.ImageURL(source.Project, sourceImage.Fingerprint), auth.EntitlementCanView)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// instanceHasNIC returns true if the given instance devices or profiles have a NIC device.
+func instanceHasNIC(devices map[string]map[string]string, profiles []api.Profile) bool {
+	for _, dev := range devices {
+		if dev["type"] == "nic" {
+			return true
+		}
+	}
+
+	for _, profile := range profiles {
+		for _, dev := range profile.Devices {
+			if dev["type"] == "nic" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func instanceFindStoragePool(s *state.State, projectName string, req *api.InstancesPost) (storagePool string, storagePoolProfile string, localRootDiskDeviceKey string, localRootDiskDevice map[string]string, resp response.Response) {
 	// Grab the container's root device if one is specified
 	localRootDiskDeviceKey, localRootDiskDevice, _ = instancetype.GetRootDiskDevice(req.Devices)
@@ -1239,6 +1390,28 @@ func instanceFindStoragePool(s *state.State, projectName string, req *api.Instan
 		}
 	}
 
+	// If we still don't have a pool, use the default storage pool of the project.
+	if storagePool == "" {
+		err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+			if err != nil {
+				return err
+			}
+
+			config, err := dbCluster.GetProjectConfig(ctx, tx.Tx(), dbProject.ID)
+			if err != nil {
+				return err
+			}
+
+			storagePool = config["default.storage_pool"]
+
+			return nil
+		})
+		if err != nil {
+			return "", "", "", nil, response.SmartError(err)
+		}
+	}
+
 	// If there is just a single pool in the database, use that
 	if storagePool == "" {
 		logger.Debug("No valid storage pool in the container's local root disk device and profiles found")
@@ -1357,3 +1530,18 @@ func clusterCopyContainerInternal(s *state.State, r *http.Request, source instan
 	// Run the migration
 	return createFromMigration(s, nil, projectName, profiles, req)
 }
+
+// architecturesString returns a human readable list of the given architectures.
+func architecturesString(architectures []int) string {
+	names := make([]string, 0, len(architectures))
+	for _, architecture := range architectures {
+		name, err := osarch.ArchitectureName(architecture)
+		if err != nil {
+			name = fmt.Sprintf("%d", architecture)
+		}
+
+		names = append(names, name)
+	}
+
+	return strings.Join(names, " or ")
+}
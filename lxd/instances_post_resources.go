@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/auth"
+	clusterRequest "github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/cluster"
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/network/zone"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// instancesPostResourcesValidate validates the resources requested along with a new instance and checks that
+// the requestor is allowed to create them.
+func instancesPostResourcesValidate(ctx context.Context, s *state.State, r *http.Request, projectName string, req *api.InstancesPost) error {
+	if req.Resources == nil {
+		return nil
+	}
+
+	if req.Source.Type != "image" && req.Source.Type != "none" {
+		return api.StatusErrorf(http.StatusBadRequest, "Resources can only be created along with instances from an image or without a source")
+	}
+
+	for i, vol := range req.Resources.Volumes {
+		if vol.Pool == "" {
+			return api.StatusErrorf(http.StatusBadRequest, "Storage pool of volume %q is required", vol.Name)
+		}
+
+		err := storagePools.ValidVolumeName(vol.Name)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid volume name %q: %w", vol.Name, err)
+		}
+
+		if vol.ContentType == "" {
+			req.Resources.Volumes[i].ContentType = cluster.StoragePoolVolumeContentTypeNameFS
+		} else if vol.ContentType != cluster.StoragePoolVolumeContentTypeNameFS && vol.ContentType != cluster.StoragePoolVolumeContentTypeNameBlock {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid content type %q for volume %q", vol.ContentType, vol.Name)
+		}
+
+		if req.Resources.Volumes[i].ContentType == cluster.StoragePoolVolumeContentTypeNameFS && vol.Path == "" {
+			return api.StatusErrorf(http.StatusBadRequest, "Mount path of filesystem volume %q is required", vol.Name)
+		}
+
+		if vol.Device == "" {
+			req.Resources.Volumes[i].Device = vol.Name
+		}
+
+		_, found := req.Devices[req.Resources.Volumes[i].Device]
+		if found {
+			return api.StatusErrorf(http.StatusBadRequest, "Device %q of volume %q already exists", req.Resources.Volumes[i].Device, vol.Name)
+		}
+	}
+
+	for _, forward := range req.Resources.NetworkForwards {
+		if forward.Network == "" {
+			return api.StatusErrorf(http.StatusBadRequest, "Network of forward %q is required", forward.ListenAddress)
+		}
+	}
+
+	for _, record := range req.Resources.NetworkZoneRecords {
+		if record.Zone == "" {
+			return api.StatusErrorf(http.StatusBadRequest, "Network zone of record %q is required", record.Name)
+		}
+
+		if record.Name == "" {
+			return api.StatusErrorf(http.StatusBadRequest, "Name of record in network zone %q is required", record.Zone)
+		}
+	}
+
+	// Check the same permissions as the individual endpoints would.
+	if len(req.Resources.Volumes) > 0 {
+		err := s.Authorizer.CheckPermission(ctx, r, entity.ProjectURL(projectName), auth.EntitlementCanCreateStorageVolumes)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, forward := range req.Resources.NetworkForwards {
+		err := s.Authorizer.CheckPermission(ctx, r, entity.NetworkURL(projectName, forward.Network), auth.EntitlementCanEdit)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, record := range req.Resources.NetworkZoneRecords {
+		err := s.Authorizer.CheckPermission(ctx, r, entity.NetworkZoneURL(projectName, record.Zone), auth.EntitlementCanEdit)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// instanceCreateWithResources creates the custom volumes requested along with a new instance and attaches them
+// to it, runs the create function and then creates the requested network forwards and zone records for the new
+// instance. If any step fails, everything created so far is removed again, including the instance.
+func instanceCreateWithResources(s *state.State, r *http.Request, req *api.InstancesPost, args *db.InstanceArgs, op *operations.Operation, create func() error) error {
+	if req.Resources == nil {
+		return create()
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	cleanup, err := instanceCreateVolumes(s, args, req.Resources.Volumes, op)
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(cleanup)
+
+	err = create()
+	if err != nil {
+		return err
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, args.Project, args.Name)
+	if err != nil {
+		return fmt.Errorf("Failed loading instance: %w", err)
+	}
+
+	reverter.Add(func() { _ = inst.Delete(true) })
+
+	cleanup, err = instanceCreateNetworkForwards(s, r, inst, req.Resources.NetworkForwards)
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(cleanup)
+
+	cleanup, err = instanceCreateNetworkZoneRecords(s, r, inst, req.Resources.NetworkZoneRecords)
+	if err != nil {
+		return err
+	}
+
+	reverter.Add(cleanup)
+
+	reverter.Success()
+	return nil
+}
+
+// instanceCreateVolumes creates the custom volumes for a new instance and adds disk devices for them to its
+// arguments. Returns a function that deletes the volumes again.
+func instanceCreateVolumes(s *state.State, args *db.InstanceArgs, volumes []api.InstancesPostVolume, op *operations.Operation) (revert.Hook, error) {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if len(volumes) == 0 {
+		return reverter.Clone().Fail, nil
+	}
+
+	projectName, err := project.StorageVolumeProject(s.DB.Cluster, args.Project, cluster.StoragePoolVolumeTypeCustom)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the project limits for all volumes before creating any of them.
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		for _, vol := range volumes {
+			volReq := api.StorageVolumesPost{
+				Name: vol.Name,
+				Type: cluster.StoragePoolVolumeTypeNameCustom,
+				StorageVolumePut: api.StorageVolumePut{
+					Config:      vol.Config,
+					Description: vol.Description,
+				},
+				ContentType: vol.ContentType,
+			}
+
+			err := project.AllowVolumeCreation(s.GlobalConfig, tx, projectName, volReq)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Devices == nil {
+		args.Devices = deviceConfig.Devices{}
+	}
+
+	for _, vol := range volumes {
+		pool, err := storagePools.LoadByName(s, vol.Pool)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading storage pool %q: %w", vol.Pool, err)
+		}
+
+		volumeDBContentType, err := storagePools.VolumeContentTypeNameToContentType(vol.ContentType)
+		if err != nil {
+			return nil, err
+		}
+
+		contentType, err := storagePools.VolumeDBContentTypeToContentType(volumeDBContentType)
+		if err != nil {
+			return nil, err
+		}
+
+		err = pool.CreateCustomVolume(projectName, vol.Name, vol.Description, vol.Config, contentType, op)
+		if err != nil {
+			return nil, fmt.Errorf("Failed creating volume %q: %w", vol.Name, err)
+		}
+
+		reverter.Add(func() { _ = pool.DeleteCustomVolume(projectName, vol.Name, op) })
+
+		device := deviceConfig.Device{
+			"type":   "disk",
+			"pool":   vol.Pool,
+			"source": vol.Name,
+		}
+
+		if vol.Path != "" {
+			device["path"] = vol.Path
+		}
+
+		args.Devices[vol.Device] = device
+	}
+
+	cleanup := reverter.Clone().Fail
+	reverter.Success()
+	return cleanup, nil
+}
+
+// instanceCreateNetworkForwards creates network forwards to a new instance. Returns a function that deletes the
+// forwards again.
+func instanceCreateNetworkForwards(s *state.State, r *http.Request, inst instance.Instance, forwards []api.InstancesPostNetworkForward) (revert.Hook, error) {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if len(forwards) == 0 {
+		return reverter.Clone().Fail, nil
+	}
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, inst.Project().Name)
+	if err != nil {
+		return nil, err
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+
+	for _, forward := range forwards {
+		n, err := network.LoadByName(s, projectName, forward.Network)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading network %q: %w", forward.Network, err)
+		}
+
+		// Check if project allows access to network.
+		if !project.NetworkAllowed(reqProject.Config, forward.Network, n.IsManaged()) {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Network not found")
+		}
+
+		if !n.Info().AddressForwards {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Network driver %q does not support forwards", n.Type())
+		}
+
+		req := forward.NetworkForwardsPost
+		req.Normalise()
+
+		// Target the instance address on the network wherever no other target address is given.
+		listenIP := net.ParseIP(req.ListenAddress)
+		targetAddress := instanceNICStaticAddress(inst, forward.Network, listenIP != nil && listenIP.To4() == nil)
+
+		config := make(map[string]string, len(req.Config)+1)
+		for k, v := range req.Config {
+			config[k] = v
+		}
+
+		req.Config = config
+		if len(req.Ports) == 0 && req.Config["target_address"] == "" {
+			if targetAddress == "" {
+				return nil, api.StatusErrorf(http.StatusBadRequest, "Instance has no static address on network %q to forward %q to", forward.Network, req.ListenAddress)
+			}
+
+			req.Config["target_address"] = targetAddress
+		}
+
+		ports := make([]api.NetworkForwardPort, 0, len(req.Ports))
+		for _, port := range req.Ports {
+			if port.TargetAddress == "" {
+				if targetAddress == "" {
+					return nil, api.StatusErrorf(http.StatusBadRequest, "Instance has no static address on network %q to forward %q to", forward.Network, req.ListenAddress)
+				}
+
+				port.TargetAddress = targetAddress
+			}
+
+			ports = append(ports, port)
+		}
+
+		req.Ports = ports
+
+		listenAddress, err := n.ForwardCreate(req, clientType)
+		if err != nil {
+			return nil, fmt.Errorf("Failed creating forward on network %q: %w", forward.Network, err)
+		}
+
+		reverter.Add(func() { _ = n.ForwardDelete(listenAddress.String(), clientType) })
+
+		s.Events.SendLifecycle(projectName, lifecycle.NetworkForwardCreated.Event(n, listenAddress.String(), request.CreateRequestor(r), nil))
+	}
+
+	cleanup := reverter.Clone().Fail
+	reverter.Success()
+	return cleanup, nil
+}
+
+// instanceCreateNetworkZoneRecords creates network zone records for a new instance. Returns a function that
+// deletes the records again.
+func instanceCreateNetworkZoneRecords(s *state.State, r *http.Request, inst instance.Instance, records []api.InstancesPostNetworkZoneRecord) (revert.Hook, error) {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if len(records) == 0 {
+		return reverter.Clone().Fail, nil
+	}
+
+	projectName, _, err := project.NetworkZoneProject(s.DB.Cluster, inst.Project().Name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		netzone, err := zone.LoadByNameAndProject(s, projectName, record.Zone)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading network zone %q: %w", record.Zone, err)
+		}
+
+		req := record.NetworkZoneRecordsPost
+		if len(req.Entries) == 0 {
+			req.Entries = instanceNICStaticEntries(inst)
+			if len(req.Entries) == 0 {
+				return nil, api.StatusErrorf(http.StatusBadRequest, "Instance has no static addresses for record %q in network zone %q", req.Name, record.Zone)
+			}
+		}
+
+		err = netzone.AddRecord(req)
+		if err != nil {
+			return nil, fmt.Errorf("Failed creating record %q in network zone %q: %w", req.Name, record.Zone, err)
+		}
+
+		reverter.Add(func() { _ = netzone.DeleteRecord(req.Name) })
+
+		s.Events.SendLifecycle(projectName, lifecycle.NetworkZoneRecordCreated.Event(netzone, req.Name, request.CreateRequestor(r), nil))
+	}
+
+	cleanup := reverter.Clone().Fail
+	reverter.Success()
+	return cleanup, nil
+}
+
+// instanceNICStaticAddress returns the static IPv4 or IPv6 address of the first instance NIC connected to the
+// network, or an empty string if there is none.
+func instanceNICStaticAddress(inst instance.Instance, networkName string, ipv6 bool) string {
+	key := "ipv4.address"
+	if ipv6 {
+		key = "ipv6.address"
+	}
+
+	for _, dev := range inst.ExpandedDevices().Sorted() {
+		if dev.Config["type"] == "nic" && dev.Config["network"] == networkName && dev.Config[key] != "" {
+			return dev.Config[key]
+		}
+	}
+
+	return ""
+}
+
+// instanceNICStaticEntries returns A and AAAA record entries for the static addresses of the instance NICs.
+func instanceNICStaticEntries(inst instance.Instance) []api.NetworkZoneRecordEntry {
+	var entries []api.NetworkZoneRecordEntry
+
+	for _, dev := range inst.ExpandedDevices().Sorted() {
+		if dev.Config["type"] != "nic" {
+			continue
+		}
+
+		if dev.Config["ipv4.address"] != "" {
+			entries = append(entries, api.NetworkZoneRecordEntry{Type: "A", Value: dev.Config["ipv4.address"]})
+		}
+
+		if dev.Config["ipv6.address"] != "" {
+			entries = append(entries, api.NetworkZoneRecordEntry{Type: "AAAA", Value: dev.Config["ipv6.address"]})
+		}
+	}
+
+	return entries
+}
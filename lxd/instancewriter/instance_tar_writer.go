@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/canonical/lxd/lxd/idmap"
 	"github.com/canonical/lxd/shared"
@@ -16,9 +17,10 @@ import (
 
 // InstanceTarWriter provides a TarWriter implementation that handles ID shifting and hardlink tracking.
 type InstanceTarWriter struct {
-	tarWriter *tar.Writer
-	idmapSet  *idmap.IdmapSet
-	linkMap   map[uint64]string
+	tarWriter    *tar.Writer
+	idmapSet     *idmap.IdmapSet
+	linkMap      map[uint64]string
+	reproducible bool
 }
 
 // NewInstanceTarWriter returns a ContainerTarWriter for the provided target Writer and id map.
@@ -36,6 +38,25 @@ func (ctw *InstanceTarWriter) ResetHardLinkMap() {
 	ctw.linkMap = map[uint64]string{}
 }
 
+// SetReproducible makes the tarball only depend on the names, content, ownership and permissions of the files, by
+// setting all timestamps to the Unix epoch and leaving out the user and group names.
+func (ctw *InstanceTarWriter) SetReproducible(reproducible bool) {
+	ctw.reproducible = reproducible
+}
+
+// normalizeHeader clears the fields of the header which vary between runs when the tarball is reproducible.
+func (ctw *InstanceTarWriter) normalizeHeader(hdr *tar.Header) {
+	if !ctw.reproducible {
+		return
+	}
+
+	hdr.ModTime = time.Unix(0, 0)
+	hdr.AccessTime = time.Time{}
+	hdr.ChangeTime = time.Time{}
+	hdr.Uname = ""
+	hdr.Gname = ""
+}
+
 // WriteFile adds a file to the tarball with the specified name using the srcPath file as the contents of the file.
 // The ignoreGrowth argument indicates whether to error if the srcPath file increases in size beyond the size in fi
 // during the write. If false the write will return an error. If true, no error is returned, instead only the size
@@ -140,6 +161,8 @@ func (ctw *InstanceTarWriter) WriteFile(name string, srcPath string, fi os.FileI
 		}
 	}
 
+	ctw.normalizeHeader(hdr)
+
 	err = ctw.tarWriter.WriteHeader(hdr)
 	if err != nil {
 		return fmt.Errorf("Failed to write tar header: %w", err)
@@ -180,6 +203,8 @@ func (ctw *InstanceTarWriter) WriteFileFromReader(src io.Reader, fi os.FileInfo)
 		return fmt.Errorf("Failed to create tar info header: %w", err)
 	}
 
+	ctw.normalizeHeader(hdr)
+
 	err = ctw.tarWriter.WriteHeader(hdr)
 	if err != nil {
 		return fmt.Errorf("Failed to write tar header: %w", err)
@@ -0,0 +1,76 @@
+package ip
+
+import (
+	"net"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// Fdb represents arguments for bridge forwarding database manipulation.
+type Fdb struct {
+	DevName string
+	MAC     net.HardwareAddr
+	Dst     net.IP
+}
+
+// Show lists the forwarding database entries of the device which have a remote destination, filtered by MAC
+// address if specified.
+func (f *Fdb) Show() ([]Fdb, error) {
+	out, err := shared.RunCommand("bridge", "fdb", "show", "dev", f.DevName)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Fdb{}
+
+	for _, line := range shared.SplitNTrimSpace(out, "\n", -1, true) {
+		// Entries with a remote destination look like "00:00:00:00:00:00 dst 192.0.2.1 self permanent".
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] != "dst" {
+			continue
+		}
+
+		mac, err := net.ParseMAC(fields[0])
+		if err != nil {
+			continue
+		}
+
+		if f.MAC != nil && mac.String() != f.MAC.String() {
+			continue
+		}
+
+		dst := net.ParseIP(fields[2])
+		if dst == nil {
+			continue
+		}
+
+		entries = append(entries, Fdb{
+			DevName: f.DevName,
+			MAC:     mac,
+			Dst:     dst,
+		})
+	}
+
+	return entries, nil
+}
+
+// Append adds a forwarding database entry, keeping any existing entry for the same MAC address.
+func (f *Fdb) Append() error {
+	_, err := shared.RunCommand("bridge", "fdb", "append", f.MAC.String(), "dev", f.DevName, "dst", f.Dst.String())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes a forwarding database entry.
+func (f *Fdb) Delete() error {
+	_, err := shared.RunCommand("bridge", "fdb", "delete", f.MAC.String(), "dev", f.DevName, "dst", f.Dst.String())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
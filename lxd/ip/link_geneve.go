@@ -0,0 +1,30 @@
+package ip
+
+// Geneve represents arguments for link of type geneve.
+type Geneve struct {
+	Link
+	ID      string
+	Remote  string
+	DstPort string
+	TTL     string
+}
+
+// additionalArgs generates geneve specific arguments.
+func (g *Geneve) additionalArgs() []string {
+	args := []string{"id", g.ID, "remote", g.Remote}
+
+	if g.TTL != "" {
+		args = append(args, "ttl", g.TTL)
+	}
+
+	if g.DstPort != "" {
+		args = append(args, "dstport", g.DstPort)
+	}
+
+	return args
+}
+
+// Add adds new virtual link.
+func (g *Geneve) Add() error {
+	return g.Link.add("geneve", g.additionalArgs())
+}
@@ -0,0 +1,32 @@
+package lifecycle
+
+import (
+	"fmt"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// AuthApprovalAction represents a lifecycle event action for approvals of privileged actions.
+type AuthApprovalAction string
+
+// All supported lifecycle events for approvals.
+const (
+	AuthApprovalCreated  = AuthApprovalAction(api.EventLifecycleAuthApprovalCreated)
+	AuthApprovalApproved = AuthApprovalAction(api.EventLifecycleAuthApprovalApproved)
+	AuthApprovalRejected = AuthApprovalAction(api.EventLifecycleAuthApprovalRejected)
+	AuthApprovalUsed     = AuthApprovalAction(api.EventLifecycleAuthApprovalUsed)
+	AuthApprovalDeleted  = AuthApprovalAction(api.EventLifecycleAuthApprovalDeleted)
+)
+
+// Event creates the lifecycle event for an action on an approval.
+func (a AuthApprovalAction) Event(id int64, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "auth", "approvals", fmt.Sprint(id))
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -0,0 +1,36 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// Internal copy of the instance pool interface.
+type instancePool interface {
+	Info() *api.InstancePool
+	Project() string
+}
+
+// InstancePoolAction represents a lifecycle event action for instance pools.
+type InstancePoolAction string
+
+// All supported lifecycle events for instance pools.
+const (
+	InstancePoolCreated          = InstancePoolAction(api.EventLifecycleInstancePoolCreated)
+	InstancePoolDeleted          = InstancePoolAction(api.EventLifecycleInstancePoolDeleted)
+	InstancePoolUpdated          = InstancePoolAction(api.EventLifecycleInstancePoolUpdated)
+	InstancePoolInstanceAcquired = InstancePoolAction(api.EventLifecycleInstancePoolInstanceAcquired)
+	InstancePoolInstanceReleased = InstancePoolAction(api.EventLifecycleInstancePoolInstanceReleased)
+)
+
+// Event creates the lifecycle event for an action on an instance pool.
+func (a InstancePoolAction) Event(p instancePool, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "instance-pools", p.Info().Name).Project(p.Project())
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
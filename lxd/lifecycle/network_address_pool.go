@@ -0,0 +1,33 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// Internal copy of the network address pool interface.
+type networkAddressPool interface {
+	Info() *api.NetworkAddressPool
+}
+
+// NetworkAddressPoolAction represents a lifecycle event action for network address pools.
+type NetworkAddressPoolAction string
+
+// All supported lifecycle events for network address pools.
+const (
+	NetworkAddressPoolCreated = NetworkAddressPoolAction(api.EventLifecycleNetworkAddressPoolCreated)
+	NetworkAddressPoolDeleted = NetworkAddressPoolAction(api.EventLifecycleNetworkAddressPoolDeleted)
+	NetworkAddressPoolUpdated = NetworkAddressPoolAction(api.EventLifecycleNetworkAddressPoolUpdated)
+)
+
+// Event creates the lifecycle event for an action on a network address pool.
+func (a NetworkAddressPoolAction) Event(n networkAddressPool, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "network-address-pools", n.Info().Name)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// SeccompPolicyAction represents a lifecycle event action for syscall interception policies.
+type SeccompPolicyAction string
+
+// All supported lifecycle events for syscall interception policies.
+const (
+	SeccompPolicyCreated = SeccompPolicyAction(api.EventLifecycleSeccompPolicyCreated)
+	SeccompPolicyDeleted = SeccompPolicyAction(api.EventLifecycleSeccompPolicyDeleted)
+	SeccompPolicyUpdated = SeccompPolicyAction(api.EventLifecycleSeccompPolicyUpdated)
+)
+
+// Event creates the lifecycle event for an action on a syscall interception policy.
+func (a SeccompPolicyAction) Event(projectName string, name string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "seccomp-policies", name).Project(projectName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
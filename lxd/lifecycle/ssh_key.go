@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// SSHKeyAction represents a lifecycle event action for SSH keys.
+type SSHKeyAction string
+
+// All supported lifecycle events for SSH keys.
+const (
+	SSHKeyCreated = SSHKeyAction(api.EventLifecycleSSHKeyCreated)
+	SSHKeyDeleted = SSHKeyAction(api.EventLifecycleSSHKeyDeleted)
+	SSHKeyUpdated = SSHKeyAction(api.EventLifecycleSSHKeyUpdated)
+)
+
+// Event creates the lifecycle event for an action on an SSH key.
+func (a SSHKeyAction) Event(projectName string, name string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "ssh-keys", name).Project(projectName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -113,6 +113,10 @@ func main() {
 	activateifneededCmd := cmdActivateifneeded{global: &globalCmd}
 	app.AddCommand(activateifneededCmd.Command())
 
+	// admin sub-command
+	adminCmd := cmdAdmin{global: &globalCmd}
+	app.AddCommand(adminCmd.Command())
+
 	// callhook sub-command
 	callhookCmd := cmdCallhook{global: &globalCmd}
 	app.AddCommand(callhookCmd.Command())
@@ -165,6 +169,10 @@ func main() {
 	forkproxyCmd := cmdForkproxy{global: &globalCmd}
 	app.AddCommand(forkproxyCmd.Command())
 
+	// forksandbox sub-command
+	forksandboxCmd := cmdForksandbox{global: &globalCmd}
+	app.AddCommand(forksandboxCmd.Command())
+
 	// forkstart sub-command
 	forkstartCmd := cmdForkstart{global: &globalCmd}
 	app.AddCommand(forkstartCmd.Command())
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/canonical/go-dqlite/driver"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/cluster"
+	clusterConfig "github.com/canonical/lxd/lxd/cluster/config"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/node"
+	"github.com/canonical/lxd/lxd/util"
+)
+
+type cmdAdmin struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAdmin) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "admin"
+	cmd.Short = "Low-level administration commands"
+	cmd.Long = `Description:
+  Low level administration tools for inspecting and repairing the state of LXD.
+`
+	// Apply patches
+	applyPatches := cmdAdminApplyPatches{global: c.global}
+	cmd.AddCommand(applyPatches.Command())
+
+	// Verify patches
+	verifyPatches := cmdAdminVerifyPatches{global: c.global}
+	cmd.AddCommand(verifyPatches.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdAdminApplyPatches struct {
+	global *cmdGlobal
+
+	flagOffline bool
+}
+
+func (c *cmdAdminApplyPatches) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "apply-patches"
+	cmd.Short = "Apply the pending patches while LXD is stopped"
+	cmd.Long = `Description:
+  Apply the pending patches while LXD is stopped
+
+  The local and global databases are opened directly, and the patches that
+  LXD applies before setting up its storage are applied, along with any
+  pending database schema update. This helps recovering from a patch that
+  fails and prevents LXD from starting, without having to start LXD.
+
+  The remaining patches are applied the next time LXD starts.
+
+  LXD must be stopped, and this only works on standalone servers, as
+  cluster members need the rest of the cluster to access the global
+  database.
+`
+	cmd.Args = cobra.NoArgs
+	cmd.RunE = c.Run
+	cmd.Flags().BoolVar(&c.flagOffline, "offline", false, "Open the databases directly while LXD is stopped")
+
+	return cmd
+}
+
+func (c *cmdAdminApplyPatches) Run(cmd *cobra.Command, args []string) error {
+	if !c.flagOffline {
+		return fmt.Errorf("Patches can only be applied with --offline, LXD applies them when it starts otherwise")
+	}
+
+	// Both LXD and this command would write to the databases.
+	_, err := lxd.ConnectLXDUnix("", nil)
+	if err == nil {
+		return fmt.Errorf("LXD is running, stop it before applying patches offline")
+	}
+
+	d := defaultDaemon()
+	defer d.shutdownCancel()
+
+	err = initializeDbObject(d)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = d.db.Node.Close() }()
+
+	clustered, err := cluster.Enabled(d.db.Node)
+	if err != nil {
+		return fmt.Errorf("Failed checking if clustered: %w", err)
+	}
+
+	if clustered {
+		return fmt.Errorf("Patches can only be applied offline on standalone servers")
+	}
+
+	err = d.db.Node.Transaction(context.TODO(), func(ctx context.Context, tx *db.NodeTx) error {
+		d.localConfig, err = node.ConfigLoad(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	networkCert, err := util.LoadCert(d.os.VarDir)
+	if err != nil {
+		return err
+	}
+
+	d.gateway, err = cluster.NewGateway(d.shutdownCtx, d.db.Node, networkCert, d.State, cluster.Latency(d.config.RaftLatency))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = d.gateway.Shutdown() }()
+
+	options := []driver.Option{
+		driver.WithDialFunc(d.gateway.DialFunc()),
+		driver.WithContext(d.gateway.Context()),
+		driver.WithConnectionTimeout(10 * time.Second),
+		driver.WithContextTimeout(time.Minute),
+		driver.WithLogFunc(cluster.DqliteLog),
+	}
+
+	d.db.Cluster, err = db.OpenCluster(context.Background(), "db.bin", d.gateway.NodeStore(), "", filepath.Join(d.os.VarDir, "database"), time.Minute, nil, options...)
+	if err != nil {
+		return fmt.Errorf("Failed to open global database: %w", err)
+	}
+
+	defer func() { _ = d.db.Cluster.Close() }()
+
+	d.gateway.Cluster = d.db.Cluster
+
+	// Apply the patches in the same order as LXD does when it starts.
+	err = patchesApply(d, patchPreLoadClusterConfig)
+	if err != nil {
+		return err
+	}
+
+	err = d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		d.globalConfig, err = clusterConfig.Load(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		d.serverName, err = tx.GetLocalNodeName(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = patchesApply(d, patchPreDaemonStorage)
+	if err != nil {
+		return err
+	}
+
+	applied := d.patchesStatus.list()
+	if len(applied) == 0 {
+		fmt.Println("No pending patches")
+		return nil
+	}
+
+	for _, status := range applied {
+		fmt.Printf("Patch %q: %s\n", status.Name, status.Status)
+	}
+
+	return nil
+}
+
+type cmdAdminVerifyPatches struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAdminVerifyPatches) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "verify-patches"
+	cmd.Short = "Check that the applied patches fixed all entities"
+	cmd.Long = `Description:
+  Check that the applied patches fixed all entities
+
+  The detection logic of each applied patch is run again without modifying
+  anything, and the entities still matching the condition the patch is meant
+  to fix are reported, for example storage volumes missing volatile.uuid.
+
+  Reported patches can be applied again with:
+    lxc query -X POST /internal/patches/<name>/rerun
+
+  The command fails if any entity is reported.
+`
+	cmd.Args = cobra.NoArgs
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdAdminVerifyPatches) Run(cmd *cobra.Command, args []string) error {
+	// Connect to LXD
+	d, err := lxd.ConnectLXDUnix("", &lxd.ConnectionArgs{SkipGetServer: true})
+	if err != nil {
+		return err
+	}
+
+	response, _, err := d.RawQuery("GET", "/internal/patches/verify", nil, "")
+	if err != nil {
+		return fmt.Errorf("Failed to verify patches: %w", err)
+	}
+
+	results := []internalPatchVerifyResult{}
+	err = json.Unmarshal(response.Metadata, &results)
+	if err != nil {
+		return fmt.Errorf("Failed to parse verify response: %w", err)
+	}
+
+	if len(results) == 0 {
+		fmt.Println("All applied patches verified")
+		return nil
+	}
+
+	for _, result := range results {
+		fmt.Printf("Patch %q didn't fix %d entities:\n", result.Name, len(result.Entities))
+		for _, entity := range result.Entities {
+			fmt.Printf("  - %s\n", entity)
+		}
+	}
+
+	return fmt.Errorf("%d applied patches didn't fix all entities", len(results))
+}
@@ -580,6 +580,7 @@ import "C"
 
 import (
 	_ "github.com/canonical/lxd/lxd/include" // Used by cgo
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/logger"
 )
 
@@ -634,3 +635,7 @@ func canUsePidFdSetns() bool {
 func canUseCoreScheduling() bool {
 	return bool(C.core_scheduling_aware)
 }
+
+func canUseTimeNamespace() bool {
+	return shared.PathExists("/proc/self/ns/time")
+}
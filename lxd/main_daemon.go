@@ -11,6 +11,7 @@ import (
 	"golang.org/x/sys/unix"
 
 	"github.com/canonical/lxd/lxd/sys"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/logger"
 )
 
@@ -18,7 +19,9 @@ type cmdDaemon struct {
 	global *cmdGlobal
 
 	// Common options
-	flagGroup string
+	flagGroup        string
+	flagUpgradeCheck bool
+	flagSkipPatches  []string
 }
 
 func (c *cmdDaemon) Command() *cobra.Command {
@@ -36,6 +39,8 @@ func (c *cmdDaemon) Command() *cobra.Command {
 `
 	cmd.RunE = c.Run
 	cmd.Flags().StringVar(&c.flagGroup, "group", "", "The group of users that will be allowed to talk to LXD"+"``")
+	cmd.Flags().StringSliceVar(&c.flagSkipPatches, "skip-patch", nil, "Mark the given patches as applied without running them, for recovering from a patch that prevents the daemon from starting (can also be set through LXD_SKIP_PATCHES)"+"``")
+	cmd.Flags().BoolVar(&c.flagUpgradeCheck, "upgrade-check", false, "Report the database updates and patches this version would apply to the running daemon and check that the cluster can be upgraded, without starting the daemon")
 
 	return cmd
 }
@@ -50,6 +55,10 @@ func (c *cmdDaemon) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("This must be run as root")
 	}
 
+	if c.flagUpgradeCheck {
+		return c.upgradeCheck()
+	}
+
 	neededPrograms := []string{"ip", "rsync", "setfattr", "tar", "unsquashfs", "xz"}
 	for _, p := range neededPrograms {
 		_, err := exec.LookPath(p)
@@ -58,11 +67,20 @@ func (c *cmdDaemon) Run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	skipPatches := append(c.flagSkipPatches, shared.SplitNTrimSpace(os.Getenv("LXD_SKIP_PATCHES"), ",", -1, true)...)
+	for _, name := range skipPatches {
+		if !shared.ValueInSlice(name, patchesGetNames()) {
+			return fmt.Errorf("Unknown patch %q", name)
+		}
+	}
+
 	defer logger.Info("Daemon stopped")
 
 	conf := defaultDaemonConfig()
 	conf.Group = c.flagGroup
 	conf.Trace = c.global.flagLogTrace
+	conf.SkipPatches = skipPatches
+	conf.LogFile = c.global.flagLogFile
 	d := newDaemon(conf, sys.DefaultOS())
 
 	sigCh := make(chan os.Signal, 1)
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/canonical/lxd/client"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// upgradeCheckMember holds the versions a cluster member is currently running.
+type upgradeCheckMember struct {
+	name          string
+	address       string
+	schema        int
+	apiExtensions int
+}
+
+// upgradeCheck is run by the new LXD binary before it replaces the running daemon. It reports the schema updates
+// and patches that would be applied to the running daemon's database along with their risk, and fails if the
+// cluster members are in a state where starting this version would not complete the upgrade.
+//
+// The running daemon is queried through the internal SQL API only, since it is likely older than this binary and
+// doesn't know about any endpoint added since.
+func (c *cmdDaemon) upgradeCheck() error {
+	d, err := lxd.ConnectLXDUnix("", &lxd.ConnectionArgs{SkipGetServer: true})
+	if err != nil {
+		return fmt.Errorf("Failed to connect to the running LXD daemon: %w", err)
+	}
+
+	members, err := upgradeCheckMembers(d)
+	if err != nil {
+		return err
+	}
+
+	response, _, err := d.RawQuery("GET", "/internal/sql?database=global", nil, "")
+	if err != nil {
+		return fmt.Errorf("Failed to dump global database: %w", err)
+	}
+
+	dump := internalSQLDump{}
+	err = json.Unmarshal(response.Metadata, &dump)
+	if err != nil {
+		return fmt.Errorf("Failed to parse global database dump: %w", err)
+	}
+
+	current, updates, err := dbCluster.DryRunUpdates(context.Background(), dump.Text)
+	if err != nil {
+		return fmt.Errorf("Failed dry run of global database updates: %w", err)
+	}
+
+	rows, err := upgradeCheckQuery(d, "local", "SELECT name FROM patches")
+	if err != nil {
+		return fmt.Errorf("Failed to get applied patches: %w", err)
+	}
+
+	appliedPatches := make([]string, 0, len(rows))
+	for _, row := range rows {
+		appliedPatches = append(appliedPatches, fmt.Sprint(row[0]))
+	}
+
+	fmt.Printf("Global database schema: %d -> %d\n", current, dbCluster.SchemaVersion)
+	if len(updates) > 0 {
+		table := upgradeCheckTable([]string{"VERSION", "ROWS CHANGED", "RISK"})
+		for _, update := range updates {
+			risk := "metadata-only"
+			if update.Changes > 0 {
+				risk = "data-rewriting"
+			}
+
+			table.Append([]string{strconv.Itoa(update.Version), strconv.FormatInt(update.Changes, 10), risk})
+		}
+
+		table.Render()
+	}
+
+	pending := [][]string{}
+	for _, patch := range patches {
+		if shared.ValueInSlice(patch.name, appliedPatches) {
+			continue
+		}
+
+		// Patches run arbitrary code against the database and the filesystem.
+		pending = append(pending, []string{patch.name, patch.stage.String(), "data-rewriting"})
+	}
+
+	fmt.Printf("\nPending patches: %d\n", len(pending))
+	if len(pending) > 0 {
+		table := upgradeCheckTable([]string{"NAME", "STAGE", "RISK"})
+		table.AppendBulk(pending)
+		table.Render()
+	}
+
+	fmt.Printf("\nCluster members: %d\n", len(members))
+	table := upgradeCheckTable([]string{"NAME", "ADDRESS", "SCHEMA", "API EXTENSIONS"})
+	for _, member := range members {
+		table.Append([]string{member.name, member.address, strconv.Itoa(member.schema), strconv.Itoa(member.apiExtensions)})
+	}
+
+	table.Render()
+
+	err = upgradeCheckCompatible(members, dbCluster.SchemaVersion, len(version.APIExtensions))
+	if err != nil {
+		return fmt.Errorf("Upgrade refused: %w", err)
+	}
+
+	fmt.Println("\nThe upgrade can proceed")
+
+	return nil
+}
+
+// upgradeCheckCompatible checks that the cluster members can all be brought to the given versions. Members must
+// not be ahead of them, and the ones that still need upgrading must all run the same version, as otherwise a
+// previous upgrade hasn't completed yet and the cluster would be left waiting on members across several versions.
+func upgradeCheckCompatible(members []upgradeCheckMember, schema int, apiExtensions int) error {
+	var previous *upgradeCheckMember
+	for i, member := range members {
+		if member.schema > schema || member.apiExtensions > apiExtensions {
+			return fmt.Errorf("Member %q is running a newer version (schema %d, API extensions %d) than this one (schema %d, API extensions %d)", member.name, member.schema, member.apiExtensions, schema, apiExtensions)
+		}
+
+		if member.schema == schema && member.apiExtensions == apiExtensions {
+			continue // Already upgraded.
+		}
+
+		if previous != nil && (member.schema != previous.schema || member.apiExtensions != previous.apiExtensions) {
+			return fmt.Errorf("Members %q (schema %d, API extensions %d) and %q (schema %d, API extensions %d) are running different versions, complete the previous upgrade first", previous.name, previous.schema, previous.apiExtensions, member.name, member.schema, member.apiExtensions)
+		}
+
+		previous = &members[i]
+	}
+
+	return nil
+}
+
+// upgradeCheckMembers returns the versions of all cluster members as recorded in the global database.
+func upgradeCheckMembers(d lxd.InstanceServer) ([]upgradeCheckMember, error) {
+	rows, err := upgradeCheckQuery(d, "global", "SELECT name, address, schema, api_extensions FROM nodes ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get cluster members: %w", err)
+	}
+
+	members := make([]upgradeCheckMember, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 4 {
+			return nil, fmt.Errorf("Unexpected number of columns for cluster member: %d", len(row))
+		}
+
+		// Numbers are decoded from JSON as floats.
+		schema, ok := row[2].(float64)
+		if !ok {
+			return nil, fmt.Errorf("Invalid schema version for cluster member %q", row[0])
+		}
+
+		apiExtensions, ok := row[3].(float64)
+		if !ok {
+			return nil, fmt.Errorf("Invalid API extensions count for cluster member %q", row[0])
+		}
+
+		members = append(members, upgradeCheckMember{
+			name:          fmt.Sprint(row[0]),
+			address:       fmt.Sprint(row[1]),
+			schema:        int(schema),
+			apiExtensions: int(apiExtensions),
+		})
+	}
+
+	return members, nil
+}
+
+// upgradeCheckQuery runs a single SELECT query against the given database of the running daemon.
+func upgradeCheckQuery(d lxd.InstanceServer, database string, query string) ([][]any, error) {
+	data := internalSQLQuery{
+		Database: database,
+		Query:    query,
+	}
+
+	response, _, err := d.RawQuery("POST", "/internal/sql", data, "")
+	if err != nil {
+		return nil, err
+	}
+
+	batch := internalSQLBatch{}
+	err = json.Unmarshal(response.Metadata, &batch)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(batch.Results) != 1 || batch.Results[0].Type != "select" {
+		return nil, fmt.Errorf("Unexpected query result")
+	}
+
+	return batch.Results[0].Rows, nil
+}
+
+// upgradeCheckTable returns a table writer for the upgrade check report.
+func upgradeCheckTable(header []string) *tablewriter.Table {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader(header)
+
+	return table
+}
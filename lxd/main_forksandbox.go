@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/lxd/sandbox"
+)
+
+type cmdForksandbox struct {
+	global *cmdGlobal
+}
+
+// Command returns a cobra command for `lxd forksandbox`.
+func (c *cmdForksandbox) Command() *cobra.Command {
+	// Main subcommand
+	cmd := &cobra.Command{}
+	cmd.Use = "forksandbox <capabilities> -- <command> [<arguments>...]"
+	cmd.Short = "Run a network helper with restricted capabilities"
+	cmd.Long = `Description:
+  Run a network helper with restricted capabilities
+
+  This internal command is used to confine the network helpers spawned by LXD, such as dnsmasq and forkdns.
+  All the capabilities but the comma separated list of capabilities are dropped from the bounding set
+  before the command is executed.
+`
+	cmd.RunE = c.Run
+	cmd.Hidden = true
+
+	return cmd
+}
+
+// Run executes the `lxd forksandbox` command.
+func (c *cmdForksandbox) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	if len(args) < 2 {
+		_ = cmd.Help()
+
+		if len(args) == 0 {
+			return nil
+		}
+
+		return fmt.Errorf("Missing required arguments")
+	}
+
+	return sandbox.Exec(strings.Split(args[0], ","), args[1:])
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/pem"
 	"fmt"
+	"net"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -202,6 +203,11 @@ func (c *cmdInit) Run(cmd *cobra.Command, args []string) error {
 		config.Node.Config["cluster.https_address"] = config.Node.Config["core.https_address"]
 	}
 
+	err = validateAddressFamilies(config)
+	if err != nil {
+		return err
+	}
+
 	// Detect if the user has chosen to join a cluster using the new
 	// cluster join API format, and use the dedicated API if so.
 	if config.Cluster != nil && config.Cluster.ClusterAddress != "" && config.Cluster.ServerAddress != "" {
@@ -241,6 +247,57 @@ func (c *cmdInit) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// validateAddressFamilies checks that the addresses and networks of the configuration can be used on hosts which
+// only have IPv6 addresses, so that such hosts fail early rather than with a partially applied configuration.
+func validateAddressFamilies(config *api.InitPreseed) error {
+	hasIPv4, hasIPv6 := util.NetworkInterfaceAddressFamilies()
+	if hasIPv4 || !hasIPv6 {
+		return nil
+	}
+
+	addresses := [][2]string{}
+	for _, key := range []string{"core.https_address", "cluster.https_address"} {
+		value, ok := config.Node.Config[key].(string)
+		if ok && value != "" {
+			addresses = append(addresses, [2]string{key, value})
+		}
+	}
+
+	if config.Cluster != nil {
+		addresses = append(addresses, [2]string{"server_address", config.Cluster.ServerAddress}, [2]string{"cluster_address", config.Cluster.ClusterAddress})
+	}
+
+	for _, address := range addresses {
+		if address[1] == "" {
+			continue
+		}
+
+		host, _, err := net.SplitHostPort(util.CanonicalNetworkAddress(address[1], shared.HTTPSDefaultPort))
+		if err != nil {
+			continue
+		}
+
+		ip := net.ParseIP(host)
+		if ip == nil || ip.To4() == nil || ip.IsLoopback() {
+			continue
+		}
+
+		if ip.IsUnspecified() {
+			return fmt.Errorf("The %q address %q only listens on IPv4 but this host only has IPv6 addresses, use \"[::]\" instead", address[0], address[1])
+		}
+
+		return fmt.Errorf("The %q address %q is an IPv4 address but this host only has IPv6 addresses", address[0], address[1])
+	}
+
+	for _, network := range config.Node.Networks {
+		if network.Config["bridge.mode"] == "fan" {
+			return fmt.Errorf("Network %q uses the fan which requires IPv4 but this host only has IPv6 addresses, use bridge.mode=overlay instead", network.Name)
+		}
+	}
+
+	return nil
+}
+
 func (c *cmdInit) defaultHostname() string {
 	if c.hostname != "" {
 		return c.hostname
@@ -130,7 +130,7 @@ func (c *cmdInit) askClustering(config *api.InitPreseed, d lxd.InstanceServer, s
 			address := util.CanonicalNetworkAddress(value, shared.HTTPSDefaultPort)
 
 			host, _, _ := net.SplitHostPort(address)
-			if shared.ValueInSlice(host, []string{"", "[::]", "0.0.0.0"}) {
+			if shared.ValueInSlice(host, []string{"", "::", "0.0.0.0"}) {
 				return fmt.Errorf("Invalid IP address or DNS name")
 			}
 
@@ -343,6 +343,10 @@ func (c *cmdInit) askNetworking(config *api.InitPreseed, d lxd.InstanceServer) e
 			}
 		}
 
+		// The fan requires an IPv4 underlay, don't offer it on IPv6-only hosts.
+		_, _, err := network.DefaultGatewaySubnetV4()
+		hasIPv4Gateway := err == nil
+
 		useExistingInterface, err := c.global.asker.AskBool("Would you like to configure LXD to use an existing bridge or host interface? (yes/no) [default=no]: ", "no")
 		if err != nil {
 			return err
@@ -401,7 +405,7 @@ func (c *cmdInit) askNetworking(config *api.InitPreseed, d lxd.InstanceServer) e
 
 				break
 			}
-		} else if config.Cluster != nil && fanKernel {
+		} else if config.Cluster != nil && fanKernel && hasIPv4Gateway {
 			fan, err := c.global.asker.AskBool("Would you like to create a new Fan overlay network? (yes/no) [default=yes]: ", "yes")
 			if err != nil {
 				return err
@@ -26,6 +26,9 @@ func (c *cmdWaitready) Command() *cobra.Command {
   This command will block until LXD is reachable over its REST API and
   is done with early start tasks like re-starting previously started
   containers.
+
+  With --verbose, the progress of the patches applied while LXD starts
+  up is shown.
 `
 	cmd.RunE = c.Run
 	cmd.Flags().IntVarP(&c.flagTimeout, "timeout", "t", 0, "Number of seconds to wait before giving up"+"``")
@@ -37,6 +40,7 @@ func (c *cmdWaitready) Run(cmd *cobra.Command, args []string) error {
 	finger := make(chan error, 1)
 	var errLast error
 	go func() {
+		var lastPatchProgress string
 		for i := 0; ; i++ {
 			// Start logging only after the 10'th attempt (about 5
 			// seconds). Then after the 30'th attempt (about 15
@@ -59,6 +63,7 @@ func (c *cmdWaitready) Run(cmd *cobra.Command, args []string) error {
 					logger.Debugf("Failed connecting to LXD daemon (attempt %d): %v", i, err)
 				}
 
+				lastPatchProgress = c.logPatchProgress(lastPatchProgress)
 				time.Sleep(500 * time.Millisecond)
 				continue
 			}
@@ -96,3 +101,43 @@ func (c *cmdWaitready) Run(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// logPatchProgress logs the progress of the patch the daemon is applying while starting up, unless it is the
+// same as the last one. Returns the logged progress.
+func (c *cmdWaitready) logPatchProgress(last string) string {
+	// The public API isn't available until the patches are applied.
+	d, err := lxd.ConnectLXDUnix("", &lxd.ConnectionArgs{SkipGetServer: true})
+	if err != nil {
+		return last
+	}
+
+	patches := []internalPatchStatus{}
+	resp, _, err := d.RawQuery("GET", "/internal/patches/status", nil, "")
+	if err != nil {
+		return last
+	}
+
+	err = resp.MetadataAsStruct(&patches)
+	if err != nil {
+		return last
+	}
+
+	for _, patch := range patches {
+		if patch.Status != patchStatusRunning {
+			continue
+		}
+
+		progress := fmt.Sprintf("Applying patch %q", patch.Name)
+		if patch.Progress != "" {
+			progress = fmt.Sprintf("%s (%s)", progress, patch.Progress)
+		}
+
+		if progress != last {
+			logger.Info(progress)
+		}
+
+		return progress
+	}
+
+	return last
+}
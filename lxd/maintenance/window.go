@@ -0,0 +1,180 @@
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window is a recurring period of time during which heavy automated tasks are allowed to run.
+type Window struct {
+	// Days on which the window starts. All days if empty.
+	Days map[time.Weekday]bool
+
+	// Start and end of the window, in minutes since midnight.
+	// A window whose end is before its start runs over midnight.
+	Start int
+	End   int
+}
+
+// Contains returns whether t falls within the window.
+func (w Window) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	if w.End <= w.Start {
+		// The window spans midnight, so the part after midnight belongs to the previous day.
+		if minute >= w.Start {
+			return w.onDay(day)
+		}
+
+		if minute < w.End {
+			return w.onDay((day + 6) % 7)
+		}
+
+		return false
+	}
+
+	return minute >= w.Start && minute < w.End && w.onDay(day)
+}
+
+func (w Window) onDay(day time.Weekday) bool {
+	return len(w.Days) == 0 || w.Days[day]
+}
+
+// Parse parses a comma separated list of maintenance windows.
+// Each window is of the form "[<days>] <HH:MM>-<HH:MM>" where days is a "|" separated list of
+// day names or day ranges, for example "mon-fri 01:00-05:00, sat|sun 00:00-08:00".
+func Parse(value string) ([]Window, error) {
+	windows := []Window{}
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		var window Window
+		var days, hours string
+
+		parts := strings.Fields(field)
+		switch len(parts) {
+		case 1:
+			hours = parts[0]
+		case 2:
+			days = parts[0]
+			hours = parts[1]
+		default:
+			return nil, fmt.Errorf("Invalid maintenance window %q", field)
+		}
+
+		if days != "" {
+			var err error
+
+			window.Days, err = parseDays(days)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid maintenance window %q: %w", field, err)
+			}
+		}
+
+		start, end, found := strings.Cut(hours, "-")
+		if !found {
+			return nil, fmt.Errorf("Invalid maintenance window %q: Time range must be of the form <HH:MM>-<HH:MM>", field)
+		}
+
+		var err error
+
+		window.Start, err = parseTime(start)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid maintenance window %q: %w", field, err)
+		}
+
+		window.End, err = parseTime(end)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid maintenance window %q: %w", field, err)
+		}
+
+		windows = append(windows, window)
+	}
+
+	return windows, nil
+}
+
+func parseDays(value string) (map[time.Weekday]bool, error) {
+	days := map[time.Weekday]bool{}
+
+	for _, entry := range strings.Split(value, "|") {
+		first, last, isRange := strings.Cut(strings.ToLower(entry), "-")
+
+		from, ok := weekdays[first]
+		if !ok {
+			return nil, fmt.Errorf("Unknown day %q", first)
+		}
+
+		to := from
+		if isRange {
+			to, ok = weekdays[last]
+			if !ok {
+				return nil, fmt.Errorf("Unknown day %q", last)
+			}
+		}
+
+		for day := from; ; day = (day + 1) % 7 {
+			days[day] = true
+
+			if day == to {
+				break
+			}
+		}
+	}
+
+	return days, nil
+}
+
+func parseTime(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return -1, fmt.Errorf("Invalid time %q", value)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Validate checks that value is a valid list of maintenance windows.
+func Validate(value string) error {
+	_, err := Parse(value)
+	return err
+}
+
+// Allowed returns whether heavy automated tasks may run at time t.
+// The project windows take precedence over the server windows when set.
+// If neither are set, tasks may run at any time.
+func Allowed(serverWindows string, projectWindows string, t time.Time) bool {
+	value := serverWindows
+	if projectWindows != "" {
+		value = projectWindows
+	}
+
+	windows, err := Parse(value)
+	if err != nil || len(windows) == 0 {
+		return true
+	}
+
+	for _, window := range windows {
+		if window.Contains(t) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,51 @@
+package maintenance_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/maintenance"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"01:00",
+		"01:00-25:00",
+		"funday 01:00-02:00",
+		"mon 01:00-02:00 extra",
+	} {
+		_, err := maintenance.Parse(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	// 2026-10-17 is a Saturday.
+	saturday := func(hour int, minute int) time.Time {
+		return time.Date(2026, 10, 17, hour, minute, 0, 0, time.Local)
+	}
+
+	cases := []struct {
+		server  string
+		project string
+		at      time.Time
+		allowed bool
+	}{
+		{"", "", saturday(12, 0), true},
+		{"01:00-05:00", "", saturday(3, 0), true},
+		{"01:00-05:00", "", saturday(5, 0), false},
+		{"mon-fri 01:00-05:00", "", saturday(3, 0), false},
+		{"mon-fri 01:00-05:00, sat|sun 00:00-08:00", "", saturday(7, 59), true},
+		{"fri 22:00-04:00", "", saturday(3, 0), true},
+		{"sat 22:00-04:00", "", saturday(3, 0), false},
+		{"fri-sun 12:00-13:00", "", saturday(12, 30), true},
+		{"01:00-05:00", "12:00-13:00", saturday(3, 0), false},
+		{"01:00-05:00", "12:00-13:00", saturday(12, 0), true},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.allowed, maintenance.Allowed(c.server, c.project, c.at), "%q/%q at %s", c.server, c.project, c.at)
+	}
+}
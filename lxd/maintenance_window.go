@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/maintenance"
+	"github.com/canonical/lxd/lxd/state"
+)
+
+// maintenanceWindowChecker returns a function reporting whether heavy automated tasks may currently act on a
+// project, according to the core.maintenance_window server setting and the maintenance.window project setting.
+// An empty project name only considers the server setting.
+func maintenanceWindowChecker(ctx context.Context, s *state.State) (func(projectName string) bool, error) {
+	serverWindows := s.GlobalConfig.MaintenanceWindow()
+	projectWindows := map[string]string{}
+
+	key := "maintenance.window"
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		projects, err := dbCluster.GetProjects(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		for _, p := range projects {
+			config, err := dbCluster.GetProjectConfig(ctx, tx.Tx(), p.ID, dbCluster.ConfigFilter{Key: &key})
+			if err != nil {
+				return err
+			}
+
+			projectWindows[p.Name] = config[key]
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	return func(projectName string) bool {
+		return maintenance.Allowed(serverWindows, projectWindows[projectName], now)
+	}, nil
+}
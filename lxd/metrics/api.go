@@ -6,6 +6,7 @@ type Metrics struct {
 	CPUs           int                          `json:"cpus" yaml:"cpus"`
 	Disk           map[string]DiskMetrics       `json:"disk" yaml:"disk"`
 	Filesystem     map[string]FilesystemMetrics `json:"filesystem" yaml:"filesystem"`
+	GPU            map[string]GPUMetrics        `json:"gpu" yaml:"gpu"`
 	Memory         MemoryMetrics                `json:"memory" yaml:"memory"`
 	Network        map[string]NetworkMetrics    `json:"network" yaml:"network"`
 	ProcessesTotal uint64                       `json:"procs_total" yaml:"procs_total"`
@@ -40,6 +41,14 @@ type FilesystemMetrics struct {
 	SizeBytes      uint64 `json:"filesystem_size_bytes" yaml:"filesystem_size_bytes"`
 }
 
+// GPUMetrics represents GPU metrics for an instance.
+type GPUMetrics struct {
+	Vendor           string  `json:"vendor" yaml:"vendor"`
+	UtilizationRatio float64 `json:"gpu_utilization_ratio" yaml:"gpu_utilization_ratio"`
+	MemoryUsedBytes  uint64  `json:"gpu_memory_used_bytes" yaml:"gpu_memory_used_bytes"`
+	MemoryTotalBytes uint64  `json:"gpu_memory_total_bytes" yaml:"gpu_memory_total_bytes"`
+}
+
 // MemoryMetrics represents memory metrics for an instance.
 type MemoryMetrics struct {
 	ActiveAnonBytes     uint64 `json:"memory_active_anon_bytes" yaml:"memory_active_anon_bytes"`
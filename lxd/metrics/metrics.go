@@ -92,9 +92,14 @@ func (m *MetricSet) String() string {
 	gaugeMetrics := []MetricType{
 		ProcsTotal,
 		CPUs,
+		GPUUtilizationRatio,
 		GoGoroutines,
 		GoHeapObjects,
 		Instances,
+		OVNDatabaseUp,
+		OVNDatabaseResponseSeconds,
+		PatchDurationSeconds,
+		PatchesPending,
 	}
 
 	for _, metricType := range metricTypes {
@@ -279,6 +284,15 @@ func MetricSetFromAPI(metrics *Metrics, labels map[string]string) (*MetricSet, e
 		set.AddSamples(NetworkTransmitPacketsTotal, Sample{Value: float64(stats.TransmitPackets), Labels: labels})
 	}
 
+	// GPU stats
+	for pciAddress, stats := range metrics.GPU {
+		labels := map[string]string{"pci": pciAddress, "vendor": stats.Vendor}
+
+		set.AddSamples(GPUMemoryTotalBytes, Sample{Value: float64(stats.MemoryTotalBytes), Labels: labels})
+		set.AddSamples(GPUMemoryUsedBytes, Sample{Value: float64(stats.MemoryUsedBytes), Labels: labels})
+		set.AddSamples(GPUUtilizationRatio, Sample{Value: stats.UtilizationRatio, Labels: labels})
+	}
+
 	// Procs stats
 	set.AddSamples(ProcsTotal, Sample{Value: float64(metrics.ProcessesTotal)})
 
@@ -56,3 +56,18 @@ func TestMetricSet_FilterSamples(t *testing.T) {
 		require.Contains(t, hasKeys, "project")
 	}
 }
+
+func TestMetricSetFromAPI_GPU(t *testing.T) {
+	m, err := MetricSetFromAPI(&Metrics{
+		GPU: map[string]GPUMetrics{
+			"0000:01:00.0": {Vendor: "nvidia", UtilizationRatio: 0.5, MemoryUsedBytes: 1024, MemoryTotalBytes: 4096},
+		},
+	}, map[string]string{"project": "default", "name": "jammy"})
+	require.NoError(t, err)
+
+	labels := map[string]string{"project": "default", "name": "jammy", "pci": "0000:01:00.0", "vendor": "nvidia"}
+	require.Equal(t, []Sample{{Value: 0.5, Labels: labels}}, m.set[GPUUtilizationRatio])
+	require.Equal(t, []Sample{{Value: 1024, Labels: labels}}, m.set[GPUMemoryUsedBytes])
+	require.Equal(t, []Sample{{Value: 4096, Labels: labels}}, m.set[GPUMemoryTotalBytes])
+	require.Contains(t, m.String(), "# TYPE lxd_gpu_utilization_ratio gauge\n")
+}
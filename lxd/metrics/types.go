@@ -34,6 +34,12 @@ const (
 	FilesystemFreeBytes
 	// FilesystemSizeBytes represents the size in bytes of a filesystem.
 	FilesystemSizeBytes
+	// GPUMemoryTotalBytes represents the total memory of a GPU.
+	GPUMemoryTotalBytes
+	// GPUMemoryUsedBytes represents the used memory of a GPU.
+	GPUMemoryUsedBytes
+	// GPUUtilizationRatio represents the utilization of a GPU.
+	GPUUtilizationRatio
 	// MemoryActiveAnonBytes represents the amount of anonymous memory on active LRU list.
 	MemoryActiveAnonBytes
 	// MemoryActiveFileBytes represents the amount of file-backed memory on active LRU list.
@@ -146,6 +152,16 @@ const (
 	GoNextGCBytes
 	// Instances represents the instance count.
 	Instances
+	// OVNDatabaseUp represents whether an endpoint of an OVN database is reachable.
+	OVNDatabaseUp
+	// OVNDatabaseResponseSeconds represents the response time of an endpoint of an OVN database.
+	OVNDatabaseResponseSeconds
+	// PatchesAppliedTotal represents the number of patches applied since the daemon started.
+	PatchesAppliedTotal
+	// PatchDurationSeconds represents the time a patch took to apply, or has been running for.
+	PatchDurationSeconds
+	// PatchesPending represents the number of patches which are pending or running.
+	PatchesPending
 )
 
 // MetricNames associates a metric type to its name.
@@ -159,6 +175,9 @@ var MetricNames = map[MetricType]string{
 	FilesystemAvailBytes:        "lxd_filesystem_avail_bytes",
 	FilesystemFreeBytes:         "lxd_filesystem_free_bytes",
 	FilesystemSizeBytes:         "lxd_filesystem_size_bytes",
+	GPUMemoryTotalBytes:         "lxd_gpu_memory_total_bytes",
+	GPUMemoryUsedBytes:          "lxd_gpu_memory_used_bytes",
+	GPUUtilizationRatio:         "lxd_gpu_utilization_ratio",
 	GoAllocBytes:                "lxd_go_alloc_bytes",
 	GoAllocBytesTotal:           "lxd_go_alloc_bytes_total",
 	GoBuckHashSysBytes:          "lxd_go_buck_hash_sys_bytes",
@@ -215,6 +234,11 @@ var MetricNames = map[MetricType]string{
 	UptimeSeconds:               "lxd_uptime_seconds",
 	WarningsTotal:               "lxd_warnings_total",
 	Instances:                   "lxd_instances",
+	OVNDatabaseUp:               "lxd_ovn_database_up",
+	OVNDatabaseResponseSeconds:  "lxd_ovn_database_response_seconds",
+	PatchesAppliedTotal:         "lxd_patches_applied_total",
+	PatchDurationSeconds:        "lxd_patch_duration_seconds",
+	PatchesPending:              "lxd_patches_pending",
 }
 
 // MetricHeaders represents the metric headers which contain help messages as specified by OpenMetrics.
@@ -228,6 +252,9 @@ var MetricHeaders = map[MetricType]string{
 	FilesystemAvailBytes:        "# HELP lxd_filesystem_avail_bytes The number of available space in bytes.",
 	FilesystemFreeBytes:         "# HELP lxd_filesystem_free_bytes The number of free space in bytes.",
 	FilesystemSizeBytes:         "# HELP lxd_filesystem_size_bytes The size of the filesystem in bytes.",
+	GPUMemoryTotalBytes:         "# HELP lxd_gpu_memory_total_bytes The total memory of the GPU in bytes.",
+	GPUMemoryUsedBytes:          "# HELP lxd_gpu_memory_used_bytes The used memory of the GPU in bytes.",
+	GPUUtilizationRatio:         "# HELP lxd_gpu_utilization_ratio The fraction of time the GPU was busy.",
 	GoAllocBytes:                "# HELP lxd_go_alloc_bytes Number of bytes allocated and still in use.",
 	GoAllocBytesTotal:           "# HELP lxd_go_alloc_bytes_total Total number of bytes allocated, even if freed.",
 	GoBuckHashSysBytes:          "# HELP lxd_go_buck_hash_sys_bytes Number of bytes used by the profiling bucket hash table.",
@@ -284,4 +311,9 @@ var MetricHeaders = map[MetricType]string{
 	UptimeSeconds:               "# HELP lxd_uptime_seconds The daemon uptime in seconds.",
 	WarningsTotal:               "# HELP lxd_warnings_total The number of active warnings.",
 	Instances:                   "# HELP lxd_instances The number of instances.",
+	OVNDatabaseUp:               "# HELP lxd_ovn_database_up Whether the OVN database endpoint was reachable on the last check.",
+	OVNDatabaseResponseSeconds:  "# HELP lxd_ovn_database_response_seconds The response time of the OVN database endpoint on the last check.",
+	PatchesAppliedTotal:         "# HELP lxd_patches_applied_total The number of patches applied since the daemon started.",
+	PatchDurationSeconds:        "# HELP lxd_patch_duration_seconds The time the patch took to apply, or has been running for.",
+	PatchesPending:              "# HELP lxd_patches_pending The number of patches which are pending or running.",
 }
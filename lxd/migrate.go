@@ -19,6 +19,8 @@ import (
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/migration"
 	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/rsync"
+	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 )
@@ -36,6 +38,28 @@ type migrationFields struct {
 	// storage specific fields
 	volumeOnly        bool
 	allowInconsistent bool
+
+	// compressionAlgorithm overrides the migration.compression_algorithm server setting for this transfer.
+	compressionAlgorithm string
+
+	// verifyChecksums requests block checksum verification for this transfer regardless of the
+	// migration.verify_checksums server setting.
+	verifyChecksums bool
+}
+
+// blockChecksums returns whether the block checksums should be offered to the target.
+func (c *migrationFields) blockChecksums(s *state.State) bool {
+	return c.verifyChecksums || s.GlobalConfig.MigrationVerifyChecksums()
+}
+
+// compression returns the compression algorithm and level to use for the migration transfer.
+func (c *migrationFields) compression(s *state.State) (string, int, error) {
+	value := c.compressionAlgorithm
+	if value == "" {
+		value = s.GlobalConfig.MigrationCompressionAlgorithm()
+	}
+
+	return rsync.ParseCompression(value)
 }
 
 func (c *migrationFields) send(m proto.Message) error {
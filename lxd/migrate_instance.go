@@ -127,6 +127,11 @@ func (s *migrationSourceWs) Do(state *state.State, migrateOp *operations.Operati
 		return wsConn, nil
 	}
 
+	compressionAlgorithm, compressionLevel, err := s.compression(state)
+	if err != nil {
+		return err
+	}
+
 	s.instance.SetOperation(migrateOp)
 	err = s.instance.MigrateSend(instance.MigrateSendArgs{
 		MigrateArgs: instance.MigrateArgs{
@@ -145,7 +150,10 @@ func (s *migrationSourceWs) Do(state *state.State, migrateOp *operations.Operati
 			},
 			ClusterMoveSourceName: s.clusterMoveSourceName,
 		},
-		AllowInconsistent: s.allowInconsistent,
+		AllowInconsistent:    s.allowInconsistent,
+		CompressionAlgorithm: compressionAlgorithm,
+		CompressionLevel:     compressionLevel,
+		BlockChecksums:       s.blockChecksums(state),
 	})
 	if err != nil {
 		l.Error("Failed migration on source", logger.Ctx{"err": err})
@@ -106,6 +106,14 @@ func (s *migrationSourceWs) DoStorage(state *state.State, projectName string, po
 		return fmt.Errorf("No source migration types available")
 	}
 
+	compressionAlgorithm, compressionLevel, err := s.compression(state)
+	if err != nil {
+		return err
+	}
+
+	// Only offer the requested rsync compression.
+	poolMigrationTypes = migration.SetRsyncCompression(poolMigrationTypes, compressionAlgorithm)
+
 	// Convert the pool's migration type options to an offer header to target.
 	offerHeader := migration.TypesToHeader(poolMigrationTypes...)
 
@@ -113,6 +121,11 @@ func (s *migrationSourceWs) DoStorage(state *state.State, projectName string, po
 	indexHeaderVersion := migration.IndexHeaderVersion
 	offerHeader.IndexHeaderVersion = &indexHeaderVersion
 
+	// Offer to send the block checksums if requested.
+	if s.blockChecksums(state) {
+		offerHeader.BlockChecksums = proto.Bool(true)
+	}
+
 	// Only send snapshots when requested.
 	if !s.volumeOnly {
 		offerHeader.Snapshots = make([]*migration.Snapshot, 0, len(srcConfig.VolumeSnapshots))
@@ -157,6 +170,8 @@ func (s *migrationSourceWs) DoStorage(state *state.State, projectName string, po
 		ContentType:        srcConfig.Volume.ContentType,
 		Info:               &migration.Info{Config: srcConfig},
 		VolumeOnly:         s.volumeOnly,
+		CompressionLevel:   compressionLevel,
+		BlockChecksums:     respHeader.GetBlockChecksums(),
 	}
 
 	// Only send the snapshots that the target requests when refreshing.
@@ -315,6 +330,11 @@ func (c *migrationSink) DoStorage(state *state.State, projectName string, poolNa
 	respHeader.Snapshots = offerHeader.Snapshots
 	respHeader.Refresh = &c.refresh
 
+	// Agree to verify the block checksums if offered by the source.
+	if offerHeader.GetBlockChecksums() {
+		respHeader.BlockChecksums = proto.Bool(true)
+	}
+
 	// Translate the legacy MigrationSinkArgs to a VolumeTargetArgs suitable for use
 	// with the new storage layer.
 	myTarget = func(conn io.ReadWriteCloser, op *operations.Operation, args migrationSinkArgs) error {
@@ -328,6 +348,7 @@ func (c *migrationSink) DoStorage(state *state.State, projectName string, poolNa
 			ContentType:        req.ContentType,
 			Refresh:            args.Refresh,
 			VolumeOnly:         args.VolumeOnly,
+			BlockChecksums:     offerHeader.GetBlockChecksums(),
 		}
 
 		// A zero length Snapshots slice indicates volume only migration in
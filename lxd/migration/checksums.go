@@ -0,0 +1,143 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// BlockChecksumSize is the size of the blocks that are checksummed individually during block volume transfers.
+const BlockChecksumSize = 4 * 1024 * 1024
+
+// BlockChecksums computes the SHA-256 checksums of consecutive blocks of the data written to it.
+type BlockChecksums struct {
+	hash      hash.Hash
+	blockLen  int
+	size      int64
+	checksums []string
+}
+
+// NewBlockChecksums returns a new BlockChecksums.
+func NewBlockChecksums() *BlockChecksums {
+	return &BlockChecksums{hash: sha256.New()}
+}
+
+// Write adds data to the checksummed stream.
+func (c *BlockChecksums) Write(p []byte) (int, error) {
+	written := 0
+
+	for len(p) > 0 {
+		n := min(len(p), BlockChecksumSize-c.blockLen)
+
+		_, _ = c.hash.Write(p[:n])
+		c.blockLen += n
+		c.size += int64(n)
+		written += n
+		p = p[n:]
+
+		if c.blockLen == BlockChecksumSize {
+			c.finishBlock()
+		}
+	}
+
+	return written, nil
+}
+
+func (c *BlockChecksums) finishBlock() {
+	c.checksums = append(c.checksums, hex.EncodeToString(c.hash.Sum(nil)))
+	c.hash.Reset()
+	c.blockLen = 0
+}
+
+// Sum returns the checksums of all blocks, including the last partial block.
+func (c *BlockChecksums) Sum() []string {
+	if c.blockLen > 0 {
+		c.finishBlock()
+	}
+
+	return c.checksums
+}
+
+// Size returns the number of bytes written.
+func (c *BlockChecksums) Size() int64 {
+	return c.size
+}
+
+// SendBlockChecksums sends the block checksums to the target as a separate message.
+func SendBlockChecksums(conn io.WriteCloser, checksums *BlockChecksums) error {
+	data, err := json.Marshal(checksums.Sum())
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Write(data)
+	if err != nil {
+		return fmt.Errorf("Failed sending block checksums: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyBlockChecksums receives the block checksums of the source and compares them with the checksums of the
+// received data. It records the result in the operation metadata.
+func VerifyBlockChecksums(op *operations.Operation, volName string, conn io.Reader, checksums *BlockChecksums) error {
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		return fmt.Errorf("Failed receiving block checksums: %w", err)
+	}
+
+	var sourceChecksums []string
+	err = json.Unmarshal(data, &sourceChecksums)
+	if err != nil {
+		return fmt.Errorf("Failed parsing block checksums: %w", err)
+	}
+
+	targetChecksums := checksums.Sum()
+
+	mismatches := 0
+	firstMismatch := int64(-1)
+	for i := 0; i < max(len(sourceChecksums), len(targetChecksums)); i++ {
+		if i < len(sourceChecksums) && i < len(targetChecksums) && sourceChecksums[i] == targetChecksums[i] {
+			continue
+		}
+
+		mismatches++
+		if firstMismatch < 0 {
+			firstMismatch = int64(i) * BlockChecksumSize
+		}
+	}
+
+	summary := fmt.Sprintf("%d blocks (%s) verified", len(targetChecksums), units.GetByteSizeStringIEC(checksums.Size(), 2))
+	if mismatches > 0 {
+		summary = fmt.Sprintf("%d of %d blocks mismatched", mismatches, max(len(sourceChecksums), len(targetChecksums)))
+	}
+
+	if op != nil {
+		meta := op.Metadata()
+		if meta == nil {
+			meta = make(map[string]any)
+		}
+
+		results, ok := meta["checksums"].(map[string]string)
+		if !ok {
+			results = map[string]string{}
+		}
+
+		results[volName] = summary
+		meta["checksums"] = results
+
+		_ = op.UpdateMetadata(meta)
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf("Checksum verification of %q failed: %s (first mismatch at offset %d)", volName, summary, firstMismatch)
+	}
+
+	return nil
+}
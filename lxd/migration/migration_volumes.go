@@ -60,6 +60,8 @@ type VolumeSourceArgs struct {
 	Info               *Info
 	VolumeOnly         bool
 	ClusterMove        bool
+	CompressionLevel   int  // Optional compression level for rsync transfers.
+	BlockChecksums     bool // Whether to send the checksums of block volumes for verification by the target.
 }
 
 // VolumeTargetArgs represents the arguments needed to setup a volume migration sink.
@@ -77,6 +79,7 @@ type VolumeTargetArgs struct {
 	ContentType           string
 	VolumeOnly            bool
 	ClusterMoveSourceName string
+	BlockChecksums        bool // Whether to verify the received block volumes against the source's checksums.
 }
 
 // TypesToHeader converts one or more Types to a MigrationHeader. It uses the first type argument
@@ -146,6 +149,8 @@ func TypesToHeader(types ...Type) *MigrationHeader {
 			Delete:        &missingFeature,
 			Compress:      &missingFeature,
 			Bidirectional: &missingFeature,
+			CompressZstd:  &missingFeature,
+			CompressLz4:   &missingFeature,
 		}
 
 		for _, feature := range t.Features {
@@ -157,6 +162,10 @@ func TypesToHeader(types ...Type) *MigrationHeader {
 				features.Compress = &hasFeature
 			} else if feature == "bidirectional" {
 				features.Bidirectional = &hasFeature
+			} else if feature == RsyncFeatureCompressZstd {
+				features.CompressZstd = &hasFeature
+			} else if feature == RsyncFeatureCompressLz4 {
+				features.CompressLz4 = &hasFeature
 			}
 		}
 
@@ -167,6 +176,46 @@ func TypesToHeader(types ...Type) *MigrationHeader {
 	return &header
 }
 
+// SetRsyncCompression restricts the rsync compression features of the types to the supplied algorithm, which is
+// one of "zlib", "zstd", "lz4" or "none". The "compress" feature (zlib) is kept for "zstd" and "lz4" so that the
+// transfer falls back to it if the other side doesn't support the requested algorithm.
+// An empty algorithm leaves the types unchanged.
+func SetRsyncCompression(types []Type, algorithm string) []Type {
+	if algorithm == "" {
+		return types
+	}
+
+	var removed []string
+	switch algorithm {
+	case "none":
+		removed = []string{"compress", RsyncFeatureCompressZstd, RsyncFeatureCompressLz4}
+	case "zlib":
+		removed = []string{RsyncFeatureCompressZstd, RsyncFeatureCompressLz4}
+	case "zstd":
+		removed = []string{RsyncFeatureCompressLz4}
+	case "lz4":
+		removed = []string{RsyncFeatureCompressZstd}
+	}
+
+	newTypes := make([]Type, 0, len(types))
+	for _, t := range types {
+		if shared.ValueInSlice(t.FSType, []MigrationFSType{MigrationFSType_RSYNC, MigrationFSType_BLOCK_AND_RSYNC, MigrationFSType_RBD_AND_RSYNC}) {
+			features := make([]string, 0, len(t.Features))
+			for _, feature := range t.Features {
+				if !shared.ValueInSlice(feature, removed) {
+					features = append(features, feature)
+				}
+			}
+
+			t.Features = features
+		}
+
+		newTypes = append(newTypes, t)
+	}
+
+	return newTypes
+}
+
 // MatchTypes attempts to find matching migration transport types between an offered type sent from a remote
 // source and the types supported by a local storage pool. If matches are found then one or more Types are
 // returned containing the method and the matching optional features present in both. The function also takes a
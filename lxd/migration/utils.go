@@ -22,6 +22,12 @@ const ZFSFeatureMigrationHeader = "migration_header"
 // ZFSFeatureZvolFilesystems indicates migration can send/recv zvols.
 const ZFSFeatureZvolFilesystems = "header_zvol_filesystems"
 
+// RsyncFeatureCompressZstd indicates that rsync can compress the transfer using zstd.
+const RsyncFeatureCompressZstd = "compress_zstd"
+
+// RsyncFeatureCompressLz4 indicates that rsync can compress the transfer using lz4.
+const RsyncFeatureCompressLz4 = "compress_lz4"
+
 // ControlResponse encapsulates MigrationControl with a receive error.
 type ControlResponse struct {
 	MigrationControl
@@ -51,6 +57,14 @@ func (m *MigrationHeader) GetRsyncFeaturesSlice() []string {
 		if m.RsyncFeatures.Bidirectional != nil && *m.RsyncFeatures.Bidirectional {
 			features = append(features, "bidirectional")
 		}
+
+		if m.RsyncFeatures.CompressZstd != nil && *m.RsyncFeatures.CompressZstd {
+			features = append(features, RsyncFeatureCompressZstd)
+		}
+
+		if m.RsyncFeatures.CompressLz4 != nil && *m.RsyncFeatures.CompressLz4 {
+			features = append(features, RsyncFeatureCompressLz4)
+		}
 	}
 
 	return features
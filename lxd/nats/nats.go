@@ -0,0 +1,391 @@
+package nats
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// This is a minimal publisher implementing the subset of the NATS client protocol needed to publish messages.
+// See https://docs.nats.io/reference/reference-protocols/nats-protocol.
+
+const (
+	defaultPort = "4222"
+
+	// queueSize is the number of events buffered while the server is unreachable.
+	// Events are dropped once the queue is full.
+	queueSize = 1024
+
+	dialTimeout  = 10 * time.Second
+	writeTimeout = 10 * time.Second
+	maxBackoff   = 30 * time.Second
+)
+
+type config struct {
+	url      *url.URL
+	username string
+	password string
+	token    string
+	caCert   string
+	subject  string
+	types    []string
+	location string
+}
+
+type message struct {
+	subject string
+	payload []byte
+}
+
+// serverInfo is the subset of the INFO message sent by the server that is relevant to the client.
+type serverInfo struct {
+	TLSRequired  bool `json:"tls_required"`
+	AuthRequired bool `json:"auth_required"`
+	MaxPayload   int  `json:"max_payload"`
+}
+
+// connectInfo is the CONNECT message sent by the client.
+type connectInfo struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	TLS       bool   `json:"tls_required"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	Version   string `json:"version"`
+	Protocol  int    `json:"protocol"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// Client represents a NATS client.
+type Client struct {
+	cfg      config
+	ctx      context.Context
+	cancel   context.CancelFunc
+	messages chan message
+	once     sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewClient returns a Client publishing the events of the given types to the NATS server at u.
+func NewClient(ctx context.Context, u *url.URL, username string, password string, token string, caCert string, subject string, types []string, location string) *Client {
+	ctx, cancel := context.WithCancel(ctx)
+
+	client := Client{
+		cfg: config{
+			url:      u,
+			username: username,
+			password: password,
+			token:    token,
+			caCert:   caCert,
+			subject:  subject,
+			types:    types,
+			location: location,
+		},
+		ctx:      ctx,
+		cancel:   cancel,
+		messages: make(chan message, queueSize),
+	}
+
+	client.wg.Add(1)
+	go client.run()
+
+	return &client
+}
+
+// Stop the client.
+func (c *Client) Stop() {
+	c.once.Do(c.cancel)
+	c.wg.Wait()
+}
+
+// HandleEvent handles the event received from the internal event listener.
+func (c *Client) HandleEvent(event api.Event) {
+	if !shared.ValueInSlice(event.Type, c.cfg.types) {
+		return
+	}
+
+	// Support overriding the location field (used on standalone systems).
+	if c.cfg.location != "" {
+		event.Location = c.cfg.location
+	}
+
+	action := event.Type
+	projectName := event.Project
+	if event.Type == api.EventTypeLifecycle {
+		lifecycleEvent := api.EventLifecycle{}
+
+		err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+		if err != nil {
+			return
+		}
+
+		action = lifecycleEvent.Action
+		if projectName == "" {
+			projectName = lifecycleEvent.Project
+		}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	msg := message{
+		subject: Subject(c.cfg.subject, event.Type, action, projectName, event.Location),
+		payload: payload,
+	}
+
+	select {
+	case c.messages <- msg:
+	default:
+		// Don't block the event listener if the server can't keep up.
+	}
+}
+
+// Subject renders the subject template, replacing the {type}, {action}, {project} and {location} placeholders.
+// Characters that have a special meaning in NATS subjects are replaced in the values.
+func Subject(template string, eventType string, action string, projectName string, location string) string {
+	token := func(value string) string {
+		if value == "" {
+			return "_"
+		}
+
+		return strings.Map(func(r rune) rune {
+			switch r {
+			case '.', '*', '>', ' ', '\t', '\r', '\n':
+				return '_'
+			}
+
+			return r
+		}, value)
+	}
+
+	replacer := strings.NewReplacer(
+		"{type}", token(eventType),
+		"{action}", token(action),
+		"{project}", token(projectName),
+		"{location}", token(location),
+	)
+
+	return replacer.Replace(template)
+}
+
+func (c *Client) run() {
+	defer c.wg.Done()
+
+	backoff := time.Second
+
+	for {
+		err := c.publish()
+		if c.ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			logger.Warn("Failed publishing events to NATS server", logger.Ctx{"url": c.cfg.url.Redacted(), "err": err})
+		} else {
+			backoff = time.Second
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// publish connects to the server and publishes the queued messages until the connection fails or the client is
+// stopped.
+func (c *Client) publish() error {
+	conn, reader, info, err := c.connect()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	// Handle the messages sent by the server.
+	readErr := make(chan error, 1)
+	var writeLock sync.Mutex
+
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				readErr <- err
+				return
+			}
+
+			line = strings.TrimSpace(line)
+
+			switch {
+			case line == "PING":
+				writeLock.Lock()
+				_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+				_, err = io.WriteString(conn, "PONG\r\n")
+				writeLock.Unlock()
+				if err != nil {
+					readErr <- err
+					return
+				}
+
+			case strings.HasPrefix(line, "-ERR"):
+				readErr <- fmt.Errorf("Server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return nil
+
+		case err := <-readErr:
+			return err
+
+		case msg := <-c.messages:
+			if info.MaxPayload > 0 && len(msg.payload) > info.MaxPayload {
+				logger.Warn("Dropping event exceeding the NATS maximum payload", logger.Ctx{"subject": msg.subject, "size": len(msg.payload)})
+				continue
+			}
+
+			writeLock.Lock()
+			_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			_, err := fmt.Fprintf(conn, "PUB %s %d\r\n%s\r\n", msg.subject, len(msg.payload), msg.payload)
+			writeLock.Unlock()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// connect establishes the connection to the server and performs the handshake.
+func (c *Client) connect() (net.Conn, *bufio.Reader, *serverInfo, error) {
+	host := c.cfg.url.Host
+	if c.cfg.url.Port() == "" {
+		host = net.JoinHostPort(c.cfg.url.Hostname(), defaultPort)
+	}
+
+	dialer := net.Dialer{Timeout: dialTimeout}
+
+	conn, err := dialer.DialContext(c.ctx, "tcp", host)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+
+	_ = conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, fmt.Errorf("Failed reading server information: %w", err)
+	}
+
+	infoJSON, ok := strings.CutPrefix(strings.TrimSpace(line), "INFO ")
+	if !ok {
+		_ = conn.Close()
+		return nil, nil, nil, fmt.Errorf("Unexpected server greeting %q", strings.TrimSpace(line))
+	}
+
+	info := serverInfo{}
+
+	err = json.Unmarshal([]byte(infoJSON), &info)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, fmt.Errorf("Failed parsing server information: %w", err)
+	}
+
+	useTLS := c.cfg.url.Scheme == "tls"
+	if info.TLSRequired && !useTLS {
+		_ = conn.Close()
+		return nil, nil, nil, errors.New("Server requires TLS, use the \"tls\" URL scheme")
+	}
+
+	if useTLS {
+		tlsConfig, err := shared.GetTLSConfigMem("", "", c.cfg.caCert, "", false)
+		if err != nil {
+			_ = conn.Close()
+			return nil, nil, nil, err
+		}
+
+		tlsConfig.ServerName = c.cfg.url.Hostname()
+
+		tlsConn := tls.Client(conn, tlsConfig)
+
+		err = tlsConn.HandshakeContext(c.ctx)
+		if err != nil {
+			_ = conn.Close()
+			return nil, nil, nil, fmt.Errorf("Failed TLS handshake: %w", err)
+		}
+
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+	}
+
+	connect, err := json.Marshal(connectInfo{
+		TLS:       useTLS,
+		Name:      "lxd",
+		Lang:      "go",
+		Version:   "1.0.0",
+		Protocol:  1,
+		User:      c.cfg.username,
+		Pass:      c.cfg.password,
+		AuthToken: c.cfg.token,
+	})
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, err
+	}
+
+	// Send the CONNECT message followed by a PING so that authentication errors are reported before publishing.
+	_, err = fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, err
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			_ = conn.Close()
+			return nil, nil, nil, err
+		}
+
+		line = strings.TrimSpace(line)
+
+		if line == "PONG" {
+			break
+		}
+
+		if strings.HasPrefix(line, "-ERR") {
+			_ = conn.Close()
+			return nil, nil, nil, fmt.Errorf("Server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return conn, reader, &info, nil
+}
@@ -0,0 +1,37 @@
+package addresspool
+
+import (
+	"net"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// NetworkAddressPool represents a pool of external addresses.
+type NetworkAddressPool interface {
+	// Initialise.
+	init(state *state.State, id int64, poolInfo *api.NetworkAddressPool)
+
+	// Info.
+	ID() int64
+	Info() *api.NetworkAddressPool
+	Etag() []any
+	UsedBy() ([]string, error)
+	Allocations() ([]api.NetworkAddressPoolAllocation, error)
+	AdvertiseBGP() bool
+
+	// Allocations.
+	Allocate(ipVersion uint, allocation db.NetworkAddressPoolAllocation) (net.IP, error)
+	InstanceAddress(ipVersion uint, instanceID int64, deviceName string) (net.IP, error)
+	AssignForward(address net.IP, forwardID int64) error
+	Release(address net.IP) error
+
+	// Internal validation.
+	validateName(name string) error
+	validateConfig(config *api.NetworkAddressPoolPut) error
+
+	// Modifications.
+	Update(config *api.NetworkAddressPoolPut) error
+	Delete() error
+}
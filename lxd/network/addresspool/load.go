@@ -0,0 +1,66 @@
+package addresspool
+
+import (
+	"context"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// LoadByName loads and initialises a network address pool from the database by name.
+func LoadByName(s *state.State, name string) (NetworkAddressPool, error) {
+	var id int64
+	var poolInfo *api.NetworkAddressPool
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		id, poolInfo, err = tx.GetNetworkAddressPool(ctx, name)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pool NetworkAddressPool = &pool{}
+	pool.init(s, id, poolInfo)
+
+	return pool, nil
+}
+
+// Create validates supplied record and creates new network address pool record in the database.
+func Create(s *state.State, poolInfo *api.NetworkAddressPoolsPost) error {
+	var pool NetworkAddressPool = &pool{}
+	pool.init(s, -1, nil)
+
+	err := pool.validateName(poolInfo.Name)
+	if err != nil {
+		return err
+	}
+
+	err = pool.validateConfig(&poolInfo.NetworkAddressPoolPut)
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Insert DB record.
+		_, err = tx.CreateNetworkAddressPool(ctx, poolInfo)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseInstanceAddresses releases the addresses allocated to an instance NIC device from any pool.
+func ReleaseInstanceAddresses(s *state.State, instanceID int64, deviceName string) error {
+	return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteNetworkAddressPoolInstanceAllocations(ctx, instanceID, deviceName)
+	})
+}
@@ -0,0 +1,471 @@
+package addresspool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/validate"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// pool represents a network address pool.
+type pool struct {
+	logger logger.Logger
+	state  *state.State
+	id     int64
+	info   *api.NetworkAddressPool
+}
+
+// init initialise internal variables.
+func (d *pool) init(state *state.State, id int64, info *api.NetworkAddressPool) {
+	if info == nil {
+		d.info = &api.NetworkAddressPool{}
+	} else {
+		d.info = info
+	}
+
+	d.logger = logger.AddContext(logger.Ctx{"networkaddresspool": d.info.Name})
+	d.id = id
+	d.state = state
+
+	if d.info.Config == nil {
+		d.info.Config = make(map[string]string)
+	}
+}
+
+// ID returns the network address pool ID.
+func (d *pool) ID() int64 {
+	return d.id
+}
+
+// Info returns copy of internal info for the network address pool.
+func (d *pool) Info() *api.NetworkAddressPool {
+	// Copy internal info to prevent modification externally.
+	info := api.NetworkAddressPool{}
+	info.Name = d.info.Name
+	info.Description = d.info.Description
+	info.Config = util.CopyConfig(d.info.Config)
+	info.UsedBy = nil // To indicate its not populated (use UsedBy() function to populate).
+
+	return &info
+}
+
+// Etag returns the values used for etag generation.
+func (d *pool) Etag() []any {
+	return []any{d.info.Name, d.info.Description, d.info.Config}
+}
+
+// AdvertiseBGP returns whether the addresses allocated to instance NICs should be advertised over BGP.
+func (d *pool) AdvertiseBGP() bool {
+	return shared.IsTrue(d.info.Config["bgp.advertise"])
+}
+
+// allocationUsedBy returns the API endpoint of the user of an allocated address.
+// Returns an empty string for addresses which are reserved but not assigned yet.
+func allocationUsedBy(allocation db.NetworkAddressPoolAllocation) string {
+	if allocation.EntityName == "" {
+		return ""
+	}
+
+	var u *api.URL
+	if allocation.InstanceID > 0 {
+		u = api.NewURL().Path(version.APIVersion, "instances", allocation.EntityName)
+	} else {
+		u = api.NewURL().Path(version.APIVersion, "networks", allocation.EntityName, "forwards", allocation.Address)
+	}
+
+	return u.Project(allocation.ProjectName).String()
+}
+
+// allocations returns the addresses allocated from the pool.
+func (d *pool) allocations() ([]db.NetworkAddressPoolAllocation, error) {
+	var allocations []db.NetworkAddressPoolAllocation
+
+	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		allocations, err = tx.GetNetworkAddressPoolAllocations(ctx, d.id)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading allocations of network address pool %q: %w", d.info.Name, err)
+	}
+
+	return allocations, nil
+}
+
+// Allocations returns the addresses allocated from the pool.
+func (d *pool) Allocations() ([]api.NetworkAddressPoolAllocation, error) {
+	allocations, err := d.allocations()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]api.NetworkAddressPoolAllocation, 0, len(allocations))
+	for _, allocation := range allocations {
+		result = append(result, api.NetworkAddressPoolAllocation{
+			Address: allocation.Address,
+			UsedBy:  allocationUsedBy(allocation),
+		})
+	}
+
+	return result, nil
+}
+
+// UsedBy returns a list of API endpoints using addresses of this pool.
+func (d *pool) UsedBy() ([]string, error) {
+	allocations, err := d.allocations()
+	if err != nil {
+		return nil, err
+	}
+
+	usedBy := []string{}
+	for _, allocation := range allocations {
+		u := allocationUsedBy(allocation)
+		if u == "" || shared.ValueInSlice(u, usedBy) {
+			continue
+		}
+
+		usedBy = append(usedBy, u)
+	}
+
+	return usedBy, nil
+}
+
+// validateName checks name is valid.
+func (d *pool) validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Name is required")
+	}
+
+	if strings.Contains(name, "/") {
+		return fmt.Errorf(`Name cannot contain "/"`)
+	}
+
+	return nil
+}
+
+// validateConfig checks the config and rules are valid.
+func (d *pool) validateConfig(info *api.NetworkAddressPoolPut) error {
+	rules := map[string]func(value string) error{}
+
+	// lxdmeta:generate(entities=network-address-pool; group=config-options; key=ipv4.addresses)
+	// Specify a comma-separated list of subnets (CIDR), ranges (`<start>-<end>`) or single addresses.
+	// All addresses of the subnets can be allocated, so use ranges to exclude the addresses used by the hosts or routers.
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: IPv4 addresses that can be allocated
+	rules["ipv4.addresses"] = validate.Optional(func(value string) error {
+		_, err := parseAddresses(value, 4)
+		return err
+	})
+
+	// lxdmeta:generate(entities=network-address-pool; group=config-options; key=ipv6.addresses)
+	// Specify a comma-separated list of subnets (CIDR), ranges (`<start>-<end>`) or single addresses.
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: IPv6 addresses that can be allocated
+	rules["ipv6.addresses"] = validate.Optional(func(value string) error {
+		_, err := parseAddresses(value, 6)
+		return err
+	})
+
+	// lxdmeta:generate(entities=network-address-pool; group=config-options; key=bgp.advertise)
+	// The address allocated to a routed NIC is advertised to the BGP peers of the server running the instance.
+	// Network forwards are advertised through the BGP settings of their network.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  required: no
+	//  shortdesc: Whether to advertise the addresses allocated to instance NICs over BGP
+	rules["bgp.advertise"] = validate.Optional(validate.IsBool)
+
+	// lxdmeta:generate(entities=network-address-pool; group=config-options; key=user.*)
+	//
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: User-provided free-form key/value pairs
+
+	checkedFields := map[string]struct{}{}
+
+	// Run the validator against each field.
+	for k, validator := range rules {
+		checkedFields[k] = struct{}{} // Mark field as checked.
+		err := validator(info.Config[k])
+		if err != nil {
+			return fmt.Errorf("Invalid value for config option %q: %w", k, err)
+		}
+	}
+
+	// Look for any unchecked fields, as these are unknown fields and validation should fail.
+	for k := range info.Config {
+		_, checked := checkedFields[k]
+		if checked {
+			continue
+		}
+
+		// User keys are not validated.
+		if shared.IsUserConfig(k) {
+			continue
+		}
+
+		return fmt.Errorf("Invalid config option %q", k)
+	}
+
+	return nil
+}
+
+// parseAddresses parses a comma-separated list of subnets, ranges and single addresses of the given IP version.
+func parseAddresses(value string, ipVersion uint) ([]*shared.IPRange, error) {
+	var ranges []*shared.IPRange
+
+	for _, entry := range shared.SplitNTrimSpace(value, ",", -1, true) {
+		var ipRange *shared.IPRange
+
+		if strings.Contains(entry, "/") {
+			_, subnet, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			// Both the address and the mask are 4 bytes long for IPv4 subnets.
+			end := make(net.IP, len(subnet.IP))
+			for i := range subnet.IP {
+				end[i] = subnet.IP[i] | ^subnet.Mask[i]
+			}
+
+			ipRange = &shared.IPRange{Start: subnet.IP.To16(), End: end.To16()}
+		} else if strings.Contains(entry, "-") {
+			var err error
+
+			ipRange, err = shared.ParseIPRange(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			ipRange.Start = ipRange.Start.To16()
+			ipRange.End = ipRange.End.To16()
+		} else {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("Invalid address %q", entry)
+			}
+
+			ipRange = &shared.IPRange{Start: ip.To16(), End: ip.To16()}
+		}
+
+		if (ipRange.Start.To4() != nil) != (ipVersion == 4) || (ipRange.End.To4() != nil) != (ipVersion == 4) {
+			return nil, fmt.Errorf("%q isn't an IPv%d subnet, range or address", entry, ipVersion)
+		}
+
+		ranges = append(ranges, ipRange)
+	}
+
+	return ranges, nil
+}
+
+// nextIP returns the address following the given one.
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+
+	return next
+}
+
+// containsAddress returns whether the address belongs to the pool with the given config.
+func containsAddress(config map[string]string, address net.IP) bool {
+	ipVersion := uint(6)
+	if address.To4() != nil {
+		ipVersion = 4
+	}
+
+	ranges, err := parseAddresses(config[fmt.Sprintf("ipv%d.addresses", ipVersion)], ipVersion)
+	if err != nil {
+		return false
+	}
+
+	for _, ipRange := range ranges {
+		if ipRange.ContainsIP(address.To16()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allocate allocates an address of the given IP version from the pool and records its user.
+// Instance NIC devices always get the same address back until it is released.
+// Returns an error if the pool is exhausted.
+func (d *pool) Allocate(ipVersion uint, allocation db.NetworkAddressPoolAllocation) (net.IP, error) {
+	ranges, err := parseAddresses(d.info.Config[fmt.Sprintf("ipv%d.addresses", ipVersion)], ipVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("Network address pool %q has no IPv%d addresses", d.info.Name, ipVersion)
+	}
+
+	var address net.IP
+
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		allocations, err := tx.GetNetworkAddressPoolAllocations(ctx, d.id)
+		if err != nil {
+			return err
+		}
+
+		allocated := make(map[string]struct{}, len(allocations))
+		for _, existing := range allocations {
+			ip := net.ParseIP(existing.Address)
+			if ip == nil {
+				continue
+			}
+
+			// Return the address already allocated to the instance NIC device.
+			if allocation.InstanceID > 0 && existing.InstanceID == allocation.InstanceID && existing.DeviceName == allocation.DeviceName && (ip.To4() != nil) == (ipVersion == 4) {
+				address = ip
+				return nil
+			}
+
+			allocated[ip.String()] = struct{}{}
+		}
+
+		for _, ipRange := range ranges {
+			for ip := ipRange.Start; bytes.Compare(ip, ipRange.End) <= 0; ip = nextIP(ip) {
+				_, found := allocated[ip.String()]
+				if !found {
+					address = ip
+					break
+				}
+
+				// Stop at the end of the address space.
+				if ip.Equal(ipRange.End) {
+					break
+				}
+			}
+
+			if address != nil {
+				break
+			}
+		}
+
+		if address == nil {
+			return api.StatusErrorf(http.StatusServiceUnavailable, "No IPv%d address available in network address pool %q", ipVersion, d.info.Name)
+		}
+
+		allocation.Address = address.String()
+
+		return tx.CreateNetworkAddressPoolAllocation(ctx, d.id, allocation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// InstanceAddress returns the address of the given IP version allocated to an instance NIC device, or nil if none.
+func (d *pool) InstanceAddress(ipVersion uint, instanceID int64, deviceName string) (net.IP, error) {
+	allocations, err := d.allocations()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, allocation := range allocations {
+		ip := net.ParseIP(allocation.Address)
+		if ip == nil || allocation.InstanceID != instanceID || allocation.DeviceName != deviceName {
+			continue
+		}
+
+		if (ip.To4() != nil) == (ipVersion == 4) {
+			return ip, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// AssignForward records the network forward using an address reserved from the pool.
+func (d *pool) AssignForward(address net.IP, forwardID int64) error {
+	return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.SetNetworkAddressPoolAllocationForward(ctx, d.id, address.String(), forwardID)
+	})
+}
+
+// Release returns an allocated address to the pool.
+func (d *pool) Release(address net.IP) error {
+	return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteNetworkAddressPoolAllocation(ctx, d.id, address.String())
+	})
+}
+
+// Update applies the supplied config to the pool.
+func (d *pool) Update(config *api.NetworkAddressPoolPut) error {
+	err := d.validateConfig(config)
+	if err != nil {
+		return err
+	}
+
+	allocations, err := d.allocations()
+	if err != nil {
+		return err
+	}
+
+	// Check that the allocated addresses remain part of the pool.
+	for _, allocation := range allocations {
+		ip := net.ParseIP(allocation.Address)
+		if ip != nil && !containsAddress(config.Config, ip) {
+			return api.StatusErrorf(http.StatusBadRequest, "Allocated address %q must remain part of the pool", allocation.Address)
+		}
+	}
+
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateNetworkAddressPool(ctx, d.id, config)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Apply changes internally and reinitialise.
+	d.info.SetWritable(*config)
+	d.init(d.state, d.id, d.info)
+
+	return nil
+}
+
+// Delete deletes the pool.
+func (d *pool) Delete() error {
+	allocations, err := d.allocations()
+	if err != nil {
+		return err
+	}
+
+	if len(allocations) > 0 {
+		return api.StatusErrorf(http.StatusBadRequest, "Cannot delete a network address pool that is in use")
+	}
+
+	return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteNetworkAddressPool(ctx, d.id)
+	})
+}
@@ -29,10 +29,15 @@ import (
 	"github.com/canonical/lxd/lxd/dnsmasq/dhcpalloc"
 	firewallDrivers "github.com/canonical/lxd/lxd/firewall/drivers"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/instance/sshkeys"
 	"github.com/canonical/lxd/lxd/ip"
 	"github.com/canonical/lxd/lxd/network/acl"
+	"github.com/canonical/lxd/lxd/network/addresspool"
+	"github.com/canonical/lxd/lxd/network/externaldns"
+	"github.com/canonical/lxd/lxd/network/imds"
 	"github.com/canonical/lxd/lxd/network/openvswitch"
 	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/sandbox"
 	"github.com/canonical/lxd/lxd/subprocess"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/lxd/warnings"
@@ -167,7 +172,7 @@ func (n *bridge) populateAutoConfig(config map[string]string) error {
 	if config["fan.underlay_subnet"] == "auto" {
 		subnet, _, err := DefaultGatewaySubnetV4()
 		if err != nil {
-			return err
+			return fmt.Errorf("Failed detecting the fan underlay subnet (the fan requires an IPv4 underlay, consider using bridge.mode=overlay on IPv6-only hosts): %w", err)
 		}
 
 		config["fan.underlay_subnet"] = subnet.String()
@@ -275,19 +280,20 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: MAC address for the bridge
 		"bridge.hwaddr": validate.Optional(validate.IsNetworkMAC),
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bridge.mtu)
-		// The default value varies depending on whether the bridge uses a tunnel or a fan setup.
+		// The default value varies depending on whether the bridge uses a tunnel, a fan or an overlay setup.
+		// In those cases, it is derived from the MTU of the underlay interface minus the encapsulation overhead, up to 1500.
 		// ---
 		//  type: integer
-		//  defaultdesc: `1500` if `bridge.mode=standard`, `1480` if `bridge.mode=fan` and `fan.type=ipip`, or `1450` if `bridge.mode=fan` and `fan.type=vxlan`
+		//  defaultdesc: `1500` if `bridge.mode=standard`, otherwise derived from the underlay interface MTU
 		//  shortdesc: Bridge MTU
 		"bridge.mtu": validate.Optional(validate.IsNetworkMTU),
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=bridge.mode)
-		// Possible values are `standard` and `fan`.
+		// Possible values are `standard`, `fan` and `overlay`.
 		// ---
 		//  type: string
 		//  defaultdesc: `standard`
 		//  shortdesc: Bridge operation mode
-		"bridge.mode": validate.Optional(validate.IsOneOf("standard", "fan")),
+		"bridge.mode": validate.Optional(validate.IsOneOf("standard", "fan", "overlay")),
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=fan.overlay_subnet)
 		// Use CIDR notation.
 		// ---
@@ -320,6 +326,46 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  defaultdesc: `vxlan`
 		//  shortdesc: Tunneling type for the FAN
 		"fan.type": validate.Optional(validate.IsOneOf("vxlan", "ipip")),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=overlay.protocol)
+		// Possible values are `vxlan` and `geneve`.
+		// ---
+		//  type: string
+		//  condition: overlay mode
+		//  defaultdesc: `vxlan`
+		//  shortdesc: Tunneling protocol for the overlay
+		"overlay.protocol": validate.Optional(validate.IsOneOf("vxlan", "geneve")),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=overlay.id)
+		//
+		// ---
+		//  type: integer
+		//  condition: overlay mode
+		//  defaultdesc: network ID
+		//  shortdesc: Virtual network identifier of the overlay
+		"overlay.id": validate.Optional(validate.IsInRange(1, 16777215)),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=overlay.port)
+		//
+		// ---
+		//  type: integer
+		//  condition: overlay mode
+		//  defaultdesc: `4789` for `vxlan` or `6081` for `geneve`
+		//  shortdesc: UDP port used by the overlay tunnels
+		"overlay.port": validate.Optional(networkValidPort),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=overlay.group)
+		// If set, broadcast, unknown unicast and multicast traffic is sent to this multicast group.
+		// Otherwise, it is replicated to every cluster member.
+		// ---
+		//  type: string
+		//  condition: overlay mode with `vxlan`
+		//  shortdesc: Multicast group of the overlay
+		"overlay.group": validate.Optional(validate.IsNetworkAddress),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=overlay.interface)
+		//
+		// ---
+		//  type: string
+		//  condition: overlay mode with `overlay.group`
+		//  defaultdesc: default gateway interface
+		//  shortdesc: Underlay interface used to join the multicast group
+		"overlay.interface": validate.Optional(validate.IsInterfaceName),
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv4.address)
 		// Use CIDR notation.
 		//
@@ -375,6 +421,14 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  defaultdesc: `true`
 		//  shortdesc: Whether to allocate IPv4 addresses using DHCP
 		"ipv4.dhcp": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv4.metadata)
+		// See {ref}`network-bridge-metadata` for more information.
+		// ---
+		//  type: bool
+		//  condition: IPv4 address
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to serve instance metadata on `169.254.169.254`
+		"ipv4.metadata": validate.Optional(validate.IsBool),
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=ipv4.dhcp.gateway)
 		//
 		// ---
@@ -559,6 +613,71 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  type: string
 		//  shortdesc: DNS zone name for IPv6 reverse DNS records
 		"dns.zone.reverse.ipv6": validate.IsAny,
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=dns.external.provider)
+		// Possible values are `rfc2136` for dynamic DNS updates and `webhook` for an HTTP endpoint.
+		// The A and AAAA records of the instances connected to the network are then published in the configured zone.
+		// ---
+		//  type: string
+		//  shortdesc: External DNS provider to publish instance records into
+		"dns.external.provider": validate.Optional(validate.IsOneOf("rfc2136", "webhook")),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=dns.external.zone)
+		//
+		// ---
+		//  type: string
+		//  condition: external DNS provider
+		//  shortdesc: External DNS zone to publish instance records into
+		"dns.external.zone": validate.IsAny,
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=dns.external.ttl)
+		//
+		// ---
+		//  type: integer
+		//  condition: external DNS provider
+		//  defaultdesc: `300`
+		//  shortdesc: TTL (in seconds) of the published records
+		"dns.external.ttl": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=dns.external.rfc2136.nameserver)
+		// Specify the address of the primary DNS server of the zone, optionally with a port.
+		// ---
+		//  type: string
+		//  condition: `rfc2136` provider
+		//  shortdesc: DNS server to send the updates to
+		"dns.external.rfc2136.nameserver": validate.Optional(validate.IsListenAddress(false, false, false)),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=dns.external.rfc2136.tsig.key)
+		//
+		// ---
+		//  type: string
+		//  condition: `rfc2136` provider
+		//  shortdesc: Name of the TSIG key used to sign the updates
+		"dns.external.rfc2136.tsig.key": validate.IsAny,
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=dns.external.rfc2136.tsig.secret)
+		//
+		// ---
+		//  type: string
+		//  condition: `rfc2136` provider
+		//  shortdesc: Base64-encoded secret of the TSIG key
+		"dns.external.rfc2136.tsig.secret": validate.IsAny,
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=dns.external.rfc2136.tsig.algorithm)
+		//
+		// ---
+		//  type: string
+		//  condition: `rfc2136` provider
+		//  defaultdesc: `hmac-sha256`
+		//  shortdesc: Algorithm of the TSIG key
+		"dns.external.rfc2136.tsig.algorithm": validate.Optional(validate.IsOneOf("hmac-sha1", "hmac-sha224", "hmac-sha256", "hmac-sha384", "hmac-sha512")),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=dns.external.webhook.url)
+		// Each change is sent as a JSON `POST` request to this URL.
+		// ---
+		//  type: string
+		//  condition: `webhook` provider
+		//  shortdesc: URL of the webhook
+		"dns.external.webhook.url": validate.Optional(validate.IsRequestURL),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=dns.external.webhook.token)
+		// The token is sent in the `Authorization` header as a bearer token.
+		// ---
+		//  type: string
+		//  condition: `webhook` provider
+		//  shortdesc: Token to authenticate against the webhook
+		"dns.external.webhook.token": validate.IsAny,
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=raw.dnsmasq)
 		//
 		// ---
@@ -745,12 +864,35 @@ func (n *bridge) Validate(config map[string]string) error {
 		return err
 	}
 
+	// Validate external DNS settings.
+	err = externaldns.ValidateConfig(config)
+	if err != nil {
+		return err
+	}
+
 	// Validate network name when used in fan mode.
 	bridgeMode := config["bridge.mode"]
 	if bridgeMode == "fan" && len(n.name) > 11 {
 		return fmt.Errorf("Network name too long to use with the FAN (must be 11 characters or less)")
 	}
 
+	// Validate network name when used in overlay mode (tunnel interfaces are named after the network).
+	if bridgeMode == "overlay" && len(n.name) > 11 {
+		return fmt.Errorf("Network name too long to use with an overlay (must be 11 characters or less)")
+	}
+
+	if bridgeMode == "overlay" && config["bridge.driver"] == "openvswitch" {
+		return fmt.Errorf("Overlay mode cannot be used with the openvswitch bridge driver")
+	}
+
+	if config["overlay.group"] != "" && config["overlay.protocol"] == "geneve" {
+		return fmt.Errorf(`"overlay.group" cannot be used with the "geneve" overlay protocol`)
+	}
+
+	if config["overlay.interface"] != "" && config["overlay.group"] == "" {
+		return fmt.Errorf(`"overlay.interface" can only be used with "overlay.group"`)
+	}
+
 	for k, v := range config {
 		key := k
 		// Bridge mode checks
@@ -766,6 +908,14 @@ func (n *bridge) Validate(config map[string]string) error {
 			return fmt.Errorf("FAN configuration may only be set when in 'fan' mode")
 		}
 
+		if bridgeMode != "overlay" && strings.HasPrefix(key, "overlay.") && v != "" {
+			return fmt.Errorf("Overlay configuration may only be set when in 'overlay' mode")
+		}
+
+		if bridgeMode == "overlay" && strings.HasPrefix(key, "tunnel.") && v != "" {
+			return fmt.Errorf("Tunnel configuration may not be set when in 'overlay' mode")
+		}
+
 		// MTU checks
 		if key == "bridge.mtu" && v != "" {
 			mtu, err := strconv.ParseInt(v, 10, 64)
@@ -805,6 +955,11 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
+	// Check the metadata service has addresses to serve.
+	if shared.IsTrue(config["ipv4.metadata"]) && shared.ValueInSlice(config["ipv4.address"], []string{"", "none"}) {
+		return fmt.Errorf(`"ipv4.metadata" requires "ipv4.address" to be set`)
+	}
+
 	// Check IPv4 OVN ranges.
 	if config["ipv4.ovn.ranges"] != "" && shared.IsTrueOrEmpty(config["ipv4.dhcp"]) {
 		dhcpSubnet := n.DHCPv4Subnet()
@@ -1024,13 +1179,16 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 
 		bridge.MTU = uint32(mtuInt)
 	} else if len(tunnels) > 0 {
-		bridge.MTU = 1400
+		// Derive the MTU from the underlays of the tunnels, accounting for the encapsulation overhead.
+		bridge.MTU = overlayMTUFromUnderlays(n.getUnderlays(), 1400)
 	} else if n.config["bridge.mode"] == "fan" {
 		if n.config["fan.type"] == "ipip" {
 			bridge.MTU = 1480
 		} else {
 			bridge.MTU = 1450
 		}
+	} else if n.config["bridge.mode"] == "overlay" {
+		bridge.MTU = overlayMTUFromUnderlays(n.getUnderlays(), 1450)
 	}
 
 	// Decide the MAC address of bridge interface.
@@ -1328,9 +1486,17 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	dnsmasqCmd := []string{"--keep-in-foreground", "--strict-order", "--bind-interfaces",
 		"--except-interface=lo",
 		"--pid-file=", // Disable attempt at writing a PID file.
-		"--no-ping",   // --no-ping is very important to prevent delays to lease file updates.
 		fmt.Sprintf("--interface=%s", n.name)}
 
+	if n.config["bridge.mode"] == "overlay" {
+		// The overlay is shared with the other cluster members, so only answer the DHCP requests of local
+		// instances and check that an address isn't used elsewhere on the overlay before allocating it.
+		dnsmasqCmd = append(dnsmasqCmd, "--dhcp-ignore=tag:!known")
+	} else {
+		// --no-ping is very important to prevent delays to lease file updates.
+		dnsmasqCmd = append(dnsmasqCmd, "--no-ping")
+	}
+
 	dnsmasqVersion, err := dnsmasq.GetVersion()
 	if err != nil {
 		return err
@@ -1416,6 +1582,20 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			return err
 		}
 
+		// Add the address of the metadata service.
+		if shared.IsTrue(n.config["ipv4.metadata"]) {
+			addr := &ip.Addr{
+				DevName: n.name,
+				Address: imds.Address + "/32",
+				Family:  ip.FamilyV4,
+			}
+
+			err = addr.Add()
+			if err != nil {
+				return err
+			}
+		}
+
 		// Configure NAT.
 		if shared.IsTrue(n.config["ipv4.nat"]) {
 			// If a SNAT source address is specified, use that, otherwise default to MASQUERADE mode.
@@ -1684,16 +1864,11 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			fanAddress = fmt.Sprintf("%s/24", addr[0])
 		}
 
-		// Update the MTU based on overlay device (if available).
-		fanMTU, err := GetDevMTU(devName)
-		if err == nil {
-			// Apply overhead.
-			if n.config["fan.type"] == "ipip" {
-				fanMTU = fanMTU - 20
-			} else {
-				fanMTU = fanMTU - 50
-			}
-
+		// Update the MTU based on overlay device (if available), accounting for the encapsulation overhead.
+		fanUnderlay := mtuUnderlay{encapsulation: n.fanEncapsulation()}
+		fanUnderlay.iface, fanUnderlay.mtu, err = underlayInterface("", devName)
+		fanMTU := fanUnderlay.overlayMTU()
+		if err == nil && fanMTU > 0 {
 			// Apply changes.
 			if fanMTU != bridge.MTU {
 				bridge.MTU = fanMTU
@@ -1893,7 +2068,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 
 				devName := tunInterface
 				if devName == "" {
-					_, devName, err = DefaultGatewaySubnetV4()
+					devName, err = DefaultGatewayInterface()
 					if err != nil {
 						return err
 					}
@@ -1955,6 +2130,19 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		}
 	}
 
+	// Configure the overlay.
+	if n.config["bridge.mode"] == "overlay" {
+		peers, err := n.overlayPeers()
+		if err != nil {
+			return err
+		}
+
+		err = n.overlaySetup(bridge.MTU, peers)
+		if err != nil {
+			return fmt.Errorf("Failed setting up overlay: %w", err)
+		}
+	}
+
 	// Generate and load apparmor profiles.
 	err = apparmor.NetworkLoad(n.state.OS, n)
 	if err != nil {
@@ -2055,6 +2243,9 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			}
 		}
 
+		// Confine dnsmasq in its sandbox.
+		sandbox.Dnsmasq.Apply(p, n.state.OS.ExecPath)
+
 		// Start dnsmasq.
 		err = p.Start(context.Background())
 		if err != nil {
@@ -2161,6 +2352,17 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
+	// Setup the instance metadata service.
+	if shared.IsTrue(n.config["ipv4.metadata"]) {
+		err = metadataStart(n.name, n.metadataLookup)
+	} else {
+		err = metadataStop(n.name)
+	}
+
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	return nil
 }
@@ -2179,6 +2381,12 @@ func (n *bridge) Stop() error {
 		return err
 	}
 
+	// Stop the instance metadata service.
+	err = metadataStop(n.name)
+	if err != nil {
+		return err
+	}
+
 	// Destroy the bridge interface
 	if n.config["bridge.driver"] == "openvswitch" {
 		ovs := openvswitch.NewOVS()
@@ -2367,6 +2575,9 @@ func (n *bridge) spawnForkDNS(listenAddress string) error {
 	// Apply AppArmor profile.
 	p.SetApparmor(apparmor.ForkdnsProfileName(n))
 
+	// Confine forkdns in its sandbox.
+	sandbox.ForkDNS.Apply(p, command)
+
 	err = p.Start(context.Background())
 	if err != nil {
 		return fmt.Errorf("Failed to run: %s %s: %w", command, strings.Join(forkdnsargs, " "), err)
@@ -2386,9 +2597,332 @@ func (n *bridge) spawnForkDNS(listenAddress string) error {
 	return nil
 }
 
+// overlayTunnelName returns the name of an overlay tunnel interface. A single interface is used with vxlan, while
+// geneve uses an interface for each other cluster member, named after the member ID.
+func (n *bridge) overlayTunnelName(memberID int64) string {
+	if memberID > 0 {
+		return fmt.Sprintf("%s-%x", n.name, memberID)
+	}
+
+	return fmt.Sprintf("%s-ovl", n.name)
+}
+
+// overlayPeers returns the underlay address of the other cluster members, keyed by member ID.
+func (n *bridge) overlayPeers() (map[int64]net.IP, error) {
+	var members []db.NodeInfo
+
+	err := n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		members, err = tx.GetNodes(ctx)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting cluster members: %w", err)
+	}
+
+	addresses := make(map[int64]string, len(members))
+	for _, member := range members {
+		addresses[member.ID] = member.Address
+	}
+
+	return n.overlayPeerAddresses(addresses)
+}
+
+// overlayPeerAddresses resolves the given cluster member addresses into underlay addresses, excluding ourselves.
+func (n *bridge) overlayPeerAddresses(addresses map[int64]string) (map[int64]net.IP, error) {
+	peers := map[int64]net.IP{}
+
+	if !n.state.ServerClustered {
+		return peers, nil
+	}
+
+	localClusterAddress := n.state.LocalConfig.ClusterAddress()
+	for memberID, address := range addresses {
+		if address == localClusterAddress {
+			continue
+		}
+
+		peer, err := overlayUnderlayAddress(address)
+		if err != nil {
+			return nil, err
+		}
+
+		peers[memberID] = peer
+	}
+
+	return peers, nil
+}
+
+// overlayUnderlayAddress returns the IP address to use as tunnel endpoint for the given cluster address.
+func overlayUnderlayAddress(address string) (net.IP, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+
+	ipAddr, err := net.ResolveIPAddr("ip", host)
+	if err != nil {
+		return nil, fmt.Errorf("Failed resolving cluster address %q: %w", address, err)
+	}
+
+	return ipAddr.IP, nil
+}
+
+// overlaySetup creates the overlay tunnel interfaces and connects them to the bridge.
+func (n *bridge) overlaySetup(mtu uint32, peers map[int64]net.IP) error {
+	if n.config["overlay.protocol"] == "geneve" {
+		for memberID, peer := range peers {
+			err := n.overlayAddGeneve(memberID, peer, mtu)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	port := n.config["overlay.port"]
+	if port == "" {
+		port = "4789"
+	}
+
+	overlayID := n.config["overlay.id"]
+	if overlayID == "" {
+		overlayID = fmt.Sprintf("%d", n.id)
+	}
+
+	vxlan := &ip.Vxlan{
+		Link:    ip.Link{Name: n.overlayTunnelName(0)},
+		VxlanID: overlayID,
+		DstPort: port,
+	}
+
+	if n.config["overlay.group"] != "" {
+		// Send broadcast, unknown unicast and multicast traffic to the multicast group.
+		devName := n.config["overlay.interface"]
+		if devName == "" {
+			var err error
+
+			devName, err = DefaultGatewayInterface()
+			if err != nil {
+				return err
+			}
+		}
+
+		vxlan.Group = n.config["overlay.group"]
+		vxlan.DevName = devName
+	} else if n.state.ServerClustered {
+		// Replicate broadcast, unknown unicast and multicast traffic to each peer using the forwarding database.
+		local, err := overlayUnderlayAddress(n.state.LocalConfig.ClusterAddress())
+		if err != nil {
+			return err
+		}
+
+		vxlan.Local = local.String()
+	}
+
+	err := vxlan.Add()
+	if err != nil {
+		return err
+	}
+
+	err = n.overlayAttach(vxlan.Name, mtu)
+	if err != nil {
+		return err
+	}
+
+	if n.config["overlay.group"] == "" {
+		return n.overlaySyncFdb(peers)
+	}
+
+	return nil
+}
+
+// overlayAddGeneve creates the geneve tunnel interface to the given cluster member.
+// The bridge port is isolated so that traffic received from a peer is never sent to another peer, which avoids
+// loops between the fully meshed tunnels.
+func (n *bridge) overlayAddGeneve(memberID int64, peer net.IP, mtu uint32) error {
+	port := n.config["overlay.port"]
+	if port == "" {
+		port = "6081"
+	}
+
+	overlayID := n.config["overlay.id"]
+	if overlayID == "" {
+		overlayID = fmt.Sprintf("%d", n.id)
+	}
+
+	geneve := &ip.Geneve{
+		Link:    ip.Link{Name: n.overlayTunnelName(memberID)},
+		ID:      overlayID,
+		Remote:  peer.String(),
+		DstPort: port,
+	}
+
+	err := geneve.Add()
+	if err != nil {
+		return err
+	}
+
+	err = n.overlayAttach(geneve.Name, mtu)
+	if err != nil {
+		return err
+	}
+
+	return geneve.BridgeLinkSetIsolated(true)
+}
+
+// overlayAttach connects an overlay tunnel interface to the bridge and brings it up.
+func (n *bridge) overlayAttach(tunName string, mtu uint32) error {
+	err := AttachInterface(n.name, tunName)
+	if err != nil {
+		return err
+	}
+
+	tunLink := &ip.Link{Name: tunName}
+	err = tunLink.SetMTU(mtu)
+	if err != nil {
+		return err
+	}
+
+	return tunLink.SetUp()
+}
+
+// overlaySyncFdb updates the forwarding database of the vxlan tunnel interface so that broadcast, unknown unicast
+// and multicast traffic is replicated to each of the given peers.
+func (n *bridge) overlaySyncFdb(peers map[int64]net.IP) error {
+	tunName := n.overlayTunnelName(0)
+	allZeros := net.HardwareAddr{0, 0, 0, 0, 0, 0}
+
+	fdb := &ip.Fdb{DevName: tunName, MAC: allZeros}
+	entries, err := fdb.Show()
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]bool{}
+	for _, entry := range entries {
+		existing[entry.Dst.String()] = true
+	}
+
+	wanted := map[string]bool{}
+	for _, peer := range peers {
+		wanted[peer.String()] = true
+
+		if existing[peer.String()] {
+			continue
+		}
+
+		entry := &ip.Fdb{DevName: tunName, MAC: allZeros, Dst: peer}
+		err := entry.Append()
+		if err != nil {
+			return fmt.Errorf("Failed adding overlay peer %q: %w", peer.String(), err)
+		}
+	}
+
+	for _, entry := range entries {
+		if wanted[entry.Dst.String()] {
+			continue
+		}
+
+		err := entry.Delete()
+		if err != nil {
+			return fmt.Errorf("Failed removing overlay peer %q: %w", entry.Dst.String(), err)
+		}
+	}
+
+	return nil
+}
+
+// overlayHandleHeartbeat updates the overlay tunnels when cluster members are added or removed.
+func (n *bridge) overlayHandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
+	// Nothing to do if the network isn't running.
+	if !InterfaceExists(n.name) {
+		return nil
+	}
+
+	addresses := make(map[int64]string, len(heartbeatData.Members))
+	for _, member := range heartbeatData.Members {
+		addresses[member.ID] = member.Address
+	}
+
+	peers, err := n.overlayPeerAddresses(addresses)
+	if err != nil {
+		return err
+	}
+
+	n.logger.Info("Refreshing overlay peers")
+
+	if n.config["overlay.protocol"] != "geneve" {
+		// Multicast overlays don't need to know about their peers.
+		if n.config["overlay.group"] != "" {
+			return nil
+		}
+
+		return n.overlaySyncFdb(peers)
+	}
+
+	mtu, err := GetDevMTU(n.name)
+	if err != nil {
+		return err
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return err
+	}
+
+	// Remove the tunnels to members which left the cluster.
+	existing := map[int64]bool{}
+	for _, iface := range ifaces {
+		suffix, found := strings.CutPrefix(iface.Name, fmt.Sprintf("%s-", n.name))
+		if !found {
+			continue
+		}
+
+		memberID, err := strconv.ParseInt(suffix, 16, 64)
+		if err != nil {
+			continue
+		}
+
+		_, ok := peers[memberID]
+		if ok {
+			existing[memberID] = true
+			continue
+		}
+
+		tunLink := &ip.Link{Name: iface.Name}
+		err = tunLink.Delete()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Add the tunnels to new members.
+	for memberID, peer := range peers {
+		if existing[memberID] {
+			continue
+		}
+
+		err := n.overlayAddGeneve(memberID, peer, mtu)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // HandleHeartbeat refreshes forkdns servers. Retrieves the IPv4 address of each cluster node (excluding ourselves)
 // for this network. It then updates the forkdns server list file if there are changes.
+// In overlay mode, it updates the overlay tunnels to the other cluster members instead.
 func (n *bridge) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
+	if n.config["bridge.mode"] == "overlay" {
+		return n.overlayHandleHeartbeat(heartbeatData)
+	}
+
 	// Make sure forkdns has been setup.
 	if !shared.PathExists(shared.VarPath("networks", n.name, "forkdns.pid")) {
 		return nil
@@ -2470,6 +3004,152 @@ func (n *bridge) getTunnels() []string {
 	return tunnels
 }
 
+// fanEncapsulation returns the encapsulation protocol used by the fan.
+func (n *bridge) fanEncapsulation() string {
+	if n.config["fan.type"] == "ipip" {
+		return "ipip"
+	}
+
+	return "vxlan"
+}
+
+// getUnderlays returns the underlay interfaces carrying the encapsulated traffic of the bridge's tunnels, fan or
+// overlay. Underlays that can't be found on the host are skipped.
+func (n *bridge) getUnderlays() []mtuUnderlay {
+	underlays := []mtuUnderlay{}
+
+	addUnderlay := func(encapsulation string, localAddress string, ifaceName string, ipv6 bool) {
+		iface, mtu, err := underlayInterface(localAddress, ifaceName)
+		if err != nil {
+			n.logger.Debug("Failed finding underlay interface", logger.Ctx{"encapsulation": encapsulation, "err": err})
+			return
+		}
+
+		underlays = append(underlays, mtuUnderlay{encapsulation: encapsulation, ipv6: ipv6, iface: iface, mtu: mtu})
+	}
+
+	isIPv6 := func(address string) bool {
+		parsedIP := net.ParseIP(address)
+
+		return parsedIP != nil && parsedIP.To4() == nil
+	}
+
+	for _, tunnel := range n.getTunnels() {
+		getConfig := func(key string) string {
+			return n.config[fmt.Sprintf("tunnel.%s.%s", tunnel, key)]
+		}
+
+		tunProtocol := getConfig("protocol")
+		if tunProtocol != "gre" && tunProtocol != "vxlan" {
+			continue
+		}
+
+		// Multicast vxlan tunnels have no local address and use the configured interface instead.
+		tunRemote := getConfig("remote")
+		addUnderlay(tunProtocol, getConfig("local"), getConfig("interface"), isIPv6(tunRemote) || isIPv6(getConfig("group")))
+	}
+
+	switch n.config["bridge.mode"] {
+	case "fan":
+		_, underlaySubnet, err := net.ParseCIDR(n.config["fan.underlay_subnet"])
+		if err != nil {
+			break
+		}
+
+		overlay := n.config["fan.overlay_subnet"]
+		if overlay == "" {
+			overlay = "240.0.0.0/8"
+		}
+
+		_, overlaySubnet, err := net.ParseCIDR(overlay)
+		if err != nil {
+			break
+		}
+
+		_, devName, _, err := n.fanAddress(underlaySubnet, overlaySubnet)
+		if err != nil {
+			break
+		}
+
+		addUnderlay(n.fanEncapsulation(), "", devName, false)
+	case "overlay":
+		protocol := n.config["overlay.protocol"]
+		if protocol == "" {
+			protocol = "vxlan"
+		}
+
+		if n.config["overlay.group"] != "" || !n.state.ServerClustered {
+			addUnderlay(protocol, "", n.config["overlay.interface"], isIPv6(n.config["overlay.group"]))
+			break
+		}
+
+		local, err := overlayUnderlayAddress(n.state.LocalConfig.ClusterAddress())
+		if err != nil {
+			n.logger.Debug("Failed finding overlay underlay address", logger.Ctx{"err": err})
+			break
+		}
+
+		addUnderlay(protocol, local.String(), "", local.To4() == nil)
+	}
+
+	return underlays
+}
+
+// MTUCheck checks the MTU of the bridge against its underlays, its ports and the NICs of the local instances
+// using it.
+func (n *bridge) MTUCheck() (*api.NetworkMTU, error) {
+	mtu, err := GetDevMTU(n.name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting MTU of %q: %w", n.name, err)
+	}
+
+	check := newMTUCheck(mtu, n.getUnderlays())
+
+	ports, err := bridgePortMTUs(n.name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, port := range sortedPortNames(ports) {
+		if ports[port] == mtu {
+			continue
+		}
+
+		check.Mismatches = append(check.Mismatches, api.NetworkMTUMismatch{
+			Interface:   port,
+			MTU:         int(ports[port]),
+			ExpectedMTU: int(mtu),
+			Description: "Bridge port MTU differs from the bridge MTU",
+		})
+	}
+
+	// Instance NICs with a larger MTU than the bridge send frames that the bridge drops.
+	err = UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		if nicConfig["mtu"] == "" {
+			return nil
+		}
+
+		nicMTU, err := strconv.ParseUint(nicConfig["mtu"], 10, 32)
+		if err != nil || uint32(nicMTU) <= mtu {
+			return nil
+		}
+
+		check.Mismatches = append(check.Mismatches, api.NetworkMTUMismatch{
+			Interface:   fmt.Sprintf("%s/%s", project.Instance(inst.Project, inst.Name), nicName),
+			MTU:         int(nicMTU),
+			ExpectedMTU: int(mtu),
+			Description: "Instance NIC MTU is larger than the bridge MTU",
+		})
+
+		return nil
+	}, dbCluster.InstanceFilter{Node: &n.state.ServerName})
+	if err != nil {
+		return nil, err
+	}
+
+	return check, nil
+}
+
 // bootRoutesV4 returns a list of IPv4 boot routes on the network's device.
 func (n *bridge) bootRoutesV4() ([]string, error) {
 	r := &ip.Route{
@@ -3067,8 +3747,42 @@ func (n *bridge) ForwardCreate(forward api.NetworkForwardsPost, clientType reque
 		return nil, fmt.Errorf("Failed parsing address forward listen address %q: %w", forward.ListenAddress, err)
 	}
 
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Allocate the listen address from the network address pool if requested.
+	var pool addresspool.NetworkAddressPool
+	if forward.Config["address_pool"] != "" {
+		if !listenAddressNet.IP.IsUnspecified() {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "The listen address must be unspecified (0.0.0.0 or ::) when using a network address pool")
+		}
+
+		pool, err = addresspool.LoadByName(n.state, forward.Config["address_pool"])
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading network address pool %q: %w", forward.Config["address_pool"], err)
+		}
+
+		ipVersion := uint(4)
+		if listenAddressNet.IP.To4() == nil {
+			ipVersion = 6
+		}
+
+		listenAddress, err := pool.Allocate(ipVersion, db.NetworkAddressPoolAllocation{})
+		if err != nil {
+			return nil, err
+		}
+
+		revert.Add(func() { _ = pool.Release(listenAddress) })
+
+		forward.ListenAddress = listenAddress.String()
+		listenAddressNet, err = ParseIPToNet(forward.ListenAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if listenAddressNet.IP.IsUnspecified() {
-		return nil, api.StatusErrorf(http.StatusNotImplemented, "Automatic listen address allocation not supported for drivers of type %q", n.netType)
+		return nil, api.StatusErrorf(http.StatusNotImplemented, "Automatic listen address allocation requires a network address pool for drivers of type %q", n.netType)
 	}
 
 	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -3078,7 +3792,7 @@ func (n *bridge) ForwardCreate(forward api.NetworkForwardsPost, clientType reque
 		return err
 	})
 	if err == nil {
-		return nil, api.StatusErrorf(http.StatusConflict, "A forward for that listen address already exists")
+		return nil, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "A forward for that listen address already exists")
 	}
 
 	_, err = n.forwardValidate(listenAddressNet.IP, forward.NetworkForwardPut)
@@ -3120,9 +3834,6 @@ func (n *bridge) ForwardCreate(forward api.NetworkForwardsPost, clientType reque
 		return nil, fmt.Errorf("Forward listen address %q overlaps with another network or NIC", listenAddressNet.String())
 	}
 
-	revert := revert.New()
-	defer revert.Fail()
-
 	var forwardID int64
 
 	err = n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -3143,6 +3854,15 @@ func (n *bridge) ForwardCreate(forward api.NetworkForwardsPost, clientType reque
 		_ = n.forwardBGPSetupPrefixes()
 	})
 
+	// Record the forward as the user of the address allocated from the pool.
+	// The address is released automatically when the forward is deleted.
+	if pool != nil {
+		err = pool.AssignForward(listenAddressNet.IP, forwardID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = n.forwardSetupFirewall()
 	if err != nil {
 		return nil, err
@@ -3256,6 +3976,10 @@ func (n *bridge) ForwardUpdate(listenAddress string, req api.NetworkForwardPut,
 		return err
 	}
 
+	if req.Config["address_pool"] != curForward.Config["address_pool"] {
+		return api.StatusErrorf(http.StatusBadRequest, "The network address pool of a forward cannot be changed")
+	}
+
 	curForwardEtagHash, err := util.EtagHash(curForward.Etag())
 	if err != nil {
 		return err
@@ -3641,6 +4365,91 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 	return leases, nil
 }
 
+// metadataLookup returns the metadata of the local instance with a NIC on the network using the given address.
+// The instance is identified by the MAC address the address resolves to on the bridge.
+func (n *bridge) metadataLookup(ctx context.Context, address net.IP) (*imds.Instance, error) {
+	neigh := &ip.Neigh{DevName: n.name}
+	neighbours, err := neigh.Show()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting neighbours of %q: %w", n.name, err)
+	}
+
+	var mac net.HardwareAddr
+	for _, neighbour := range neighbours {
+		if neighbour.Addr.Equal(address) && neighbour.MAC != nil {
+			mac = neighbour.MAC
+			break
+		}
+	}
+
+	if mac == nil {
+		return nil, api.StatusErrorf(http.StatusNotFound, "No neighbour found with address %q", address.String())
+	}
+
+	var globalConfigDump map[string]any
+	if n.state.GlobalConfig != nil {
+		globalConfigDump = n.state.GlobalConfig.Dump()
+	}
+
+	var metadata *imds.Instance
+	var instConfig map[string]string
+	var instProject string
+	filter := dbCluster.InstanceFilter{Node: &n.state.ServerName}
+	err = UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		if metadata != nil {
+			return nil
+		}
+
+		hwaddr := nicConfig["hwaddr"]
+		if hwaddr == "" {
+			hwaddr = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+		}
+
+		nicMAC, _ := net.ParseMAC(hwaddr)
+		if nicMAC == nil || nicMAC.String() != mac.String() {
+			return nil
+		}
+
+		inst.Config = instancetype.ExpandInstanceConfig(globalConfigDump, inst.Config, inst.Profiles)
+		metadata = metadataInstance(inst, mac, address)
+		instConfig = inst.Config
+		instProject = inst.Project
+
+		return nil
+	}, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata == nil {
+		return nil, api.StatusErrorf(http.StatusNotFound, "No instance found with MAC address %q", mac.String())
+	}
+
+	// Add the SSH keys of the project selected by the instance.
+	names := sshkeys.Selected(instConfig)
+	if len(names) > 0 {
+		err = n.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			keys, err := dbCluster.GetSSHKeys(ctx, tx.Tx(), dbCluster.SSHKeyFilter{Project: &instProject})
+			if err != nil {
+				return err
+			}
+
+			for _, key := range keys {
+				if shared.ValueInSlice(key.Name, names) {
+					metadata.SSHKeys[key.Name] = key.PublicKey
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading SSH keys: %w", err)
+		}
+	}
+
+	return metadata, nil
+}
+
 // UsesDNSMasq indicates if network's config indicates if it needs to use dnsmasq.
 func (n *bridge) UsesDNSMasq() bool {
 	return n.config["bridge.mode"] == "fan" || !shared.ValueInSlice(n.config["ipv4.address"], []string{"", "none"}) || !shared.ValueInSlice(n.config["ipv6.address"], []string{"", "none"})
@@ -399,6 +399,8 @@ func (n *common) update(applyNetwork api.NetworkPut, targetNode string, clientTy
 		}
 	}
 
+	n.state.DB.Cluster.EntityCache().Invalidate(entity.TypeNetwork, n.name)
+
 	return nil
 }
 
@@ -473,6 +475,8 @@ func (n *common) rename(newName string) error {
 		return err
 	}
 
+	n.state.DB.Cluster.EntityCache().Invalidate(entity.TypeNetwork, n.name)
+
 	// Reinitialise internal name variable and logger context with new name.
 	n.name = newName
 
@@ -513,6 +517,8 @@ func (n *common) delete() error {
 	delete(unavailableNetworks, pn)
 	unavailableNetworksMu.Unlock()
 
+	n.state.DB.Cluster.EntityCache().Invalidate(entity.TypeNetwork, n.name)
+
 	return nil
 }
 
@@ -863,6 +869,11 @@ func (n *common) forwardValidate(listenAddress net.IP, forward api.NetworkForwar
 			continue
 		}
 
+		// Bridge networks can allocate listen addresses from network address pools.
+		if k == "address_pool" && n.netType == "bridge" {
+			continue
+		}
+
 		// User keys are not validated.
 		if shared.IsUserConfig(k) {
 			continue
@@ -1397,6 +1408,11 @@ func (n *common) Leases(projectName string, clientType request.ClientType) ([]ap
 	return nil, ErrNotImplemented
 }
 
+// MTUCheck returns ErrNotImplemented for drivers that do not support MTU checks.
+func (n *common) MTUCheck() (*api.NetworkMTU, error) {
+	return nil, ErrNotImplemented
+}
+
 // PeerCreate returns ErrNotImplemented for drivers that do not support forwards.
 func (n *common) PeerCreate(forward api.NetworkPeersPost) error {
 	return ErrNotImplemented
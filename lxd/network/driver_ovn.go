@@ -27,6 +27,7 @@ import (
 	"github.com/canonical/lxd/lxd/ip"
 	"github.com/canonical/lxd/lxd/locking"
 	"github.com/canonical/lxd/lxd/network/acl"
+	"github.com/canonical/lxd/lxd/network/externaldns"
 	"github.com/canonical/lxd/lxd/network/openvswitch"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/util"
@@ -395,9 +396,10 @@ func (n *ovn) Validate(config map[string]string) error {
 		"bridge.hwaddr": validate.Optional(validate.IsNetworkMAC),
 		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=bridge.mtu)
 		// The default value allows the host to host Geneve tunnels.
+		// It is derived from the MTU of the OVN underlay interface minus the Geneve overhead, up to 1500.
 		// ---
 		//  type: integer
-		//  defaultdesc: `1442`
+		//  defaultdesc: `1442` with an underlay MTU of 1500 and IPv4 encapsulation
 		//  shortdesc: Bridge MTU
 		"bridge.mtu": validate.Optional(validate.IsNetworkMTU),
 		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=ipv4.address)
@@ -534,6 +536,71 @@ func (n *ovn) Validate(config map[string]string) error {
 		//  type: string
 		//  shortdesc: DNS zone name for IPv6 reverse DNS records
 		"dns.zone.reverse.ipv6": validate.IsAny,
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=dns.external.provider)
+		// Possible values are `rfc2136` for dynamic DNS updates and `webhook` for an HTTP endpoint.
+		// The A and AAAA records of the instances connected to the network are then published in the configured zone.
+		// ---
+		//  type: string
+		//  shortdesc: External DNS provider to publish instance records into
+		"dns.external.provider": validate.Optional(validate.IsOneOf("rfc2136", "webhook")),
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=dns.external.zone)
+		//
+		// ---
+		//  type: string
+		//  condition: external DNS provider
+		//  shortdesc: External DNS zone to publish instance records into
+		"dns.external.zone": validate.IsAny,
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=dns.external.ttl)
+		//
+		// ---
+		//  type: integer
+		//  condition: external DNS provider
+		//  defaultdesc: `300`
+		//  shortdesc: TTL (in seconds) of the published records
+		"dns.external.ttl": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=dns.external.rfc2136.nameserver)
+		// Specify the address of the primary DNS server of the zone, optionally with a port.
+		// ---
+		//  type: string
+		//  condition: `rfc2136` provider
+		//  shortdesc: DNS server to send the updates to
+		"dns.external.rfc2136.nameserver": validate.Optional(validate.IsListenAddress(false, false, false)),
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=dns.external.rfc2136.tsig.key)
+		//
+		// ---
+		//  type: string
+		//  condition: `rfc2136` provider
+		//  shortdesc: Name of the TSIG key used to sign the updates
+		"dns.external.rfc2136.tsig.key": validate.IsAny,
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=dns.external.rfc2136.tsig.secret)
+		//
+		// ---
+		//  type: string
+		//  condition: `rfc2136` provider
+		//  shortdesc: Base64-encoded secret of the TSIG key
+		"dns.external.rfc2136.tsig.secret": validate.IsAny,
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=dns.external.rfc2136.tsig.algorithm)
+		//
+		// ---
+		//  type: string
+		//  condition: `rfc2136` provider
+		//  defaultdesc: `hmac-sha256`
+		//  shortdesc: Algorithm of the TSIG key
+		"dns.external.rfc2136.tsig.algorithm": validate.Optional(validate.IsOneOf("hmac-sha1", "hmac-sha224", "hmac-sha256", "hmac-sha384", "hmac-sha512")),
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=dns.external.webhook.url)
+		// Each change is sent as a JSON `POST` request to this URL.
+		// ---
+		//  type: string
+		//  condition: `webhook` provider
+		//  shortdesc: URL of the webhook
+		"dns.external.webhook.url": validate.Optional(validate.IsRequestURL),
+		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=dns.external.webhook.token)
+		// The token is sent in the `Authorization` header as a bearer token.
+		// ---
+		//  type: string
+		//  condition: `webhook` provider
+		//  shortdesc: Token to authenticate against the webhook
+		"dns.external.webhook.token": validate.IsAny,
 		// lxdmeta:generate(entities=network-ovn; group=network-conf; key=security.acls)
 		// Specify a comma-separated list of network ACLs.
 		// ---
@@ -597,6 +664,12 @@ func (n *ovn) Validate(config map[string]string) error {
 		return err
 	}
 
+	// Validate external DNS settings.
+	err = externaldns.ValidateConfig(config)
+	if err != nil {
+		return err
+	}
+
 	// Check that if IPv6 enabled then the network size must be at least a /64 as both RA and DHCPv6
 	// in OVN (as it generates addresses using EUI64) require at least a /64 subnet to operate.
 	_, ipv6Net, _ := net.ParseCIDR(config["ipv6.address"])
@@ -880,86 +953,74 @@ func (n *ovn) getBridgeMTU() uint32 {
 	return 0
 }
 
-// getUnderlayInfo returns the MTU for the underlay network interface and the enscapsulation IP for OVN tunnels.
-func (n *ovn) getUnderlayInfo() (uint32, net.IP, error) {
-	// findMTUFromIP searches all interfaces on the host looking for one that has specified IP.
-	findMTUFromIP := func(findIP net.IP) (uint32, error) {
-		// Look for interface that has the OVN enscapsulation IP assigned.
-		ifaces, err := net.Interfaces()
-		if err != nil {
-			return 0, fmt.Errorf("Failed getting local network interfaces: %w", err)
-		}
-
-		for _, iface := range ifaces {
-			addrs, err := iface.Addrs()
-			if err != nil {
-				continue
-			}
-
-			for _, addr := range addrs {
-				ip, _, err := net.ParseCIDR(addr.String())
-				if err != nil {
-					continue
-				}
-
-				if ip.Equal(findIP) {
-					underlayMTU, err := GetDevMTU(iface.Name)
-					if err != nil {
-						return 0, fmt.Errorf("Failed getting MTU for %q: %w", iface.Name, err)
-					}
-
-					return underlayMTU, nil // Found what we were looking for.
-				}
-			}
-		}
-
-		return 0, fmt.Errorf("No matching interface found for OVN enscapsulation IP %q", findIP.String())
-	}
-
+// getUnderlay returns the underlay network interface used for the OVN tunnels, found from the OVN encapsulation IP.
+func (n *ovn) getUnderlay() (*mtuUnderlay, error) {
 	ovs := openvswitch.NewOVS()
 	encapIP, err := ovs.OVNEncapIP()
 	if err != nil {
-		return 0, nil, fmt.Errorf("Failed getting OVN enscapsulation IP from OVS: %w", err)
+		return nil, fmt.Errorf("Failed getting OVN enscapsulation IP from OVS: %w", err)
 	}
 
-	underlayMTU, err := findMTUFromIP(encapIP)
+	iface, underlayMTU, err := underlayInterfaceFromIP(encapIP)
 	if err != nil {
-		return 0, nil, err
+		return nil, err
 	}
 
-	return underlayMTU, encapIP, nil
+	return &mtuUnderlay{encapsulation: "geneve", ipv6: encapIP.To4() == nil, iface: iface, mtu: underlayMTU}, nil
 }
 
 // getOptimalBridgeMTU returns the MTU that can be used for the bridge and instance devices based on the MTU value
 // of the OVN underlay network interface. This assumes that the OVN tunnel mechanism used is geneve and that the
 // same underlying network settings (MTU and encapsulation IP family) are used on all OVN nodes.
 func (n *ovn) getOptimalBridgeMTU() (uint32, error) {
-	// Get underlay MTU and encapsulation IP.
-	underlayMTU, encapIP, err := n.getUnderlayInfo()
+	underlay, err := n.getUnderlay()
 	if err != nil {
 		return 0, fmt.Errorf("Failed getting OVN underlay info: %w", err)
 	}
 
-	// Encapsulation family is IPv6.
-	if encapIP.To4() == nil {
-		// If the underlay's MTU is large enough to accommodate a 1500 overlay MTU and the geneve tunnel
-		// overhead of 78 bytes (when used with IPv6 encapsulation) then indicate 1500 MTU can be used.
-		if underlayMTU >= 1578 {
-			return 1500, nil
-		}
+	// The geneve tunnel overhead is 58 bytes when used with IPv4 encapsulation and 78 bytes with IPv6, so an
+	// underlay MTU of 1500 leads to an overlay MTU of 1442 or 1422 respectively.
+	mtu := overlayMTUFromUnderlays([]mtuUnderlay{*underlay}, 0)
+	if mtu == 0 {
+		return 0, fmt.Errorf("OVN underlay interface %q MTU %d is too small for geneve encapsulation", underlay.iface, underlay.mtu)
+	}
+
+	return mtu, nil
+}
 
-		// Default to 1422 which can work with an underlay MTU of 1500.
-		return 1422, nil
+// MTUCheck checks the MTU of the network against the OVN underlay network interface and its uplink.
+func (n *ovn) MTUCheck() (*api.NetworkMTU, error) {
+	mtu := n.getBridgeMTU()
+	if mtu == 0 {
+		return nil, fmt.Errorf("Network MTU isn't known yet")
 	}
 
-	// If the underlay's MTU is large enough to accommodate a 1500 overlay MTU and the geneve tunnel
-	// overhead of 58 bytes (when used with IPv4 encapsulation) then indicate 1500 MTU can be used.
-	if underlayMTU >= 1558 {
-		return 1500, nil
+	underlays := []mtuUnderlay{}
+
+	underlay, err := n.getUnderlay()
+	if err != nil {
+		n.logger.Debug("Failed getting OVN underlay info", logger.Ctx{"err": err})
+	} else {
+		underlays = append(underlays, *underlay)
 	}
 
-	// Default to 1442 which can work with underlay MTU of 1500.
-	return 1442, nil
+	check := newMTUCheck(mtu, underlays)
+
+	// The uplink network must be able to carry the traffic routed out of the network.
+	uplinkNetworkName := n.config["network"]
+	if uplinkNetworkName != "" && uplinkNetworkName != "none" {
+		uplinkMTU, err := GetDevMTU(uplinkNetworkName)
+		if err == nil && uplinkMTU < mtu {
+			check.Mismatches = append(check.Mismatches, api.NetworkMTUMismatch{
+				Interface:   uplinkNetworkName,
+				MTU:         int(uplinkMTU),
+				ExpectedMTU: int(mtu),
+				Description: "Uplink MTU is smaller than the network MTU",
+			})
+		}
+	}
+
+	return check, nil
 }
 
 // getNetworkPrefix returns OVN network prefix to use for object names.
@@ -2022,12 +2083,10 @@ func (n *ovn) allowedUplinkNetworks(p *api.Project) ([]string, error) {
 		return allowedUplinkNetworkNames, nil
 	}
 
-	// Parse the allowed uplinks and return any that are present in the actual defined networks.
-	allowedRestrictedUplinks := shared.SplitNTrimSpace(p.Config["restricted.networks.uplinks"], ",", -1, false)
-
-	for _, allowedRestrictedUplink := range allowedRestrictedUplinks {
-		if shared.ValueInSlice(allowedRestrictedUplink, uplinkNetworkNames) {
-			allowedUplinkNetworkNames = append(allowedUplinkNetworkNames, allowedRestrictedUplink)
+	// Return the actual defined networks that match the allowed uplinks.
+	for _, uplinkNetworkName := range uplinkNetworkNames {
+		if project.NetworkNameMatches(p.Config["restricted.networks.uplinks"], uplinkNetworkName) {
+			allowedUplinkNetworkNames = append(allowedUplinkNetworkNames, uplinkNetworkName)
 		}
 	}
 
@@ -5588,11 +5647,11 @@ func (n *ovn) PeerCreate(peer api.NetworkPeersPost) error {
 
 	for _, existingPeer := range peers {
 		if peer.Name == existingPeer.Name {
-			return api.StatusErrorf(http.StatusConflict, "A peer for that name already exists")
+			return api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "A peer for that name already exists")
 		}
 
 		if peer.TargetProject == existingPeer.TargetProject && peer.TargetNetwork == existingPeer.TargetNetwork {
-			return api.StatusErrorf(http.StatusConflict, "A peer for that target network already exists")
+			return api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "A peer for that target network already exists")
 		}
 	}
 
@@ -0,0 +1,217 @@
+// Package externaldns publishes the DNS records of instances into DNS servers that aren't managed by LXD.
+package externaldns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Provider publishes records into an external DNS zone.
+type Provider interface {
+	// Set creates or replaces the record set with the name and type of the given record.
+	Set(ctx context.Context, record Record) error
+
+	// Delete removes the record set with the given name and type.
+	Delete(ctx context.Context, name string, recordType string) error
+}
+
+// Record is a DNS record set. The name is relative to the zone of the provider.
+type Record struct {
+	Name   string
+	Type   string
+	Values []string
+}
+
+// Key returns a string uniquely identifying the record set.
+func (r Record) Key() string {
+	return r.Name + "/" + r.Type
+}
+
+// equal returns true if both record sets have the same name, type and values.
+func (r Record) equal(other Record) bool {
+	if r.Key() != other.Key() || len(r.Values) != len(other.Values) {
+		return false
+	}
+
+	for i := range r.Values {
+		if r.Values[i] != other.Values[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Load returns the provider configured in the given network config, or nil if none is configured.
+func Load(config map[string]string) (Provider, error) {
+	err := ValidateConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := uint32(300)
+	if config["dns.external.ttl"] != "" {
+		value, err := strconv.ParseUint(config["dns.external.ttl"], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid TTL %q: %w", config["dns.external.ttl"], err)
+		}
+
+		ttl = uint32(value)
+	}
+
+	switch config["dns.external.provider"] {
+	case "":
+		return nil, nil
+	case "rfc2136":
+		return &rfc2136{
+			zone:          config["dns.external.zone"],
+			ttl:           ttl,
+			nameserver:    config["dns.external.rfc2136.nameserver"],
+			tsigKey:       config["dns.external.rfc2136.tsig.key"],
+			tsigSecret:    config["dns.external.rfc2136.tsig.secret"],
+			tsigAlgorithm: config["dns.external.rfc2136.tsig.algorithm"],
+		}, nil
+	case "webhook":
+		return &webhook{
+			zone:  config["dns.external.zone"],
+			ttl:   ttl,
+			url:   config["dns.external.webhook.url"],
+			token: config["dns.external.webhook.token"],
+		}, nil
+	}
+
+	return nil, fmt.Errorf("Unknown external DNS provider %q", config["dns.external.provider"])
+}
+
+// ValidateConfig checks that the settings required by the configured provider are set.
+func ValidateConfig(config map[string]string) error {
+	provider := config["dns.external.provider"]
+	if provider == "" {
+		return nil
+	}
+
+	if config["dns.external.zone"] == "" {
+		return fmt.Errorf(`"dns.external.zone" must be set when using an external DNS provider`)
+	}
+
+	switch provider {
+	case "rfc2136":
+		if config["dns.external.rfc2136.nameserver"] == "" {
+			return fmt.Errorf(`"dns.external.rfc2136.nameserver" must be set when using the %q provider`, provider)
+		}
+
+		if (config["dns.external.rfc2136.tsig.key"] == "") != (config["dns.external.rfc2136.tsig.secret"] == "") {
+			return fmt.Errorf(`"dns.external.rfc2136.tsig.key" and "dns.external.rfc2136.tsig.secret" must be set together`)
+		}
+
+	case "webhook":
+		if config["dns.external.webhook.url"] == "" {
+			return fmt.Errorf(`"dns.external.webhook.url" must be set when using the %q provider`, provider)
+		}
+	}
+
+	return nil
+}
+
+// InstanceRecordName returns the name of the records of an instance, relative to the zone. Instances which aren't
+// in the project of the network get the name of their project as a suffix so that names don't collide.
+func InstanceRecordName(networkProjectName string, instanceProjectName string, instanceName string) string {
+	if instanceProjectName == networkProjectName {
+		return instanceName
+	}
+
+	return instanceName + "." + instanceProjectName
+}
+
+// RecordsFromLeases returns the A and AAAA record sets of the instances found in the given leases, keyed by
+// Record.Key().
+func RecordsFromLeases(networkProjectName string, instanceProjectName string, leases []api.NetworkLease) map[string]Record {
+	records := map[string]Record{}
+
+	for _, lease := range leases {
+		// Only publish the addresses of instances.
+		if lease.Hostname == "" || (lease.Type != "static" && lease.Type != "dynamic") {
+			continue
+		}
+
+		ip := net.ParseIP(lease.Address)
+		if ip == nil {
+			continue
+		}
+
+		record := Record{
+			Name: InstanceRecordName(networkProjectName, instanceProjectName, lease.Hostname),
+			Type: "AAAA",
+		}
+
+		if ip.To4() != nil {
+			record.Type = "A"
+		}
+
+		existing, ok := records[record.Key()]
+		if ok {
+			record = existing
+		}
+
+		record.Values = append(record.Values, ip.String())
+		sort.Strings(record.Values)
+		records[record.Key()] = record
+	}
+
+	return records
+}
+
+// Sync publishes the desired record sets which differ from the published ones and removes the published record
+// sets which aren't desired anymore. It returns the record sets which are published afterwards, even on failure,
+// along with the first error encountered.
+func Sync(ctx context.Context, provider Provider, published map[string]Record, desired map[string]Record) (map[string]Record, error) {
+	var firstErr error
+
+	result := make(map[string]Record, len(desired))
+	for key, record := range published {
+		result[key] = record
+	}
+
+	for key, record := range desired {
+		existing, ok := published[key]
+		if ok && existing.equal(record) {
+			continue
+		}
+
+		err := provider.Set(ctx, record)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("Failed publishing %s record %q: %w", record.Type, record.Name, err)
+			}
+
+			continue
+		}
+
+		result[key] = record
+	}
+
+	for key, record := range published {
+		_, ok := desired[key]
+		if ok {
+			continue
+		}
+
+		err := provider.Delete(ctx, record.Name, record.Type)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("Failed removing %s record %q: %w", record.Type, record.Name, err)
+			}
+
+			continue
+		}
+
+		delete(result, key)
+	}
+
+	return result, firstErr
+}
@@ -0,0 +1,131 @@
+package externaldns_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/network/externaldns"
+	"github.com/canonical/lxd/shared/api"
+)
+
+type fakeProvider struct {
+	set     []string
+	deleted []string
+	fail    string
+}
+
+func (p *fakeProvider) Set(ctx context.Context, record externaldns.Record) error {
+	if record.Name == p.fail {
+		return fmt.Errorf("Failed")
+	}
+
+	p.set = append(p.set, record.Key())
+	return nil
+}
+
+func (p *fakeProvider) Delete(ctx context.Context, name string, recordType string) error {
+	if name == p.fail {
+		return fmt.Errorf("Failed")
+	}
+
+	p.deleted = append(p.deleted, name+"/"+recordType)
+	return nil
+}
+
+func TestRecordsFromLeases(t *testing.T) {
+	leases := []api.NetworkLease{
+		{Hostname: "lxdbr0.gw", Address: "10.0.0.1", Type: "gateway"},
+		{Hostname: "c1", Address: "10.0.0.10", Type: "static"},
+		{Hostname: "c1", Address: "fd42::10", Type: "static"},
+		{Hostname: "c1", Address: "10.0.0.11", Type: "dynamic"},
+		{Hostname: "", Address: "10.0.0.12", Type: "dynamic"},
+	}
+
+	records := externaldns.RecordsFromLeases("default", "default", leases)
+	assert.Equal(t, map[string]externaldns.Record{
+		"c1/A":    {Name: "c1", Type: "A", Values: []string{"10.0.0.10", "10.0.0.11"}},
+		"c1/AAAA": {Name: "c1", Type: "AAAA", Values: []string{"fd42::10"}},
+	}, records)
+
+	records = externaldns.RecordsFromLeases("default", "foo", leases[1:2])
+	assert.Equal(t, map[string]externaldns.Record{
+		"c1.foo/A": {Name: "c1.foo", Type: "A", Values: []string{"10.0.0.10"}},
+	}, records)
+}
+
+func TestSync(t *testing.T) {
+	published := map[string]externaldns.Record{
+		"c1/A": {Name: "c1", Type: "A", Values: []string{"10.0.0.10"}},
+		"c2/A": {Name: "c2", Type: "A", Values: []string{"10.0.0.20"}},
+		"c3/A": {Name: "c3", Type: "A", Values: []string{"10.0.0.30"}},
+	}
+
+	desired := map[string]externaldns.Record{
+		"c1/A": {Name: "c1", Type: "A", Values: []string{"10.0.0.10"}},
+		"c2/A": {Name: "c2", Type: "A", Values: []string{"10.0.0.21"}},
+		"c4/A": {Name: "c4", Type: "A", Values: []string{"10.0.0.40"}},
+	}
+
+	provider := &fakeProvider{fail: "c4"}
+	result, err := externaldns.Sync(context.Background(), provider, published, desired)
+	assert.Error(t, err)
+
+	// Unchanged records aren't sent again and failed ones aren't considered published.
+	assert.Equal(t, []string{"c2/A"}, provider.set)
+	assert.Equal(t, []string{"c3/A"}, provider.deleted)
+	assert.Equal(t, map[string]externaldns.Record{
+		"c1/A": desired["c1/A"],
+		"c2/A": desired["c2/A"],
+	}, result)
+}
+
+func TestWebhook(t *testing.T) {
+	var requests []map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		req := map[string]any{}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		assert.NoError(t, err)
+
+		requests = append(requests, req)
+	}))
+
+	defer server.Close()
+
+	provider, err := externaldns.Load(map[string]string{
+		"dns.external.provider":      "webhook",
+		"dns.external.zone":          "lxd.example.net",
+		"dns.external.webhook.url":   server.URL,
+		"dns.external.webhook.token": "secret",
+	})
+	require.NoError(t, err)
+
+	err = provider.Set(context.Background(), externaldns.Record{Name: "c1", Type: "A", Values: []string{"10.0.0.10"}})
+	require.NoError(t, err)
+
+	err = provider.Delete(context.Background(), "c1", "A")
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, "upsert", requests[0]["action"])
+	assert.Equal(t, "lxd.example.net", requests[0]["zone"])
+	assert.Equal(t, []any{"10.0.0.10"}, requests[0]["values"])
+	assert.Equal(t, "delete", requests[1]["action"])
+}
+
+func TestValidateConfig(t *testing.T) {
+	assert.NoError(t, externaldns.ValidateConfig(map[string]string{}))
+	assert.Error(t, externaldns.ValidateConfig(map[string]string{"dns.external.provider": "webhook"}))
+	assert.Error(t, externaldns.ValidateConfig(map[string]string{"dns.external.provider": "rfc2136", "dns.external.zone": "lxd.example.net"}))
+	assert.Error(t, externaldns.ValidateConfig(map[string]string{"dns.external.provider": "rfc2136", "dns.external.zone": "lxd.example.net", "dns.external.rfc2136.nameserver": "192.0.2.53", "dns.external.rfc2136.tsig.key": "lxd"}))
+	assert.NoError(t, externaldns.ValidateConfig(map[string]string{"dns.external.provider": "rfc2136", "dns.external.zone": "lxd.example.net", "dns.external.rfc2136.nameserver": "192.0.2.53"}))
+}
@@ -0,0 +1,89 @@
+package externaldns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// rfc2136 publishes records using dynamic DNS updates.
+type rfc2136 struct {
+	zone          string
+	ttl           uint32
+	nameserver    string
+	tsigKey       string
+	tsigSecret    string
+	tsigAlgorithm string
+}
+
+// Set replaces the record set in a single update so that it is never seen half updated.
+func (p *rfc2136) Set(ctx context.Context, record Record) error {
+	fqdn := dns.Fqdn(record.Name + "." + p.zone)
+
+	rrs := make([]dns.RR, 0, len(record.Values))
+	for _, value := range record.Values {
+		rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", fqdn, p.ttl, record.Type, value))
+		if err != nil {
+			return fmt.Errorf("Invalid record value %q: %w", value, err)
+		}
+
+		rrs = append(rrs, rr)
+	}
+
+	msg := p.newUpdate(fqdn, record.Type)
+	msg.Insert(rrs)
+
+	return p.exchange(ctx, msg)
+}
+
+// Delete removes the record set.
+func (p *rfc2136) Delete(ctx context.Context, name string, recordType string) error {
+	msg := p.newUpdate(dns.Fqdn(name+"."+p.zone), recordType)
+
+	return p.exchange(ctx, msg)
+}
+
+// newUpdate returns an update message removing the record set with the given name and type.
+func (p *rfc2136) newUpdate(fqdn string, recordType string) *dns.Msg {
+	msg := &dns.Msg{}
+	msg.SetUpdate(dns.Fqdn(p.zone))
+	msg.RemoveRRset([]dns.RR{&dns.ANY{Hdr: dns.RR_Header{Name: fqdn, Rrtype: dns.StringToType[recordType]}}})
+
+	return msg
+}
+
+// exchange sends the update to the name server and checks its answer.
+func (p *rfc2136) exchange(ctx context.Context, msg *dns.Msg) error {
+	nameserver := p.nameserver
+	_, _, err := net.SplitHostPort(nameserver)
+	if err != nil {
+		nameserver = net.JoinHostPort(nameserver, "53")
+	}
+
+	client := &dns.Client{Timeout: 10 * time.Second}
+
+	if p.tsigKey != "" {
+		algorithm := dns.HmacSHA256
+		if p.tsigAlgorithm != "" {
+			algorithm = dns.Fqdn(p.tsigAlgorithm)
+		}
+
+		keyName := dns.Fqdn(p.tsigKey)
+		client.TsigSecret = map[string]string{keyName: p.tsigSecret}
+		msg.SetTsig(keyName, algorithm, 300, time.Now().Unix())
+	}
+
+	reply, _, err := client.ExchangeContext(ctx, msg, nameserver)
+	if err != nil {
+		return fmt.Errorf("Failed sending DNS update to %q: %w", nameserver, err)
+	}
+
+	if reply.Rcode != dns.RcodeSuccess {
+		return fmt.Errorf("DNS update refused by %q: %s", nameserver, dns.RcodeToString[reply.Rcode])
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package externaldns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookRequest is the body sent to the webhook for each change.
+type webhookRequest struct {
+	Action string   `json:"action"`
+	Zone   string   `json:"zone"`
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	TTL    uint32   `json:"ttl,omitempty"`
+	Values []string `json:"values,omitempty"`
+}
+
+// webhook publishes records by sending them to an HTTP endpoint, which is expected to apply them to the DNS
+// service it fronts (for example a cloud DNS API).
+type webhook struct {
+	zone  string
+	ttl   uint32
+	url   string
+	token string
+}
+
+// Set asks the webhook to create or replace the record set.
+func (p *webhook) Set(ctx context.Context, record Record) error {
+	return p.send(ctx, webhookRequest{
+		Action: "upsert",
+		Zone:   p.zone,
+		Name:   record.Name,
+		Type:   record.Type,
+		TTL:    p.ttl,
+		Values: record.Values,
+	})
+}
+
+// Delete asks the webhook to remove the record set.
+func (p *webhook) Delete(ctx context.Context, name string, recordType string) error {
+	return p.send(ctx, webhookRequest{
+		Action: "delete",
+		Zone:   p.zone,
+		Name:   name,
+		Type:   recordType,
+	})
+}
+
+// send posts the request to the webhook and checks its answer.
+func (p *webhook) send(ctx context.Context, req webhookRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("Failed sending request to webhook: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhook returned unexpected status %q", resp.Status)
+	}
+
+	return nil
+}
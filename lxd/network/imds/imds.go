@@ -0,0 +1,302 @@
+// Package imds serves the metadata of instances over HTTP in the format of the EC2 instance metadata service.
+package imds
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// Address is the link-local address the metadata service listens on.
+const Address = "169.254.169.254"
+
+// TokenHeader is the header holding session tokens.
+const TokenHeader = "X-aws-ec2-metadata-token"
+
+// TokenTTLHeader is the header holding the lifetime in seconds of requested session tokens.
+const TokenTTLHeader = "X-aws-ec2-metadata-token-ttl-seconds"
+
+// tokenMaxTTL is the maximum lifetime of session tokens.
+const tokenMaxTTL = 6 * time.Hour
+
+// versionRegexp matches the dated versions of the metadata API, which are all served like "latest".
+var versionRegexp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// Instance is the metadata of an instance.
+type Instance struct {
+	// ID is the cloud-init instance ID.
+	ID       string
+	Name     string
+	Location string
+	MAC      string
+	Address  string
+	UserData string
+
+	// Tags are exposed under meta-data/tags/instance.
+	Tags map[string]string
+
+	// SSHKeys maps the names of public keys to their OpenSSH representation.
+	SSHKeys map[string]string
+}
+
+// Lookup returns the instance using the given address. It returns a not found status error if there's none.
+type Lookup func(ctx context.Context, address net.IP) (*Instance, error)
+
+// token is a session token bound to the address it was issued to.
+type token struct {
+	address   string
+	expiresAt time.Time
+}
+
+// Server serves the metadata of the instance making each request.
+type Server struct {
+	lookup Lookup
+
+	tokens   map[string]token
+	tokensMu sync.Mutex
+}
+
+// NewServer returns a metadata server using the given function to find the instance making requests.
+func NewServer(lookup Lookup) *Server {
+	return &Server{
+		lookup: lookup,
+		tokens: map[string]token{},
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	address := net.ParseIP(host)
+	if address == nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Path == "/latest/api/token" {
+		s.serveToken(w, r, address)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Session tokens are optional, but must be valid when given.
+	value := r.Header.Get(TokenHeader)
+	if value != "" && !s.checkToken(value, address) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if r.URL.Path == "/" {
+		_, _ = fmt.Fprint(w, "2009-04-04\nlatest")
+		return
+	}
+
+	version, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if version != "latest" && !versionRegexp.MatchString(version) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	inst, err := s.lookup(r.Context(), address)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+
+		logger.Warn("Failed finding instance making metadata request", logger.Ctx{"address": address.String(), "err": err})
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	content, ok := inst.Get(path)
+	if !ok {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = fmt.Fprint(w, content)
+}
+
+// serveToken issues session tokens.
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request, address net.IP) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Refuse requests relayed by proxies, so that they can't be used to get tokens on behalf of the instance.
+	if r.Header.Get("X-Forwarded-For") != "" {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	ttl, err := strconv.Atoi(r.Header.Get(TokenTTLHeader))
+	if err != nil || ttl <= 0 || time.Duration(ttl)*time.Second > tokenMaxTTL {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	buf := make([]byte, 32)
+	_, err = rand.Read(buf)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	value := hex.EncodeToString(buf)
+	now := time.Now()
+
+	s.tokensMu.Lock()
+	for k, t := range s.tokens {
+		if !t.expiresAt.After(now) {
+			delete(s.tokens, k)
+		}
+	}
+
+	s.tokens[value] = token{address: address.String(), expiresAt: now.Add(time.Duration(ttl) * time.Second)}
+	s.tokensMu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set(TokenTTLHeader, strconv.Itoa(ttl))
+	_, _ = fmt.Fprint(w, value)
+}
+
+// checkToken returns whether the given session token is valid for the given address.
+func (s *Server) checkToken(value string, address net.IP) bool {
+	s.tokensMu.Lock()
+	defer s.tokensMu.Unlock()
+
+	t, ok := s.tokens[value]
+	if !ok {
+		return false
+	}
+
+	return t.address == address.String() && t.expiresAt.After(time.Now())
+}
+
+// Get returns the content at the given path of the metadata tree, relative to the version. Directories list their
+// entries, with a trailing slash for subdirectories.
+func (i *Instance) Get(path string) (string, bool) {
+	if path == "user-data" {
+		return i.UserData, i.UserData != ""
+	}
+
+	if path == "" {
+		entries := []string{"meta-data/"}
+		if i.UserData != "" {
+			entries = append(entries, "user-data")
+		}
+
+		return strings.Join(entries, "\n"), true
+	}
+
+	path, ok := strings.CutPrefix(strings.TrimSuffix(path, "/"), "meta-data")
+	if !ok {
+		return "", false
+	}
+
+	path = strings.TrimPrefix(path, "/")
+
+	// Public keys are listed as "<index>=<name>".
+	keyNames := make([]string, 0, len(i.SSHKeys))
+	for name := range i.SSHKeys {
+		keyNames = append(keyNames, name)
+	}
+
+	sort.Strings(keyNames)
+
+	if path == "public-keys" {
+		entries := make([]string, 0, len(keyNames))
+		for index, name := range keyNames {
+			entries = append(entries, fmt.Sprintf("%d=%s", index, name))
+		}
+
+		return strings.Join(entries, "\n"), len(entries) > 0
+	}
+
+	files := map[string]string{
+		"hostname":                    i.Name,
+		"instance-id":                 i.ID,
+		"local-hostname":              i.Name,
+		"local-ipv4":                  i.Address,
+		"mac":                         i.MAC,
+		"placement/availability-zone": i.Location,
+	}
+
+	for index, name := range keyNames {
+		files[fmt.Sprintf("public-keys/%d/openssh-key", index)] = i.SSHKeys[name]
+	}
+
+	for k, v := range i.Tags {
+		files["tags/instance/"+k] = v
+	}
+
+	content, ok := files[path]
+	if ok {
+		return content, true
+	}
+
+	// List the directory.
+	prefix := path + "/"
+	if path == "" {
+		prefix = ""
+	}
+
+	entries := map[string]bool{}
+	for k := range files {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			continue
+		}
+
+		name, _, isDir := strings.Cut(rest, "/")
+		if isDir {
+			name += "/"
+		}
+
+		entries[name] = true
+	}
+
+	// Always list tags, even when there are none.
+	if path == "tags" {
+		entries["instance/"] = true
+	} else if path == "" {
+		entries["tags/"] = true
+	}
+
+	if len(entries) == 0 && path != "tags/instance" {
+		return "", false
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, "\n"), true
+}
@@ -0,0 +1,115 @@
+package imds_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/network/imds"
+	"github.com/canonical/lxd/shared/api"
+)
+
+func newServer() *imds.Server {
+	return imds.NewServer(func(ctx context.Context, address net.IP) (*imds.Instance, error) {
+		if address.String() != "10.0.0.2" {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Instance not found")
+		}
+
+		return &imds.Instance{
+			ID:       "c1-id",
+			Name:     "c1",
+			Location: "lxd01",
+			MAC:      "00:16:3e:00:00:01",
+			Address:  "10.0.0.2",
+			UserData: "#cloud-config\n",
+			Tags:     map[string]string{"role": "web"},
+			SSHKeys:  map[string]string{"bob": "ssh-ed25519 BBB", "alice": "ssh-ed25519 AAA"},
+		}, nil
+	})
+}
+
+func request(t *testing.T, server *imds.Server, method string, path string, remoteAddr string, headers map[string]string) (int, string) {
+	r := httptest.NewRequest(method, path, nil)
+	r.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+
+	body, err := io.ReadAll(w.Result().Body)
+	require.NoError(t, err)
+
+	return w.Code, string(body)
+}
+
+func TestServer(t *testing.T) {
+	server := newServer()
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/", http.StatusOK, "2009-04-04\nlatest"},
+		{"/latest/", http.StatusOK, "meta-data/\nuser-data"},
+		{"/latest/meta-data/", http.StatusOK, "hostname\ninstance-id\nlocal-hostname\nlocal-ipv4\nmac\nplacement/\npublic-keys/\ntags/"},
+		{"/2009-04-04/meta-data/instance-id", http.StatusOK, "c1-id"},
+		{"/latest/meta-data/local-ipv4", http.StatusOK, "10.0.0.2"},
+		{"/latest/meta-data/placement/availability-zone", http.StatusOK, "lxd01"},
+		{"/latest/meta-data/public-keys/", http.StatusOK, "0=alice\n1=bob"},
+		{"/latest/meta-data/public-keys/1/", http.StatusOK, "openssh-key"},
+		{"/latest/meta-data/public-keys/1/openssh-key", http.StatusOK, "ssh-ed25519 BBB"},
+		{"/latest/meta-data/tags/instance/", http.StatusOK, "role"},
+		{"/latest/meta-data/tags/instance/role", http.StatusOK, "web"},
+		{"/latest/user-data", http.StatusOK, "#cloud-config\n"},
+		{"/latest/meta-data/missing", http.StatusNotFound, "Not Found\n"},
+		{"/other/meta-data/", http.StatusNotFound, "Not Found\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			code, body := request(t, server, http.MethodGet, test.path, "10.0.0.2:12345", nil)
+			assert.Equal(t, test.code, code)
+			assert.Equal(t, test.body, body)
+		})
+	}
+}
+
+func TestServer_UnknownInstance(t *testing.T) {
+	code, _ := request(t, newServer(), http.MethodGet, "/latest/meta-data/instance-id", "10.0.0.3:12345", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestServer_Tokens(t *testing.T) {
+	server := newServer()
+
+	// Tokens require a lifetime.
+	code, _ := request(t, server, http.MethodPut, "/latest/api/token", "10.0.0.2:12345", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// Tokens can't be requested through proxies.
+	code, _ = request(t, server, http.MethodPut, "/latest/api/token", "10.0.0.2:12345", map[string]string{imds.TokenTTLHeader: "60", "X-Forwarded-For": "10.0.0.9"})
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, token := request(t, server, http.MethodPut, "/latest/api/token", "10.0.0.2:12345", map[string]string{imds.TokenTTLHeader: "60"})
+	require.Equal(t, http.StatusOK, code)
+
+	code, body := request(t, server, http.MethodGet, "/latest/meta-data/hostname", "10.0.0.2:12345", map[string]string{imds.TokenHeader: token})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "c1", body)
+
+	// Tokens are bound to the address they were issued to.
+	code, _ = request(t, server, http.MethodGet, "/latest/meta-data/hostname", "10.0.0.3:12345", map[string]string{imds.TokenHeader: token})
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = request(t, server, http.MethodGet, "/latest/meta-data/hostname", "10.0.0.2:12345", map[string]string{imds.TokenHeader: "invalid"})
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
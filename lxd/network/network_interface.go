@@ -57,6 +57,7 @@ type Network interface {
 	// Status.
 	State() (*api.NetworkState, error)
 	Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error)
+	MTUCheck() (*api.NetworkMTU, error)
 
 	// Address Forwards.
 	ForwardCreate(forward api.NetworkForwardsPost, clientType request.ClientType) (net.IP, error)
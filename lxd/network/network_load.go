@@ -68,6 +68,36 @@ func LoadByName(s *state.State, projectName string, name string) (Network, error
 	return n, nil
 }
 
+// LoadByNameCached loads an instantiated network from the database by project and name, like LoadByName, but
+// reads the network from the entity cache of the cluster database when possible.
+// It must only be used when the network is read and not changed, for example to validate instance devices.
+func LoadByNameCached(s *state.State, projectName string, name string) (Network, error) {
+	var id int64
+	var netInfo *api.Network
+	var netNodes map[int64]db.NetworkNode
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		id, netInfo, netNodes, err = tx.GetCachedNetwork(ctx, projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	driverFunc, ok := drivers[netInfo.Type]
+	if !ok {
+		return nil, ErrUnknownDriver
+	}
+
+	n := driverFunc()
+	n.init(s, id, projectName, netInfo, netNodes)
+
+	return n, nil
+}
+
 // PatchPreCheck checks if there are any unavailable networks.
 func PatchPreCheck() error {
 	unavailableNetworksMu.Lock()
@@ -25,6 +25,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/lxd/network/externaldns"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/util"
@@ -392,6 +393,47 @@ func DefaultGatewaySubnetV4() (*net.IPNet, string, error) {
 	return subnet, ifaceName, nil
 }
 
+// DefaultGatewayInterface returns the name of the interface of the IPv4 default gateway, or of the IPv6 default
+// gateway on hosts without IPv4 default gateway.
+func DefaultGatewayInterface() (string, error) {
+	_, ifaceName, err := DefaultGatewaySubnetV4()
+	if err == nil {
+		return ifaceName, nil
+	}
+
+	ifaceName, errV6 := defaultGatewayInterfaceV6()
+	if errV6 != nil {
+		return "", fmt.Errorf("%w, %w", err, errV6)
+	}
+
+	return ifaceName, nil
+}
+
+// defaultGatewayInterfaceV6 returns the name of the interface of the IPv6 default gateway.
+func defaultGatewayInterfaceV6() (string, error) {
+	file, err := os.Open("/proc/net/ipv6_route")
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+
+		// Skip the unreachable default routes which the kernel adds on the loopback interface.
+		if fields[0] == strings.Repeat("0", 32) && fields[1] == "00" && fields[9] != "lo" {
+			return fields[9], nil
+		}
+	}
+
+	return "", fmt.Errorf("No default gateway for IPv6")
+}
+
 // UpdateDNSMasqStatic rebuilds the DNSMasq static allocations.
 func UpdateDNSMasqStatic(s *state.State, networkName string) error {
 	// We don't want to race with ourselves here.
@@ -1365,6 +1407,32 @@ func NICUsesNetwork(nicDev map[string]string, networks ...*api.Network) bool {
 	return false
 }
 
+// ExternalDNSRemoveInstance removes the records of an instance from the external DNS provider of the network, if
+// one is configured.
+func ExternalDNSRemoveInstance(n Network, instanceProjectName string, instanceName string) error {
+	provider, err := externaldns.Load(n.Config())
+	if err != nil {
+		return err
+	}
+
+	if provider == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	name := externaldns.InstanceRecordName(n.Project(), instanceProjectName, instanceName)
+	for _, recordType := range []string{"A", "AAAA"} {
+		err = provider.Delete(ctx, name, recordType)
+		if err != nil {
+			return fmt.Errorf("Failed removing %s record %q: %w", recordType, name, err)
+		}
+	}
+
+	return nil
+}
+
 // BridgeNetfilterEnabled checks whether the bridge netfilter feature is loaded and enabled.
 // If it is not an error is returned. This is needed in order for instances connected to a bridge to access DNAT
 // listeners on the LXD host, as otherwise the packets from the bridge do have the SNAT netfilter rules applied.
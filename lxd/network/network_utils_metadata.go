@@ -0,0 +1,132 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/network/imds"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// metadataServers holds the running instance metadata servers by bridge name.
+var metadataServers = map[string]*http.Server{}
+var metadataServersMu sync.Mutex
+
+// metadataStart starts serving instance metadata on the link-local metadata address of the given bridge, using the
+// given function to find the instance making each request. Does nothing if the bridge is already served.
+func metadataStart(bridgeName string, lookup imds.Lookup) error {
+	metadataServersMu.Lock()
+	defer metadataServersMu.Unlock()
+
+	_, ok := metadataServers[bridgeName]
+	if ok {
+		return nil
+	}
+
+	// Bind to the bridge so that the same address can be served on multiple bridges.
+	lc := net.ListenConfig{
+		Control: func(network string, address string, c syscall.RawConn) error {
+			var sockErr error
+			err := c.Control(func(fd uintptr) {
+				sockErr = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE, bridgeName)
+			})
+			if err != nil {
+				return err
+			}
+
+			return sockErr
+		},
+	}
+
+	listener, err := lc.Listen(context.Background(), "tcp4", net.JoinHostPort(imds.Address, "80"))
+	if err != nil {
+		return fmt.Errorf("Failed listening on metadata address of %q: %w", bridgeName, err)
+	}
+
+	server := &http.Server{
+		Handler:           imds.NewServer(lookup),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	metadataServers[bridgeName] = server
+
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			logger.Warn("Instance metadata server stopped", logger.Ctx{"network": bridgeName, "err": err})
+		}
+	}()
+
+	return nil
+}
+
+// metadataStop stops serving instance metadata on the given bridge.
+func metadataStop(bridgeName string) error {
+	metadataServersMu.Lock()
+	defer metadataServersMu.Unlock()
+
+	server, ok := metadataServers[bridgeName]
+	if !ok {
+		return nil
+	}
+
+	delete(metadataServers, bridgeName)
+
+	return server.Close()
+}
+
+// metadataInstance returns the metadata of the given instance, whose config must be expanded, when using the given
+// MAC and IP addresses.
+func metadataInstance(inst db.InstanceArgs, mac net.HardwareAddr, address net.IP) *imds.Instance {
+	// Legacy cloud-init keys aren't exposed as tags.
+	cloudInitKeys := []string{"user.meta-data", "user.network-config", "user.user-data", "user.vendor-data"}
+
+	metadata := &imds.Instance{
+		ID:       inst.Config["volatile.cloud-init.instance-id"],
+		Name:     inst.Name,
+		Location: inst.Node,
+		MAC:      mac.String(),
+		Address:  address.String(),
+		UserData: inst.Config["cloud-init.user-data"],
+		Tags:     map[string]string{},
+		SSHKeys:  map[string]string{},
+	}
+
+	if metadata.ID == "" {
+		metadata.ID = inst.Name
+	}
+
+	if metadata.UserData == "" {
+		metadata.UserData = inst.Config["user.user-data"]
+	}
+
+	for k, v := range inst.Config {
+		name, ok := strings.CutPrefix(k, "user.")
+		if ok {
+			if !shared.ValueInSlice(k, cloudInitKeys) {
+				metadata.Tags[name] = v
+			}
+
+			continue
+		}
+
+		// SSH keys are set as "<user>:<public key>".
+		name, ok = strings.CutPrefix(k, "cloud-init.ssh-keys.")
+		if ok {
+			_, key, _ := strings.Cut(v, ":")
+			metadata.SSHKeys[name] = key
+		}
+	}
+
+	return metadata
+}
@@ -0,0 +1,217 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Overhead in bytes of the supported encapsulations when carried over IPv4.
+// The inner Ethernet header (14 bytes) is included for the layer 2 encapsulations.
+const (
+	mtuOverheadIPIP   = 20 // Outer IPv4 header.
+	mtuOverheadGRE    = 38 // Outer IPv4 header, GRE header and inner Ethernet header.
+	mtuOverheadVXLAN  = 50 // Outer IPv4 header, UDP header, VXLAN header and inner Ethernet header.
+	mtuOverheadGeneve = 58 // Outer IPv4 header, UDP header, Geneve header with options and inner Ethernet header.
+)
+
+// mtuOverheadIPv6 is the additional overhead of an outer IPv6 header compared to an IPv4 one.
+const mtuOverheadIPv6 = 20
+
+// mtuUnderlay describes the underlay network interface used to carry encapsulated traffic.
+type mtuUnderlay struct {
+	encapsulation string
+	ipv6          bool
+	iface         string
+	mtu           uint32
+}
+
+// overhead returns the number of bytes added by the encapsulation.
+func (u mtuUnderlay) overhead() uint32 {
+	var overhead uint32
+
+	switch u.encapsulation {
+	case "ipip":
+		overhead = mtuOverheadIPIP
+	case "gre":
+		overhead = mtuOverheadGRE
+	case "vxlan":
+		overhead = mtuOverheadVXLAN
+	case "geneve":
+		overhead = mtuOverheadGeneve
+	}
+
+	if u.ipv6 {
+		overhead += mtuOverheadIPv6
+	}
+
+	return overhead
+}
+
+// overlayMTU returns the largest MTU that the underlay can carry once the encapsulation overhead is deducted.
+func (u mtuUnderlay) overlayMTU() uint32 {
+	if u.mtu <= u.overhead() {
+		return 0
+	}
+
+	return u.mtu - u.overhead()
+}
+
+// overlayMTUFromUnderlays returns the MTU that fits all of the underlays, capped to the default MTU.
+// The fallback MTU is returned if none of the underlays is known.
+func overlayMTUFromUnderlays(underlays []mtuUnderlay, fallback uint32) uint32 {
+	mtu := uint32(bridgeMTUDefault)
+	found := false
+
+	for _, underlay := range underlays {
+		underlayMTU := underlay.overlayMTU()
+		if underlayMTU == 0 {
+			continue
+		}
+
+		found = true
+		if underlayMTU < mtu {
+			mtu = underlayMTU
+		}
+	}
+
+	if !found {
+		return fallback
+	}
+
+	return mtu
+}
+
+// newMTUCheck returns the MTU check of a network using the given MTU, flagging the underlays that can't carry it.
+func newMTUCheck(mtu uint32, underlays []mtuUnderlay) *api.NetworkMTU {
+	check := &api.NetworkMTU{
+		MTU:        int(mtu),
+		Underlays:  make([]api.NetworkMTUUnderlay, 0, len(underlays)),
+		Mismatches: []api.NetworkMTUMismatch{},
+	}
+
+	for _, underlay := range underlays {
+		check.Underlays = append(check.Underlays, api.NetworkMTUUnderlay{
+			Interface:     underlay.iface,
+			MTU:           int(underlay.mtu),
+			Encapsulation: underlay.encapsulation,
+			Overhead:      int(underlay.overhead()),
+		})
+
+		overlayMTU := underlay.overlayMTU()
+		if check.ExpectedMTU == 0 || int(overlayMTU) < check.ExpectedMTU {
+			check.ExpectedMTU = int(overlayMTU)
+		}
+
+		if overlayMTU < mtu {
+			check.Mismatches = append(check.Mismatches, api.NetworkMTUMismatch{
+				Interface:   underlay.iface,
+				MTU:         int(underlay.mtu),
+				ExpectedMTU: int(mtu + underlay.overhead()),
+				Description: fmt.Sprintf("Underlay MTU is too small to carry the network MTU with %s encapsulation", underlay.encapsulation),
+			})
+		}
+	}
+
+	return check
+}
+
+// underlayInterfaceFromIP searches all interfaces on the host looking for one that has the specified IP.
+// It returns the name and MTU of the interface.
+func underlayInterfaceFromIP(findIP net.IP) (string, uint32, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", 0, fmt.Errorf("Failed getting local network interfaces: %w", err)
+	}
+
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			ip, _, err := net.ParseCIDR(addr.String())
+			if err != nil {
+				continue
+			}
+
+			if ip.Equal(findIP) {
+				underlayMTU, err := GetDevMTU(iface.Name)
+				if err != nil {
+					return "", 0, fmt.Errorf("Failed getting MTU for %q: %w", iface.Name, err)
+				}
+
+				return iface.Name, underlayMTU, nil // Found what we were looking for.
+			}
+		}
+	}
+
+	return "", 0, fmt.Errorf("No matching interface found for IP %q", findIP.String())
+}
+
+// underlayInterface returns the name and MTU of the interface carrying the encapsulated traffic.
+// The interface is found from the local address if set, otherwise from the interface name if set, otherwise the
+// interface of the default gateway is used.
+func underlayInterface(localAddress string, ifaceName string) (string, uint32, error) {
+	if localAddress != "" {
+		localIP := net.ParseIP(localAddress)
+		if localIP == nil {
+			return "", 0, fmt.Errorf("Invalid local address %q", localAddress)
+		}
+
+		return underlayInterfaceFromIP(localIP)
+	}
+
+	if ifaceName == "" {
+		var err error
+
+		ifaceName, err = DefaultGatewayInterface()
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	mtu, err := GetDevMTU(ifaceName)
+	if err != nil {
+		return "", 0, fmt.Errorf("Failed getting MTU for %q: %w", ifaceName, err)
+	}
+
+	return ifaceName, mtu, nil
+}
+
+// bridgePortMTUs returns the MTU of each interface connected to the bridge, keyed by interface name.
+func bridgePortMTUs(bridgeName string) (map[string]uint32, error) {
+	entries, err := os.ReadDir(filepath.Join("/sys/class/net", bridgeName, "brif"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing ports of bridge %q: %w", bridgeName, err)
+	}
+
+	ports := make(map[string]uint32, len(entries))
+	for _, entry := range entries {
+		mtu, err := GetDevMTU(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		ports[entry.Name()] = mtu
+	}
+
+	return ports, nil
+}
+
+// sortedPortNames returns the names of the ports sorted alphabetically.
+func sortedPortNames(ports map[string]uint32) []string {
+	names := make([]string, 0, len(ports))
+	for name := range ports {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -167,3 +167,50 @@ func Test_randomAddressInSubnet(t *testing.T) {
 		})
 	}
 }
+
+func Test_overlayMTUFromUnderlays(t *testing.T) {
+	tests := []struct {
+		name      string
+		underlays []mtuUnderlay
+		fallback  uint32
+		want      uint32
+	}{
+		{
+			name:     "no underlay",
+			fallback: 1400,
+			want:     1400,
+		},
+		{
+			name:      "geneve over IPv4",
+			underlays: []mtuUnderlay{{encapsulation: "geneve", mtu: 1500}},
+			want:      1442,
+		},
+		{
+			name:      "geneve over IPv6",
+			underlays: []mtuUnderlay{{encapsulation: "geneve", ipv6: true, mtu: 1500}},
+			want:      1422,
+		},
+		{
+			name:      "jumbo frames are capped",
+			underlays: []mtuUnderlay{{encapsulation: "vxlan", mtu: 9000}},
+			want:      1500,
+		},
+		{
+			name:      "smallest underlay wins",
+			underlays: []mtuUnderlay{{encapsulation: "gre", mtu: 1500}, {encapsulation: "vxlan", mtu: 1450}},
+			want:      1400,
+		},
+		{
+			name:      "underlay too small",
+			underlays: []mtuUnderlay{{encapsulation: "vxlan", mtu: 40}},
+			fallback:  1450,
+			want:      1450,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, overlayMTUFromUnderlays(tt.underlays, tt.fallback))
+		})
+	}
+}
@@ -260,12 +260,13 @@ func (o *OVN) SetNorthboundDBAddress(addr string) {
 }
 
 // getNorthboundDB returns connection string to use for northbound database.
+// The endpoints known to be healthy are put first so that the unreachable ones are only tried last.
 func (o *OVN) getNorthboundDB() string {
 	if o.nbDBAddr == "" {
 		return "unix:/var/run/ovn/ovnnb_db.sock"
 	}
 
-	return o.nbDBAddr
+	return ovnOrderEndpoints(OVNNorthbound, o.nbDBAddr)
 }
 
 // SetSouthboundDBAddress sets the address that runs the OVN northbound databases.
@@ -273,13 +274,14 @@ func (o *OVN) SetSouthboundDBAddress(addr string) {
 	o.sbDBAddr = addr
 }
 
-// getSouthboundDB returns connection string to use for northbound database.
+// getSouthboundDB returns connection string to use for southbound database.
+// The endpoints known to be healthy are put first so that the unreachable ones are only tried last.
 func (o *OVN) getSouthboundDB() string {
 	if o.sbDBAddr == "" {
 		return "unix:/var/run/ovn/ovnsb_db.sock"
 	}
 
-	return o.sbDBAddr
+	return ovnOrderEndpoints(OVNSouthbound, o.sbDBAddr)
 }
 
 // sbctl executes ovn-sbctl with arguments to connect to wrapper's southbound database.
@@ -295,9 +297,18 @@ func (o *OVN) nbctl(args ...string) (string, error) {
 // xbctl optionally executes either ovn-nbctl or ovn-sbctl with arguments to connect to wrapper's northbound or southbound database.
 func (o *OVN) xbctl(southbound bool, extraArgs ...string) (string, error) {
 	dbAddr := o.getNorthboundDB()
-	cmd := "ovn-nbctl"
 	if southbound {
 		dbAddr = o.getSouthboundDB()
+	}
+
+	return o.xbctlDB(context.Background(), southbound, dbAddr, 10, extraArgs...)
+}
+
+// xbctlDB executes either ovn-nbctl or ovn-sbctl with arguments to connect to the given database address, giving
+// up after the timeout in seconds.
+func (o *OVN) xbctlDB(ctx context.Context, southbound bool, dbAddr string, timeout int, extraArgs ...string) (string, error) {
+	cmd := "ovn-nbctl"
+	if southbound {
 		cmd = "ovn-sbctl"
 	}
 
@@ -306,7 +317,7 @@ func (o *OVN) xbctl(southbound bool, extraArgs ...string) (string, error) {
 	}
 
 	// Figure out args.
-	args := []string{"--timeout=10", "--db", dbAddr}
+	args := []string{fmt.Sprintf("--timeout=%d", timeout), "--db", dbAddr}
 
 	// Handle SSL args.
 	files := []*os.File{}
@@ -346,7 +357,7 @@ func (o *OVN) xbctl(southbound bool, extraArgs ...string) (string, error) {
 	}
 
 	args = append(args, extraArgs...)
-	return shared.RunCommandInheritFds(context.Background(), files, cmd, args...)
+	return shared.RunCommandInheritFds(ctx, files, cmd, args...)
 }
 
 // LogicalRouterAdd adds a named logical router.
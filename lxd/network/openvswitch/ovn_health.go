@@ -0,0 +1,149 @@
+package openvswitch
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OVNDatabase identifies an OVN database.
+type OVNDatabase string
+
+// OVNNorthbound is the OVN northbound database.
+const OVNNorthbound = OVNDatabase("northbound")
+
+// OVNSouthbound is the OVN southbound database.
+const OVNSouthbound = OVNDatabase("southbound")
+
+// ovnHealthCheckTimeout is the timeout in seconds of the health check of a database endpoint.
+const ovnHealthCheckTimeout = 5
+
+// OVNEndpointHealth represents the health of an endpoint of an OVN database.
+type OVNEndpointHealth struct {
+	Database     OVNDatabase
+	Endpoint     string
+	Healthy      bool
+	ResponseTime time.Duration
+	LastChecked  time.Time
+	Err          error
+}
+
+// ovnHealth contains the result of the last health check of each endpoint, keyed by database and endpoint.
+var ovnHealth = map[OVNDatabase]map[string]OVNEndpointHealth{}
+var ovnHealthMu sync.Mutex
+
+// ovnEndpoints splits a database connection string into its endpoints.
+func ovnEndpoints(connection string) []string {
+	endpoints := []string{}
+	for _, endpoint := range strings.Split(connection, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	return endpoints
+}
+
+// ovnOrderEndpoints reorders the endpoints of a database connection string so that the healthy endpoints come
+// first and the unhealthy ones last. Endpoints that haven't been checked yet keep their place in between.
+func ovnOrderEndpoints(database OVNDatabase, connection string) string {
+	endpoints := ovnEndpoints(connection)
+	if len(endpoints) < 2 {
+		return connection
+	}
+
+	ovnHealthMu.Lock()
+	health := ovnHealth[database]
+	rank := func(endpoint string) int {
+		result, ok := health[endpoint]
+		if !ok {
+			return 1
+		}
+
+		if result.Healthy {
+			return 0
+		}
+
+		return 2
+	}
+
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return rank(endpoints[i]) < rank(endpoints[j])
+	})
+	ovnHealthMu.Unlock()
+
+	return strings.Join(endpoints, ",")
+}
+
+// CheckHealth checks each endpoint of the northbound and southbound databases individually and records the
+// results, which are used to prefer the healthy endpoints when connecting to the databases.
+// Endpoints that are no longer part of the connection strings are forgotten, so that changes in the membership of
+// the OVN central cluster are picked up.
+func (o *OVN) CheckHealth(ctx context.Context) []OVNEndpointHealth {
+	results := []OVNEndpointHealth{}
+
+	databases := map[OVNDatabase]string{
+		OVNNorthbound: o.nbDBAddr,
+		OVNSouthbound: o.sbDBAddr,
+	}
+
+	if o.nbDBAddr == "" {
+		databases[OVNNorthbound] = "unix:/var/run/ovn/ovnnb_db.sock"
+	}
+
+	if o.sbDBAddr == "" {
+		databases[OVNSouthbound] = "unix:/var/run/ovn/ovnsb_db.sock"
+	}
+
+	for _, database := range []OVNDatabase{OVNNorthbound, OVNSouthbound} {
+		checked := map[string]OVNEndpointHealth{}
+
+		for _, endpoint := range ovnEndpoints(databases[database]) {
+			result := OVNEndpointHealth{
+				Database:    database,
+				Endpoint:    endpoint,
+				LastChecked: time.Now(),
+			}
+
+			// Followers are good enough to tell whether the endpoint is reachable.
+			_, result.Err = o.xbctlDB(ctx, database == OVNSouthbound, endpoint, ovnHealthCheckTimeout, "--no-leader-only", "get-connection")
+			result.ResponseTime = time.Since(result.LastChecked)
+			result.Healthy = result.Err == nil
+
+			checked[endpoint] = result
+			results = append(results, result)
+		}
+
+		ovnHealthMu.Lock()
+		ovnHealth[database] = checked
+		ovnHealthMu.Unlock()
+	}
+
+	return results
+}
+
+// OVNHealth returns the result of the last health check of each endpoint of the OVN databases.
+func OVNHealth() []OVNEndpointHealth {
+	ovnHealthMu.Lock()
+	defer ovnHealthMu.Unlock()
+
+	results := []OVNEndpointHealth{}
+	for _, database := range []OVNDatabase{OVNNorthbound, OVNSouthbound} {
+		for _, result := range ovnHealth[database] {
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Database != results[j].Database {
+			return results[i].Database < results[j].Database
+		}
+
+		return results[i].Endpoint < results[j].Endpoint
+	})
+
+	return results
+}
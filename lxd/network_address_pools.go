@@ -0,0 +1,500 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/network/addresspool"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var networkAddressPoolsCmd = APIEndpoint{
+	Path: "network-address-pools",
+
+	Get:  APIEndpointAction{Handler: networkAddressPoolsGet, AccessHandler: allowAuthenticated},
+	Post: APIEndpointAction{Handler: networkAddressPoolsPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var networkAddressPoolCmd = APIEndpoint{
+	Path: "network-address-pools/{pool}",
+
+	Delete: APIEndpointAction{Handler: networkAddressPoolDelete, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Get:    APIEndpointAction{Handler: networkAddressPoolGet, AccessHandler: allowAuthenticated},
+	Put:    APIEndpointAction{Handler: networkAddressPoolPut, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Patch:  APIEndpointAction{Handler: networkAddressPoolPut, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var networkAddressPoolAllocationsCmd = APIEndpoint{
+	Path: "network-address-pools/{pool}/allocations",
+
+	Get: APIEndpointAction{Handler: networkAddressPoolAllocationsGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewResources)},
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/network-address-pools network-address-pools network_address_pools_get
+//
+//	Get the network address pools
+//
+//	Returns a list of network address pools (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/network-address-pools/public"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/network-address-pools?recursion=1 network-address-pools network_address_pools_get_recursion1
+//
+//	Get the network address pools
+//
+//	Returns a list of network address pools (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of network address pools
+//	          items:
+//	            $ref: "#/definitions/NetworkAddressPool"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressPoolsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	recursion := util.IsRecursionRequest(r)
+
+	var poolNames []string
+
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		// Get list of network address pools.
+		poolNames, err = tx.GetNetworkAddressPools(ctx)
+
+		return err
+	})
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.NetworkAddressPool{}
+	for _, poolName := range poolNames {
+		if !recursion {
+			resultString = append(resultString, api.NewURL().Path(version.APIVersion, "network-address-pools", poolName).String())
+		} else {
+			pool, err := addresspool.LoadByName(s, poolName)
+			if err != nil {
+				continue
+			}
+
+			poolInfo := pool.Info()
+			poolInfo.UsedBy, _ = pool.UsedBy() // Ignore errors in UsedBy, will return nil.
+			poolInfo.UsedBy = project.FilterUsedBy(s.Authorizer, r, poolInfo.UsedBy)
+
+			resultMap = append(resultMap, *poolInfo)
+		}
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/network-address-pools network-address-pools network_address_pools_post
+//
+//	Add a network address pool
+//
+//	Creates a new network address pool.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: pool
+//	    description: Address pool
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkAddressPoolsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressPoolsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	req := api.NetworkAddressPoolsPost{}
+
+	// Parse the request into a record.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	_, err = addresspool.LoadByName(s, req.Name)
+	if err == nil {
+		return response.BadRequest(fmt.Errorf("The network address pool already exists"))
+	}
+
+	// Create the address pool.
+	err = addresspool.Create(s, &req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := addresspool.LoadByName(s, req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	lc := lifecycle.NetworkAddressPoolCreated.Event(pool, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/network-address-pools/{pool} network-address-pools network_address_pool_delete
+//
+//	Delete the network address pool
+//
+//	Removes the network address pool.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressPoolDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["pool"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := addresspool.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = pool.Delete()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.NetworkAddressPoolDeleted.Event(pool, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/network-address-pools/{pool} network-address-pools network_address_pool_get
+//
+//	Get the network address pool
+//
+//	Gets a specific network address pool.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Address pool
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkAddressPool"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressPoolGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["pool"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := addresspool.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info := pool.Info()
+	info.UsedBy, err = pool.UsedBy()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info.UsedBy = project.FilterUsedBy(s.Authorizer, r, info.UsedBy)
+
+	return response.SyncResponseETag(true, info, pool.Etag())
+}
+
+// swagger:operation PATCH /1.0/network-address-pools/{pool} network-address-pools network_address_pool_patch
+//
+//	Partially update the network address pool
+//
+//	Updates a subset of the network address pool configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: pool
+//	    description: Address pool configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkAddressPoolPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/network-address-pools/{pool} network-address-pools network_address_pool_put
+//
+//	Update the network address pool
+//
+//	Updates the entire network address pool configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: pool
+//	    description: Address pool configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkAddressPoolPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressPoolPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["pool"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get the existing network address pool.
+	pool, err := addresspool.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, pool.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.NetworkAddressPoolPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if r.Method == http.MethodPatch {
+		if req.Config == nil {
+			req.Config = map[string]string{}
+		}
+
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config.
+		for k, v := range pool.Info().Config {
+			_, ok := req.Config[k]
+			if !ok {
+				req.Config[k] = v
+			}
+		}
+	}
+
+	err = pool.Update(&req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.NetworkAddressPoolUpdated.Event(pool, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/network-address-pools/{pool}/allocations network-address-pools network_address_pool_allocations_get
+//
+//	Get the network address pool allocations
+//
+//	Returns the addresses allocated from the network address pool.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of allocated addresses
+//	          items:
+//	            $ref: "#/definitions/NetworkAddressPoolAllocation"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressPoolAllocationsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["pool"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	pool, err := addresspool.LoadByName(s, poolName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	allocations, err := pool.Allocations()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, allocations)
+}
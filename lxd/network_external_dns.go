@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/canonical/lxd/lxd/cluster"
+	clusterRequest "github.com/canonical/lxd/lxd/cluster/request"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/network/externaldns"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// networkExternalDNSSyncTask publishes the records of the instances connected to managed networks into the
+// external DNS provider configured on each network. Only the leader publishes records when clustered.
+func networkExternalDNSSyncTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil && !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if err == nil && s.LocalConfig.ClusterAddress() != leader {
+			// Forget what was published so that everything gets published again when becoming leader.
+			d.externalDNSRecords = map[string]map[string]externaldns.Record{}
+			return
+		}
+
+		err = networkExternalDNSSync(ctx, s, d.externalDNSRecords)
+		if err != nil {
+			logger.Warn("Failed publishing instance records to external DNS", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// networkExternalDNSSync publishes the records of the instances found in the leases of every managed network
+// using an external DNS provider. The published map keeps track of the records published for each network, keyed
+// by "<project>/<network>", so that only changes are sent to the providers.
+func networkExternalDNSSync(ctx context.Context, s *state.State, published map[string]map[string]externaldns.Record) error {
+	var projectNetworks map[string]map[int64]api.Network
+	var projectNames []string
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		projectNetworks, err = tx.GetCreatedNetworks(ctx)
+		if err != nil {
+			return err
+		}
+
+		projectNames, err = dbCluster.GetProjectNames(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading networks: %w", err)
+	}
+
+	var firstErr error
+	seen := map[string]bool{}
+
+	for networkProjectName, networks := range projectNetworks {
+		for _, netInfo := range networks {
+			if netInfo.Config["dns.external.provider"] == "" {
+				continue
+			}
+
+			key := networkProjectName + "/" + netInfo.Name
+			seen[key] = true
+
+			err := networkExternalDNSSyncNetwork(ctx, s, networkProjectName, netInfo.Name, projectNames, published, key)
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("Failed publishing records of network %q in project %q: %w", netInfo.Name, networkProjectName, err)
+			}
+		}
+	}
+
+	// Forget about networks which don't use an external DNS provider anymore.
+	for key := range published {
+		if !seen[key] {
+			delete(published, key)
+		}
+	}
+
+	return firstErr
+}
+
+// networkExternalDNSSyncNetwork publishes the records of the instances connected to a single network.
+func networkExternalDNSSyncNetwork(ctx context.Context, s *state.State, networkProjectName string, networkName string, projectNames []string, published map[string]map[string]externaldns.Record, key string) error {
+	n, err := network.LoadByName(s, networkProjectName, networkName)
+	if err != nil {
+		return err
+	}
+
+	provider, err := externaldns.Load(n.Config())
+	if err != nil {
+		return err
+	}
+
+	if provider == nil {
+		return nil
+	}
+
+	desired := map[string]externaldns.Record{}
+	for _, projectName := range projectNames {
+		leases, err := n.Leases(projectName, clusterRequest.ClientTypeNormal)
+		if err != nil {
+			return fmt.Errorf("Failed getting leases for project %q: %w", projectName, err)
+		}
+
+		for recordKey, record := range externaldns.RecordsFromLeases(networkProjectName, projectName, leases) {
+			desired[recordKey] = record
+		}
+	}
+
+	records, err := externaldns.Sync(ctx, provider, published[key], desired)
+	published[key] = records
+
+	return err
+}
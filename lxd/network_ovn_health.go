@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/network/openvswitch"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/warnings"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// networkOVNHealthCheckTask checks the connections to each endpoint of the OVN databases when OVN networks exist.
+// The results are exposed as metrics and used to prefer the healthy endpoints, and a warning is raised while any
+// endpoint is unreachable.
+func networkOVNHealthCheckTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := networkOVNHealthCheck(ctx, d.State())
+		if err != nil {
+			logger.Warn("Failed checking OVN database connections", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// networkOVNHealthCheck checks the connections to each endpoint of the OVN databases and updates the warning
+// about the unreachable ones.
+func networkOVNHealthCheck(ctx context.Context, s *state.State) error {
+	hasOVN := false
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		projectNetworks, err := tx.GetCreatedNetworks(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, networks := range projectNetworks {
+			for _, network := range networks {
+				if network.Type == "ovn" {
+					hasOVN = true
+					return nil
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !hasOVN {
+		return warnings.ResolveWarningsByLocalNodeAndType(s.DB.Cluster, warningtype.OVNDatabaseUnavailable)
+	}
+
+	// Load the client every time so that changes to the connection strings are picked up.
+	client, err := openvswitch.NewOVN(s)
+	if err != nil {
+		return err
+	}
+
+	previous := map[string]bool{}
+	for _, result := range openvswitch.OVNHealth() {
+		previous[string(result.Database)+" "+result.Endpoint] = result.Healthy
+	}
+
+	unhealthy := []string{}
+	for _, result := range client.CheckHealth(ctx) {
+		l := logger.AddContext(logger.Ctx{"database": result.Database, "endpoint": result.Endpoint})
+		wasHealthy, checked := previous[string(result.Database)+" "+result.Endpoint]
+
+		if !result.Healthy {
+			if !checked || wasHealthy {
+				l.Warn("OVN database endpoint unreachable", logger.Ctx{"err": result.Err})
+			}
+
+			unhealthy = append(unhealthy, fmt.Sprintf("%s database endpoint %q: %v", result.Database, result.Endpoint, result.Err))
+			continue
+		}
+
+		if checked && !wasHealthy {
+			l.Info("OVN database endpoint reachable again", logger.Ctx{"responseTime": result.ResponseTime})
+		}
+	}
+
+	if len(unhealthy) == 0 {
+		return warnings.ResolveWarningsByLocalNodeAndType(s.DB.Cluster, warningtype.OVNDatabaseUnavailable)
+	}
+
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, "", "", -1, warningtype.OVNDatabaseUnavailable, strings.Join(unhealthy, "; "))
+	})
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -73,6 +74,12 @@ var networkStateCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: networkStateGet, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanView, "networkName")},
 }
 
+var networkMTUCmd = APIEndpoint{
+	Path: "networks/{networkName}/mtu",
+
+	Get: APIEndpointAction{Handler: networkMTUGet, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanView, "networkName")},
+}
+
 // API endpoints
 
 // swagger:operation GET /1.0/networks networks networks_get
@@ -1027,6 +1034,7 @@ func networkDelete(d *Daemon, r *http.Request) response.Response {
 	}
 
 	requestor := request.CreateRequestor(r)
+	tombstoneRecord(s, entity.TypeNetwork, networkTombstone(s, n, requestor))
 	s.Events.SendLifecycle(projectName, lifecycle.NetworkDeleted.Event(n, requestor, nil))
 
 	return response.EmptySyncResponse
@@ -1285,8 +1293,15 @@ func networkPut(d *Daemon, r *http.Request) response.Response {
 
 	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
 
+	oldRevision := networkConfigRevision(s, n)
+
 	response := doNetworkUpdate(projectName, n, req, targetNode, clientType, r.Method, s.ServerClustered)
 
+	// Only changes to the global configuration are recorded, and only by the member handling the request.
+	if targetNode == "" && clientType == clusterRequest.ClientTypeNormal {
+		networkConfigHistoryRecord(s, r, projectName, networkName, oldRevision)
+	}
+
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(projectName, lifecycle.NetworkUpdated.Event(n, requestor, nil))
 
@@ -1858,3 +1873,92 @@ func networkStateGet(d *Daemon, r *http.Request) response.Response {
 
 	return response.SyncResponse(true, state)
 }
+
+// swagger:operation GET /1.0/networks/{name}/mtu networks networks_mtu_get
+//
+//	Check the network MTU
+//
+//	Returns the MTU of the network along with the underlay interfaces carrying its encapsulated traffic
+//	and the interfaces whose MTU doesn't match it on the cluster member.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkMTU"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkMTUGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// If a target was specified, forward the request to the relevant node.
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	check, err := n.MTUCheck()
+	if err != nil {
+		if errors.Is(err, network.ErrNotImplemented) {
+			return response.NotImplemented(fmt.Errorf("MTU check isn't supported by %q networks", n.Type()))
+		}
+
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, check)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
 )
@@ -38,11 +39,11 @@ func networkAutoAttach(cluster *db.Cluster, devName string) error {
 	return nil
 }
 
-// networkUpdateForkdnsServersTask runs every 30s and refreshes the forkdns servers list.
+// networkUpdateForkdnsServersTask runs every 30s and refreshes the forkdns servers list and the overlay peers.
 func networkUpdateForkdnsServersTask(s *state.State, heartbeatData *cluster.APIHeartbeat) error {
 	logger.Debug("Refreshing forkdns servers")
 
-	// Use api.ProjectDefaultName here as forkdns (fan bridge) networks don't support projects.
+	// Use api.ProjectDefaultName here as forkdns (fan bridge) and overlay networks don't support projects.
 	projectName := api.ProjectDefaultName
 
 	// Get a list of managed networks
@@ -64,7 +65,7 @@ func networkUpdateForkdnsServersTask(s *state.State, heartbeatData *cluster.APIH
 			continue
 		}
 
-		if n.Type() == "bridge" && n.Config()["bridge.mode"] == "fan" {
+		if n.Type() == "bridge" && shared.ValueInSlice(n.Config()["bridge.mode"], []string{"fan", "overlay"}) {
 			err := n.HandleHeartbeat(heartbeatData)
 			if err != nil {
 				return err
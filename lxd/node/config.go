@@ -3,6 +3,7 @@ package node
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/canonical/lxd/lxd/config"
 	"github.com/canonical/lxd/lxd/db"
@@ -92,6 +93,26 @@ func (c *Config) MetricsAddress() string {
 	return metricsAddress
 }
 
+// EventsAddress returns the address and port to setup the events listener on.
+func (c *Config) EventsAddress() string {
+	eventsAddress := c.m.GetString("core.events_address")
+	if eventsAddress != "" {
+		return util.CanonicalNetworkAddress(eventsAddress, shared.HTTPSEventsDefaultPort)
+	}
+
+	return eventsAddress
+}
+
+// MigrationAddress returns the address and port to setup the migration listener on.
+func (c *Config) MigrationAddress() string {
+	migrationAddress := c.m.GetString("core.migration_address")
+	if migrationAddress != "" {
+		return util.CanonicalNetworkAddress(migrationAddress, shared.HTTPSMigrationDefaultPort)
+	}
+
+	return migrationAddress
+}
+
 // MAASMachine returns the MAAS machine this instance is associated with, if
 // any.
 func (c *Config) MAASMachine() string {
@@ -118,11 +139,23 @@ func (c *Config) StorageImagesVolume() string {
 	return c.m.GetString("storage.images_volume")
 }
 
+// StoragePatchConcurrency returns how many storage pools patches process at the same time.
+func (c *Config) StoragePatchConcurrency() int64 {
+	return c.m.GetInt64("storage.patch_concurrency")
+}
+
 // SyslogSocket returns true if the syslog socket is enabled, otherwise false.
 func (c *Config) SyslogSocket() bool {
 	return c.m.GetBool("core.syslog_socket")
 }
 
+// PatchWaitTimeout returns how long patches wait for other cluster members before failing.
+// A zero value means waiting indefinitely.
+func (c *Config) PatchWaitTimeout() time.Duration {
+	n := c.m.GetInt64("core.patch_wait_timeout")
+	return time.Duration(n) * time.Second
+}
+
 // Dump current configuration keys and their values. Keys with values matching
 // their defaults are omitted.
 func (c *Config) Dump() map[string]any {
@@ -220,6 +253,17 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Address to bind the authoritative DNS server to
 	"core.dns_address": {Validator: validate.Optional(validate.IsListenAddress(true, true, false))},
 
+	// Network address for the events server
+
+	// lxdmeta:generate(entities=server; group=core; key=core.events_address)
+	// If set, the event stream (`/1.0/events`) is also served on this address, separately from the REST API.
+	// See {ref}`events`.
+	// ---
+	//  type: string
+	//  scope: local
+	//  shortdesc: Address to bind the events server to (HTTPS)
+	"core.events_address": {Validator: validate.Optional(validate.IsListenAddress(true, true, false))},
+
 	// Network address for the metrics server
 
 	// lxdmeta:generate(entities=server; group=core; key=core.metrics_address)
@@ -230,6 +274,17 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Address to bind the metrics server to (HTTPS)
 	"core.metrics_address": {Validator: validate.Optional(validate.IsListenAddress(true, true, false))},
 
+	// Network address for the migration server
+
+	// lxdmeta:generate(entities=server; group=core; key=core.migration_address)
+	// If set, clients instruct other servers to use this address for the migration and data transfer connections
+	// of instances and storage volumes instead of the REST API address.
+	// ---
+	//  type: string
+	//  scope: local
+	//  shortdesc: Address to bind the migration server to (HTTPS)
+	"core.migration_address": {Validator: validate.Optional(validate.IsListenAddress(true, true, false))},
+
 	// Network address for the storage buckets server
 
 	// lxdmeta:generate(entities=server; group=core; key=core.storage_buckets_address)
@@ -251,6 +306,19 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to enable the syslog unixgram socket listener
 	"core.syslog_socket": {Validator: validate.Optional(validate.IsBool), Type: config.Bool},
 
+	// Patch wait timeout
+
+	// lxdmeta:generate(entities=server; group=core; key=core.patch_wait_timeout)
+	// Some patches applied at daemon startup wait for the cluster leader or for all other cluster members to apply them first.
+	// Specify the number of seconds after which such a patch fails instead of blocking the startup of the daemon.
+	// Set this option to `0` to wait indefinitely.
+	// ---
+	//  type: integer
+	//  scope: local
+	//  defaultdesc: `0`
+	//  shortdesc: Number of seconds patches wait for other cluster members
+	"core.patch_wait_timeout": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsUint32)},
+
 	// MAAS machine this LXD instance is associated with
 
 	// lxdmeta:generate(entities=server; group=miscellaneous; key=maas.machine)
@@ -278,4 +346,17 @@ var ConfigSchema = config.Schema{
 	//  scope: local
 	//  shortdesc: Volume to use to store the image tarballs
 	"storage.images_volume": {},
+
+	// Storage patches
+
+	// lxdmeta:generate(entities=server; group=miscellaneous; key=storage.patch_concurrency)
+	// Some patches applied at daemon startup after an upgrade update every storage pool.
+	// Specify how many storage pools such a patch processes at the same time.
+	// Set this option to `1` to process the storage pools one after the other.
+	// ---
+	//  type: integer
+	//  scope: local
+	//  defaultdesc: `4`
+	//  shortdesc: Number of storage pools that patches process concurrently
+	"storage.patch_concurrency": {Type: config.Int64, Default: "4", Validator: validate.Optional(validate.IsInRange(1, 64))},
 }
@@ -0,0 +1,135 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// callbackSignatureHeader is the header holding the signature of the callback payload.
+const callbackSignatureHeader = "X-LXD-Signature"
+
+// callbackTimeout is how long a single callback attempt may take.
+const callbackTimeout = 30 * time.Second
+
+// setCallback validates and records the URL to notify when the operation completes.
+func (op *Operation) setCallback(callbackURL string) error {
+	if callbackURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid operation callback URL: %v", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return api.StatusErrorf(http.StatusBadRequest, "Operation callback URL must be an HTTP or HTTPS URL")
+	}
+
+	op.callbackURL = callbackURL
+
+	return nil
+}
+
+// sendCallback posts the final state of the operation to its callback URL, retrying with an exponential backoff
+// if that fails.
+func (op *Operation) sendCallback() {
+	_, md, err := op.Render()
+	if err != nil {
+		op.logger.Warn("Failed rendering operation for callback", logger.Ctx{"err": err})
+		return
+	}
+
+	body, err := json.Marshal(md)
+	if err != nil {
+		op.logger.Warn("Failed encoding operation for callback", logger.Ctx{"err": err})
+		return
+	}
+
+	ctx := context.Background()
+	var secret string
+	var retries int64
+
+	if op.state != nil {
+		ctx = op.state.ShutdownCtx
+
+		if op.state.GlobalConfig != nil {
+			secret, retries = op.state.GlobalConfig.OperationCallbacks()
+		}
+	}
+
+	delay := time.Second
+	for attempt := int64(0); ; attempt++ {
+		err = postCallback(ctx, op.callbackURL, body, secret)
+		if err == nil {
+			op.logger.Debug("Sent operation callback", logger.Ctx{"url": op.callbackURL})
+			return
+		}
+
+		if attempt >= retries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+
+	op.logger.Warn("Failed sending operation callback", logger.Ctx{"url": op.callbackURL, "err": err})
+}
+
+// postCallback sends the payload to the callback URL, signing it if a secret is set.
+func postCallback(ctx context.Context, callbackURL string, body []byte, secret string) error {
+	ctx, cancel := context.WithTimeout(ctx, callbackTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", version.UserAgent)
+
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write(body)
+		req.Header.Set(callbackSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	// Don't follow redirects so that the callback only ever reaches the requested URL.
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Callback returned unexpected status %q", resp.Status)
+	}
+
+	return nil
+}
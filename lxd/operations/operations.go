@@ -110,6 +110,7 @@ type Operation struct {
 	dbOpType    operationtype.Type
 	requestor   *api.EventLifecycleRequestor
 	logger      logger.Logger
+	callbackURL string
 
 	// Those functions are called at various points in the Operation lifecycle
 	onRun     func(*Operation) error
@@ -184,9 +185,14 @@ func OperationCreate(s *state.State, projectName string, opClass OperationClass,
 		return nil, fmt.Errorf("Token operations can't have a Cancel hook")
 	}
 
-	// Set requestor if request was provided.
+	// Set requestor and completion callback if request was provided.
 	if r != nil {
 		op.SetRequestor(r)
+
+		err = op.setCallback(r.Header.Get(request.HeaderOperationCallback))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	operationsLock.Lock()
@@ -236,6 +242,10 @@ func (op *Operation) done() {
 	op.finished.Cancel()
 	op.lock.Unlock()
 
+	if op.callbackURL != "" {
+		go op.sendCallback()
+	}
+
 	go func() {
 		shutdownCtx := context.Background()
 		if op.state != nil {
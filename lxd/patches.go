@@ -9,9 +9,12 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/canonical/lxd/lxd/backup"
 	"github.com/canonical/lxd/lxd/certificate"
@@ -19,6 +22,7 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/node"
@@ -41,8 +45,27 @@ const (
 	patchPreDaemonStorage
 	patchPostDaemonStorage
 	patchPostNetworks
+	patchPostInstances
 )
 
+// String returns the name of the patch stage.
+func (s patchStage) String() string {
+	switch s {
+	case patchPreLoadClusterConfig:
+		return "pre-load-cluster-config"
+	case patchPreDaemonStorage:
+		return "pre-daemon-storage"
+	case patchPostDaemonStorage:
+		return "post-daemon-storage"
+	case patchPostNetworks:
+		return "post-networks"
+	case patchPostInstances:
+		return "post-instances"
+	}
+
+	return "unset"
+}
+
 /*
 Patches are one-time actions that are sometimes needed to update
 
@@ -58,6 +81,12 @@ Patches are one-time actions that are sometimes needed to update
 	DO NOT use this mechanism for database update. Schema updates must be
 	done through the separate schema update mechanism.
 
+	Patches fixing storage volumes should use patchStorageVolumes, which
+	handles clustering and remote storage pools consistently.
+
+	Patches fixing database records should provide a verify function, which
+	reports the records still needing the fix without modifying anything, so
+	that patches marked as applied can be checked with "lxd admin verify-patches".
 
 	Only append to the patches list, never remove entries and never re-order them.
 */
@@ -72,7 +101,7 @@ var patches = []patch{
 	{name: "thinpool_typo_fix", stage: patchPostDaemonStorage, run: patchThinpoolTypoFix},
 	{name: "vm_rename_uuid_key", stage: patchPostDaemonStorage, run: patchVMRenameUUIDKey},
 	{name: "db_nodes_autoinc", stage: patchPreDaemonStorage, run: patchDBNodesAutoInc},
-	{name: "network_acl_remove_defaults", stage: patchPostDaemonStorage, run: patchGenericNetwork(patchNetworkACLRemoveDefaults)},
+	{name: "network_acl_remove_defaults", stage: patchPostDaemonStorage, run: patchGenericNetwork(patchNetworkACLRemoveDefaults), verify: patchVerifyNetworkACLRemoveDefaults},
 	{name: "clustering_server_cert_trust", stage: patchPreDaemonStorage, run: patchClusteringServerCertTrust},
 	{name: "warnings_remove_empty_node", stage: patchPostDaemonStorage, run: patchRemoveWarningsWithEmptyNode},
 	{name: "dnsmasq_entries_include_device_name", stage: patchPostDaemonStorage, run: patchDnsmasqEntriesIncludeDeviceName},
@@ -83,35 +112,331 @@ var patches = []patch{
 	{name: "storage_move_custom_iso_block_volumes", stage: patchPostDaemonStorage, run: patchStorageRenameCustomISOBlockVolumes},
 	{name: "zfs_set_content_type_user_property", stage: patchPostDaemonStorage, run: patchZfsSetContentTypeUserProperty},
 	{name: "storage_zfs_unset_invalid_block_settings", stage: patchPostDaemonStorage, run: patchStorageZfsUnsetInvalidBlockSettings},
-	{name: "storage_zfs_unset_invalid_block_settings_v2", stage: patchPostDaemonStorage, run: patchStorageZfsUnsetInvalidBlockSettingsV2},
+	{name: "storage_zfs_unset_invalid_block_settings_v2", stage: patchPostDaemonStorage, run: patchStorageZfsUnsetInvalidBlockSettingsV2, verify: patchVerifyStorageZfsUnsetInvalidBlockSettings},
 	{name: "storage_unset_invalid_block_settings", stage: patchPostDaemonStorage, run: patchStorageUnsetInvalidBlockSettings},
-	{name: "candid_rbac_remove_config_keys", stage: patchPreDaemonStorage, run: patchRemoveCandidRBACConfigKeys},
+	{name: "candid_rbac_remove_config_keys", stage: patchPreDaemonStorage, run: patchRemoveCandidRBACConfigKeys, verify: patchVerifyConfigKeys(patchCandidRBACConfigKeys...)},
 	{name: "storage_set_volume_uuid", stage: patchPostDaemonStorage, run: patchStorageSetVolumeUUID},
-	{name: "storage_set_volume_uuid_v2", stage: patchPostDaemonStorage, run: patchStorageSetVolumeUUIDV2},
+	{name: "storage_set_volume_uuid_v2", stage: patchPostDaemonStorage, run: patchStorageSetVolumeUUIDV2, verify: patchVerifyStorageSetVolumeUUID},
 	{name: "storage_move_custom_iso_block_volumes_v2", stage: patchPostDaemonStorage, run: patchStorageRenameCustomISOBlockVolumesV2},
-	{name: "storage_unset_invalid_block_settings_v2", stage: patchPostDaemonStorage, run: patchStorageUnsetInvalidBlockSettingsV2},
-	{name: "config_remove_core_trust_password", stage: patchPreLoadClusterConfig, run: patchRemoveCoreTrustPassword},
+	{name: "storage_unset_invalid_block_settings_v2", stage: patchPostDaemonStorage, run: patchStorageUnsetInvalidBlockSettingsV2, verify: patchVerifyStorageUnsetInvalidBlockSettings},
+	{name: "config_remove_core_trust_password", stage: patchPreLoadClusterConfig, run: patchRemoveCoreTrustPassword, verify: patchVerifyConfigKeys("core.trust_password")},
 }
 
 type patch struct {
-	name  string
-	stage patchStage
-	run   func(name string, d *Daemon) error
+	name   string
+	stage  patchStage
+	run    func(name string, d *Daemon) error
+	wait   patchWaitPolicy
+	verify func(name string, d *Daemon) ([]string, error) // Returns the entities still needing the patch, if any.
+}
+
+// patchWaitPolicy defines how a patch waits for the cluster leader or other cluster members, see patchWait.
+type patchWaitPolicy struct {
+	interval time.Duration // Time between checks when not woken up by a notification, defaults to ten seconds.
+	timeout  time.Duration // Time after which the patch fails, defaults to core.patch_wait_timeout.
 }
 
 func (p *patch) apply(d *Daemon) error {
 	logger.Info("Applying patch", logger.Ctx{"name": p.name})
+	d.patchesStatus.start(p.name, p.wait)
 
 	err := p.run(p.name, d)
 	if err != nil {
-		return fmt.Errorf("Failed applying patch %q: %w", p.name, err)
+		err = fmt.Errorf("Failed applying patch %q: %w", p.name, err)
+	} else {
+		err = d.db.Node.MarkPatchAsApplied(p.name)
+		if err != nil {
+			err = fmt.Errorf("Failed marking patch applied %q: %w", p.name, err)
+		} else {
+			patchNotify(d)
+		}
 	}
 
-	err = d.db.Node.MarkPatchAsApplied(p.name)
+	d.patchesStatus.finish(p.name, err)
+	p.log(d)
+
+	return err
+}
+
+// skip marks the patch as applied without running it, so that a patch which prevents the daemon from starting
+// can be bypassed, see DaemonConfig.SkipPatches.
+func (p *patch) skip(d *Daemon) error {
+	logger.Warn("Skipping patch", logger.Ctx{"name": p.name})
+
+	err := d.db.Node.MarkPatchAsApplied(p.name)
 	if err != nil {
 		return fmt.Errorf("Failed marking patch applied %q: %w", p.name, err)
 	}
 
+	patchNotify(d)
+	d.patchesStatus.skip(p.name)
+	p.log(d)
+
+	return nil
+}
+
+// log records the outcome of the patch in the patches log of the cluster database.
+// Failing to do so doesn't fail the patch.
+func (p *patch) log(d *Daemon) {
+	status := d.patchesStatus.get(p.name)
+	if status == nil {
+		return
+	}
+
+	entry := db.PatchLogEntry{
+		Name:       p.name,
+		Stage:      p.stage.String(),
+		Status:     status.Status,
+		Error:      status.Error,
+		Summary:    status.summary(),
+		StartedAt:  status.StartedAt,
+		FinishedAt: status.FinishedAt,
+	}
+
+	err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.CreatePatchLogEntry(ctx, entry)
+	})
+	if err != nil {
+		logger.Warn("Failed recording patch in the patches log", logger.Ctx{"name": p.name, "err": err})
+	}
+}
+
+// Patch statuses reported through the internal API.
+const (
+	patchStatusPending = "pending"
+	patchStatusRunning = "running"
+	patchStatusApplied = "applied"
+	patchStatusFailed  = "failed"
+	patchStatusSkipped = "skipped"
+)
+
+// internalPatchStatus represents the status of a patch applied since the daemon started.
+type internalPatchStatus struct {
+	Name       string    `json:"name"        yaml:"name"`
+	Stage      string    `json:"stage"       yaml:"stage"`
+	Status     string    `json:"status"      yaml:"status"`
+	Progress   string    `json:"progress"    yaml:"progress"`
+	Error      string    `json:"error"       yaml:"error"`
+	StartedAt  time.Time `json:"started_at"  yaml:"started_at"`
+	FinishedAt time.Time `json:"finished_at" yaml:"finished_at"`
+
+	wait     patchWaitPolicy
+	modified map[string]int
+}
+
+// summary returns how many entities of each type the patch modified, for example "instance: 3, storage_pool: 2".
+func (s *internalPatchStatus) summary() string {
+	entityTypes := make([]string, 0, len(s.modified))
+	for entityType := range s.modified {
+		entityTypes = append(entityTypes, entityType)
+	}
+
+	sort.Strings(entityTypes)
+
+	parts := make([]string, 0, len(entityTypes))
+	for _, entityType := range entityTypes {
+		parts = append(parts, fmt.Sprintf("%s: %d", entityType, s.modified[entityType]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// patchesStatus tracks the patches applied since the daemon started, so that their progress can be queried
+// through the internal API while the daemon is still starting up.
+type patchesStatus struct {
+	mu      sync.Mutex
+	patches []internalPatchStatus
+	changed chan struct{} // Closed by wake, see changes.
+	metrics bool          // Whether the patch metrics are served while the daemon starts up, see enableMetrics.
+}
+
+// queue records the patches which are going to be applied as pending.
+func (s *patchesStatus) queue(stage patchStage, names []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, name := range names {
+		if s.find(name) == nil {
+			s.patches = append(s.patches, internalPatchStatus{Name: name, Stage: stage.String(), Status: patchStatusPending})
+		}
+	}
+}
+
+// start records that the patch is being applied along with its wait policy.
+func (s *patchesStatus) start(name string, wait patchWaitPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.find(name)
+	if status == nil {
+		s.patches = append(s.patches, internalPatchStatus{Name: name})
+		status = &s.patches[len(s.patches)-1]
+	}
+
+	status.Status = patchStatusRunning
+	status.Progress = ""
+	status.Error = ""
+	status.StartedAt = time.Now().UTC()
+	status.FinishedAt = time.Time{}
+	status.wait = wait
+	status.modified = nil
+}
+
+// waitPolicy returns the wait policy of the patch being applied.
+func (s *patchesStatus) waitPolicy(name string) patchWaitPolicy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.find(name)
+	if status == nil {
+		return patchWaitPolicy{}
+	}
+
+	return status.wait
+}
+
+// changes returns a channel which is closed the next time wake is called.
+// Callers must get the channel before checking their condition so that they don't miss a wake up.
+func (s *patchesStatus) changes() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+	}
+
+	return s.changed
+}
+
+// wake wakes up the patches waiting on cluster members, so that they re-check their condition.
+// It is called when a patch is applied or a patch barrier is reached, either locally or by another cluster member,
+// and when a heartbeat is sent or received.
+func (s *patchesStatus) wake() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
+}
+
+// progress records how far the patch has got. The progress is also logged as it can otherwise only be seen
+// through the internal API.
+func (s *patchesStatus) progress(name string, progress string) {
+	logger.Info("Patch progress", logger.Ctx{"name": name, "progress": progress})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.find(name)
+	if status != nil {
+		status.Progress = progress
+	}
+}
+
+// modified records that the patch modified the given number of entities of the given type, for the patches log.
+func (s *patchesStatus) modified(name string, entityType string, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.find(name)
+	if status == nil {
+		return
+	}
+
+	if status.modified == nil {
+		status.modified = map[string]int{}
+	}
+
+	status.modified[entityType] += count
+}
+
+// get returns a copy of the status of the patch with the given name, or nil if it isn't tracked.
+func (s *patchesStatus) get(name string) *internalPatchStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.find(name)
+	if status == nil {
+		return nil
+	}
+
+	statusCopy := *status
+
+	return &statusCopy
+}
+
+// finish records that the patch has been applied, or failed if err isn't nil.
+func (s *patchesStatus) finish(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.find(name)
+	if status == nil {
+		return
+	}
+
+	status.FinishedAt = time.Now().UTC()
+	status.Status = patchStatusApplied
+	if err != nil {
+		status.Status = patchStatusFailed
+		status.Error = err.Error()
+	}
+}
+
+// skip records that the patch has been marked as applied without running it.
+func (s *patchesStatus) skip(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.find(name)
+	if status == nil {
+		s.patches = append(s.patches, internalPatchStatus{Name: name})
+		status = &s.patches[len(s.patches)-1]
+	}
+
+	now := time.Now().UTC()
+	status.Status = patchStatusSkipped
+	status.Error = ""
+	status.StartedAt = now
+	status.FinishedAt = now
+}
+
+// enableMetrics records that the metrics endpoint can serve the patch metrics while the daemon is still starting
+// up, once the global configuration and the trusted identities needed to authenticate the requests are loaded.
+func (s *patchesStatus) enableMetrics() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.metrics = true
+}
+
+// metricsEnabled returns whether the patch metrics can be served while the daemon is still starting up.
+func (s *patchesStatus) metricsEnabled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.metrics
+}
+
+// list returns a copy of the statuses of the patches, in the order they are applied.
+func (s *patchesStatus) list() []internalPatchStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]internalPatchStatus{}, s.patches...)
+}
+
+// find returns the status of the patch with the given name, or nil if it isn't tracked.
+// The caller must hold the lock.
+func (s *patchesStatus) find(name string) *internalPatchStatus {
+	for i := range s.patches {
+		if s.patches[i].Name == name {
+			return &s.patches[i]
+		}
+	}
+
 	return nil
 }
 
@@ -136,19 +461,422 @@ func patchesApply(d *Daemon, stage patchStage) error {
 		return err
 	}
 
+	pendingPatches := make([]string, 0, len(patches))
+	for _, patch := range patches {
+		if patch.stage == stage && !shared.ValueInSlice(patch.name, appliedPatches) {
+			pendingPatches = append(pendingPatches, patch.name)
+		}
+	}
+
+	d.patchesStatus.queue(stage, pendingPatches)
+
 	for _, patch := range patches {
 		if patch.stage == patchNoStageSet {
 			return fmt.Errorf("Patch %q has no stage set: %d", patch.name, patch.stage)
 		}
 
+		if patch.stage != stage {
+			continue
+		}
+
 		if shared.ValueInSlice(patch.name, appliedPatches) {
 			continue
 		}
 
-		err := patch.apply(d)
+		if shared.ValueInSlice(patch.name, d.config.SkipPatches) {
+			err := patch.skip(d)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err := patch.apply(d)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// patchRerunMu prevents the same patch from being re-run several times at once.
+var patchRerunMu sync.Mutex
+
+// patchRerun clears the applied marker of the named patch and applies it again.
+// If the patch fails, it stays unmarked and is applied again at the next daemon startup.
+func patchRerun(d *Daemon, name string) error {
+	var p *patch
+	for i := range patches {
+		if patches[i].name == name {
+			p = &patches[i]
+			break
+		}
+	}
+
+	if p == nil {
+		return api.StatusErrorf(http.StatusNotFound, "Patch %q not found", name)
+	}
+
+	if !patchRerunMu.TryLock() {
+		return api.StatusErrorf(http.StatusConflict, "Another patch is being re-run")
+	}
+
+	defer patchRerunMu.Unlock()
+
+	appliedPatches, err := d.db.Node.GetAppliedPatches()
+	if err != nil {
+		return err
+	}
+
+	if !shared.ValueInSlice(name, appliedPatches) {
+		return api.StatusErrorf(http.StatusBadRequest, "Patch %q hasn't been applied yet", name)
+	}
+
+	logger.Warn("Re-running patch", logger.Ctx{"name": name})
+
+	err = d.db.Node.UnmarkPatchAsApplied(name)
+	if err != nil {
+		return fmt.Errorf("Failed clearing applied marker of patch %q: %w", name, err)
+	}
+
+	return p.apply(d)
+}
+
+// internalPatchVerifyResult represents the entities still needing an applied patch.
+type internalPatchVerifyResult struct {
+	Name     string   `json:"name"     yaml:"name"`
+	Entities []string `json:"entities" yaml:"entities"`
+}
+
+// patchesVerify runs the verify function of all the applied patches having one and returns the patches for which
+// some entities still match the condition the patch is meant to fix. Nothing is modified, so this is safe to run at
+// any time to detect patches that were marked as applied without doing all of their work.
+func patchesVerify(d *Daemon) ([]internalPatchVerifyResult, error) {
+	appliedPatches, err := d.db.Node.GetAppliedPatches()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []internalPatchVerifyResult{}
+	for _, p := range patches {
+		if p.verify == nil || !shared.ValueInSlice(p.name, appliedPatches) {
+			continue
+		}
+
+		entities, err := p.verify(p.name, d)
+		if err != nil {
+			return nil, fmt.Errorf("Failed verifying patch %q: %w", p.name, err)
+		}
+
+		if len(entities) > 0 {
+			results = append(results, internalPatchVerifyResult{Name: p.name, Entities: entities})
+		}
+	}
+
+	return results, nil
+}
+
+// patchVerifyQuery returns the entities of the given type whose names are returned by the given read-only query
+// against the cluster database, formatted as "<type> <name>".
+func patchVerifyQuery(d *Daemon, entityType string, q string, args ...any) ([]string, error) {
+	var names []string
+
+	err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		names, err = query.SelectStrings(ctx, tx.Tx(), q, args...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]string, 0, len(names))
+	for _, name := range names {
+		entities = append(entities, entityType+" "+name)
+	}
+
+	return entities, nil
+}
+
+// patchVerifyConfigKeys returns a verify function reporting the given cluster config keys if they are still set.
+func patchVerifyConfigKeys(keys ...string) func(name string, d *Daemon) ([]string, error) {
+	return func(name string, d *Daemon) ([]string, error) {
+		q := fmt.Sprintf("SELECT key FROM config WHERE key IN %s ORDER BY key", query.Params(len(keys)))
+
+		args := make([]any, 0, len(keys))
+		for _, key := range keys {
+			args = append(args, key)
+		}
+
+		return patchVerifyQuery(d, "config", q, args...)
+	}
+}
+
+// patchWait calls check until it returns true, following the wait policy of the named patch.
+// The check is repeated whenever the patches are woken up by a patch being applied or a patch barrier being reached
+// on any cluster member, or by a heartbeat, and otherwise at the interval of the policy as a safety net.
+// It fails once the timeout of the policy is reached, so that a member that can't make progress doesn't block
+// the daemon startup indefinitely.
+func patchWait(d *Daemon, name string, waitingOn string, check func() (bool, error)) error {
+	policy := d.patchesStatus.waitPolicy(name)
+	if policy.interval <= 0 {
+		policy.interval = 10 * time.Second
+	}
+
+	if policy.timeout <= 0 {
+		d.globalConfigMu.Lock()
+		localConfig := d.localConfig
+		d.globalConfigMu.Unlock()
+
+		if localConfig != nil {
+			policy.timeout = localConfig.PatchWaitTimeout()
+		}
+	}
+
+	ctx := d.shutdownCtx
+	if policy.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.timeout)
+		defer cancel()
+	}
+
+	for {
+		changes := d.patchesStatus.changes()
+
+		done, err := check()
+		if err != nil {
+			return err
+		}
+
+		if done {
+			return nil
+		}
+
+		logger.Warnf("Waiting for %q patch to be applied on %s", name, waitingOn)
+		d.patchesStatus.progress(name, "Waiting for "+waitingOn)
+
+		select {
+		case <-ctx.Done():
+			if d.shutdownCtx.Err() != nil {
+				return d.shutdownCtx.Err()
+			}
+
+			return fmt.Errorf("Timed out after %s waiting for the patch to be applied on %s (see core.patch_wait_timeout)", policy.timeout, waitingOn)
+		case <-changes:
+		case <-time.After(policy.interval):
+		}
+	}
+}
+
+// patchBarrier records that this member reached the barrier of the named patch, and then waits until all the
+// cluster members reached it too. This lets a patch wait for "patch X applied on all members" at the point where it
+// needs it, for example before switching to a new behaviour which all members must understand.
+func patchBarrier(d *Daemon, name string) error {
+	if !d.serverClustered {
+		return nil
+	}
+
+	err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.CreatePatchBarrierArrival(ctx, name)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed recording arrival at barrier of patch %q: %w", name, err)
+	}
+
+	patchNotify(d)
+
+	return patchWait(d, name, "all cluster members", func() (bool, error) {
+		var missing []string
+		err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+			missing, err = tx.GetPatchBarrierMissingMembers(ctx, name)
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+
+		if len(missing) > 0 {
+			logger.Debug("Patch barrier not reached by all cluster members", logger.Ctx{"name": name, "members": missing})
+			return false, nil
+		}
+
+		return true, nil
+	})
+}
+
+// patchNotify wakes up the local patches waiting on cluster members and notifies the other cluster members so that
+// theirs re-check their condition. Failing to notify a member only delays it until its next periodic check.
+func patchNotify(d *Daemon) {
+	d.patchesStatus.wake()
+
+	s := d.State()
+	if !s.ServerClustered || s.LocalConfig == nil || s.Endpoints == nil {
+		return
+	}
+
+	go func() {
+		err := cluster.NotifyPatches(s, s.Endpoints.NetworkCert(), s.ServerCert())
+		if err != nil {
+			logger.Debug("Failed notifying cluster members about patches", logger.Ctx{"err": err})
+		}
+	}()
+}
+
+// patchStoragePools calls f for each of the given storage pools. As storage pools are independent from each
+// other, up to storage.patch_concurrency pools are processed at the same time.
+func patchStoragePools(d *Daemon, name string, poolNames []string, f func(poolName string) error) error {
+	s := d.State()
+
+	g := errgroup.Group{}
+	g.SetLimit(int(s.LocalConfig.StoragePatchConcurrency()))
+
+	var done atomic.Int64
+	for _, poolName := range poolNames {
+		g.Go(func() error {
+			err := f(poolName)
+			if err != nil {
+				return err
+			}
+
+			d.patchesStatus.modified(name, "storage_pool", 1)
+			d.patchesStatus.progress(name, fmt.Sprintf("Storage pools: %d/%d", done.Add(1), len(poolNames)))
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// patchVolumeFilter selects the storage volumes passed to the function of patchStorageVolumes.
+type patchVolumeFilter struct {
+	Drivers      []string                            // Drivers of the pools to patch, all drivers if empty.
+	Types        []string                            // Volume type names to patch, all types if empty.
+	ContentTypes []string                            // Content type names to patch, all content types if empty.
+	Snapshots    bool                                // Whether to also patch the snapshots of the volumes.
+	Capability   func(info storageDrivers.Info) bool // Capability the pool driver must have, ignored if nil.
+}
+
+// patchStorageVolumes calls f for each storage volume of this member matching the filter, along with its loaded pool.
+// Pools are processed concurrently, see patchStoragePools, and their volumes one after the other.
+//
+// The volumes of remote pools are available on all cluster members. When remoteOnce is true, they are only passed
+// to f on the member selected by selectedPatchClusterMember, which suits patches that only update the cluster
+// database or the shared storage. Otherwise every member gets them, for example to update its local state.
+//
+// Reporting the modified volumes through patchesStatus.modified is up to f, as it may leave some volumes unchanged.
+func patchStorageVolumes(d *Daemon, name string, filter patchVolumeFilter, remoteOnce bool, f func(pool storagePools.Pool, vol *db.StorageVolume) error) error {
+	s := d.State()
+
+	var poolNames []string
+	poolDrivers := map[string]string{}
+	poolVolumes := map[string][]*db.StorageVolume{}
+
+	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		pools, err := tx.GetStoragePoolNames(ctx)
+		if err != nil {
+			// Nothing to patch if there are no storage pools.
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil
+			}
+
+			return fmt.Errorf("Failed getting storage pool names: %w", err)
+		}
+
+		for _, pool := range pools {
+			poolID, err := tx.GetStoragePoolID(ctx, pool)
+			if err != nil {
+				return fmt.Errorf("Failed getting storage pool ID of pool %q: %w", pool, err)
+			}
+
+			driverName, err := tx.GetStoragePoolDriver(ctx, poolID)
+			if err != nil {
+				return fmt.Errorf("Failed getting storage pool driver of pool %q: %w", pool, err)
+			}
+
+			if len(filter.Drivers) > 0 && !shared.ValueInSlice(driverName, filter.Drivers) {
+				continue
+			}
+
+			// Only get the volumes of this member and the volumes of remote pools.
+			volumes, err := tx.GetStorageVolumes(ctx, true, db.StorageVolumeFilter{PoolID: &poolID})
+			if err != nil {
+				return fmt.Errorf("Failed getting storage volumes of pool %q: %w", pool, err)
+			}
+
+			poolNames = append(poolNames, pool)
+			poolDrivers[pool] = driverName
+			poolVolumes[pool] = volumes
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	isSelectedPatchMember, err := selectedPatchClusterMember(d)
+	if err != nil {
+		return err
+	}
+
+	return patchStoragePools(d, name, poolNames, func(poolName string) error {
+		remote := shared.ValueInSlice(poolDrivers[poolName], storageDrivers.RemoteDriverNames())
+		if remote && remoteOnce && !isSelectedPatchMember {
+			return nil
+		}
+
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			return fmt.Errorf("Failed loading pool %q: %w", poolName, err)
+		}
+
+		if filter.Capability != nil && !filter.Capability(pool.Driver().Info()) {
+			return nil
+		}
+
+		for _, vol := range poolVolumes[poolName] {
+			if !filter.Snapshots && shared.IsSnapshot(vol.Name) {
+				continue
+			}
+
+			if len(filter.Types) > 0 && !shared.ValueInSlice(vol.Type, filter.Types) {
+				continue
+			}
+
+			if len(filter.ContentTypes) > 0 && !shared.ValueInSlice(vol.ContentType, filter.ContentTypes) {
+				continue
+			}
+
+			err := f(pool, vol)
+			if err != nil {
+				return fmt.Errorf("Failed patching volume %q in project %q on pool %q: %w", vol.Name, vol.Project, poolName, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// patchInstances calls f for each instance on this member, reporting the progress of the named patch.
+// It is meant for patches of the patchPostInstances stage, which run once the instance drivers are initialised
+// and before the instances are started.
+func patchInstances(d *Daemon, name string, f func(inst instance.Instance) error) error {
+	insts, err := instance.LoadNodeAll(d.State(), instancetype.Any)
+	if err != nil {
+		return fmt.Errorf("Failed loading local instances: %w", err)
+	}
+
+	for i, inst := range insts {
+		err := f(inst)
 		if err != nil {
-			return err
+			return fmt.Errorf("Failed patching instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
 		}
+
+		d.patchesStatus.modified(name, "instance", 1)
+		d.patchesStatus.progress(name, fmt.Sprintf("Instances: %d/%d", i+1, len(insts)))
 	}
 
 	return nil
@@ -205,7 +933,7 @@ func patchDnsmasqEntriesIncludeDeviceName(name string, d *Daemon) error {
 }
 
 func patchRemoveWarningsWithEmptyNode(name string, d *Daemon) error {
-	err := d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		warnings, err := dbCluster.GetWarnings(ctx, tx.Tx())
 		if err != nil {
 			return err
@@ -235,7 +963,7 @@ func patchClusteringServerCertTrust(name string, d *Daemon) error {
 	}
 
 	var serverName string
-	err := d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
 		serverName, err = tx.GetLocalNodeName(ctx)
 		return err
@@ -251,7 +979,7 @@ func patchClusteringServerCertTrust(name string, d *Daemon) error {
 	}
 	// Update our own entry in the nodes table.
 	logger.Infof("Adding local server certificate to global trust store for %q patch", name)
-	err = d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err = d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		return cluster.EnsureServerCertificateTrusted(serverName, serverCert, tx)
 	})
 	if err != nil {
@@ -259,17 +987,18 @@ func patchClusteringServerCertTrust(name string, d *Daemon) error {
 	}
 
 	logger.Infof("Added local server certificate to global trust store for %q patch", name)
+	patchNotify(d)
 
 	// Check all other members have done the same.
-	for {
+	err = patchWait(d, name, "all cluster members", func() (bool, error) {
 		var err error
 		var dbCerts []dbCluster.Certificate
-		err = d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err = d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 			dbCerts, err = dbCluster.GetCertificates(ctx, tx.Tx())
 			return err
 		})
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		trustedServerCerts := make(map[string]dbCluster.Certificate)
@@ -281,7 +1010,7 @@ func patchClusteringServerCertTrust(name string, d *Daemon) error {
 		}
 
 		var members []db.NodeInfo
-		err = d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err = d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 			members, err = tx.GetNodes(ctx)
 			if err != nil {
 				return fmt.Errorf("Failed getting cluster members: %w", err)
@@ -290,29 +1019,25 @@ func patchClusteringServerCertTrust(name string, d *Daemon) error {
 			return nil
 		})
 		if err != nil {
-			return err
+			return false, err
 		}
 
-		missingCerts := false
 		for _, member := range members {
 			_, found := trustedServerCerts[member.Name]
 			if !found {
 				logger.Warnf("Missing trusted server certificate for cluster member %q", member.Name)
-				missingCerts = true
-				break
+				return false, nil
 			}
 		}
 
-		if missingCerts {
-			logger.Warnf("Waiting for %q patch to be applied on all cluster members", name)
-			time.Sleep(time.Second)
-			continue
-		}
-
-		logger.Infof("Trusted server certificates found in trust store for all cluster members")
-		break
+		return true, nil
+	})
+	if err != nil {
+		return err
 	}
 
+	logger.Infof("Trusted server certificates found in trust store for all cluster members")
+
 	// Now switch to using our server certificate for intra-cluster communication and load the trusted server
 	// certificates for the other members into the in-memory trusted cache.
 	logger.Infof("Set client certificate to server certificate %v", serverCert.Fingerprint())
@@ -331,7 +1056,7 @@ func patchNetworkACLRemoveDefaults(name string, d *Daemon) error {
 	var projectNames []string
 
 	// Get projects.
-	err = d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err = d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		projectNames, err = dbCluster.GetProjectNames(ctx, tx.Tx())
 		return err
 	})
@@ -339,7 +1064,7 @@ func patchNetworkACLRemoveDefaults(name string, d *Daemon) error {
 		return err
 	}
 
-	err = d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err = d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		// Get ACLs in projects.
 		for _, projectName := range projectNames {
 			aclNames, err := tx.GetNetworkACLs(ctx, projectName)
@@ -387,52 +1112,70 @@ func patchNetworkACLRemoveDefaults(name string, d *Daemon) error {
 	return nil
 }
 
+// patchVerifyNetworkACLRemoveDefaults reports the network ACLs still having default settings.
+func patchVerifyNetworkACLRemoveDefaults(name string, d *Daemon) ([]string, error) {
+	return patchVerifyQuery(d, "network_acl", `
+SELECT projects.name || "/" || networks_acls.name
+FROM networks_acls
+	JOIN projects ON projects.id = networks_acls.project_id
+WHERE networks_acls.id IN (
+	SELECT network_acl_id FROM networks_acls_config WHERE key IN ("default.action", "default.logged")
+)
+ORDER BY projects.name, networks_acls.name
+	`)
+}
+
 // patchDBNodesAutoInc re-creates the nodes table id column as AUTOINCREMENT.
 // Its done as a patch rather than a schema update so we can use PRAGMA foreign_keys = OFF without a transaction.
 func patchDBNodesAutoInc(name string, d *Daemon) error {
-	for {
+	// Only apply patch on leader, otherwise wait for it to be applied.
+	alreadyApplied := false
+	err := patchWait(d, name, "leader cluster member", func() (bool, error) {
 		// Only apply patch if schema needs it.
 		var schemaSQL string
 		row := d.State().DB.Cluster.DB().QueryRow("SELECT sql FROM sqlite_master WHERE name = 'nodes'")
 		err := row.Scan(&schemaSQL)
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		if strings.Contains(schemaSQL, "id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL") {
-			logger.Debugf(`Skipping %q patch as "nodes" table id column already AUTOINCREMENT`, name)
-			return nil // Nothing to do.
+			alreadyApplied = true
+			return true, nil
 		}
 
-		// Only apply patch on leader, otherwise wait for it to be applied.
 		var localConfig *node.Config
-		err = d.db.Node.Transaction(context.TODO(), func(ctx context.Context, tx *db.NodeTx) error {
+		err = d.db.Node.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.NodeTx) error {
 			localConfig, err = node.ConfigLoad(ctx, tx)
 			return err
 		})
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		leaderAddress, err := d.gateway.LeaderAddress()
 		if err != nil {
 			if errors.Is(err, cluster.ErrNodeIsNotClustered) {
-				break // Apply change on standalone node.
+				return true, nil // Apply change on standalone node.
 			}
 
-			return err
+			return false, err
 		}
 
-		if localConfig.ClusterAddress() == leaderAddress {
-			break // Apply change on leader node.
-		}
+		// Apply change on leader node.
+		return localConfig.ClusterAddress() == leaderAddress, nil
+	})
+	if err != nil {
+		return err
+	}
 
-		logger.Warnf("Waiting for %q patch to be applied on leader cluster member", name)
-		time.Sleep(time.Second)
+	if alreadyApplied {
+		logger.Debugf(`Skipping %q patch as "nodes" table id column already AUTOINCREMENT`, name)
+		return nil // Nothing to do.
 	}
 
 	// Apply patch.
-	_, err := d.State().DB.Cluster.DB().Exec(`
+	_, err = d.State().DB.Cluster.DB().Exec(`
 PRAGMA foreign_keys=OFF; -- So that integrity doesn't get in the way for now.
 PRAGMA legacy_alter_table = ON; -- So that views referencing this table don't block change.
 
@@ -471,7 +1214,7 @@ func patchVMRenameUUIDKey(name string, d *Daemon) error {
 
 	s := d.State()
 
-	return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	return s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
 			if inst.Type != instancetype.VM {
 				return nil
@@ -536,20 +1279,20 @@ func patchThinpoolTypoFix(name string, d *Daemon) error {
 	revert.Add(func() { _ = tx.Rollback() })
 
 	// Fetch the IDs of all existing nodes.
-	nodeIDs, err := query.SelectIntegers(context.TODO(), tx, "SELECT id FROM nodes")
+	nodeIDs, err := query.SelectIntegers(d.shutdownCtx, tx, "SELECT id FROM nodes")
 	if err != nil {
 		return fmt.Errorf("Failed to get IDs of current nodes: %w", err)
 	}
 
 	// Fetch the IDs of all existing lvm pools.
-	poolIDs, err := query.SelectIntegers(context.TODO(), tx, "SELECT id FROM storage_pools WHERE driver='lvm'")
+	poolIDs, err := query.SelectIntegers(d.shutdownCtx, tx, "SELECT id FROM storage_pools WHERE driver='lvm'")
 	if err != nil {
 		return fmt.Errorf("Failed to get IDs of current lvm pools: %w", err)
 	}
 
 	for _, poolID := range poolIDs {
 		// Fetch the config for this lvm pool and check if it has the lvm.thinpool_name.
-		config, err := query.SelectConfig(context.TODO(), tx, "storage_pools_config", "storage_pool_id=? AND node_id IS NULL", poolID)
+		config, err := query.SelectConfig(d.shutdownCtx, tx, "storage_pools_config", "storage_pool_id=? AND node_id IS NULL", poolID)
 		if err != nil {
 			return fmt.Errorf("Failed to fetch of lvm pool config: %w", err)
 		}
@@ -592,7 +1335,7 @@ INSERT INTO storage_pools_config(storage_pool_id, node_id, key, value)
 // This prevents outbound connectivity breaking on existing fan networks now that the default behaviour of not
 // having "ipv4.nat" set is to disable NAT (bringing in line with the non-fan bridge behavior and docs).
 func patchNetworkFANEnableNAT(name string, d *Daemon) error {
-	err := d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		projectNetworks, err := tx.GetCreatedNetworks(ctx)
 		if err != nil {
 			return err
@@ -640,7 +1383,7 @@ func patchNetworkFANEnableNAT(name string, d *Daemon) error {
 // patchNetworkOVNRemoveRoutes removes the "ipv4.routes.external" and "ipv6.routes.external" settings from OVN
 // networks. It was decided that the OVN NIC level equivalent settings were sufficient.
 func patchNetworkOVNRemoveRoutes(name string, d *Daemon) error {
-	err := d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		projectNetworks, err := tx.GetCreatedNetworks(ctx)
 		if err != nil {
 			return err
@@ -692,7 +1435,7 @@ func patchNetworkOVNRemoveRoutes(name string, d *Daemon) error {
 // the new NAT settings which default to disabled if not specified.
 // patchNetworkCearBridgeVolatileHwaddr removes the unsupported `volatile.bridge.hwaddr` config key from networks.
 func patchNetworkOVNEnableNAT(name string, d *Daemon) error {
-	err := d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		projectNetworks, err := tx.GetCreatedNetworks(ctx)
 		if err != nil {
 			return err
@@ -788,7 +1531,7 @@ func patchGenericNetwork(f func(name string, d *Daemon) error) func(name string,
 }
 
 func patchClusteringDropDatabaseRole(name string, d *Daemon) error {
-	return d.State().DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	return d.State().DB.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		members, err := tx.GetNodes(ctx)
 		if err != nil {
 			return fmt.Errorf("Failed getting cluster members: %w", err)
@@ -809,7 +1552,7 @@ func patchNetworkClearBridgeVolatileHwaddr(name string, d *Daemon) error {
 	// Use api.ProjectDefaultName, as bridge networks don't support projects.
 	projectName := api.ProjectDefaultName
 
-	err := d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		// Get the list of networks.
 		networks, err := tx.GetNetworks(ctx, projectName)
 		if err != nil {
@@ -853,7 +1596,7 @@ func patchZfsSetContentTypeUserProperty(name string, d *Daemon) error {
 
 	var pools []string
 
-	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
 
 		// Get all storage pool names.
@@ -900,7 +1643,7 @@ func patchZfsSetContentTypeUserProperty(name string, d *Daemon) error {
 		return err
 	}
 
-	for poolName, volumes := range customPoolVolumes {
+	return patchStoragePools(d, name, pools, func(poolName string) error {
 		// Load storage pool.
 		p, err := storagePools.LoadByName(s, poolName)
 		if err != nil {
@@ -908,10 +1651,10 @@ func patchZfsSetContentTypeUserProperty(name string, d *Daemon) error {
 		}
 
 		if p.Driver().Info().Name != "zfs" {
-			continue
+			return nil
 		}
 
-		for _, vol := range volumes {
+		for _, vol := range customPoolVolumes[poolName] {
 			// In a non-clusted environment ServerName will be empty.
 			if s.ServerName != "" && vol.Location != s.ServerName {
 				continue
@@ -929,9 +1672,9 @@ func patchZfsSetContentTypeUserProperty(name string, d *Daemon) error {
 				logger.Debug("Failed setting lxd:content_type property", logger.Ctx{"name": zfsVolName, "err": err})
 			}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
 // patchStorageZfsUnsetInvalidBlockSettings removes invalid block settings from volumes.
@@ -940,7 +1683,7 @@ func patchStorageZfsUnsetInvalidBlockSettings(_ string, d *Daemon) error {
 
 	var pools []string
 
-	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
 
 		// Get all storage pool names.
@@ -1037,7 +1780,7 @@ func patchStorageZfsUnsetInvalidBlockSettings(_ string, d *Daemon) error {
 				continue
 			}
 
-			err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			err = s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
 				return tx.UpdateStoragePoolVolume(ctx, vol.Project, vol.Name, volType, pool, vol.Description, config)
 			})
 			if err != nil {
@@ -1053,120 +1796,75 @@ func patchStorageZfsUnsetInvalidBlockSettings(_ string, d *Daemon) error {
 // This patch fixes the previous one.
 // - Handle non-clusted environments correctly.
 // - Always remove block.* settings from VMs.
-func patchStorageZfsUnsetInvalidBlockSettingsV2(_ string, d *Daemon) error {
+func patchStorageZfsUnsetInvalidBlockSettingsV2(name string, d *Daemon) error {
 	s := d.State()
 
-	var pools []string
-
-	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		var err error
+	filter := patchVolumeFilter{
+		Drivers: []string{"zfs"},
+		Types:   []string{dbCluster.StoragePoolVolumeTypeNameCustom, dbCluster.StoragePoolVolumeTypeNameVM},
+	}
 
-		// Get all storage pool names.
-		pools, err = tx.GetStoragePoolNames(ctx)
+	return patchStorageVolumes(d, name, filter, true, func(pool storagePools.Pool, vol *db.StorageVolume) error {
+		config := vol.Config
 
-		return err
-	})
-	if err != nil {
-		// Skip the rest of the patch if no storage pools were found.
-		if api.StatusErrorCheck(err, http.StatusNotFound) {
+		// Only check zfs.block_mode for custom volumes. VMs should never have any block.* settings
+		// regardless of the zfs.block_mode setting.
+		if shared.IsTrue(config["zfs.block_mode"]) && vol.Type == dbCluster.StoragePoolVolumeTypeNameCustom {
 			return nil
 		}
 
-		return fmt.Errorf("Failed getting storage pool names: %w", err)
-	}
-
-	volTypeCustom := dbCluster.StoragePoolVolumeTypeCustom
-	volTypeVM := dbCluster.StoragePoolVolumeTypeVM
-
-	poolIDNameMap := make(map[int64]string, 0)
-	poolVolumes := make(map[int64][]*db.StorageVolume, 0)
-
-	err = s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
-		for _, pool := range pools {
-			// Get storage pool ID.
-			poolID, err := tx.GetStoragePoolID(ctx, pool)
-			if err != nil {
-				return fmt.Errorf("Failed getting storage pool ID of pool %q: %w", pool, err)
-			}
-
-			driverName, err := tx.GetStoragePoolDriver(ctx, poolID)
-			if err != nil {
-				return fmt.Errorf("Failed getting storage pool driver of pool %q: %w", pool, err)
-			}
-
-			if driverName != "zfs" {
-				continue
+		update := false
+		for _, k := range []string{"block.filesystem", "block.mount_options"} {
+			_, found := config[k]
+			if found {
+				delete(config, k)
+				update = true
 			}
+		}
 
-			// Get the pool's custom storage volumes.
-			volumes, err := tx.GetStorageVolumes(ctx, false, db.StorageVolumeFilter{Type: &volTypeCustom, PoolID: &poolID}, db.StorageVolumeFilter{Type: &volTypeVM, PoolID: &poolID})
-			if err != nil {
-				return fmt.Errorf("Failed getting custom storage volumes of pool %q: %w", pool, err)
-			}
+		if !update {
+			return nil
+		}
 
-			if poolVolumes[poolID] == nil {
-				poolVolumes[poolID] = []*db.StorageVolume{}
-			}
+		volType, err := storagePools.VolumeTypeNameToDBType(vol.Type)
+		if err != nil {
+			return err
+		}
 
-			poolIDNameMap[poolID] = pool
-			poolVolumes[poolID] = append(poolVolumes[poolID], volumes...)
+		err = s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateStoragePoolVolume(ctx, vol.Project, vol.Name, volType, pool.ID(), vol.Description, config)
+		})
+		if err != nil {
+			return err
 		}
 
+		d.patchesStatus.modified(name, "storage_volume", 1)
+
 		return nil
 	})
-	if err != nil {
-		return err
-	}
-
-	var volType int
-
-	for pool, volumes := range poolVolumes {
-		for _, vol := range volumes {
-			// In a non-clusted environment ServerName will be empty.
-			if s.ServerName != "" && vol.Location != s.ServerName {
-				continue
-			}
-
-			config := vol.Config
-
-			// Only check zfs.block_mode for custom volumes. VMs should never have any block.* settings
-			// regardless of the zfs.block_mode setting.
-			if shared.IsTrue(config["zfs.block_mode"]) && vol.Type == dbCluster.StoragePoolVolumeTypeNameCustom {
-				continue
-			}
-
-			update := false
-			for _, k := range []string{"block.filesystem", "block.mount_options"} {
-				_, found := config[k]
-				if found {
-					delete(config, k)
-					update = true
-				}
-			}
-
-			if !update {
-				continue
-			}
-
-			if vol.Type == dbCluster.StoragePoolVolumeTypeNameVM {
-				volType = volTypeVM
-			} else if vol.Type == dbCluster.StoragePoolVolumeTypeNameCustom {
-				volType = volTypeCustom
-			} else {
-				// Should not happen.
-				continue
-			}
-
-			err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-				return tx.UpdateStoragePoolVolume(ctx, vol.Project, vol.Name, volType, pool, vol.Description, config)
-			})
-			if err != nil {
-				return fmt.Errorf("Failed updating volume %q in project %q on pool %q: %w", vol.Name, vol.Project, poolIDNameMap[pool], err)
-			}
-		}
-	}
+}
 
-	return nil
+// patchVerifyStorageZfsUnsetInvalidBlockSettings reports the ZFS volumes still having invalid block settings, using
+// the same conditions as patchStorageZfsUnsetInvalidBlockSettingsV2.
+func patchVerifyStorageZfsUnsetInvalidBlockSettings(name string, d *Daemon) ([]string, error) {
+	return patchVerifyQuery(d, "storage_volume", `
+SELECT projects.name || "/" || storage_pools.name || "/" || storage_volumes.name
+FROM storage_volumes
+	JOIN storage_pools ON storage_pools.id = storage_volumes.storage_pool_id
+	JOIN projects ON projects.id = storage_volumes.project_id
+WHERE storage_pools.driver = "zfs"
+AND (
+	storage_volumes.type = ?
+	OR (storage_volumes.type = ? AND storage_volumes.id NOT IN (
+		SELECT storage_volume_id FROM storage_volumes_config
+		WHERE key = "zfs.block_mode" AND LOWER(value) IN ("1", "on", "yes", "true")
+	))
+)
+AND storage_volumes.id IN (
+	SELECT storage_volume_id FROM storage_volumes_config WHERE key IN ("block.filesystem", "block.mount_options")
+)
+ORDER BY projects.name, storage_pools.name, storage_volumes.name
+	`, dbCluster.StoragePoolVolumeTypeVM, dbCluster.StoragePoolVolumeTypeCustom)
 }
 
 // patchStorageUnsetInvalidBlockSettings removes invalid block settings from LVM and Ceph RBD volumes.
@@ -1176,23 +1874,31 @@ func patchStorageUnsetInvalidBlockSettings(_ string, d *Daemon) error {
 	return nil
 }
 
+// patchCandidRBACConfigKeys are the Candid and RBAC related config keys removed by patchRemoveCandidRBACConfigKeys.
+var patchCandidRBACConfigKeys = []string{
+	"candid.api.url",
+	"candid.api.key",
+	"candid.expiry",
+	"candid.domains",
+	"rbac.api.url",
+	"rbac.api.key",
+	"rbac.expiry",
+	"rbac.agent.url",
+	"rbac.agent.username",
+	"rbac.agent.private_key",
+	"rbac.agent.public_key",
+}
+
 // patchRemoveCandidRBACConfigKeys removes all Candid and RBAC related configuration from the database.
 func patchRemoveCandidRBACConfigKeys(_ string, d *Daemon) error {
+	config := make(map[string]string, len(patchCandidRBACConfigKeys))
+	for _, key := range patchCandidRBACConfigKeys {
+		config[key] = ""
+	}
+
 	s := d.State()
 	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
-		return tx.UpdateClusterConfig(map[string]string{
-			"candid.api.url":         "",
-			"candid.api.key":         "",
-			"candid.expiry":          "",
-			"candid.domains":         "",
-			"rbac.api.url":           "",
-			"rbac.api.key":           "",
-			"rbac.expiry":            "",
-			"rbac.agent.url":         "",
-			"rbac.agent.username":    "",
-			"rbac.agent.private_key": "",
-			"rbac.agent.public_key":  "",
-		})
+		return tx.UpdateClusterConfig(config)
 	})
 	if err != nil {
 		return fmt.Errorf("Failed to remove RBAC and Candid configuration keys: %w", err)
@@ -1209,7 +1915,7 @@ func patchStorageSetVolumeUUID(_ string, d *Daemon) error {
 }
 
 // patchStorageSetVolumeUUIDV2 sets a unique volatile.uuid field for each volume and its snapshots using an idempotent SQL query.
-func patchStorageSetVolumeUUIDV2(_ string, d *Daemon) error {
+func patchStorageSetVolumeUUIDV2(name string, d *Daemon) error {
 	type volumeConfigEntry struct {
 		id    string
 		value *string
@@ -1260,12 +1966,18 @@ INSERT OR IGNORE INTO %s (%s, key, value) VALUES (?, "volatile.uuid", ?)
 	}
 
 	// Set a new "volatile.uuid" for all volumes which are missing the config key.
-	for _, volumeUUID := range volumeUUIDs {
+	for i, volumeUUID := range volumeUUIDs {
+		if i%1000 == 0 {
+			d.patchesStatus.progress(name, fmt.Sprintf("Volumes: %d/%d", i, len(volumeUUIDs)))
+		}
+
 		if volumeUUID.value == nil {
 			err := setVolumeUUID(volumeUUID.id, "storage_volumes_config", "storage_volume_id")
 			if err != nil {
 				return err
 			}
+
+			d.patchesStatus.modified(name, "storage_volume", 1)
 		}
 	}
 
@@ -1276,12 +1988,18 @@ INSERT OR IGNORE INTO %s (%s, key, value) VALUES (?, "volatile.uuid", ?)
 	}
 
 	// Set a new "volatile.uuid" for all volumes which are missing the config key.
-	for _, volumeSnapshotUUID := range volumeSnapshotsUUIDs {
+	for i, volumeSnapshotUUID := range volumeSnapshotsUUIDs {
+		if i%1000 == 0 {
+			d.patchesStatus.progress(name, fmt.Sprintf("Volume snapshots: %d/%d", i, len(volumeSnapshotsUUIDs)))
+		}
+
 		if volumeSnapshotUUID.value == nil {
 			err := setVolumeUUID(volumeSnapshotUUID.id, "storage_volumes_snapshots_config", "storage_volume_snapshot_id")
 			if err != nil {
 				return err
 			}
+
+			d.patchesStatus.modified(name, "storage_volume_snapshot", 1)
 		}
 	}
 
@@ -1298,119 +2016,103 @@ INSERT OR IGNORE INTO %s (%s, key, value) VALUES (?, "volatile.uuid", ?)
 			if err != nil {
 				return err
 			}
+
+			d.patchesStatus.modified(name, "storage_bucket", 1)
 		}
 	}
 
 	return nil
 }
 
-// patchStorageRenameCustomISOBlockVolumesV2 renames existing custom ISO volumes by adding the ".iso" suffix so they can be distinguished from regular custom block volumes.
-// This patch doesn't use the patchGenericStorage function because the storage drivers themselves aren't aware of custom ISO volumes.
-func patchStorageRenameCustomISOBlockVolumesV2(name string, d *Daemon) error {
-	s := d.State()
-
-	var pools []string
-
-	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		var err error
-
-		// Get all storage pool names.
-		pools, err = tx.GetStoragePoolNames(ctx)
-
-		return err
-	})
+// patchVerifyStorageSetVolumeUUID reports the storage volumes, snapshots and buckets still missing volatile.uuid.
+func patchVerifyStorageSetVolumeUUID(name string, d *Daemon) ([]string, error) {
+	volumes, err := patchVerifyQuery(d, "storage_volume", `
+SELECT projects.name || "/" || storage_pools.name || "/" || storage_volumes.name
+FROM storage_volumes
+	JOIN storage_pools ON storage_pools.id = storage_volumes.storage_pool_id
+	JOIN projects ON projects.id = storage_volumes.project_id
+WHERE storage_volumes.id NOT IN (
+	SELECT storage_volume_id FROM storage_volumes_config WHERE key = "volatile.uuid"
+)
+ORDER BY projects.name, storage_pools.name, storage_volumes.name
+	`)
 	if err != nil {
-		// Skip the rest of the patch if no storage pools were found.
-		if api.StatusErrorCheck(err, http.StatusNotFound) {
-			return nil
-		}
-
-		return fmt.Errorf("Failed getting storage pool names: %w", err)
+		return nil, err
 	}
 
-	volTypeCustom := dbCluster.StoragePoolVolumeTypeCustom
-	customPoolVolumes := make(map[string][]*db.StorageVolume, 0)
-
-	err = s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
-		for _, pool := range pools {
-			// Get storage pool ID.
-			poolID, err := tx.GetStoragePoolID(ctx, pool)
-			if err != nil {
-				return fmt.Errorf("Failed getting storage pool ID of pool %q: %w", pool, err)
-			}
-
-			// Get the pool's custom storage volumes.
-			customVolumes, err := tx.GetStorageVolumes(ctx, false, db.StorageVolumeFilter{Type: &volTypeCustom, PoolID: &poolID})
-			if err != nil {
-				return fmt.Errorf("Failed getting custom storage volumes of pool %q: %w", pool, err)
-			}
-
-			if customPoolVolumes[pool] == nil {
-				customPoolVolumes[pool] = []*db.StorageVolume{}
-			}
-
-			customPoolVolumes[pool] = append(customPoolVolumes[pool], customVolumes...)
-		}
-
-		return nil
-	})
+	snapshots, err := patchVerifyQuery(d, "storage_volume_snapshot", `
+SELECT projects.name || "/" || storage_pools.name || "/" || storage_volumes.name || "/" || storage_volumes_snapshots.name
+FROM storage_volumes_snapshots
+	JOIN storage_volumes ON storage_volumes.id = storage_volumes_snapshots.storage_volume_id
+	JOIN storage_pools ON storage_pools.id = storage_volumes.storage_pool_id
+	JOIN projects ON projects.id = storage_volumes.project_id
+WHERE storage_volumes_snapshots.id NOT IN (
+	SELECT storage_volume_snapshot_id FROM storage_volumes_snapshots_config WHERE key = "volatile.uuid"
+)
+ORDER BY projects.name, storage_pools.name, storage_volumes.name, storage_volumes_snapshots.name
+	`)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	isSelectedPatchMember, err := selectedPatchClusterMember(d)
+	buckets, err := patchVerifyQuery(d, "storage_bucket", `
+SELECT projects.name || "/" || storage_pools.name || "/" || storage_buckets.name
+FROM storage_buckets
+	JOIN storage_pools ON storage_pools.id = storage_buckets.storage_pool_id
+	JOIN projects ON projects.id = storage_buckets.project_id
+WHERE storage_buckets.id NOT IN (
+	SELECT storage_bucket_id FROM storage_buckets_config WHERE key = "volatile.uuid"
+)
+ORDER BY projects.name, storage_pools.name, storage_buckets.name
+	`)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for poolName, volumes := range customPoolVolumes {
-		// Load storage pool.
-		p, err := storagePools.LoadByName(s, poolName)
-		if err != nil {
-			return fmt.Errorf("Failed loading pool %q: %w", poolName, err)
-		}
-
-		// Ensure the renaming is done only on the selected patch cluster member for remote storage pools.
-		if p.Driver().Info().Remote && !isSelectedPatchMember {
-			continue
-		}
+	entities := make([]string, 0, len(volumes)+len(snapshots)+len(buckets))
+	entities = append(entities, volumes...)
+	entities = append(entities, snapshots...)
+	entities = append(entities, buckets...)
 
-		for _, vol := range volumes {
-			// In a non-clusted environment ServerName will be empty.
-			if s.ServerName != "" && vol.Location != s.ServerName {
-				continue
-			}
+	return entities, nil
+}
 
-			// Exclude non-ISO custom volumes.
-			if vol.ContentType != dbCluster.StoragePoolVolumeContentTypeNameISO {
-				continue
-			}
+// patchStorageRenameCustomISOBlockVolumesV2 renames existing custom ISO volumes by adding the ".iso" suffix so they can be distinguished from regular custom block volumes.
+// This patch doesn't use the patchGenericStorage function because the storage drivers themselves aren't aware of custom ISO volumes.
+func patchStorageRenameCustomISOBlockVolumesV2(name string, d *Daemon) error {
+	filter := patchVolumeFilter{
+		Types:        []string{dbCluster.StoragePoolVolumeTypeNameCustom},
+		ContentTypes: []string{dbCluster.StoragePoolVolumeContentTypeNameISO},
+	}
 
-			// The existing volume using the actual *.iso suffix has ContentTypeISO.
-			existingVol := storageDrivers.NewVolume(p.Driver(), p.Name(), storageDrivers.VolumeTypeCustom, storageDrivers.ContentTypeISO, project.StorageVolume(vol.Project, vol.Name), nil, nil)
+	// Ensure the renaming is done only on the selected patch cluster member for remote storage pools.
+	return patchStorageVolumes(d, name, filter, true, func(p storagePools.Pool, vol *db.StorageVolume) error {
+		// The existing volume using the actual *.iso suffix has ContentTypeISO.
+		existingVol := storageDrivers.NewVolume(p.Driver(), p.Name(), storageDrivers.VolumeTypeCustom, storageDrivers.ContentTypeISO, project.StorageVolume(vol.Project, vol.Name), nil, nil)
 
-			hasVol, err := p.Driver().HasVolume(existingVol)
-			if err != nil {
-				return fmt.Errorf("Failed to check if volume %q exists in pool %q: %w", existingVol.Name(), p.Name(), err)
-			}
+		hasVol, err := p.Driver().HasVolume(existingVol)
+		if err != nil {
+			return fmt.Errorf("Failed to check if volume %q exists in pool %q: %w", existingVol.Name(), p.Name(), err)
+		}
 
-			// patchStorageRenameCustomISOBlockVolumes might have already set the *.iso suffix.
-			// Check if the storage volume isn't already renamed.
-			if hasVol {
-				continue
-			}
+		// patchStorageRenameCustomISOBlockVolumes might have already set the *.iso suffix.
+		// Check if the storage volume isn't already renamed.
+		if hasVol {
+			return nil
+		}
 
-			// We need to use ContentTypeBlock here in order for the driver to figure out the correct (old) location.
-			oldVol := storageDrivers.NewVolume(p.Driver(), p.Name(), storageDrivers.VolumeTypeCustom, storageDrivers.ContentTypeBlock, project.StorageVolume(vol.Project, vol.Name), nil, nil)
+		// We need to use ContentTypeBlock here in order for the driver to figure out the correct (old) location.
+		oldVol := storageDrivers.NewVolume(p.Driver(), p.Name(), storageDrivers.VolumeTypeCustom, storageDrivers.ContentTypeBlock, project.StorageVolume(vol.Project, vol.Name), nil, nil)
 
-			err = p.Driver().RenameVolume(oldVol, fmt.Sprintf("%s.iso", oldVol.Name()), nil)
-			if err != nil {
-				return fmt.Errorf("Failed to rename volume %q in pool %q: %w", oldVol.Name(), p.Name(), err)
-			}
+		err = p.Driver().RenameVolume(oldVol, fmt.Sprintf("%s.iso", oldVol.Name()), nil)
+		if err != nil {
+			return fmt.Errorf("Failed to rename volume %q in pool %q: %w", oldVol.Name(), p.Name(), err)
 		}
-	}
 
-	return nil
+		d.patchesStatus.modified(name, "storage_volume", 1)
+
+		return nil
+	})
 }
 
 // patchStorageUnsetInvalidBlockSettingsV2 removes invalid block settings from LVM and Ceph RBD volumes.
@@ -1432,6 +2134,24 @@ DELETE FROM storage_volumes_config
 	return err
 }
 
+// patchVerifyStorageUnsetInvalidBlockSettings reports the LVM and Ceph RBD volumes still having invalid block
+// settings, using the same conditions as patchStorageUnsetInvalidBlockSettingsV2.
+func patchVerifyStorageUnsetInvalidBlockSettings(name string, d *Daemon) ([]string, error) {
+	return patchVerifyQuery(d, "storage_volume", `
+SELECT projects.name || "/" || storage_pools.name || "/" || storage_volumes.name
+FROM storage_volumes
+	JOIN storage_pools ON storage_pools.id = storage_volumes.storage_pool_id
+	JOIN projects ON projects.id = storage_volumes.project_id
+WHERE storage_volumes.type = ?
+AND storage_volumes.content_type = ?
+AND storage_pools.driver IN ("lvm", "ceph")
+AND storage_volumes.id IN (
+	SELECT storage_volume_id FROM storage_volumes_config WHERE key IN ("block.filesystem", "block.mount_options")
+)
+ORDER BY projects.name, storage_pools.name, storage_volumes.name
+	`, dbCluster.StoragePoolVolumeTypeCustom, dbCluster.StoragePoolVolumeContentTypeBlock)
+}
+
 // patchRemoveCoreTrustPassword removes the core.trust_password config key from the cluster.
 func patchRemoveCoreTrustPassword(_ string, d *Daemon) error {
 	s := d.State()
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	clusterConfig "github.com/canonical/lxd/lxd/cluster/config"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/node"
+)
+
+// newPatchTestDaemon returns a minimal daemon backed by fresh in-memory node and cluster databases, which is
+// enough to run individual patches against a seeded dataset. The daemon isn't started, so patches relying on
+// storage drivers, networks or the rest of the cluster can't be tested this way.
+//
+// The cluster database has the default project and a single cluster member with ID 1, like a freshly
+// bootstrapped non-clustered server.
+func newPatchTestDaemon(t *testing.T) *Daemon {
+	t.Helper()
+
+	d := defaultDaemon()
+	d.os.MockMode = true
+	t.Cleanup(d.shutdownCancel)
+
+	nodeDB, nodeCleanup := db.NewTestNode(t)
+	t.Cleanup(nodeCleanup)
+
+	clusterDB, clusterCleanup := db.NewTestCluster(t)
+	t.Cleanup(clusterCleanup)
+
+	d.db.Node = nodeDB
+	d.db.Cluster = clusterDB
+
+	var err error
+	err = d.db.Node.Transaction(context.Background(), func(ctx context.Context, tx *db.NodeTx) error {
+		d.localConfig, err = node.ConfigLoad(ctx, tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	err = d.db.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+		d.globalConfig, err = clusterConfig.Load(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		d.serverName, err = tx.GetLocalNodeName(ctx)
+		return err
+	})
+	require.NoError(t, err)
+
+	return d
+}
+
+// patchTestSeedCluster runs the given statements against the cluster database of the daemon, in order to
+// reproduce the dataset a patch is expected to fix.
+func patchTestSeedCluster(t *testing.T, d *Daemon, stmts ...string) {
+	t.Helper()
+
+	err := d.db.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+		for _, stmt := range stmts {
+			_, err := tx.Tx().ExecContext(ctx, stmt)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// patchTestSeedNode runs the given statements against the node database of the daemon.
+func patchTestSeedNode(t *testing.T, d *Daemon, stmts ...string) {
+	t.Helper()
+
+	for _, stmt := range stmts {
+		_, err := d.db.Node.DB().Exec(stmt)
+		require.NoError(t, err)
+	}
+}
+
+// patchTestApply applies the patch with the given name to the daemon, exactly like it is applied on startup,
+// and returns its error.
+func patchTestApply(t *testing.T, d *Daemon, name string) error {
+	t.Helper()
+
+	for _, p := range patches {
+		if p.name == name {
+			return p.apply(d)
+		}
+	}
+
+	t.Fatalf("Unknown patch %q", name)
+
+	return nil
+}
+
+// patchTestQueryStrings returns the first column of the rows returned by the given query against the cluster
+// database of the daemon.
+func patchTestQueryStrings(t *testing.T, d *Daemon, q string, args ...any) []string {
+	t.Helper()
+
+	var values []string
+	err := d.db.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+		rows, err := tx.Tx().QueryContext(ctx, q, args...)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = rows.Close() }()
+
+		for rows.Next() {
+			var value string
+			err := rows.Scan(&value)
+			if err != nil {
+				return err
+			}
+
+			values = append(values, value)
+		}
+
+		return rows.Err()
+	})
+	require.NoError(t, err)
+
+	return values
+}
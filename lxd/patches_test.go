@@ -0,0 +1,194 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/metrics"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+)
+
+func TestPatchNetworkACLRemoveDefaults(t *testing.T) {
+	d := newPatchTestDaemon(t)
+
+	patchTestSeedCluster(t, d,
+		`INSERT INTO networks_acls (id, project_id, name, description, ingress, egress) VALUES (1, 1, 'acl1', '', '[]', '[]')`,
+		`INSERT INTO networks_acls_config (network_acl_id, key, value) VALUES (1, 'default.action', 'drop')`,
+		`INSERT INTO networks_acls_config (network_acl_id, key, value) VALUES (1, 'default.logged', 'true')`,
+		`INSERT INTO networks_acls_config (network_acl_id, key, value) VALUES (1, 'user.foo', 'bar')`,
+	)
+
+	err := patchTestApply(t, d, "network_acl_remove_defaults")
+	require.NoError(t, err)
+
+	keys := patchTestQueryStrings(t, d, `SELECT key FROM networks_acls_config WHERE network_acl_id = 1 ORDER BY key`)
+	assert.Equal(t, []string{"user.foo"}, keys)
+}
+
+func TestPatchSkip(t *testing.T) {
+	d := newPatchTestDaemon(t)
+
+	patchTestSeedCluster(t, d,
+		`INSERT INTO networks_acls (id, project_id, name, description, ingress, egress) VALUES (1, 1, 'acl1', '', '[]', '[]')`,
+		`INSERT INTO networks_acls_config (network_acl_id, key, value) VALUES (1, 'default.action', 'drop')`,
+	)
+
+	for _, p := range patches {
+		if p.name == "network_acl_remove_defaults" {
+			err := p.skip(d)
+			require.NoError(t, err)
+		}
+	}
+
+	// The patch is marked as applied, but didn't run.
+	applied, err := d.db.Node.GetAppliedPatches()
+	require.NoError(t, err)
+	assert.Contains(t, applied, "network_acl_remove_defaults")
+
+	keys := patchTestQueryStrings(t, d, `SELECT key FROM networks_acls_config WHERE network_acl_id = 1`)
+	assert.Equal(t, []string{"default.action"}, keys)
+
+	status := d.patchesStatus.get("network_acl_remove_defaults")
+	require.NotNil(t, status)
+	assert.Equal(t, patchStatusSkipped, status.Status)
+}
+
+func TestPatchStorageZfsUnsetInvalidBlockSettingsV2(t *testing.T) {
+	d := newPatchTestDaemon(t)
+
+	// A ZFS pool with a filesystem custom volume, a block mode custom volume and a VM volume, all carrying
+	// block settings, and a LVM pool whose block settings must be left alone.
+	patchTestSeedCluster(t, d,
+		`INSERT INTO storage_pools (id, name, driver, description, state) VALUES (1, 'zfs', 'zfs', '', 1)`,
+		`INSERT INTO storage_pools (id, name, driver, description, state) VALUES (2, 'lvm', 'lvm', '', 1)`,
+		`INSERT INTO storage_pools_nodes (storage_pool_id, node_id, state) VALUES (1, 1, 1)`,
+		`INSERT INTO storage_pools_nodes (storage_pool_id, node_id, state) VALUES (2, 1, 1)`,
+		`INSERT INTO storage_volumes (id, name, storage_pool_id, node_id, type, description, project_id, content_type) VALUES (1, 'fs', 1, 1, 2, '', 1, 0)`,
+		`INSERT INTO storage_volumes (id, name, storage_pool_id, node_id, type, description, project_id, content_type) VALUES (2, 'block', 1, 1, 2, '', 1, 0)`,
+		`INSERT INTO storage_volumes (id, name, storage_pool_id, node_id, type, description, project_id, content_type) VALUES (3, 'vm', 1, 1, 3, '', 1, 1)`,
+		`INSERT INTO storage_volumes (id, name, storage_pool_id, node_id, type, description, project_id, content_type) VALUES (4, 'lvm', 2, 1, 2, '', 1, 0)`,
+		`INSERT INTO storage_volumes_config (storage_volume_id, key, value) VALUES (1, 'block.filesystem', 'ext4')`,
+		`INSERT INTO storage_volumes_config (storage_volume_id, key, value) VALUES (1, 'block.mount_options', 'discard')`,
+		`INSERT INTO storage_volumes_config (storage_volume_id, key, value) VALUES (1, 'size', '10GiB')`,
+		`INSERT INTO storage_volumes_config (storage_volume_id, key, value) VALUES (2, 'block.filesystem', 'ext4')`,
+		`INSERT INTO storage_volumes_config (storage_volume_id, key, value) VALUES (2, 'zfs.block_mode', 'true')`,
+		`INSERT INTO storage_volumes_config (storage_volume_id, key, value) VALUES (3, 'block.filesystem', 'ext4')`,
+		`INSERT INTO storage_volumes_config (storage_volume_id, key, value) VALUES (3, 'zfs.block_mode', 'true')`,
+		`INSERT INTO storage_volumes_config (storage_volume_id, key, value) VALUES (4, 'block.filesystem', 'ext4')`,
+	)
+
+	err := patchTestApply(t, d, "storage_zfs_unset_invalid_block_settings_v2")
+	require.NoError(t, err)
+
+	q := `SELECT key FROM storage_volumes_config WHERE storage_volume_id = ? ORDER BY key`
+	assert.Equal(t, []string{"size"}, patchTestQueryStrings(t, d, q, 1))
+	assert.Equal(t, []string{"block.filesystem", "zfs.block_mode"}, patchTestQueryStrings(t, d, q, 2))
+	assert.Equal(t, []string{"zfs.block_mode"}, patchTestQueryStrings(t, d, q, 3))
+	assert.Equal(t, []string{"block.filesystem"}, patchTestQueryStrings(t, d, q, 4))
+}
+
+func TestPatchStorageVolumes(t *testing.T) {
+	d := newPatchTestDaemon(t)
+
+	// A local pool with a custom volume, its snapshot and a VM volume, and a remote pool with a custom volume.
+	patchTestSeedCluster(t, d,
+		`INSERT INTO storage_pools (id, name, driver, description, state) VALUES (1, 'local', 'zfs', '', 1)`,
+		`INSERT INTO storage_pools (id, name, driver, description, state) VALUES (2, 'remote', 'ceph', '', 1)`,
+		`INSERT INTO storage_pools_nodes (storage_pool_id, node_id, state) VALUES (1, 1, 1)`,
+		`INSERT INTO storage_pools_nodes (storage_pool_id, node_id, state) VALUES (2, 1, 1)`,
+		`INSERT INTO storage_volumes (id, name, storage_pool_id, node_id, type, description, project_id, content_type) VALUES (1, 'vol1', 1, 1, 2, '', 1, 0)`,
+		`INSERT INTO storage_volumes_snapshots (id, storage_volume_id, name, description) VALUES (2, 1, 'snap0', '')`,
+		`INSERT INTO storage_volumes (id, name, storage_pool_id, node_id, type, description, project_id, content_type) VALUES (3, 'vm1', 1, 1, 3, '', 1, 1)`,
+		`INSERT INTO storage_volumes (id, name, storage_pool_id, node_id, type, description, project_id, content_type) VALUES (4, 'vol2', 2, NULL, 2, '', 1, 0)`,
+	)
+
+	volumes := func(filter patchVolumeFilter) []string {
+		var mu sync.Mutex
+		var names []string
+
+		err := patchStorageVolumes(d, "test", filter, true, func(pool storagePools.Pool, vol *db.StorageVolume) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			names = append(names, vol.Pool+"/"+vol.Name)
+			return nil
+		})
+		require.NoError(t, err)
+
+		sort.Strings(names)
+		return names
+	}
+
+	assert.Equal(t, []string{"local/vm1", "local/vol1", "remote/vol2"}, volumes(patchVolumeFilter{}))
+	assert.Equal(t, []string{"local/vm1", "local/vol1", "local/vol1/snap0", "remote/vol2"}, volumes(patchVolumeFilter{Snapshots: true}))
+	assert.Equal(t, []string{"local/vol1", "remote/vol2"}, volumes(patchVolumeFilter{Types: []string{dbCluster.StoragePoolVolumeTypeNameCustom}}))
+	assert.Equal(t, []string{"local/vm1"}, volumes(patchVolumeFilter{ContentTypes: []string{dbCluster.StoragePoolVolumeContentTypeNameBlock}}))
+	assert.Equal(t, []string{"remote/vol2"}, volumes(patchVolumeFilter{Drivers: []string{"ceph"}}))
+}
+
+func TestPatchesVerify(t *testing.T) {
+	d := newPatchTestDaemon(t)
+
+	// A volume, a snapshot and a bucket missing volatile.uuid, and a volume which has one.
+	patchTestSeedCluster(t, d,
+		`INSERT INTO storage_pools (id, name, driver, description, state) VALUES (1, 'pool', 'dir', '', 1)`,
+		`INSERT INTO storage_pools_nodes (storage_pool_id, node_id, state) VALUES (1, 1, 1)`,
+		`INSERT INTO storage_volumes (id, name, storage_pool_id, node_id, type, description, project_id, content_type) VALUES (1, 'vol1', 1, 1, 2, '', 1, 0)`,
+		`INSERT INTO storage_volumes (id, name, storage_pool_id, node_id, type, description, project_id, content_type) VALUES (2, 'vol2', 1, 1, 2, '', 1, 0)`,
+		`INSERT INTO storage_volumes_config (storage_volume_id, key, value) VALUES (2, 'volatile.uuid', 'c6a04aa8-7a5c-4a2b-a9e4-2f0c1b1d8e3f')`,
+		`INSERT INTO storage_volumes_snapshots (id, storage_volume_id, name, description) VALUES (3, 2, 'snap0', '')`,
+		`INSERT INTO storage_buckets (id, name, storage_pool_id, node_id, description, project_id) VALUES (1, 'bucket1', 1, 1, '', 1)`,
+	)
+
+	// Patches that aren't applied aren't verified.
+	results, err := patchesVerify(d)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// A patch marked as applied without running reports the entities it should have fixed.
+	for _, p := range patches {
+		if p.name == "storage_set_volume_uuid_v2" {
+			err := p.skip(d)
+			require.NoError(t, err)
+		}
+	}
+
+	results, err = patchesVerify(d)
+	require.NoError(t, err)
+	assert.Equal(t, []internalPatchVerifyResult{{
+		Name:     "storage_set_volume_uuid_v2",
+		Entities: []string{"storage_volume default/pool/vol1", "storage_volume_snapshot default/pool/vol2/snap0", "storage_bucket default/pool/bucket1"},
+	}}, results)
+
+	// Once the patch is applied, nothing is left to fix.
+	err = patchTestApply(t, d, "storage_set_volume_uuid_v2")
+	require.NoError(t, err)
+
+	results, err = patchesVerify(d)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestPatchesMetrics(t *testing.T) {
+	s := &patchesStatus{}
+	s.queue(patchPreDaemonStorage, []string{"applied", "waiting", "pending"})
+	s.start("applied", patchWaitPolicy{})
+	s.finish("applied", nil)
+	s.start("waiting", patchWaitPolicy{})
+
+	out := metrics.NewMetricSet(nil)
+	patchesMetrics(out, s.list())
+
+	text := out.String()
+	assert.Contains(t, text, "lxd_patches_applied_total 1\n")
+	assert.Contains(t, text, "lxd_patches_pending 2\n")
+	assert.Contains(t, text, `lxd_patch_duration_seconds{name="applied",status="applied"}`)
+	assert.Contains(t, text, `lxd_patch_duration_seconds{name="waiting",status="running"}`)
+	assert.NotContains(t, text, `name="pending"`)
+}
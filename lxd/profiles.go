@@ -526,7 +526,15 @@ func profilePut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	err = authApprovalCheckConfig(r.Context(), s, r, entity.ProfileURL(p.Name, name), profile.Config, profile.Devices, req.Config, req.Devices)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	err = doProfileUpdate(s, *p, name, id, profile, req)
+	if err == nil {
+		configHistoryRecordOrWarn(s, r, entity.TypeProfile, int(id), profileConfigRevision(profile.Writable()), profileConfigRevision(req))
+	}
 
 	if err == nil && !isClusterNotification(r) {
 		// Notify all other nodes. If a node is down, it will be ignored.
@@ -675,10 +683,22 @@ func profilePatch(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	err = authApprovalCheckConfig(r.Context(), s, r, entity.ProfileURL(p.Name, name), profile.Config, profile.Devices, req.Config, req.Devices)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(p.Name, lifecycle.ProfileUpdated.Event(name, p.Name, requestor, nil))
 
-	return response.SmartError(doProfileUpdate(s, *p, name, id, profile, req))
+	err = doProfileUpdate(s, *p, name, id, profile, req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	configHistoryRecordOrWarn(s, r, entity.TypeProfile, int(id), profileConfigRevision(profile.Writable()), profileConfigRevision(req))
+
+	return response.EmptySyncResponse
 }
 
 // swagger:operation POST /1.0/profiles/{name} profiles profile_post
@@ -810,6 +830,7 @@ func profileDelete(d *Daemon, r *http.Request) response.Response {
 		return response.Forbidden(errors.New(`The "default" profile cannot be deleted`))
 	}
 
+	var apiProfile *api.Profile
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		profile, err := dbCluster.GetProfile(ctx, tx.Tx(), p.Name, name)
 		if err != nil {
@@ -825,6 +846,11 @@ func profileDelete(d *Daemon, r *http.Request) response.Response {
 			return fmt.Errorf("Profile is currently in use")
 		}
 
+		apiProfile, err = profile.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
 		return dbCluster.DeleteProfile(ctx, tx.Tx(), p.Name, name)
 	})
 	if err != nil {
@@ -832,6 +858,7 @@ func profileDelete(d *Daemon, r *http.Request) response.Response {
 	}
 
 	requestor := request.CreateRequestor(r)
+	tombstoneRecord(s, entity.TypeProfile, profileTombstone(p.Name, *apiProfile, requestor))
 	s.Events.SendLifecycle(p.Name, lifecycle.ProfileDeleted.Event(name, p.Name, requestor, nil))
 
 	return response.EmptySyncResponse
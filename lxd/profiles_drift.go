@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"sort"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+var profileDriftCmd = APIEndpoint{
+	Path: "profiles/{name}/drift",
+
+	Get: APIEndpointAction{Handler: profileDriftGet, AccessHandler: allowPermission(entity.TypeProfile, auth.EntitlementCanView, "name")},
+}
+
+// swagger:operation GET /1.0/profiles/{name}/drift profiles profile_drift_get
+//
+//	Get the instances that drifted from the profile
+//
+//	Returns the instances using the profile that were created from an older revision of the profile, or that
+//	override some of its configuration keys or devices.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Instance drift
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of instances
+//	          items:
+//	            $ref: "#/definitions/ProfileInstanceDrift"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func profileDriftGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	_, name, id, err := profileHistoryEntity(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeInstance)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	drift := []api.ProfileInstanceDrift{}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		currentRevision, err := dbCluster.GetLatestConfigRevisionID(ctx, tx.Tx(), dbCluster.EntityType(entity.TypeProfile), int(id))
+		if err != nil {
+			return err
+		}
+
+		dbInstances, err := dbCluster.GetProfileInstances(ctx, tx.Tx(), int(id))
+		if err != nil {
+			return err
+		}
+
+		instances := make([]dbCluster.Instance, 0, len(dbInstances))
+		for _, dbInst := range dbInstances {
+			if userHasPermission(entity.InstanceURL(dbInst.Project, dbInst.Name)) {
+				instances = append(instances, dbInst)
+			}
+		}
+
+		if len(instances) == 0 {
+			return nil
+		}
+
+		instArgs, err := tx.InstancesToInstanceArgs(ctx, true, instances...)
+		if err != nil {
+			return err
+		}
+
+		for _, args := range instArgs {
+			instDrift := profileInstanceDrift(name, int64(currentRevision), args)
+			if instDrift != nil {
+				drift = append(drift, *instDrift)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].Project != drift[j].Project {
+			return drift[i].Project < drift[j].Project
+		}
+
+		return drift[i].Instance < drift[j].Instance
+	})
+
+	return response.SyncResponse(true, drift)
+}
+
+// profileInstanceDrift returns how the instance differs from the current revision of the given profile.
+// Nil is returned if the instance is up to date and doesn't override any configuration of the profile.
+func profileInstanceDrift(profileName string, currentRevision int64, args db.InstanceArgs) *api.ProfileInstanceDrift {
+	drift := api.ProfileInstanceDrift{
+		Instance:          args.Name,
+		Project:           args.Project,
+		Revision:          -1,
+		CurrentRevision:   currentRevision,
+		OverriddenConfig:  []string{},
+		OverriddenDevices: []string{},
+	}
+
+	revisions, err := instance.ParseProfileRevisions(args.Config["volatile.profiles.revisions"])
+	if err == nil {
+		revision, ok := revisions[profileName]
+		if ok {
+			drift.Revision = revision
+			drift.Outdated = revision < currentRevision
+		}
+	}
+
+	// Find the profile, the profiles applied after it can override its configuration.
+	profileIndex := -1
+	for i, profile := range args.Profiles {
+		if profile.Name == profileName {
+			profileIndex = i
+			break
+		}
+	}
+
+	if profileIndex < 0 {
+		return nil
+	}
+
+	profile := args.Profiles[profileIndex]
+	laterProfiles := args.Profiles[profileIndex+1:]
+
+	for key, value := range profile.Config {
+		effective, ok := args.Config[key]
+		if !ok {
+			effective = value
+			for _, laterProfile := range laterProfiles {
+				laterValue, ok := laterProfile.Config[key]
+				if ok {
+					effective = laterValue
+				}
+			}
+		}
+
+		if effective != value {
+			drift.OverriddenConfig = append(drift.OverriddenConfig, key)
+		}
+	}
+
+	for deviceName, device := range profile.Devices {
+		effective, ok := args.Devices[deviceName]
+		if !ok {
+			effective = device
+			for _, laterProfile := range laterProfiles {
+				laterDevice, ok := laterProfile.Devices[deviceName]
+				if ok {
+					effective = laterDevice
+				}
+			}
+		}
+
+		if !maps.Equal(effective, device) {
+			drift.OverriddenDevices = append(drift.OverriddenDevices, deviceName)
+		}
+	}
+
+	if !drift.Outdated && len(drift.OverriddenConfig) == 0 && len(drift.OverriddenDevices) == 0 {
+		return nil
+	}
+
+	sort.Strings(drift.OverriddenConfig)
+	sort.Strings(drift.OverriddenDevices)
+
+	return &drift
+}
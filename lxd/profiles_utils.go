@@ -12,6 +12,7 @@ import (
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
 )
 
 func doProfileUpdate(s *state.State, p api.Project, profileName string, id int64, profile *api.Profile, req api.ProfilePut) error {
@@ -130,6 +131,9 @@ func doProfileUpdate(s *state.State, p api.Project, profileName string, id int64
 		return err
 	}
 
+	// Make sure the instances are updated with the new profile rather than a cached one.
+	s.DB.Cluster.EntityCache().Invalidate(entity.TypeProfile, profileName)
+
 	// Update all the instances on this node using the profile. Must be done after db.TxCommit due to DB lock.
 	failures := map[*db.InstanceArgs]error{}
 	for _, it := range insts {
@@ -160,6 +164,10 @@ func doProfileUpdate(s *state.State, p api.Project, profileName string, id int64
 // Like doProfileUpdate but does not update the database, since it was already
 // updated by doProfileUpdate itself, called on the notifying node.
 func doProfileUpdateCluster(s *state.State, projectName string, profileName string, old api.ProfilePut) error {
+	// The profile lifecycle event may not have been received yet, so make sure the instances are updated with
+	// the new profile rather than a cached one.
+	s.DB.Cluster.EntityCache().Invalidate(entity.TypeProfile, profileName)
+
 	insts, projects, err := getProfileInstancesInfo(s.DB.Cluster, projectName, profileName)
 	if err != nil {
 		return fmt.Errorf("Failed to query instances associated with profile %q: %w", profileName, err)
@@ -0,0 +1,79 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// ParseDiskMargin parses the `limits.disk.margin` value, which is either a size or a percentage of the given limit.
+func ParseDiskMargin(value string, limit int64) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	percentStr, isPercent := strings.CutSuffix(value, "%")
+	if isPercent {
+		percent, err := strconv.ParseInt(percentStr, 10, 64)
+		if err != nil || percent < 0 || percent > 100 {
+			return -1, fmt.Errorf("Invalid percentage %q", value)
+		}
+
+		return limit * percent / 100, nil
+	}
+
+	return units.ParseByteSizeString(value)
+}
+
+// ValidDiskMargin validates a `limits.disk.margin` value.
+func ValidDiskMargin(value string) error {
+	_, err := ParseDiskMargin(value, 0)
+	return err
+}
+
+// DiskUsageEnforced returns whether `limits.disk` is checked against the disk usage reported by the storage
+// drivers for the project.
+func DiskUsageEnforced(p *api.Project) bool {
+	return p.Config["limits.disk"] != "" && p.Config["limits.disk.enforcement"] == "usage"
+}
+
+// CheckDiskUsage returns an error if adding the given number of bytes to the disk usage reported by the storage
+// drivers for the project would exceed its `limits.disk` budget, minus the `limits.disk.margin` safety margin.
+// Nothing is checked until the disk usage of the project has been recorded.
+func CheckDiskUsage(ctx context.Context, tx *db.ClusterTx, p *api.Project, extra int64) error {
+	if !DiskUsageEnforced(p) {
+		return nil
+	}
+
+	limit, err := units.ParseByteSizeString(p.Config["limits.disk"])
+	if err != nil {
+		return err
+	}
+
+	margin, err := ParseDiskMargin(p.Config["limits.disk.margin"], limit)
+	if err != nil {
+		return err
+	}
+
+	usage, found, err := tx.GetProjectDiskUsage(ctx, p.Name)
+	if err != nil {
+		return fmt.Errorf("Failed getting disk usage of project %q: %w", p.Name, err)
+	}
+
+	if !found {
+		return nil
+	}
+
+	budget := limit - margin
+	if usage+extra > budget {
+		return api.StatusErrorf(http.StatusForbidden, "Disk usage of project %q would exceed its budget (used %s, needed %s, budget %s)", p.Name, units.GetByteSizeStringIEC(usage, 1), units.GetByteSizeStringIEC(extra, 1), units.GetByteSizeStringIEC(budget, 1))
+	}
+
+	return nil
+}
@@ -104,7 +104,7 @@ func checkTotalInstanceCountLimit(info *projectInfo) error {
 	}
 
 	if limit >= 0 && count >= limit {
-		return fmt.Errorf("Reached maximum number of instances in project %q", info.Project.Name)
+		return api.ReasonErrorf(api.ErrorReasonProjectLimitReached, map[string]any{"project": info.Project.Name, "key": "limits.instances", "limit": limit}, "Reached maximum number of instances in project %q", info.Project.Name)
 	}
 
 	return nil
@@ -132,7 +132,12 @@ func checkInstanceCountLimit(info *projectInfo, instanceType instancetype.Type)
 	}
 
 	if limit >= 0 && count >= limit {
-		return fmt.Errorf("Reached maximum number of instances of type %q in project %q", instanceType, info.Project.Name)
+		key := "limits.containers"
+		if instanceType == instancetype.VM {
+			key = "limits.virtual-machines"
+		}
+
+		return api.ReasonErrorf(api.ErrorReasonProjectLimitReached, map[string]any{"project": info.Project.Name, "key": key, "limit": limit}, "Reached maximum number of instances of type %q in project %q", instanceType, info.Project.Name)
 	}
 
 	return nil
@@ -366,7 +371,7 @@ func checkRestrictionsAndAggregateLimits(globalConfig *clusterConfig.Config, tx
 	if isRestricted {
 		err = checkRestrictions(info.Project, info.Instances, info.Profiles)
 		if err != nil {
-			return err
+			return api.ReasonErrorf(api.ErrorReasonProjectRestricted, map[string]any{"project": info.Project.Name}, "%w", err)
 		}
 	}
 
@@ -425,7 +430,7 @@ func checkAggregateLimits(info *projectInfo, aggregateKeys []string) error {
 		}
 
 		if totals[key] > max {
-			return fmt.Errorf("Reached maximum aggregate value %q for %q in project %q", info.Project.Config[key], key, info.Project.Name)
+			return api.ReasonErrorf(api.ErrorReasonProjectLimitReached, map[string]any{"project": info.Project.Name, "key": key, "limit": info.Project.Config[key]}, "Reached maximum aggregate value %q for %q in project %q", info.Project.Config[key], key, info.Project.Name)
 		}
 	}
 	return nil
@@ -457,6 +462,7 @@ func parseHostIDMapRange(isUID bool, isGID bool, listValue string) ([]idmap.Idma
 // instances and profiles.
 func checkRestrictions(project api.Project, instances []api.Instance, profiles []api.Profile) error {
 	containerConfigChecks := map[string]func(value string) error{}
+	vmConfigChecks := map[string]func(value string) error{}
 	devicesChecks := map[string]func(value map[string]string) error{}
 
 	allowContainerLowLevel := false
@@ -522,6 +528,15 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 				allowVMLowLevel = true
 			}
 
+		case "restricted.virtual-machines.usb_redirection":
+			vmConfigChecks["security.usb_redirection"] = func(instanceValue string) error {
+				if restrictionValue != "allow" && shared.IsTrue(instanceValue) {
+					return fmt.Errorf("USB redirection is forbidden")
+				}
+
+				return nil
+			}
+
 		case "restricted.devices.unix-char":
 			devicesChecks["unix-char"] = func(device map[string]string) error {
 				if restrictionValue != "allow" {
@@ -608,17 +623,7 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 
 				// Check if the NIC's parent/network setting is allowed based on the
 				// restricted.devices.nic and restricted.networks.access settings.
-				if device["network"] != "" {
-					if !NetworkAllowed(project.Config, device["network"], true) {
-						return fmt.Errorf("Network not allowed in project")
-					}
-				} else if device["parent"] != "" {
-					if !NetworkAllowed(project.Config, device["parent"], false) {
-						return fmt.Errorf("Network not allowed in project")
-					}
-				}
-
-				return nil
+				return checkNICNetworkAllowed(project.Config, device)
 			}
 
 		case "restricted.devices.disk":
@@ -711,6 +716,10 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 				checker = containerConfigChecks[key]
 			}
 
+			if checker == nil && isVMOrProfile {
+				checker = vmConfigChecks[key]
+			}
+
 			if checker == nil {
 				continue
 			}
@@ -777,6 +786,58 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 	return nil
 }
 
+// checkNICNetworkAllowed checks that the network or parent interface used by a NIC device is allowed by the
+// project's restricted.devices.nic, restricted.devices.nic.parents and restricted.networks.access settings.
+func checkNICNetworkAllowed(projectConfig map[string]string, device map[string]string) error {
+	if device["network"] != "" {
+		if !NetworkAllowed(projectConfig, device["network"], true) {
+			return fmt.Errorf("Network %q not allowed in project", device["network"])
+		}
+	} else if device["parent"] != "" {
+		if !NetworkAllowed(projectConfig, device["parent"], false) {
+			return fmt.Errorf("Parent interface %q not allowed in project", device["parent"])
+		}
+	}
+
+	return nil
+}
+
+// CheckInstanceNetworkRestrictions checks the NIC devices of the instances of the given project against the
+// project's network restrictions. It returns the violations found, indexed by instance name.
+func CheckInstanceNetworkRestrictions(globalConfig *clusterConfig.Config, tx *db.ClusterTx, projectName string) (map[string]error, error) {
+	var globalConfigDump map[string]any
+	if globalConfig != nil {
+		globalConfigDump = globalConfig.Dump()
+	}
+
+	info, err := fetchProject(globalConfigDump, tx, projectName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	info.Instances, err = expandInstancesConfigAndDevices(globalConfigDump, info.Instances, info.Profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := map[string]error{}
+	for _, instance := range info.Instances {
+		for name, device := range instance.Devices {
+			if device["type"] != "nic" {
+				continue
+			}
+
+			err := checkNICNetworkAllowed(info.Project.Config, device)
+			if err != nil {
+				violations[instance.Name] = fmt.Errorf("Invalid device %q: %w", name, err)
+				break
+			}
+		}
+	}
+
+	return violations, nil
+}
+
 // CheckRestrictedDevicesDiskPaths checks whether the disk's source path is within the allowed paths specified in
 // the project's restricted.devices.disk.paths config setting.
 // If no allowed paths are specified in project, then it allows all paths, and returns true and empty string.
@@ -803,35 +864,46 @@ func CheckRestrictedDevicesDiskPaths(projectConfig map[string]string, sourcePath
 var allAggregateLimits = []string{
 	"limits.cpu",
 	"limits.disk",
+	"limits.inotify.instances",
+	"limits.inotify.watches",
 	"limits.memory",
 	"limits.processes",
 }
 
 // allRestrictions lists all available 'restrict.*' config keys along with their default setting.
 var allRestrictions = map[string]string{
-	"restricted.backups":                   "block",
-	"restricted.cluster.groups":            "",
-	"restricted.cluster.target":            "block",
-	"restricted.containers.nesting":        "block",
-	"restricted.containers.interception":   "block",
-	"restricted.containers.lowlevel":       "block",
-	"restricted.containers.privilege":      "unprivileged",
-	"restricted.virtual-machines.lowlevel": "block",
-	"restricted.devices.unix-char":         "block",
-	"restricted.devices.unix-block":        "block",
-	"restricted.devices.unix-hotplug":      "block",
-	"restricted.devices.infiniband":        "block",
-	"restricted.devices.gpu":               "block",
-	"restricted.devices.usb":               "block",
-	"restricted.devices.pci":               "block",
-	"restricted.devices.proxy":             "block",
-	"restricted.devices.nic":               "managed",
-	"restricted.devices.disk":              "managed",
-	"restricted.devices.disk.paths":        "",
-	"restricted.idmap.uid":                 "",
-	"restricted.idmap.gid":                 "",
-	"restricted.networks.access":           "",
-	"restricted.snapshots":                 "block",
+	"restricted.backups":                          "block",
+	"restricted.cluster.groups":                   "",
+	"restricted.cluster.target":                   "block",
+	"restricted.containers.nesting":               "block",
+	"restricted.containers.interception":          "block",
+	"restricted.containers.lowlevel":              "block",
+	"restricted.containers.privilege":             "unprivileged",
+	"restricted.virtual-machines.lowlevel":        "block",
+	"restricted.virtual-machines.usb_redirection": "block",
+	"restricted.devices.unix-char":                "block",
+	"restricted.devices.unix-block":               "block",
+	"restricted.devices.unix-hotplug":             "block",
+	"restricted.devices.infiniband":               "block",
+	"restricted.devices.gpu":                      "block",
+	"restricted.devices.usb":                      "block",
+	"restricted.devices.pci":                      "block",
+	"restricted.devices.proxy":                    "block",
+	"restricted.devices.nic":                      "managed",
+	"restricted.devices.nic.parents":              "",
+	"restricted.devices.disk":                     "managed",
+	"restricted.devices.disk.paths":               "",
+	"restricted.idmap.uid":                        "",
+	"restricted.idmap.gid":                        "",
+	"restricted.networks.access":                  "",
+	"restricted.snapshots":                        "block",
+}
+
+// networkRestrictions lists the restrictions on the networks used by NIC devices. Changing them doesn't fail
+// when existing instances no longer comply; those instances get a warning instead.
+var networkRestrictions = []string{
+	"restricted.devices.nic.parents",
+	"restricted.networks.access",
 }
 
 // allowableIntercept lists all syscall interception keys which may be allowed.
@@ -841,6 +913,7 @@ var allowableIntercept = []string{
 	"security.syscalls.intercept.mknod",
 	"security.syscalls.intercept.mount",
 	"security.syscalls.intercept.mount.fuse",
+	"security.syscalls.intercept.policies",
 	"security.syscalls.intercept.setxattr",
 	"security.syscalls.intercept.sysinfo",
 }
@@ -1026,11 +1099,27 @@ func AllowProjectUpdate(globalConfig *clusterConfig.Config, tx *db.ClusterTx, pr
 	// instances.
 	aggregateKeys := []string{}
 
+	// The existing instances are checked against the current network restrictions, as they are only warned
+	// about the new ones.
+	checkConfig := make(map[string]string, len(config))
+	for key, value := range config {
+		checkConfig[key] = value
+	}
+
+	for _, key := range networkRestrictions {
+		value, ok := info.Project.Config[key]
+		if ok {
+			checkConfig[key] = value
+		} else {
+			delete(checkConfig, key)
+		}
+	}
+
 	for _, key := range changed {
 		if strings.HasPrefix(key, "restricted.") {
 			project := api.Project{
 				Name:   projectName,
-				Config: config,
+				Config: checkConfig,
 			}
 
 			err := checkRestrictions(project, info.Instances, info.Profiles)
@@ -1058,6 +1147,10 @@ func AllowProjectUpdate(globalConfig *clusterConfig.Config, tx *db.ClusterTx, pr
 
 		case "limits.processes":
 			fallthrough
+		case "limits.inotify.instances":
+			fallthrough
+		case "limits.inotify.watches":
+			fallthrough
 		case "limits.cpu":
 			fallthrough
 		case "limits.memory":
@@ -1434,6 +1527,12 @@ var aggregateLimitConfigValueParsers = map[string]func(string) (int64, error){
 
 		return int64(limit), nil
 	},
+	"limits.inotify.instances": func(value string) (int64, error) {
+		return strconv.ParseInt(value, 10, 64)
+	},
+	"limits.inotify.watches": func(value string) (int64, error) {
+		return strconv.ParseInt(value, 10, 64)
+	},
 	"limits.cpu": func(value string) (int64, error) {
 		if strings.Contains(value, ",") || strings.Contains(value, "-") {
 			return -1, fmt.Errorf("CPUs can't be pinned if project limits are used")
@@ -1458,6 +1557,12 @@ var aggregateLimitConfigValuePrinters = map[string]func(int64) string{
 	"limits.processes": func(limit int64) string {
 		return fmt.Sprintf("%d", limit)
 	},
+	"limits.inotify.instances": func(limit int64) string {
+		return fmt.Sprintf("%d", limit)
+	},
+	"limits.inotify.watches": func(limit int64) string {
+		return fmt.Sprintf("%d", limit)
+	},
 	"limits.cpu": func(limit int64) string {
 		return fmt.Sprintf("%d", limit)
 	},
@@ -1693,6 +1798,20 @@ func CheckTarget(ctx context.Context, authorizer auth.Authorizer, r *http.Reques
 		return nil, "", err
 	}
 
+	return checkTargetMemberOrGroup(ctx, tx, p, targetMemberName, targetGroupName, allMembers)
+}
+
+// CheckDefaultTarget checks if the default cluster target (member or group) of the project is allowed.
+// Unlike CheckTarget, it doesn't check restricted.cluster.target as the default target is set on the project
+// rather than requested by the client.
+func CheckDefaultTarget(ctx context.Context, tx *db.ClusterTx, p *api.Project, allMembers []db.NodeInfo) (*db.NodeInfo, string, error) {
+	targetMemberName, targetGroupName := shared.TargetDetect(p.Config["default.target"])
+
+	return checkTargetMemberOrGroup(ctx, tx, p, targetMemberName, targetGroupName, allMembers)
+}
+
+// checkTargetMemberOrGroup checks if the given cluster member or group is allowed for the project.
+func checkTargetMemberOrGroup(ctx context.Context, tx *db.ClusterTx, p *api.Project, targetMemberName string, targetGroupName string, allMembers []db.NodeInfo) (*db.NodeInfo, string, error) {
 	if targetMemberName != "" {
 		member, err := CheckTargetMember(p, targetMemberName, allMembers)
 		if err != nil {
@@ -3,6 +3,7 @@ package project
 import (
 	"context"
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/canonical/lxd/lxd/db"
@@ -81,12 +82,8 @@ func StorageVolumeProject(c *db.Cluster, projectName string, volumeType int) (st
 
 	var project *api.Project
 	err := c.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
-		if err != nil {
-			return err
-		}
-
-		project, err = dbProject.ToAPI(ctx, tx.Tx())
+		var err error
+		project, err = tx.GetCachedProject(ctx, projectName)
 
 		return err
 	})
@@ -128,12 +125,8 @@ func StorageVolumeProjectFromRecord(p *api.Project, volumeType int) string {
 func StorageBucketProject(ctx context.Context, c *db.Cluster, projectName string) (string, error) {
 	var p *api.Project
 	err := c.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
-		dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
-		if err != nil {
-			return err
-		}
-
-		p, err = dbProject.ToAPI(ctx, tx.Tx())
+		var err error
+		p, err = tx.GetCachedProject(ctx, projectName)
 
 		return err
 	})
@@ -164,12 +157,8 @@ func StorageBucketProjectFromRecord(p *api.Project) string {
 func NetworkProject(c *db.Cluster, projectName string) (string, *api.Project, error) {
 	var p *api.Project
 	err := c.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
-		if err != nil {
-			return err
-		}
-
-		p, err = dbProject.ToAPI(ctx, tx.Tx())
+		var err error
+		p, err = tx.GetCachedProject(ctx, projectName)
 
 		return err
 	})
@@ -195,6 +184,15 @@ func NetworkProjectFromRecord(p *api.Project) string {
 	return api.ProjectDefaultName
 }
 
+// USBRedirectionAllowed returns whether the project allows redirecting USB devices into virtual machines over SPICE.
+func USBRedirectionAllowed(reqProjectConfig map[string]string) bool {
+	if shared.IsFalseOrEmpty(reqProjectConfig["restricted"]) {
+		return true
+	}
+
+	return reqProjectConfig["restricted.virtual-machines.usb_redirection"] == "allow"
+}
+
 // NetworkAllowed returns whether access is allowed to a particular network based on projectConfig.
 func NetworkAllowed(reqProjectConfig map[string]string, networkName string, isManaged bool) bool {
 	// If project is not restricted, then access to network is allowed.
@@ -212,14 +210,31 @@ func NetworkAllowed(reqProjectConfig map[string]string, networkName string, isMa
 		return false
 	}
 
+	// Unmanaged parent interfaces are checked against restricted.devices.nic.parents if set.
+	if !isManaged && reqProjectConfig["restricted.devices.nic.parents"] != "" {
+		return NetworkNameMatches(reqProjectConfig["restricted.devices.nic.parents"], networkName)
+	}
+
 	// If restricted.networks.access is not set then allow access to all networks.
 	if reqProjectConfig["restricted.networks.access"] == "" {
 		return true
 	}
 
-	// Check if reqquested network is in list of allowed networks.
-	allowedRestrictedNetworks := shared.SplitNTrimSpace(reqProjectConfig["restricted.networks.access"], ",", -1, false)
-	return shared.ValueInSlice(networkName, allowedRestrictedNetworks)
+	// Check if requested network matches the list of allowed networks.
+	return NetworkNameMatches(reqProjectConfig["restricted.networks.access"], networkName)
+}
+
+// NetworkNameMatches returns true if the network name matches one of the entries of the comma-delimited list of
+// patterns. The patterns use shell file name matching, e.g. "lxdbr*" or "uplink[0-9]".
+func NetworkNameMatches(patterns string, networkName string) bool {
+	for _, pattern := range shared.SplitNTrimSpace(patterns, ",", -1, false) {
+		match, err := path.Match(pattern, networkName)
+		if err == nil && match {
+			return true
+		}
+	}
+
+	return false
 }
 
 // ProfileProject returns the effective project to use for the profile based on the requested project.
@@ -228,30 +243,21 @@ func NetworkAllowed(reqProjectConfig map[string]string, networkName string, isMa
 func ProfileProject(c *db.Cluster, projectName string) (*api.Project, error) {
 	var p *api.Project
 	err := c.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
+		var err error
+		p, err = tx.GetCachedProject(ctx, projectName)
 		if err != nil {
 			return fmt.Errorf("Failed loading project %q: %w", projectName, err)
 		}
 
-		p, err = dbProject.ToAPI(ctx, tx.Tx())
-		if err != nil {
-			return fmt.Errorf("Failed loading config for project %q: %w", projectName, err)
-		}
-
 		effectiveProjectName := ProfileProjectFromRecord(p)
 
 		if effectiveProjectName == api.ProjectDefaultName {
-			dbProject, err = cluster.GetProject(ctx, tx.Tx(), effectiveProjectName)
+			p, err = tx.GetCachedProject(ctx, effectiveProjectName)
 			if err != nil {
 				return fmt.Errorf("Failed loading project %q: %w", effectiveProjectName, err)
 			}
 		}
 
-		p, err = dbProject.ToAPI(ctx, tx.Tx())
-		if err != nil {
-			return fmt.Errorf("Failed loading config for project %q: %w", dbProject.Name, err)
-		}
-
 		return nil
 	})
 	if err != nil {
@@ -281,12 +287,8 @@ func ProfileProjectFromRecord(p *api.Project) string {
 func NetworkZoneProject(c *db.Cluster, projectName string) (string, *api.Project, error) {
 	var p *api.Project
 	err := c.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
-		if err != nil {
-			return err
-		}
-
-		p, err = dbProject.ToAPI(ctx, tx.Tx())
+		var err error
+		p, err = tx.GetCachedProject(ctx, projectName)
 
 		return err
 	})
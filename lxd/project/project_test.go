@@ -45,3 +45,35 @@ func ExampleStorageVolume() {
 	// Output: default_test
 	// project_name_test1
 }
+
+func ExampleParseDiskMargin() {
+	margin, _ := project.ParseDiskMargin("10%", 50*1024*1024*1024)
+	fmt.Println(margin)
+
+	margin, _ = project.ParseDiskMargin("1GiB", 50*1024*1024*1024)
+	fmt.Println(margin)
+
+	_, err := project.ParseDiskMargin("150%", 50*1024*1024*1024)
+	fmt.Println(err)
+	// Output: 5368709120
+	// 1073741824
+	// Invalid percentage "150%"
+}
+
+func ExampleNetworkAllowed() {
+	config := map[string]string{
+		"restricted":                     "true",
+		"restricted.devices.nic":         "allow",
+		"restricted.devices.nic.parents": "eth0,enp5s0f*",
+		"restricted.networks.access":     "lxdbr*",
+	}
+
+	fmt.Println(project.NetworkAllowed(config, "lxdbr1", true))
+	fmt.Println(project.NetworkAllowed(config, "ovn0", true))
+	fmt.Println(project.NetworkAllowed(config, "enp5s0f1", false))
+	fmt.Println(project.NetworkAllowed(config, "lxdbr1", false))
+	// Output: true
+	// false
+	// true
+	// false
+}
@@ -37,6 +37,8 @@ func GetCurrentAllocations(globalConfig map[string]any, ctx context.Context, tx
 
 	result["cpu"] = raw["limits.cpu"]
 	result["disk"] = raw["limits.disk"]
+	result["inotify-instances"] = raw["limits.inotify.instances"]
+	result["inotify-watches"] = raw["limits.inotify.watches"]
 	result["memory"] = raw["limits.memory"]
 	result["networks"] = raw["limits.networks"]
 	result["processes"] = raw["limits.processes"]
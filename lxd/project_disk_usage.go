@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// projectsDiskUsageInterval is how often the disk usage of the projects is reconciled.
+const projectsDiskUsageInterval = 10 * time.Minute
+
+func projectsDiskUsageTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		projectsDiskUsageUpdate(ctx, d)
+	}
+
+	return f, task.Every(projectsDiskUsageInterval)
+}
+
+// projectsDiskUsageUpdate records the disk usage reported by the storage drivers for the projects that enforce
+// `limits.disk` against it.
+// Each member accounts for the instances that it runs and the custom volumes that it holds, while the leader also
+// accounts for the custom volumes on remote storage pools.
+func projectsDiskUsageUpdate(ctx context.Context, d *Daemon) {
+	s := d.State()
+
+	var projects []api.Project
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProjects, err := cluster.GetProjects(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		for _, dbProject := range dbProjects {
+			p, err := dbProject.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			if project.DiskUsageEnforced(p) {
+				projects = append(projects, *p)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Warn("Failed loading projects to reconcile their disk usage", logger.Ctx{"err": err})
+		return
+	}
+
+	if len(projects) == 0 {
+		return
+	}
+
+	isLeader := true
+	if s.ServerClustered {
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil {
+			logger.Warn("Failed getting cluster leader to reconcile disk usage", logger.Ctx{"err": err})
+			return
+		}
+
+		isLeader = leader == s.LocalConfig.ClusterAddress()
+	}
+
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		logger.Warn("Failed loading instances to reconcile disk usage", logger.Ctx{"err": err})
+		return
+	}
+
+	for _, p := range projects {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var usage int64
+
+		for _, inst := range instances {
+			if inst.Project().Name != p.Name {
+				continue
+			}
+
+			usage += instanceDiskUsage(s, inst)
+		}
+
+		customUsage, err := projectCustomVolumesDiskUsage(ctx, s, p.Name, isLeader)
+		if err != nil {
+			logger.Warn("Failed getting disk usage of custom volumes", logger.Ctx{"project": p.Name, "err": err})
+			continue
+		}
+
+		usage += customUsage
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateProjectDiskUsage(ctx, p.Name, usage)
+		})
+		if err != nil {
+			logger.Warn("Failed recording disk usage of project", logger.Ctx{"project": p.Name, "err": err})
+		}
+	}
+}
+
+// instanceDiskUsage returns the disk usage of the root volume of the instance as reported by the storage driver.
+// Zero is returned if the driver can't report it.
+func instanceDiskUsage(s *state.State, inst instance.Instance) int64 {
+	pool, err := storagePools.LoadByInstance(s, inst)
+	if err != nil {
+		return 0
+	}
+
+	usage, err := pool.GetInstanceUsage(inst)
+	if err != nil {
+		if !errors.Is(err, storageDrivers.ErrNotSupported) {
+			logger.Debug("Failed getting instance disk usage", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+		}
+
+		return 0
+	}
+
+	return usage.Used
+}
+
+// customVolumeDiskUsage returns the disk usage of the custom volume as reported by the storage driver.
+// Zero is returned if the driver can't report it.
+func customVolumeDiskUsage(s *state.State, poolName string, projectName string, volumeName string) int64 {
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return 0
+	}
+
+	usage, err := pool.GetCustomVolumeUsage(projectName, volumeName)
+	if err != nil {
+		if !errors.Is(err, storageDrivers.ErrNotSupported) {
+			logger.Debug("Failed getting custom volume disk usage", logger.Ctx{"project": projectName, "pool": poolName, "volume": volumeName, "err": err})
+		}
+
+		return 0
+	}
+
+	return usage.Used
+}
+
+// projectCustomVolumesDiskUsage returns the disk usage of the custom volumes of the project that are held by this
+// member, including the volumes on remote storage pools if includeRemote is true.
+func projectCustomVolumesDiskUsage(ctx context.Context, s *state.State, projectName string, includeRemote bool) (int64, error) {
+	var volumes []*db.StorageVolume
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		volumeType := cluster.StoragePoolVolumeTypeCustom
+		volumes, err = tx.GetStorageVolumes(ctx, true, db.StorageVolumeFilter{Type: &volumeType, Project: &projectName})
+
+		return err
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	var usage int64
+	for _, vol := range volumes {
+		if shared.IsSnapshot(vol.Name) {
+			continue
+		}
+
+		if vol.Location == "" && !includeRemote {
+			continue
+		}
+
+		usage += customVolumeDiskUsage(s, vol.Pool, projectName, vol.Name)
+	}
+
+	return usage, nil
+}
+
+// projectCheckDiskUsage returns an error if adding the given number of bytes to the disk usage of the project would
+// exceed its budget.
+func projectCheckDiskUsage(ctx context.Context, s *state.State, projectName string, extra int64) error {
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		p, err := dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		return project.CheckDiskUsage(ctx, tx, p, extra)
+	})
+}
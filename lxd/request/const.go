@@ -61,4 +61,7 @@ const (
 	// HeaderForwardedIdentityProviderGroups is the forwarded identity provider groups field in request header.
 	// This will be a JSON marshalled []string.
 	HeaderForwardedIdentityProviderGroups = "X-LXD-forwarded-identity-provider-groups"
+
+	// HeaderOperationCallback is the URL to notify when the operation created by the request completes.
+	HeaderOperationCallback = "X-LXD-Operation-Callback"
 )
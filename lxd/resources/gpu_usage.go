@@ -0,0 +1,131 @@
+package resources
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GPUUsage represents the current usage of a GPU.
+type GPUUsage struct {
+	Vendor           string
+	UtilizationRatio float64
+	MemoryUsedBytes  uint64
+	MemoryTotalBytes uint64
+}
+
+// GetGPUUsage returns the current usage of the GPUs of the system, indexed by PCI address.
+// NVIDIA GPUs are queried through nvidia-smi and AMD GPUs through the sysfs interface of the amdgpu driver.
+// GPUs from other vendors, or whose driver doesn't report usage, are omitted.
+func GetGPUUsage() (map[string]GPUUsage, error) {
+	usage := map[string]GPUUsage{}
+
+	nvidiaUsage, err := loadNvidiaUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	for pciAddress, gpuUsage := range nvidiaUsage {
+		usage[pciAddress] = gpuUsage
+	}
+
+	if !sysfsExists(sysBusPci) {
+		return usage, nil
+	}
+
+	entries, err := os.ReadDir(sysBusPci)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list %q: %w", sysBusPci, err)
+	}
+
+	for _, entry := range entries {
+		devicePath := filepath.Join(sysBusPci, entry.Name())
+
+		driverPath, err := filepath.EvalSymlinks(filepath.Join(devicePath, "driver"))
+		if err != nil || filepath.Base(driverPath) != "amdgpu" {
+			continue
+		}
+
+		busy, err := readUint(filepath.Join(devicePath, "gpu_busy_percent"))
+		if err != nil {
+			continue
+		}
+
+		gpuUsage := GPUUsage{
+			Vendor:           "amd",
+			UtilizationRatio: float64(busy) / 100,
+		}
+
+		gpuUsage.MemoryUsedBytes, _ = readUint(filepath.Join(devicePath, "mem_info_vram_used"))
+		gpuUsage.MemoryTotalBytes, _ = readUint(filepath.Join(devicePath, "mem_info_vram_total"))
+
+		usage[entry.Name()] = gpuUsage
+	}
+
+	return usage, nil
+}
+
+func loadNvidiaUsage() (map[string]GPUUsage, error) {
+	usage := map[string]GPUUsage{}
+
+	// Skip systems without NVIDIA GPUs.
+	if !sysfsExists(procDriverNvidia) {
+		return usage, nil
+	}
+
+	_, err := exec.LookPath("nvidia-smi")
+	if err != nil {
+		return usage, nil
+	}
+
+	out, err := exec.Command("nvidia-smi", "--query-gpu=pci.bus_id,utilization.gpu,memory.used,memory.total", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return nil, fmt.Errorf("Failed running nvidia-smi: %w", err)
+	}
+
+	r := csv.NewReader(strings.NewReader(string(out)))
+	r.TrimLeadingSpace = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing nvidia-smi output: %w", err)
+	}
+
+	for _, record := range records {
+		if len(record) != 4 {
+			continue
+		}
+
+		// nvidia-smi uses an 8 digit PCI domain, e.g. 00000000:01:00.0.
+		pciAddress := strings.ToLower(record[0])
+		if len(pciAddress) > 12 {
+			pciAddress = pciAddress[len(pciAddress)-12:]
+		}
+
+		gpuUsage := GPUUsage{Vendor: "nvidia"}
+
+		// Fields may be reported as "[N/A]" when not supported by the GPU.
+		utilization, err := strconv.ParseFloat(record[1], 64)
+		if err == nil {
+			gpuUsage.UtilizationRatio = utilization / 100
+		}
+
+		memoryUsed, err := strconv.ParseUint(record[2], 10, 64)
+		if err == nil {
+			gpuUsage.MemoryUsedBytes = memoryUsed * 1024 * 1024
+		}
+
+		memoryTotal, err := strconv.ParseUint(record[3], 10, 64)
+		if err == nil {
+			gpuUsage.MemoryTotalBytes = memoryTotal * 1024 * 1024
+		}
+
+		usage[pciAddress] = gpuUsage
+	}
+
+	return usage, nil
+}
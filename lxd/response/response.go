@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -248,18 +249,35 @@ func (r *syncResponse) String() string {
 
 // Error response.
 type errorResponse struct {
-	code int    // Code to return in both the HTTP header and Code field of the response body.
-	msg  string // Message to return in the Error field of the response body.
+	code     int             // Code to return in both the HTTP header and Code field of the response body.
+	msg      string          // Message to return in the Error field of the response body.
+	reason   api.ErrorReason // Reason to return in the ErrorReason field of the response body.
+	metadata map[string]any  // Metadata to return in the Metadata field of the response body.
+}
+
+// newErrorResponse returns an error response with the given code and msg.
+// The reason and metadata are taken from the StatusError that caused err if it has the same status code.
+// Otherwise the generic reason of the code is used.
+func newErrorResponse(code int, msg string, err error) *errorResponse {
+	resp := &errorResponse{code: code, msg: msg}
+
+	var statusErr api.StatusError
+	if errors.As(err, &statusErr) && statusErr.Status() == code {
+		resp.reason = statusErr.Reason()
+		resp.metadata = statusErr.Metadata()
+	}
+
+	return resp
 }
 
 // ErrorResponse returns an error response with the given code and msg.
 func ErrorResponse(code int, msg string) Response {
-	return &errorResponse{code, msg}
+	return newErrorResponse(code, msg, nil)
 }
 
 // BadRequest returns a bad request response (400) with the given error.
 func BadRequest(err error) Response {
-	return &errorResponse{http.StatusBadRequest, err.Error()}
+	return newErrorResponse(http.StatusBadRequest, err.Error(), err)
 }
 
 // Conflict returns a conflict response (409) with the given error.
@@ -269,7 +287,7 @@ func Conflict(err error) Response {
 		message = err.Error()
 	}
 
-	return &errorResponse{http.StatusConflict, message}
+	return newErrorResponse(http.StatusConflict, message, err)
 }
 
 // Forbidden returns a forbidden response (403) with the given error.
@@ -279,12 +297,12 @@ func Forbidden(err error) Response {
 		message = err.Error()
 	}
 
-	return &errorResponse{http.StatusForbidden, message}
+	return newErrorResponse(http.StatusForbidden, message, err)
 }
 
 // InternalError returns an internal error response (500) with the given error.
 func InternalError(err error) Response {
-	return &errorResponse{http.StatusInternalServerError, err.Error()}
+	return newErrorResponse(http.StatusInternalServerError, err.Error(), err)
 }
 
 // NotFound returns a not found response (404) with the given error.
@@ -294,7 +312,7 @@ func NotFound(err error) Response {
 		message = err.Error()
 	}
 
-	return &errorResponse{http.StatusNotFound, message}
+	return newErrorResponse(http.StatusNotFound, message, err)
 }
 
 // NotImplemented returns a not implemented response (501) with the given error.
@@ -304,13 +322,13 @@ func NotImplemented(err error) Response {
 		message = err.Error()
 	}
 
-	return &errorResponse{http.StatusNotImplemented, message}
+	return newErrorResponse(http.StatusNotImplemented, message, err)
 }
 
 // PreconditionFailed returns a precondition failed response (412) with the
 // given error.
 func PreconditionFailed(err error) Response {
-	return &errorResponse{http.StatusPreconditionFailed, err.Error()}
+	return newErrorResponse(http.StatusPreconditionFailed, err.Error(), err)
 }
 
 // Unavailable return an unavailable response (503) with the given error.
@@ -320,7 +338,7 @@ func Unavailable(err error) Response {
 		message = err.Error()
 	}
 
-	return &errorResponse{http.StatusServiceUnavailable, message}
+	return newErrorResponse(http.StatusServiceUnavailable, message, err)
 }
 
 func (r *errorResponse) String() string {
@@ -338,10 +356,20 @@ func (r *errorResponse) Render(w http.ResponseWriter) error {
 		output = io.MultiWriter(buf, captured)
 	}
 
+	reason := r.reason
+	if reason == "" {
+		reason = api.ErrorReasonFromStatus(r.code)
+	}
+
 	resp := api.ResponseRaw{
-		Type:  api.ErrorResponse,
-		Error: r.msg,
-		Code:  r.code, // Set the error code in the Code field of the response body.
+		Type:        api.ErrorResponse,
+		Error:       r.msg,
+		Code:        r.code, // Set the error code in the Code field of the response body.
+		ErrorReason: reason,
+	}
+
+	if r.metadata != nil {
+		resp.Metadata = r.metadata
 	}
 
 	err := json.NewEncoder(output).Encode(resp)
@@ -583,5 +611,5 @@ func Unauthorized(err error) Response {
 		message = err.Error()
 	}
 
-	return &errorResponse{http.StatusUnauthorized, message}
+	return newErrorResponse(http.StatusUnauthorized, message, err)
 }
@@ -6,12 +6,15 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/canonical/lxd/lxd/db/dberr"
 	"github.com/canonical/lxd/shared/api"
 )
 
 var httpResponseErrors = map[int][]error{
-	http.StatusNotFound:  {os.ErrNotExist, sql.ErrNoRows},
-	http.StatusForbidden: {os.ErrPermission},
+	http.StatusBadRequest: {dberr.ErrConstraint},
+	http.StatusNotFound:   {os.ErrNotExist, sql.ErrNoRows, dberr.ErrNotFound},
+	http.StatusForbidden:  {os.ErrPermission},
+	http.StatusConflict:   {dberr.ErrConflict},
 }
 
 // SmartError returns the right error message based on err.
@@ -23,7 +26,7 @@ func SmartError(err error) Response {
 
 	statusCode, found := api.StatusErrorMatch(err)
 	if found {
-		return &errorResponse{statusCode, err.Error()}
+		return newErrorResponse(statusCode, err.Error(), err)
 	}
 
 	for httpStatusCode, checkErrs := range httpResponseErrors {
@@ -31,17 +34,17 @@ func SmartError(err error) Response {
 			if errors.Is(err, checkErr) {
 				if err != checkErr {
 					// If the error has been wrapped return the top-level error message.
-					return &errorResponse{httpStatusCode, err.Error()}
+					return newErrorResponse(httpStatusCode, err.Error(), nil)
 				}
 
 				// If the error hasn't been wrapped, replace the error message with the generic
 				// HTTP status text.
-				return &errorResponse{httpStatusCode, http.StatusText(httpStatusCode)}
+				return newErrorResponse(httpStatusCode, http.StatusText(httpStatusCode), nil)
 			}
 		}
 	}
 
-	return &errorResponse{http.StatusInternalServerError, err.Error()}
+	return newErrorResponse(http.StatusInternalServerError, err.Error(), nil)
 }
 
 // IsNotFoundError returns true if the error is considered a Not Found error.
@@ -7,6 +7,7 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -422,7 +423,13 @@ func rsyncFeatureArgs(features []string) []string {
 		args = append(args, "--delete")
 	}
 
-	if shared.ValueInSlice("compress", features) {
+	if shared.ValueInSlice("compress_zstd", features) {
+		args = append(args, "--compress")
+		args = append(args, "--compress-choice=zstd")
+	} else if shared.ValueInSlice("compress_lz4", features) {
+		args = append(args, "--compress")
+		args = append(args, "--compress-choice=lz4")
+	} else if shared.ValueInSlice("compress", features) {
 		args = append(args, "--compress")
 		args = append(args, "--compress-level=2")
 	}
@@ -430,6 +437,59 @@ func rsyncFeatureArgs(features []string) []string {
 	return args
 }
 
+// CompressionAlgorithms returns the compression algorithms supported by the local rsync.
+func CompressionAlgorithms() []string {
+	out, err := shared.RunCommand("rsync", "--version")
+	if err != nil {
+		return nil
+	}
+
+	// The list is printed on the line following the "Compress list:" header (rsync 3.2.0 and later).
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "Compress list:" || i+1 >= len(lines) {
+			continue
+		}
+
+		return strings.Fields(lines[i+1])
+	}
+
+	return nil
+}
+
+// ParseCompression parses a migration compression setting of the form "<algorithm>" or "<algorithm> -<level>".
+// The supported algorithms are "zlib", "zstd", "lz4" and "none". An empty value returns an empty algorithm.
+func ParseCompression(value string) (string, int, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return "", 0, nil
+	}
+
+	if len(fields) > 2 {
+		return "", 0, fmt.Errorf("Invalid compression %q", value)
+	}
+
+	algorithm := fields[0]
+	if !shared.ValueInSlice(algorithm, []string{"zlib", "zstd", "lz4", "none"}) {
+		return "", 0, fmt.Errorf("Unsupported compression algorithm %q", algorithm)
+	}
+
+	if len(fields) == 1 {
+		return algorithm, 0, nil
+	}
+
+	if algorithm == "none" {
+		return "", 0, fmt.Errorf("Compression level cannot be set without a compression algorithm")
+	}
+
+	level, err := strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+	if err != nil || !strings.HasPrefix(fields[1], "-") || level < 1 {
+		return "", 0, fmt.Errorf("Invalid compression level %q", fields[1])
+	}
+
+	return algorithm, level, nil
+}
+
 // AtLeast compares the local version to a minimum version.
 func AtLeast(min string) bool {
 	// Parse the current version.
@@ -0,0 +1,45 @@
+package rsync
+
+import (
+	"testing"
+)
+
+func TestParseCompression(t *testing.T) {
+	tests := []struct {
+		value     string
+		algorithm string
+		level     int
+		fail      bool
+	}{
+		{value: "", algorithm: "", level: 0},
+		{value: "zlib", algorithm: "zlib", level: 0},
+		{value: "zstd -3", algorithm: "zstd", level: 3},
+		{value: "lz4 -1", algorithm: "lz4", level: 1},
+		{value: "none", algorithm: "none", level: 0},
+		{value: "none -1", fail: true},
+		{value: "zstd 3", fail: true},
+		{value: "zstd -0", fail: true},
+		{value: "zstd -3 -4", fail: true},
+		{value: "gzip", fail: true},
+	}
+
+	for _, test := range tests {
+		algorithm, level, err := ParseCompression(test.value)
+		if test.fail {
+			if err == nil {
+				t.Errorf("Expected %q to fail", test.value)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", test.value, err)
+			continue
+		}
+
+		if algorithm != test.algorithm || level != test.level {
+			t.Errorf("Unexpected result for %q: got %q/%d, expected %q/%d", test.value, algorithm, level, test.algorithm, test.level)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Package sandbox confines the network helpers spawned by the daemon, such as dnsmasq and forkdns, so that a
+// compromised helper can do as little as possible on the host.
+//
+// Helpers are started in their own mount, IPC and UTS namespaces. They are then executed through the forksandbox
+// sub-command, which drops the capabilities the helper doesn't need from its bounding set, and either applies the
+// AppArmor profile of the helper or sets no_new_privs, before executing it.
+//
+// The helpers stay in the network namespace of the host, as dnsmasq has to serve DHCP and DNS on the bridge
+// interface of its network and forkdns has to reach the forkdns of the other cluster members.
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/subprocess"
+	"github.com/canonical/lxd/shared"
+)
+
+// Profile describes how a helper is confined.
+type Profile struct {
+	// Namespaces the helper is started in, as CLONE_NEW* flags.
+	Namespaces uintptr
+
+	// Capabilities kept in the bounding set of the helper when it is started as root.
+	Capabilities []string
+}
+
+// namespaces are the namespaces every helper is started in.
+const namespaces = unix.CLONE_NEWNS | unix.CLONE_NEWIPC | unix.CLONE_NEWUTS
+
+// Dnsmasq is the profile of the dnsmasq of managed networks. dnsmasq starts as root to bind its ports and open
+// its raw sockets, and then switches to the unprivileged user itself, keeping only the network capabilities.
+var Dnsmasq = Profile{
+	Namespaces:   namespaces,
+	Capabilities: []string{"chown", "dac_override", "net_admin", "net_bind_service", "net_raw", "setgid", "setuid"},
+}
+
+// ForkDNS is the profile of forkdns, which is started as the unprivileged user and doesn't need any capability.
+var ForkDNS = Profile{
+	Namespaces: namespaces,
+}
+
+// capabilities maps the names of the capabilities which profiles can keep to their numbers.
+var capabilities = map[string]int{
+	"chown":            unix.CAP_CHOWN,
+	"dac_override":     unix.CAP_DAC_OVERRIDE,
+	"net_admin":        unix.CAP_NET_ADMIN,
+	"net_bind_service": unix.CAP_NET_BIND_SERVICE,
+	"net_raw":          unix.CAP_NET_RAW,
+	"setgid":           unix.CAP_SETGID,
+	"setuid":           unix.CAP_SETUID,
+}
+
+// Enabled returns whether helpers are confined. Confinement can be turned off for debugging by setting
+// LXD_SECURITY_SANDBOX to false.
+func Enabled() bool {
+	return !shared.IsFalse(os.Getenv("LXD_SECURITY_SANDBOX"))
+}
+
+// Apply confines the given helper process according to the profile.
+// The execPath is the path of the LXD binary, whose forksandbox sub-command executes the helper.
+func (p Profile) Apply(proc *subprocess.Process, execPath string) {
+	if !Enabled() {
+		return
+	}
+
+	proc.SetSandbox([]string{execPath, "forksandbox", strings.Join(p.Capabilities, ","), "--"})
+
+	if proc.SysProcAttr == nil {
+		proc.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	proc.SysProcAttr.Cloneflags |= p.Namespaces
+}
+
+// Exec drops all the capabilities but the given ones from the bounding set when running as root, sets
+// no_new_privs unless the command applies an AppArmor profile, and executes the given command.
+// It only returns on error.
+func Exec(keep []string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("Missing command")
+	}
+
+	keepSet := map[int]bool{}
+	for _, name := range keep {
+		if name == "" {
+			continue
+		}
+
+		capability, ok := capabilities[name]
+		if !ok {
+			return fmt.Errorf("Unknown capability %q", name)
+		}
+
+		keepSet[capability] = true
+	}
+
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return err
+	}
+
+	// The bounding set and no_new_privs are per thread, so they must be set on the thread calling execve.
+	runtime.LockOSThread()
+
+	if os.Geteuid() == 0 {
+		lastCap, err := lastCapability()
+		if err != nil {
+			return err
+		}
+
+		for capability := 0; capability <= lastCap; capability++ {
+			if keepSet[capability] {
+				continue
+			}
+
+			err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(capability), 0, 0, 0)
+			if err != nil {
+				return fmt.Errorf("Failed dropping capability %d from bounding set: %w", capability, err)
+			}
+		}
+	}
+
+	// AppArmor refuses most profile transitions under no_new_privs, so leave it unset when the command changes
+	// its AppArmor profile. The profile then confines the helper instead.
+	if filepath.Base(args[0]) != "aa-exec" {
+		err = unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0)
+		if err != nil {
+			return fmt.Errorf("Failed setting no_new_privs: %w", err)
+		}
+	}
+
+	return unix.Exec(path, args, os.Environ())
+}
+
+// lastCapability returns the highest capability number supported by the kernel.
+func lastCapability() (int, error) {
+	content, err := os.ReadFile("/proc/sys/kernel/cap_last_cap")
+	if err != nil {
+		return -1, fmt.Errorf("Failed reading last capability: %w", err)
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(content)))
+}
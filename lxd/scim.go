@@ -0,0 +1,915 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/auth/scim"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// scimHandlerFunc handles a SCIM request, returning the HTTP status code and the resource to send back.
+type scimHandlerFunc func(s *state.State, r *http.Request) (int, any, error)
+
+// scimRoutes registers the handlers of the SCIM 2.0 endpoint, used by identity providers to provision OIDC identities
+// and their membership of authorization groups.
+func scimRoutes(d *Daemon, router *mux.Router) {
+	routes := []struct {
+		method  string
+		path    string
+		handler scimHandlerFunc
+	}{
+		{http.MethodGet, "/ServiceProviderConfig", scimServiceProviderConfigGet},
+		{http.MethodGet, "/Users", scimUsersGet},
+		{http.MethodPost, "/Users", scimUsersPost},
+		{http.MethodGet, "/Users/{id}", scimUserGet},
+		{http.MethodPut, "/Users/{id}", scimUserPut},
+		{http.MethodPatch, "/Users/{id}", scimUserPatch},
+		{http.MethodDelete, "/Users/{id}", scimUserDelete},
+		{http.MethodGet, "/Groups", scimGroupsGet},
+		{http.MethodPost, "/Groups", scimGroupsPost},
+		{http.MethodGet, "/Groups/{id}", scimGroupGet},
+		{http.MethodPut, "/Groups/{id}", scimGroupPut},
+		{http.MethodPatch, "/Groups/{id}", scimGroupPatch},
+		{http.MethodDelete, "/Groups/{id}", scimGroupDelete},
+	}
+
+	for _, route := range routes {
+		router.HandleFunc("/scim/v2"+route.path, scimHandle(d, route.handler)).Methods(route.method)
+	}
+}
+
+// scimHandle authenticates the identity provider with the configured bearer token before calling the handler, and
+// renders its result or error as SCIM.
+func scimHandle(d *Daemon, handler scimHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var status int
+		var resource any
+		var err error
+
+		// Block requests until the cluster database is set up.
+		select {
+		case <-d.setupChan:
+		default:
+			err = scim.NewError(http.StatusServiceUnavailable, "", "LXD daemon setup in progress")
+		}
+
+		s := d.State()
+		if err == nil {
+			err = scimAuthenticate(s, r)
+		}
+
+		if err == nil {
+			status, resource, err = handler(s, r)
+		}
+
+		if err != nil {
+			scimErr := &scim.Error{}
+			if !errors.As(err, &scimErr) {
+				code, found := api.StatusErrorMatch(err)
+				if !found {
+					code = http.StatusInternalServerError
+				}
+
+				scimType := ""
+				if code == http.StatusConflict {
+					scimType = scim.ErrorUniqueness
+				}
+
+				scimErr = scim.NewError(code, scimType, "%v", err)
+			}
+
+			if scimErr.Code() == http.StatusInternalServerError {
+				logger.Warn("Failed handling SCIM request", logger.Ctx{"url": r.URL.String(), "method": r.Method, "err": err})
+			}
+
+			status = scimErr.Code()
+			resource = scimErr
+		}
+
+		w.Header().Set("Content-Type", scim.ContentType)
+		w.WriteHeader(status)
+
+		if resource == nil {
+			return
+		}
+
+		err = json.NewEncoder(w).Encode(resource)
+		if err != nil {
+			logger.Warn("Failed writing SCIM response", logger.Ctx{"url": r.URL.String(), "err": err})
+		}
+	}
+}
+
+// scimAuthenticate checks the bearer token of the request against the configured SCIM token. The endpoint doesn't
+// exist as far as clients are concerned if no token is configured.
+func scimAuthenticate(s *state.State, r *http.Request) error {
+	token := s.GlobalConfig.SCIMToken()
+	if token == "" {
+		return scim.NewError(http.StatusNotFound, "", "SCIM provisioning isn't enabled")
+	}
+
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+		return scim.NewError(http.StatusUnauthorized, "", "Invalid SCIM bearer token")
+	}
+
+	return nil
+}
+
+func scimServiceProviderConfigGet(s *state.State, r *http.Request) (int, any, error) {
+	return http.StatusOK, scim.ServiceProviderConfig{
+		Schemas: []string{scim.SchemaServiceProviderConfig},
+		Patch:   scim.Supported{Supported: true},
+		Filter:  scim.Filter{Supported: true, MaxResults: 1000},
+		AuthenticationSchemes: []scim.AuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "OAuth Bearer Token",
+			Description: "Authentication using the token set in the oidc.scim.token server configuration key",
+		}},
+	}, nil
+}
+
+func scimUsersGet(s *state.State, r *http.Request) (int, any, error) {
+	authMethod := dbCluster.AuthMethod(api.AuthenticationMethodOIDC)
+	filter := dbCluster.IdentityFilter{AuthMethod: &authMethod}
+	if r.FormValue("filter") != "" {
+		attribute, value, err := scim.ParseFilter(r.FormValue("filter"))
+		if err != nil {
+			return 0, nil, err
+		}
+
+		// User names are the email addresses of the identities.
+		if !strings.EqualFold(attribute, "userName") {
+			return 0, nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidFilter, "Unsupported filter attribute %q", attribute)
+		}
+
+		filter.Identifier = &value
+	}
+
+	var users []scim.User
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		identities, err := dbCluster.GetIdentitys(ctx, tx.Tx(), filter)
+		if err != nil {
+			return err
+		}
+
+		users = make([]scim.User, 0, len(identities))
+		for _, identity := range identities {
+			user, err := scimUserResource(ctx, tx, r, identity)
+			if err != nil {
+				return err
+			}
+
+			users = append(users, *user)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	startIndex, count, err := scimPagination(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, scim.NewListResponse(users, startIndex, count), nil
+}
+
+func scimUsersPost(s *state.State, r *http.Request) (int, any, error) {
+	user := scim.User{}
+	err := json.NewDecoder(r.Body).Decode(&user)
+	if err != nil {
+		return 0, nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Invalid request body: %v", err)
+	}
+
+	if user.Email() == "" {
+		return 0, nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "User name is required")
+	}
+
+	if !user.IsActive() {
+		return 0, nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Inactive users can't be provisioned")
+	}
+
+	// The OIDC subject isn't known yet. It's recorded on the first login of the user.
+	metadata, err := json.Marshal(dbCluster.OIDCMetadata{})
+	if err != nil {
+		return 0, nil, fmt.Errorf("Failed to marshal OIDC identity metadata: %w", err)
+	}
+
+	var created *scim.User
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		identity := dbCluster.Identity{
+			AuthMethod: api.AuthenticationMethodOIDC,
+			Type:       api.IdentityTypeOIDCClient,
+			Identifier: user.Email(),
+			Name:       user.FullName(),
+			Metadata:   string(metadata),
+		}
+
+		id, err := dbCluster.CreateIdentity(ctx, tx.Tx(), identity)
+		if err != nil {
+			return err
+		}
+
+		identity.ID = int(id)
+		created, err = scimUserResource(ctx, tx, r, identity)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = scimRefreshIdentities(s, lifecycle.IdentityCreated.Event(api.AuthenticationMethodOIDC, created.UserName, request.CreateRequestor(r), nil))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusCreated, created, nil
+}
+
+func scimUserGet(s *state.State, r *http.Request) (int, any, error) {
+	var user *scim.User
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		identity, err := scimGetIdentity(ctx, tx, mux.Vars(r)["id"])
+		if err != nil {
+			return err
+		}
+
+		user, err = scimUserResource(ctx, tx, r, *identity)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, user, nil
+}
+
+func scimUserPut(s *state.State, r *http.Request) (int, any, error) {
+	user := scim.User{}
+	err := json.NewDecoder(r.Body).Decode(&user)
+	if err != nil {
+		return 0, nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Invalid request body: %v", err)
+	}
+
+	return scimUserUpdate(s, r, func(*scim.User) (*scim.User, error) { return &user, nil })
+}
+
+func scimUserPatch(s *state.State, r *http.Request) (int, any, error) {
+	patch := scim.PatchOp{}
+	err := json.NewDecoder(r.Body).Decode(&patch)
+	if err != nil {
+		return 0, nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Invalid request body: %v", err)
+	}
+
+	return scimUserUpdate(s, r, func(user *scim.User) (*scim.User, error) {
+		for _, op := range patch.Operations {
+			if strings.EqualFold(op.Op, "remove") {
+				return nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidPath, "User attributes can't be removed")
+			}
+
+			// Without a path, the value holds the attributes to set.
+			values := map[string]any{op.Path: op.Value}
+			if op.Path == "" {
+				err := scimDecodeValue(op.Value, &values)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			for path, value := range values {
+				err := scimUserPatchAttribute(user, path, value)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		return user, nil
+	})
+}
+
+// scimUserPatchAttribute sets the user attribute with the given path to the given value.
+func scimUserPatchAttribute(user *scim.User, path string, value any) error {
+	switch strings.ToLower(path) {
+	case "active":
+		// Some identity providers send booleans as strings.
+		active, err := strconv.ParseBool(fmt.Sprint(value))
+		if err != nil {
+			return scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Invalid value for %q: %v", path, value)
+		}
+
+		user.Active = &active
+	case "username":
+		user.UserName = fmt.Sprint(value)
+		user.Emails = nil
+	case "displayname":
+		user.DisplayName = fmt.Sprint(value)
+	case "emails":
+		return scimDecodeValue(value, &user.Emails)
+	case "externalid":
+		user.ExternalID = fmt.Sprint(value)
+	default:
+		// Ignore attributes LXD doesn't record, such as phone numbers or addresses.
+		logger.Debug("Ignoring unsupported SCIM user attribute", logger.Ctx{"path": path})
+	}
+
+	return nil
+}
+
+// scimUserUpdate applies the changes made by the given function to the user with the ID of the request. Deactivated
+// users are deprovisioned by deleting their identity.
+func scimUserUpdate(s *state.State, r *http.Request, change func(user *scim.User) (*scim.User, error)) (int, any, error) {
+	var action lifecycle.IdentityAction
+	var identifier string
+	var updated *scim.User
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		identity, err := scimGetIdentity(ctx, tx, mux.Vars(r)["id"])
+		if err != nil {
+			return err
+		}
+
+		current, err := scimUserResource(ctx, tx, r, *identity)
+		if err != nil {
+			return err
+		}
+
+		user, err := change(current)
+		if err != nil {
+			return err
+		}
+
+		identifier = identity.Identifier
+		if !user.IsActive() {
+			err = dbCluster.DeleteIdentity(ctx, tx.Tx(), identity.AuthMethod, identity.Identifier)
+			if err != nil {
+				return err
+			}
+
+			action = lifecycle.IdentityDeleted
+			updated = current
+			updated.Active = user.Active
+			updated.Groups = nil
+			return nil
+		}
+
+		if user.Email() == "" {
+			return scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "User name is required")
+		}
+
+		identity.Identifier = user.Email()
+		identity.Name = user.FullName()
+		err = dbCluster.UpdateIdentity(ctx, tx.Tx(), identity.AuthMethod, identifier, *identity)
+		if err != nil {
+			return err
+		}
+
+		action = lifecycle.IdentityUpdated
+		updated, err = scimUserResource(ctx, tx, r, *identity)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = scimRefreshIdentities(s, action.Event(api.AuthenticationMethodOIDC, identifier, request.CreateRequestor(r), nil))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, updated, nil
+}
+
+func scimUserDelete(s *state.State, r *http.Request) (int, any, error) {
+	var identifier string
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		identity, err := scimGetIdentity(ctx, tx, mux.Vars(r)["id"])
+		if err != nil {
+			return err
+		}
+
+		identifier = identity.Identifier
+		return dbCluster.DeleteIdentity(ctx, tx.Tx(), identity.AuthMethod, identity.Identifier)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = scimRefreshIdentities(s, lifecycle.IdentityDeleted.Event(api.AuthenticationMethodOIDC, identifier, request.CreateRequestor(r), nil))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+func scimGroupsGet(s *state.State, r *http.Request) (int, any, error) {
+	filter := dbCluster.AuthGroupFilter{}
+	if r.FormValue("filter") != "" {
+		attribute, value, err := scim.ParseFilter(r.FormValue("filter"))
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if !strings.EqualFold(attribute, "displayName") {
+			return 0, nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidFilter, "Unsupported filter attribute %q", attribute)
+		}
+
+		filter.Name = &value
+	}
+
+	// Identity providers exclude the members when they only look up a group.
+	excludeMembers := strings.EqualFold(r.FormValue("excludedAttributes"), "members")
+
+	var groups []scim.Group
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		authGroups, err := dbCluster.GetAuthGroups(ctx, tx.Tx(), filter)
+		if err != nil {
+			return err
+		}
+
+		groups = make([]scim.Group, 0, len(authGroups))
+		for _, authGroup := range authGroups {
+			group, err := scimGroupResource(ctx, tx, r, authGroup)
+			if err != nil {
+				return err
+			}
+
+			if excludeMembers {
+				group.Members = nil
+			}
+
+			groups = append(groups, *group)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	startIndex, count, err := scimPagination(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, scim.NewListResponse(groups, startIndex, count), nil
+}
+
+func scimGroupsPost(s *state.State, r *http.Request) (int, any, error) {
+	group := scim.Group{}
+	err := json.NewDecoder(r.Body).Decode(&group)
+	if err != nil {
+		return 0, nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Invalid request body: %v", err)
+	}
+
+	err = validateGroupName(group.DisplayName)
+	if err != nil {
+		return 0, nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "%v", err)
+	}
+
+	var created *scim.Group
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		identityIDs, err := scimMemberIdentityIDs(ctx, tx, group.Members)
+		if err != nil {
+			return err
+		}
+
+		authGroup := dbCluster.AuthGroup{Name: group.DisplayName}
+		id, err := dbCluster.CreateAuthGroup(ctx, tx.Tx(), authGroup)
+		if err != nil {
+			return err
+		}
+
+		authGroup.ID = int(id)
+		err = dbCluster.SetAuthGroupIdentities(ctx, tx.Tx(), authGroup.ID, identityIDs)
+		if err != nil {
+			return err
+		}
+
+		created, err = scimGroupResource(ctx, tx, r, authGroup)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = scimRefreshIdentities(s, lifecycle.AuthGroupCreated.Event(group.DisplayName, request.CreateRequestor(r), nil))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusCreated, created, nil
+}
+
+func scimGroupGet(s *state.State, r *http.Request) (int, any, error) {
+	var group *scim.Group
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		authGroup, err := scimGetAuthGroup(ctx, tx, mux.Vars(r)["id"])
+		if err != nil {
+			return err
+		}
+
+		group, err = scimGroupResource(ctx, tx, r, *authGroup)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, group, nil
+}
+
+func scimGroupPut(s *state.State, r *http.Request) (int, any, error) {
+	group := scim.Group{}
+	err := json.NewDecoder(r.Body).Decode(&group)
+	if err != nil {
+		return 0, nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Invalid request body: %v", err)
+	}
+
+	return scimGroupUpdate(s, r, func(*scim.Group) (*scim.Group, error) { return &group, nil })
+}
+
+func scimGroupPatch(s *state.State, r *http.Request) (int, any, error) {
+	patch := scim.PatchOp{}
+	err := json.NewDecoder(r.Body).Decode(&patch)
+	if err != nil {
+		return 0, nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Invalid request body: %v", err)
+	}
+
+	return scimGroupUpdate(s, r, func(group *scim.Group) (*scim.Group, error) {
+		for _, op := range patch.Operations {
+			err := scimGroupPatchOperation(group, op)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return group, nil
+	})
+}
+
+// scimGroupPatchOperation applies the given operation to the name or members of the group.
+func scimGroupPatchOperation(group *scim.Group, op scim.PatchOperation) error {
+	attribute, selected, err := scim.ParseValuePath(op.Path)
+	if err != nil {
+		return err
+	}
+
+	// Without a path, the value holds the attributes to set.
+	if attribute == "" {
+		if !strings.EqualFold(op.Op, "replace") && !strings.EqualFold(op.Op, "add") {
+			return scim.NewError(http.StatusBadRequest, scim.ErrorInvalidPath, "Operation %q requires a path", op.Op)
+		}
+
+		values := scim.Group{}
+		err := scimDecodeValue(op.Value, &values)
+		if err != nil {
+			return err
+		}
+
+		if values.DisplayName != "" {
+			group.DisplayName = values.DisplayName
+		}
+
+		if values.Members != nil {
+			return scimGroupPatchOperation(group, scim.PatchOperation{Op: op.Op, Path: "members", Value: values.Members})
+		}
+
+		return nil
+	}
+
+	if strings.EqualFold(attribute, "displayName") {
+		if strings.EqualFold(op.Op, "remove") {
+			return scim.NewError(http.StatusBadRequest, scim.ErrorInvalidPath, "Group name can't be removed")
+		}
+
+		group.DisplayName = fmt.Sprint(op.Value)
+		return nil
+	}
+
+	if !strings.EqualFold(attribute, "members") {
+		logger.Debug("Ignoring unsupported SCIM group attribute", logger.Ctx{"path": op.Path})
+		return nil
+	}
+
+	var members []scim.Member
+	if op.Value != nil {
+		err := scimDecodeValue(op.Value, &members)
+		if err != nil {
+			return err
+		}
+	}
+
+	if selected != "" {
+		members = append(members, scim.Member{Value: selected})
+	}
+
+	switch strings.ToLower(op.Op) {
+	case "add":
+		group.Members = append(group.Members, members...)
+	case "replace":
+		group.Members = members
+	case "remove":
+		// Removing the members attribute without selecting members removes them all.
+		if len(members) == 0 {
+			group.Members = nil
+			return nil
+		}
+
+		kept := make([]scim.Member, 0, len(group.Members))
+		for _, member := range group.Members {
+			removed := false
+			for _, m := range members {
+				if m.Value == member.Value {
+					removed = true
+					break
+				}
+			}
+
+			if !removed {
+				kept = append(kept, member)
+			}
+		}
+
+		group.Members = kept
+	default:
+		return scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Unsupported operation %q", op.Op)
+	}
+
+	return nil
+}
+
+// scimGroupUpdate applies the changes made by the given function to the group with the ID of the request.
+func scimGroupUpdate(s *state.State, r *http.Request, change func(group *scim.Group) (*scim.Group, error)) (int, any, error) {
+	var events []api.EventLifecycle
+	var updated *scim.Group
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		authGroup, err := scimGetAuthGroup(ctx, tx, mux.Vars(r)["id"])
+		if err != nil {
+			return err
+		}
+
+		current, err := scimGroupResource(ctx, tx, r, *authGroup)
+		if err != nil {
+			return err
+		}
+
+		group, err := change(current)
+		if err != nil {
+			return err
+		}
+
+		if group.DisplayName != "" && group.DisplayName != authGroup.Name {
+			err = validateGroupName(group.DisplayName)
+			if err != nil {
+				return scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "%v", err)
+			}
+
+			err = dbCluster.RenameAuthGroup(ctx, tx.Tx(), authGroup.Name, group.DisplayName)
+			if err != nil {
+				return err
+			}
+
+			events = append(events, lifecycle.AuthGroupRenamed.Event(group.DisplayName, request.CreateRequestor(r), map[string]any{"old_name": authGroup.Name}))
+			authGroup.Name = group.DisplayName
+		}
+
+		identityIDs, err := scimMemberIdentityIDs(ctx, tx, group.Members)
+		if err != nil {
+			return err
+		}
+
+		err = dbCluster.SetAuthGroupIdentities(ctx, tx.Tx(), authGroup.ID, identityIDs)
+		if err != nil {
+			return err
+		}
+
+		events = append(events, lifecycle.AuthGroupUpdated.Event(authGroup.Name, request.CreateRequestor(r), nil))
+		updated, err = scimGroupResource(ctx, tx, r, *authGroup)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = scimRefreshIdentities(s, events...)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, updated, nil
+}
+
+func scimGroupDelete(s *state.State, r *http.Request) (int, any, error) {
+	var name string
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		authGroup, err := scimGetAuthGroup(ctx, tx, mux.Vars(r)["id"])
+		if err != nil {
+			return err
+		}
+
+		name = authGroup.Name
+		return dbCluster.DeleteAuthGroup(ctx, tx.Tx(), authGroup.Name)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = scimRefreshIdentities(s, lifecycle.AuthGroupDeleted.Event(name, request.CreateRequestor(r), nil))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// scimRefreshIdentities refreshes the identity cache of all cluster members after a change of identities or group
+// memberships, then sends the given lifecycle events.
+func scimRefreshIdentities(s *state.State, events ...api.EventLifecycle) error {
+	// Notify other cluster members to update their identity cache.
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return err
+	}
+
+	err = notifier(func(client lxd.InstanceServer) error {
+		_, _, err := client.RawQuery(http.MethodPost, "/internal/identity-cache-refresh", nil, "")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.UpdateIdentityCache()
+
+	for _, lc := range events {
+		s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+	}
+
+	return nil
+}
+
+// scimGetIdentity returns the OIDC identity with the given SCIM ID.
+func scimGetIdentity(ctx context.Context, tx *db.ClusterTx, id string) (*dbCluster.Identity, error) {
+	identityID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, scim.NewError(http.StatusNotFound, "", "User %q not found", id)
+	}
+
+	authMethod := dbCluster.AuthMethod(api.AuthenticationMethodOIDC)
+	identities, err := dbCluster.GetIdentitys(ctx, tx.Tx(), dbCluster.IdentityFilter{ID: &identityID, AuthMethod: &authMethod})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(identities) == 0 {
+		return nil, scim.NewError(http.StatusNotFound, "", "User %q not found", id)
+	}
+
+	return &identities[0], nil
+}
+
+// scimGetAuthGroup returns the authorization group with the given SCIM ID.
+func scimGetAuthGroup(ctx context.Context, tx *db.ClusterTx, id string) (*dbCluster.AuthGroup, error) {
+	groupID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, scim.NewError(http.StatusNotFound, "", "Group %q not found", id)
+	}
+
+	groups, err := dbCluster.GetAuthGroups(ctx, tx.Tx(), dbCluster.AuthGroupFilter{ID: &groupID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups) == 0 {
+		return nil, scim.NewError(http.StatusNotFound, "", "Group %q not found", id)
+	}
+
+	return &groups[0], nil
+}
+
+// scimMemberIdentityIDs returns the IDs of the OIDC identities referenced by the given group members.
+func scimMemberIdentityIDs(ctx context.Context, tx *db.ClusterTx, members []scim.Member) ([]int, error) {
+	identityIDs := make([]int, 0, len(members))
+	for _, member := range members {
+		identity, err := scimGetIdentity(ctx, tx, member.Value)
+		if err != nil {
+			return nil, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Invalid group member %q: %v", member.Value, err)
+		}
+
+		identityIDs = append(identityIDs, identity.ID)
+	}
+
+	return identityIDs, nil
+}
+
+// scimUserResource returns the SCIM representation of the given OIDC identity.
+func scimUserResource(ctx context.Context, tx *db.ClusterTx, r *http.Request, identity dbCluster.Identity) (*scim.User, error) {
+	groups, err := dbCluster.GetAuthGroupsByIdentityID(ctx, tx.Tx(), identity.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := true
+	id := strconv.Itoa(identity.ID)
+	user := &scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          id,
+		UserName:    identity.Identifier,
+		DisplayName: identity.Name,
+		Emails:      []scim.Email{{Value: identity.Identifier, Primary: true}},
+		Active:      &active,
+		Meta:        &scim.Meta{ResourceType: "User", Location: scimLocation(r, "Users", id)},
+	}
+
+	for _, group := range groups {
+		user.Groups = append(user.Groups, scim.Member{Value: strconv.Itoa(group.ID), Display: group.Name})
+	}
+
+	return user, nil
+}
+
+// scimGroupResource returns the SCIM representation of the given authorization group.
+func scimGroupResource(ctx context.Context, tx *db.ClusterTx, r *http.Request, authGroup dbCluster.AuthGroup) (*scim.Group, error) {
+	identities, err := dbCluster.GetIdentitiesByAuthGroupID(ctx, tx.Tx(), authGroup.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strconv.Itoa(authGroup.ID)
+	group := &scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          id,
+		DisplayName: authGroup.Name,
+		Members:     make([]scim.Member, 0, len(identities)),
+		Meta:        &scim.Meta{ResourceType: "Group", Location: scimLocation(r, "Groups", id)},
+	}
+
+	for _, identity := range identities {
+		group.Members = append(group.Members, scim.Member{Value: strconv.Itoa(identity.ID), Display: identity.Identifier})
+	}
+
+	return group, nil
+}
+
+// scimLocation returns the URL of the resource of the given type and ID.
+func scimLocation(r *http.Request, resourceType string, id string) string {
+	return fmt.Sprintf("https://%s/scim/v2/%s/%s", r.Host, resourceType, id)
+}
+
+// scimPagination returns the 1-based start index and the number of resources requested, -1 meaning all.
+func scimPagination(r *http.Request) (startIndex int, count int, err error) {
+	startIndex = 1
+	count = -1
+
+	if r.FormValue("startIndex") != "" {
+		startIndex, err = strconv.Atoi(r.FormValue("startIndex"))
+		if err != nil {
+			return 0, 0, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Invalid start index %q", r.FormValue("startIndex"))
+		}
+	}
+
+	if r.FormValue("count") != "" {
+		count, err = strconv.Atoi(r.FormValue("count"))
+		if err != nil || count < 0 {
+			return 0, 0, scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Invalid count %q", r.FormValue("count"))
+		}
+	}
+
+	return startIndex, count, nil
+}
+
+// scimDecodeValue converts the value of a patch operation into the given target.
+func scimDecodeValue(value any, target any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(b, target)
+	if err != nil {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorInvalidValue, "Invalid value %s: %v", b, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,329 @@
+//go:build linux && cgo
+
+package seccomp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// auditInstance wraps an instance for the lifecycle events of intercepted syscalls, which aren't tied to any
+// operation.
+type auditInstance struct {
+	Instance
+}
+
+// Operation returns nil as intercepted syscalls don't come from an API request.
+func (auditInstance) Operation() *operations.Operation {
+	return nil
+}
+
+// policyMknodRule is a device that a policy allows creating.
+// A negative major or minor number matches any number.
+type policyMknodRule struct {
+	block bool
+	major int64
+	minor int64
+}
+
+// policy is a syscall interception policy attached to an instance.
+type policy struct {
+	name         string
+	mknodDevices []policyMknodRule
+	mountAllowed []string
+	audit        bool
+}
+
+// instancePolicies caches the policies of the instances, keyed by project and instance name.
+// The policies are loaded when the seccomp profile of the instance is generated, so changes to a policy apply on
+// the next start of the instance.
+var instancePolicies = map[string][]*policy{}
+var instancePoliciesMu sync.Mutex
+
+// parsePolicyMknodDevices parses a comma-separated list of devices in the `c|b MAJOR:MINOR` form.
+func parsePolicyMknodDevices(value string) ([]policyMknodRule, error) {
+	rules := []policyMknodRule{}
+
+	parseNumber := func(number string) (int64, error) {
+		if number == "*" {
+			return -1, nil
+		}
+
+		return strconv.ParseInt(number, 10, 32)
+	}
+
+	for _, entry := range shared.SplitNTrimSpace(value, ",", -1, true) {
+		fields := strings.Fields(entry)
+		if len(fields) != 2 || (fields[0] != "c" && fields[0] != "b") {
+			return nil, fmt.Errorf("Device %q is not of the form 'c|b MAJOR:MINOR'", entry)
+		}
+
+		major, minor, found := strings.Cut(fields[1], ":")
+		if !found {
+			return nil, fmt.Errorf("Device %q is not of the form 'c|b MAJOR:MINOR'", entry)
+		}
+
+		rule := policyMknodRule{block: fields[0] == "b"}
+
+		var err error
+		rule.major, err = parseNumber(major)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid major number in device %q: %w", entry, err)
+		}
+
+		rule.minor, err = parseNumber(minor)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid minor number in device %q: %w", entry, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// ValidatePolicyConfig validates the configuration of a syscall interception policy.
+func ValidatePolicyConfig(config map[string]string) error {
+	rules := map[string]func(value string) error{}
+
+	// lxdmeta:generate(entities=seccomp-policy; group=config-options; key=mknod.devices)
+	// Specify the devices as a comma-separated list of entries in the `c|b MAJOR:MINOR` form, where `c` is for character devices and `b` for block devices.
+	// Use `*` to match any major or minor number, for example `b 7:*` for all loop devices.
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Devices that containers can create with `mknod`
+	rules["mknod.devices"] = func(value string) error {
+		_, err := parsePolicyMknodDevices(value)
+		return err
+	}
+
+	// lxdmeta:generate(entities=seccomp-policy; group=config-options; key=mount.allowed)
+	// Specify a comma-separated list of file systems that containers can mount, for example `ext4,xfs`.
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: File systems that containers can mount
+	rules["mount.allowed"] = validate.Optional(validate.IsListOf(validate.IsNotEmpty))
+
+	// lxdmeta:generate(entities=seccomp-policy; group=config-options; key=audit)
+	// When enabled, LXD emits an `instance-syscall-intercepted` lifecycle event for every syscall that the policy covers, whether it is allowed or not.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  required: no
+	//  shortdesc: Whether to emit events for intercepted syscalls
+	rules["audit"] = validate.Optional(validate.IsBool)
+
+	// lxdmeta:generate(entities=seccomp-policy; group=config-options; key=user.*)
+	//
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: User-provided free-form key/value pairs
+
+	checkedFields := map[string]struct{}{}
+
+	// Run the validator against each field.
+	for k, validator := range rules {
+		checkedFields[k] = struct{}{} // Mark field as checked.
+		err := validator(config[k])
+		if err != nil {
+			return fmt.Errorf("Invalid value for config option %q: %w", k, err)
+		}
+	}
+
+	// Look for any unchecked fields, as these are unknown fields and validation should fail.
+	for k := range config {
+		_, checked := checkedFields[k]
+		if checked {
+			continue
+		}
+
+		// User keys are not validated.
+		if shared.IsUserConfig(k) {
+			continue
+		}
+
+		return fmt.Errorf("Invalid config option %q", k)
+	}
+
+	return nil
+}
+
+// loadPolicies loads the policies listed in `security.syscalls.intercept.policies` of the instance and caches them.
+func loadPolicies(s *state.State, c Instance) ([]*policy, error) {
+	policyNames := shared.SplitNTrimSpace(c.ExpandedConfig()["security.syscalls.intercept.policies"], ",", -1, true)
+
+	policies := make([]*policy, 0, len(policyNames))
+	if len(policyNames) > 0 {
+		err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			for _, policyName := range policyNames {
+				_, info, err := tx.GetSeccompPolicyByName(ctx, c.Project().Name, policyName)
+				if err != nil {
+					return fmt.Errorf("Failed loading syscall interception policy %q: %w", policyName, err)
+				}
+
+				p := &policy{
+					name:         info.Name,
+					mountAllowed: shared.SplitNTrimSpace(info.Config["mount.allowed"], ",", -1, true),
+					audit:        shared.IsTrue(info.Config["audit"]),
+				}
+
+				p.mknodDevices, err = parsePolicyMknodDevices(info.Config["mknod.devices"])
+				if err != nil {
+					return fmt.Errorf("Invalid syscall interception policy %q: %w", policyName, err)
+				}
+
+				policies = append(policies, p)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	instancePoliciesMu.Lock()
+	instancePolicies[project.Instance(c.Project().Name, c.Name())] = policies
+	instancePoliciesMu.Unlock()
+
+	return policies, nil
+}
+
+// getPolicies returns the cached policies of the instance.
+func getPolicies(c Instance) []*policy {
+	instancePoliciesMu.Lock()
+	defer instancePoliciesMu.Unlock()
+
+	return instancePolicies[project.Instance(c.Project().Name, c.Name())]
+}
+
+// forgetPolicies removes the cached policies of the instance.
+func forgetPolicies(c Instance) {
+	instancePoliciesMu.Lock()
+	delete(instancePolicies, project.Instance(c.Project().Name, c.Name()))
+	instancePoliciesMu.Unlock()
+}
+
+// policiesNeedMknod returns whether any of the policies allows creating devices.
+func policiesNeedMknod(policies []*policy) bool {
+	for _, p := range policies {
+		if len(p.mknodDevices) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// policiesNeedMount returns whether any of the policies allows mounting file systems.
+func policiesNeedMount(policies []*policy) bool {
+	for _, p := range policies {
+		if len(p.mountAllowed) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// policyAllowsMknod returns the first policy of the instance that allows creating the device, if any.
+func (s *Server) policyAllowsMknod(c Instance, mode uint32, dev uint64) *policy {
+	block := mode&unix.S_IFMT == unix.S_IFBLK
+	if !block && mode&unix.S_IFMT != unix.S_IFCHR {
+		return nil
+	}
+
+	major := int64(unix.Major(dev))
+	minor := int64(unix.Minor(dev))
+
+	for _, p := range getPolicies(c) {
+		for _, rule := range p.mknodDevices {
+			if rule.block != block {
+				continue
+			}
+
+			if (rule.major < 0 || rule.major == major) && (rule.minor < 0 || rule.minor == minor) {
+				return p
+			}
+		}
+	}
+
+	return nil
+}
+
+// policyAllowsMount returns the first policy of the instance that allows mounting the file system, if any.
+func (s *Server) policyAllowsMount(c Instance, fstype string) *policy {
+	for _, p := range getPolicies(c) {
+		if shared.ValueInSlice(fstype, p.mountAllowed) {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// policyAudit emits an audit event for a syscall covered by the policies of the instance.
+// If the syscall was allowed, only the policy that allowed it is considered, otherwise an event is emitted if any of
+// the policies of the instance covering that syscall has auditing enabled.
+func (s *Server) policyAudit(c Instance, syscall string, allowedBy *policy, details map[string]any) {
+	var audited *policy
+	if allowedBy != nil {
+		if allowedBy.audit {
+			audited = allowedBy
+		}
+	} else {
+		for _, p := range getPolicies(c) {
+			covers := (syscall == "mknod" && len(p.mknodDevices) > 0) || (syscall == "mount" && len(p.mountAllowed) > 0)
+			if p.audit && covers {
+				audited = p
+				break
+			}
+		}
+	}
+
+	if audited == nil {
+		return
+	}
+
+	ctx := map[string]any{
+		"syscall": syscall,
+		"allowed": allowedBy != nil,
+	}
+
+	if allowedBy != nil {
+		ctx["policy"] = allowedBy.name
+	}
+
+	for k, v := range details {
+		ctx[k] = v
+	}
+
+	logger.Info("Intercepted syscall", logger.Ctx{"project": c.Project().Name, "instance": c.Name(), "syscall": syscall, "allowed": allowedBy != nil})
+	s.s.Events.SendLifecycle(c.Project().Name, lifecycle.InstanceSyscallIntercepted.Event(auditInstance{c}, ctx))
+}
+
+// mknodPolicyCheck returns whether a policy of the instance allows creating the device, emitting an audit event if
+// needed.
+func (s *Server) mknodPolicyCheck(c Instance, mode uint32, dev uint64) bool {
+	allowedBy := s.policyAllowsMknod(c, mode, dev)
+	s.policyAudit(c, "mknod", allowedBy, map[string]any{"major": unix.Major(dev), "minor": unix.Minor(dev)})
+
+	return allowedBy != nil
+}
@@ -665,6 +665,7 @@ func InstanceNeedsPolicy(c Instance) bool {
 		"raw.seccomp",
 		"security.syscalls.allow",
 		"security.syscalls.deny",
+		"security.syscalls.intercept.policies",
 	}
 
 	for _, k := range keys {
@@ -746,6 +747,15 @@ func InstanceNeedsIntercept(s *state.State, c Instance) (bool, error) {
 		needed = true
 	}
 
+	if config["security.syscalls.intercept.policies"] != "" {
+		err := lxcSupportSeccompNotifyContinue(s)
+		if err != nil {
+			return needed, err
+		}
+
+		needed = true
+	}
+
 	return needed, nil
 }
 
@@ -803,11 +813,17 @@ func seccompGetPolicyContent(s *state.State, c Instance) (string, error) {
 	}
 
 	if ok {
+		// Load the syscall interception policies of the instance.
+		policies, err := loadPolicies(s, c)
+		if err != nil {
+			return "", err
+		}
+
 		// Prevent the container from overriding our syscall
 		// supervision.
 		policy += seccompNotifyDisallow
 
-		if shared.IsTrue(config["security.syscalls.intercept.mknod"]) {
+		if shared.IsTrue(config["security.syscalls.intercept.mknod"]) || policiesNeedMknod(policies) {
 			policy += seccompNotifyMknod
 		}
 
@@ -827,7 +843,7 @@ func seccompGetPolicyContent(s *state.State, c Instance) (string, error) {
 			policy += seccompNotifyModule
 		}
 
-		if shared.IsTrue(config["security.syscalls.intercept.mount"]) {
+		if shared.IsTrue(config["security.syscalls.intercept.mount"]) || policiesNeedMount(policies) {
 			policy += seccompNotifyMount
 			// We block the new mount api for now to simplify mount
 			// syscall interception. Since it keeps state over
@@ -895,6 +911,7 @@ func DeleteProfile(c Instance) {
 	 * delete can fail and that's ok.
 	 */
 	_ = os.Remove(ProfilePath(c))
+	forgetPolicies(c)
 }
 
 // Server defines a seccomp server.
@@ -1337,6 +1354,10 @@ func (s *Server) doDeviceSyscall(c Instance, args *MknodArgs, siov *Iovec) int {
 
 	dev := deviceConfig.Device{}
 	dev["type"] = "unix-char"
+	if uint32(args.cMode)&unix.S_IFMT == unix.S_IFBLK {
+		dev["type"] = "unix-block"
+	}
+
 	dev["mode"] = fmt.Sprintf("%#o", args.cMode)
 	dev["major"] = fmt.Sprintf("%d", unix.Major(uint64(args.cDev)))
 	dev["minor"] = fmt.Sprintf("%d", unix.Minor(uint64(args.cDev)))
@@ -1387,14 +1408,16 @@ func (s *Server) HandleMknodSyscall(c Instance, siov *Iovec) int {
 	defer logger.Debug("Handling mknod syscall", ctx)
 
 	if C.device_allowed(C.dev_t(siov.req.data.args[2]), C.mode_t(siov.req.data.args[1])) < 0 {
-		ctx["err"] = "Device not allowed"
-		if s.s.OS.SeccompListenerContinue {
-			ctx["syscall_continue"] = "true"
-			C.seccomp_notify_update_response(siov.resp, 0, C.uint32_t(seccompUserNotifFlagContinue))
-			return 0
-		}
+		if !s.mknodPolicyCheck(c, uint32(siov.req.data.args[1]), uint64(siov.req.data.args[2])) {
+			ctx["err"] = "Device not allowed"
+			if s.s.OS.SeccompListenerContinue {
+				ctx["syscall_continue"] = "true"
+				C.seccomp_notify_update_response(siov.resp, 0, C.uint32_t(seccompUserNotifFlagContinue))
+				return 0
+			}
 
-		return int(siov.resp.error)
+			return int(siov.resp.error)
+		}
 	}
 
 	cPathBuf := [unix.PathMax]C.char{}
@@ -1452,6 +1475,10 @@ func (s *Server) HandleMknodatSyscall(c Instance, siov *Iovec) int {
 	}
 
 	siov.resp.error = C.device_allowed(C.dev_t(siov.req.data.args[3]), C.mode_t(siov.req.data.args[2]))
+	if siov.resp.error != 0 && s.mknodPolicyCheck(c, uint32(siov.req.data.args[2]), uint64(siov.req.data.args[3])) {
+		siov.resp.error = 0
+	}
+
 	if siov.resp.error != 0 {
 		ctx["err"] = "Device not allowed"
 		if s.s.OS.SeccompListenerContinue {
@@ -2711,6 +2738,12 @@ func (s *Server) MountSyscallValid(c Instance, args *MountArgs) (bool, string) {
 		return true, fuse
 	}
 
+	allowedBy := s.policyAllowsMount(c, args.fstype)
+	s.policyAudit(c, "mount", allowedBy, map[string]any{"fstype": args.fstype, "source": args.source, "target": args.target})
+	if allowedBy != nil {
+		return true, ""
+	}
+
 	return false, ""
 }
 
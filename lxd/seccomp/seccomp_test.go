@@ -18,3 +18,22 @@ func TestMountFlagsToOpts(t *testing.T) {
 		t.Fatal(fmt.Errorf("Mount options parsing failed with invalid option string: %s", opts))
 	}
 }
+
+func TestParsePolicyMknodDevices(t *testing.T) {
+	rules, err := parsePolicyMknodDevices("b 7:*, c 10:237")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []policyMknodRule{{block: true, major: 7, minor: -1}, {block: false, major: 10, minor: 237}}
+	if len(rules) != len(expected) || rules[0] != expected[0] || rules[1] != expected[1] {
+		t.Fatalf("Unexpected rules: %v", rules)
+	}
+
+	for _, value := range []string{"x 1:2", "c 1", "b a:1", "c1:2"} {
+		_, err := parsePolicyMknodDevices(value)
+		if err == nil {
+			t.Fatalf("Expected %q to be rejected", value)
+		}
+	}
+}
@@ -0,0 +1,552 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/seccomp"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/validate"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var seccompPoliciesCmd = APIEndpoint{
+	Path: "seccomp-policies",
+
+	Get:  APIEndpointAction{Handler: seccompPoliciesGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewInstances)},
+	Post: APIEndpointAction{Handler: seccompPoliciesPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var seccompPolicyCmd = APIEndpoint{
+	Path: "seccomp-policies/{name}",
+
+	Delete: APIEndpointAction{Handler: seccompPolicyDelete, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Get:    APIEndpointAction{Handler: seccompPolicyGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewInstances)},
+	Put:    APIEndpointAction{Handler: seccompPolicyPut, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Patch:  APIEndpointAction{Handler: seccompPolicyPut, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+// seccompPolicyUsedBy returns the URLs of the instances of the project that use the syscall interception policy.
+func seccompPolicyUsedBy(ctx context.Context, s *state.State, tx *db.ClusterTx, projectName string, policyName string) ([]string, error) {
+	usedBy := []string{}
+
+	instanceType := instancetype.Container
+	filter := cluster.InstanceFilter{Project: &projectName, Type: &instanceType}
+
+	err := tx.InstanceList(ctx, func(inst db.InstanceArgs, _ api.Project) error {
+		expandedConfig := instancetype.ExpandInstanceConfig(s.GlobalConfig.Dump(), inst.Config, inst.Profiles)
+
+		policyNames := shared.SplitNTrimSpace(expandedConfig["security.syscalls.intercept.policies"], ",", -1, true)
+		if shared.ValueInSlice(policyName, policyNames) {
+			usedBy = append(usedBy, api.NewURL().Path(version.APIVersion, "instances", inst.Name).Project(projectName).String())
+		}
+
+		return nil
+	}, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return usedBy, nil
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/seccomp-policies seccomp-policies seccomp_policies_get
+//
+//	Get the syscall interception policies
+//
+//	Returns a list of syscall interception policies (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/seccomp-policies/loop-devices"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/seccomp-policies?recursion=1 seccomp-policies seccomp_policies_get_recursion1
+//
+//	Get the syscall interception policies
+//
+//	Returns a list of syscall interception policies (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of syscall interception policies
+//	          items:
+//	            $ref: "#/definitions/SeccompPolicy"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func seccompPoliciesGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	recursion := util.IsRecursionRequest(r)
+
+	resultString := []string{}
+	resultMap := []api.SeccompPolicy{}
+
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		policyNames, err := tx.GetSeccompPolicies(ctx, projectName)
+		if err != nil {
+			return err
+		}
+
+		for _, policyName := range policyNames {
+			if !recursion {
+				resultString = append(resultString, api.NewURL().Path(version.APIVersion, "seccomp-policies", policyName).String())
+				continue
+			}
+
+			_, policy, err := tx.GetSeccompPolicyByName(ctx, projectName, policyName)
+			if err != nil {
+				return err
+			}
+
+			policy.UsedBy, err = seccompPolicyUsedBy(ctx, s, tx, projectName, policyName)
+			if err != nil {
+				return err
+			}
+
+			resultMap = append(resultMap, *policy)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/seccomp-policies seccomp-policies seccomp_policies_post
+//
+//	Add a syscall interception policy
+//
+//	Creates a new syscall interception policy.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: policy
+//	    description: Syscall interception policy
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/SeccompPoliciesPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func seccompPoliciesPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	req := api.SeccompPoliciesPost{}
+
+	// Parse the request into a record.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Name == "" {
+		return response.BadRequest(fmt.Errorf("No name provided"))
+	}
+
+	err = validate.IsURLSegmentSafe(req.Name)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid policy name %q: %w", req.Name, err))
+	}
+
+	err = seccomp.ValidatePolicyConfig(req.Config)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, _, err := tx.GetSeccompPolicyByName(ctx, projectName, req.Name)
+		if err == nil {
+			return api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "The syscall interception policy already exists")
+		}
+
+		if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		_, err = tx.CreateSeccompPolicy(ctx, projectName, &req)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	lc := lifecycle.SeccompPolicyCreated.Event(projectName, req.Name, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/seccomp-policies/{name} seccomp-policies seccomp_policy_delete
+//
+//	Delete the syscall interception policy
+//
+//	Removes the syscall interception policy.
+//	The policy must not be used by any instance.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func seccompPolicyDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	policyName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, _, err := tx.GetSeccompPolicyByName(ctx, projectName, policyName)
+		if err != nil {
+			return err
+		}
+
+		usedBy, err := seccompPolicyUsedBy(ctx, s, tx, projectName, policyName)
+		if err != nil {
+			return err
+		}
+
+		if len(usedBy) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "The syscall interception policy is currently in use")
+		}
+
+		return tx.DeleteSeccompPolicy(ctx, id)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.SeccompPolicyDeleted.Event(projectName, policyName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/seccomp-policies/{name} seccomp-policies seccomp_policy_get
+//
+//	Get the syscall interception policy
+//
+//	Gets a specific syscall interception policy.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Syscall interception policy
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/SeccompPolicy"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func seccompPolicyGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	policyName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var policy *api.SeccompPolicy
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, policy, err = tx.GetSeccompPolicyByName(ctx, projectName, policyName)
+		if err != nil {
+			return err
+		}
+
+		policy.UsedBy, err = seccompPolicyUsedBy(ctx, s, tx, projectName, policyName)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, policy, []any{policy.Name, policy.Description, policy.Config})
+}
+
+// swagger:operation PATCH /1.0/seccomp-policies/{name} seccomp-policies seccomp_policy_patch
+//
+//	Partially update the syscall interception policy
+//
+//	Updates a subset of the syscall interception policy configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: policy
+//	    description: Syscall interception policy configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/SeccompPolicyPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/seccomp-policies/{name} seccomp-policies seccomp_policy_put
+//
+//	Update the syscall interception policy
+//
+//	Updates the entire syscall interception policy configuration.
+//	Running instances pick up the changes on their next start.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: policy
+//	    description: Syscall interception policy configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/SeccompPolicyPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func seccompPolicyPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	policyName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.SeccompPolicyPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Get the existing policy.
+		id, policy, err := tx.GetSeccompPolicyByName(ctx, projectName, policyName)
+		if err != nil {
+			return err
+		}
+
+		// Validate the ETag.
+		err = util.EtagCheck(r, []any{policy.Name, policy.Description, policy.Config})
+		if err != nil {
+			return api.StatusErrorf(http.StatusPreconditionFailed, "%w", err)
+		}
+
+		if r.Method == http.MethodPatch {
+			if req.Config == nil {
+				req.Config = map[string]string{}
+			}
+
+			// If config being updated via "patch" method, then merge all existing config with the keys that
+			// are present in the request config.
+			for k, v := range policy.Config {
+				_, ok := req.Config[k]
+				if !ok {
+					req.Config[k] = v
+				}
+			}
+		}
+
+		err = seccomp.ValidatePolicyConfig(req.Config)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "%w", err)
+		}
+
+		return tx.UpdateSeccompPolicy(ctx, id, &req)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.SeccompPolicyUpdated.Event(projectName, policyName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
@@ -0,0 +1,756 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/instance/sshkeys"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/validate"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var sshKeysCmd = APIEndpoint{
+	Path: "ssh-keys",
+
+	Get:  APIEndpointAction{Handler: sshKeysGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanView)},
+	Post: APIEndpointAction{Handler: sshKeysPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEdit)},
+}
+
+var sshKeyCmd = APIEndpoint{
+	Path: "ssh-keys/{name}",
+
+	Get:    APIEndpointAction{Handler: sshKeyGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanView)},
+	Put:    APIEndpointAction{Handler: sshKeyPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEdit)},
+	Delete: APIEndpointAction{Handler: sshKeyDelete, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEdit)},
+}
+
+// swagger:operation GET /1.0/ssh-keys ssh_keys ssh_keys_get
+//
+//	Get the SSH keys
+//
+//	Returns a list of SSH keys of the project (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/ssh-keys/alice-laptop",
+//	              "/1.0/ssh-keys/bob-desktop"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/ssh-keys?recursion=1 ssh_keys ssh_keys_get_recursion1
+//
+//	Get the SSH keys
+//
+//	Returns a list of SSH keys of the project.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of SSH keys
+//	          items:
+//	            $ref: "#/definitions/SSHKey"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func sshKeysGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+	projectName := request.ProjectParam(r)
+
+	var keys []dbCluster.SSHKey
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		keys, err = dbCluster.GetSSHKeys(ctx, tx.Tx(), dbCluster.SSHKeyFilter{Project: &projectName})
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if util.IsRecursionRequest(r) {
+		apiKeys := make([]api.SSHKey, 0, len(keys))
+		for _, key := range keys {
+			apiKeys = append(apiKeys, sshKeyToAPI(key))
+		}
+
+		return response.SyncResponse(true, apiKeys)
+	}
+
+	urls := make([]string, 0, len(keys))
+	for _, key := range keys {
+		urls = append(urls, api.NewURL().Path(version.APIVersion, "ssh-keys", key.Name).Project(projectName).String())
+	}
+
+	return response.SyncResponse(true, urls)
+}
+
+// swagger:operation POST /1.0/ssh-keys ssh_keys ssh_keys_post
+//
+//	Add an SSH key
+//
+//	Adds an SSH public key to the project.
+//	The key is installed in the running instances selecting it with `security.ssh-keys`.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: key
+//	    description: SSH key
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/SSHKeysPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "409":
+//	    $ref: "#/responses/Conflict"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func sshKeysPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+	projectName := request.ProjectParam(r)
+
+	req := api.SSHKeysPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = sshKeyValidateName(req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = sshKeyValidate(req.SSHKeyPut)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Check that the project exists, as keys can be added to any project.
+		_, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		exists, err := dbCluster.SSHKeyExists(ctx, tx.Tx(), projectName, req.Name)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return api.StatusErrorf(http.StatusConflict, "An SSH key with name %q already exists", req.Name)
+		}
+
+		_, err = dbCluster.CreateSSHKey(ctx, tx.Tx(), dbCluster.SSHKey{
+			Project:     projectName,
+			Name:        req.Name,
+			Description: req.Description,
+			Username:    req.User,
+			PublicKey:   strings.TrimSpace(req.PublicKey),
+		})
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	go sshKeysSyncProject(s, projectName)
+
+	lc := lifecycle.SSHKeyCreated.Event(projectName, req.Name, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation GET /1.0/ssh-keys/{name} ssh_keys ssh_key_get
+//
+//	Get the SSH key
+//
+//	Gets a specific SSH key of the project.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: SSH key
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/SSHKey"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func sshKeyGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var key *dbCluster.SSHKey
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		key, err = dbCluster.GetSSHKey(ctx, tx.Tx(), projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	apiKey := sshKeyToAPI(*key)
+
+	return response.SyncResponseETag(true, apiKey, apiKey.Writable())
+}
+
+// swagger:operation PUT /1.0/ssh-keys/{name} ssh_keys ssh_key_put
+//
+//	Update the SSH key
+//
+//	Updates the SSH key. The running instances using it are updated to the new key, which allows rotating keys.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: key
+//	    description: SSH key
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/SSHKeyPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func sshKeyPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.SSHKeyPut{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = sshKeyValidate(req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		key, err := dbCluster.GetSSHKey(ctx, tx.Tx(), projectName, name)
+		if err != nil {
+			return err
+		}
+
+		// Validate the ETag.
+		apiKey := sshKeyToAPI(*key)
+		err = util.EtagCheck(r, apiKey.Writable())
+		if err != nil {
+			return api.StatusErrorf(http.StatusPreconditionFailed, "%w", err)
+		}
+
+		key.Description = req.Description
+		key.Username = req.User
+		key.PublicKey = strings.TrimSpace(req.PublicKey)
+
+		return dbCluster.UpdateSSHKey(ctx, tx.Tx(), projectName, name, *key)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	go sshKeysSyncProject(s, projectName)
+
+	s.Events.SendLifecycle(projectName, lifecycle.SSHKeyUpdated.Event(projectName, name, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation DELETE /1.0/ssh-keys/{name} ssh_keys ssh_key_delete
+//
+//	Delete the SSH key
+//
+//	Removes the SSH key from the project. The key is revoked from the running instances using it.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func sshKeyDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.DeleteSSHKey(ctx, tx.Tx(), projectName, name)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	go sshKeysSyncProject(s, projectName)
+
+	s.Events.SendLifecycle(projectName, lifecycle.SSHKeyDeleted.Event(projectName, name, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// sshKeyToAPI returns the API representation of the given key, including its fingerprint.
+func sshKeyToAPI(key dbCluster.SSHKey) api.SSHKey {
+	apiKey := key.ToAPI()
+
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.PublicKey))
+	if err == nil {
+		apiKey.Fingerprint = ssh.FingerprintSHA256(publicKey)
+	}
+
+	return apiKey
+}
+
+// sshKeyValidateName checks that the given name can be used for an SSH key and listed in security.ssh-keys.
+func sshKeyValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("No name provided")
+	}
+
+	if strings.Contains(name, ",") || strings.TrimSpace(name) != name {
+		return fmt.Errorf("Name cannot contain commas or surrounding spaces")
+	}
+
+	err := validate.IsURLSegmentSafe(name)
+	if err != nil {
+		return fmt.Errorf("Invalid name: %w", err)
+	}
+
+	return nil
+}
+
+// sshKeyValidate checks the modifiable fields of an SSH key.
+func sshKeyValidate(req api.SSHKeyPut) error {
+	if req.User == "" {
+		return fmt.Errorf("No user provided")
+	}
+
+	if strings.ContainsAny(req.User, ":/\n") || req.User == "." || req.User == ".." {
+		return fmt.Errorf("Invalid user %q", req.User)
+	}
+
+	// Only accept a single key, without options that could be used to run commands.
+	_, _, options, rest, err := ssh.ParseAuthorizedKey([]byte(req.PublicKey))
+	if err != nil {
+		return fmt.Errorf("Invalid public key: %w", err)
+	}
+
+	if len(options) > 0 {
+		return fmt.Errorf("Public keys cannot have options")
+	}
+
+	if len(strings.TrimSpace(string(rest))) > 0 {
+		return fmt.Errorf("Only one public key can be provided")
+	}
+
+	return nil
+}
+
+// sshKeysSyncProject syncs the SSH keys of the running instances of the given project located on this member.
+// Instances located on other members are synced by their own periodic task.
+func sshKeysSyncProject(s *state.State, projectName string) {
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		logger.Warn("Failed loading instances to sync their SSH keys", logger.Ctx{"project": projectName, "err": err})
+		return
+	}
+
+	for _, inst := range instances {
+		if inst.Project().Name != projectName || !inst.IsRunning() {
+			continue
+		}
+
+		err := instanceSSHKeysSync(s, inst)
+		if err != nil {
+			logger.Warn("Failed syncing instance SSH keys", logger.Ctx{"project": projectName, "instance": inst.Name(), "err": err})
+		}
+	}
+}
+
+// instanceSSHKeysSync installs the SSH keys selected by security.ssh-keys in the authorized_keys files of the given
+// running instance, and revokes the ones that aren't selected or don't exist anymore. It only accesses the instance
+// when the selected keys differ from the ones last installed.
+func instanceSSHKeysSync(s *state.State, inst instance.Instance) error {
+	names := sshkeys.Selected(inst.ExpandedConfig())
+	previousUsers := shared.SplitNTrimSpace(inst.LocalConfig()["volatile.ssh-keys.users"], ",", -1, true)
+	if len(names) == 0 && len(previousUsers) == 0 {
+		return nil
+	}
+
+	projectName := inst.Project().Name
+
+	var keys []dbCluster.SSHKey
+	if len(names) > 0 {
+		err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+
+			keys, err = dbCluster.GetSSHKeys(ctx, tx.Tx(), dbCluster.SSHKeyFilter{Project: &projectName})
+
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed loading SSH keys: %w", err)
+		}
+	}
+
+	// Group the selected keys by user, in a stable order.
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+
+	userKeys := map[string][]string{}
+	users := []string{}
+	hash := sha256.New()
+	for _, key := range keys {
+		if !shared.ValueInSlice(key.Name, names) {
+			continue
+		}
+
+		_, ok := userKeys[key.Username]
+		if !ok {
+			users = append(users, key.Username)
+		}
+
+		userKeys[key.Username] = append(userKeys[key.Username], key.PublicKey)
+		_, _ = fmt.Fprintf(hash, "%s\n%s\n", key.Username, key.PublicKey)
+	}
+
+	sort.Strings(users)
+	managedUsers := strings.Join(users, ",")
+
+	digest := ""
+	if len(users) > 0 {
+		digest = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	if digest == inst.LocalConfig()["volatile.ssh-keys.hash"] {
+		return nil
+	}
+
+	client, err := inst.FileSFTP()
+	if err != nil {
+		return fmt.Errorf("Failed connecting to instance: %w", err)
+	}
+
+	defer func() { _ = client.Close() }()
+
+	passwdFile, err := client.Open("/etc/passwd")
+	if err != nil {
+		return fmt.Errorf("Failed opening passwd file: %w", err)
+	}
+
+	passwd, err := io.ReadAll(passwdFile)
+	_ = passwdFile.Close()
+	if err != nil {
+		return fmt.Errorf("Failed reading passwd file: %w", err)
+	}
+
+	// Also update the users that had keys installed, so that the keys they don't have anymore are revoked.
+	for _, name := range previousUsers {
+		_, ok := userKeys[name]
+		if !ok {
+			users = append(users, name)
+		}
+	}
+
+	for _, name := range users {
+		user, err := sshkeys.LookupUser(passwd, name)
+		if err != nil {
+			// Users can be created later on, by cloud-init for example.
+			if len(userKeys[name]) > 0 {
+				return err
+			}
+
+			continue
+		}
+
+		err = instanceSSHKeysWrite(client, user, userKeys[name])
+		if err != nil {
+			return fmt.Errorf("Failed updating SSH keys of user %q: %w", name, err)
+		}
+	}
+
+	return inst.VolatileSet(map[string]string{
+		"volatile.ssh-keys.users": managedUsers,
+		"volatile.ssh-keys.hash":  digest,
+	})
+}
+
+// instanceSSHKeysWrite replaces the managed keys in the authorized_keys file of the given user, creating the file and
+// its directory when needed.
+func instanceSSHKeysWrite(client *sftp.Client, user *sshkeys.User, keys []string) error {
+	sshDir := filepath.Join(user.Home, ".ssh")
+	path := filepath.Join(sshDir, "authorized_keys")
+
+	var content []byte
+	file, err := client.Open(path)
+	if err == nil {
+		content, err = io.ReadAll(file)
+		_ = file.Close()
+		if err != nil {
+			return err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	} else if len(keys) == 0 {
+		return nil
+	}
+
+	newContent := sshkeys.Update(content, keys)
+	if string(newContent) == string(content) {
+		return nil
+	}
+
+	_, err = client.Stat(sshDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = client.MkdirAll(sshDir)
+		if err != nil {
+			return err
+		}
+
+		err = client.Chmod(sshDir, 0700)
+		if err != nil {
+			return err
+		}
+
+		err = client.Chown(sshDir, int(user.UID), int(user.GID))
+		if err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	file, err = client.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(newContent)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	err = client.Chmod(path, 0600)
+	if err != nil {
+		return err
+	}
+
+	return client.Chown(path, int(user.UID), int(user.GID))
+}
+
+func syncInstanceSSHKeysTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		instances, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Warn("Failed loading instances to sync their SSH keys", logger.Ctx{"err": err})
+			return
+		}
+
+		for _, inst := range instances {
+			if !inst.IsRunning() {
+				continue
+			}
+
+			err := instanceSSHKeysSync(s, inst)
+			if err != nil {
+				// Instances may not be ready yet, so keep trying quietly.
+				logger.Debug("Failed syncing instance SSH keys", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			}
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
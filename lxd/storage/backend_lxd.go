@@ -143,7 +143,26 @@ func (b *lxdBackend) isStatusReady() error {
 
 // ToAPI returns the storage pool as an API representation.
 func (b *lxdBackend) ToAPI() api.StoragePool {
-	return b.db
+	pool := b.db
+	pool.Capabilities = driverCapabilities(b.driver.Info())
+
+	return pool
+}
+
+// driverCapabilities returns the capabilities of a storage driver as exposed through the API.
+func driverCapabilities(info drivers.Info) *api.StoragePoolCapabilities {
+	return &api.StoragePoolCapabilities{
+		OptimizedCopy:      info.OptimizedCopy,
+		OptimizedImages:    info.OptimizedImages,
+		OptimizedBackups:   info.OptimizedBackups,
+		LiveResize:         info.LiveResize,
+		BlockMode:          info.BlockBacking,
+		Snapshots:          len(info.VolumeTypes) > 0,
+		RestoreAnySnapshot: info.RestoreAnySnapshot,
+		IOLimits:           info.BlockBacking,
+		Remote:             info.Remote,
+		Buckets:            info.Buckets,
+	}
 }
 
 // Driver returns the storage pool driver.
@@ -261,7 +280,17 @@ func (b *lxdBackend) GetResources() (*api.ResourcesStoragePool, error) {
 	l.Debug("GetResources started")
 	defer l.Debug("GetResources finished")
 
-	return b.driver.GetResources()
+	res, err := b.driver.GetResources()
+	if err != nil {
+		return nil, err
+	}
+
+	res.Forecast, err = b.getUsageForecast(res.Space.Used, res.Space.Total)
+	if err != nil {
+		l.Warn("Failed getting storage pool usage forecast", logger.Ctx{"err": err})
+	}
+
+	return res, nil
 }
 
 // IsUsed returns whether the storage pool is used by any volumes or profiles (excluding image volumes).
@@ -1030,6 +1059,18 @@ func (b *lxdBackend) CreateInstanceFromCopy(inst instance.Instance, src instance
 		return err
 	}
 
+	// Check the copy won't fill the pool beyond its threshold.
+	var extra int64
+	usage, err := srcPool.GetInstanceUsage(src)
+	if err == nil {
+		extra = usage.Used
+	}
+
+	err = b.checkFillThreshold(extra)
+	if err != nil {
+		return err
+	}
+
 	srcPoolBackend, ok := srcPool.(*lxdBackend)
 	if !ok {
 		return fmt.Errorf("Source pool is not a lxdBackend")
@@ -1945,6 +1986,109 @@ func (b *lxdBackend) CreateInstanceFromImage(inst instance.Instance, fingerprint
 	return nil
 }
 
+// ReplaceInstanceFromImage replaces the content of an existing instance volume with the content of the image.
+// Unlike deleting the volume and creating it again from the image, this keeps the snapshots of the volume.
+func (b *lxdBackend) ReplaceInstanceFromImage(inst instance.Instance, fingerprint string, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "fingerprint": fingerprint})
+	l.Debug("ReplaceInstanceFromImage started")
+	defer l.Debug("ReplaceInstanceFromImage finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return err
+	}
+
+	contentType := InstanceContentType(inst)
+
+	dbVol, err := VolumeDBGet(b, inst.Project().Name, inst.Name(), volType)
+	if err != nil {
+		return err
+	}
+
+	volStorageName := project.Instance(inst.Project().Name, inst.Name())
+	vol := b.GetVolume(volType, contentType, volStorageName, dbVol.Config)
+
+	err = b.applyInstanceRootDiskOverrides(inst, &vol)
+	if err != nil {
+		return err
+	}
+
+	err = b.driver.MountVolume(vol, op)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _, _ = b.driver.UnmountVolume(vol, false, op) }()
+
+	var rootBlockPath string
+	if vol.IsVMBlock() {
+		rootBlockPath, err = b.driver.GetVolumeDiskPath(vol)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remove the current content of the volume. The root disk of virtual machines is overwritten by the unpack.
+	entries, err := os.ReadDir(vol.MountPath())
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entryPath := filepath.Join(vol.MountPath(), entry.Name())
+		if entryPath == rootBlockPath {
+			continue
+		}
+
+		err = os.RemoveAll(entryPath)
+		if err != nil {
+			return fmt.Errorf("Failed removing %q: %w", entryPath, err)
+		}
+	}
+
+	_, err = b.imageFiller(fingerprint, op)(vol, rootBlockPath, false)
+	if err != nil {
+		return err
+	}
+
+	// Unpacking into a file based root disk sizes it to the image, so grow it back to the volume size.
+	if rootBlockPath != "" && !shared.IsBlockdevPath(rootBlockPath) {
+		volSize := vol.ConfigSize()
+		if volSize == "" {
+			volSize = drivers.DefaultBlockSize
+		}
+
+		volSizeBytes, err := units.ParseByteSizeString(volSize)
+		if err != nil {
+			return err
+		}
+
+		diskSizeBytes, err := drivers.BlockDiskSizeBytes(rootBlockPath)
+		if err != nil {
+			return err
+		}
+
+		if diskSizeBytes < volSizeBytes {
+			err = b.driver.SetVolumeQuota(vol, volSize, false, op)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = inst.DeferTemplateApply(instance.TemplateTriggerCreate)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // CreateInstanceFromMigration receives an instance being migrated.
 // The args.Name and args.Config fields are ignored and, instance properties are used instead.
 func (b *lxdBackend) CreateInstanceFromMigration(inst instance.Instance, conn io.ReadWriteCloser, args migration.VolumeTargetArgs, op *operations.Operation) error {
@@ -2756,6 +2900,12 @@ func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancew
 		return err
 	}
 
+	// Check the pool isn't filled beyond its threshold.
+	err = b.checkFillThreshold(0)
+	if err != nil {
+		return err
+	}
+
 	contentType := InstanceContentType(inst)
 
 	// Load storage volume from database.
@@ -2870,6 +3020,56 @@ func (b *lxdBackend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, err
 	return &val, nil
 }
 
+// GetInstanceSnapshotUsage returns the disk space only used by the instance snapshot's root volume and the disk
+// space it shares with the instance or with other snapshots.
+func (b *lxdBackend) GetInstanceSnapshotUsage(inst instance.Instance) (*api.StorageVolumeSnapshotUsage, error) {
+	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+	l.Debug("GetInstanceSnapshotUsage started")
+	defer l.Debug("GetInstanceSnapshotUsage finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	if !inst.IsSnapshot() {
+		return nil, fmt.Errorf("Instance must be a snapshot")
+	}
+
+	volType, err := InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := InstanceContentType(inst)
+
+	// Load storage volume from database.
+	dbVol, err := VolumeDBGet(b, inst.Project().Name, inst.Name(), volType)
+	if err != nil {
+		return nil, err
+	}
+
+	volStorageName := project.Instance(inst.Project().Name, inst.Name())
+	vol := b.GetVolume(volType, contentType, volStorageName, dbVol.Config)
+
+	return b.getSnapshotUsage(vol)
+}
+
+// getSnapshotUsage returns the disk space usage of the given snapshot volume.
+func (b *lxdBackend) getSnapshotUsage(snapVol drivers.Volume) (*api.StorageVolumeSnapshotUsage, error) {
+	unique, referenced, err := b.driver.GetVolumeSnapshotUsage(snapVol)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &api.StorageVolumeSnapshotUsage{Unique: unique}
+	if referenced > unique {
+		usage.Shared = referenced - unique
+	}
+
+	return usage, nil
+}
+
 // SetInstanceQuota sets the quota on the instance's root volume.
 // Returns ErrInUse if the instance is running and the storage driver doesn't support online resizing.
 func (b *lxdBackend) SetInstanceQuota(inst instance.Instance, size string, vmStateSize string, op *operations.Operation) error {
@@ -3095,6 +3295,12 @@ func (b *lxdBackend) CreateInstanceSnapshot(inst instance.Instance, src instance
 		return err
 	}
 
+	// Check the pool isn't filled beyond its threshold.
+	err = b.checkFillThreshold(0)
+	if err != nil {
+		return err
+	}
+
 	contentType := InstanceContentType(inst)
 
 	// Load storage volume from database.
@@ -3986,7 +4192,7 @@ func (b *lxdBackend) CreateBucket(projectName string, bucket api.StorageBucketsP
 		}
 
 		if bucketExists {
-			return api.StatusErrorf(http.StatusConflict, "A bucket for that name already exists")
+			return api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "A bucket for that name already exists")
 		}
 
 		// Create new bucket.
@@ -3996,6 +4202,14 @@ func (b *lxdBackend) CreateBucket(projectName string, bucket api.StorageBucketsP
 		}
 
 		revert.Add(func() { _ = s3Client.RemoveBucket(ctx, bucket.Name) })
+
+		// Apply the lifecycle rules of the bucket.
+		if bucket.Config[s3.LifecycleExpirationDays] != "" || bucket.Config[s3.LifecycleAbortIncompleteUploadsDays] != "" {
+			err = s3.SetBucketLifecycle(ctx, s3Client, bucket.Name, bucket.Config)
+			if err != nil {
+				return err
+			}
+		}
 	} else {
 		// Handle per-driver implementation for remote storage drivers.
 		err = b.driver.CreateBucket(bucketVol, op)
@@ -4092,6 +4306,35 @@ func (b *lxdBackend) UpdateBucket(projectName string, bucketName string, bucket
 			if err != nil {
 				return err
 			}
+
+			// Apply the new lifecycle rules of the bucket, which requires MinIO to be running.
+			lifecycleChanged := false
+			for key := range changedConfig {
+				if s3.IsLifecycleKey(key) {
+					lifecycleChanged = true
+					break
+				}
+			}
+
+			if lifecycleChanged {
+				minioProc, err := b.ActivateBucket(projectName, bucketName, op)
+				if err != nil {
+					return err
+				}
+
+				s3Client, err := minioProc.S3Client()
+				if err != nil {
+					return err
+				}
+
+				ctx, ctxCancel := context.WithTimeout(context.TODO(), time.Duration(time.Second*30))
+				defer ctxCancel()
+
+				err = s3.SetBucketLifecycle(ctx, s3Client, curBucket.Name, bucket.Config)
+				if err != nil {
+					return err
+				}
+			}
 		} else {
 			// Handle per-driver implementation for remote storage drivers.
 			err = b.driver.UpdateBucket(curBucketVol, changedConfig)
@@ -4821,6 +5064,18 @@ func (b *lxdBackend) CreateCustomVolumeFromCopy(projectName string, srcProjectNa
 		}
 	}
 
+	// Check the copy won't fill the pool beyond its threshold.
+	var extra int64
+	usage, err := srcPool.GetCustomVolumeUsage(srcProjectName, srcVolName)
+	if err == nil {
+		extra = usage.Used
+	}
+
+	err = b.checkFillThreshold(extra)
+	if err != nil {
+		return err
+	}
+
 	// Check source volume exists and is custom type, and get its config including all of the snapshots.
 	srcConfig, err := srcPool.GenerateCustomVolumeBackupConfig(srcProjectName, srcVolName, true, op)
 	if err != nil {
@@ -5815,6 +6070,30 @@ func (b *lxdBackend) GetCustomVolumeUsage(projectName, volName string) (*VolumeU
 	return &val, nil
 }
 
+// GetCustomVolumeSnapshotUsage returns the disk space only used by the custom volume snapshot and the disk space
+// it shares with the volume or with other snapshots.
+func (b *lxdBackend) GetCustomVolumeSnapshotUsage(projectName string, volName string) (*api.StorageVolumeSnapshotUsage, error) {
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	if !shared.IsSnapshot(volName) {
+		return nil, fmt.Errorf("Volume must be a snapshot")
+	}
+
+	volume, err := VolumeDBGet(b, projectName, volName, drivers.VolumeTypeCustom)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the volume name on storage.
+	volStorageName := project.StorageVolume(projectName, volName)
+	vol := b.GetVolume(drivers.VolumeTypeCustom, drivers.ContentType(volume.ContentType), volStorageName, volume.Config)
+
+	return b.getSnapshotUsage(vol)
+}
+
 // MountCustomVolume mounts a custom volume.
 func (b *lxdBackend) MountCustomVolume(projectName, volName string, op *operations.Operation) (*MountInfo, error) {
 	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName})
@@ -5970,7 +6249,13 @@ func (b *lxdBackend) CreateCustomVolumeSnapshot(projectName, volName string, new
 	if err != nil && !response.IsNotFoundError(err) {
 		return err
 	} else if volume != nil {
-		return api.StatusErrorf(http.StatusConflict, "Snapshot by that name already exists")
+		return api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "Snapshot by that name already exists")
+	}
+
+	// Check the pool isn't filled beyond its threshold.
+	err = b.checkFillThreshold(0)
+	if err != nil {
+		return err
 	}
 
 	// Load parent volume information and check it exists.
@@ -7057,6 +7342,12 @@ func (b *lxdBackend) BackupCustomVolume(projectName string, volName string, tarW
 		return err
 	}
 
+	// Check the pool isn't filled beyond its threshold.
+	err = b.checkFillThreshold(0)
+	if err != nil {
+		return err
+	}
+
 	// Get the volume name on storage.
 	volStorageName := project.StorageVolume(projectName, volume.Name)
 
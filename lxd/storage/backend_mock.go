@@ -124,6 +124,10 @@ func (b *mockBackend) CreateInstanceFromImage(inst instance.Instance, fingerprin
 	return nil
 }
 
+func (b *mockBackend) ReplaceInstanceFromImage(inst instance.Instance, fingerprint string, op *operations.Operation) error {
+	return nil
+}
+
 func (b *mockBackend) CreateInstanceFromMigration(inst instance.Instance, conn io.ReadWriteCloser, args migration.VolumeTargetArgs, op *operations.Operation) error {
 	return nil
 }
@@ -189,6 +193,10 @@ func (b *mockBackend) GetInstanceUsage(inst instance.Instance) (*VolumeUsage, er
 	return nil, nil
 }
 
+func (b *mockBackend) GetInstanceSnapshotUsage(inst instance.Instance) (*api.StorageVolumeSnapshotUsage, error) {
+	return nil, nil
+}
+
 func (b *mockBackend) SetInstanceQuota(inst instance.Instance, size string, vmStateSize string, op *operations.Operation) error {
 	return nil
 }
@@ -313,6 +321,10 @@ func (b *mockBackend) GetCustomVolumeUsage(projectName string, volName string) (
 	return nil, nil
 }
 
+func (b *mockBackend) GetCustomVolumeSnapshotUsage(projectName string, volName string) (*api.StorageVolumeSnapshotUsage, error) {
+	return nil, nil
+}
+
 func (b *mockBackend) MountCustomVolume(projectName string, volName string, op *operations.Operation) (*MountInfo, error) {
 	return nil, nil
 }
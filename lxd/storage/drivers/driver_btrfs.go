@@ -95,6 +95,9 @@ func (d *btrfs) Info() Info {
 		Version:                      btrfsVersion,
 		DefaultVMBlockFilesystemSize: deviceConfig.DefaultVMBlockFilesystemSize,
 		OptimizedImages:              true,
+		OptimizedCopy:                true,
+		LiveResize:                   true,
+		RestoreAnySnapshot:           true,
 		OptimizedBackups:             true,
 		OptimizedBackupHeader:        true,
 		PreservesInodes:              !d.state.OS.RunningInUserNS,
@@ -518,7 +521,7 @@ func (d *btrfs) MigrationTypes(contentType ContentType, refresh bool, copySnapsh
 	if shared.IsFalse(d.Config()["rsync.compression"]) {
 		rsyncFeatures = []string{"xattrs", "delete", "bidirectional"}
 	} else {
-		rsyncFeatures = []string{"xattrs", "delete", "compress", "bidirectional"}
+		rsyncFeatures = append([]string{"xattrs", "delete", "bidirectional"}, rsyncCompressionFeatures()...)
 	}
 
 	// Only offer rsync if running in an unprivileged container.
@@ -334,15 +334,23 @@ func (d *btrfs) deleteSubvolume(rootPath string, recursion bool) error {
 }
 
 func (d *btrfs) getQGroup(path string) (string, int64, error) {
+	qgroup, _, exclusive, err := d.getQGroupUsage(path)
+	return qgroup, exclusive, err
+}
+
+// getQGroupUsage returns the identifier of the qgroup of the subvolume at the given path, along with the disk space
+// it references and the disk space only it references.
+func (d *btrfs) getQGroupUsage(path string) (string, int64, int64, error) {
 	// Try to get the qgroup details.
 	output, err := shared.RunCommand("btrfs", "qgroup", "show", "-e", "-f", "--raw", path)
 	if err != nil {
-		return "", -1, errBtrfsNoQuota
+		return "", -1, -1, errBtrfsNoQuota
 	}
 
 	// Parse to extract the qgroup identifier.
 	var qgroup string
-	usage := int64(-1)
+	referenced := int64(-1)
+	exclusive := int64(-1)
 	for _, line := range strings.Split(output, "\n") {
 		// Use case-insensitive field title match because BTRFS tooling changed casing between versions.
 		if line == "" || strings.HasPrefix(strings.ToLower(line), "qgroupid") || strings.HasPrefix(line, "-") {
@@ -357,19 +365,24 @@ func (d *btrfs) getQGroup(path string) (string, int64, error) {
 		}
 
 		qgroup = fields[0]
-		val, err := strconv.ParseInt(fields[2], 10, 64)
+		val, err := strconv.ParseInt(fields[1], 10, 64)
+		if err == nil {
+			referenced = val
+		}
+
+		val, err = strconv.ParseInt(fields[2], 10, 64)
 		if err == nil {
-			usage = val
+			exclusive = val
 		}
 
 		break
 	}
 
 	if qgroup == "" {
-		return "", -1, errBtrfsNoQGroup
+		return "", -1, -1, errBtrfsNoQGroup
 	}
 
-	return qgroup, usage, nil
+	return qgroup, referenced, exclusive, nil
 }
 
 func (d *btrfs) sendSubvolume(path string, parent string, conn io.ReadWriteCloser, tracker *ioprogress.ProgressTracker) error {
@@ -900,6 +900,25 @@ func (d *btrfs) GetVolumeUsage(vol Volume) (int64, error) {
 	return usage, nil
 }
 
+// GetVolumeSnapshotUsage returns the disk space only used by the snapshot and the disk space it references.
+func (d *btrfs) GetVolumeSnapshotUsage(snapVol Volume) (int64, int64, error) {
+	if !snapVol.IsSnapshot() {
+		return -1, -1, ErrNotSupported
+	}
+
+	// Attempt to get the qgroup information.
+	_, referenced, exclusive, err := d.getQGroupUsage(snapVol.MountPath())
+	if err != nil {
+		if err == errBtrfsNoQuota {
+			return -1, -1, ErrNotSupported
+		}
+
+		return -1, -1, err
+	}
+
+	return exclusive, referenced, nil
+}
+
 // SetVolumeQuota applies a size limit on volume.
 // Does nothing if supplied with an empty/zero size for block volumes, and for filesystem volumes removes quota.
 func (d *btrfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error {
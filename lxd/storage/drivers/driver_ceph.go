@@ -83,6 +83,9 @@ func (d *ceph) Info() Info {
 		Version:                      cephVersion,
 		DefaultVMBlockFilesystemSize: deviceConfig.DefaultVMBlockFilesystemSize,
 		OptimizedImages:              true,
+		OptimizedCopy:                true,
+		LiveResize:                   true,
+		RestoreAnySnapshot:           true,
 		PreservesInodes:              false,
 		Remote:                       d.isRemote(),
 		VolumeTypes:                  []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
@@ -450,7 +453,7 @@ func (d *ceph) MigrationTypes(contentType ContentType, refresh bool, copySnapsho
 	if shared.IsFalse(d.Config()["rsync.compression"]) {
 		rsyncFeatures = []string{"xattrs", "delete", "bidirectional"}
 	} else {
-		rsyncFeatures = []string{"xattrs", "delete", "compress", "bidirectional"}
+		rsyncFeatures = append([]string{"xattrs", "delete", "bidirectional"}, rsyncCompressionFeatures()...)
 	}
 
 	if refresh {
@@ -81,6 +81,9 @@ func (d *cephfs) Info() Info {
 		Version:                      cephfsVersion,
 		DefaultVMBlockFilesystemSize: deviceConfig.DefaultVMBlockFilesystemSize,
 		OptimizedImages:              false,
+		OptimizedCopy:                true,
+		LiveResize:                   true,
+		RestoreAnySnapshot:           true,
 		PreservesInodes:              false,
 		Remote:                       d.isRemote(),
 		VolumeTypes:                  []VolumeType{VolumeTypeCustom},
@@ -495,7 +498,7 @@ func (d *cephfs) MigrationTypes(contentType ContentType, refresh bool, copySnaps
 	if shared.IsFalse(d.Config()["rsync.compression"]) {
 		rsyncFeatures = []string{"delete", "bidirectional"}
 	} else {
-		rsyncFeatures = []string{"delete", "compress", "bidirectional"}
+		rsyncFeatures = append([]string{"delete", "bidirectional"}, rsyncCompressionFeatures()...)
 	}
 
 	if contentType != ContentTypeFS {
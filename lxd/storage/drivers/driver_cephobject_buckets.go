@@ -16,6 +16,7 @@ import (
 
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/storage/s3"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/revert"
@@ -111,7 +112,7 @@ func (d *cephobject) CreateBucket(bucket Volume, op *operations.Operation) error
 	}
 
 	if bucketExists {
-		return api.StatusErrorf(http.StatusConflict, "A bucket for that name already exists")
+		return api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "A bucket for that name already exists")
 	}
 
 	// Create new bucket.
@@ -144,6 +145,14 @@ func (d *cephobject) CreateBucket(bucket Volume, op *operations.Operation) error
 		}
 	}
 
+	// Apply the lifecycle rules if specified.
+	if bucket.config[s3.LifecycleExpirationDays] != "" || bucket.config[s3.LifecycleAbortIncompleteUploadsDays] != "" {
+		err = d.setBucketLifecycle(bucket, bucket.config)
+		if err != nil {
+			return err
+		}
+	}
+
 	revert.Success()
 	return nil
 }
@@ -166,6 +175,28 @@ func (d *cephobject) setBucketQuota(bucket Volume, quotaSize string) error {
 	return nil
 }
 
+// setBucketLifecycle applies the lifecycle rules of the given bucket config to the bucket. As the bucket is linked
+// to its bucket user, the credentials of that user are used.
+func (d *cephobject) setBucketLifecycle(bucket Volume, config map[string]string) error {
+	_, bucketName := project.StorageVolumeParts(bucket.name)
+	storageBucketName := d.radosgwBucketName(bucketName)
+
+	ctx, ctxCancel := context.WithTimeout(context.TODO(), time.Duration(time.Second*30))
+	defer ctxCancel()
+
+	bucketUserInfo, _, err := d.radosgwadminGetUser(ctx, storageBucketName)
+	if err != nil {
+		return fmt.Errorf("Failed getting bucket user: %w", err)
+	}
+
+	minioClient, err := d.s3Client(*bucketUserInfo)
+	if err != nil {
+		return err
+	}
+
+	return s3.SetBucketLifecycle(ctx, minioClient, storageBucketName, config)
+}
+
 // DeleteBucket deletes an existing bucket.
 func (d *cephobject) DeleteBucket(bucket Volume, op *operations.Operation) error {
 	_, bucketName := project.StorageVolumeParts(bucket.name)
@@ -194,6 +225,28 @@ func (d *cephobject) UpdateBucket(bucket Volume, changedConfig map[string]string
 		}
 	}
 
+	// Apply the lifecycle rules of the new config if any of them changed.
+	newConfig := make(map[string]string, len(bucket.config))
+	for key, value := range bucket.config {
+		newConfig[key] = value
+	}
+
+	lifecycleChanged := false
+	for key, value := range changedConfig {
+		if s3.IsLifecycleKey(key) {
+			lifecycleChanged = true
+		}
+
+		newConfig[key] = value
+	}
+
+	if lifecycleChanged {
+		err := d.setBucketLifecycle(bucket, newConfig)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -226,7 +279,7 @@ func (d *cephobject) CreateBucketKey(bucket Volume, keyName string, creds S3Cred
 
 	_, subUserExists := bucketSubUsers[keyName]
 	if subUserExists {
-		return nil, api.StatusErrorf(http.StatusConflict, "A bucket key for that name already exists")
+		return nil, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "A bucket key for that name already exists")
 	}
 
 	// Create a sub user for the key on the bucket user.
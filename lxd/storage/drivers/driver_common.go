@@ -204,7 +204,7 @@ func (d *common) MigrationTypes(contentType ContentType, refresh bool, copySnaps
 	if shared.IsFalse(d.Config()["rsync.compression"]) {
 		rsyncFeatures = []string{"xattrs", "delete", "bidirectional"}
 	} else {
-		rsyncFeatures = []string{"xattrs", "delete", "compress", "bidirectional"}
+		rsyncFeatures = append([]string{"xattrs", "delete", "bidirectional"}, rsyncCompressionFeatures()...)
 	}
 
 	if IsContentBlock(contentType) {
@@ -363,6 +363,11 @@ func (d *common) GetVolumeUsage(vol Volume) (int64, error) {
 	return -1, ErrNotSupported
 }
 
+// GetVolumeSnapshotUsage returns the disk space only used by a snapshot and the disk space it references.
+func (d *common) GetVolumeSnapshotUsage(snapVol Volume) (int64, int64, error) {
+	return -1, -1, ErrNotSupported
+}
+
 // SetVolumeQuota applies a size limit on volume.
 func (d *common) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error {
 	return ErrNotSupported
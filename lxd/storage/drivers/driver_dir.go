@@ -13,6 +13,7 @@ import (
 	"github.com/canonical/lxd/lxd/storage/filesystem"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
 )
 
 type dir struct {
@@ -39,16 +40,20 @@ func (d *dir) Info() Info {
 		Name:                         "dir",
 		Version:                      "1",
 		DefaultVMBlockFilesystemSize: deviceConfig.DefaultVMBlockFilesystemSize,
-		OptimizedImages:              false,
+		OptimizedImages:              d.optimizedImages(),
+		OptimizedCopy:                false,
+		LiveResize:                   true,
+		RestoreAnySnapshot:           true,
 		PreservesInodes:              false,
 		Remote:                       d.isRemote(),
 		VolumeTypes:                  []VolumeType{VolumeTypeBucket, VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
 		BlockBacking:                 false,
 		RunningCopyFreeze:            true,
-		DirectIO:                     true,
+		DirectIO:                     !d.tmpfs(),
 		IOUring:                      true,
 		MountedRoot:                  true,
 		Buckets:                      true,
+		Volatile:                     d.tmpfs(),
 	}
 }
 
@@ -72,6 +77,11 @@ func (d *dir) Create() error {
 
 	sourcePath := shared.HostPath(d.config["source"])
 
+	// The content of tmpfs pools lives in memory so there is no source directory to use.
+	if d.tmpfs() && sourcePath != GetPoolMountPath(d.name) {
+		return fmt.Errorf("Source cannot be set when dir.tmpfs is enabled")
+	}
+
 	if !shared.PathExists(sourcePath) {
 		return fmt.Errorf("Source path %q doesn't exist", sourcePath)
 	}
@@ -129,11 +139,65 @@ func (d *dir) Delete(op *operations.Operation) error {
 
 // Validate checks that all provide keys are supported and that no conflicting or missing configuration is present.
 func (d *dir) Validate(config map[string]string) error {
-	return d.validatePool(config, nil, nil)
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=storage-dir; group=pool-conf; key=dir.optimized_images)
+		// When enabled, LXD unpacks each image once into an image volume on the pool and creates instances by copying that volume.
+		// Identical files of different images are stored only once.
+		// See {ref}`storage-dir-optimized-images` for more information.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to store images as optimized image volumes
+		"dir.optimized_images": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=storage-dir; group=pool-conf; key=dir.tmpfs)
+		// When enabled, LXD mounts a `tmpfs` file system for the storage pool and keeps all its volumes in memory.
+		// The content of the pool is lost when the pool is unmounted or the host restarts, so only ephemeral instances can use it.
+		// See {ref}`storage-dir-tmpfs` for more information.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to keep the storage pool in memory
+		"dir.tmpfs": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=storage-dir; group=pool-conf; key=size)
+		// Specify the maximum amount of memory that the storage pool can use, in bytes ({ref}`suffixes <instances-limit-units>` are supported).
+		// You can change the size while the pool is in use.
+		// This option is used only when {config:option}`storage-dir-pool-conf:dir.tmpfs` is enabled.
+		// ---
+		//  type: string
+		//  defaultdesc: 50% of the host memory
+		//  shortdesc: Size of the storage pool (for `tmpfs` pools)
+		"size": validate.Optional(validate.IsSize),
+	}
+
+	return d.validatePool(config, rules, nil)
 }
 
 // Update applies any driver changes required from a configuration change.
 func (d *dir) Update(changedConfig map[string]string) error {
+	_, changed := changedConfig["dir.optimized_images"]
+	if changed {
+		return fmt.Errorf("dir.optimized_images cannot be changed")
+	}
+
+	_, changed = changedConfig["dir.tmpfs"]
+	if changed {
+		return fmt.Errorf("dir.tmpfs cannot be changed")
+	}
+
+	size, changed := changedConfig["size"]
+	if changed && d.tmpfs() {
+		options, err := d.tmpfsOptions(size)
+		if err != nil {
+			return err
+		}
+
+		// Resize the tmpfs in place.
+		err = TryMount("tmpfs", GetPoolMountPath(d.name), "tmpfs", unix.MS_REMOUNT, options)
+		if err != nil {
+			return fmt.Errorf("Failed resizing the storage pool: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -142,6 +206,25 @@ func (d *dir) Mount() (bool, error) {
 	path := GetPoolMountPath(d.name)
 	sourcePath := shared.HostPath(d.config["source"])
 
+	if d.tmpfs() {
+		// Check if already mounted.
+		if filesystem.IsMountPoint(path) {
+			return false, nil
+		}
+
+		options, err := d.tmpfsOptions(d.config["size"])
+		if err != nil {
+			return false, err
+		}
+
+		err = TryMount("tmpfs", path, "tmpfs", 0, options)
+		if err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
 	// Check if we're dealing with an external mount.
 	if sourcePath == path {
 		return false, nil
@@ -166,7 +249,7 @@ func (d *dir) Unmount() (bool, error) {
 	path := GetPoolMountPath(d.name)
 
 	// Check if we're dealing with an external mount.
-	if d.config["source"] == path {
+	if d.config["source"] == path && !d.tmpfs() {
 		return false, nil
 	}
 
@@ -1,9 +1,19 @@
 package drivers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
 
 	"github.com/canonical/lxd/lxd/storage/quota"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/units"
@@ -135,3 +145,239 @@ func (d *dir) setQuota(path string, volID int64, sizeBytes int64) error {
 	// Set the project quota size.
 	return quota.SetProjectQuota(path, projectID, sizeBytes)
 }
+
+// optimizedImages returns whether the pool stores images as optimized image volumes.
+func (d *dir) optimizedImages() bool {
+	return shared.IsTrue(d.config["dir.optimized_images"])
+}
+
+// tmpfs returns whether the pool keeps its content in memory on a tmpfs.
+func (d *dir) tmpfs() bool {
+	return shared.IsTrue(d.config["dir.tmpfs"])
+}
+
+// tmpfsOptions returns the mount options for the pool's tmpfs with the given size limit.
+// When no size is given the tmpfs can use up to half of the host memory.
+func (d *dir) tmpfsOptions(size string) (string, error) {
+	if size == "" {
+		return "mode=0711,size=50%", nil
+	}
+
+	sizeBytes, err := units.ParseByteSizeString(size)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("mode=0711,size=%d", sizeBytes), nil
+}
+
+// usesImageObjects returns whether the volume is an optimized image volume whose files are deduplicated into
+// the pool's image object store. Such volumes don't use project quotas as their inodes are shared with other
+// image volumes.
+func (d *dir) usesImageObjects(vol Volume) bool {
+	return d.optimizedImages() && vol.volType == VolumeTypeImage && vol.contentType == ContentTypeFS
+}
+
+// imageObjectsPath returns the path of the pool's image object store.
+func (d *dir) imageObjectsPath() string {
+	return filepath.Join(GetPoolMountPath(d.name), ".image-objects")
+}
+
+// imageObjectKey returns the content address of a regular file. It covers the file's content as well as the
+// metadata that is shared between all hardlinks of an inode.
+func imageObjectKey(path string, st *unix.Stat_t) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+	_, err = fmt.Fprintf(hash, "%o:%d:%d:%d.%d\n", st.Mode, st.Uid, st.Gid, st.Mtim.Sec, st.Mtim.Nsec)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// deduplicateImage replaces the regular files of an unpacked image with hardlinks into the pool's image object
+// store so that identical files of different images are only stored once.
+// Files that are already hardlinked within the image or that carry extended attributes are left untouched.
+func (d *dir) deduplicateImage(path string) error {
+	objectsPath := d.imageObjectsPath()
+	usedKeys := map[string]bool{}
+	dirTimes := map[string]*unix.Stat_t{}
+
+	err := filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		var st unix.Stat_t
+		err = unix.Lstat(filePath, &st)
+		if err != nil {
+			return err
+		}
+
+		if st.Nlink > 1 {
+			return nil
+		}
+
+		size, err := unix.Llistxattr(filePath, nil)
+		if err == nil && size > 0 {
+			return nil
+		}
+
+		key, err := imageObjectKey(filePath, &st)
+		if err != nil {
+			return err
+		}
+
+		// Each object is only used once per image so that any inode shared within the image is a hardlink
+		// that was part of the image itself.
+		if usedKeys[key] {
+			return nil
+		}
+
+		usedKeys[key] = true
+
+		objectPath := filepath.Join(objectsPath, key[:2], key)
+		if !shared.PathExists(objectPath) {
+			err = os.MkdirAll(filepath.Dir(objectPath), 0700)
+			if err != nil {
+				return err
+			}
+
+			err = os.Link(filePath, objectPath)
+			if err == nil || !os.IsExist(err) {
+				return err
+			}
+		}
+
+		// Record the directory timestamps before replacing the file so they can be restored afterwards.
+		parentPath := filepath.Dir(filePath)
+		if dirTimes[parentPath] == nil {
+			var dirSt unix.Stat_t
+			err = unix.Lstat(parentPath, &dirSt)
+			if err != nil {
+				return err
+			}
+
+			dirTimes[parentPath] = &dirSt
+		}
+
+		// Replace the file atomically with a hardlink to the existing object.
+		tmpPath := filePath + ".lxd-dedup"
+		err = os.Link(objectPath, tmpPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// The object has been pruned in the meantime, keep the file as is.
+				return nil
+			}
+
+			return err
+		}
+
+		err = os.Rename(tmpPath, filePath)
+		if err != nil {
+			_ = os.Remove(tmpPath)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed deduplicating image files in %q: %w", path, err)
+	}
+
+	for dirPath, st := range dirTimes {
+		err = os.Chtimes(dirPath, time.Unix(st.Atim.Unix()), time.Unix(st.Mtim.Unix()))
+		if err != nil {
+			return fmt.Errorf("Failed restoring timestamps of %q: %w", dirPath, err)
+		}
+	}
+
+	return nil
+}
+
+// pruneImageObjects removes the objects from the pool's image object store that aren't used by any image anymore.
+func (d *dir) pruneImageObjects() error {
+	objectsPath := d.imageObjectsPath()
+	if !shared.PathExists(objectsPath) {
+		return nil
+	}
+
+	return filepath.WalkDir(objectsPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		var st unix.Stat_t
+		err = unix.Lstat(filePath, &st)
+		if err != nil {
+			return err
+		}
+
+		if st.Nlink > 1 {
+			return nil
+		}
+
+		err = os.Remove(filePath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// imageUsage returns the disk space used by the files of an image volume once copied into a new volume.
+func imageUsage(path string) (int64, error) {
+	var usage int64
+	seenInodes := map[uint64]bool{}
+
+	err := filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		var st unix.Stat_t
+		err = unix.Lstat(filePath, &st)
+		if err != nil {
+			return err
+		}
+
+		// Hardlinks within the image are preserved by the copy, only count them once.
+		if entry.Type().IsRegular() && st.Nlink > 1 {
+			if seenInodes[st.Ino] {
+				return nil
+			}
+
+			seenInodes[st.Ino] = true
+		}
+
+		usage += st.Blocks * 512
+
+		return nil
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return usage, nil
+}
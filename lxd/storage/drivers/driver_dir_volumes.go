@@ -49,7 +49,7 @@ func (d *dir) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 		if err != nil {
 			return err
 		}
-	} else if vol.volType != VolumeTypeBucket {
+	} else if vol.volType != VolumeTypeBucket && !d.usesImageObjects(vol) {
 		// Filesystem quotas only used with non-block volume types.
 		revertFunc, err := d.setupInitialQuota(vol)
 		if err != nil {
@@ -67,6 +67,14 @@ func (d *dir) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Oper
 		return err
 	}
 
+	// Store the unpacked image files in the pool's image object store.
+	if d.usesImageObjects(vol) && filler != nil && filler.Fill != nil {
+		err = d.deduplicateImage(volPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	// If we are creating a block volume, resize it to the requested size or the default.
 	// For block volumes, we expect the filler function to have converted the qcow2 image to raw into the rootBlockPath.
 	// For ISOs the content will just be copied.
@@ -138,6 +146,11 @@ func (d *dir) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, srcD
 
 // CreateVolumeFromCopy provides same-pool volume copying functionality.
 func (d *dir) CreateVolumeFromCopy(vol VolumeCopy, srcVol VolumeCopy, allowInconsistent bool, op *operations.Operation) error {
+	// Instances created from an optimized image volume are copied using reflinks where supported.
+	if d.usesImageObjects(srcVol.Volume) && !srcVol.IsSnapshot() && vol.contentType == ContentTypeFS {
+		return d.createVolumeFromImage(vol.Volume, srcVol.Volume, op)
+	}
+
 	var srcSnapshots []string
 
 	if len(vol.Snapshots) > 0 && !srcVol.IsSnapshot() {
@@ -158,6 +171,56 @@ func (d *dir) CreateVolumeFromCopy(vol VolumeCopy, srcVol VolumeCopy, allowIncon
 	return err
 }
 
+// createVolumeFromImage creates a volume from an optimized image volume.
+// The new volume's quota is set up before copying so that a volume that is too small for the image fails early.
+func (d *dir) createVolumeFromImage(vol Volume, srcVol Volume, op *operations.Operation) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	err := d.CreateVolume(vol, nil, op)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { _ = d.DeleteVolume(vol, op) })
+
+	volPath := vol.MountPath()
+
+	err = srcVol.MountTask(func(srcMountPath string, op *operations.Operation) error {
+		sizeBytes, err := units.ParseByteSizeString(vol.ConfigSize())
+		if err != nil {
+			return err
+		}
+
+		ok, _ := quota.Supported(volPath)
+		if ok && sizeBytes > 0 {
+			usage, err := imageUsage(srcMountPath)
+			if err != nil {
+				return err
+			}
+
+			if usage > sizeBytes {
+				return fmt.Errorf("Volume size %s is smaller than the %s used by the image", units.GetByteSizeStringIEC(sizeBytes, 2), units.GetByteSizeStringIEC(usage, 2))
+			}
+		}
+
+		d.logger.Debug("Copying image volume", logger.Ctx{"sourcePath": srcMountPath, "targetPath": volPath})
+
+		_, err = shared.RunCommandContext(d.state.ShutdownCtx, "cp", "-a", "--reflink=auto", srcMountPath+"/.", volPath)
+		if err != nil {
+			return fmt.Errorf("Failed copying image volume: %w", err)
+		}
+
+		return nil
+	}, op)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
 // CreateVolumeFromMigration creates a volume being sent via a migration.
 func (d *dir) CreateVolumeFromMigration(vol VolumeCopy, conn io.ReadWriteCloser, volTargetArgs migration.VolumeTargetArgs, preFiller *VolumeFiller, op *operations.Operation) error {
 	_, err := genericVFSCreateVolumeFromMigration(d, d.setupInitialQuota, vol, conn, volTargetArgs, preFiller, op)
@@ -190,7 +253,7 @@ func (d *dir) DeleteVolume(vol Volume, op *operations.Operation) error {
 	}
 
 	// Get the volume ID for the volume, which is used to remove project quota.
-	if vol.Type() != VolumeTypeBucket {
+	if vol.Type() != VolumeTypeBucket && !d.usesImageObjects(vol) {
 		volID, err := d.getVolID(vol.volType, vol.name)
 		if err != nil {
 			return err
@@ -216,6 +279,14 @@ func (d *dir) DeleteVolume(vol Volume, op *operations.Operation) error {
 		return err
 	}
 
+	// Remove the image objects that were only used by this image.
+	if d.usesImageObjects(vol) {
+		err = d.pruneImageObjects()
+		if err != nil {
+			return fmt.Errorf("Failed pruning image objects: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -336,7 +407,7 @@ func (d *dir) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op
 		}
 
 		return nil
-	} else if vol.Type() != VolumeTypeBucket {
+	} else if vol.Type() != VolumeTypeBucket && !d.usesImageObjects(vol) {
 		// For non-VM block volumes, set filesystem quota.
 		volID, err := d.getVolID(vol.volType, vol.name)
 		if err != nil {
@@ -476,16 +547,24 @@ func (d *dir) CreateVolumeSnapshot(snapVol Volume, op *operations.Operation) err
 			return err
 		}
 
-		d.Logger().Debug("Copying block volume", logger.Ctx{"srcDevPath": srcDevPath, "targetPath": targetDevPath})
-
-		err = ensureSparseFile(targetDevPath, 0)
+		// Use a reflink where supported, so that the block volume doesn't need to be copied in full.
+		cloned, err := cloneFile(srcDevPath, targetDevPath)
 		if err != nil {
 			return err
 		}
 
-		err = copyDevice(srcDevPath, targetDevPath)
-		if err != nil {
-			return err
+		if !cloned {
+			d.Logger().Debug("Copying block volume", logger.Ctx{"srcDevPath": srcDevPath, "targetPath": targetDevPath})
+
+			err = ensureSparseFile(targetDevPath, 0)
+			if err != nil {
+				return err
+			}
+
+			err = copyDevice(srcDevPath, targetDevPath)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -617,16 +696,24 @@ func (d *dir) RestoreVolume(vol Volume, snapVol Volume, op *operations.Operation
 			return err
 		}
 
-		d.Logger().Debug("Restoring block volume", logger.Ctx{"srcDevPath": srcDevPath, "targetPath": targetDevPath})
-
-		err = ensureSparseFile(targetDevPath, 0)
+		// Use a reflink where supported, so that the block volume doesn't need to be copied in full.
+		cloned, err := cloneFile(srcDevPath, targetDevPath)
 		if err != nil {
 			return err
 		}
 
-		err = copyDevice(srcDevPath, targetDevPath)
-		if err != nil {
-			return err
+		if !cloned {
+			d.Logger().Debug("Restoring block volume", logger.Ctx{"srcDevPath": srcDevPath, "targetPath": targetDevPath})
+
+			err = ensureSparseFile(targetDevPath, 0)
+			if err != nil {
+				return err
+			}
+
+			err = copyDevice(srcDevPath, targetDevPath)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -0,0 +1,92 @@
+package drivers
+
+import (
+	"github.com/canonical/lxd/lxd/fault"
+	"github.com/canonical/lxd/lxd/operations"
+)
+
+// faultDriver wraps a driver to fail the calls that change or mount the pool and its volumes while a storage
+// driver fault is injected for the pool.
+type faultDriver struct {
+	driver
+}
+
+// Mount mounts the storage pool unless a fault is injected.
+func (d *faultDriver) Mount() (bool, error) {
+	err := fault.StorageDriverError(d.Name(), "Mount")
+	if err != nil {
+		return false, err
+	}
+
+	return d.driver.Mount()
+}
+
+// CreateVolume creates a volume unless a fault is injected.
+func (d *faultDriver) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Operation) error {
+	err := fault.StorageDriverError(d.Name(), "CreateVolume")
+	if err != nil {
+		return err
+	}
+
+	return d.driver.CreateVolume(vol, filler, op)
+}
+
+// CreateVolumeFromCopy copies a volume unless a fault is injected.
+func (d *faultDriver) CreateVolumeFromCopy(vol VolumeCopy, srcVol VolumeCopy, allowInconsistent bool, op *operations.Operation) error {
+	err := fault.StorageDriverError(d.Name(), "CreateVolumeFromCopy")
+	if err != nil {
+		return err
+	}
+
+	return d.driver.CreateVolumeFromCopy(vol, srcVol, allowInconsistent, op)
+}
+
+// DeleteVolume deletes a volume unless a fault is injected.
+func (d *faultDriver) DeleteVolume(vol Volume, op *operations.Operation) error {
+	err := fault.StorageDriverError(d.Name(), "DeleteVolume")
+	if err != nil {
+		return err
+	}
+
+	return d.driver.DeleteVolume(vol, op)
+}
+
+// MountVolume mounts a volume unless a fault is injected.
+func (d *faultDriver) MountVolume(vol Volume, op *operations.Operation) error {
+	err := fault.StorageDriverError(d.Name(), "MountVolume")
+	if err != nil {
+		return err
+	}
+
+	return d.driver.MountVolume(vol, op)
+}
+
+// UnmountVolume unmounts a volume unless a fault is injected.
+func (d *faultDriver) UnmountVolume(vol Volume, keepBlockDev bool, op *operations.Operation) (bool, error) {
+	err := fault.StorageDriverError(d.Name(), "UnmountVolume")
+	if err != nil {
+		return false, err
+	}
+
+	return d.driver.UnmountVolume(vol, keepBlockDev, op)
+}
+
+// CreateVolumeSnapshot creates a volume snapshot unless a fault is injected.
+func (d *faultDriver) CreateVolumeSnapshot(snapVol Volume, op *operations.Operation) error {
+	err := fault.StorageDriverError(d.Name(), "CreateVolumeSnapshot")
+	if err != nil {
+		return err
+	}
+
+	return d.driver.CreateVolumeSnapshot(snapVol, op)
+}
+
+// DeleteVolumeSnapshot deletes a volume snapshot unless a fault is injected.
+func (d *faultDriver) DeleteVolumeSnapshot(snapVol Volume, op *operations.Operation) error {
+	err := fault.StorageDriverError(d.Name(), "DeleteVolumeSnapshot")
+	if err != nil {
+		return err
+	}
+
+	return d.driver.DeleteVolumeSnapshot(snapVol, op)
+}
@@ -89,6 +89,9 @@ func (d *lvm) Info() Info {
 		Version:                      lvmVersion,
 		DefaultVMBlockFilesystemSize: deviceConfig.DefaultVMBlockFilesystemSize,
 		OptimizedImages:              d.usesThinpool(), // Only thinpool pools support optimized images.
+		OptimizedCopy:                d.usesThinpool(),
+		LiveResize:                   true,
+		RestoreAnySnapshot:           true,
 		PreservesInodes:              false,
 		Remote:                       d.isRemote(),
 		VolumeTypes:                  []VolumeType{VolumeTypeBucket, VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
@@ -28,6 +28,9 @@ func (d *mock) Info() Info {
 		Version:                      "1",
 		DefaultVMBlockFilesystemSize: deviceConfig.DefaultVMBlockFilesystemSize,
 		OptimizedImages:              false,
+		OptimizedCopy:                false,
+		LiveResize:                   false,
+		RestoreAnySnapshot:           true,
 		PreservesInodes:              false,
 		Remote:                       d.isRemote(),
 		VolumeTypes:                  []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
@@ -70,6 +70,9 @@ func (d *powerflex) Info() Info {
 		Version:                      powerFlexVersion,
 		DefaultVMBlockFilesystemSize: deviceConfig.DefaultVMPowerFlexBlockFilesystemSize,
 		OptimizedImages:              false,
+		OptimizedCopy:                false,
+		LiveResize:                   false,
+		RestoreAnySnapshot:           true,
 		PreservesInodes:              false,
 		Remote:                       d.isRemote(),
 		VolumeTypes:                  []VolumeType{VolumeTypeCustom, VolumeTypeVM, VolumeTypeContainer, VolumeTypeImage},
@@ -303,7 +306,7 @@ func (d *powerflex) MigrationTypes(contentType ContentType, refresh bool, copySn
 	if shared.IsFalse(d.Config()["rsync.compression"]) {
 		rsyncFeatures = []string{"xattrs", "delete", "bidirectional"}
 	} else {
-		rsyncFeatures = []string{"xattrs", "delete", "compress", "bidirectional"}
+		rsyncFeatures = append([]string{"xattrs", "delete", "bidirectional"}, rsyncCompressionFeatures()...)
 	}
 
 	if refresh {
@@ -11,6 +11,9 @@ type Info struct {
 	VolumeMultiNode              bool         // Whether volumes can be used on multiple nodes concurrently.
 	OptimizedImages              bool         // Whether driver stores images as separate volume.
 	OptimizedBackups             bool         // Whether driver supports optimized volume backups.
+	OptimizedCopy                bool         // Whether driver copies volumes by instantly cloning them.
+	LiveResize                   bool         // Whether filesystem volumes can be grown while in use.
+	RestoreAnySnapshot           bool         // Whether a snapshot can be restored without deleting the more recent ones.
 	OptimizedBackupHeader        bool         // Whether driver generates an optimised backup header file in backup.
 	PreservesInodes              bool         // Whether driver preserves inodes when volumes are moved hosts.
 	BlockBacking                 bool         // Whether driver uses block devices as backing store.
@@ -18,6 +21,7 @@ type Info struct {
 	DirectIO                     bool         // Whether the driver supports direct I/O.
 	IOUring                      bool         // Whether the driver supports io_uring.
 	MountedRoot                  bool         // Whether the pool directory itself is a mount.
+	Volatile                     bool         // Whether the pool content is lost when the pool is unmounted.
 }
 
 // VolumeFiller provides a struct for filling a volume.
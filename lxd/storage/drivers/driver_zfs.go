@@ -121,6 +121,9 @@ func (d *zfs) Info() Info {
 		Version:                      zfsVersion,
 		DefaultVMBlockFilesystemSize: deviceConfig.DefaultVMBlockFilesystemSize,
 		OptimizedImages:              true,
+		OptimizedCopy:                true,
+		LiveResize:                   true,
+		RestoreAnySnapshot:           false,
 		OptimizedBackups:             true,
 		PreservesInodes:              true,
 		Remote:                       d.isRemote(),
@@ -666,7 +669,7 @@ func (d *zfs) MigrationTypes(contentType ContentType, refresh bool, copySnapshot
 	if shared.IsFalse(d.Config()["rsync.compression"]) {
 		rsyncFeatures = []string{"xattrs", "delete", "bidirectional"}
 	} else {
-		rsyncFeatures = []string{"xattrs", "delete", "compress", "bidirectional"}
+		rsyncFeatures = append([]string{"xattrs", "delete", "bidirectional"}, rsyncCompressionFeatures()...)
 	}
 
 	// Detect ZFS features.
@@ -1729,6 +1729,31 @@ func (d *zfs) GetVolumeUsage(vol Volume) (int64, error) {
 	return valueInt, nil
 }
 
+// GetVolumeSnapshotUsage returns the disk space only used by the snapshot and the disk space it references.
+func (d *zfs) GetVolumeSnapshotUsage(snapVol Volume) (int64, int64, error) {
+	if !snapVol.IsSnapshot() {
+		return -1, -1, ErrNotSupported
+	}
+
+	// The "used" property of a snapshot is the space that would be freed by destroying it.
+	props, err := d.getDatasetProperties(d.dataset(snapVol, false), "used", "referenced")
+	if err != nil {
+		return -1, -1, err
+	}
+
+	used, err := strconv.ParseInt(props["used"], 10, 64)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	referenced, err := strconv.ParseInt(props["referenced"], 10, 64)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	return used, referenced, nil
+}
+
 // SetVolumeQuota sets the quota/reservation on the volume.
 // Does nothing if supplied with an empty/zero size for block volumes.
 func (d *zfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) error {
@@ -169,6 +169,11 @@ func genericVFSMigrateVolume(d Driver, s *state.State, vol VolumeCopy, conn io.R
 		}
 	}
 
+	// Apply the requested compression level if the transfer is compressed.
+	if volSrcArgs.CompressionLevel > 0 && shared.ValueInSlice("compress", volSrcArgs.MigrationType.Features) {
+		rsyncArgs = append(rsyncArgs, fmt.Sprintf("--compress-level=%d", volSrcArgs.CompressionLevel))
+	}
+
 	// Define function to send a filesystem volume.
 	sendFSVol := func(vol Volume, conn io.ReadWriteCloser, mountPath string) error {
 		var wrapper *ioprogress.ProgressTracker
@@ -220,7 +225,14 @@ func genericVFSMigrateVolume(d Driver, s *state.State, vol VolumeCopy, conn io.R
 			}
 		}
 
-		d.Logger().Debug("Sending block volume", logger.Ctx{"volName": vol.name, "path": path})
+		// Compute the block checksums while sending if requested.
+		var checksums *migration.BlockChecksums
+		if volSrcArgs.BlockChecksums {
+			checksums = migration.NewBlockChecksums()
+			fromPipe = io.NopCloser(io.TeeReader(fromPipe, checksums))
+		}
+
+		d.Logger().Debug("Sending block volume", logger.Ctx{"volName": vol.name, "path": path, "checksums": volSrcArgs.BlockChecksums})
 		_, err = io.Copy(conn, fromPipe)
 		if err != nil {
 			return fmt.Errorf("Error copying %q to migration connection: %w", path, err)
@@ -231,6 +243,19 @@ func genericVFSMigrateVolume(d Driver, s *state.State, vol VolumeCopy, conn io.R
 			return fmt.Errorf("Failed to close file %q: %w", path, err)
 		}
 
+		if checksums != nil {
+			// Indicate the end of the volume data before sending the checksums.
+			err = conn.Close()
+			if err != nil {
+				return err
+			}
+
+			err = migration.SendBlockChecksums(conn, checksums)
+			if err != nil {
+				return err
+			}
+		}
+
 		return nil
 	}
 
@@ -353,7 +378,14 @@ func genericVFSCreateVolumeFromMigration(d Driver, initVolume func(vol Volume) (
 			}
 		}
 
-		d.Logger().Debug("Receiving block volume started", logger.Ctx{"volName": volName, "path": path})
+		// Compute the block checksums while receiving if requested.
+		var checksums *migration.BlockChecksums
+		if volTargetArgs.BlockChecksums {
+			checksums = migration.NewBlockChecksums()
+			fromPipe = io.NopCloser(io.TeeReader(fromPipe, checksums))
+		}
+
+		d.Logger().Debug("Receiving block volume started", logger.Ctx{"volName": volName, "path": path, "checksums": volTargetArgs.BlockChecksums})
 		defer d.Logger().Debug("Receiving block volume stopped", logger.Ctx{"volName": volName, "path": path})
 
 		_, err = io.Copy(to, fromPipe)
@@ -361,6 +393,13 @@ func genericVFSCreateVolumeFromMigration(d Driver, initVolume func(vol Volume) (
 			return fmt.Errorf("Error copying from migration connection to %q: %w", path, err)
 		}
 
+		if checksums != nil {
+			err = migration.VerifyBlockChecksums(op, volName, conn, checksums)
+			if err != nil {
+				return err
+			}
+		}
+
 		return to.Close()
 	}
 
@@ -1,6 +1,7 @@
 package drivers
 
 import (
+	"github.com/canonical/lxd/lxd/fault"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared/logger"
 )
@@ -46,6 +47,10 @@ func Load(state *state.State, driverName string, name string, config map[string]
 		return nil, err
 	}
 
+	if fault.Enabled() {
+		return &faultDriver{driver: d}, nil
+	}
+
 	return d, nil
 }
 
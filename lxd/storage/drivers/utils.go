@@ -2,6 +2,7 @@ package drivers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -15,7 +16,9 @@ import (
 	"golang.org/x/sys/unix"
 
 	"github.com/canonical/lxd/lxd/idmap"
+	"github.com/canonical/lxd/lxd/migration"
 	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/rsync"
 	"github.com/canonical/lxd/lxd/storage/filesystem"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -653,6 +656,36 @@ func copyDevice(inputPath string, outputPath string) error {
 	return nil
 }
 
+// cloneFile makes the file at outputPath a reflink copy of the file at inputPath, so that both files share
+// their data blocks until either of them is modified. This is instant regardless of the file size.
+// Returns false if the filesystem doesn't support reflinks, in which case the file needs to be copied.
+func cloneFile(inputPath string, outputPath string) (bool, error) {
+	from, err := os.Open(inputPath)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() { _ = from.Close() }()
+
+	to, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() { _ = to.Close() }()
+
+	err = unix.IoctlFileClone(int(to.Fd()), int(from.Fd()))
+	if err != nil {
+		if errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EXDEV) || errors.Is(err, unix.EINVAL) || errors.Is(err, unix.ENOTTY) || errors.Is(err, unix.ENOSYS) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("Failed cloning %q to %q: %w", inputPath, outputPath, err)
+	}
+
+	return true, to.Close()
+}
+
 // loopFilePath returns the loop file path for a storage pool.
 func loopFilePath(poolName string) string {
 	return filepath.Join(shared.VarPath("disks"), fmt.Sprintf("%s.img", poolName))
@@ -922,3 +955,20 @@ func roundAbove(above, val int64) int64 {
 
 	return rounded
 }
+
+// rsyncCompressionFeatures returns the rsync migration features for the compression algorithms supported by
+// the local rsync.
+func rsyncCompressionFeatures() []string {
+	features := []string{"compress"}
+
+	algorithms := rsync.CompressionAlgorithms()
+	if shared.ValueInSlice("zstd", algorithms) {
+		features = append(features, migration.RsyncFeatureCompressZstd)
+	}
+
+	if shared.ValueInSlice("lz4", algorithms) {
+		features = append(features, migration.RsyncFeatureCompressLz4)
+	}
+
+	return features
+}
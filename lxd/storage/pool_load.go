@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"golang.org/x/sync/errgroup"
+
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/project"
@@ -274,17 +276,27 @@ func Patch(s *state.State, patchName string) error {
 		return fmt.Errorf("Failed loading storage pool names: %w", err)
 	}
 
+	// The pools are independent from each other, so patch several of them at the same time.
+	g := errgroup.Group{}
+	if s.LocalConfig != nil {
+		g.SetLimit(int(s.LocalConfig.StoragePatchConcurrency()))
+	}
+
 	for _, poolName := range pools {
-		pool, err := LoadByName(s, poolName)
-		if err != nil {
-			return fmt.Errorf("Failed loading storage pool %q: %w", poolName, err)
-		}
+		g.Go(func() error {
+			pool, err := LoadByName(s, poolName)
+			if err != nil {
+				return fmt.Errorf("Failed loading storage pool %q: %w", poolName, err)
+			}
 
-		err = pool.ApplyPatch(patchName)
-		if err != nil {
-			return fmt.Errorf("Failed applying patch to pool %q: %w", poolName, err)
-		}
+			err = pool.ApplyPatch(patchName)
+			if err != nil {
+				return fmt.Errorf("Failed applying patch to pool %q: %w", poolName, err)
+			}
+
+			return nil
+		})
 	}
 
-	return nil
+	return g.Wait()
 }
@@ -0,0 +1,87 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+)
+
+// Bucket config keys defining the lifecycle rules of a bucket.
+const (
+	LifecycleExpirationDays             = "lifecycle.expiration_days"
+	LifecycleAbortIncompleteUploadsDays = "lifecycle.abort_incomplete_uploads_days"
+)
+
+// IsLifecycleKey returns whether the bucket config key defines a lifecycle rule.
+func IsLifecycleKey(key string) bool {
+	return strings.HasPrefix(key, "lifecycle.")
+}
+
+// BucketLifecycle returns the S3 lifecycle configuration enforcing the lifecycle rules of the bucket config.
+// The returned configuration is empty if no rule is set.
+func BucketLifecycle(config map[string]string) (*lifecycle.Configuration, error) {
+	lc := lifecycle.NewConfiguration()
+
+	expirationDays, err := lifecycleDays(config, LifecycleExpirationDays)
+	if err != nil {
+		return nil, err
+	}
+
+	if expirationDays > 0 {
+		lc.Rules = append(lc.Rules, lifecycle.Rule{
+			ID:         "lxd-expiration",
+			Status:     "Enabled",
+			Expiration: lifecycle.Expiration{Days: lifecycle.ExpirationDays(expirationDays)},
+		})
+	}
+
+	abortDays, err := lifecycleDays(config, LifecycleAbortIncompleteUploadsDays)
+	if err != nil {
+		return nil, err
+	}
+
+	if abortDays > 0 {
+		lc.Rules = append(lc.Rules, lifecycle.Rule{
+			ID:                             "lxd-abort-incomplete-uploads",
+			Status:                         "Enabled",
+			AbortIncompleteMultipartUpload: lifecycle.AbortIncompleteMultipartUpload{DaysAfterInitiation: lifecycle.ExpirationDays(abortDays)},
+		})
+	}
+
+	return lc, nil
+}
+
+// SetBucketLifecycle replaces the lifecycle configuration of the bucket with the lifecycle rules of the bucket
+// config. The lifecycle configuration is removed if no rule is set.
+func SetBucketLifecycle(ctx context.Context, client *minio.Client, bucketName string, config map[string]string) error {
+	lc, err := BucketLifecycle(config)
+	if err != nil {
+		return err
+	}
+
+	err = client.SetBucketLifecycle(ctx, bucketName, lc)
+	if err != nil {
+		return fmt.Errorf("Failed setting bucket lifecycle: %w", err)
+	}
+
+	return nil
+}
+
+// lifecycleDays returns the number of days of the given lifecycle rule, or 0 if it isn't set.
+func lifecycleDays(config map[string]string, key string) (int, error) {
+	value := config[key]
+	if value == "" {
+		return 0, nil
+	}
+
+	days, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid value for %q: %w", key, err)
+	}
+
+	return int(days), nil
+}
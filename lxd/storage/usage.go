@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// UsageForecastWindow is the period of the usage samples the forecast of a storage pool is based on.
+const UsageForecastWindow = 7 * 24 * time.Hour
+
+// usageForecast fits the used disk space of the given samples over time, and returns the resulting growth per day
+// along with the number of days until the pool is full. Returns nil if there aren't enough samples.
+func usageForecast(samples []db.StoragePoolUsageSample, used uint64, total uint64) *api.ResourcesStoragePoolForecast {
+	if len(samples) < 2 || samples[len(samples)-1].Date.Sub(samples[0].Date) < time.Hour {
+		return nil
+	}
+
+	// Least squares fit of the used disk space (bytes) against time (days).
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range samples {
+		x := sample.Date.Sub(samples[0].Date).Hours() / 24
+		y := float64(sample.Used)
+
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	n := float64(len(samples))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return nil
+	}
+
+	growth := (n*sumXY - sumX*sumY) / denominator
+
+	forecast := &api.ResourcesStoragePoolForecast{
+		GrowthPerDay:  int64(growth),
+		DaysUntilFull: -1,
+		Since:         samples[0].Date,
+	}
+
+	if growth >= 1 {
+		forecast.DaysUntilFull = 0
+		if total > used {
+			forecast.DaysUntilFull = int64(float64(total-used) / growth)
+		}
+	}
+
+	return forecast
+}
+
+// getUsageForecast returns the forecast disk space usage of the pool based on its recorded usage samples.
+func (b *lxdBackend) getUsageForecast(used uint64, total uint64) (*api.ResourcesStoragePoolForecast, error) {
+	var samples []db.StoragePoolUsageSample
+	err := b.state.DB.Node.Transaction(context.TODO(), func(ctx context.Context, tx *db.NodeTx) error {
+		var err error
+		samples, err = tx.GetStoragePoolUsageSamples(ctx, b.name, time.Now().Add(-UsageForecastWindow))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return usageForecast(samples, used, total), nil
+}
+
+// checkFillThreshold returns an error if the used disk space of the pool, increased by the given number of bytes,
+// exceeds the fill threshold configured on the pool.
+func (b *lxdBackend) checkFillThreshold(extra int64) error {
+	threshold := b.db.Config["fill_threshold"]
+	if threshold == "" {
+		return nil
+	}
+
+	percent, err := strconv.ParseUint(threshold, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid fill threshold %q: %w", threshold, err)
+	}
+
+	res, err := b.driver.GetResources()
+	if err != nil {
+		if errors.Is(err, drivers.ErrNotSupported) {
+			return nil
+		}
+
+		return fmt.Errorf("Failed getting storage pool usage: %w", err)
+	}
+
+	if res.Space.Total == 0 {
+		return nil
+	}
+
+	used := res.Space.Used
+	if extra > 0 {
+		used += uint64(extra)
+	}
+
+	if used*100 > res.Space.Total*percent {
+		return api.StatusErrorf(http.StatusInsufficientStorage, "Storage pool %q would be %d%% full, above its fill threshold of %d%%", b.name, used*100/res.Space.Total, percent)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -25,6 +26,7 @@ import (
 	"github.com/canonical/lxd/lxd/rsync"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/lxd/storage/s3"
 	"github.com/canonical/lxd/lxd/sys"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -536,6 +538,35 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		rules["security.unmapped"] = validate.Optional(validate.IsBool)
 	}
 
+	// security.export.projects is only relevant for custom volumes.
+	if vol != nil && vol.Type() == drivers.VolumeTypeCustom {
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=security.export.projects)
+		// Specify a comma-separated list of projects whose instances can attach the volume read-only, using the `source.project` option of the disk device.
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  shortdesc: Projects the volume is exported to
+		rules["security.export.projects"] = validate.Optional(validate.IsListOf(validate.IsNotEmpty))
+	}
+
+	// Lifecycle rules are only relevant for buckets.
+	if vol != nil && vol.Type() == drivers.VolumeTypeBucket {
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephobject,storage-dir,storage-lvm,storage-zfs; group=bucket-conf; key=lifecycle.expiration_days)
+		// Objects are deleted by the bucket backend once they are older than the given number of days.
+		// ---
+		//  type: integer
+		//  defaultdesc: `0` (objects don't expire)
+		//  shortdesc: Number of days after which objects expire
+		rules[s3.LifecycleExpirationDays] = validate.Optional(validate.IsUint32)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephobject,storage-dir,storage-lvm,storage-zfs; group=bucket-conf; key=lifecycle.abort_incomplete_uploads_days)
+		// Multipart uploads that aren't completed within the given number of days are aborted by the bucket backend, and their parts are deleted.
+		// ---
+		//  type: integer
+		//  defaultdesc: `0` (incomplete uploads are kept)
+		//  shortdesc: Number of days after which incomplete multipart uploads are aborted
+		rules[s3.LifecycleAbortIncompleteUploadsDays] = validate.Optional(validate.IsUint32)
+	}
+
 	// Those keys are only valid for volumes.
 	if vol != nil {
 		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=volatile.uuid)
@@ -613,6 +644,14 @@ func validatePoolCommonRules() map[string]func(string) error {
 		//  defaultdesc: `true`
 		//  shortdesc: Whether to use compression while migrating storage pools
 		"rsync.compression": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=storage-btrfs,storage-ceph,storage-cephfs,storage-dir,storage-lvm,storage-powerflex,storage-zfs; group=pool-conf; key=fill_threshold)
+		// Snapshots, copies and backups are refused when the used disk space of the pool, including the
+		// estimated size of copied volumes, would exceed this percentage of its total disk space.
+		// See {ref}`storage-pool-forecast` for more information.
+		// ---
+		//  type: integer
+		//  shortdesc: Percentage of used disk space above which snapshots, copies and backups are refused
+		"fill_threshold": validate.Optional(validate.IsInRange(1, 100)),
 	}
 
 	// Add to pool config rules (prefixed with volume.*) which are common for pool and volume.
@@ -652,6 +691,63 @@ func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error
 	return rules
 }
 
+// qcow2VirtualSize returns the virtual size of the qcow2 image file.
+func qcow2VirtualSize(sysOS *sys.OS, imgPath string, dstPath string) (int64, error) {
+	// Get info about qcow2 file. Force input format to qcow2 so we don't rely on qemu-img's detection
+	// logic as that has been known to have vulnerabilities and we only support qcow2 images anyway.
+	// Use prlimit because qemu-img can consume considerable RAM & CPU time if fed a maliciously
+	// crafted disk image. Since cloud tenants are not to be trusted, ensure QEMU is limits to 1 GiB
+	// address space and 2 seconds CPU time, which ought to be more than enough for real world images.
+	cmd := []string{"prlimit", "--cpu=2", "--as=1073741824", "qemu-img", "info", "-f", "qcow2", "--output=json", imgPath}
+	imgJSON, err := apparmor.QemuImg(sysOS, cmd, imgPath, dstPath)
+	if err != nil {
+		return -1, fmt.Errorf("Failed reading image info %q: %w", imgPath, err)
+	}
+
+	imgInfo := struct {
+		Format      string `json:"format"`
+		VirtualSize int64  `json:"virtual-size"`
+	}{}
+
+	err = json.Unmarshal([]byte(imgJSON), &imgInfo)
+	if err != nil {
+		return -1, fmt.Errorf("Failed unmarshalling image info %q: %w (%q)", imgPath, err, imgJSON)
+	}
+
+	// Belt and braces qcow2 check.
+	if imgInfo.Format != "qcow2" {
+		return -1, fmt.Errorf("Unexpected image format %q", imgInfo.Format)
+	}
+
+	return imgInfo.VirtualSize, nil
+}
+
+// ImageRootfsSize returns the size of the disk of a VM image once unpacked, or 0 if it can't be determined
+// without unpacking the image (for container images and unified VM images).
+func ImageRootfsSize(sysOS *sys.OS, fingerprint string) (int64, error) {
+	imageRootfsFile := shared.VarPath("images", fingerprint) + ".rootfs"
+	if !shared.PathExists(imageRootfsFile) {
+		return 0, nil
+	}
+
+	return qcow2VirtualSize(sysOS, imageRootfsFile, "")
+}
+
+// imageUnpackError returns a more helpful error if unpacking an image failed because it doesn't fit into the
+// volume.
+func imageUnpackError(vol drivers.Volume, err error) error {
+	if !errors.Is(err, unix.ENOSPC) && !strings.Contains(err.Error(), "No space left on device") {
+		return err
+	}
+
+	volSize := vol.ConfigSize()
+	if volSize == "" || volSize == "0" {
+		return fmt.Errorf("Image doesn't fit into the storage pool: %w", err)
+	}
+
+	return fmt.Errorf("Image doesn't fit into the volume of size %s, use a larger root disk size: %w", volSize, err)
+}
+
 // ImageUnpack unpacks a filesystem image into the destination path.
 // There are several formats that images can come in:
 // Container Format A: Separate metadata tarball and root squashfs file.
@@ -680,7 +776,7 @@ func ImageUnpack(imageFile string, vol drivers.Volume, destBlockFile string, sys
 		// Unpack the main image file.
 		err := archive.Unpack(imageFile, destPath, vol.IsBlockBacked(), sysOS, tracker)
 		if err != nil {
-			return -1, err
+			return -1, imageUnpackError(vol, err)
 		}
 
 		// Check for separate root file.
@@ -692,7 +788,7 @@ func ImageUnpack(imageFile string, vol drivers.Volume, destBlockFile string, sys
 
 			err = archive.Unpack(imageRootfsFile, rootfsPath, vol.IsBlockBacked(), sysOS, tracker)
 			if err != nil {
-				return -1, err
+				return -1, imageUnpackError(vol, err)
 			}
 		}
 
@@ -721,36 +817,15 @@ func ImageUnpack(imageFile string, vol drivers.Volume, destBlockFile string, sys
 	// convertBlockImage converts the qcow2 block image file into a raw block device. If needed it will attempt
 	// to enlarge the destination volume to accommodate the unpacked qcow2 image file.
 	convertBlockImage := func(v drivers.Volume, imgPath string, dstPath string) (int64, error) {
-		// Get info about qcow2 file. Force input format to qcow2 so we don't rely on qemu-img's detection
-		// logic as that has been known to have vulnerabilities and we only support qcow2 images anyway.
-		// Use prlimit because qemu-img can consume considerable RAM & CPU time if fed a maliciously
-		// crafted disk image. Since cloud tenants are not to be trusted, ensure QEMU is limits to 1 GiB
-		// address space and 2 seconds CPU time, which ought to be more than enough for real world images.
-		cmd := []string{"prlimit", "--cpu=2", "--as=1073741824", "qemu-img", "info", "-f", "qcow2", "--output=json", imgPath}
-		imgJSON, err := apparmor.QemuImg(sysOS, cmd, imgPath, dstPath)
-		if err != nil {
-			return -1, fmt.Errorf("Failed reading image info %q: %w", imgPath, err)
-		}
-
-		imgInfo := struct {
-			Format      string `json:"format"`
-			VirtualSize int64  `json:"virtual-size"`
-		}{}
-
-		err = json.Unmarshal([]byte(imgJSON), &imgInfo)
+		virtualSize, err := qcow2VirtualSize(sysOS, imgPath, dstPath)
 		if err != nil {
-			return -1, fmt.Errorf("Failed unmarshalling image info %q: %w (%q)", imgPath, err, imgJSON)
-		}
-
-		// Belt and braces qcow2 check.
-		if imgInfo.Format != "qcow2" {
-			return -1, fmt.Errorf("Unexpected image format %q", imgInfo.Format)
+			return -1, err
 		}
 
 		// Check whether image is allowed to be unpacked into pool volume. Create a partial image volume
 		// struct and then use it to check that target volume size can be set as needed.
 		imgVolConfig := map[string]string{
-			"volatile.rootfs.size": fmt.Sprintf("%d", imgInfo.VirtualSize),
+			"volatile.rootfs.size": fmt.Sprintf("%d", virtualSize),
 		}
 
 		imgVol := drivers.NewVolume(nil, "", drivers.VolumeTypeImage, drivers.ContentTypeBlock, "", imgVolConfig, nil)
@@ -769,7 +844,7 @@ func ImageUnpack(imageFile string, vol drivers.Volume, destBlockFile string, sys
 
 			// If the target volume's size is smaller than the image unpack size, then we need to
 			// increase the target volume's size.
-			if volSizeBytes < imgInfo.VirtualSize {
+			if volSizeBytes < virtualSize {
 				l.Debug("Increasing volume size", logger.Ctx{"imgPath": imgPath, "dstPath": dstPath, "oldSize": volSizeBytes, "newSize": newVolSize, "allowUnsafeResize": allowUnsafeResize})
 				err = vol.SetQuota(newVolSize, allowUnsafeResize, nil)
 				if err != nil {
@@ -781,7 +856,7 @@ func ImageUnpack(imageFile string, vol drivers.Volume, destBlockFile string, sys
 		// Convert the qcow2 format to a raw block device.
 		l.Debug("Converting qcow2 image to raw disk", logger.Ctx{"imgPath": imgPath, "dstPath": dstPath})
 
-		cmd = []string{
+		cmd := []string{
 			"nice", "-n19", // Run with low priority to reduce CPU impact on other processes.
 			"qemu-img", "convert", "-f", "qcow2", "-O", "raw",
 		}
@@ -812,7 +887,7 @@ func ImageUnpack(imageFile string, vol drivers.Volume, destBlockFile string, sys
 			return -1, fmt.Errorf("Failed converting image to raw at %q: %w", dstPath, err)
 		}
 
-		return imgInfo.VirtualSize, nil
+		return virtualSize, nil
 	}
 
 	var imgSize int64
@@ -942,13 +1017,6 @@ func VolumeUsedByProfileDevices(s *state.State, poolName string, projectName str
 			return err
 		}
 
-		// Check profile's storage project is the same as the volume's project.
-		// If not then the volume names mentioned in the profile's config cannot be referring to volumes
-		// in the volume's project we are trying to match, and this profile cannot possibly be using it.
-		if projectName != profileStorageProject {
-			continue
-		}
-
 		var usedByDevices []string
 
 		// Iterate through each of the profiles's devices, looking for disks in the same pool as volume.
@@ -962,6 +1030,11 @@ func VolumeUsedByProfileDevices(s *state.State, poolName string, projectName str
 				continue
 			}
 
+			// Check the volume referenced by the device is in the volume's project.
+			if diskVolumeProject(dev, profileStorageProject) != projectName {
+				continue
+			}
+
 			if dev["source"] == vol.Name {
 				usedByDevices = append(usedByDevices, name)
 			}
@@ -978,6 +1051,17 @@ func VolumeUsedByProfileDevices(s *state.State, poolName string, projectName str
 	return nil
 }
 
+// diskVolumeProject returns the project of the custom volume referenced by a disk device, given the effective
+// storage project of the instance or profile that the device belongs to. Disk devices can reference volumes
+// exported by other projects using the "source.project" setting.
+func diskVolumeProject(dev map[string]string, storageProjectName string) string {
+	if dev["source.project"] != "" {
+		return dev["source.project"]
+	}
+
+	return storageProjectName
+}
+
 // VolumeUsedByInstanceDevices finds instances using a volume (either directly or via their expanded profiles if
 // expandDevices is true) and passes them to instanceFunc for evaluation. If instanceFunc returns an error then it
 // is returned immediately. The instanceFunc is executed during a DB transaction, so DB queries are not permitted.
@@ -1003,13 +1087,6 @@ func VolumeUsedByInstanceDevices(s *state.State, poolName string, projectName st
 				return err
 			}
 
-			// Check instance's storage project is the same as the volume's project.
-			// If not then the volume names mentioned in the instance's config cannot be referring to volumes
-			// in the volume's project we are trying to match, and this instance cannot possibly be using it.
-			if projectName != instStorageProject {
-				return nil
-			}
-
 			// Use local devices for usage check by if expandDevices is false (but don't modify instance).
 			devices := inst.Devices
 
@@ -1031,6 +1108,11 @@ func VolumeUsedByInstanceDevices(s *state.State, poolName string, projectName st
 					continue
 				}
 
+				// Check the volume referenced by the device is in the volume's project.
+				if diskVolumeProject(dev, instStorageProject) != projectName {
+					continue
+				}
+
 				if dev["source"] == vol.Name {
 					usedByDevices = append(usedByDevices, devName)
 				}
@@ -1104,6 +1186,11 @@ func VolumeUsedByDaemon(s *state.State, poolName string, volumeName string) (boo
 		return true, nil
 	}
 
+	// Check the volume shared by all cluster members.
+	if s.GlobalConfig != nil && s.GlobalConfig.ImagesVolume() == fullName {
+		return true, nil
+	}
+
 	return false, nil
 }
 
@@ -1134,6 +1221,11 @@ func RenderSnapshotUsage(s *state.State, snapInst instance.Instance) func(respon
 			if err == nil {
 				apiRes.Size = volumeState.Used
 			}
+
+			usage, err := pool.GetInstanceSnapshotUsage(snapInst)
+			if err == nil {
+				apiRes.Usage = usage
+			}
 		}
 
 		return nil
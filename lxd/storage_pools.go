@@ -770,8 +770,15 @@ func storagePoolPut(d *Daemon, r *http.Request) response.Response {
 
 	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
 
+	oldRevision := storagePoolConfigRevision(s, pool)
+
 	response := doStoragePoolUpdate(s, pool, req, targetNode, clientType, r.Method, s.ServerClustered)
 
+	// Only changes to the global configuration are recorded, and only by the member handling the request.
+	if targetNode == "" && clientType == clusterRequest.ClientTypeNormal {
+		storagePoolConfigHistoryRecord(s, r, poolName, oldRevision)
+	}
+
 	requestor := request.CreateRequestor(r)
 
 	ctx := logger.Ctx{}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// storagePoolUsageRetention is how long the disk space usage samples of the storage pools are kept.
+const storagePoolUsageRetention = 30 * 24 * time.Hour
+
+func storagePoolUsageSampleTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		storagePoolUsageSample(ctx, d.State())
+	}
+
+	return f, task.Hourly()
+}
+
+// storagePoolUsageSample records the disk space usage of the storage pools on the local member, used to forecast
+// when they will be full, and forgets about the samples of deleted pools.
+func storagePoolUsageSample(ctx context.Context, s *state.State) {
+	var poolNames []string
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		poolNames, err = tx.GetStoragePoolNames(ctx)
+		return err
+	})
+	if err != nil && !response.IsNotFoundError(err) {
+		logger.Warn("Failed loading storage pools to sample disk space usage", logger.Ctx{"err": err})
+		return
+	}
+
+	now := time.Now()
+	for _, poolName := range poolNames {
+		if ctx.Err() != nil {
+			return
+		}
+
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			logger.Debug("Failed loading storage pool to sample disk space usage", logger.Ctx{"pool": poolName, "err": err})
+			continue
+		}
+
+		res, err := pool.Driver().GetResources()
+		if err != nil {
+			if !errors.Is(err, storageDrivers.ErrNotSupported) {
+				logger.Debug("Failed getting storage pool disk space usage", logger.Ctx{"pool": poolName, "err": err})
+			}
+
+			continue
+		}
+
+		err = s.DB.Node.Transaction(ctx, func(ctx context.Context, tx *db.NodeTx) error {
+			return tx.CreateStoragePoolUsageSample(ctx, poolName, db.StoragePoolUsageSample{
+				Used:  res.Space.Used,
+				Total: res.Space.Total,
+				Date:  now,
+			})
+		})
+		if err != nil {
+			logger.Warn("Failed recording storage pool disk space usage", logger.Ctx{"pool": poolName, "err": err})
+		}
+	}
+
+	err = s.DB.Node.Transaction(ctx, func(ctx context.Context, tx *db.NodeTx) error {
+		return tx.PruneStoragePoolUsageSamples(ctx, poolNames, now.Add(-storagePoolUsageRetention))
+	})
+	if err != nil {
+		logger.Warn("Failed pruning storage pool disk space usage", logger.Ctx{"err": err})
+	}
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"net/http"
 
 	"github.com/canonical/lxd/lxd/cluster/request"
 	"github.com/canonical/lxd/lxd/db"
@@ -23,7 +22,7 @@ func storagePoolDBCreate(s *state.State, poolName string, poolDescription string
 		return err
 	})
 	if err == nil {
-		return -1, api.StatusErrorf(http.StatusConflict, "Storage pool %q already exists", poolName)
+		return -1, api.ReasonErrorf(api.ErrorReasonAlreadyExists, nil, "Storage pool %q already exists", poolName)
 	}
 
 	// Make sure that we don't pass a nil to the next function.
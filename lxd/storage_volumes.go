@@ -32,6 +32,7 @@ import (
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/rsync"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/lxd/util"
@@ -1178,6 +1179,17 @@ func doVolumeCreateOrCopy(s *state.State, r *http.Request, requestProjectName st
 		return response.EmptySyncResponse
 	}
 
+	// Refuse the copy if it would exceed the disk budget of the target project.
+	sourceProjectName := srcProjectName
+	if sourceProjectName == "" {
+		sourceProjectName = projectName
+	}
+
+	err = projectCheckDiskUsage(r.Context(), s, projectName, customVolumeDiskUsage(s, req.Source.Pool, sourceProjectName, req.Source.Name))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Volume copy operations potentially take a long time, so run as an async operation.
 	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.VolumeCopy, nil, nil, run, nil, nil, r)
 	if err != nil {
@@ -1520,7 +1532,7 @@ func storagePoolVolumePost(d *Daemon, r *http.Request) response.Response {
 		}
 
 		if targetMemberInfo.IsOffline(s.GlobalConfig.OfflineThreshold()) {
-			return response.BadRequest(fmt.Errorf("Target cluster member is offline"))
+			return response.SmartError(api.ReasonErrorf(api.ErrorReasonClusterMemberOffline, map[string]any{"member": targetMemberInfo.Name}, "Target cluster member is offline"))
 		}
 
 		run := func(op *operations.Operation) error {
@@ -1707,7 +1719,7 @@ func storageVolumePostClusteringMigrate(s *state.State, r *http.Request, srcPool
 	// Make sure that the source member is online if we end up being called from another member after a
 	// redirection due to the source member being offline.
 	if srcMemberOffline {
-		return nil, fmt.Errorf("The cluster member hosting the storage volume is offline")
+		return nil, api.ReasonErrorf(api.ErrorReasonClusterMemberOffline, map[string]any{"member": srcMember.Name}, "The cluster member hosting the storage volume is offline")
 	}
 
 	run := func(op *operations.Operation) error {
@@ -1796,11 +1808,19 @@ func storageVolumePostClusteringMigrate(s *state.State, r *http.Request, srcPool
 
 // storagePoolVolumeTypePostMigration handles volume migration type POST requests.
 func storagePoolVolumeTypePostMigration(state *state.State, r *http.Request, requestProjectName string, projectName string, poolName string, volumeName string, req api.StorageVolumePost) response.Response {
+	_, _, err := rsync.ParseCompression(req.CompressionAlgorithm)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
 	ws, err := newStorageMigrationSource(req.VolumeOnly, req.Target)
 	if err != nil {
 		return response.InternalError(err)
 	}
 
+	ws.compressionAlgorithm = req.CompressionAlgorithm
+	ws.verifyChecksums = req.VerifyChecksums
+
 	resources := map[string][]api.URL{}
 	srcVolParentName, srcVolSnapName, srcIsSnapshot := api.GetParentAndSnapshotName(volumeName)
 	if srcIsSnapshot {
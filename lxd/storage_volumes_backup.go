@@ -913,11 +913,12 @@ func storagePoolVolumeTypeCustomBackupExportGet(d *Daemon, r *http.Request) resp
 		return response.SmartError(err)
 	}
 
-	ent := response.FileResponseEntry{
-		Path: shared.VarPath("backups", "custom", poolName, project.StorageVolume(projectName, fullName)),
+	ent, err := backupFileResponseEntry(shared.VarPath("backups", "custom", poolName, project.StorageVolume(projectName, fullName)))
+	if err != nil {
+		return response.SmartError(err)
 	}
 
 	s.Events.SendLifecycle(projectName, lifecycle.StorageVolumeBackupRetrieved.Event(poolName, volumeTypeName, fullName, projectName, request.CreateRequestor(r), nil))
 
-	return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
+	return response.FileResponse(r, []response.FileResponseEntry{*ent}, nil)
 }
@@ -143,7 +143,7 @@ func storagePoolVolumeSnapshotsTypePost(d *Daemon, r *http.Request) response.Res
 			return err
 		}
 
-		return nil
+		return project.CheckDiskUsage(ctx, tx, p, 0)
 	})
 	if err != nil {
 		return response.SmartError(err)
@@ -430,6 +430,15 @@ func storagePoolVolumeSnapshotsTypeGet(d *Daemon, r *http.Request) response.Resp
 		return response.SmartError(err)
 	}
 
+	// Load the pool to report the disk space usage of custom volume snapshots.
+	var pool storagePools.Pool
+	if recursion && volumeType == dbCluster.StoragePoolVolumeTypeCustom {
+		pool, err = storagePools.LoadByName(s, poolName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	// Prepare the response.
 	resultString := []string{}
 	resultMap := []*api.StorageVolumeSnapshot{}
@@ -466,6 +475,10 @@ func storagePoolVolumeSnapshotsTypeGet(d *Daemon, r *http.Request) response.Resp
 				tmp.ExpiresAt = &expiryDate
 			}
 
+			if pool != nil {
+				tmp.Usage, _ = pool.GetCustomVolumeSnapshotUsage(projectName, volume.Name)
+			}
+
 			resultMap = append(resultMap, tmp)
 		}
 	}
@@ -748,6 +761,15 @@ func storagePoolVolumeSnapshotTypeGet(d *Daemon, r *http.Request) response.Respo
 	snapshot.ContentType = dbVolume.ContentType
 	snapshot.CreatedAt = dbVolume.CreatedAt
 
+	if volumeType == dbCluster.StoragePoolVolumeTypeCustom {
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		snapshot.Usage, _ = pool.GetCustomVolumeSnapshotUsage(projectName, fullSnapshotName)
+	}
+
 	etag := []any{snapshot.Description, expiry}
 	return response.SyncResponseETag(true, &snapshot, etag)
 }
@@ -1178,7 +1200,13 @@ func pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d *Daemon) (task.Func, t
 		var memberCount int
 		var onlineMemberIDs []int64
 
-		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		maintenanceAllowed, err := maintenanceWindowChecker(ctx, s)
+		if err != nil {
+			logger.Error("Failed loading maintenance windows", logger.Ctx{"err": err})
+			return
+		}
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 			// Get the list of expired custom volume snapshots for this member (or remote).
 			allExpiredSnapshots, err := tx.GetExpiredStorageVolumeSnapshots(ctx, true)
 			if err != nil {
@@ -1186,6 +1214,11 @@ func pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d *Daemon) (task.Func, t
 			}
 
 			for _, v := range allExpiredSnapshots {
+				// Leave expired snapshots in place until the project's maintenance window.
+				if !maintenanceAllowed(v.ProjectName) {
+					continue
+				}
+
 				if v.NodeID < 0 {
 					// Keep a separate list of remote volumes in order to select a member to
 					// perform the snapshot expiry on later.
@@ -1221,6 +1254,13 @@ func pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d *Daemon) (task.Func, t
 					continue
 				}
 
+				// Skip the scheduled snapshot if the project is out of disk budget.
+				err = project.CheckDiskUsage(ctx, tx, projects[v.ProjectName], 0)
+				if err != nil {
+					logger.Warn("Skipping scheduled custom volume snapshot", logger.Ctx{"project": v.ProjectName, "volume": v.Name, "err": err})
+					continue
+				}
+
 				schedule, ok := v.Config["snapshots.schedule"]
 				if !ok || schedule == "" {
 					continue
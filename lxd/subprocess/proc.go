@@ -27,6 +27,7 @@ type Process struct {
 	Name     string         `yaml:"name"`
 	Args     []string       `yaml:"args,flow"`
 	Apparmor string         `yaml:"apparmor"`
+	Sandbox  []string       `yaml:"sandbox,flow"`
 	PID      int64          `yaml:"pid"`
 	Stdin    io.ReadCloser  `yaml:"-"`
 	Stdout   io.WriteCloser `yaml:"-"`
@@ -84,6 +85,11 @@ func (p *Process) SetApparmor(profile string) {
 	p.Apparmor = profile
 }
 
+// SetSandbox sets the command used to confine the process before executing it, see the sandbox package.
+func (p *Process) SetSandbox(command []string) {
+	p.Sandbox = command
+}
+
 // SetCreds allows setting process credentials.
 func (p *Process) SetCreds(uid uint32, gid uint32) {
 	p.UID = uid
@@ -141,14 +147,19 @@ func (p *Process) StartWithFiles(ctx context.Context, fds []*os.File) error {
 }
 
 func (p *Process) start(ctx context.Context, fds []*os.File) error {
-	var cmd *exec.Cmd
+	args := append([]string{p.Name}, p.Args...)
 
 	if p.Apparmor != "" && p.hasApparmor() {
-		cmd = exec.CommandContext(ctx, "aa-exec", append([]string{"-p", p.Apparmor, p.Name}, p.Args...)...)
-	} else {
-		cmd = exec.CommandContext(ctx, p.Name, p.Args...)
+		args = append([]string{"aa-exec", "-p", p.Apparmor}, args...)
 	}
 
+	// The sandbox command confines the process before executing it, so it must come first.
+	if len(p.Sandbox) > 0 {
+		args = append(append([]string{}, p.Sandbox...), args...)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+
 	cmd.Stdout = p.Stdout
 	cmd.Stderr = p.Stderr
 	cmd.Stdin = p.Stdin
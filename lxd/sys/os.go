@@ -81,10 +81,12 @@ type OS struct {
 	PidFdSetns              bool
 	SeccompListener         bool
 	SeccompListenerContinue bool
+	TimeNamespace           bool
 	UeventInjection         bool
 	VFS3Fscaps              bool
 
 	ContainerCoreScheduling bool
+	ContainerTimeNamespace  bool
 	NativeTerminals         bool
 	PidFds                  bool
 	SeccompListenerAddfd    bool
@@ -0,0 +1,660 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var tombstonesCmd = APIEndpoint{
+	Path: "tombstones",
+
+	Get: APIEndpointAction{Handler: tombstonesGet, AccessHandler: allowAuthenticated},
+}
+
+var tombstoneCmd = APIEndpoint{
+	Path: "tombstones/{id}",
+
+	Get:    APIEndpointAction{Handler: tombstoneGet, AccessHandler: allowAuthenticated},
+	Post:   APIEndpointAction{Handler: tombstonePost, AccessHandler: allowAuthenticated},
+	Delete: APIEndpointAction{Handler: tombstoneDelete, AccessHandler: allowAuthenticated},
+}
+
+// tombstoneEntitlements holds the project entitlements required to act on the tombstones of an entity type.
+type tombstoneEntitlements struct {
+	view    auth.Entitlement
+	restore auth.Entitlement
+	delete  auth.Entitlement
+}
+
+// tombstoneEntityEntitlements maps the entity types that tombstones are recorded for to their entitlements.
+var tombstoneEntityEntitlements = map[entity.Type]tombstoneEntitlements{
+	entity.TypeInstance: {
+		view:    auth.EntitlementCanViewInstances,
+		restore: auth.EntitlementCanCreateInstances,
+		delete:  auth.EntitlementCanDeleteInstances,
+	},
+	entity.TypeProfile: {
+		view:    auth.EntitlementCanViewProfiles,
+		restore: auth.EntitlementCanCreateProfiles,
+		delete:  auth.EntitlementCanDeleteProfiles,
+	},
+	entity.TypeNetwork: {
+		view:    auth.EntitlementCanViewNetworks,
+		restore: auth.EntitlementCanCreateNetworks,
+		delete:  auth.EntitlementCanDeleteNetworks,
+	},
+}
+
+// tombstoneExpiry returns when a tombstone recorded at the given date expires.
+func tombstoneExpiry(s *state.State, date time.Time) (time.Time, error) {
+	return shared.GetExpiry(date, s.GlobalConfig.TombstonesExpiry())
+}
+
+// tombstoneRecord records the definition of a deleted entity so that it can be restored until the tombstone expires.
+// Nothing is recorded if tombstones are disabled. Failing to record a tombstone never fails the deletion that
+// triggered it, so errors are only logged.
+func tombstoneRecord(s *state.State, entityType entity.Type, tombstone api.Tombstone) {
+	now := time.Now()
+
+	expiry, err := tombstoneExpiry(s, now)
+	if err != nil {
+		logger.Warn("Failed parsing tombstones expiry", logger.Ctx{"err": err})
+		return
+	}
+
+	if !expiry.After(now) {
+		return
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := dbCluster.CreateTombstoneFromAPI(ctx, tx.Tx(), dbCluster.EntityType(entityType), tombstone)
+
+		return err
+	})
+	if err != nil {
+		logger.Warn("Failed recording tombstone", logger.Ctx{"entityType": entityType, "project": tombstone.Project, "name": tombstone.Name, "err": err})
+	}
+}
+
+// instanceTombstone returns the definition of an instance as a tombstone.
+func instanceTombstone(inst instance.Instance, requestor *api.EventLifecycleRequestor) api.Tombstone {
+	revision := instanceConfigRevision(inst)
+
+	architecture, _ := osarch.ArchitectureName(inst.Architecture())
+
+	return api.Tombstone{
+		Project:      inst.Project().Name,
+		Name:         inst.Name(),
+		Requestor:    requestor,
+		Type:         inst.Type().String(),
+		Description:  revision.Description,
+		Config:       revision.Config,
+		Devices:      revision.Devices,
+		Profiles:     revision.Profiles,
+		Architecture: architecture,
+		Ephemeral:    inst.IsEphemeral(),
+	}
+}
+
+// profileTombstone returns the definition of a profile as a tombstone.
+func profileTombstone(projectName string, profile api.Profile, requestor *api.EventLifecycleRequestor) api.Tombstone {
+	revision := profileConfigRevision(profile.Writable())
+
+	return api.Tombstone{
+		Project:     projectName,
+		Name:        profile.Name,
+		Requestor:   requestor,
+		Description: revision.Description,
+		Config:      revision.Config,
+		Devices:     revision.Devices,
+	}
+}
+
+// networkTombstone returns the global definition of a network as a tombstone.
+func networkTombstone(s *state.State, n network.Network, requestor *api.EventLifecycleRequestor) api.Tombstone {
+	revision := networkConfigRevision(s, n)
+
+	return api.Tombstone{
+		Project:     n.Project(),
+		Name:        n.Name(),
+		Requestor:   requestor,
+		Type:        n.Type(),
+		Description: revision.Description,
+		Config:      revision.Config,
+	}
+}
+
+// tombstoneLoad returns the requested tombstone if it hasn't expired and the caller is allowed to view it.
+func tombstoneLoad(s *state.State, r *http.Request) (*api.Tombstone, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid tombstone ID")
+	}
+
+	var tombstone *api.Tombstone
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbTombstone, err := dbCluster.GetTombstone(ctx, tx.Tx(), id)
+		if err != nil {
+			return err
+		}
+
+		tombstone, err = dbTombstone.ToAPI()
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tombstone.ExpiresAt, err = tombstoneExpiry(s, tombstone.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tombstone.ExpiresAt.After(time.Now()) {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Tombstone not found")
+	}
+
+	err = tombstoneCheckPermission(s, r, tombstone, func(e tombstoneEntitlements) auth.Entitlement { return e.view })
+	if err != nil {
+		if auth.IsDeniedError(err) {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Tombstone not found")
+		}
+
+		return nil, err
+	}
+
+	return tombstone, nil
+}
+
+// tombstoneCheckPermission checks that the caller has the selected entitlement on the project of the tombstone.
+func tombstoneCheckPermission(s *state.State, r *http.Request, tombstone *api.Tombstone, entitlement func(tombstoneEntitlements) auth.Entitlement) error {
+	entitlements, ok := tombstoneEntityEntitlements[entity.Type(tombstone.EntityType)]
+	if !ok {
+		return fmt.Errorf("Unsupported tombstone entity type %q", tombstone.EntityType)
+	}
+
+	return s.Authorizer.CheckPermission(r.Context(), r, entity.ProjectURL(tombstone.Project), entitlement(entitlements))
+}
+
+// swagger:operation GET /1.0/tombstones tombstones tombstones_get
+//
+//	Get the tombstones
+//
+//	Returns a list of tombstones of recently deleted entities (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: all-projects
+//	    description: Retrieve tombstones from all projects
+//	    type: boolean
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/tombstones/1",
+//	              "/1.0/tombstones/2"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/tombstones?recursion=1 tombstones tombstones_get_recursion1
+//
+//	Get the tombstones
+//
+//	Returns a list of tombstones of recently deleted entities (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: all-projects
+//	    description: Retrieve tombstones from all projects
+//	    type: boolean
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of tombstones
+//	          items:
+//	            $ref: "#/definitions/Tombstone"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func tombstonesGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	allProjects := shared.IsTrue(request.QueryParam(r, "all-projects"))
+	projectName := request.QueryParam(r, "project")
+	if allProjects && projectName != "" {
+		return response.BadRequest(fmt.Errorf("Cannot specify a project when requesting all projects"))
+	} else if !allProjects && projectName == "" {
+		projectName = api.ProjectDefaultName
+	}
+
+	userHasPermission := make(map[entity.Type]auth.PermissionChecker, len(tombstoneEntityEntitlements))
+	for entityType, entitlements := range tombstoneEntityEntitlements {
+		var err error
+
+		userHasPermission[entityType], err = s.Authorizer.GetPermissionChecker(r.Context(), r, entitlements.view, entity.TypeProject)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
+	var dbTombstones []dbCluster.Tombstone
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		filter := dbCluster.TombstoneFilter{}
+		if !allProjects {
+			filter.Project = &projectName
+		}
+
+		dbTombstones, err = dbCluster.GetTombstones(ctx, tx.Tx(), filter)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	now := time.Now()
+	tombstones := make([]api.Tombstone, 0, len(dbTombstones))
+	for _, dbTombstone := range dbTombstones {
+		checker, ok := userHasPermission[entity.Type(dbTombstone.EntityType)]
+		if !ok || !checker(entity.ProjectURL(dbTombstone.Project)) {
+			continue
+		}
+
+		tombstone, err := dbTombstone.ToAPI()
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		tombstone.ExpiresAt, err = tombstoneExpiry(s, tombstone.DeletedAt)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Skip tombstones that expired but haven't been pruned yet.
+		if !tombstone.ExpiresAt.After(now) {
+			continue
+		}
+
+		tombstones = append(tombstones, *tombstone)
+	}
+
+	if !util.IsRecursionRequest(r) {
+		urls := make([]string, 0, len(tombstones))
+		for _, tombstone := range tombstones {
+			urls = append(urls, api.NewURL().Path(version.APIVersion, "tombstones", strconv.FormatInt(tombstone.ID, 10)).String())
+		}
+
+		return response.SyncResponse(true, urls)
+	}
+
+	return response.SyncResponse(true, tombstones)
+}
+
+// swagger:operation GET /1.0/tombstones/{id} tombstones tombstone_get
+//
+//	Get the tombstone
+//
+//	Gets the recorded definition of a recently deleted entity.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Tombstone
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/Tombstone"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func tombstoneGet(d *Daemon, r *http.Request) response.Response {
+	tombstone, err := tombstoneLoad(d.State(), r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, tombstone)
+}
+
+// swagger:operation POST /1.0/tombstones/{id} tombstones tombstone_post
+//
+//	Restore the tombstone
+//
+//	Recreates the deleted entity from its recorded definition.
+//	Only the definition is restored, the data of a deleted instance is not.
+//	Restoring an instance returns a background operation, restoring a profile or network is synchronous.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: tombstone
+//	    description: Restore request
+//	    required: false
+//	    schema:
+//	      $ref: "#/definitions/TombstonePost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func tombstonePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	tombstone, err := tombstoneLoad(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = tombstoneCheckPermission(s, r, tombstone, func(e tombstoneEntitlements) auth.Entitlement { return e.restore })
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.TombstonePost{}
+	if r.ContentLength != 0 {
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
+	if req.Name == "" {
+		req.Name = tombstone.Name
+	}
+
+	// The entity is restored through the regular creation handlers so that it goes through the same validation,
+	// project restrictions and cluster placement as a newly created one.
+	var createReq any
+	var createHandler func(d *Daemon, r *http.Request) response.Response
+
+	switch entity.Type(tombstone.EntityType) {
+	case entity.TypeInstance:
+		// An instance without profiles must not get the default profile applied.
+		profiles := tombstone.Profiles
+		if profiles == nil {
+			profiles = []string{}
+		}
+
+		createReq = api.InstancesPost{
+			Name:   req.Name,
+			Type:   api.InstanceType(tombstone.Type),
+			Source: api.InstanceSource{Type: "none"},
+			InstancePut: api.InstancePut{
+				Architecture: tombstone.Architecture,
+				Config:       tombstone.Config,
+				Devices:      tombstone.Devices,
+				Ephemeral:    tombstone.Ephemeral,
+				Profiles:     profiles,
+				Description:  tombstone.Description,
+			},
+		}
+
+		createHandler = instancesPost
+	case entity.TypeProfile:
+		createReq = api.ProfilesPost{
+			Name: req.Name,
+			ProfilePut: api.ProfilePut{
+				Config:      tombstone.Config,
+				Description: tombstone.Description,
+				Devices:     tombstone.Devices,
+			},
+		}
+
+		createHandler = profilesPost
+	case entity.TypeNetwork:
+		createReq = api.NetworksPost{
+			Name: req.Name,
+			Type: tombstone.Type,
+			NetworkPut: api.NetworkPut{
+				Config:      tombstone.Config,
+				Description: tombstone.Description,
+			},
+		}
+
+		createHandler = networksPost
+	default:
+		return response.InternalError(fmt.Errorf("Unsupported tombstone entity type %q", tombstone.EntityType))
+	}
+
+	buf := bytes.Buffer{}
+	err = json.NewEncoder(&buf).Encode(createReq)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	r.Body = shared.BytesReadCloser{Buf: &buf}
+
+	// The creation handlers take the project from the request URL.
+	query := r.URL.Query()
+	query.Set("project", tombstone.Project)
+	r.URL.RawQuery = query.Encode()
+
+	return createHandler(d, r)
+}
+
+// swagger:operation DELETE /1.0/tombstones/{id} tombstones tombstone_delete
+//
+//	Delete the tombstone
+//
+//	Removes the tombstone, the deleted entity can't be restored anymore.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func tombstoneDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	tombstone, err := tombstoneLoad(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = tombstoneCheckPermission(s, r, tombstone, func(e tombstoneEntitlements) auth.Entitlement { return e.delete })
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.DeleteTombstone(ctx, tx.Tx(), int(tombstone.ID))
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+func pruneExpiredTombstonesTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		opRun := func(op *operations.Operation) error {
+			return pruneExpiredTombstones(ctx, s)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.TombstonesPrune, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating prune expired tombstones operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Debug("Pruning expired tombstones")
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting prune expired tombstones operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed pruning expired tombstones", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Debug("Done pruning expired tombstones")
+	}
+
+	return f, task.Hourly()
+}
+
+func pruneExpiredTombstones(ctx context.Context, s *state.State) error {
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		tombstones, err := dbCluster.GetTombstones(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed to get tombstones: %w", err)
+		}
+
+		now := time.Now()
+		for _, tombstone := range tombstones {
+			expiry, err := tombstoneExpiry(s, tombstone.Date)
+			if err != nil {
+				return err
+			}
+
+			if expiry.After(now) {
+				continue
+			}
+
+			err = dbCluster.DeleteTombstone(ctx, tx.Tx(), tombstone.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to delete expired tombstones: %w", err)
+	}
+
+	return nil
+}
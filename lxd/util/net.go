@@ -157,6 +157,37 @@ func NetworkInterfaceAddress() string {
 	return ""
 }
 
+// NetworkInterfaceAddressFamilies returns whether any network interface of the host has a global unicast IPv4
+// address and whether any has a global unicast IPv6 address.
+func NetworkInterfaceAddressFamilies() (hasIPv4 bool, hasIPv6 bool) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return false, false
+	}
+
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || !ipNet.IP.IsGlobalUnicast() {
+				continue
+			}
+
+			if ipNet.IP.To4() != nil {
+				hasIPv4 = true
+			} else {
+				hasIPv6 = true
+			}
+		}
+	}
+
+	return hasIPv4, hasIPv6
+}
+
 // IsAddressCovered detects if network address1 is actually covered by
 // address2, in the sense that they are either the same address or address2 is
 // specified using a wildcard with the same port of address1.
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"time"
+)
+
+const (
+	// AuthApprovalActionDeleteInstance is the action of deleting an instance.
+	AuthApprovalActionDeleteInstance = "delete-instance"
+
+	// AuthApprovalActionSetPrivileged is the action of setting security.privileged on an instance or profile.
+	AuthApprovalActionSetPrivileged = "set-privileged"
+
+	// AuthApprovalActionAttachRawDisk is the action of adding a disk device passing a host block device through to
+	// an instance or profile.
+	AuthApprovalActionAttachRawDisk = "attach-raw-disk"
+)
+
+const (
+	// AuthApprovalStatusPending is the status of approvals waiting for a decision.
+	AuthApprovalStatusPending = "pending"
+
+	// AuthApprovalStatusApproved is the status of approvals that can be used.
+	AuthApprovalStatusApproved = "approved"
+
+	// AuthApprovalStatusRejected is the status of rejected approvals.
+	AuthApprovalStatusRejected = "rejected"
+
+	// AuthApprovalStatusUsed is the status of approvals of a single entity that were used.
+	AuthApprovalStatusUsed = "used"
+)
+
+// AuthApprovalsPost represents the fields of a request for approval of a privileged action.
+//
+// swagger:model
+//
+// API extension: auth_approvals.
+type AuthApprovalsPost struct {
+	// Action to approve
+	// Example: delete-instance
+	Action string `json:"action" yaml:"action"`
+
+	// URL of the entity the action applies to. Leaving it empty requests a time-bound elevation, allowing the
+	// action on any entity until the approval expires.
+	// Example: /1.0/instances/c1?project=default
+	Entity string `json:"entity" yaml:"entity"`
+
+	// Why the action is needed
+	// Example: Decommissioning the old database server
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// AuthApprovalPost represents the decision about a request for approval.
+//
+// swagger:model
+//
+// API extension: auth_approvals.
+type AuthApprovalPost struct {
+	// New status of the approval (approved or rejected)
+	// Example: approved
+	Status string `json:"status" yaml:"status"`
+}
+
+// AuthApproval represents a request for approval of a privileged action.
+//
+// swagger:model
+//
+// API extension: auth_approvals.
+type AuthApproval struct {
+	AuthApprovalsPost `yaml:",inline"`
+
+	// Approval identifier
+	// Example: 42
+	ID int64 `json:"id" yaml:"id"`
+
+	// Status of the approval (pending, approved, rejected or used)
+	// Example: approved
+	Status string `json:"status" yaml:"status"`
+
+	// Requestor of the approval, who can use it
+	Requestor *EventLifecycleRequestor `json:"requestor" yaml:"requestor"`
+
+	// Identity that approved or rejected the request
+	// Example: jane@example.com
+	Approver string `json:"approver" yaml:"approver"`
+
+	// When the approval was requested
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// When the approval expires
+	// Example: 2021-03-23T18:38:37.753398689-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
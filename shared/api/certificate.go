@@ -63,6 +63,41 @@ type CertificatesPost struct {
 	//
 	// API extension: certificate_token
 	Token bool `json:"token" yaml:"token"`
+
+	// Whether to have the server generate the certificate and key (metrics certificates only)
+	// Example: true
+	//
+	// API extension: certificate_generate_metrics
+	Generate bool `json:"generate" yaml:"generate"`
+
+	// When the generated certificate expires (defaults to one year)
+	// Example: 2027-01-01T00:00:00Z
+	//
+	// API extension: certificate_generate_metrics
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+}
+
+// CertificateGenerated represents a certificate and key pair generated by the server
+//
+// swagger:model
+//
+// API extension: certificate_generate_metrics.
+type CertificateGenerated struct {
+	// The certificate fingerprint
+	// Example: fd200419b271f1dc2a5591b693cc5774b7f234e1ff8c6b78ad703b6888fe2b69
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+
+	// The generated certificate, PEM encoded
+	// Example: X509 PEM certificate
+	Certificate string `json:"certificate" yaml:"certificate"`
+
+	// The private key of the generated certificate, PEM encoded
+	// Example: PEM private key
+	Key string `json:"key" yaml:"key"`
+
+	// When the generated certificate expires
+	// Example: 2027-01-01T00:00:00Z
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
 }
 
 // CertificatePut represents the modifiable fields of a LXD certificate
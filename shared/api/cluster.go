@@ -215,6 +215,50 @@ type ClusterMember struct {
 	//
 	// API extension: clustering_groups
 	Groups []string `json:"groups" yaml:"groups"`
+
+	// Versions of the software components of the cluster member
+	// Example: {"kernel": "6.8.0-35-generic", "lxd": "5.21.1", "lxc": "6.0.0", "qemu": "8.2.2", "criu": "3.17.1"}
+	//
+	// API extension: cluster_version_skew
+	Versions map[string]string `json:"versions,omitempty" yaml:"versions,omitempty"`
+
+	// Evacuation state of the instances of an evacuated cluster member, or of one whose evacuation or restore was interrupted
+	//
+	// API extension: clustering_evacuation_state
+	Evacuation []ClusterMemberEvacuationInstance `json:"evacuation,omitempty" yaml:"evacuation,omitempty"`
+
+	// Patch barriers not yet reached by all cluster members, along with whether this member reached them ("reached") or not ("pending")
+	// Example: {"storage_move_custom_iso_block_volumes_v2": "reached"}
+	//
+	// API extension: clustering_patch_barriers
+	PatchBarriers map[string]string `json:"patch_barriers,omitempty" yaml:"patch_barriers,omitempty"`
+}
+
+// ClusterMemberEvacuationInstance represents the evacuation state of an instance.
+//
+// swagger:model
+//
+// API extension: clustering_evacuation_state.
+type ClusterMemberEvacuationInstance struct {
+	// Project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Name of the instance
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Cluster member the instance is currently on
+	// Example: lxd02
+	Location string `json:"location" yaml:"location"`
+
+	// Evacuation state of the instance (stopped, migrating, migrated, skipped or failed)
+	// Example: migrated
+	State string `json:"state" yaml:"state"`
+
+	// Error of the last failed evacuation or restore of the instance
+	// Example: Failed to stop instance "c1"
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 // Writable converts a full Profile struct into a ProfilePut struct (filters read-only fields).
@@ -295,6 +339,12 @@ type ClusterMemberStatePost struct {
 	//
 	// API extension: clustering_evacuate_mode
 	Mode string `json:"mode" yaml:"mode"`
+
+	// Whether to live-migrate instances to members with known-unsafe version differences
+	// Example: false
+	//
+	// API extension: cluster_version_skew
+	AllowVersionSkew bool `json:"allow_version_skew,omitempty" yaml:"allow_version_skew,omitempty"`
 }
 
 // ClusterGroupsPost represents the fields available for a new cluster group.
@@ -0,0 +1,42 @@
+package api
+
+// ClusterDatabaseIntegrity represents the result of checking the integrity of the global database.
+//
+// swagger:model
+//
+// API extension: cluster_database_integrity.
+type ClusterDatabaseIntegrity struct {
+	// Classes of orphaned rows that were checked
+	Checks []ClusterDatabaseIntegrityCheck `json:"checks" yaml:"checks"`
+}
+
+// ClusterDatabaseIntegrityCheck represents a class of orphaned rows of the global database, which reference
+// entities that don't exist.
+//
+// swagger:model
+//
+// API extension: cluster_database_integrity.
+type ClusterDatabaseIntegrityCheck struct {
+	// Name of the check
+	// Example: config-without-entity
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the orphaned rows
+	// Example: Configuration of entities that don't exist
+	Description string `json:"description" yaml:"description"`
+
+	// Number of orphaned rows found
+	// Example: 3
+	Orphans int64 `json:"orphans" yaml:"orphans"`
+}
+
+// ClusterDatabaseIntegrityPost represents the fields required to clean up orphaned rows of the global database.
+//
+// swagger:model
+//
+// API extension: cluster_database_integrity.
+type ClusterDatabaseIntegrityPost struct {
+	// Names of the checks whose orphaned rows should be deleted
+	// Example: ["config-without-entity", "operations-without-member"]
+	Delete []string `json:"delete" yaml:"delete"`
+}
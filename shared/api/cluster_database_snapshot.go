@@ -0,0 +1,35 @@
+package api
+
+import (
+	"time"
+)
+
+// ClusterDatabaseSnapshot represents a snapshot of the global database shipped to object storage.
+//
+// swagger:model
+//
+// API extension: cluster_database_snapshots.
+type ClusterDatabaseSnapshot struct {
+	// Name of the snapshot, derived from the time it was taken
+	// Example: 20261018T120000Z
+	Name string `json:"name" yaml:"name"`
+
+	// When the snapshot was taken
+	// Example: 2026-10-18T12:00:00Z
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// Size of the compressed snapshot in bytes
+	// Example: 524288
+	Size int64 `json:"size" yaml:"size"`
+}
+
+// ClusterDatabaseSnapshotPost represents the fields required to act on a database snapshot.
+//
+// swagger:model
+//
+// API extension: cluster_database_snapshots.
+type ClusterDatabaseSnapshotPost struct {
+	// Action to perform, only "restore" is supported
+	// Example: restore
+	Action string `json:"action" yaml:"action"`
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"time"
+)
+
+// ClusterMemberLogRecord represents a message of the daemon log stream of a cluster member.
+//
+// swagger:model
+//
+// API extension: cluster_member_log.
+type ClusterMemberLogRecord struct {
+	// Time at which the line was read
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+
+	// Content of the record (a line of the daemon log)
+	// Example: time="2021-03-23T17:38:37-04:00" level=info msg="LXD is starting"
+	Message string `json:"message" yaml:"message"`
+
+	// Error that caused the log to stop streaming
+	// Example: permission denied
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"time"
+)
+
+// ConfigRevision represents a recorded revision of the configuration of an entity.
+//
+// swagger:model
+//
+// API extension: config_history.
+type ConfigRevision struct {
+	// Revision identifier
+	// Example: 42
+	Revision int64 `json:"revision" yaml:"revision"`
+
+	// When the revision was recorded
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// Requestor that caused the revision to be recorded (if known)
+	Requestor *EventLifecycleRequestor `json:"requestor" yaml:"requestor"`
+
+	// Description of the entity at this revision
+	// Example: My profile
+	Description string `json:"description" yaml:"description"`
+
+	// Configuration of the entity at this revision
+	// Example: {"limits.cpu": "4"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Devices of the entity at this revision (instances and profiles only)
+	// Example: {"root": {"type": "disk", "pool": "default", "path": "/"}}
+	Devices map[string]map[string]string `json:"devices,omitempty" yaml:"devices,omitempty"`
+
+	// Profiles applied to the entity at this revision (instances only)
+	// Example: ["default"]
+	Profiles []string `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+}
@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // DevLXDPut represents the modifiable data.
 type DevLXDPut struct {
 	// Instance state
@@ -23,3 +27,37 @@ type DevLXDGet struct {
 	// Example: lxd01
 	Location string `json:"location" yaml:"location"`
 }
+
+// DevLXDCredentials represents the short-lived credentials of an instance.
+//
+// API extension: devlxd_credentials.
+type DevLXDCredentials struct {
+	// Signed token (JWT) asserting the identity of the instance
+	// Example: eyJhbGciOiJFUzM4NCIsImtpZCI6IjJjOGE...
+	Token string `json:"token" yaml:"token"`
+
+	// When the token expires
+	// Example: 2021-03-23T18:38:37.753398689-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+
+	// When new credentials should be requested
+	// Example: 2021-03-23T18:08:37.753398689-04:00
+	RenewAt time.Time `json:"renew_at" yaml:"renew_at"`
+
+	// Certificate (PEM) whose key signed the token
+	// Example: X509 PEM certificate
+	Certificate string `json:"certificate" yaml:"certificate"`
+}
+
+// DevLXDDiskEncryptionKeyPost represents a recovery key of an encrypted guest block device escrowed by an instance.
+//
+// API extension: instances_disk_encryption.
+type DevLXDDiskEncryptionKeyPost struct {
+	// Name of the encrypted block device in the guest
+	// Example: vda3
+	Device string `json:"device" yaml:"device"`
+
+	// Recovery key (or passphrase) that unlocks the device
+	// Example: 123456-234567-345678-456789-567890-678901-789012-890123
+	RecoveryKey string `json:"recovery_key" yaml:"recovery_key"`
+}
@@ -14,10 +14,24 @@ func StatusErrorf(status int, format string, a ...any) StatusError {
 	}
 }
 
+// ReasonErrorf returns a new StatusError containing the specified reason, metadata and message.
+// The HTTP status code is the one registered for the reason.
+func ReasonErrorf(reason ErrorReason, metadata map[string]any, format string, a ...any) StatusError {
+	return StatusError{
+		status:   reason.Status(),
+		reason:   reason,
+		metadata: metadata,
+		err:      fmt.Errorf(format, a...),
+	}
+}
+
 // StatusError error type that contains an HTTP status code and message.
+// It can optionally contain a machine-readable reason and metadata describing the cause of the error.
 type StatusError struct {
-	status int
-	err    error
+	status   int
+	reason   ErrorReason
+	metadata map[string]any
+	err      error
 }
 
 // Error returns the error message or the http.StatusText() of the status code if error message is empty.
@@ -39,6 +53,30 @@ func (e StatusError) Status() int {
 	return e.status
 }
 
+// Reason returns the machine-readable reason of the error.
+// If no reason was set, the generic reason of the HTTP status code is returned.
+func (e StatusError) Reason() ErrorReason {
+	if e.reason != "" {
+		return e.reason
+	}
+
+	return ErrorReasonFromStatus(e.status)
+}
+
+// Metadata returns the metadata describing the cause of the error, if any.
+func (e StatusError) Metadata() map[string]any {
+	return e.metadata
+}
+
+// WithReason returns a copy of the StatusError with the specified reason and metadata.
+// The HTTP status code is left unchanged.
+func (e StatusError) WithReason(reason ErrorReason, metadata map[string]any) StatusError {
+	e.reason = reason
+	e.metadata = metadata
+
+	return e
+}
+
 // StatusErrorMatch checks if err was caused by StatusError. Can optionally also check whether the StatusError's
 // status code matches one of the supplied status codes in matchStatus.
 // Returns the matched StatusError status code and true if match criteria are met, otherwise false.
@@ -68,3 +106,33 @@ func StatusErrorCheck(err error, matchStatusCodes ...int) bool {
 	_, found := StatusErrorMatch(err, matchStatusCodes...)
 	return found
 }
+
+// ErrorReasonMatch checks if err was caused by StatusError. Can optionally also check whether the StatusError's
+// reason matches one of the supplied reasons in matchReasons.
+// Returns the matched StatusError reason and true if match criteria are met, otherwise false.
+func ErrorReasonMatch(err error, matchReasons ...ErrorReason) (ErrorReason, bool) {
+	var statusErr StatusError
+
+	if errors.As(err, &statusErr) {
+		reason := statusErr.Reason()
+
+		if len(matchReasons) <= 0 {
+			return reason, true
+		}
+
+		for _, r := range matchReasons {
+			if reason == r {
+				return reason, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// ErrorReasonCheck returns whether or not err was caused by a StatusError and if it matches one of the
+// optional reasons.
+func ErrorReasonCheck(err error, matchReasons ...ErrorReason) bool {
+	_, found := ErrorReasonMatch(err, matchReasons...)
+	return found
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http"
+)
+
+// ErrorReason is a machine-readable identifier of the cause of an API error.
+// Unlike the error message, it is guaranteed never to change and can be relied on by API clients.
+type ErrorReason string
+
+// Generic error reasons, used when no more specific reason applies.
+const (
+	ErrorReasonBadRequest         ErrorReason = "bad_request"
+	ErrorReasonUnauthorized       ErrorReason = "unauthorized"
+	ErrorReasonForbidden          ErrorReason = "forbidden"
+	ErrorReasonNotFound           ErrorReason = "not_found"
+	ErrorReasonConflict           ErrorReason = "conflict"
+	ErrorReasonPreconditionFailed ErrorReason = "precondition_failed"
+	ErrorReasonInternal           ErrorReason = "internal"
+	ErrorReasonNotImplemented     ErrorReason = "not_implemented"
+	ErrorReasonUnavailable        ErrorReason = "unavailable"
+)
+
+// Specific error reasons.
+const (
+	// ErrorReasonAlreadyExists indicates that an entity with the same name already exists.
+	ErrorReasonAlreadyExists ErrorReason = "already_exists"
+
+	// ErrorReasonAmbiguousTarget indicates that the entity exists on more than one cluster member and a target
+	// must be specified.
+	ErrorReasonAmbiguousTarget ErrorReason = "ambiguous_target"
+
+	// ErrorReasonClusterMemberOffline indicates that the cluster member needed to handle the request is offline.
+	// The metadata contains the name of the member in "member" when known.
+	ErrorReasonClusterMemberOffline ErrorReason = "cluster_member_offline"
+
+	// ErrorReasonProjectLimitReached indicates that the request would exceed a project limit.
+	// The metadata contains the name of the project in "project", the limit config key in "key" and its value
+	// in "limit".
+	ErrorReasonProjectLimitReached ErrorReason = "project_limit_reached"
+
+	// ErrorReasonProjectRestricted indicates that the request isn't allowed by the project restrictions.
+	// The metadata contains the name of the project in "project".
+	ErrorReasonProjectRestricted ErrorReason = "project_restricted"
+)
+
+// ErrorReasonStatuses associates each error reason to the HTTP status code it is returned with.
+var ErrorReasonStatuses = map[ErrorReason]int{
+	ErrorReasonBadRequest:         http.StatusBadRequest,
+	ErrorReasonUnauthorized:       http.StatusUnauthorized,
+	ErrorReasonForbidden:          http.StatusForbidden,
+	ErrorReasonNotFound:           http.StatusNotFound,
+	ErrorReasonConflict:           http.StatusConflict,
+	ErrorReasonPreconditionFailed: http.StatusPreconditionFailed,
+	ErrorReasonInternal:           http.StatusInternalServerError,
+	ErrorReasonNotImplemented:     http.StatusNotImplemented,
+	ErrorReasonUnavailable:        http.StatusServiceUnavailable,
+
+	ErrorReasonAlreadyExists:        http.StatusConflict,
+	ErrorReasonAmbiguousTarget:      http.StatusConflict,
+	ErrorReasonClusterMemberOffline: http.StatusServiceUnavailable,
+	ErrorReasonProjectLimitReached:  http.StatusForbidden,
+	ErrorReasonProjectRestricted:    http.StatusForbidden,
+}
+
+// Status returns the HTTP status code registered for the error reason.
+func (r ErrorReason) Status() int {
+	status, ok := ErrorReasonStatuses[r]
+	if !ok {
+		return http.StatusInternalServerError
+	}
+
+	return status
+}
+
+// ErrorReasonFromStatus returns the generic error reason of the HTTP status code.
+func ErrorReasonFromStatus(status int) ErrorReason {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorReasonBadRequest
+	case http.StatusUnauthorized:
+		return ErrorReasonUnauthorized
+	case http.StatusForbidden:
+		return ErrorReasonForbidden
+	case http.StatusNotFound:
+		return ErrorReasonNotFound
+	case http.StatusConflict:
+		return ErrorReasonConflict
+	case http.StatusPreconditionFailed:
+		return ErrorReasonPreconditionFailed
+	case http.StatusNotImplemented:
+		return ErrorReasonNotImplemented
+	case http.StatusServiceUnavailable:
+		return ErrorReasonUnavailable
+	}
+
+	return ErrorReasonInternal
+}
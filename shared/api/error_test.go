@@ -0,0 +1,50 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReasonErrorf(t *testing.T) {
+	err := ReasonErrorf(ErrorReasonProjectLimitReached, map[string]any{"project": "foo"}, "Reached maximum number of instances in project %q", "foo")
+
+	assert.Equal(t, http.StatusForbidden, err.Status())
+	assert.Equal(t, ErrorReasonProjectLimitReached, err.Reason())
+	assert.Equal(t, map[string]any{"project": "foo"}, err.Metadata())
+	assert.Equal(t, `Reached maximum number of instances in project "foo"`, err.Error())
+}
+
+func TestStatusErrorReason(t *testing.T) {
+	// Errors without an explicit reason get the generic reason of their status code.
+	err := StatusErrorf(http.StatusNotFound, "Instance not found")
+	assert.Equal(t, ErrorReasonNotFound, err.Reason())
+	assert.Nil(t, err.Metadata())
+
+	// Setting a reason keeps the status code.
+	err = err.WithReason(ErrorReasonClusterMemberOffline, nil)
+	assert.Equal(t, http.StatusNotFound, err.Status())
+	assert.Equal(t, ErrorReasonClusterMemberOffline, err.Reason())
+}
+
+func TestErrorReasonMatch(t *testing.T) {
+	err := fmt.Errorf("Failed creating instance: %w", ReasonErrorf(ErrorReasonAlreadyExists, nil, "Instance already exists"))
+
+	reason, found := ErrorReasonMatch(err)
+	assert.True(t, found)
+	assert.Equal(t, ErrorReasonAlreadyExists, reason)
+
+	assert.True(t, ErrorReasonCheck(err, ErrorReasonNotFound, ErrorReasonAlreadyExists))
+	assert.False(t, ErrorReasonCheck(err, ErrorReasonNotFound))
+	assert.False(t, ErrorReasonCheck(fmt.Errorf("Plain error")))
+}
+
+func TestErrorReasonStatus(t *testing.T) {
+	for reason, status := range ErrorReasonStatuses {
+		assert.Equal(t, status, reason.Status())
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, ErrorReason("unknown").Status())
+}
@@ -12,6 +12,9 @@ const (
 	EventTypeLogging   = "logging"
 	EventTypeOperation = "operation"
 	EventTypeOVN       = "ovn"
+
+	// API extension: instance_journal_forwarding.
+	EventTypeInstanceLog = "instance-log"
 )
 
 // Event represents an event entry (over websocket)
@@ -117,6 +120,27 @@ func (event *Event) ToLogging() (EventLogRecord, error) {
 			},
 		}
 
+		return record, nil
+	} else if event.Type == EventTypeInstanceLog {
+		e := &EventInstanceLog{}
+		err := json.Unmarshal(event.Metadata, &e)
+		if err != nil {
+			return EventLogRecord{}, err
+		}
+
+		ctx := []any{"instance", e.Instance, "source", e.Source}
+		for k, v := range e.Fields {
+			ctx = append(ctx, k)
+			ctx = append(ctx, v)
+		}
+
+		record := EventLogRecord{
+			Time: e.Timestamp,
+			Lvl:  "info",
+			Msg:  e.Message,
+			Ctx:  ctx,
+		}
+
 		return record, nil
 	}
 
@@ -138,6 +162,17 @@ type EventLogging struct {
 	Context map[string]string `yaml:"context" json:"context"`
 }
 
+// EventInstanceLog represents a log record of an instance forwarded to the event stream.
+//
+// API extension: instance_journal_forwarding.
+type EventInstanceLog struct {
+	InstanceLogRecord `yaml:",inline"`
+
+	// Name of the instance
+	// Example: v1
+	Instance string `yaml:"instance" json:"instance"`
+}
+
 // EventLifecycle represets a lifecycle type event entry
 //
 // API extension: event_lifecycle.
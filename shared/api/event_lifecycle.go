@@ -50,6 +50,11 @@ const (
 	EventLifecycleInstanceMetadataUpdated           = "instance-metadata-updated"
 	EventLifecycleInstancePaused                    = "instance-paused"
 	EventLifecycleInstanceReady                     = "instance-ready"
+	EventLifecycleInstancePoolCreated               = "instance-pool-created"
+	EventLifecycleInstancePoolDeleted               = "instance-pool-deleted"
+	EventLifecycleInstancePoolInstanceAcquired      = "instance-pool-instance-acquired"
+	EventLifecycleInstancePoolInstanceReleased      = "instance-pool-instance-released"
+	EventLifecycleInstancePoolUpdated               = "instance-pool-updated"
 	EventLifecycleInstanceRenamed                   = "instance-renamed"
 	EventLifecycleInstanceRestarted                 = "instance-restarted"
 	EventLifecycleInstanceRestored                  = "instance-restored"
@@ -61,11 +66,15 @@ const (
 	EventLifecycleInstanceSnapshotUpdated           = "instance-snapshot-updated"
 	EventLifecycleInstanceStarted                   = "instance-started"
 	EventLifecycleInstanceStopped                   = "instance-stopped"
+	EventLifecycleInstanceSyscallIntercepted        = "instance-syscall-intercepted"
 	EventLifecycleInstanceUpdated                   = "instance-updated"
 	EventLifecycleNetworkACLCreated                 = "network-acl-created"
 	EventLifecycleNetworkACLDeleted                 = "network-acl-deleted"
 	EventLifecycleNetworkACLRenamed                 = "network-acl-renamed"
 	EventLifecycleNetworkACLUpdated                 = "network-acl-updated"
+	EventLifecycleNetworkAddressPoolCreated         = "network-address-pool-created"
+	EventLifecycleNetworkAddressPoolDeleted         = "network-address-pool-deleted"
+	EventLifecycleNetworkAddressPoolUpdated         = "network-address-pool-updated"
 	EventLifecycleNetworkCreated                    = "network-created"
 	EventLifecycleNetworkDeleted                    = "network-deleted"
 	EventLifecycleNetworkForwardCreated             = "network-forward-created"
@@ -94,6 +103,12 @@ const (
 	EventLifecycleProjectDeleted                    = "project-deleted"
 	EventLifecycleProjectRenamed                    = "project-renamed"
 	EventLifecycleProjectUpdated                    = "project-updated"
+	EventLifecycleSeccompPolicyCreated              = "seccomp-policy-created"
+	EventLifecycleSeccompPolicyDeleted              = "seccomp-policy-deleted"
+	EventLifecycleSeccompPolicyUpdated              = "seccomp-policy-updated"
+	EventLifecycleSSHKeyCreated                     = "ssh-key-created"
+	EventLifecycleSSHKeyDeleted                     = "ssh-key-deleted"
+	EventLifecycleSSHKeyUpdated                     = "ssh-key-updated"
 	EventLifecycleStoragePoolCreated                = "storage-pool-created"
 	EventLifecycleStoragePoolDeleted                = "storage-pool-deleted"
 	EventLifecycleStoragePoolUpdated                = "storage-pool-updated"
@@ -121,10 +136,16 @@ const (
 	EventLifecycleWarningReset                      = "warning-reset"
 	EventLifecycleIdentityCreated                   = "identity-created"
 	EventLifecycleIdentityUpdated                   = "identity-updated"
+	EventLifecycleIdentityDeleted                   = "identity-deleted"
 	EventLifecycleAuthGroupCreated                  = "auth-group-created"
 	EventLifecycleAuthGroupUpdated                  = "auth-group-updated"
 	EventLifecycleAuthGroupRenamed                  = "auth-group-renamed"
 	EventLifecycleAuthGroupDeleted                  = "auth-group-deleted"
+	EventLifecycleAuthApprovalCreated               = "auth-approval-created"
+	EventLifecycleAuthApprovalApproved              = "auth-approval-approved"
+	EventLifecycleAuthApprovalRejected              = "auth-approval-rejected"
+	EventLifecycleAuthApprovalUsed                  = "auth-approval-used"
+	EventLifecycleAuthApprovalDeleted               = "auth-approval-deleted"
 	EventLifecycleIdentityProviderGroupCreated      = "identity-provider-group-created"
 	EventLifecycleIdentityProviderGroupUpdated      = "identity-provider-group-updated"
 	EventLifecycleIdentityProviderGroupRenamed      = "identity-provider-group-renamed"
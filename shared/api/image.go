@@ -57,6 +57,19 @@ type ImagesPost struct {
 	// API extension: image_compression_algorithm
 	CompressionAlgorithm string `json:"compression_algorithm" yaml:"compression_algorithm"`
 
+	// Compression level to use when turning an instance into an image (the default of the algorithm if zero)
+	// Example: 19
+	//
+	// API extension: image_publish_reproducible
+	CompressionLevel int `json:"compression_level" yaml:"compression_level"`
+
+	// Whether to generate the image deterministically when turning an instance into an image, so that the same
+	// instance content always results in the same fingerprint
+	// Example: true
+	//
+	// API extension: image_publish_reproducible
+	Reproducible bool `json:"reproducible" yaml:"reproducible"`
+
 	// Aliases to add to the image
 	// Example: [{"name": "foo"}, {"name": "bar"}]
 	//
@@ -282,6 +295,18 @@ type ImageAliasesEntryPost struct {
 	// Alias name
 	// Example: ubuntu-24.04
 	Name string `json:"name" yaml:"name"`
+
+	// Channel whose target becomes the target of the alias (instead of renaming it)
+	// Example: candidate
+	//
+	// API extension: image_alias_channels
+	Promote string `json:"promote,omitempty" yaml:"promote,omitempty"`
+
+	// Whether to restore the previous target of the alias (instead of renaming it)
+	// Example: false
+	//
+	// API extension: image_alias_channels
+	Rollback bool `json:"rollback,omitempty" yaml:"rollback,omitempty"`
 }
 
 // ImageAliasesEntryPut represents the modifiable fields of a LXD image alias
@@ -295,6 +320,12 @@ type ImageAliasesEntryPut struct {
 	// Target fingerprint for the alias
 	// Example: 06b86454720d36b20f94e31c6812e05ec51c1b568cf3a8abd273769d213394bb
 	Target string `json:"target" yaml:"target"`
+
+	// Target fingerprints of the alias channels
+	// Example: {"candidate": "06b86454720d36b20f94e31c6812e05ec51c1b568cf3a8abd273769d213394bb"}
+	//
+	// API extension: image_alias_channels
+	Channels map[string]string `json:"channels" yaml:"channels"`
 }
 
 // ImageAliasesEntry represents a LXD image alias
@@ -318,6 +349,18 @@ type ImageAliasesEntry struct {
 	// Target fingerprint for the alias
 	// Example: 06b86454720d36b20f94e31c6812e05ec51c1b568cf3a8abd273769d213394bb
 	Target string `json:"target" yaml:"target"`
+
+	// Target fingerprints of the alias channels
+	// Example: {"candidate": "06b86454720d36b20f94e31c6812e05ec51c1b568cf3a8abd273769d213394bb"}
+	//
+	// API extension: image_alias_channels
+	Channels map[string]string `json:"channels,omitempty" yaml:"channels,omitempty"`
+
+	// Target fingerprint the alias had before its target last changed, which a rollback restores
+	// Example: 84a71299044bc3c3563396bef153c0da83d494f6bf3d38fecc55d776b1e19bf9
+	//
+	// API extension: image_alias_channels
+	PreviousTarget string `json:"previous_target,omitempty" yaml:"previous_target,omitempty"`
 }
 
 // ImageMetadata represents LXD image metadata (used in image tarball)
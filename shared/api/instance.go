@@ -49,6 +49,91 @@ type InstancesPost struct {
 	// Type (container or virtual-machine)
 	// Example: container
 	Type InstanceType `json:"type" yaml:"type"`
+
+	// Resources to create along with the instance, which are deleted again if the creation fails
+	//
+	// API extension: instances_create_resources
+	Resources *InstancesPostResources `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
+// InstancesPostResources represents the resources created along with a new instance.
+//
+// swagger:model
+//
+// API extension: instances_create_resources.
+type InstancesPostResources struct {
+	// New custom storage volumes to attach to the instance
+	Volumes []InstancesPostVolume `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+
+	// Network forwards to the instance
+	NetworkForwards []InstancesPostNetworkForward `json:"network_forwards,omitempty" yaml:"network_forwards,omitempty"`
+
+	// Network zone records for the instance
+	NetworkZoneRecords []InstancesPostNetworkZoneRecord `json:"network_zone_records,omitempty" yaml:"network_zone_records,omitempty"`
+}
+
+// InstancesPostVolume represents a new custom storage volume attached to a new instance.
+//
+// swagger:model
+//
+// API extension: instances_create_resources.
+type InstancesPostVolume struct {
+	// Storage pool of the volume
+	// Example: default
+	Pool string `json:"pool" yaml:"pool"`
+
+	// Volume name
+	// Example: data
+	Name string `json:"name" yaml:"name"`
+
+	// Volume content type (filesystem or block)
+	// Example: filesystem
+	ContentType string `json:"content_type" yaml:"content_type"`
+
+	// Volume configuration
+	// Example: {"size": "10GiB"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Description of the volume
+	// Example: Data volume
+	Description string `json:"description" yaml:"description"`
+
+	// Name of the disk device of the instance (defaults to the volume name)
+	// Example: data
+	Device string `json:"device" yaml:"device"`
+
+	// Mount path of filesystem volumes in the instance
+	// Example: /data
+	Path string `json:"path" yaml:"path"`
+}
+
+// InstancesPostNetworkForward represents a network forward to a new instance.
+// Forwarded ports without a target address, and the forward itself if it has neither ports nor a default
+// target address, target the static address of the instance NIC connected to the network.
+//
+// swagger:model
+//
+// API extension: instances_create_resources.
+type InstancesPostNetworkForward struct {
+	NetworkForwardsPost `yaml:",inline"`
+
+	// Network of the forward
+	// Example: lxdbr0
+	Network string `json:"network" yaml:"network"`
+}
+
+// InstancesPostNetworkZoneRecord represents a network zone record for a new instance.
+// A record without entries gets A and AAAA entries for the static addresses of the instance NICs.
+//
+// swagger:model
+//
+// API extension: instances_create_resources.
+type InstancesPostNetworkZoneRecord struct {
+	NetworkZoneRecordsPost `yaml:",inline"`
+
+	// Network zone of the record
+	// Example: example.net
+	Zone string `json:"zone" yaml:"zone"`
 }
 
 // InstancesPut represents the fields available for a mass update.
@@ -108,6 +193,29 @@ type InstancePost struct {
 	// API extension: instance_allow_inconsistent_copy
 	AllowInconsistent bool `json:"allow_inconsistent" yaml:"allow_inconsistent"`
 
+	// Compression algorithm to use for the migration transfer (overrides migration.compression_algorithm)
+	// Example: zstd -3
+	//
+	// API extension: migration_compression
+	CompressionAlgorithm string `json:"compression_algorithm,omitempty" yaml:"compression_algorithm,omitempty"`
+
+	// Whether to verify the checksums of transferred block volumes on the target (overrides migration.verify_checksums)
+	// Example: true
+	//
+	// API extension: migration_verify_checksums
+	VerifyChecksums bool `json:"verify_checksums,omitempty" yaml:"verify_checksums,omitempty"`
+
+	// Whether to live-migrate to a cluster member with known-unsafe version differences
+	// Example: false
+	//
+	// API extension: cluster_version_skew
+	AllowVersionSkew bool `json:"allow_version_skew,omitempty" yaml:"allow_version_skew,omitempty"`
+
+	// Remote server to move the instance to (migration only)
+	//
+	// API extension: instance_adopt
+	Remote *InstancePostRemote `json:"remote,omitempty" yaml:"remote,omitempty"`
+
 	// Instance configuration file.
 	// Example: {"security.nesting": "true"}
 	//
@@ -146,6 +254,29 @@ type InstancePostTarget struct {
 	Websockets map[string]string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
 }
 
+// InstancePostRemote represents a remote server to move an instance to.
+//
+// swagger:model
+//
+// API extension: instance_adopt.
+type InstancePostRemote struct {
+	// URL of the remote server
+	// Example: https://1.2.3.4:8443
+	Server string `json:"server" yaml:"server"`
+
+	// The certificate of the remote server
+	// Example: X509 PEM certificate
+	Certificate string `json:"certificate" yaml:"certificate"`
+
+	// Project on the remote server
+	// Example: default
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+
+	// Trust token issued by the remote server, used to add this server to its trust store
+	// Example: eyJjbGllbnRfbmFtZSI6IaIsImZpbmdlcnByaW50Ijoi...
+	TrustToken string `json:"trust_token,omitempty" yaml:"trust_token,omitempty"`
+}
+
 // InstancePut represents the modifiable fields of a LXD instance.
 //
 // swagger:model
@@ -418,6 +549,12 @@ type InstanceSource struct {
 	//
 	// API extension: instance_allow_inconsistent_copy
 	AllowInconsistent bool `json:"allow_inconsistent" yaml:"allow_inconsistent"`
+
+	// Trust token issued by the remote server, used to add this server to its trust store (for adopt)
+	// Example: eyJjbGllbnRfbmFtZSI6IaIsImZpbmdlcnByaW50Ijoi...
+	//
+	// API extension: instance_adopt
+	TrustToken string `json:"trust_token,omitempty" yaml:"trust_token,omitempty"`
 }
 
 // InstanceUEFIVars represents the UEFI variables of a LXD virtual machine.
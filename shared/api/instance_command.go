@@ -0,0 +1,103 @@
+package api
+
+import (
+	"time"
+)
+
+const (
+	// InstanceCommandTriggerBoot runs the command at the next start of the instance.
+	InstanceCommandTriggerBoot = "boot"
+
+	// InstanceCommandTriggerSchedule runs the command once the scheduled time is reached and the instance is running.
+	InstanceCommandTriggerSchedule = "schedule"
+)
+
+const (
+	// InstanceCommandStatusPending indicates that the command hasn't run yet.
+	InstanceCommandStatusPending = "pending"
+
+	// InstanceCommandStatusRunning indicates that the command is running.
+	InstanceCommandStatusRunning = "running"
+
+	// InstanceCommandStatusDone indicates that the command has run, regardless of its exit code.
+	InstanceCommandStatusDone = "done"
+
+	// InstanceCommandStatusFailed indicates that the command couldn't be run.
+	InstanceCommandStatusFailed = "failed"
+)
+
+// InstanceCommandsPost represents a command queued to run in an instance.
+//
+// swagger:model
+//
+// API extension: instance_commands.
+type InstanceCommandsPost struct {
+	// Command and its arguments
+	// Example: ["apt-get", "upgrade", "-y"]
+	Command []string `json:"command" yaml:"command"`
+
+	// Additional environment to pass to the command
+	// Example: {"DEBIAN_FRONTEND": "noninteractive"}
+	Environment map[string]string `json:"environment" yaml:"environment"`
+
+	// UID of the user to run the command as
+	// Example: 0
+	User uint32 `json:"user" yaml:"user"`
+
+	// GID of the user to run the command as
+	// Example: 0
+	Group uint32 `json:"group" yaml:"group"`
+
+	// Current working directory for the command
+	// Example: /root
+	Cwd string `json:"cwd" yaml:"cwd"`
+
+	// When to run the command (boot or schedule)
+	// Example: boot
+	Trigger string `json:"trigger" yaml:"trigger"`
+
+	// When to run the command if triggered by schedule
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	RunAt time.Time `json:"run_at" yaml:"run_at"`
+}
+
+// InstanceCommand represents a command queued to run in an instance and its result.
+//
+// swagger:model
+//
+// API extension: instance_commands.
+type InstanceCommand struct {
+	InstanceCommandsPost `yaml:",inline"`
+
+	// Command ID
+	// Example: 3c9bd4a4-8d7c-4a3c-9d79-1f0c1b0e2cd8
+	ID string `json:"id" yaml:"id"`
+
+	// Command status (pending, running, done or failed)
+	// Example: done
+	Status string `json:"status" yaml:"status"`
+
+	// When the command was queued
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// When the command started running
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	StartedAt time.Time `json:"started_at" yaml:"started_at"`
+
+	// When the command stopped running
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	FinishedAt time.Time `json:"finished_at" yaml:"finished_at"`
+
+	// Exit code of the command
+	// Example: 0
+	ExitCode int `json:"exit_code" yaml:"exit_code"`
+
+	// Why the command couldn't be run
+	// Example: Instance agent isn't started
+	Error string `json:"error" yaml:"error"`
+
+	// URLs of the recorded standard output ("1") and standard error ("2") of the command
+	// Example: {"1": "/1.0/instances/c1/logs/exec-output/exec_3c9bd4a4-8d7c-4a3c-9d79-1f0c1b0e2cd8.stdout"}
+	Output map[string]string `json:"output" yaml:"output"`
+}
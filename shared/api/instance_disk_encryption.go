@@ -0,0 +1,24 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceDiskEncryptionKey represents a recovery key escrowed by an instance for one of its encrypted block devices.
+//
+// swagger:model
+//
+// API extension: instances_disk_encryption.
+type InstanceDiskEncryptionKey struct {
+	// Name of the encrypted block device in the guest
+	// Example: vda3
+	Device string `json:"device" yaml:"device"`
+
+	// Recovery key (or passphrase) that unlocks the device
+	// Example: 123456-234567-345678-456789-567890-678901-789012-890123
+	RecoveryKey string `json:"recovery_key" yaml:"recovery_key"`
+
+	// When the key was escrowed
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
@@ -0,0 +1,46 @@
+package api
+
+// InstanceFirewall represents the host firewall rules applied for the devices of an instance.
+//
+// swagger:model
+//
+// API extension: instance_firewall_rules.
+type InstanceFirewall struct {
+	// Firewall driver in use on the cluster member running the instance
+	// Example: nftables
+	Driver string `json:"driver" yaml:"driver"`
+
+	// Rules applied for the devices of the instance
+	Rules []InstanceFirewallRule `json:"rules" yaml:"rules"`
+}
+
+// InstanceFirewallRule represents a host firewall rule applied for an instance device along with its counters.
+//
+// swagger:model
+//
+// API extension: instance_firewall_rules.
+type InstanceFirewallRule struct {
+	// Name of the instance device the rule was applied for
+	// Example: eth0
+	Device string `json:"device" yaml:"device"`
+
+	// Table the rule belongs to
+	// Example: bridge lxd
+	Table string `json:"table" yaml:"table"`
+
+	// Chain the rule belongs to
+	// Example: in.c1.eth0
+	Chain string `json:"chain" yaml:"chain"`
+
+	// Rule as listed by the firewall
+	// Example: iifname "veth1234" ether type ip ip saddr @ipv4.c1.eth0 accept
+	Rule string `json:"rule" yaml:"rule"`
+
+	// Number of packets matched by the rule
+	// Example: 1024
+	Packets uint64 `json:"packets" yaml:"packets"`
+
+	// Number of bytes matched by the rule
+	// Example: 524288
+	Bytes uint64 `json:"bytes" yaml:"bytes"`
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceLease represents the lease of an ephemeral instance that has a TTL.
+//
+// swagger:model
+//
+// API extension: instance_ephemeral_ttl.
+type InstanceLease struct {
+	// How long the instance can be inactive before it is deleted
+	// Example: 2H
+	TTL string `json:"ttl" yaml:"ttl"`
+
+	// Last activity of the instance
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	LastActivity time.Time `json:"last_activity" yaml:"last_activity"`
+
+	// When the instance is deleted unless there is new activity
+	// Example: 2021-03-23T19:38:37.753398689-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
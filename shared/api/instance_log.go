@@ -0,0 +1,47 @@
+package api
+
+import (
+	"time"
+)
+
+// Sources of the records of the instance log stream.
+const (
+	// InstanceLogSourceConsole is the console of the instance (containers only).
+	InstanceLogSourceConsole = "console"
+
+	// InstanceLogSourceLXC is the liblxc log of the instance (containers only).
+	InstanceLogSourceLXC = "lxc.log"
+
+	// InstanceLogSourceQEMU is the QEMU log of the instance (virtual machines only).
+	InstanceLogSourceQEMU = "qemu.log"
+
+	// InstanceLogSourceJournal is the systemd journal of the guest, forwarded by the lxd-agent (virtual machines only).
+	InstanceLogSourceJournal = "journal"
+)
+
+// InstanceLogRecord represents a message of the instance log stream.
+//
+// swagger:model
+//
+// API extension: instance_logs_stream.
+type InstanceLogRecord struct {
+	// Source of the record (console, lxc.log, qemu.log or journal)
+	// Example: journal
+	Source string `json:"source" yaml:"source"`
+
+	// Time of the record as reported by the source, or the time at which it was read
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+
+	// Content of the record (a line of the source)
+	// Example: Started Daily apt download activities.
+	Message string `json:"message" yaml:"message"`
+
+	// Additional fields provided by the source (journal only)
+	// Example: {"_SYSTEMD_UNIT": "apt-daily.service", "PRIORITY": "6"}
+	Fields map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	// Error that caused the source to stop streaming records
+	// Example: Instance is not running
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
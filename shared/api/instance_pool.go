@@ -0,0 +1,93 @@
+package api
+
+// InstancePoolsPost represents the fields of a new LXD instance pool
+//
+// swagger:model
+//
+// API extension: instance_pools.
+type InstancePoolsPost struct {
+	InstancePoolPut `yaml:",inline"`
+
+	// The name of the instance pool
+	// Example: runners
+	Name string `json:"name" yaml:"name"`
+}
+
+// InstancePoolPut represents the modifiable fields of a LXD instance pool
+//
+// swagger:model
+//
+// API extension: instance_pools.
+type InstancePoolPut struct {
+	// Description of the instance pool
+	// Example: CI runners
+	Description string `json:"description" yaml:"description"`
+
+	// Instance pool configuration map (refer to doc/howto/instances_pools.md)
+	// Example: {"source": "runner-template", "size": "5"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// InstancePool represents a set of pre-created standby instances that can be handed out on request.
+//
+// swagger:model
+//
+// API extension: instance_pools.
+type InstancePool struct {
+	// The name of the instance pool
+	// Example: runners
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the instance pool
+	// Example: CI runners
+	Description string `json:"description" yaml:"description"`
+
+	// Instance pool configuration map (refer to doc/howto/instances_pools.md)
+	// Example: {"source": "runner-template", "size": "5"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// List of URLs of instances of this pool
+	// Read only: true
+	// Example: ["/1.0/instances/runners-f3a2c1"]
+	UsedBy []string `json:"used_by" yaml:"used_by"` // Instances that belong to the pool.
+}
+
+// Writable converts a full InstancePool struct into a InstancePoolPut struct (filters read-only fields).
+func (pool *InstancePool) Writable() InstancePoolPut {
+	return InstancePoolPut{
+		Description: pool.Description,
+		Config:      pool.Config,
+	}
+}
+
+// SetWritable sets applicable values from InstancePoolPut struct to InstancePool struct.
+func (pool *InstancePool) SetWritable(put InstancePoolPut) {
+	pool.Description = put.Description
+	pool.Config = put.Config
+}
+
+// InstancePoolInstance represents an instance that belongs to an instance pool.
+//
+// swagger:model
+//
+// API extension: instance_pools.
+type InstancePoolInstance struct {
+	// Name of the instance
+	// Example: runners-f3a2c1
+	Name string `json:"name" yaml:"name"`
+
+	// Whether the instance has been handed out
+	// Example: true
+	Acquired bool `json:"acquired" yaml:"acquired"`
+}
+
+// InstancePoolReleasePost represents the fields required to release an instance acquired from an instance pool
+//
+// swagger:model
+//
+// API extension: instance_pools.
+type InstancePoolReleasePost struct {
+	// Name of the acquired instance
+	// Example: runners-f3a2c1
+	Instance string `json:"instance" yaml:"instance"`
+}
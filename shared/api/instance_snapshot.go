@@ -117,6 +117,11 @@ type InstanceSnapshot struct {
 	//
 	// API extension: snapshot_disk_usage
 	Size int64 `json:"size" yaml:"size"`
+
+	// Disk space usage of the snapshot of the root disk
+	//
+	// API extension: snapshot_space_usage
+	Usage *StorageVolumeSnapshotUsage `json:"usage,omitempty" yaml:"usage,omitempty"`
 }
 
 // Writable converts a full InstanceSnapshot struct into a InstanceSnapshotPut struct
@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // InstanceStatePut represents the modifiable fields of a LXD instance's state.
 //
 // swagger:model
@@ -56,6 +60,104 @@ type InstanceState struct {
 
 	// CPU usage information
 	CPU InstanceStateCPU `json:"cpu" yaml:"cpu"`
+
+	// Kernel resources usage information (containers only)
+	//
+	// API extension: instances_kernel_limits
+	KernelResources *InstanceStateKernelResources `json:"kernel_resources,omitempty" yaml:"kernel_resources,omitempty"`
+
+	// How long the phases of the last start of the instance took
+	//
+	// API extension: instances_state_boot
+	Boot *InstanceStateBoot `json:"boot,omitempty" yaml:"boot,omitempty"`
+
+	// Encrypted block devices of the guest, as reported by the lxd-agent (virtual machines only)
+	//
+	// API extension: instances_disk_encryption
+	DiskEncryption []InstanceStateDiskEncryption `json:"disk_encryption,omitempty" yaml:"disk_encryption,omitempty"`
+}
+
+// InstanceStateDiskEncryption represents an encrypted block device of the guest of a LXD instance.
+//
+// swagger:model
+//
+// API extension: instances_disk_encryption.
+type InstanceStateDiskEncryption struct {
+	// Name of the encrypted block device in the guest
+	// Example: vda3
+	Device string `json:"device" yaml:"device"`
+
+	// Name of the decrypted device mapping, empty if the device isn't unlocked
+	// Example: dm_crypt-0
+	Mapping string `json:"mapping" yaml:"mapping"`
+
+	// Encryption format (luks1, luks2, plain, bitlocker, ...)
+	// Example: luks2
+	Type string `json:"type" yaml:"type"`
+
+	// Where the decrypted device is mounted in the guest, if anywhere
+	// Example: /
+	Mountpoint string `json:"mountpoint" yaml:"mountpoint"`
+}
+
+// InstanceStateBoot represents how long the phases of the last start of a LXD instance took.
+//
+// swagger:model
+//
+// API extension: instances_state_boot.
+type InstanceStateBoot struct {
+	// When the start began
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	StartedAt time.Time `json:"started_at" yaml:"started_at"`
+
+	// Total duration of the recorded phases in nanoseconds
+	// Example: 4317004912
+	Duration int64 `json:"duration" yaml:"duration"`
+
+	// Phases of the start, in the order they completed
+	Phases []InstanceStateBootPhase `json:"phases" yaml:"phases"`
+}
+
+// InstanceStateBootPhase represents how long a phase of the start of a LXD instance took.
+//
+// swagger:model
+//
+// API extension: instances_state_boot.
+type InstanceStateBootPhase struct {
+	// Name of the phase
+	// Example: devices
+	Name string `json:"name" yaml:"name"`
+
+	// Duration of the phase in nanoseconds
+	// Example: 1203846102
+	Duration int64 `json:"duration" yaml:"duration"`
+}
+
+// InstanceStateKernelResources represents the kernel resources section of a LXD instance's state.
+//
+// swagger:model
+//
+// API extension: instances_kernel_limits.
+type InstanceStateKernelResources struct {
+	// Number of open file descriptors
+	// Example: 512
+	OpenFiles int64 `json:"open_files" yaml:"open_files"`
+
+	// Number of inotify instances
+	// Example: 12
+	InotifyInstances int64 `json:"inotify_instances" yaml:"inotify_instances"`
+
+	// Number of inotify watches
+	// Example: 320
+	InotifyWatches int64 `json:"inotify_watches" yaml:"inotify_watches"`
+
+	// Number of keys owned by the users of the instance
+	// Example: 8
+	Keys int64 `json:"keys" yaml:"keys"`
+
+	// Size of the keys owned by the users of the instance in bytes
+	// Example: 240
+	KeysBytes int64 `json:"keys_bytes" yaml:"keys_bytes"`
 }
 
 // InstanceStateDisk represents the disk information section of a LXD instance's state.
@@ -0,0 +1,132 @@
+package api
+
+// InstanceTopology represents how an instance is placed on the host resources.
+//
+// swagger:model
+//
+// API extension: instances_topology.
+type InstanceTopology struct {
+	// CPU placement (empty when the instance isn't running)
+	CPU InstanceTopologyCPU `json:"cpu" yaml:"cpu"`
+
+	// Memory placement (empty when the instance isn't running)
+	Memory InstanceTopologyMemory `json:"memory" yaml:"memory"`
+
+	// Host devices backing the disks of the instance
+	Disks []InstanceTopologyDisk `json:"disks" yaml:"disks"`
+
+	// Host devices backing the network interfaces of the instance
+	NICs []InstanceTopologyNIC `json:"nics" yaml:"nics"`
+}
+
+// InstanceTopologyCPU represents the host CPU threads an instance runs on.
+//
+// swagger:model
+//
+// API extension: instances_topology.
+type InstanceTopologyCPU struct {
+	// Whether the instance is pinned to specific CPU threads or NUMA nodes through its configuration
+	// Example: true
+	Pinned bool `json:"pinned" yaml:"pinned"`
+
+	// Host CPU threads the instance is allowed to run on
+	// Example: [0, 1, 2, 3]
+	Threads []int64 `json:"threads" yaml:"threads"`
+
+	// NUMA nodes of the host CPU threads
+	// Example: [0]
+	NUMANodes []uint64 `json:"numa_nodes" yaml:"numa_nodes"`
+
+	// Host CPU threads each virtual CPU is allowed to run on (virtual machines only)
+	// Example: {"0": [0], "1": [1]}
+	VCPUs map[int][]int64 `json:"vcpus,omitempty" yaml:"vcpus,omitempty"`
+}
+
+// InstanceTopologyMemory represents the host NUMA nodes the memory of an instance is placed on.
+//
+// swagger:model
+//
+// API extension: instances_topology.
+type InstanceTopologyMemory struct {
+	// NUMA nodes the instance is allowed to allocate memory on
+	// Example: [0]
+	NUMANodes []uint64 `json:"numa_nodes" yaml:"numa_nodes"`
+
+	// Memory allocated on each NUMA node in bytes (virtual machines only)
+	// Example: {"0": 1073741824}
+	Usage map[uint64]uint64 `json:"usage,omitempty" yaml:"usage,omitempty"`
+}
+
+// InstanceTopologyDisk represents the host devices backing a disk device of an instance.
+//
+// swagger:model
+//
+// API extension: instances_topology.
+type InstanceTopologyDisk struct {
+	// Name of the disk device
+	// Example: root
+	Name string `json:"name" yaml:"name"`
+
+	// Storage pool of the disk (empty for host paths)
+	// Example: local
+	Pool string `json:"pool" yaml:"pool"`
+
+	// Storage driver of the pool
+	// Example: zfs
+	Driver string `json:"driver" yaml:"driver"`
+
+	// Host path of the disk (host paths only)
+	// Example: /srv/data
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// Whether the storage is remote, in which case no host device backs the disk
+	// Example: false
+	Remote bool `json:"remote" yaml:"remote"`
+
+	// Host disks backing the disk
+	// Example: ["nvme0n1"]
+	Devices []string `json:"devices" yaml:"devices"`
+
+	// NUMA nodes of the host disks
+	// Example: [0]
+	NUMANodes []uint64 `json:"numa_nodes" yaml:"numa_nodes"`
+
+	// Error that prevented finding the host disks
+	// Example: Failed listing the devices of zpool "local"
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// InstanceTopologyNIC represents the path from a network interface of an instance to the host network cards.
+//
+// swagger:model
+//
+// API extension: instances_topology.
+type InstanceTopologyNIC struct {
+	// Name of the NIC device
+	// Example: eth0
+	Name string `json:"name" yaml:"name"`
+
+	// Type of the NIC (nictype or type of the network)
+	// Example: bridge
+	Type string `json:"type" yaml:"type"`
+
+	// Managed network of the NIC
+	// Example: lxdbr0
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
+
+	// Uplink network of the managed network (OVN networks only)
+	// Example: UPLINK
+	Uplink string `json:"uplink,omitempty" yaml:"uplink,omitempty"`
+
+	// Host interface the NIC is attached to (bridge or parent interface)
+	// Example: lxdbr0
+	Parent string `json:"parent,omitempty" yaml:"parent,omitempty"`
+
+	// Host network interfaces of the network cards the traffic goes through
+	// Example: ["enp5s0"]
+	Devices []string `json:"devices" yaml:"devices"`
+
+	// NUMA nodes of the host network cards
+	// Example: [0]
+	NUMANodes []uint64 `json:"numa_nodes" yaml:"numa_nodes"`
+}
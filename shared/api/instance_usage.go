@@ -0,0 +1,42 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceUsage represents the recorded resource usage history of an instance.
+//
+// swagger:model
+//
+// API extension: instance_usage.
+type InstanceUsage struct {
+	// Interval between two samples in seconds
+	// Example: 300
+	Interval int64 `json:"interval" yaml:"interval"`
+
+	// Samples recorded over the requested period, oldest first
+	Samples []InstanceUsageSample `json:"samples" yaml:"samples"`
+}
+
+// InstanceUsageSample represents the resource usage of an instance at a point in time.
+//
+// swagger:model
+//
+// API extension: instance_usage.
+type InstanceUsageSample struct {
+	// When the sample was recorded
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	Time time.Time `json:"time" yaml:"time"`
+
+	// Cumulative CPU time used in nanoseconds
+	// Example: 3637691016
+	CPUUsage int64 `json:"cpu_usage" yaml:"cpu_usage"`
+
+	// Memory usage in bytes
+	// Example: 73248768
+	MemoryUsage int64 `json:"memory_usage" yaml:"memory_usage"`
+
+	// Disk usage in bytes (sum of all disks reporting usage)
+	// Example: 502239232
+	DiskUsage int64 `json:"disk_usage" yaml:"disk_usage"`
+}
@@ -0,0 +1,51 @@
+package api
+
+// InventoryItemTypeMember is the inventory item type of cluster members.
+const InventoryItemTypeMember = "member"
+
+// InventoryItemTypeInstance is the inventory item type of instances.
+const InventoryItemTypeInstance = "instance"
+
+// InventoryItemTypeVolume is the inventory item type of custom storage volumes.
+const InventoryItemTypeVolume = "volume"
+
+// InventoryItemTypeNetwork is the inventory item type of networks.
+const InventoryItemTypeNetwork = "network"
+
+// InventoryItemTypeImage is the inventory item type of images.
+const InventoryItemTypeImage = "image"
+
+// InventoryItem represents a single resource of the inventory export
+//
+// swagger:model
+//
+// API extension: inventory_export.
+type InventoryItem struct {
+	// Type of resource (member, instance, volume, network or image)
+	// Example: instance
+	Type string `json:"type" yaml:"type"`
+
+	// Project of the resource (empty for cluster members)
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Name of the resource (fingerprint for images)
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Kind of resource within its type (instance type, network type, volume content type, member architecture)
+	// Example: container
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Cluster members holding the resource (address for cluster members, empty when not member specific)
+	// Example: ["server01"]
+	Locations []string `json:"locations" yaml:"locations"`
+
+	// Storage pool of the resource (for instances and volumes)
+	// Example: default
+	Pool string `json:"pool" yaml:"pool"`
+
+	// Size in bytes (image size, or configured volume size), 0 if unknown
+	// Example: 10737418240
+	Size int64 `json:"size" yaml:"size"`
+}
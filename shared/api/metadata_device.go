@@ -0,0 +1,50 @@
+package api
+
+// MetadataDeviceType represents the configuration schema of a device type.
+//
+// swagger:model
+//
+// API extension: device_schemas.
+type MetadataDeviceType struct {
+	// Device type
+	// Example: nic
+	Type string `json:"type" yaml:"type"`
+
+	// Device sub-type, that is the NIC type of network devices or the GPU type of GPU devices
+	// Example: bridged
+	SubType string `json:"subtype,omitempty" yaml:"subtype,omitempty"`
+
+	// Configuration keys of the device type
+	Keys map[string]MetadataDeviceKey `json:"keys" yaml:"keys"`
+}
+
+// MetadataDeviceKey represents a configuration key of a device type.
+//
+// swagger:model
+//
+// API extension: device_schemas.
+type MetadataDeviceKey struct {
+	// Value type
+	// Example: integer
+	Type string `json:"type" yaml:"type"`
+
+	// Description of the default value
+	// Example: `1500`
+	Default string `json:"default,omitempty" yaml:"default,omitempty"`
+
+	// Whether the key is required
+	// Example: no
+	Required string `json:"required,omitempty" yaml:"required,omitempty"`
+
+	// Condition under which the key applies
+	// Example: virtual machine
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+
+	// Short description of the key
+	// Example: MTU of the new interface
+	Description string `json:"description" yaml:"description"`
+
+	// Whether the key can be updated without removing and adding the device again
+	// Example: false
+	LiveUpdate bool `json:"live_update" yaml:"live_update"`
+}
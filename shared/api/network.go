@@ -333,3 +333,70 @@ type NetworkStateOVN struct {
 	// OVN network chassis name
 	Chassis string `json:"chassis" yaml:"chassis"`
 }
+
+// NetworkMTU represents the MTU check of a network on a cluster member
+//
+// swagger:model
+//
+// API extension: network_mtu_check.
+type NetworkMTU struct {
+	// MTU used by the network
+	// Example: 1442
+	MTU int `json:"mtu" yaml:"mtu"`
+
+	// Largest MTU that the underlay can carry once the encapsulation overhead is deducted (0 if not encapsulated)
+	// Example: 1442
+	ExpectedMTU int `json:"expected_mtu" yaml:"expected_mtu"`
+
+	// Underlay interfaces used to carry the encapsulated traffic of the network
+	Underlays []NetworkMTUUnderlay `json:"underlays" yaml:"underlays"`
+
+	// List of MTU mismatches found
+	Mismatches []NetworkMTUMismatch `json:"mismatches" yaml:"mismatches"`
+}
+
+// NetworkMTUUnderlay represents an underlay interface carrying the encapsulated traffic of a network
+//
+// swagger:model
+//
+// API extension: network_mtu_check.
+type NetworkMTUUnderlay struct {
+	// Name of the interface
+	// Example: eth0
+	Interface string `json:"interface" yaml:"interface"`
+
+	// MTU of the interface
+	// Example: 1500
+	MTU int `json:"mtu" yaml:"mtu"`
+
+	// Encapsulation protocol
+	// Example: geneve
+	Encapsulation string `json:"encapsulation" yaml:"encapsulation"`
+
+	// Overhead of the encapsulation in bytes
+	// Example: 58
+	Overhead int `json:"overhead" yaml:"overhead"`
+}
+
+// NetworkMTUMismatch represents an interface whose MTU doesn't match the network's
+//
+// swagger:model
+//
+// API extension: network_mtu_check.
+type NetworkMTUMismatch struct {
+	// Name of the interface or instance device
+	// Example: veth3f2d4a1c
+	Interface string `json:"interface" yaml:"interface"`
+
+	// MTU of the interface
+	// Example: 1500
+	MTU int `json:"mtu" yaml:"mtu"`
+
+	// MTU expected for the interface
+	// Example: 1442
+	ExpectedMTU int `json:"expected_mtu" yaml:"expected_mtu"`
+
+	// Description of the mismatch
+	// Example: Interface MTU differs from the bridge MTU
+	Description string `json:"description" yaml:"description"`
+}
@@ -0,0 +1,83 @@
+package api
+
+// NetworkAddressPoolsPost represents the fields of a new LXD network address pool
+//
+// swagger:model
+//
+// API extension: network_address_pools.
+type NetworkAddressPoolsPost struct {
+	NetworkAddressPoolPut `yaml:",inline"`
+
+	// The name of the address pool
+	// Example: public
+	Name string `json:"name" yaml:"name"`
+}
+
+// NetworkAddressPoolPut represents the modifiable fields of a LXD network address pool
+//
+// swagger:model
+//
+// API extension: network_address_pools.
+type NetworkAddressPoolPut struct {
+	// Description of the address pool
+	// Example: Public addresses routed to the hosts
+	Description string `json:"description" yaml:"description"`
+
+	// Address pool configuration map (refer to doc/howto/network_address_pools.md)
+	// Example: {"ipv4.addresses": "192.0.2.8/29"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// NetworkAddressPool represents a pool of external addresses that can be allocated to instance NICs and network
+// forwards.
+//
+// swagger:model
+//
+// API extension: network_address_pools.
+type NetworkAddressPool struct {
+	// The name of the address pool
+	// Example: public
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the address pool
+	// Example: Public addresses routed to the hosts
+	Description string `json:"description" yaml:"description"`
+
+	// Address pool configuration map (refer to doc/howto/network_address_pools.md)
+	// Example: {"ipv4.addresses": "192.0.2.8/29"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// List of URLs of objects using this address pool
+	// Read only: true
+	// Example: ["/1.0/instances/c1", "/1.0/networks/lxdbr0/forwards/192.0.2.9"]
+	UsedBy []string `json:"used_by" yaml:"used_by"` // Resources that use the address pool.
+}
+
+// Writable converts a full NetworkAddressPool struct into a NetworkAddressPoolPut struct (filters read-only fields).
+func (pool *NetworkAddressPool) Writable() NetworkAddressPoolPut {
+	return NetworkAddressPoolPut{
+		Description: pool.Description,
+		Config:      pool.Config,
+	}
+}
+
+// SetWritable sets applicable values from NetworkAddressPoolPut struct to NetworkAddressPool struct.
+func (pool *NetworkAddressPool) SetWritable(put NetworkAddressPoolPut) {
+	pool.Description = put.Description
+	pool.Config = put.Config
+}
+
+// NetworkAddressPoolAllocation represents an address allocated from a network address pool.
+//
+// swagger:model
+//
+// API extension: network_address_pools.
+type NetworkAddressPoolAllocation struct {
+	// Allocated address
+	// Example: 192.0.2.9
+	Address string `json:"address" yaml:"address"`
+
+	// URL of the object using the address
+	// Example: /1.0/instances/c1
+	UsedBy string `json:"used_by" yaml:"used_by"`
+}
@@ -0,0 +1,36 @@
+package api
+
+// ProfileInstanceDrift represents how an instance using a profile differs from the current revision of the profile.
+//
+// swagger:model
+//
+// API extension: profile_drift.
+type ProfileInstanceDrift struct {
+	// Name of the instance
+	// Example: c1
+	Instance string `json:"instance" yaml:"instance"`
+
+	// Project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Revision of the profile the instance was created from (-1 if unknown)
+	// Example: 12
+	Revision int64 `json:"revision" yaml:"revision"`
+
+	// Current revision of the profile
+	// Example: 15
+	CurrentRevision int64 `json:"current_revision" yaml:"current_revision"`
+
+	// Whether the instance was created from an older revision of the profile
+	// Example: true
+	Outdated bool `json:"outdated" yaml:"outdated"`
+
+	// Configuration keys of the profile that are overridden for the instance
+	// Example: ["limits.cpu"]
+	OverriddenConfig []string `json:"overridden_config" yaml:"overridden_config"`
+
+	// Devices of the profile that are overridden for the instance
+	// Example: ["eth0"]
+	OverriddenDevices []string `json:"overridden_devices" yaml:"overridden_devices"`
+}
@@ -114,3 +114,48 @@ type ProjectStateResource struct {
 	// Example: 4
 	Usage int64
 }
+
+// ProjectIdmap represents the range of host IDs reserved for the isolated containers of a project
+//
+// swagger:model
+//
+// API extension: project_idmap_ranges.
+type ProjectIdmap struct {
+	// First host ID of the range (0 if not allocated yet)
+	// Example: 1065536
+	Base int64 `json:"base" yaml:"base"`
+
+	// Number of host IDs in the range (0 if the project doesn't reserve a range)
+	// Example: 655360
+	Size int64 `json:"size" yaml:"size"`
+
+	// Ranges used by the isolated containers of the project
+	Instances []ProjectIdmapInstance `json:"instances" yaml:"instances"`
+}
+
+// ProjectIdmapInstance represents the range of host IDs used by an isolated container
+//
+// swagger:model
+//
+// API extension: project_idmap_ranges.
+type ProjectIdmapInstance struct {
+	// Name of the instance
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Cluster member hosting the instance
+	// Example: server01
+	Location string `json:"location" yaml:"location"`
+
+	// First host ID used by the instance
+	// Example: 1065536
+	Base int64 `json:"base" yaml:"base"`
+
+	// Number of host IDs used by the instance
+	// Example: 65536
+	Size int64 `json:"size" yaml:"size"`
+
+	// Whether the range is outside of the range reserved for the project
+	// Example: false
+	Outside bool `json:"outside" yaml:"outside"`
+}
@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // Resources represents the system resources available for LXD
 //
 // swagger:model
@@ -783,6 +787,30 @@ type ResourcesStoragePool struct {
 
 	// DIsk inode usage
 	Inodes ResourcesStoragePoolInodes `json:"inodes,omitempty" yaml:"inodes,omitempty"`
+
+	// Forecast of the disk space usage based on its recent growth
+	//
+	// API extension: storage_pool_forecast
+	Forecast *ResourcesStoragePoolForecast `json:"forecast,omitempty" yaml:"forecast,omitempty"`
+}
+
+// ResourcesStoragePoolForecast represents the forecast disk space usage of a given storage pool
+//
+// swagger:model
+//
+// API extension: storage_pool_forecast.
+type ResourcesStoragePoolForecast struct {
+	// Growth of the used disk space per day (bytes, negative if shrinking)
+	// Example: 1073741824
+	GrowthPerDay int64 `json:"growth_per_day" yaml:"growth_per_day"`
+
+	// Estimated number of days until the storage pool is full at the current growth (-1 if not growing)
+	// Example: 42
+	DaysUntilFull int64 `json:"days_until_full" yaml:"days_until_full"`
+
+	// Time of the oldest usage sample the forecast is based on
+	// Example: 2024-10-11T12:00:00Z
+	Since time.Time `json:"since" yaml:"since"`
 }
 
 // ResourcesStoragePoolSpace represents the space available to a given storage pool
@@ -19,6 +19,10 @@ type ResponseRaw struct {
 	Code  int    `json:"error_code" yaml:"error_code"`
 	Error string `json:"error" yaml:"error"`
 
+	// Machine-readable reason of the error (valid only for Error responses)
+	// API extension: error_reasons
+	ErrorReason ErrorReason `json:"error_reason,omitempty" yaml:"error_reason,omitempty"`
+
 	Metadata any `json:"metadata" yaml:"metadata"`
 }
 
@@ -37,6 +41,10 @@ type Response struct {
 	Code  int    `json:"error_code" yaml:"error_code"`
 	Error string `json:"error" yaml:"error"`
 
+	// Machine-readable reason of the error (valid only for Error responses)
+	// API extension: error_reasons
+	ErrorReason ErrorReason `json:"error_reason,omitempty" yaml:"error_reason,omitempty"`
+
 	// Valid for Sync and Error responses
 	Metadata json.RawMessage `json:"metadata" yaml:"metadata"`
 }
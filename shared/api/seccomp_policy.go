@@ -0,0 +1,62 @@
+package api
+
+// SeccompPoliciesPost represents the fields of a new LXD syscall interception policy
+//
+// swagger:model
+//
+// API extension: seccomp_policies.
+type SeccompPoliciesPost struct {
+	SeccompPolicyPut `yaml:",inline"`
+
+	// The name of the policy
+	// Example: loop-devices
+	Name string `json:"name" yaml:"name"`
+}
+
+// SeccompPolicyPut represents the modifiable fields of a LXD syscall interception policy
+//
+// swagger:model
+//
+// API extension: seccomp_policies.
+type SeccompPolicyPut struct {
+	// Description of the policy
+	// Example: Allow creating loop devices
+	Description string `json:"description" yaml:"description"`
+
+	// Policy configuration map (refer to doc/syscall-interception.md)
+	// Example: {"mknod.devices": "b 7:*,c 10:237", "audit": "true"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// SeccompPolicy represents an admin-defined set of syscalls that containers are allowed to perform through
+// syscall interception.
+//
+// swagger:model
+//
+// API extension: seccomp_policies.
+type SeccompPolicy struct {
+	// The name of the policy
+	// Example: loop-devices
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the policy
+	// Example: Allow creating loop devices
+	Description string `json:"description" yaml:"description"`
+
+	// Policy configuration map (refer to doc/syscall-interception.md)
+	// Example: {"mknod.devices": "b 7:*,c 10:237", "audit": "true"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// List of URLs of instances using this policy
+	// Read only: true
+	// Example: ["/1.0/instances/c1"]
+	UsedBy []string `json:"used_by" yaml:"used_by"`
+}
+
+// Writable converts a full SeccompPolicy struct into a SeccompPolicyPut struct (filters read-only fields).
+func (policy *SeccompPolicy) Writable() SeccompPolicyPut {
+	return SeccompPolicyPut{
+		Description: policy.Description,
+		Config:      policy.Config,
+	}
+}
@@ -62,6 +62,18 @@ type ServerEnvironment struct {
 	// API extension: lxc_features
 	LXCFeatures map[string]string `json:"lxc_features" yaml:"lxc_features"`
 
+	// List of addresses the migration listener is listening on
+	// Example: ["10.0.0.1:8445"]
+	//
+	// API extension: network_listeners_split
+	MigrationAddresses []string `json:"migration_addresses" yaml:"migration_addresses"`
+
+	// Certificate of the migration listener as PEM encoded X509
+	// Example: X509 PEM certificate
+	//
+	// API extension: network_listeners_split
+	MigrationCertificate string `json:"migration_certificate" yaml:"migration_certificate"`
+
 	// Name of the operating system (Linux distribution)
 	// Example: Ubuntu
 	//
@@ -0,0 +1,55 @@
+package api
+
+// SSHKeysPost represents the fields of a new SSH public key.
+//
+// swagger:model
+//
+// API extension: ssh_keys.
+type SSHKeysPost struct {
+	SSHKeyPut `yaml:",inline"`
+
+	// Name of the key
+	// Example: alice-laptop
+	Name string `json:"name" yaml:"name"`
+}
+
+// SSHKeyPut represents the modifiable fields of an SSH public key.
+//
+// swagger:model
+//
+// API extension: ssh_keys.
+type SSHKeyPut struct {
+	// Description of the key
+	// Example: Alice's laptop
+	Description string `json:"description" yaml:"description"`
+
+	// User the key gives access to in the instances
+	// Example: ubuntu
+	User string `json:"user" yaml:"user"`
+
+	// Public key in the OpenSSH authorized_keys format
+	// Example: ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHxx alice@laptop
+	PublicKey string `json:"public_key" yaml:"public_key"`
+}
+
+// SSHKey represents an SSH public key that LXD keeps in sync in the instances using it.
+//
+// swagger:model
+//
+// API extension: ssh_keys.
+type SSHKey struct {
+	SSHKeyPut `yaml:",inline"`
+
+	// Name of the key
+	// Example: alice-laptop
+	Name string `json:"name" yaml:"name"`
+
+	// SHA256 fingerprint of the key
+	// Example: SHA256:1pMG2zGTlz0t3ExnxbRe9pv2c6ZxrnY4E1UqqSBx9Uw
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+}
+
+// Writable converts a full SSHKey struct into a SSHKeyPut struct (filters read-only fields).
+func (k *SSHKey) Writable() SSHKeyPut {
+	return k.SSHKeyPut
+}
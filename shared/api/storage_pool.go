@@ -73,6 +73,60 @@ type StoragePool struct {
 	//
 	// API extension: clustering
 	Locations []string `json:"locations" yaml:"locations"`
+
+	// Capabilities of the storage pool driver
+	// Read only: true
+	//
+	// API extension: storage_pool_capabilities
+	Capabilities *StoragePoolCapabilities `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+}
+
+// StoragePoolCapabilities represents the capabilities of the driver of a storage pool.
+//
+// swagger:model
+//
+// API extension: storage_pool_capabilities.
+type StoragePoolCapabilities struct {
+	// Whether volumes are copied by cloning them instantly rather than by copying their content
+	// Example: true
+	OptimizedCopy bool `json:"optimized_copy" yaml:"optimized_copy"`
+
+	// Whether images are stored as volumes that instances are cloned from
+	// Example: true
+	OptimizedImages bool `json:"optimized_images" yaml:"optimized_images"`
+
+	// Whether optimized backups (in the driver's own format) are supported
+	// Example: true
+	OptimizedBackups bool `json:"optimized_backups" yaml:"optimized_backups"`
+
+	// Whether filesystem volumes can be grown while in use
+	// Example: true
+	LiveResize bool `json:"live_resize" yaml:"live_resize"`
+
+	// Whether volumes are backed by block devices
+	// Example: false
+	BlockMode bool `json:"block_mode" yaml:"block_mode"`
+
+	// Whether volumes can have snapshots
+	// Example: true
+	Snapshots bool `json:"snapshots" yaml:"snapshots"`
+
+	// Whether a snapshot can be restored even when more recent snapshots exist, without deleting them
+	// Example: false
+	RestoreAnySnapshot bool `json:"restore_any_snapshot" yaml:"restore_any_snapshot"`
+
+	// Whether the limits.read and limits.write disk options apply to the containers on the pool
+	// (they always apply to virtual machines)
+	// Example: false
+	IOLimits bool `json:"io_limits" yaml:"io_limits"`
+
+	// Whether the driver uses a remote backing store shared by all cluster members
+	// Example: false
+	Remote bool `json:"remote" yaml:"remote"`
+
+	// Whether storage buckets are supported
+	// Example: true
+	Buckets bool `json:"buckets" yaml:"buckets"`
 }
 
 // StoragePoolPut represents the modifiable fields of a LXD storage pool.
@@ -75,6 +75,18 @@ type StorageVolumePost struct {
 	//
 	// API extension: cluster_internal_custom_volume_copy
 	Source StorageVolumeSource `json:"source" yaml:"source"`
+
+	// Compression algorithm to use for the migration transfer (overrides migration.compression_algorithm)
+	// Example: zstd -3
+	//
+	// API extension: migration_compression
+	CompressionAlgorithm string `json:"compression_algorithm,omitempty" yaml:"compression_algorithm,omitempty"`
+
+	// Whether to verify the checksums of transferred block volumes on the target (overrides migration.verify_checksums)
+	// Example: true
+	//
+	// API extension: migration_verify_checksums
+	VerifyChecksums bool `json:"verify_checksums,omitempty" yaml:"verify_checksums,omitempty"`
 }
 
 // StorageVolumePostTarget represents the migration target host and operation
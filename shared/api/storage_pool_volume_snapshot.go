@@ -77,6 +77,26 @@ type StorageVolumeSnapshot struct {
 	// Storage volume configuration map (refer to doc/storage.md)
 	// Example: {"zfs.remove_snapshots": "true", "size": "50GiB"}
 	Config map[string]string `json:"config" yaml:"config"`
+
+	// Disk space usage of the snapshot
+	//
+	// API extension: snapshot_space_usage
+	Usage *StorageVolumeSnapshotUsage `json:"usage,omitempty" yaml:"usage,omitempty"`
+}
+
+// StorageVolumeSnapshotUsage represents the disk space usage of a snapshot.
+//
+// swagger:model
+//
+// API extension: snapshot_space_usage.
+type StorageVolumeSnapshotUsage struct {
+	// Disk space only used by the snapshot, that is freed when deleting it (bytes)
+	// Example: 143360
+	Unique int64 `json:"unique" yaml:"unique"`
+
+	// Disk space used by the snapshot that is shared with the volume or with other snapshots (bytes)
+	// Example: 1073741824
+	Shared int64 `json:"shared" yaml:"shared"`
 }
 
 // StorageVolumeSnapshotPut represents the modifiable fields of a LXD storage volume
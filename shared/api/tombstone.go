@@ -0,0 +1,78 @@
+package api
+
+import (
+	"time"
+)
+
+// Tombstone represents the recorded definition of a deleted entity.
+//
+// swagger:model
+//
+// API extension: tombstones.
+type Tombstone struct {
+	// Tombstone identifier
+	// Example: 42
+	ID int64 `json:"id" yaml:"id"`
+
+	// Type of the deleted entity (instance, profile or network)
+	// Example: instance
+	EntityType string `json:"entity_type" yaml:"entity_type"`
+
+	// Project the deleted entity belonged to
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Name of the deleted entity
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// When the entity was deleted
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	DeletedAt time.Time `json:"deleted_at" yaml:"deleted_at"`
+
+	// When the tombstone expires
+	// Example: 2021-03-24T17:38:37.753398689-04:00
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+
+	// Requestor that deleted the entity (if known)
+	Requestor *EventLifecycleRequestor `json:"requestor" yaml:"requestor"`
+
+	// Type of the deleted instance or network
+	// Example: container
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Description of the deleted entity
+	// Example: My instance
+	Description string `json:"description" yaml:"description"`
+
+	// Configuration of the deleted entity
+	// Example: {"limits.cpu": "4"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Devices of the deleted entity (instances and profiles only)
+	// Example: {"root": {"type": "disk", "pool": "default", "path": "/"}}
+	Devices map[string]map[string]string `json:"devices,omitempty" yaml:"devices,omitempty"`
+
+	// Profiles applied to the deleted entity (instances only)
+	// Example: ["default"]
+	Profiles []string `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+
+	// Architecture of the deleted entity (instances only)
+	// Example: x86_64
+	Architecture string `json:"architecture,omitempty" yaml:"architecture,omitempty"`
+
+	// Whether the deleted entity was ephemeral (instances only)
+	// Example: false
+	Ephemeral bool `json:"ephemeral,omitempty" yaml:"ephemeral,omitempty"`
+}
+
+// TombstonePost represents the fields used to restore the definition of a deleted entity.
+//
+// swagger:model
+//
+// API extension: tombstones.
+type TombstonePost struct {
+	// Name to restore the entity under (defaults to its original name)
+	// Example: c1-restored
+	Name string `json:"name" yaml:"name"`
+}
@@ -28,6 +28,8 @@ func DetectCompressionFile(f io.Reader) ([]string, string, []string, error) {
 	// gz - 2 bytes, 0x1f 0x8b
 	// lzma - 6 bytes, { [0x000, 0xE0], '7', 'z', 'X', 'Z', 0x00 } -
 	// xy - 6 bytes,  header format { 0xFD, '7', 'z', 'X', 'Z', 0x00 }
+	// zst - 4 bytes, 0x28 0xb5 0x2f 0xfd
+	// lz4 - 4 bytes, 0x04 0x22 0x4d 0x18
 	// tar - 263 bytes, trying to get ustar from 257 - 262
 	header := make([]byte, 263)
 	_, err := f.Read(header)
@@ -54,6 +56,8 @@ func DetectCompressionFile(f io.Reader) ([]string, string, []string, error) {
 		return []string{""}, ".qcow2", []string{"qemu-img", "convert", "-O", "raw"}, nil
 	case bytes.Equal(header[0:4], []byte{0x28, 0xb5, 0x2f, 0xfd}):
 		return []string{"--zstd", "-xf"}, ".tar.zst", []string{"zstd", "-d"}, nil
+	case bytes.Equal(header[0:4], []byte{0x04, 0x22, 0x4d, 0x18}):
+		return []string{"-Ilz4", "-xf"}, ".tar.lz4", []string{"lz4", "-d"}, nil
 	default:
 		return nil, "", nil, fmt.Errorf("Unsupported compression")
 	}
@@ -42,6 +42,12 @@ const HTTPSMetricsDefaultPort = 9100
 // HTTPSStorageBucketsDefaultPort the default port for the storage buckets listener.
 const HTTPSStorageBucketsDefaultPort = 9000
 
+// HTTPSEventsDefaultPort the default port for the events listener.
+const HTTPSEventsDefaultPort = 8444
+
+// HTTPSMigrationDefaultPort the default port for the migration listener.
+const HTTPSMigrationDefaultPort = 8445
+
 // URLEncode encodes a path and query parameters to a URL.
 func URLEncode(path string, query map[string]string) (string, error) {
 	u, err := url.Parse(path)
@@ -408,6 +408,83 @@ var APIExtensions = []string{
 	"device_usb_serial",
 	"network_allocate_external_ips",
 	"explicit_trust_token",
+	"config_history",
+	"tombstones",
+	"instance_usage",
+	"event_project_isolation",
+	"acme_dns01_listener_certificates",
+	"network_listeners_split",
+	"network_dns_external",
+	"network_bridge_overlay",
+	"network_address_pools",
+	"storage_dir_optimized_images",
+	"migration_compression",
+	"migration_verify_checksums",
+	"storage_dir_tmpfs",
+	"instance_pools",
+	"instance_ephemeral_ttl",
+	"instance_freeze_agent",
+	"instance_stateful_stop_actions",
+	"instance_host_shutdown_ignore",
+	"unix_hotplug_vm_properties",
+	"instance_kernel_modules_auto",
+	"project_idmap_ranges",
+	"seccomp_policies",
+	"instance_clock_offsets",
+	"project_disk_usage_enforcement",
+	"operation_callbacks",
+	"event_bus_nats",
+	"metrics_gpu",
+	"instance_rebuild_snapshots",
+	"storage_volume_export",
+	"backup_deduplication",
+	"profile_drift",
+	"instance_logs_stream",
+	"instance_journal_forwarding",
+	"cluster_images_volume",
+	"project_network_restriction_patterns",
+	"network_mtu_check",
+	"ovn_database_health",
+	"instance_firewall_rules",
+	"instance_usb_redirection",
+	"error_reasons",
+	"cluster_database_snapshots",
+	"cluster_database_integrity",
+	"scim_provisioning",
+	"auth_approvals",
+	"devlxd_credentials",
+	"network_bridge_metadata",
+	"ssh_keys",
+	"cluster_version_skew",
+	"instance_adopt",
+	"device_schemas",
+	"storage_pool_forecast",
+	"snapshot_space_usage",
+	"instances_kernel_limits",
+	"instances_vm_clock",
+	"instances_cross_project_clone",
+	"certificate_generate_metrics",
+	"maintenance_window",
+	"inventory_export",
+	"clustering_evacuation_state",
+	"storage_pool_capabilities",
+	"instances_create_resources",
+	"instances_unique_names",
+	"instance_commands",
+	"instance_pool_move_live",
+	"patch_wait_timeout",
+	"instances_state_boot",
+	"storage_patch_concurrency",
+	"image_alias_channels",
+	"projects_default_placement",
+	"instances_disk_encryption",
+	"clustering_patch_barriers",
+	"projects_metrics",
+	"storage_buckets_lifecycle",
+	"cluster_member_log",
+	"instances_topology",
+	"metrics_patches",
+	"image_publish_reproducible",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
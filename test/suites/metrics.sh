@@ -108,6 +108,25 @@ test_metrics() {
   ! curl -k -s --cert "${TEST_DIR}/metrics-restricted.crt" --key "${TEST_DIR}/metrics-restricted.key" -X GET "https://${metrics_addr}/1.0/metrics?project=foo" | grep "name=\"c1\""
   ! curl -k -s --cert "${TEST_DIR}/metrics-restricted.crt" --key "${TEST_DIR}/metrics-restricted.key" -X GET "https://${metrics_addr}/1.0/metrics?project=foo" | grep "name=\"c2\""
 
+  # the project metrics endpoint only returns the metrics of the project, without the internal server metrics
+  curl -k -s --cert "${TEST_DIR}/metrics-restricted.crt" --key "${TEST_DIR}/metrics-restricted.key" -X GET "https://${metrics_addr}/1.0/projects/foo/metrics" | grep "name=\"c3\""
+  curl -k -s --cert "${TEST_DIR}/metrics-restricted.crt" --key "${TEST_DIR}/metrics-restricted.key" -X GET "https://${LXD_ADDR}/1.0/projects/foo/metrics" | grep "name=\"c3\""
+  ! curl -k -s --cert "${TEST_DIR}/metrics-restricted.crt" --key "${TEST_DIR}/metrics-restricted.key" -X GET "https://${metrics_addr}/1.0/projects/foo/metrics" | grep -E "^lxd_warnings_total [0-9]+$" || false
+  curl -k -s --cert "${TEST_DIR}/metrics-restricted.crt" --key "${TEST_DIR}/metrics-restricted.key" -X GET "https://${metrics_addr}/1.0/projects/default/metrics" | grep "\"error_code\":403"
+  ! lxc query "/1.0/projects/default/metrics" | grep -E "^lxd_warnings_total [0-9]+$" || false
+  lxc query "/1.0/projects/default/metrics" | grep "name=\"c1\""
+
+  # a client restricted to the foo project can generate metrics certificates for it, but not for other projects
+  gen_cert_and_key "${TEST_DIR}/tenant.key" "${TEST_DIR}/tenant.crt" "tenant.local"
+  lxc config trust add "${TEST_DIR}/tenant.crt" --restricted --projects foo
+  curl -k -s --cert "${TEST_DIR}/tenant.crt" --key "${TEST_DIR}/tenant.key" -X POST -d '{"type": "metrics", "generate": true, "name": "tenant-metrics", "restricted": true, "projects": ["foo"]}' "https://${LXD_ADDR}/1.0/certificates" | grep -F '"status_code":200'
+  curl -k -s --cert "${TEST_DIR}/tenant.crt" --key "${TEST_DIR}/tenant.key" -X POST -d '{"type": "metrics", "generate": true, "name": "tenant-metrics-default", "restricted": true, "projects": ["default"]}' "https://${LXD_ADDR}/1.0/certificates" | grep -F '"error_code":403'
+  curl -k -s --cert "${TEST_DIR}/tenant.crt" --key "${TEST_DIR}/tenant.key" -X POST -d '{"type": "metrics", "generate": true, "name": "tenant-metrics-all"}' "https://${LXD_ADDR}/1.0/certificates" | grep -F '"error_code":403'
+  lxc config trust list --format csv | grep -F ",tenant-metrics,"
+  ! lxc config trust list --format csv | grep -F ",tenant-metrics-default," || false
+  lxc config trust remove "$(lxc config trust list --format csv | grep -F ",tenant-metrics," | cut -d, -f4)"
+  lxc config trust remove "$(openssl x509 -in "${TEST_DIR}/tenant.crt" -outform der | sha256sum | head -c12)"
+
   # Check that we can get the count of existing containers. There should be two in the default project: c1 (RUNNING) and c2 (STOPPED).
   curl -k -s --cert "${TEST_DIR}/metrics.crt" --key "${TEST_DIR}/metrics.key" -X GET "https://${metrics_addr}/1.0/metrics" | grep -xF 'lxd_instances{project="default",type="container"} 2'
   sleep 10